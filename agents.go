@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/harness"
+	"github.com/simonbystrom/mastermind/internal/harness/claudecode"
+	"github.com/simonbystrom/mastermind/internal/harness/opencode"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+// taskTimeout is how long `mastermind spawn --task` waits for the agent to
+// be ready for its task when no daemon runs to hand it over.
+const taskTimeout = 2 * time.Minute
+
+// runSpawn implements `mastermind spawn <branch>`: it spawns an agent on
+// branch, creating the branch from --base unless it exists, and prints the
+// agent's ID. A --task is typed into the agent once it is ready; with no
+// daemon running the command waits for that.
+func runSpawn(args []string) int {
+	fs := flag.NewFlagSet("spawn", flag.ExitOnError)
+	repo := fs.String("repo", "", "path to git repository (defaults to current directory)")
+	session := fs.String("session", "", "tmux session name (defaults to current session)")
+	base := fs.String("base", "", "base branch (defaults to the current branch)")
+	harnessName := fs.String("harness", "", "harness to run (defaults to [harness] default)")
+	task := fs.String("task", "", "task to give the agent once it is ready; - reads it from stdin")
+	readOnly := fs.Bool("read-only", false, "spawn a research agent that is never merged or pushed")
+	fetchBase := fs.Bool("fetch-base", false, "fast-forward the base branch from origin before creating the branch (defaults to [git] fetch_base)")
+	asJSON := fs.Bool("json", false, "print the agent as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: mastermind spawn [flags] <branch>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	branch := fs.Arg(0)
+	// Only a --fetch-base given on the command line overrides the config.
+	var fetch *bool
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "fetch-base" {
+			fetch = fetchBase
+		}
+	})
+
+	if *task == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: reading task: %v\n", err)
+			return 1
+		}
+		*task = strings.TrimSpace(string(data))
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	orch, store, logFile, err := startHeadless(ctx, *repo, *session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+
+	repoPath := orch.IPCBackend().Repo()
+	if *base == "" {
+		if *base, err = git.CurrentBranch(repoPath); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+	}
+	err = orch.IPCBackend().Spawn(ipc.SpawnParams{
+		Branch:       branch,
+		BaseBranch:   *base,
+		CreateBranch: !git.BranchExists(repoPath, branch),
+		Harness:      *harnessName,
+		ReadOnly:     *readOnly,
+		Task:         *task,
+		FetchBase:    fetch,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	done := make(chan struct{})
+	go func() {
+		orch.StartMonitor()
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	id := waitForAgent(ctx, orch, branch)
+	switch {
+	case *asJSON:
+		printJSON(struct {
+			ID     string `json:"id"`
+			Branch string `json:"branch"`
+		}{id, branch})
+	case id == "":
+		fmt.Printf("Spawned an agent on %s\n", branch)
+	default:
+		fmt.Println(id)
+	}
+	if id == "" {
+		return 0
+	}
+
+	// The daemon hands the task over; otherwise this process's monitor has
+	// to, before it exits.
+	if *task != "" && !orch.DaemonClient() {
+		if !waitForTask(ctx, store, id) {
+			fmt.Fprintf(os.Stderr, "error: agent %s was not ready for its task within %s\n", id, taskTimeout)
+			return 1
+		}
+	}
+	return 0
+}
+
+// waitForTask waits until agent id has been given its pending task,
+// reporting whether it was.
+func waitForTask(ctx context.Context, store *agent.Store, id string) bool {
+	deadline := time.After(taskTimeout)
+	for {
+		a, ok := store.Get(id)
+		if !ok {
+			return false
+		}
+		if a.GetPendingTask() == "" {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-deadline:
+			return false
+		case <-time.After(batchPoll / 4):
+		}
+	}
+}
+
+// agentJSON is an agent as printed by `mastermind list --json`: its
+// persisted state plus what its statusline sidecar reports.
+type agentJSON struct {
+	agent.PersistedAgent
+	Model           string  `json:"model,omitempty"`
+	CostUSD         float64 `json:"cost_usd"`
+	ContextPct      float64 `json:"context_pct"`
+	LinesAdded      int     `json:"lines_added"`
+	LinesRemoved    int     `json:"lines_removed"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// listJSON is the output of `mastermind list --json`, shaped like the web
+// dashboard's /api/agents.
+type listJSON struct {
+	Repo   string      `json:"repo"`
+	Agents []agentJSON `json:"agents"`
+}
+
+// metricsHarnesses read the metrics sidecars of the built-in harnesses;
+// custom agent commands write none.
+var metricsHarnesses = map[harness.Type]harness.Harness{
+	harness.TypeClaudeCode: &claudecode.Harness{},
+	harness.TypeOpenCode:   &opencode.Harness{},
+}
+
+// describeAgent adds an agent's metrics and working time to its
+// persisted state.
+func describeAgent(pa agent.PersistedAgent, now time.Time) agentJSON {
+	aj := agentJSON{PersistedAgent: pa}
+	duration := pa.AccumulatedDuration
+	if !pa.RunningStartedAt.IsZero() {
+		duration += now.Sub(pa.RunningStartedAt)
+	}
+	aj.DurationSeconds = duration.Round(time.Second).Seconds()
+
+	h := metricsHarnesses[pa.Harness]
+	if pa.Harness == "" {
+		h = metricsHarnesses[harness.TypeClaudeCode]
+	}
+	if h == nil {
+		return aj
+	}
+	md, err := h.ReadMetrics(pa.WorktreePath)
+	if err != nil || md == nil {
+		return aj
+	}
+	aj.Model = md.Model
+	aj.CostUSD = md.CostUSD
+	aj.ContextPct = md.ContextPct
+	aj.LinesAdded = md.LinesAdded
+	aj.LinesRemoved = md.LinesRemoved
+	return aj
+}
+
+// runList implements `mastermind list`: it prints the tracked agents from
+// the state file, with the cost and context use their sidecars report, so
+// it works outside tmux and while the TUI or daemon runs.
+func runList(args []string) int {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	repo := fs.String("repo", "", "path to git repository (defaults to current directory)")
+	asJSON := fs.Bool("json", false, "print the agents as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: mastermind list [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	absRepo, err := resolveRepo(*repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	agents, err := agent.LoadState(filepath.Join(absRepo, ".worktrees", "mastermind-state.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	now := time.Now()
+	out := listJSON{Repo: absRepo, Agents: make([]agentJSON, len(agents))}
+	for i, pa := range agents {
+		out.Agents[i] = describeAgent(pa, now)
+	}
+	if *asJSON {
+		return printJSON(out)
+	}
+
+	if len(agents) == 0 {
+		fmt.Println("No agents")
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tBRANCH\tBASE\tSTATUS\tHARNESS\tCOST\tCONTEXT\tDURATION")
+	for _, a := range out.Agents {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t$%.2f\t%.0f%%\t%s\n", a.ID, a.Branch, a.BaseBranch, a.Status, a.Harness,
+			a.CostUSD, a.ContextPct, time.Duration(a.DurationSeconds)*time.Second)
+	}
+	w.Flush()
+	return 0
+}
+
+// runMerge implements `mastermind merge <id|branch>`: it merges the
+// agent's branch into its base, as the dashboard's quick merge does, and
+// exits non-zero on conflicts or failure.
+func runMerge(args []string) int {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	repo := fs.String("repo", "", "path to git repository (defaults to current directory)")
+	session := fs.String("session", "", "tmux session name (defaults to current session)")
+	deleteBranch := fs.Bool("delete-branch", false, "delete the branch after merging")
+	keepWorktree := fs.Bool("keep-worktree", false, "keep the agent and its worktree after merging")
+	push := fs.Bool("push", false, "push the base branch to its upstream after merging")
+	strategy := fs.String("strategy", "", `"merge", "rebase" or "squash" (defaults to [merge] strategy)`)
+	message := fs.String("message", "", "commit message for a squash merge (defaults to one listing the branch's commits)")
+	asJSON := fs.Bool("json", false, "print the outcome as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: mastermind merge [flags] <id|branch>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if *strategy != "" && !slices.Contains(orchestrator.MergeStrategies, orchestrator.MergeStrategy(*strategy)) {
+		fmt.Fprintf(os.Stderr, "error: unknown merge strategy %q\n", *strategy)
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	orch, store, logFile, err := startHeadless(ctx, *repo, *session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+
+	a, err := findAgent(orch, store, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	res := orch.IPCBackend().Merge(ipc.MergeParams{ID: a.ID, DeleteBranch: *deleteBranch, RemoveWorktree: !*keepWorktree, Push: *push, Strategy: *strategy, Message: *message})
+	if *asJSON {
+		printJSON(struct {
+			ID     string `json:"id"`
+			Branch string `json:"branch"`
+			ipc.MergeResult
+		}{a.ID, a.Branch, res})
+		if !res.Success || res.PushError != "" {
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case res.Conflict:
+		fmt.Fprintf(os.Stderr, "merge of %s has conflicts; resolve them in the dashboard:\n", a.Branch)
+		for _, f := range res.ConflictFiles {
+			fmt.Fprintf(os.Stderr, "  %s\n", f)
+		}
+		for _, n := range res.ConflictNotes {
+			fmt.Fprintf(os.Stderr, "  %s\n", n)
+		}
+		return 1
+	case !res.Success:
+		fmt.Fprintf(os.Stderr, "error: %s\n", res.Error)
+		return 1
+	}
+	fmt.Printf("Merged %s into %s\n", a.Branch, a.GetBaseBranch())
+	if res.PushError != "" {
+		fmt.Fprintf(os.Stderr, "error: push: %s\n", res.PushError)
+		return 1
+	}
+	return 0
+}
+
+// runDismiss implements `mastermind dismiss <id|branch>`: it stops the
+// agent and removes its tmux window and worktree.
+func runDismiss(args []string) int {
+	fs := flag.NewFlagSet("dismiss", flag.ExitOnError)
+	repo := fs.String("repo", "", "path to git repository (defaults to current directory)")
+	session := fs.String("session", "", "tmux session name (defaults to current session)")
+	deleteBranch := fs.Bool("delete-branch", false, "delete the agent's branch too")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: mastermind dismiss [flags] <id|branch>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	orch, store, logFile, err := startHeadless(ctx, *repo, *session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+
+	a, err := findAgent(orch, store, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if err := orch.IPCBackend().Dismiss(ipc.DismissParams{ID: a.ID, DeleteBranch: *deleteBranch}); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Dismissed agent %s (%s)\n", a.ID, a.Branch)
+	if _, err := os.Stat(a.WorktreePath); err == nil && !orch.DaemonClient() {
+		fmt.Fprintf(os.Stderr, "warning: processes still run in %s, so the worktree was kept\n", a.WorktreePath)
+	}
+	return 0
+}
+
+// printJSON prints v as indented JSON and returns the command's exit code.
+func printJSON(v any) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// findAgent returns the agent with ID ref, or else the agent on branch ref.
+func findAgent(orch *orchestrator.Orchestrator, store *agent.Store, ref string) (*agent.Agent, error) {
+	if a, ok := store.Get(ref); ok {
+		return a, nil
+	}
+	if id := orch.AgentBranches()[ref]; id != "" {
+		if a, ok := store.Get(id); ok {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("no agent with ID or branch %q", ref)
+}
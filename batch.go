@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/harness"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+	"github.com/simonbystrom/mastermind/internal/playbook"
+)
+
+// batchPoll is how often a batch checks for a free slot under the
+// max_running cap.
+const batchPoll = 2 * time.Second
+
+// batchResult is the outcome of one task of a batch.
+type batchResult struct {
+	branch  string
+	agentID string
+	err     string
+}
+
+// runBatch implements `mastermind batch <tasks.yaml>`: it spawns one agent
+// per task, waiting for a free slot whenever [agents] max_running agents
+// are working, and prints a summary. Every task is validated before the
+// first agent is spawned. It exits non-zero when a task failed to spawn.
+func runBatch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	repo := fs.String("repo", "", "path to git repository (defaults to current directory)")
+	session := fs.String("session", "", "tmux session name (defaults to current session)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: mastermind batch [flags] <tasks.yaml>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	tasks, err := playbook.LoadTasks(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	orch, _, logFile, err := startHeadless(ctx, *repo, *session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+
+	presets, err := orch.Playbooks()
+	if err != nil {
+		slog.Warn("some playbooks failed to load", "error", err)
+	}
+	playbooks, err := playbook.ResolveTasks(tasks, presets)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	harnesses := make([]harness.Type, len(tasks))
+	for i, t := range tasks {
+		switch h := harness.Type(t.Harness); {
+		case h == "":
+			harnesses[i] = orch.DefaultHarness()
+		case orch.HasHarness(h):
+			harnesses[i] = h
+		default:
+			fmt.Fprintf(os.Stderr, "error: task %d: unknown harness %q\n", i+1, t.Harness)
+			return 1
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		orch.StartMonitor()
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	results := make([]batchResult, len(playbooks))
+	for i, p := range playbooks {
+		results[i].branch = p.Branch
+		if !waitForCapacity(ctx, orch) {
+			results[i].err = "not spawned: interrupted"
+			continue
+		}
+		if err := orch.RunPlaybook(p, p.Branch, harnesses[i]); err != nil {
+			results[i].err = err.Error()
+			fmt.Printf("%s: %v\n", p.Branch, err)
+			continue
+		}
+		results[i].agentID = waitForAgent(ctx, orch, p.Branch)
+		fmt.Printf("%s: spawned\n", p.Branch)
+	}
+	return printBatchSummary(results)
+}
+
+// waitForCapacity blocks until another agent may be spawned under the
+// max_running cap. It returns false when ctx is cancelled first.
+func waitForCapacity(ctx context.Context, orch *orchestrator.Orchestrator) bool {
+	for !orch.HasCapacity() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(batchPoll):
+		}
+	}
+	return ctx.Err() == nil
+}
+
+// waitForAgent returns the ID of the agent on branch. Following a daemon,
+// the agent only shows up once the daemon's state has been synced, and it
+// must count against the cap before the next task is spawned.
+func waitForAgent(ctx context.Context, orch *orchestrator.Orchestrator, branch string) string {
+	deadline := time.After(5 * batchPoll)
+	for {
+		if id := orch.AgentBranches()[branch]; id != "" {
+			return id
+		}
+		select {
+		case <-ctx.Done():
+			return ""
+		case <-deadline:
+			return ""
+		case <-time.After(batchPoll / 4):
+		}
+	}
+}
+
+// printBatchSummary prints one line per task and returns the command's
+// exit code.
+func printBatchSummary(results []batchResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.err != "" {
+			failed++
+		}
+	}
+	fmt.Printf("\nBatch: %d spawned, %d failed\n", len(results)-failed, failed)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, r := range results {
+		id := r.agentID
+		if id == "" {
+			id = "—"
+		}
+		outcome := "spawned"
+		if r.err != "" {
+			outcome = "failed: " + r.err
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", id, r.branch, outcome)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+// runCleanup implements `mastermind cleanup`: the emergency way out of a
+// preview left behind by a TUI that was killed or hung before it could
+// clean up. It checks the previous branch out again in the main worktree
+// and deletes the preview branch, as recorded in
+// .worktrees/mastermind-preview.json. It also lists the worktrees of
+// dismissed agents kept for processes still running in them, and with
+// --kill-orphans kills those processes and removes the worktrees.
+func runCleanup(args []string) int {
+	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	repo := fs.String("repo", "", "path to git repository (defaults to current directory)")
+	killOrphans := fs.Bool("kill-orphans", false, "kill processes left running in dismissed agents' worktrees and remove the worktrees")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: mastermind cleanup [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	absRepo, err := resolveRepo(*repo)
+	if err == nil {
+		err = validateGitRepo(absRepo)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	cfg, err := config.LoadForRepo(absRepo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: loading config: %v\n", err)
+		return 1
+	}
+	worktreeDir, logFile, err := setupWorktreeDir(absRepo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+
+	// No tmux session is needed: only git and the state files are touched.
+	orch := orchestrator.New(context.Background(), agent.NewStore(), absRepo, "", worktreeDir, orchestratorOptions(cfg)...)
+	if err := orch.LoadAgents(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: loading agents: %v\n", err)
+		return 1
+	}
+
+	status := 0
+	for _, held := range orch.HeldWorktrees() {
+		if !*killOrphans {
+			fmt.Printf("Worktree %s of agent %s is kept for %d running process(es); rerun with --kill-orphans to kill them\n", held.WorktreePath, held.AgentID, len(held.Processes))
+			continue
+		}
+		if res := orch.KillOrphans(held); res.Error != "" {
+			fmt.Fprintf(os.Stderr, "error: worktree %s: %s\n", held.WorktreePath, res.Error)
+			status = 1
+			continue
+		}
+		fmt.Printf("Killed the processes left in %s and removed it\n", held.WorktreePath)
+	}
+
+	stopped, err := orch.RecoverPreview()
+	if stopped.Stash != "" {
+		fmt.Printf("Changes made in the main worktree during the preview were stashed as %s: %s\n", stopped.Stash, strings.Join(stopped.Stashed, ", "))
+	}
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	case stopped.AgentID == "":
+		fmt.Println("No preview to clean up")
+	default:
+		fmt.Printf("Cleaned up the preview of agent %s\n", stopped.AgentID)
+	}
+	return status
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/daemon"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+// runDaemon implements `mastermind daemon`: the monitor without the TUI.
+// It keeps tracking agents, persisting state and logging events until it
+// is stopped, so closing the dashboard doesn't stop monitoring. A TUI
+// started while the daemon runs follows it instead of polling tmux itself,
+// and any frontend can drive it over the IPC socket.
+func runDaemon(args []string) int {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	repo := fs.String("repo", "", "path to git repository (defaults to current directory)")
+	session := fs.String("session", "", "tmux session name (defaults to current session)")
+	stop := fs.Bool("stop", false, "stop the daemon running for the repository")
+	fs.Parse(args)
+
+	absRepo, err := resolveRepo(*repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if *stop {
+		if err := daemon.Stop(filepath.Join(absRepo, ".worktrees")); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if err := validateDependencies(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if err := validateGitRepo(absRepo); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if *session == "" {
+		detected, err := detectTmuxSession()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		*session = detected
+	}
+
+	cfg, err := config.LoadForRepo(absRepo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		return 1
+	}
+
+	worktreeDir, logFile, err := setupWorktreeDir(absRepo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+
+	lock, err := daemon.Acquire(worktreeDir)
+	if errors.Is(err, daemon.ErrRunning) {
+		pid, _ := daemon.Running(worktreeDir)
+		fmt.Fprintf(os.Stderr, "error: daemon already running (pid %d)\n", pid)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer lock.Release()
+
+	events, err := daemon.CreateEventLog(daemon.EventsPath(worktreeDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer events.Close()
+
+	slog.Info("mastermind daemon starting", "repo", absRepo, "session", *session, "pid", os.Getpid())
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	store := agent.NewStore()
+	orch := orchestrator.New(ctx, store, absRepo, *session, worktreeDir,
+		append(orchestratorOptions(cfg), orchestrator.WithDaemon(events))...)
+	orch.RecoverAgents()
+
+	ln, err := ipc.Listen(daemon.SocketPath(worktreeDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer os.Remove(daemon.SocketPath(worktreeDir))
+	srv := ipc.NewServer(orch.IPCBackend())
+	go func() {
+		if err := srv.Serve(ctx, ln); err != nil {
+			slog.Error("ipc server stopped", "error", err)
+		}
+	}()
+
+	startRemote(ctx, cfg, srv.Backend())
+
+	// Blocks until a signal cancels the context.
+	orch.StartMonitor()
+	orch.RemovePromptStatus()
+	slog.Info("mastermind daemon stopped")
+	return 0
+}
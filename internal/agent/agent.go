@@ -6,6 +6,7 @@ import (
 
 	"github.com/simonbystrom/mastermind/internal/harness"
 	"github.com/simonbystrom/mastermind/internal/hook"
+	"github.com/simonbystrom/mastermind/internal/team"
 )
 
 type Status string
@@ -27,30 +28,66 @@ type Agent struct {
 	// Immutable fields (safe to read without lock)
 	ID           string
 	Branch       string
-	BaseBranch   string
 	WorktreePath string
 	TmuxWindow   string
 	TmuxPaneID   string
 	StartedAt    time.Time
-	Harness      harness.Type // "claude" or "opencode"
+	Harness      harness.Type // "claude", "opencode", or a [[harness.agents]] name
+	Ticket       string       // linked Linear/Jira ticket ID, e.g. "ENG-123"
+	Prompt       string       // initial task given at spawn, if any
+	ReadOnly     bool         // research agent: never merged or pushed, branch kept on dismiss
+	ReportFile   string       // report agent: document collected from the worktree on finish
+	Playbook     string       // playbook file the agent runs, whose checks run on finish
+	Team         string       // agent team lead: name of the team it was spawned to form
+
+	// BaseBranch is the branch this agent merges into. It changes when a
+	// stacked agent's parent is merged, so once the agent is shared read it
+	// with GetBaseBranch. Protected by mu.
+	BaseBranch string
 
 	// Mutable fields (protected by mu)
 	mu              sync.RWMutex
 	status          Status
 	waitingFor      string // "permission" or "input" when status == StatusWaiting
 	everActive      bool   // true once the agent has been seen actively working
+	paneUnknown     bool   // tmux could not be queried on the last poll
 	exitCode        int
 	finishedAt      time.Time
 	lazygitPaneID   string // tracks the lazygit split pane
+	shellPaneID     string // tracks the scratch shell split pane
 	preReviewCommit string // HEAD hash before review started
 
-	// Merge cleanup preferences (set by merge wizard, read after conflict resolution)
+	// The bottom lines of the agent's pane when it started waiting, which
+	// show what it is waiting for
+	attentionReason []string
+
+	// Task typed into the agent's pane once it is ready for input, cleared
+	// when sent
+	pendingTask string
+
+	// The store holding the agent, told when its status or base branch
+	// changes, which can move it in a sorted list
+	store *Store
+
+	// Merge preferences (set by merge wizard, read after conflict resolution)
 	mergeDeleteBranch   bool
 	mergeRemoveWorktree bool
+	mergeSquashMessage  string
+	mergePush           bool
+
+	// Commits the branch has that its base doesn't and the other way
+	// round, as last counted by the orchestrator
+	ahead, behind    int
+	aheadBehindKnown bool
+
+	// Status a sync with base that left conflicts returns the agent to
+	// once they are resolved; empty if no sync is in progress
+	syncStatus Status
 
 	// Duration tracking: only counts time spent in StatusRunning.
 	accumulatedDuration time.Duration // total time accumulated in previous running periods
 	runningStartedAt    time.Time     // when the current running period started (zero if not running)
+	readyAt             time.Time     // when the agent last stopped running (zero while running)
 
 	// Claude Code session ID (persisted for conversation resumption)
 	sessionID string
@@ -60,6 +97,63 @@ type Agent struct {
 
 	// Claude Code todo/phase data (read from sidecar file)
 	todos []hook.TodoItem
+
+	// Claude Code agent team led by the agent, if any, and what each of
+	// its sessions has spent
+	team      *team.TeamInfo
+	teamCosts []SessionCost
+	// Teammates killed or restarted from mastermind, by name
+	teammateActions map[string]TeammateAction
+
+	// Where the monitor got the last status reading from ("stream",
+	// "hook" or "pane"), and the last hook status file it saw, fresh or not
+	statusSource string
+	hookStatus   *hook.StatusFile
+	heartbeat    time.Time // last hook event of any kind
+
+	// Pull request opened from the agent's branch, if any
+	pullRequest *PullRequest
+
+	// Whether the push guard lets the agent push its branch
+	allowPush bool
+
+	// Where the report of a report agent was collected to
+	reportPath string
+}
+
+// Pull request states.
+const (
+	PROpen   = "open"
+	PRMerged = "merged"
+	PRClosed = "closed"
+)
+
+// SessionCost is what one Claude Code session in an agent team has spent.
+type SessionCost struct {
+	Name    string // teammate name, or "lead"
+	CostUSD float64
+}
+
+// Teammate actions taken from mastermind.
+const (
+	TeammateKilled    = "killed"
+	TeammateRestarted = "restarted"
+)
+
+// TeammateAction records that mastermind killed or restarted a teammate's
+// pane, so the dashboard can show it before the team's own data catches up.
+type TeammateAction struct {
+	Action string // TeammateKilled or TeammateRestarted
+	At     time.Time
+}
+
+// PullRequest records a pull request opened from an agent's branch.
+type PullRequest struct {
+	Number    int      `json:"number"`
+	URL       string   `json:"url"`
+	State     string   `json:"state"`
+	Reviewers []string `json:"reviewers,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
 }
 
 func NewAgent(branch, baseBranch, worktreePath, tmuxWindow, tmuxPaneID string, harnessType harness.Type) *Agent {
@@ -88,6 +182,9 @@ func (a *Agent) SetStatus(s Status) {
 	defer a.mu.Unlock()
 	prev := a.status
 	a.status = s
+	if prev != s {
+		a.changed(ChangeStatus)
+	}
 
 	// Pause timer when leaving running state.
 	if prev == StatusRunning && s != StatusRunning {
@@ -95,11 +192,36 @@ func (a *Agent) SetStatus(s Status) {
 			a.accumulatedDuration += time.Since(a.runningStartedAt)
 			a.runningStartedAt = time.Time{}
 		}
+		a.readyAt = time.Now()
 	}
 
 	// Resume timer when entering running state.
 	if s == StatusRunning && prev != StatusRunning {
 		a.runningStartedAt = time.Now()
+		a.readyAt = time.Time{}
+	}
+}
+
+func (a *Agent) GetBaseBranch() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.BaseBranch
+}
+
+func (a *Agent) SetBaseBranch(branch string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.BaseBranch != branch {
+		a.changed(ChangeBaseBranch)
+	}
+	a.BaseBranch = branch
+}
+
+// changed tells the store holding the agent about a change. Callers hold
+// a.mu.
+func (a *Agent) changed(kind ChangeKind) {
+	if a.store != nil {
+		a.store.changed(Change{Kind: kind, AgentID: a.ID})
 	}
 }
 
@@ -115,6 +237,34 @@ func (a *Agent) SetWaitingFor(wf string) {
 	a.waitingFor = wf
 }
 
+// GetAttentionReason returns the pane lines captured when the agent
+// started waiting, or nil.
+func (a *Agent) GetAttentionReason() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.attentionReason
+}
+
+func (a *Agent) SetAttentionReason(lines []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.attentionReason = lines
+}
+
+// GetPendingTask returns the task still to be typed into the agent's pane
+// once it is ready for input, or "".
+func (a *Agent) GetPendingTask() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.pendingTask
+}
+
+func (a *Agent) SetPendingTask(task string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pendingTask = task
+}
+
 func (a *Agent) GetEverActive() bool {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -127,6 +277,20 @@ func (a *Agent) SetEverActive(v bool) {
 	a.everActive = v
 }
 
+// GetPaneUnknown reports whether the agent's pane state is unknown because
+// the last tmux query failed.
+func (a *Agent) GetPaneUnknown() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.paneUnknown
+}
+
+func (a *Agent) SetPaneUnknown(v bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.paneUnknown = v
+}
+
 func (a *Agent) GetExitCode() int {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -161,6 +325,18 @@ func (a *Agent) SetLazygitPaneID(id string) {
 	a.lazygitPaneID = id
 }
 
+func (a *Agent) GetShellPaneID() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.shellPaneID
+}
+
+func (a *Agent) SetShellPaneID(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.shellPaneID = id
+}
+
 func (a *Agent) GetPreReviewCommit() string {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -197,6 +373,63 @@ func (a *Agent) SetMergeRemoveWorktree(v bool) {
 	a.mergeRemoveWorktree = v
 }
 
+// GetMergeSquashMessage returns the commit message of the squash merge in
+// progress, or "" if the merge doesn't squash.
+func (a *Agent) GetMergeSquashMessage() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.mergeSquashMessage
+}
+
+func (a *Agent) SetMergeSquashMessage(msg string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mergeSquashMessage = msg
+}
+
+// GetMergePush reports whether base is pushed once the merge in progress
+// lands.
+func (a *Agent) GetMergePush() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.mergePush
+}
+
+func (a *Agent) SetMergePush(v bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mergePush = v
+}
+
+// GetSyncStatus returns the status the agent goes back to once the
+// conflicts of syncing it with base are resolved, or "" if its conflicts,
+// if any, are from a merge into base.
+func (a *Agent) GetSyncStatus() Status {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.syncStatus
+}
+
+func (a *Agent) SetSyncStatus(s Status) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.syncStatus = s
+}
+
+// GetAheadBehind returns how many commits the agent's branch is ahead of
+// and behind its base, as last counted; ok is false until they have been.
+func (a *Agent) GetAheadBehind() (ahead, behind int, ok bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.ahead, a.behind, a.aheadBehindKnown
+}
+
+func (a *Agent) SetAheadBehind(ahead, behind int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ahead, a.behind, a.aheadBehindKnown = ahead, behind, true
+}
+
 func (a *Agent) GetStatuslineData() *StatuslineData {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -237,6 +470,161 @@ func (a *Agent) SetTodos(todos []hook.TodoItem) {
 	a.todos = todos
 }
 
+// GetTeam returns the agent team the agent leads, or nil.
+func (a *Agent) GetTeam() *team.TeamInfo {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.team
+}
+
+func (a *Agent) SetTeam(info *team.TeamInfo) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.team = info
+}
+
+// GetTeamCosts returns what each session of the agent team the agent
+// leads has spent, lead first, or nil if it leads no team.
+func (a *Agent) GetTeamCosts() []SessionCost {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.teamCosts
+}
+
+func (a *Agent) SetTeamCosts(costs []SessionCost) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.teamCosts = costs
+}
+
+// GetTeammateAction returns the last action taken on the named teammate,
+// if any.
+func (a *Agent) GetTeammateAction(name string) (TeammateAction, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	act, ok := a.teammateActions[name]
+	return act, ok
+}
+
+func (a *Agent) SetTeammateAction(name string, act TeammateAction) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.teammateActions == nil {
+		a.teammateActions = make(map[string]TeammateAction)
+	}
+	a.teammateActions[name] = act
+}
+
+// TeamCostUSD returns what the agent and its teammates have spent
+// together, and false if there are no per-session costs to add up.
+func (a *Agent) TeamCostUSD() (float64, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.teamCosts) == 0 {
+		return 0, false
+	}
+	var total float64
+	for _, c := range a.teamCosts {
+		total += c.CostUSD
+	}
+	return total, true
+}
+
+// GetStatusSource returns the name of the status provider behind the
+// agent's last status reading, or "" if none had a reading.
+func (a *Agent) GetStatusSource() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.statusSource
+}
+
+func (a *Agent) SetStatusSource(source string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.statusSource = source
+}
+
+// GetHookStatus returns the last hook status file seen for the agent,
+// which may be stale, or nil.
+func (a *Agent) GetHookStatus() *hook.StatusFile {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.hookStatus
+}
+
+func (a *Agent) SetHookStatus(sf *hook.StatusFile) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hookStatus = sf
+}
+
+func (a *Agent) GetHeartbeat() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.heartbeat
+}
+
+func (a *Agent) SetHeartbeat(t time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.heartbeat = t
+}
+
+// SilentFor returns how long an agent whose last hook status said it was
+// working has sent no hook events at all. It is zero for an agent that
+// finished its turn and sits idle at the prompt, which is silent as
+// expected, and for agents without hooks. A long silence from a working
+// agent means Claude hung or crashed while its pane stayed open.
+func (a *Agent) SilentFor(now time.Time) time.Duration {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	switch a.status {
+	case StatusRunning, StatusWaiting, StatusReviewReady, StatusDone:
+	default:
+		return 0
+	}
+	if a.heartbeat.IsZero() || a.hookStatus == nil || a.hookStatus.Status != hook.StatusRunning {
+		return 0
+	}
+	return now.Sub(a.heartbeat)
+}
+
+func (a *Agent) GetPullRequest() *PullRequest {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.pullRequest
+}
+
+func (a *Agent) SetPullRequest(pr *PullRequest) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pullRequest = pr
+}
+
+func (a *Agent) GetAllowPush() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.allowPush
+}
+
+func (a *Agent) SetAllowPush(allow bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.allowPush = allow
+}
+
+func (a *Agent) GetReportPath() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.reportPath
+}
+
+func (a *Agent) SetReportPath(path string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reportPath = path
+}
+
 func (a *Agent) Duration() time.Duration {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -253,6 +641,20 @@ func (a *Agent) GetAccumulatedDuration() time.Duration {
 	return a.accumulatedDuration
 }
 
+// GetReadyAt returns when the agent last stopped running, e.g. finished
+// its task or began waiting on the user. It is zero while the agent runs.
+func (a *Agent) GetReadyAt() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.readyAt
+}
+
+func (a *Agent) SetReadyAt(t time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.readyAt = t
+}
+
 func (a *Agent) GetRunningStartedAt() time.Time {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -263,18 +665,26 @@ func (a *Agent) GetRunningStartedAt() time.Time {
 type AgentSnapshot struct {
 	Status              Status
 	WaitingFor          string
+	AttentionReason     []string
+	PendingTask         string
 	EverActive          bool
+	PaneUnknown         bool
 	ExitCode            int
 	FinishedAt          time.Time
 	LazygitPaneID       string
+	ShellPaneID         string
 	PreReviewCommit     string
 	SessionID           string
 	AccumulatedDuration time.Duration
 	RunningStartedAt    time.Time
+	ReadyAt             time.Time
 	StatuslineData      *StatuslineData
 	MergeDeleteBranch   bool
 	MergeRemoveWorktree bool
 	Todos               []hook.TodoItem
+	PullRequest         *PullRequest
+	AllowPush           bool
+	ReportPath          string
 }
 
 // Snapshot reads all mutable fields under a single lock acquisition.
@@ -284,18 +694,26 @@ func (a *Agent) Snapshot() AgentSnapshot {
 	return AgentSnapshot{
 		Status:              a.status,
 		WaitingFor:          a.waitingFor,
+		AttentionReason:     a.attentionReason,
+		PendingTask:         a.pendingTask,
 		EverActive:          a.everActive,
+		PaneUnknown:         a.paneUnknown,
 		ExitCode:            a.exitCode,
 		FinishedAt:          a.finishedAt,
 		LazygitPaneID:       a.lazygitPaneID,
+		ShellPaneID:         a.shellPaneID,
 		PreReviewCommit:     a.preReviewCommit,
 		SessionID:           a.sessionID,
 		AccumulatedDuration: a.accumulatedDuration,
 		RunningStartedAt:    a.runningStartedAt,
+		ReadyAt:             a.readyAt,
 		StatuslineData:      a.statuslineData,
 		MergeDeleteBranch:   a.mergeDeleteBranch,
 		MergeRemoveWorktree: a.mergeRemoveWorktree,
 		Todos:               a.todos,
+		PullRequest:         a.pullRequest,
+		AllowPush:           a.allowPush,
+		ReportPath:          a.reportPath,
 	}
 }
 
@@ -190,6 +190,53 @@ func TestAgent_Duration_Finished(t *testing.T) {
 	}
 }
 
+func TestAgent_ReadyAt(t *testing.T) {
+	a := NewAgent("b", "main", "/wt", "@1", "%0", "claude")
+	if !a.GetReadyAt().IsZero() {
+		t.Fatal("a running agent should not be ready")
+	}
+
+	before := time.Now()
+	a.SetStatus(StatusWaiting)
+	ready := a.GetReadyAt()
+	if ready.Before(before) {
+		t.Errorf("ReadyAt = %v, want when it stopped running", ready)
+	}
+	a.SetStatus(StatusReviewReady)
+	if !a.GetReadyAt().Equal(ready) {
+		t.Error("ReadyAt should not move between idle states")
+	}
+
+	a.SetStatus(StatusRunning)
+	if !a.GetReadyAt().IsZero() {
+		t.Error("ReadyAt should reset when the agent runs again")
+	}
+}
+
+func TestAgent_SilentFor(t *testing.T) {
+	now := time.Now()
+	a := NewAgent("b", "main", "/wt", "@1", "%0", "claude")
+	if d := a.SilentFor(now); d != 0 {
+		t.Errorf("without hooks: SilentFor = %v, want 0", d)
+	}
+
+	a.SetHeartbeat(now.Add(-10 * time.Minute))
+	a.SetHookStatus(&hook.StatusFile{Status: hook.StatusRunning})
+	if d := a.SilentFor(now); d != 10*time.Minute {
+		t.Errorf("working: SilentFor = %v, want 10m", d)
+	}
+	// Pane polling sees a stable pane, but Claude never reported stopping.
+	a.SetStatus(StatusReviewReady)
+	if d := a.SilentFor(now); d != 10*time.Minute {
+		t.Errorf("idle pane after running hook: SilentFor = %v, want 10m", d)
+	}
+
+	a.SetHookStatus(&hook.StatusFile{Status: hook.StatusIdle})
+	if d := a.SilentFor(now); d != 0 {
+		t.Errorf("idle at prompt: SilentFor = %v, want 0", d)
+	}
+}
+
 func TestAgent_Snapshot(t *testing.T) {
 	a := NewAgent("feat/snap", "main", "/tmp/wt", "@1", "%0", "claude")
 	a.SetStatus(StatusWaiting)
@@ -197,6 +244,7 @@ func TestAgent_Snapshot(t *testing.T) {
 	a.SetEverActive(true)
 	a.SetFinished(1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
 	a.SetLazygitPaneID("%5")
+	a.SetShellPaneID("%6")
 	a.SetPreReviewCommit("abc123")
 	a.SetTodos([]hook.TodoItem{
 		{ID: "t1", Content: "Write tests", Status: "pending"},
@@ -223,6 +271,9 @@ func TestAgent_Snapshot(t *testing.T) {
 	if snap.LazygitPaneID != "%5" {
 		t.Errorf("Snapshot().LazygitPaneID = %q, want %%5", snap.LazygitPaneID)
 	}
+	if snap.ShellPaneID != "%6" {
+		t.Errorf("Snapshot().ShellPaneID = %q, want %%6", snap.ShellPaneID)
+	}
 	if snap.PreReviewCommit != "abc123" {
 		t.Errorf("Snapshot().PreReviewCommit = %q, want %q", snap.PreReviewCommit, "abc123")
 	}
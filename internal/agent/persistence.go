@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/simonbystrom/mastermind/internal/harness"
@@ -17,50 +18,92 @@ type PersistedAgent struct {
 	WorktreePath        string        `json:"worktree_path"`
 	TmuxWindow          string        `json:"tmux_window"`
 	TmuxPaneID          string        `json:"tmux_pane_id"`
-	Harness             harness.Type  `json:"harness,omitempty"` // "claude" or "opencode"
+	Harness             harness.Type  `json:"harness,omitempty"` // "claude", "opencode" or a custom name
+	Ticket              string        `json:"ticket,omitempty"`
+	Prompt              string        `json:"prompt,omitempty"`
+	ReadOnly            bool          `json:"read_only,omitempty"`
+	ReportFile          string        `json:"report_file,omitempty"`
+	Playbook            string        `json:"playbook,omitempty"`
+	Team                string        `json:"team,omitempty"`
 	Status              Status        `json:"status"`
 	WaitingFor          string        `json:"waiting_for"`
+	AttentionReason     []string      `json:"attention_reason,omitempty"`
+	PendingTask         string        `json:"pending_task,omitempty"`
 	EverActive          bool          `json:"ever_active"`
 	ExitCode            int           `json:"exit_code"`
 	StartedAt           time.Time     `json:"started_at"`
 	FinishedAt          time.Time     `json:"finished_at"`
 	LazygitPaneID       string        `json:"lazygit_pane_id,omitempty"`
+	ShellPaneID         string        `json:"shell_pane_id,omitempty"`
 	PreReviewCommit     string        `json:"pre_review_commit,omitempty"`
 	SessionID           string        `json:"session_id,omitempty"`
 	AccumulatedDuration time.Duration `json:"accumulated_duration"`
 	RunningStartedAt    time.Time     `json:"running_started_at"`
+	ReadyAt             time.Time     `json:"ready_at"`
+	PullRequest         *PullRequest  `json:"pull_request,omitempty"`
+	AllowPush           bool          `json:"allow_push,omitempty"`
+	ReportPath          string        `json:"report_path,omitempty"`
 }
 
-// SaveState atomically writes agent state to a JSON file.
-func SaveState(path string, agents []*Agent) error {
+// Persisted returns the agent's persistable state.
+func (a *Agent) Persisted() PersistedAgent {
+	snap := a.Snapshot()
+	return PersistedAgent{
+		ID:                  a.ID,
+		Branch:              a.Branch,
+		BaseBranch:          a.GetBaseBranch(),
+		WorktreePath:        a.WorktreePath,
+		TmuxWindow:          a.TmuxWindow,
+		TmuxPaneID:          a.TmuxPaneID,
+		Harness:             a.Harness,
+		Ticket:              a.Ticket,
+		Prompt:              a.Prompt,
+		ReadOnly:            a.ReadOnly,
+		ReportFile:          a.ReportFile,
+		Playbook:            a.Playbook,
+		Team:                a.Team,
+		Status:              snap.Status,
+		WaitingFor:          snap.WaitingFor,
+		AttentionReason:     snap.AttentionReason,
+		PendingTask:         snap.PendingTask,
+		EverActive:          snap.EverActive,
+		ExitCode:            snap.ExitCode,
+		StartedAt:           a.StartedAt,
+		FinishedAt:          snap.FinishedAt,
+		LazygitPaneID:       snap.LazygitPaneID,
+		ShellPaneID:         snap.ShellPaneID,
+		PreReviewCommit:     snap.PreReviewCommit,
+		SessionID:           snap.SessionID,
+		AccumulatedDuration: snap.AccumulatedDuration,
+		RunningStartedAt:    snap.RunningStartedAt,
+		ReadyAt:             snap.ReadyAt,
+		PullRequest:         snap.PullRequest,
+		AllowPush:           snap.AllowPush,
+		ReportPath:          snap.ReportPath,
+	}
+}
+
+// MarshalState encodes agent state as written by SaveState. Agents are
+// ordered by ID so equal state always encodes to equal bytes.
+func MarshalState(agents []*Agent) ([]byte, error) {
 	persisted := make([]PersistedAgent, len(agents))
 	for i, a := range agents {
-		snap := a.Snapshot()
-		persisted[i] = PersistedAgent{
-			ID:                  a.ID,
-			Branch:              a.Branch,
-			BaseBranch:          a.BaseBranch,
-			WorktreePath:        a.WorktreePath,
-			TmuxWindow:          a.TmuxWindow,
-			TmuxPaneID:          a.TmuxPaneID,
-			Harness:             a.Harness,
-			Status:              snap.Status,
-			WaitingFor:          snap.WaitingFor,
-			EverActive:          snap.EverActive,
-			ExitCode:            snap.ExitCode,
-			StartedAt:           a.StartedAt,
-			FinishedAt:          snap.FinishedAt,
-			LazygitPaneID:       snap.LazygitPaneID,
-			PreReviewCommit:     snap.PreReviewCommit,
-			SessionID:           snap.SessionID,
-			AccumulatedDuration: snap.AccumulatedDuration,
-			RunningStartedAt:    snap.RunningStartedAt,
-		}
+		persisted[i] = a.Persisted()
 	}
+	sort.Slice(persisted, func(i, j int) bool { return persisted[i].ID < persisted[j].ID })
 
 	data, err := json.Marshal(persisted)
 	if err != nil {
-		return fmt.Errorf("marshal state: %w", err)
+		return nil, fmt.Errorf("marshal state: %w", err)
+	}
+	return data, nil
+}
+
+// SaveState atomically writes agent state to a JSON file.
+func SaveState(path string, agents []*Agent) error {
+	data, err := MarshalState(agents)
+	if err != nil {
+		return err
 	}
 
 	tmpPath := path + ".tmp"
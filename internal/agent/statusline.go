@@ -4,8 +4,15 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// SessionStatuslineDir is where the statusline script keeps the last
+// statusline JSON of every Claude Code session in a worktree, named
+// <session ID>.json. An agent team's teammates share the lead's worktree,
+// so this is where their costs are read from.
+var SessionStatuslineDir = filepath.Join(".claude", "mastermind-status")
+
 // StatuslineData holds parsed fields from Claude Code's statusline JSON.
 type StatuslineData struct {
 	Model          string
@@ -15,6 +22,8 @@ type StatuslineData struct {
 	LinesRemoved   int
 	DurationMs     int64
 	SessionID      string
+	InputTokens    int
+	OutputTokens   int
 }
 
 // statuslineJSON mirrors the nested structure of Claude Code's statusline output.
@@ -37,7 +46,40 @@ type statuslineJSON struct {
 // ReadStatuslineFile reads and parses the .claude-status.json sidecar file
 // from the given worktree path.
 func ReadStatuslineFile(worktreePath string) (*StatuslineData, error) {
-	data, err := os.ReadFile(filepath.Join(worktreePath, ".claude-status.json"))
+	return parseStatuslineFile(filepath.Join(worktreePath, ".claude-status.json"))
+}
+
+// ReadSessionStatuslines reads the statusline data of every session that
+// ran in the worktree from SessionStatuslineDir. Unreadable files are
+// skipped; a missing directory means no sessions.
+func ReadSessionStatuslines(worktreePath string) ([]*StatuslineData, error) {
+	dir := filepath.Join(worktreePath, SessionStatuslineDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sessions []*StatuslineData
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		sd, err := parseStatuslineFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		if sd.SessionID == "" {
+			sd.SessionID = strings.TrimSuffix(e.Name(), ".json")
+		}
+		sessions = append(sessions, sd)
+	}
+	return sessions, nil
+}
+
+func parseStatuslineFile(path string) (*StatuslineData, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
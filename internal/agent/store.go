@@ -2,15 +2,53 @@ package agent
 
 import (
 	"fmt"
+	"log/slog"
 	"sync"
 	"sync/atomic"
 )
 
+// ChangeKind says how the agents in a store changed.
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeStatus
+	ChangeBaseBranch
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeStatus:
+		return "status"
+	case ChangeBaseBranch:
+		return "base branch"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is published to the store's subscribers when an agent is added
+// or removed, or changes status or base branch.
+type Change struct {
+	Kind    ChangeKind
+	AgentID string
+}
+
 type Store struct {
 	mu     sync.RWMutex
 	agents map[string]*Agent
 	nextID atomic.Int64
 	dirty  atomic.Bool
+	// version counts changes that can reorder a sorted list of agents:
+	// agents added or removed, and changes of status or base branch.
+	version atomic.Uint64
+
+	subsMu sync.Mutex
+	subs   []chan Change
 }
 
 func NewStore() *Store {
@@ -25,9 +63,25 @@ func (s *Store) Add(a *Agent) {
 	if a.ID == "" {
 		id := s.nextID.Add(1)
 		a.ID = fmt.Sprintf("a%d", id)
+	} else {
+		// Keep generated IDs clear of agents added with an explicit ID,
+		// e.g. recovered from the state file.
+		var n int64
+		if _, err := fmt.Sscanf(a.ID, "a%d", &n); err == nil {
+			for {
+				cur := s.nextID.Load()
+				if cur >= n || s.nextID.CompareAndSwap(cur, n) {
+					break
+				}
+			}
+		}
 	}
+	a.mu.Lock()
+	a.store = s
+	a.mu.Unlock()
 	s.agents[a.ID] = a
 	s.dirty.Store(true)
+	s.changed(Change{Kind: ChangeAdded, AgentID: a.ID})
 }
 
 // MarkDirty marks the store as having unsaved changes.
@@ -62,6 +116,20 @@ func (s *Store) All() []*Agent {
 	return result
 }
 
+// StackedOn returns the agents whose base branch is branch, i.e. the
+// agents stacked on top of the agent working on branch.
+func (s *Store) StackedOn(branch string) []*Agent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []*Agent
+	for _, a := range s.agents {
+		if branch != "" && a.GetBaseBranch() == branch {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
 func (s *Store) UpdateStatus(id string, status Status) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -77,6 +145,60 @@ func (s *Store) UpdateStatus(id string, status Status) bool {
 func (s *Store) Remove(id string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	a, ok := s.agents[id]
+	if !ok {
+		return
+	}
 	delete(s.agents, id)
 	s.dirty.Store(true)
+	s.changed(Change{Kind: ChangeRemoved, AgentID: id})
+	a.mu.Lock()
+	a.store = nil
+	a.mu.Unlock()
+}
+
+// Version returns a number that changes whenever agents are added or
+// removed, or change status or base branch, so a sorted list of the
+// agents can be kept until it does.
+func (s *Store) Version() uint64 {
+	return s.version.Load()
+}
+
+// Subscribe returns a channel receiving every change after the call.
+// Publishing never blocks: a subscriber whose buffer is full misses the
+// change, which it can make up for by reading the store.
+func (s *Store) Subscribe(buffer int) <-chan Change {
+	ch := make(chan Change, buffer)
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	s.subs = append(s.subs, ch)
+	return ch
+}
+
+// Unsubscribe stops delivery to ch, a channel returned by Subscribe, and
+// closes it.
+func (s *Store) Unsubscribe(ch <-chan Change) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	for i, sub := range s.subs {
+		if sub == ch {
+			s.subs = append(s.subs[:i], s.subs[i+1:]...)
+			close(sub)
+			return
+		}
+	}
+}
+
+// changed bumps the version and publishes c to the subscribers.
+func (s *Store) changed(c Change) {
+	s.version.Add(1)
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	for _, ch := range s.subs {
+		select {
+		case ch <- c:
+		default:
+			slog.Debug("store change dropped, subscriber full", "agent", c.AgentID, "change", c.Kind)
+		}
+	}
 }
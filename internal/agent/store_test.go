@@ -47,6 +47,19 @@ func TestStore_Add_PresetID(t *testing.T) {
 	}
 }
 
+func TestStore_Add_AutoIDSkipsRecoveredIDs(t *testing.T) {
+	s := NewStore()
+	recovered := NewAgent("b1", "main", "/wt1", "@1", "%0", "claude")
+	recovered.ID = "a3"
+	s.Add(recovered)
+
+	a := NewAgent("b2", "main", "/wt2", "@2", "%1", "claude")
+	s.Add(a)
+	if a.ID != "a4" {
+		t.Errorf("auto ID after recovered a3 = %q, want %q", a.ID, "a4")
+	}
+}
+
 func TestStore_Get_NotFound(t *testing.T) {
 	s := NewStore()
 	_, ok := s.Get("nonexistent")
@@ -76,6 +89,24 @@ func TestStore_All_Empty(t *testing.T) {
 	}
 }
 
+func TestStore_StackedOn(t *testing.T) {
+	s := NewStore()
+	parent := NewAgent("feat/a", "main", "/wt/a", "@1", "%0", "claude")
+	child := NewAgent("feat/b", "feat/a", "/wt/b", "@2", "%1", "claude")
+	other := NewAgent("feat/c", "main", "/wt/c", "@3", "%2", "claude")
+	s.Add(parent)
+	s.Add(child)
+	s.Add(other)
+
+	stacked := s.StackedOn("feat/a")
+	if len(stacked) != 1 || stacked[0] != child {
+		t.Errorf("StackedOn(feat/a) = %v, want [child]", stacked)
+	}
+	if len(s.StackedOn("feat/b")) != 0 {
+		t.Error("nothing is stacked on feat/b")
+	}
+}
+
 func TestStore_UpdateStatus(t *testing.T) {
 	s := NewStore()
 	a := NewAgent("feat/x", "main", "/wt", "@1", "%0", "claude")
@@ -131,3 +162,76 @@ func TestStore_ConcurrentAccess(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestStore_Version(t *testing.T) {
+	s := NewStore()
+	a := NewAgent("b", "main", "/wt", "@1", "%0", "claude")
+
+	v := s.Version()
+	s.Add(a)
+	if s.Version() == v {
+		t.Error("Add should change the version")
+	}
+
+	v = s.Version()
+	a.SetStatus(a.GetStatus())
+	a.SetWaitingFor("input")
+	if s.Version() != v {
+		t.Error("changes that don't reorder agents should keep the version")
+	}
+	for _, c := range []struct {
+		name   string
+		change func()
+	}{
+		{"SetStatus", func() { a.SetStatus(StatusDone) }},
+		{"SetBaseBranch", func() { a.SetBaseBranch("develop") }},
+		{"Remove", func() { s.Remove(a.ID) }},
+	} {
+		v = s.Version()
+		c.change()
+		if s.Version() == v {
+			t.Errorf("%s should change the version", c.name)
+		}
+	}
+}
+
+func TestStore_Subscribe(t *testing.T) {
+	s := NewStore()
+	changes := s.Subscribe(16)
+	a := NewAgent("b", "main", "/wt", "@1", "%0", "claude")
+
+	s.Add(a)
+	a.SetStatus(a.GetStatus())
+	a.SetStatus(StatusDone)
+	a.SetBaseBranch("develop")
+	s.Remove(a.ID)
+	a.SetStatus(StatusRunning) // no longer in the store
+
+	want := []Change{
+		{ChangeAdded, a.ID},
+		{ChangeStatus, a.ID},
+		{ChangeBaseBranch, a.ID},
+		{ChangeRemoved, a.ID},
+	}
+	for _, w := range want {
+		select {
+		case got := <-changes:
+			if got != w {
+				t.Errorf("change = %+v, want %+v", got, w)
+			}
+		default:
+			t.Fatalf("missing change %+v", w)
+		}
+	}
+	select {
+	case got := <-changes:
+		t.Errorf("unexpected change %+v", got)
+	default:
+	}
+
+	s.Unsubscribe(changes)
+	if _, ok := <-changes; ok {
+		t.Error("Unsubscribe should close the channel")
+	}
+	s.Add(NewAgent("c", "main", "/wt2", "@2", "%1", "claude"))
+}
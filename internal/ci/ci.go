@@ -0,0 +1,84 @@
+// Package ci reads failing GitHub Actions runs through the gh CLI so an
+// agent can be spawned to fix them.
+package ci
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogFileName holds the full failure log in the agent's worktree.
+const LogFileName = ".mastermind-ci.log"
+
+// Run is a workflow run as reported by `gh run list`.
+type Run struct {
+	ID        int64     `json:"databaseId"`
+	Title     string    `json:"displayTitle"`
+	Branch    string    `json:"headBranch"`
+	Workflow  string    `json:"workflowName"`
+	CreatedAt time.Time `json:"createdAt"`
+	URL       string    `json:"url"`
+}
+
+// ListFailedRuns returns the most recent failed workflow runs for the
+// repository at repoPath.
+func ListFailedRuns(repoPath string, limit int) ([]Run, error) {
+	cmd := exec.Command("gh", "run", "list",
+		"--status", "failure",
+		"--limit", strconv.Itoa(limit),
+		"--json", "databaseId,displayTitle,headBranch,workflowName,createdAt,url")
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gh run list: %w", ghError(err))
+	}
+	return parseRuns(out)
+}
+
+func parseRuns(data []byte) ([]Run, error) {
+	var runs []Run
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("parse runs: %w", err)
+	}
+	return runs, nil
+}
+
+// FailedLogs returns the logs of the failed steps of a run.
+func FailedLogs(repoPath string, runID int64) (string, error) {
+	cmd := exec.Command("gh", "run", "view", strconv.FormatInt(runID, 10), "--log-failed")
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("gh run view: %w", ghError(err))
+	}
+	return string(out), nil
+}
+
+// ghError folds gh's stderr into the error so failures like missing auth
+// are visible.
+func ghError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%s (%w)", strings.TrimSpace(string(exitErr.Stderr)), err)
+	}
+	return err
+}
+
+// Prompt builds the agent's task for fixing run. The failure log is not
+// inlined, as the prompt ends up on the agent's command line; the agent
+// is pointed at LogFileName instead.
+func Prompt(run Run) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The CI workflow %q failed on branch %s (run %d: %s).\n", run.Workflow, run.Branch, run.ID, run.Title)
+	if run.URL != "" {
+		fmt.Fprintf(&b, "Run: %s\n", run.URL)
+	}
+	fmt.Fprintf(&b, "The failure log is in %s; read it first.\n\n", LogFileName)
+	b.WriteString("Find the cause of the failure, fix it, and verify the fix locally where possible.")
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package ci
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRuns(t *testing.T) {
+	data := `[{"databaseId":42,"displayTitle":"Fix parser","headBranch":"feat/parser","workflowName":"CI","createdAt":"2025-01-02T03:04:05Z","url":"https://example.com/runs/42"}]`
+
+	runs, err := parseRuns([]byte(data))
+	if err != nil {
+		t.Fatalf("parseRuns: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(runs))
+	}
+	r := runs[0]
+	if r.ID != 42 || r.Branch != "feat/parser" || r.Workflow != "CI" || r.Title != "Fix parser" {
+		t.Errorf("run = %+v", r)
+	}
+	if r.CreatedAt.Year() != 2025 {
+		t.Errorf("createdAt = %v", r.CreatedAt)
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	run := Run{ID: 7, Branch: "feat/x", Workflow: "Tests", Title: "Add x"}
+
+	p := Prompt(run)
+
+	for _, want := range []string{"feat/x", `"Tests"`, LogFileName} {
+		if !strings.Contains(p, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 
@@ -43,6 +45,37 @@ type Layout struct {
 	LazygitSplit   int `toml:"lazygit_split"`
 }
 
+// Format holds how numbers and times are shown.
+type Format struct {
+	// Locale picks the decimal and grouping separators of costs, e.g.
+	// "de-DE". Empty uses LC_ALL, LC_NUMERIC or LANG.
+	Locale string `toml:"locale"`
+	// Clock24h shows times as 15:04 rather than 3:04PM.
+	Clock24h bool `toml:"clock_24h"`
+}
+
+// Instance tells mastermind instances running side by side apart, e.g.
+// one per repository.
+type Instance struct {
+	// Name is shown in the dashboard title and names the tmux window of
+	// the TUI. Empty keeps the window's name.
+	Name string `toml:"name"`
+	// Accent replaces the logo, title and border colors.
+	Accent string `toml:"accent"`
+}
+
+// Accessibility holds settings that make the TUI usable with a terminal
+// screen reader.
+type Accessibility struct {
+	// ScreenReader replaces box drawing and symbols with plain text, marks
+	// the selected row with ">" rather than only a background color, runs
+	// the TUI without the alternate screen and prints every notification,
+	// status changes included, as a line of its own.
+	ScreenReader bool `toml:"screen_reader"`
+	// RowSpacing is the number of blank lines between agent rows.
+	RowSpacing int `toml:"row_spacing"`
+}
+
 // Claude holds settings for Claude Code agent behavior.
 type Claude struct {
 	AgentTeams       bool   `toml:"agent_teams"`
@@ -50,26 +83,284 @@ type Claude struct {
 	SkipPermissions  bool   `toml:"skip_permissions"`
 	PromptEditor     bool   `toml:"prompt_editor"`
 	PromptEditorSize int    `toml:"prompt_editor_size"`
+	StreamJSON       bool   `toml:"stream_json"`
+	// StatuslineScript renders agents' statuslines instead of mastermind's
+	// built-in one. It is run through the shell with the statusline JSON
+	// on stdin, after mastermind saved its metrics sidecar.
+	StatuslineScript string `toml:"statusline_script"`
 }
 
 // Harness holds settings for the AI assistant harness selection.
 type Harness struct {
-	Default string `toml:"default"` // "claude" or "opencode"
+	Default string `toml:"default"` // "claude", "opencode" or the name of an agent command
+	// Agents are agent CLIs other than Claude Code and OpenCode, e.g.
+	// aider or codex, offered next to them.
+	Agents []AgentCommand `toml:"agents"`
+}
+
+// AgentCommand is an agent CLI configured as a [[harness.agents]] table.
+// It writes no status files, so its status is read from its pane.
+type AgentCommand struct {
+	Name       string   `toml:"name"`        // harness name, e.g. "aider"
+	Command    string   `toml:"command"`     // executable to run
+	Args       []string `toml:"args"`        // arguments always passed
+	PromptFlag string   `toml:"prompt_flag"` // flag an initial prompt is passed with; empty appends it
+	// InputPatterns and PermissionPatterns are regular expressions
+	// matched against the bottom lines of the agent's pane; a match means
+	// it waits for input or for permission. Otherwise it is working.
+	InputPatterns      []string `toml:"input_patterns"`
+	PermissionPatterns []string `toml:"permission_patterns"`
 }
 
 // Notifications holds settings for OS-level notifications.
 type Notifications struct {
 	Enabled bool   `toml:"enabled"` // send macOS notifications on attention events
 	Sound   string `toml:"sound"`   // macOS system sound name (Glass, Ping, Pop, Tink, etc.)
+	// TmuxBell rings the bell in the mastermind window on attention events
+	// so tmux's status line flags it while another window is active.
+	TmuxBell bool `toml:"tmux_bell"`
+	// MarkWindows prefixes agent window names with "❗ " while the agent
+	// waits for permission.
+	MarkWindows bool `toml:"mark_windows"`
+	// WindowStatus keeps every agent window name prefixed with a glyph for
+	// the agent's status, e.g. "✻ feat-auth" or "✔ feat-auth".
+	WindowStatus bool `toml:"window_status"`
+}
+
+// Monitor holds settings for agent status detection.
+type Monitor struct {
+	// Providers lists status providers in priority order. The first
+	// provider with a usable reading wins. Known: "stream", "hook", "pane";
+	// the defaults are used if none of the names is known.
+	Providers []string `toml:"providers"`
+	// Shim launches agents through `mastermind shim` to record exit codes.
+	Shim bool `toml:"shim"`
+	// GoneAfter is how many consecutive polls (2s apart) an agent's pane
+	// must be missing before the agent is treated as closed, so a tmux
+	// server restart does not dismiss every agent.
+	GoneAfter int `toml:"gone_after"`
+	// NoSignalMinutes is how long an agent that was working may send no
+	// hook events before the dashboard shows "no signal". 0 turns it off.
+	NoSignalMinutes int `toml:"no_signal_minutes"`
+}
+
+// Tmux holds settings for talking to the tmux server.
+type Tmux struct {
+	// Retries is how many times a failed tmux query is retried before the
+	// error is reported. 0 disables retrying.
+	Retries int `toml:"retries"`
+	// RetryBackoffMS is the wait before the first retry in milliseconds.
+	// It doubles after each retry.
+	RetryBackoffMS int `toml:"retry_backoff_ms"`
+	// Sessions are offered in the spawn wizard alongside the existing tmux
+	// sessions, e.g. a "noise" session for low-priority agents. They are
+	// created on first use.
+	Sessions []string `toml:"sessions"`
+}
+
+// Web holds settings for the optional web dashboard.
+type Web struct {
+	// Listen is the address the web dashboard is served on, e.g.
+	// "127.0.0.1:8765", or "0.0.0.0:8765" with a Token to reach it from a
+	// phone. A bare ":8765" listens on 127.0.0.1. Empty disables it.
+	Listen string `toml:"listen"`
+	// Token, when set, must be given as ?token= or a bearer token to view
+	// the dashboard.
+	Token string `toml:"token"`
+}
+
+// Slack holds settings for the optional Slack app integration, served on
+// the web dashboard's address.
+type Slack struct {
+	// SigningSecret verifies requests from Slack to /slack/commands and
+	// /slack/actions. Empty disables the endpoints.
+	SigningSecret string `toml:"signing_secret"`
+	// BotToken and Channel enable posting attention events, with action
+	// buttons, to a channel.
+	BotToken string `toml:"bot_token"`
+	Channel  string `toml:"channel"`
+}
+
+// Forge holds settings for the git hosting provider.
+type Forge struct {
+	// Provider is "github", "gitlab", "gitea" or "bitbucket". Empty
+	// detects it from the origin remote's host.
+	Provider string `toml:"provider"`
+	// URL is the web URL of a self-hosted Gitea. Empty uses the origin
+	// remote's host.
+	URL string `toml:"url"`
+	// Token is a Gitea access token or a Bitbucket app password. GitHub
+	// and GitLab use the gh and glab logins instead.
+	Token string `toml:"token"`
+	// Username goes with a Bitbucket app password.
+	Username string `toml:"username"`
+}
+
+// PullRequests holds defaults for pull requests opened from agent
+// branches.
+type PullRequests struct {
+	// Reviewers are always asked to review.
+	Reviewers []string `toml:"reviewers"`
+	// Codeowners also asks the CODEOWNERS owners of the changed files.
+	Codeowners bool `toml:"codeowners"`
+	// Labels are applied to every pull request.
+	Labels []string `toml:"labels"`
+	// PollSeconds is how often finished agents' pull requests are checked
+	// for merges on the hosting provider. 0 disables the check.
+	PollSeconds int `toml:"poll_seconds"`
+	// AutoDismiss dismisses an agent and deletes its branch once its pull
+	// request is merged, instead of offering to.
+	AutoDismiss bool `toml:"auto_dismiss"`
+}
+
+// Tickets holds settings for linking agents to Linear or Jira tickets.
+type Tickets struct {
+	// Provider is "linear" or "jira" to look up ticket titles and move
+	// tickets between states. Empty still links ticket IDs to agents.
+	Provider string `toml:"provider"`
+	// Token is a Linear API key or a Jira API token.
+	Token string `toml:"token"`
+	// JiraURL and JiraEmail identify the Jira site and the account the
+	// token belongs to.
+	JiraURL   string `toml:"jira_url"`
+	JiraEmail string `toml:"jira_email"`
+	// InProgress and InReview are the states a ticket moves to when its
+	// agent is spawned and when it is ready for review. Empty skips the
+	// transition.
+	InProgress string `toml:"in_progress"`
+	InReview   string `toml:"in_review"`
+}
+
+// Pane is an extra pane opened next to the agent in every agent window.
+type Pane struct {
+	Command string `toml:"command"` // run through the shell; empty opens a shell
+	Split   string `toml:"split"`   // "right" (default) or "below" the agent pane
+	Size    int    `toml:"size"`    // percentage of the agent pane to take, default 30
+}
+
+// Git holds settings for the git repositories agents work in.
+type Git struct {
+	// PushGuard installs a pre-push hook that blocks pushes from agent
+	// worktrees unless allowed per agent.
+	PushGuard bool `toml:"push_guard"`
+	// Rerere merges agent branches with git's rerere so conflict
+	// resolutions are remembered and reused across agents.
+	Rerere bool `toml:"rerere"`
+	// ArchiveBranches moves agent branches to
+	// refs/mastermind/archive/<date>/<branch> instead of deleting them.
+	ArchiveBranches bool `toml:"archive_branches"`
+	// BackupRetentionDays is how long the uncommitted changes backed up
+	// to .worktrees/backups before a worktree is removed or a preview
+	// discards them are kept. 0 takes no backups.
+	BackupRetentionDays int `toml:"backup_retention_days"`
+	// FetchBase fetches the base branch and fast-forwards it to origin's
+	// before an agent's branch is created from it.
+	FetchBase bool `toml:"fetch_base"`
+}
+
+// Preview holds settings for previewing an agent's changes.
+type Preview struct {
+	// DiffOnly makes p show the diff-only preview, which reads the
+	// agent's worktree, instead of checking its changes out in the main
+	// worktree.
+	DiffOnly bool `toml:"diff_only"`
+}
+
+// Merge holds the defaults of the merge wizard's cleanup options.
+type Merge struct {
+	DeleteBranch   bool `toml:"delete_branch"`
+	RemoveWorktree bool `toml:"remove_worktree"`
+	// Remember makes the choices last made in the wizard the defaults for
+	// the repository, overriding the two above.
+	Remember bool `toml:"remember"`
+	// Retries is how many times base is merged into the agent's branch
+	// again when it moves before it can be fast-forwarded.
+	Retries int `toml:"retries"`
+	// LargeConflictKB is the size above which a conflicted file is marked
+	// large in the conflicts view. Zero marks none.
+	LargeConflictKB int `toml:"large_conflict_kb"`
+	// Editor opens a conflicted file from the conflicts view; {file} is
+	// replaced by its path and {line} by the line of its first conflict
+	// marker. Empty uses $VISUAL or $EDITOR with "+{line} {file}".
+	Editor string `toml:"editor"`
+	// OpenLazygitOnConflict opens lazygit as soon as a merge reports
+	// conflicts, in place of the conflicts view.
+	OpenLazygitOnConflict bool `toml:"open_lazygit_on_conflict"`
+	// Strategy is how the wizard merges by default: "merge" creates a
+	// merge commit, "rebase" rebases the branch onto base and
+	// fast-forwards base to it, "squash" collapses the branch into one
+	// commit on base.
+	Strategy string `toml:"strategy"`
+	// Push pushes the base branch to its upstream after a merge.
+	Push bool `toml:"push"`
+}
+
+// Agents holds limits that apply to all agents.
+type Agents struct {
+	// MaxRunning caps how many agents may be running or waiting on the
+	// user at once; spawning beyond it fails. Zero means no cap.
+	MaxRunning int `toml:"max_running"`
+	// StopSeconds is how long a dismissed agent gets to exit after /exit,
+	// and then after SIGTERM, before its processes are killed.
+	StopSeconds int `toml:"stop_seconds"`
+}
+
+// Cleanup holds the rules the monitor cleans up agents by on its own.
+type Cleanup struct {
+	// DoneAfterMinutes dismisses agents that have been done this long.
+	// 0 disables it.
+	DoneAfterMinutes int `toml:"done_after_minutes"`
+	// DeleteBranches also deletes the branches of agents dismissed for
+	// being done.
+	DeleteBranches bool `toml:"delete_branches"`
+	// MergedAt is the time of day ("03:00") at which agents whose branch
+	// is merged into their base are dismissed and their branches deleted.
+	// Empty disables it.
+	MergedAt string `toml:"merged_at"`
+}
+
+// Reports holds settings for report agents, whose deliverable is a
+// document collected when they finish.
+type Reports struct {
+	File string `toml:"file"` // file the agent writes in its worktree
+	Dir  string `toml:"dir"`  // where reports are collected; empty uses .worktrees/reports
+}
+
+// Playbooks holds settings for task playbooks.
+type Playbooks struct {
+	Dir string `toml:"dir"` // directory the spawn wizard lists, relative to the repo
+}
+
+// Window holds the layout template for new agent windows.
+type Window struct {
+	Panes []Pane `toml:"panes"`
 }
 
 // Config is the top-level configuration.
 type Config struct {
 	Colors        Colors        `toml:"colors"`
 	Layout        Layout        `toml:"layout"`
+	Format        Format        `toml:"format"`
+	Instance      Instance      `toml:"instance"`
+	Accessibility Accessibility `toml:"accessibility"`
 	Claude        Claude        `toml:"claude"`
 	Harness       Harness       `toml:"harness"`
 	Notifications Notifications `toml:"notifications"`
+	Monitor       Monitor       `toml:"monitor"`
+	Tmux          Tmux          `toml:"tmux"`
+	Web           Web           `toml:"web"`
+	Slack         Slack         `toml:"slack"`
+	Tickets       Tickets       `toml:"tickets"`
+	Forge         Forge         `toml:"forge"`
+	PullRequests  PullRequests  `toml:"pull_requests"`
+	Preview       Preview       `toml:"preview"`
+	Git           Git           `toml:"git"`
+	Merge         Merge         `toml:"merge"`
+	Agents        Agents        `toml:"agents"`
+	Cleanup       Cleanup       `toml:"cleanup"`
+	Reports       Reports       `toml:"reports"`
+	Playbooks     Playbooks     `toml:"playbooks"`
+	Window        Window        `toml:"window"`
 }
 
 // Default returns a Config populated with the current hardcoded defaults.
@@ -106,6 +397,9 @@ func Default() Config {
 			DashboardWidth: 55,
 			LazygitSplit:   80,
 		},
+		Format: Format{
+			Clock24h: true,
+		},
 		Claude: Claude{
 			AgentTeams:       true,
 			TeammateMode:     "in-process",
@@ -118,6 +412,45 @@ func Default() Config {
 			Enabled: true,
 			Sound:   "Glass",
 		},
+		Monitor: Monitor{
+			Providers:       []string{"stream", "hook", "pane"},
+			Shim:            true,
+			GoneAfter:       3,
+			NoSignalMinutes: 5,
+		},
+		Tmux: Tmux{
+			Retries:        2,
+			RetryBackoffMS: 100,
+		},
+		Tickets: Tickets{
+			InProgress: "In Progress",
+			InReview:   "In Review",
+		},
+		PullRequests: PullRequests{
+			Codeowners:  true,
+			PollSeconds: 120,
+		},
+		Git: Git{
+			PushGuard:           true,
+			Rerere:              true,
+			BackupRetentionDays: 14,
+		},
+		Merge: Merge{
+			DeleteBranch:    true,
+			RemoveWorktree:  true,
+			Retries:         3,
+			LargeConflictKB: 1024,
+			Strategy:        "merge",
+		},
+		Agents: Agents{
+			StopSeconds: 5,
+		},
+		Reports: Reports{
+			File: "REPORT.md",
+		},
+		Playbooks: Playbooks{
+			Dir: ".mastermind/playbooks",
+		},
 	}
 }
 
@@ -133,8 +466,17 @@ func Path() string {
 
 // Load reads the config file and returns a Config. Omitted fields keep
 // their default values. If the file does not exist, defaults are returned
-// with no error.
+// with no error. EnvPrefix variables override the file.
 func Load() (Config, error) {
+	cfg, err := loadFile()
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, applyEnv(&cfg)
+}
+
+// loadFile reads the config file over the defaults.
+func loadFile() (Config, error) {
 	cfg := Default()
 	path := Path()
 
@@ -152,6 +494,47 @@ func Load() (Config, error) {
 	return cfg, nil
 }
 
+// RepoFileName is the optional per-repository config file, read from the
+// repository root. Only its [window] section is used, overriding the user
+// config's.
+const RepoFileName = ".mastermind.conf"
+
+// repoFile holds the settings a repository's RepoFileName may set. A
+// repository can come from anyone, so settings such as skip_permissions,
+// agent commands or the web token are only taken from the user config.
+type repoFile struct {
+	Window *Window `toml:"window"`
+}
+
+// LoadForRepo reads the user config and overlays the [window] section of
+// the repository's RepoFileName, if present. Other sections in it are
+// ignored with a warning. EnvPrefix variables override both files.
+func LoadForRepo(repoPath string) (Config, error) {
+	cfg, err := loadFile()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(repoPath, RepoFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return cfg, err
+	}
+	if err == nil {
+		var rf repoFile
+		md, err := toml.Decode(string(data), &rf)
+		if err != nil {
+			return cfg, fmt.Errorf("%s: %w", RepoFileName, err)
+		}
+		for _, key := range md.Undecoded() {
+			slog.Warn("ignoring setting only the user config can set", "file", RepoFileName, "key", key.String())
+		}
+		if rf.Window != nil {
+			cfg.Window = *rf.Window
+		}
+	}
+	return cfg, applyEnv(&cfg)
+}
+
 const defaultFileContent = `# Mastermind configuration
 # Uncomment and modify values to customize. All values are optional.
 # Colors can be hex (#rrggbb) or xterm-256 codes (0-255).
@@ -187,13 +570,117 @@ const defaultFileContent = `# Mastermind configuration
 [layout]
 # dashboard_width = 55   # percentage of terminal width for left panel
 # lazygit_split   = 80   # percentage for lazygit pane size
+# Both can be changed at runtime with < > - + on the dashboard.
+
+[format]
+# locale    = ""    # separators for costs, e.g. "de-DE"; empty uses $LC_ALL, $LC_NUMERIC or $LANG
+# clock_24h = true  # 24-hour times in notifications, the errors panel and the dashboard; false shows 3:04PM
+
+[instance]
+# name   = ""  # shown in the dashboard title and as the tmux window name, e.g. "api"
+# accent = ""  # color of the logo, title and border, e.g. "#fab387"
+
+[accessibility]
+# screen_reader = false  # plain-text output for screen readers: no box drawing or symbols, no alternate screen, status changes announced as lines
+# row_spacing   = 0      # blank lines between agent rows
 
 [harness]
-# default = "claude"  # Default harness: "claude" or "opencode"
+# default = "claude"  # Default harness: "claude", "opencode" or the name of a [[harness.agents]] entry
+
+# Other agent CLIs, offered in the spawn wizard next to Claude Code and
+# OpenCode. Their status is read from their pane with the patterns.
+# [[harness.agents]]
+# name                = "aider"
+# command             = "aider"
+# args                = ["--no-auto-commits"]
+# prompt_flag         = "--message"   # flag an initial prompt is passed with; empty appends it
+# input_patterns      = ['^> ?$']      # regexps for pane lines shown while it waits for input
+# permission_patterns = ['\(Y\)es/\(N\)o']  # ... and while it waits for permission
 
 [notifications]
 # enabled = true       # send macOS notifications when agents need attention
 # sound   = "Glass"    # macOS system sound (Glass, Ping, Pop, Tink, etc.)
+# tmux_bell    = false  # ring the bell in the mastermind window so tmux flags it
+# mark_windows = false  # prefix agent windows with ❗ while they wait for permission
+# window_status = false # prefix agent windows with a live status glyph (✻ running, ✔ done, ...)
+
+[monitor]
+# providers = ["stream", "hook", "pane"]  # status detection, highest priority first
+# shim      = true  # launch agents through mastermind's exit-code wrapper
+# gone_after = 3    # polls an agent pane must be missing before it counts as closed
+# no_signal_minutes = 5  # flag working agents whose hooks went quiet this long; 0 disables
+
+[tmux]
+# retries          = 2    # retry failed tmux queries (e.g. "server not responding")
+# retry_backoff_ms = 100  # wait before the first retry, doubled each time
+# sessions         = []   # extra sessions to offer when spawning, e.g. ["noise"]
+
+[web]
+# listen = ""  # serve the web dashboard, e.g. "127.0.0.1:8765", or "0.0.0.0:8765" with a token for your LAN; empty disables it
+# token  = ""  # require ?token=<token> to view it
+
+[slack]
+# signing_secret = ""  # enable /mastermind slash commands and buttons (needs [web] listen)
+# bot_token      = ""  # xoxb- token used to post attention events
+# channel        = ""  # channel ID to post attention events to
+
+[forge]
+# provider = ""  # "github", "gitlab", "gitea" or "bitbucket"; empty detects it from the origin remote
+# url      = ""  # web URL of a self-hosted Gitea (defaults to the origin host)
+# token    = ""  # Gitea access token or Bitbucket app password (GitHub and GitLab use gh/glab logins)
+# username = ""  # Bitbucket username for the app password
+
+[pull_requests]
+# reviewers    = []     # always request these reviewers, e.g. ["alice", "acme/backend"]
+# codeowners   = true   # also request the CODEOWNERS owners of the changed files
+# labels       = []     # labels applied to every pull request
+# poll_seconds = 120    # check finished agents' pull requests for merges this often; 0 disables
+# auto_dismiss = false  # dismiss the agent and delete its branch once its pull request is merged
+
+[preview]
+# diff_only = false  # p shows the agent's diff (also V) instead of checking its changes out in the main worktree
+
+[git]
+# push_guard = true  # block pushes from agent worktrees with a pre-push hook (an existing one in .git/hooks runs after it); allow them per agent with "a"
+# rerere = true      # merge with git rerere (unless set) so conflict resolutions are reused across agents
+# archive_branches = false  # archive agent branches under refs/mastermind/archive/<date>/ instead of deleting them; restore with "A"
+# backup_retention_days = 14  # days to keep backups of uncommitted changes in .worktrees/backups taken before worktrees are removed; 0 takes none
+# fetch_base = false  # fetch the base branch and fast-forward it to origin's before spawning an agent from it; toggle per spawn with "f"
+
+[merge]
+# delete_branch = true      # merge wizard default: delete the agent's branch after merging
+# remove_worktree = true    # merge wizard default: remove the agent's worktree after merging
+# remember = false          # default to the choices last made in the wizard for this repository, over the two above
+# retries = 3               # merge base in again this often when it moves during a merge, e.g. another agent merged
+# large_conflict_kb = 1024  # mark conflicted files bigger than this as large; 0 marks none
+# editor = ""               # opens a conflicted file at its first conflict, e.g. "code --goto {file}:{line}"; empty runs $EDITOR +{line} {file}
+# open_lazygit_on_conflict = false  # open lazygit right away when a merge has conflicts, instead of the conflicts view
+# strategy = "merge"        # "merge" creates a merge commit; "rebase" rebases the branch onto base and fast-forwards, for linear history; "squash" collapses the branch into one commit on base
+# push = false              # merge wizard default: push the base branch to its upstream after merging
+
+[agents]
+# max_running  = 0  # refuse to spawn while this many agents are running or waiting; 0 is no cap
+# stop_seconds = 5  # wait this long for a dismissed agent to exit, then for SIGTERM, before killing its processes
+
+[cleanup]
+# done_after_minutes = 0      # dismiss agents that have been done this long; 0 never does
+# delete_branches    = false  # also delete their branches
+# merged_at          = ""     # time of day, e.g. "03:00", to dismiss agents whose branch is merged and delete the branches
+
+[reports]
+# file = "REPORT.md"  # file report agents write in their worktree
+# dir  = ""           # where finished reports are collected, relative to the repo; empty uses .worktrees/reports
+
+[playbooks]
+# dir = ".mastermind/playbooks"  # task playbooks listed in the spawn wizard, relative to the repo
+
+[tickets]
+# provider    = ""             # "linear" or "jira" to look up titles and move tickets; empty only links IDs
+# token       = ""             # Linear API key, or Jira API token
+# jira_url    = ""             # e.g. "https://acme.atlassian.net"
+# jira_email  = ""             # account the Jira token belongs to
+# in_progress = "In Progress"  # state a ticket moves to when its agent is spawned; empty disables
+# in_review   = "In Review"    # state a ticket moves to when its agent is ready for review; empty disables
 
 [claude]
 # agent_teams      = true   # enable Claude Code agent teams (CLAUDE_CODE_EXPERIMENTAL_AGENT_TEAMS)
@@ -201,6 +688,15 @@ const defaultFileContent = `# Mastermind configuration
 # skip_permissions = false  # pass --dangerously-skip-permissions to all spawned agents
 # prompt_editor      = false  # open nvim in a split pane for drafting prompts
 # prompt_editor_size = 50     # percentage of window height for the prompt editor pane
+# stream_json        = false  # run agents with -p --output-format stream-json for exact status/tokens
+# statusline_script  = ""     # your own statusline script, e.g. "~/.claude/statusline.sh"; empty uses mastermind's
+
+# Extra panes opened next to the agent in every agent window. Usually set
+# per repository in .mastermind.conf at the repo root.
+# [[window.panes]]
+# command = ""                # run through the shell; empty opens a shell
+# split   = "right"           # "right" or "below" the agent pane
+# size    = 30                # percentage of the agent pane
 `
 
 // WriteDefault writes the default config file with all values commented out.
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadForRepo_IgnoresUserOnlySettings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	conf := `[claude]
+skip_permissions = true
+
+[web]
+listen = "0.0.0.0:80"
+
+[[window.panes]]
+command = "make watch"
+`
+	if err := os.WriteFile(filepath.Join(repo, RepoFileName), []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadForRepo(repo)
+	if err != nil {
+		t.Fatalf("LoadForRepo: %v", err)
+	}
+	def := Default()
+	if cfg.Claude.SkipPermissions != def.Claude.SkipPermissions {
+		t.Errorf("skip_permissions = %v, want the repo file ignored", cfg.Claude.SkipPermissions)
+	}
+	if cfg.Web.Listen != def.Web.Listen {
+		t.Errorf("web listen = %q, want the repo file ignored", cfg.Web.Listen)
+	}
+	if len(cfg.Window.Panes) != 1 {
+		t.Errorf("window panes = %+v, want the repo file's", cfg.Window.Panes)
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   string
+		value string
+		check func(Config) bool
+	}{
+		{"string", "MASTERMIND_FORGE_PROVIDER", "gitlab", func(c Config) bool { return c.Forge.Provider == "gitlab" }},
+		{"int", "MASTERMIND_LAYOUT_DASHBOARD_WIDTH", " 80 ", func(c Config) bool { return c.Layout.DashboardWidth == 80 }},
+		{"bool", "MASTERMIND_GIT_PUSH_GUARD", "false", func(c Config) bool { return !c.Git.PushGuard }},
+		{"slice", "MASTERMIND_PULL_REQUESTS_REVIEWERS", "alice, bob,,", func(c Config) bool {
+			return slices.Equal(c.PullRequests.Reviewers, []string{"alice", "bob"})
+		}},
+		{"empty slice", "MASTERMIND_PULL_REQUESTS_REVIEWERS", "", func(c Config) bool { return len(c.PullRequests.Reviewers) == 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			cfg := Default()
+			cfg.PullRequests.Reviewers = []string{"carol"}
+			if err := applyEnv(&cfg); err != nil {
+				t.Fatalf("applyEnv: %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("%s=%q not applied: %+v", tt.env, tt.value, cfg)
+			}
+		})
+	}
+}
+
+func TestApplyEnv_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   string
+		value string
+		want  string
+	}{
+		{"int", "MASTERMIND_LAYOUT_DASHBOARD_WIDTH", "wide", `invalid number "wide"`},
+		{"bool", "MASTERMIND_GIT_PUSH_GUARD", "maybe", `invalid boolean "maybe"`},
+		{"array of tables", "MASTERMIND_WINDOW_PANES", "shell", "can't be set from the environment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			cfg := Default()
+			err := applyEnv(&cfg)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.env+": ") || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("applyEnv = %v, want %s: %s", err, tt.env, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadForRepo_EnvOverridesRepoFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	conf := "[[window.panes]]\ncommand = \"make watch\"\n"
+	if err := os.WriteFile(filepath.Join(repo, RepoFileName), []byte(conf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MASTERMIND_LAYOUT_DASHBOARD_WIDTH", "90")
+
+	cfg, err := LoadForRepo(repo)
+	if err != nil {
+		t.Fatalf("LoadForRepo: %v", err)
+	}
+	if cfg.Layout.DashboardWidth != 90 {
+		t.Errorf("dashboard_width = %d, want the environment's 90", cfg.Layout.DashboardWidth)
+	}
+	if len(cfg.Window.Panes) != 1 || cfg.Window.Panes[0].Command != "make watch" {
+		t.Errorf("window panes = %+v, want the repo file's where the environment is silent", cfg.Window.Panes)
+	}
+}
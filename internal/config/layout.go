@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SaveLayout writes the layout's sizes into the [layout] section of the
+// config file at path, keeping the rest of the file, comments included,
+// as it is. A commented-out key is uncommented; a missing section or key
+// is added. The file is created if it doesn't exist.
+func SaveLayout(path string, l Layout) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read config: %w", err)
+	}
+	content := setSectionInt(string(data), "layout", "dashboard_width", l.DashboardWidth)
+	content = setSectionInt(content, "layout", "lazygit_split", l.LazygitSplit)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+// setSectionInt sets key to v in the TOML section of content. An existing
+// line for the key, commented out or not, keeps its alignment and trailing
+// comment; otherwise the key is added below the section header.
+func setSectionInt(content, section, key string, v int) string {
+	lines := strings.Split(content, "\n")
+	keyRe := regexp.MustCompile(`^(\s*)(?:#\s*)?(` + regexp.QuoteMeta(key) + `\s*=\s*)[^#\s]*(.*)$`)
+	value := strconv.Itoa(v)
+
+	header := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if header < 0 {
+			if trimmed == "["+section+"]" {
+				header = i
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") {
+			break
+		}
+		if m := keyRe.FindStringSubmatch(line); m != nil {
+			lines[i] = m[1] + m[2] + value + m[3]
+			return strings.Join(lines, "\n")
+		}
+	}
+
+	entry := key + " = " + value
+	if header < 0 {
+		content = strings.TrimRight(content, "\n")
+		if content != "" {
+			content += "\n\n"
+		}
+		return content + "[" + section + "]\n" + entry + "\n"
+	}
+	lines = append(lines[:header+1], append([]string{entry}, lines[header+1:]...)...)
+	return strings.Join(lines, "\n")
+}
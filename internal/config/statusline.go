@@ -1,16 +1,28 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
-const statuslineScript = `#!/bin/sh
+// statuslineSidecar saves the statusline JSON Claude Code passes on stdin
+// as .claude-status.json in the agent's worktree, where mastermind reads
+// its metrics, and per session under .claude/mastermind-status/ so the
+// costs of an agent team's teammates, who share the worktree, add up.
+const statuslineSidecar = `#!/bin/sh
 input=$(cat)
 
 dir=$(echo "$input" | jq -r '.workspace.current_dir // .cwd // ""')
 [ -n "$dir" ] && echo "$input" > "$dir/.claude-status.json"
+session=$(echo "$input" | jq -r '.session_id // ""')
+if [ -n "$dir" ] && [ -n "$session" ]; then
+  mkdir -p "$dir/.claude/mastermind-status" && echo "$input" > "$dir/.claude/mastermind-status/$session.json"
+fi
+`
 
+const statuslineScript = statuslineSidecar + `
 model=$(echo "$input" | jq -r '.model.display_name // ""')
 used=$(echo "$input" | jq -r '.context_window.used_percentage // empty')
 
@@ -74,11 +86,31 @@ func StatuslineScriptPath() string {
 }
 
 // WriteStatuslineScript writes the statusline bash script to disk.
-// It always overwrites to ensure the latest version is installed.
-func WriteStatuslineScript() error {
+// It always overwrites to ensure the latest version is installed. With
+// custom set ([claude] statusline_script), the script only saves the
+// sidecar and hands the JSON on to custom, which renders the statusline;
+// the user's script itself is never written to.
+func WriteStatuslineScript(custom string) error {
 	path := StatuslineScriptPath()
+	script := statuslineScript
+	if custom != "" {
+		if expandHome(custom) == path {
+			return fmt.Errorf("statusline_script %s is mastermind's own statusline script", custom)
+		}
+		script = statuslineSidecar + "\nprintf '%s\\n' \"$input\" | " + custom + "\n"
+	}
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
-	return os.WriteFile(path, []byte(statuslineScript), 0o755)
+	return os.WriteFile(path, []byte(script), 0o755)
+}
+
+// expandHome replaces a leading "~/" in path with the home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
 }
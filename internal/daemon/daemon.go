@@ -0,0 +1,109 @@
+// Package daemon lets mastermind's monitor run headless in the background.
+// A daemon holds an exclusive lock on a pidfile in the worktree directory
+// so only one monitor tracks a repository at a time, and appends every
+// monitor event to a log that TUI clients follow. Frontends issue commands
+// over a unix socket next to the pidfile; see package ipc.
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrRunning is returned by Acquire when another daemon holds the lock.
+var ErrRunning = errors.New("daemon already running")
+
+// PIDPath returns the daemon pidfile for a worktree directory.
+func PIDPath(worktreeDir string) string {
+	return filepath.Join(worktreeDir, "mastermind-daemon.pid")
+}
+
+// EventsPath returns the event log the daemon writes for a worktree
+// directory.
+func EventsPath(worktreeDir string) string {
+	return filepath.Join(worktreeDir, "mastermind-events.jsonl")
+}
+
+// SocketPath returns the unix socket the daemon serves frontends on for a
+// worktree directory.
+func SocketPath(worktreeDir string) string {
+	return filepath.Join(worktreeDir, "mastermind.sock")
+}
+
+// Lock is the daemon's hold on its pidfile. The lock is released by the
+// kernel when the process exits, so a crashed daemon never leaves a stale
+// lock behind.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire locks the pidfile in worktreeDir and records the current pid in
+// it. It returns ErrRunning if another daemon holds the lock.
+func Acquire(worktreeDir string) (*Lock, error) {
+	f, err := os.OpenFile(PIDPath(worktreeDir), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open pidfile: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrRunning
+		}
+		return nil, fmt.Errorf("lock pidfile: %w", err)
+	}
+	if err := f.Truncate(0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("truncate pidfile: %w", err)
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write pidfile: %w", err)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Release removes the pidfile and drops the lock.
+func (l *Lock) Release() {
+	os.Remove(l.f.Name())
+	l.f.Close()
+}
+
+// Running reports whether a daemon holds the lock in worktreeDir, and its
+// pid if so.
+func Running(worktreeDir string) (int, bool) {
+	f, err := os.Open(PIDPath(worktreeDir))
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err == nil {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		return 0, false
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return 0, true
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, true
+}
+
+// Stop asks the daemon running for worktreeDir to shut down.
+func Stop(worktreeDir string) error {
+	pid, ok := Running(worktreeDir)
+	if !ok {
+		return fmt.Errorf("no daemon running")
+	}
+	if pid <= 0 {
+		return fmt.Errorf("daemon pidfile has no pid")
+	}
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("signal daemon %d: %w", pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+func TestAcquire_ExclusiveAndRunning(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, ok := Running(dir); ok {
+		t.Fatal("Running before Acquire should be false")
+	}
+
+	lock, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if _, err := Acquire(dir); !errors.Is(err, ErrRunning) {
+		t.Errorf("second Acquire err = %v, want ErrRunning", err)
+	}
+	pid, ok := Running(dir)
+	if !ok || pid != os.Getpid() {
+		t.Errorf("Running = %d, %v; want %d, true", pid, ok, os.Getpid())
+	}
+
+	lock.Release()
+	if _, ok := Running(dir); ok {
+		t.Error("Running after Release should be false")
+	}
+}
+
+func TestEventLog_RoundTrip(t *testing.T) {
+	path := EventsPath(t.TempDir())
+	log, err := CreateEventLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	want := []monitor.Event{
+		monitor.AgentFinished{AgentID: "a1", ExitCode: 1, HasChanges: true},
+		monitor.Attention{AgentID: "a2", Message: "needs input"},
+	}
+	for _, ev := range want {
+		if err := log.Append(ev); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	got, offset, err := ReadEvents(path, 0)
+	if err != nil {
+		t.Fatalf("ReadEvents: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %#v, want %#v", i, got[i], want[i])
+		}
+	}
+
+	// Nothing new after the returned offset.
+	got, _, err = ReadEvents(path, offset)
+	if err != nil || len(got) != 0 {
+		t.Errorf("ReadEvents at end = %v, %v; want no events", got, err)
+	}
+}
+
+func TestReadEvents_LeavesPartialLine(t *testing.T) {
+	path := EventsPath(t.TempDir())
+	line := `{"type":"gone","agent":"a1","event":{"AgentID":"a1"}}` + "\n"
+	if err := os.WriteFile(path, []byte(line+`{"type":"gone"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, offset, err := ReadEvents(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != (monitor.AgentGone{AgentID: "a1"}) {
+		t.Errorf("events = %#v, want one AgentGone", got)
+	}
+	if offset != int64(len(line)) {
+		t.Errorf("offset = %d, want %d (start of the partial line)", offset, len(line))
+	}
+}
@@ -0,0 +1,119 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// record is one line of the event log.
+type record struct {
+	Time  time.Time       `json:"time"`
+	Type  string          `json:"type"`
+	Agent string          `json:"agent"`
+	Event json.RawMessage `json:"event"`
+}
+
+// EventLog appends monitor events to a JSON-lines file.
+type EventLog struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// CreateEventLog starts a fresh event log at path, discarding events from
+// a previous daemon run.
+func CreateEventLog(path string) (*EventLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open event log: %w", err)
+	}
+	return &EventLog{f: f}, nil
+}
+
+// Append writes ev to the log as one line.
+func (l *EventLog) Append(ev monitor.Event) error {
+	typ, body, err := monitor.MarshalEvent(ev)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(record{Time: time.Now(), Type: typ, Agent: ev.AgentRef(), Event: body})
+	if err != nil {
+		return fmt.Errorf("marshal event record: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.f.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the log file.
+func (l *EventLog) Close() error {
+	return l.f.Close()
+}
+
+// ReadEvents returns the events written to the log at path after offset,
+// and the offset to resume from. A partially written last line is left for
+// the next call. If the log is shorter than offset it was restarted by a
+// new daemon, and reading starts over from the beginning.
+func ReadEvents(path string, offset int64) ([]monitor.Event, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, offset, fmt.Errorf("open event log: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, offset, fmt.Errorf("stat event log: %w", err)
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, fmt.Errorf("seek event log: %w", err)
+	}
+
+	var events []monitor.Event
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			// EOF, possibly mid-line: resume at the start of the line.
+			break
+		}
+		offset += int64(len(line))
+		var rec record
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			slog.Warn("skipping malformed event log line", "error", err)
+			continue
+		}
+		ev, err := monitor.UnmarshalEvent(rec.Type, rec.Event)
+		if err != nil {
+			slog.Warn("skipping event log record", "type", rec.Type, "error", err)
+			continue
+		}
+		events = append(events, ev)
+	}
+	return events, offset, nil
+}
+
+// EndOffset returns the current size of the log at path, so a client can
+// follow only events written from now on.
+func EndOffset(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
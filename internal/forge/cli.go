@@ -0,0 +1,186 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/simonbystrom/mastermind/internal/ci"
+)
+
+// githubProvider drives GitHub through the gh CLI.
+type githubProvider struct {
+	repoPath string
+	run      runner
+}
+
+func (p *githubProvider) Kind() Kind { return GitHub }
+
+func (p *githubProvider) Push(branch string) error { return push(p.run, p.repoPath, branch) }
+
+func (p *githubProvider) CreatePullRequest(opts PullRequestOptions) (PullRequest, error) {
+	args := []string{"pr", "create", "--head", opts.Head, "--base", opts.Base, "--title", opts.Title, "--body", opts.Body}
+	if opts.Draft {
+		args = append(args, "--draft")
+	}
+	for _, l := range opts.Labels {
+		args = append(args, "--label", l)
+	}
+	out, err := p.run(p.repoPath, "gh", args...)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	return pullRequestFromURL(out, "/pull/")
+}
+
+func (p *githubProvider) CIStatus(branch string) (CIState, error) {
+	out, err := p.run(p.repoPath, "gh", "run", "list", "--branch", branch, "--limit", "1", "--json", "status,conclusion")
+	if err != nil {
+		return CINone, err
+	}
+	var runs []struct {
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	}
+	if err := json.Unmarshal(out, &runs); err != nil {
+		return CINone, fmt.Errorf("parse runs: %w", err)
+	}
+	if len(runs) == 0 {
+		return CINone, nil
+	}
+	if runs[0].Status != "completed" {
+		return CIPending, nil
+	}
+	switch runs[0].Conclusion {
+	case "success", "neutral", "skipped":
+		return CISuccess, nil
+	default:
+		return CIFailure, nil
+	}
+}
+
+func (p *githubProvider) RequestReview(number int, reviewers []string) error {
+	_, err := p.run(p.repoPath, "gh", "pr", "edit", strconv.Itoa(number), "--add-reviewer", strings.Join(reviewers, ","))
+	return err
+}
+
+func (p *githubProvider) FindPullRequest(head, base string) (PullRequest, bool, error) {
+	out, err := p.run(p.repoPath, "gh", "pr", "list", "--head", head, "--base", base,
+		"--state", "all", "--limit", "1", "--json", "number,url,state,headRefOid")
+	if err != nil {
+		return PullRequest{}, false, err
+	}
+	var prs []struct {
+		Number     int    `json:"number"`
+		URL        string `json:"url"`
+		State      string `json:"state"`
+		HeadRefOid string `json:"headRefOid"`
+	}
+	if err := json.Unmarshal(out, &prs); err != nil {
+		return PullRequest{}, false, fmt.Errorf("parse pull requests: %w", err)
+	}
+	if len(prs) == 0 {
+		return PullRequest{}, false, nil
+	}
+	state := PROpen
+	switch prs[0].State {
+	case "MERGED":
+		state = PRMerged
+	case "CLOSED":
+		state = PRClosed
+	}
+	return PullRequest{Number: prs[0].Number, URL: prs[0].URL, State: state, HeadOID: prs[0].HeadRefOid}, true, nil
+}
+
+func (p *githubProvider) FailedRuns(limit int) ([]ci.Run, error) {
+	return ci.ListFailedRuns(p.repoPath, limit)
+}
+
+func (p *githubProvider) FailedLogs(runID int64) (string, error) {
+	return ci.FailedLogs(p.repoPath, runID)
+}
+
+// gitlabProvider drives GitLab through the glab CLI.
+type gitlabProvider struct {
+	repoPath string
+	run      runner
+}
+
+func (p *gitlabProvider) Kind() Kind { return GitLab }
+
+func (p *gitlabProvider) Push(branch string) error { return push(p.run, p.repoPath, branch) }
+
+func (p *gitlabProvider) CreatePullRequest(opts PullRequestOptions) (PullRequest, error) {
+	args := []string{"mr", "create", "--source-branch", opts.Head, "--target-branch", opts.Base,
+		"--title", opts.Title, "--description", opts.Body, "--yes"}
+	if opts.Draft {
+		args = append(args, "--draft")
+	}
+	if len(opts.Labels) > 0 {
+		args = append(args, "--label", strings.Join(opts.Labels, ","))
+	}
+	out, err := p.run(p.repoPath, "glab", args...)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	return pullRequestFromURL(out, "/merge_requests/")
+}
+
+func (p *gitlabProvider) CIStatus(branch string) (CIState, error) {
+	out, err := p.run(p.repoPath, "glab", "api", "projects/:id/pipelines?per_page=1&ref="+url.QueryEscape(branch))
+	if err != nil {
+		return CINone, err
+	}
+	var pipelines []struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(out, &pipelines); err != nil {
+		return CINone, fmt.Errorf("parse pipelines: %w", err)
+	}
+	if len(pipelines) == 0 {
+		return CINone, nil
+	}
+	switch pipelines[0].Status {
+	case "success", "skipped":
+		return CISuccess, nil
+	case "failed", "canceled":
+		return CIFailure, nil
+	default:
+		return CIPending, nil
+	}
+}
+
+func (p *gitlabProvider) RequestReview(number int, reviewers []string) error {
+	_, err := p.run(p.repoPath, "glab", "mr", "update", strconv.Itoa(number), "--reviewer", strings.Join(reviewers, ","))
+	return err
+}
+
+func (p *gitlabProvider) FindPullRequest(head, base string) (PullRequest, bool, error) {
+	out, err := p.run(p.repoPath, "glab", "api", "projects/:id/merge_requests?per_page=1&order_by=created_at"+
+		"&source_branch="+url.QueryEscape(head)+"&target_branch="+url.QueryEscape(base))
+	if err != nil {
+		return PullRequest{}, false, err
+	}
+	var mrs []struct {
+		IID    int    `json:"iid"`
+		WebURL string `json:"web_url"`
+		State  string `json:"state"`
+		SHA    string `json:"sha"`
+	}
+	if err := json.Unmarshal(out, &mrs); err != nil {
+		return PullRequest{}, false, fmt.Errorf("parse merge requests: %w", err)
+	}
+	if len(mrs) == 0 {
+		return PullRequest{}, false, nil
+	}
+	state := PROpen
+	switch mrs[0].State {
+	case "merged":
+		state = PRMerged
+	case "closed":
+		state = PRClosed
+	}
+	return PullRequest{Number: mrs[0].IID, URL: mrs[0].WebURL, State: state, HeadOID: mrs[0].SHA}, true, nil
+}
@@ -0,0 +1,141 @@
+package forge
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeownersPaths are where GitHub, GitLab and Gitea look for a CODEOWNERS
+// file, in the order they are checked.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// Codeowners maps path patterns to owners. Later rules take precedence.
+type Codeowners struct {
+	rules []ownerRule
+}
+
+type ownerRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// ReadCodeowners loads the repository's CODEOWNERS file. It returns nil,
+// nil when the repository has none.
+func ReadCodeowners(repoPath string) (*Codeowners, error) {
+	for _, rel := range codeownersPaths {
+		f, err := os.Open(filepath.Join(repoPath, rel))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return parseCodeowners(bufio.NewScanner(f))
+	}
+	return nil, nil
+}
+
+// ParseCodeowners parses CODEOWNERS content.
+func ParseCodeowners(content string) *Codeowners {
+	c, _ := parseCodeowners(bufio.NewScanner(strings.NewReader(content)))
+	return c
+}
+
+func parseCodeowners(sc *bufio.Scanner) (*Codeowners, error) {
+	c := &Codeowners{}
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		// GitLab section headers ("[Docs]", "^[Optional]") carry no
+		// pattern of their own.
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		re, err := ownerPattern(fields[0])
+		if err != nil {
+			continue // skip patterns the hosting provider would reject too
+		}
+		rule := ownerRule{pattern: re}
+		for _, o := range fields[1:] {
+			// Owners given by email cannot be requested as reviewers
+			// by name; skip them.
+			if o = strings.TrimPrefix(o, "@"); !strings.Contains(o, "@") {
+				rule.owners = append(rule.owners, o)
+			}
+		}
+		c.rules = append(c.rules, rule)
+	}
+	return c, sc.Err()
+}
+
+// Owners returns the owners of files, deduplicated in first-seen order.
+// Each file is owned by the last rule matching it.
+func (c *Codeowners) Owners(files []string) []string {
+	if c == nil {
+		return nil
+	}
+	var owners []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		for i := len(c.rules) - 1; i >= 0; i-- {
+			if !c.rules[i].pattern.MatchString(file) {
+				continue
+			}
+			for _, o := range c.rules[i].owners {
+				if !seen[o] {
+					seen[o] = true
+					owners = append(owners, o)
+				}
+			}
+			break
+		}
+	}
+	return owners
+}
+
+// ownerPattern compiles a CODEOWNERS pattern, which follows gitignore
+// rules: a leading or inner slash anchors it at the repository root, a
+// trailing slash limits it to directories, "**" spans directories, and a
+// pattern matching a directory matches everything under it.
+func ownerPattern(pattern string) (*regexp.Regexp, error) {
+	dir := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if dir {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
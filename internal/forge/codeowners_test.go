@@ -0,0 +1,52 @@
+package forge
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCodeowners_Owners(t *testing.T) {
+	c := ParseCodeowners(`# Default owners
+*                 @acme/core
+*.md              @docs-team   # prose
+/build/           @ops ops@acme.dev
+internal/**/db.go @dba
+[Frontend]
+web/              @frontend @ann
+`)
+	for _, tc := range []struct {
+		files []string
+		want  []string
+	}{
+		{[]string{"main.go"}, []string{"acme/core"}},
+		{[]string{"internal/x/README.md"}, []string{"docs-team"}},
+		{[]string{"build/ci.sh"}, []string{"ops"}},
+		{[]string{"tools/build/ci.sh"}, []string{"acme/core"}},
+		{[]string{"internal/db.go", "internal/a/b/db.go"}, []string{"dba"}},
+		{[]string{"web/app.js", "main.go", "src/web/x.js"}, []string{"frontend", "ann", "acme/core"}},
+		{[]string{"src/web/x.js"}, []string{"frontend", "ann"}},
+	} {
+		if got := c.Owners(tc.files); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Owners(%v) = %v, want %v", tc.files, got, tc.want)
+		}
+	}
+}
+
+func TestReadCodeowners(t *testing.T) {
+	dir := t.TempDir()
+	c, err := ReadCodeowners(dir)
+	if err != nil || c != nil {
+		t.Fatalf("no CODEOWNERS: got %v, %v", c, err)
+	}
+	os.MkdirAll(filepath.Join(dir, ".github"), 0o755)
+	os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("* @ann\n"), 0o644)
+	c, err = ReadCodeowners(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Owners([]string{"x.go"}); !reflect.DeepEqual(got, []string{"ann"}) {
+		t.Errorf("Owners = %v", got)
+	}
+}
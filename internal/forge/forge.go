@@ -0,0 +1,250 @@
+// Package forge abstracts the git hosting provider (GitHub, GitLab, Gitea,
+// Bitbucket) behind one interface for pushing branches, opening pull
+// requests, reading CI status and requesting reviews. GitHub and GitLab go
+// through their CLIs (gh, glab) so their logins are reused; Gitea and
+// Bitbucket are driven over their REST APIs with a configured token.
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/simonbystrom/mastermind/internal/ci"
+)
+
+// Kind identifies a hosting provider.
+type Kind string
+
+const (
+	GitHub    Kind = "github"
+	GitLab    Kind = "gitlab"
+	Gitea     Kind = "gitea"
+	Bitbucket Kind = "bitbucket"
+)
+
+// PullRequestOptions describe a pull (or merge) request to open.
+type PullRequestOptions struct {
+	Head   string // branch with the changes
+	Base   string // branch to merge into
+	Title  string
+	Body   string
+	Draft  bool
+	Labels []string // ignored on Bitbucket, which has no pull request labels
+}
+
+// PullRequest is an opened pull request.
+type PullRequest struct {
+	Number int
+	URL    string
+	State  PRState
+	// HeadOID is the commit the pull request's head branch was at, as
+	// reported by FindPullRequest. Bitbucket abbreviates it; empty when
+	// unknown.
+	HeadOID string
+}
+
+// PRState is the state of a pull request.
+type PRState string
+
+const (
+	PROpen   PRState = "open"
+	PRMerged PRState = "merged"
+	PRClosed PRState = "closed" // closed without merging
+)
+
+// CIState summarizes the CI checks on a branch.
+type CIState string
+
+const (
+	CINone    CIState = "" // no checks reported
+	CIPending CIState = "pending"
+	CISuccess CIState = "success"
+	CIFailure CIState = "failure"
+)
+
+// Provider is a git hosting provider.
+type Provider interface {
+	Kind() Kind
+	// Push pushes branch to origin and sets it as the upstream.
+	Push(branch string) error
+	// CreatePullRequest opens a pull request (merge request on GitLab).
+	CreatePullRequest(opts PullRequestOptions) (PullRequest, error)
+	// CIStatus reports the CI state of the latest commit on branch.
+	CIStatus(branch string) (CIState, error)
+	// RequestReview asks reviewers, in the provider's user naming, to
+	// review pull request number.
+	RequestReview(number int, reviewers []string) error
+	// FindPullRequest returns the most recently opened pull request from
+	// head into base, in any state. ok is false when there is none.
+	FindPullRequest(head, base string) (pr PullRequest, ok bool, err error)
+}
+
+// RunLister is implemented by providers whose failed CI runs can be
+// handed to an agent to fix.
+type RunLister interface {
+	FailedRuns(limit int) ([]ci.Run, error)
+	FailedLogs(runID int64) (string, error)
+}
+
+// Config selects and authenticates the provider.
+type Config struct {
+	// Provider is a Kind; empty detects it from the origin remote's host.
+	Provider string
+	// URL is the web URL of a self-hosted Gitea, or an API base URL
+	// override. Empty derives it from the origin remote.
+	URL string
+	// Token is a Gitea access token or a Bitbucket app password.
+	Token string
+	// Username goes with a Bitbucket app password.
+	Username string
+}
+
+// New returns the provider for the repository at repoPath.
+func New(repoPath string, cfg Config) (Provider, error) {
+	return newProvider(repoPath, cfg, execRun)
+}
+
+func newProvider(repoPath string, cfg Config, run runner) (Provider, error) {
+	out, err := run(repoPath, "git", "remote", "get-url", "origin")
+	if err != nil {
+		return nil, fmt.Errorf("read origin remote: %w", err)
+	}
+	remote, err := ParseRemote(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, err
+	}
+	kind := Kind(cfg.Provider)
+	if kind == "" {
+		if kind = Detect(remote.Host); kind == "" {
+			return nil, fmt.Errorf("cannot tell the hosting provider of %s; set [forge] provider", remote.Host)
+		}
+	}
+
+	switch kind {
+	case GitHub:
+		return &githubProvider{repoPath: repoPath, run: run}, nil
+	case GitLab:
+		return &gitlabProvider{repoPath: repoPath, run: run}, nil
+	case Gitea:
+		base := cfg.URL
+		if base == "" {
+			base = "https://" + remote.Host
+		}
+		return &giteaProvider{repoPath: repoPath, run: run, remote: remote,
+			api: newAPI(strings.TrimSuffix(base, "/")+"/api/v1", "token "+cfg.Token)}, nil
+	case Bitbucket:
+		base := cfg.URL
+		if base == "" {
+			base = bitbucketAPIURL
+		}
+		return &bitbucketProvider{repoPath: repoPath, run: run, remote: remote,
+			api: newAPI(strings.TrimSuffix(base, "/"), basicAuth(cfg.Username, cfg.Token))}, nil
+	default:
+		return nil, fmt.Errorf("unknown hosting provider %q", kind)
+	}
+}
+
+// Detect guesses the provider from a remote host name, or returns "".
+func Detect(host string) Kind {
+	host = strings.ToLower(host)
+	switch {
+	case strings.Contains(host, "github"):
+		return GitHub
+	case strings.Contains(host, "gitlab"):
+		return GitLab
+	case strings.Contains(host, "bitbucket"):
+		return Bitbucket
+	case strings.Contains(host, "gitea"), host == "codeberg.org":
+		return Gitea
+	}
+	return ""
+}
+
+// Remote is a parsed remote URL. Owner may contain slashes (GitLab
+// subgroups).
+type Remote struct {
+	Host  string
+	Owner string
+	Repo  string
+}
+
+// scpRemote matches scp-like remotes such as git@github.com:owner/repo.git.
+var scpRemote = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// ParseRemote parses an https, ssh or scp-like remote URL.
+func ParseRemote(raw string) (Remote, error) {
+	var host, path string
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Remote{}, fmt.Errorf("parse remote %q: %w", raw, err)
+		}
+		host, path = u.Hostname(), u.Path
+	} else if m := scpRemote.FindStringSubmatch(raw); m != nil {
+		host, path = m[1], m[2]
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if host == "" || i <= 0 {
+		return Remote{}, fmt.Errorf("unrecognized remote %q", raw)
+	}
+	return Remote{Host: host, Owner: path[:i], Repo: path[i+1:]}, nil
+}
+
+// runner runs a command in dir and returns its stdout. Providers take one
+// so tests can stand in for git and the forge CLIs.
+type runner func(dir, name string, args ...string) ([]byte, error)
+
+func execRun(dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		// Fold stderr into the error so failures like missing auth are
+		// visible.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("%s (%w)", strings.TrimSpace(string(exitErr.Stderr)), err)
+		}
+		return out, fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return out, nil
+}
+
+// push pushes branch to origin with git, which every provider shares.
+func push(run runner, repoPath, branch string) error {
+	_, err := run(repoPath, "git", "push", "--set-upstream", "origin", branch)
+	return err
+}
+
+// revParse resolves branch to a commit hash.
+func revParse(run runner, repoPath, branch string) (string, error) {
+	out, err := run(repoPath, "git", "rev-parse", branch)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// trailingNumber matches the number at the end of a pull request URL.
+var trailingNumber = regexp.MustCompile(`/(\d+)/?$`)
+
+// pullRequestFromURL finds the URL containing marker in CLI output and
+// takes the pull request number from its end.
+func pullRequestFromURL(out []byte, marker string) (PullRequest, error) {
+	for _, field := range strings.Fields(string(out)) {
+		if !strings.Contains(field, marker) {
+			continue
+		}
+		if m := trailingNumber.FindStringSubmatch(field); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return PullRequest{Number: n, URL: field}, nil
+		}
+	}
+	return PullRequest{}, fmt.Errorf("no pull request URL in output: %s", strings.TrimSpace(string(out)))
+}
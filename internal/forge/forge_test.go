@@ -0,0 +1,239 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeRun answers commands from a table keyed by "name arg0 arg1 ..." and
+// records every call.
+type fakeRun struct {
+	out   map[string]string
+	calls []string
+}
+
+func (f *fakeRun) run(dir, name string, args ...string) ([]byte, error) {
+	call := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, call)
+	for prefix, out := range f.out {
+		if strings.HasPrefix(call, prefix) {
+			return []byte(out), nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected command %q", call)
+}
+
+func TestParseRemote(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want Remote
+	}{
+		{"git@github.com:acme/widget.git", Remote{"github.com", "acme", "widget"}},
+		{"https://github.com/acme/widget", Remote{"github.com", "acme", "widget"}},
+		{"ssh://git@gitlab.example.com:2222/group/sub/widget.git", Remote{"gitlab.example.com", "group/sub", "widget"}},
+		{"https://user@bitbucket.org/team/widget.git", Remote{"bitbucket.org", "team", "widget"}},
+	} {
+		got, err := ParseRemote(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseRemote(%q) = %+v, %v; want %+v", tc.in, got, err, tc.want)
+		}
+	}
+	if _, err := ParseRemote("/srv/git/widget"); err == nil {
+		t.Error("expected error for local path remote")
+	}
+}
+
+func TestNew_DetectsProvider(t *testing.T) {
+	for _, tc := range []struct {
+		remote string
+		cfg    Config
+		want   Kind
+	}{
+		{"git@github.com:acme/widget.git", Config{}, GitHub},
+		{"git@gitlab.com:acme/widget.git", Config{}, GitLab},
+		{"https://bitbucket.org/acme/widget.git", Config{}, Bitbucket},
+		{"https://codeberg.org/acme/widget.git", Config{}, Gitea},
+		{"git@git.acme.dev:acme/widget.git", Config{Provider: "gitea"}, Gitea},
+	} {
+		f := &fakeRun{out: map[string]string{"git remote get-url origin": tc.remote + "\n"}}
+		p, err := newProvider("/repo", tc.cfg, f.run)
+		if err != nil {
+			t.Errorf("%s: %v", tc.remote, err)
+			continue
+		}
+		if p.Kind() != tc.want {
+			t.Errorf("%s: kind = %s, want %s", tc.remote, p.Kind(), tc.want)
+		}
+	}
+
+	f := &fakeRun{out: map[string]string{"git remote get-url origin": "git@git.acme.dev:acme/widget.git"}}
+	if _, err := newProvider("/repo", Config{}, f.run); err == nil || !strings.Contains(err.Error(), "[forge] provider") {
+		t.Errorf("unknown host: err = %v", err)
+	}
+}
+
+func TestGitHub(t *testing.T) {
+	f := &fakeRun{out: map[string]string{
+		"gh pr create": "Creating pull request...\nhttps://github.com/acme/widget/pull/42\n",
+		"gh run list":  `[{"status":"completed","conclusion":"failure"}]`,
+		"gh pr edit":   "",
+		"git push":     "",
+	}}
+	p := &githubProvider{repoPath: "/repo", run: f.run}
+
+	pr, err := p.CreatePullRequest(PullRequestOptions{Head: "feat/x", Base: "main", Title: "T", Body: "B", Draft: true})
+	if err != nil || pr.Number != 42 || pr.URL != "https://github.com/acme/widget/pull/42" {
+		t.Errorf("CreatePullRequest = %+v, %v", pr, err)
+	}
+	if !strings.HasSuffix(f.calls[0], "--draft") {
+		t.Errorf("call = %q, want --draft", f.calls[0])
+	}
+	if state, err := p.CIStatus("feat/x"); err != nil || state != CIFailure {
+		t.Errorf("CIStatus = %q, %v", state, err)
+	}
+	if err := p.RequestReview(42, []string{"ann", "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Push("feat/x"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"gh pr edit 42 --add-reviewer ann,bob", "git push --set-upstream origin feat/x"}
+	if got := f.calls[2:]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestGitHub_FindPullRequest(t *testing.T) {
+	f := &fakeRun{out: map[string]string{
+		"gh pr list": `[{"number":42,"url":"https://github.com/acme/widget/pull/42","state":"MERGED","headRefOid":"abc123"}]`,
+	}}
+	p := &githubProvider{repoPath: "/repo", run: f.run}
+	pr, ok, err := p.FindPullRequest("feat/x", "main")
+	if err != nil || !ok || pr.Number != 42 || pr.State != PRMerged || pr.HeadOID != "abc123" {
+		t.Errorf("FindPullRequest = %+v, %v, %v", pr, ok, err)
+	}
+
+	f.out["gh pr list"] = "[]"
+	if _, ok, err := p.FindPullRequest("feat/y", "main"); err != nil || ok {
+		t.Errorf("no pull request: ok = %v, err = %v", ok, err)
+	}
+}
+
+func TestGitLab_CIStatus(t *testing.T) {
+	for out, want := range map[string]CIState{
+		`[]`:                     CINone,
+		`[{"status":"running"}]`: CIPending,
+		`[{"status":"success"}]`: CISuccess,
+		`[{"status":"failed"}]`:  CIFailure,
+	} {
+		f := &fakeRun{out: map[string]string{"glab api": out}}
+		p := &gitlabProvider{repoPath: "/repo", run: f.run}
+		if got, err := p.CIStatus("feat/x"); err != nil || got != want {
+			t.Errorf("%s: CIStatus = %q, %v; want %q", out, got, err, want)
+		}
+		if !strings.Contains(f.calls[0], "ref=feat%2Fx") {
+			t.Errorf("call = %q", f.calls[0])
+		}
+	}
+}
+
+func TestGitea(t *testing.T) {
+	var gotAuth string
+	var gotReviewers []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/api/v1/repos/acme/widget/pulls":
+			w.Write([]byte(`{"number":7,"html_url":"https://git.acme.dev/acme/widget/pulls/7"}`))
+		case "/api/v1/repos/acme/widget/commits/abc123/status":
+			w.Write([]byte(`{"state":"pending","total_count":2}`))
+		case "/api/v1/repos/acme/widget/pulls/main/feat/x":
+			w.Write([]byte(`{"number":7,"html_url":"https://git.acme.dev/acme/widget/pulls/7","state":"closed","merged":true}`))
+		case "/api/v1/repos/acme/widget/pulls/7/requested_reviewers":
+			var body struct {
+				Reviewers []string `json:"reviewers"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			gotReviewers = body.Reviewers
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := &fakeRun{out: map[string]string{
+		"git remote get-url origin": "git@git.acme.dev:acme/widget.git",
+		"git rev-parse feat/x":      "abc123\n",
+	}}
+	p, err := newProvider("/repo", Config{Provider: "gitea", URL: srv.URL, Token: "tok"}, f.run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := p.CreatePullRequest(PullRequestOptions{Head: "feat/x", Base: "main", Title: "T"})
+	if err != nil || pr.Number != 7 {
+		t.Errorf("CreatePullRequest = %+v, %v", pr, err)
+	}
+	if gotAuth != "token tok" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if state, err := p.CIStatus("feat/x"); err != nil || state != CIPending {
+		t.Errorf("CIStatus = %q, %v", state, err)
+	}
+	if err := p.RequestReview(7, []string{"ann"}); err != nil || len(gotReviewers) != 1 {
+		t.Errorf("RequestReview: %v, reviewers = %v", err, gotReviewers)
+	}
+	if pr, ok, err := p.FindPullRequest("feat/x", "main"); err != nil || !ok || pr.State != PRMerged {
+		t.Errorf("FindPullRequest = %+v, %v, %v", pr, ok, err)
+	}
+	if _, ok, err := p.FindPullRequest("feat/none", "main"); err != nil || ok {
+		t.Errorf("FindPullRequest without a pull request: ok = %v, err = %v", ok, err)
+	}
+}
+
+func TestBitbucket(t *testing.T) {
+	var put map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "me" || pass != "app-pw" {
+			t.Errorf("basic auth = %q:%q", user, pass)
+		}
+		switch {
+		case r.URL.Path == "/repositories/team/widget/pullrequests":
+			w.Write([]byte(`{"id":3,"links":{"html":{"href":"https://bitbucket.org/team/widget/pull-requests/3"}}}`))
+		case r.URL.Path == "/repositories/team/widget/commit/abc123/statuses":
+			w.Write([]byte(`{"values":[{"state":"SUCCESSFUL"},{"state":"FAILED"}]}`))
+		case r.URL.Path == "/repositories/team/widget/pullrequests/3" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"title":"T","reviewers":[{"account_id":"a1"}]}`))
+		case r.URL.Path == "/repositories/team/widget/pullrequests/3":
+			json.NewDecoder(r.Body).Decode(&put)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := &fakeRun{out: map[string]string{
+		"git remote get-url origin": "git@bitbucket.org:team/widget.git",
+		"git rev-parse feat/x":      "abc123\n",
+	}}
+	p, err := newProvider("/repo", Config{URL: srv.URL, Username: "me", Token: "app-pw"}, f.run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr, err := p.CreatePullRequest(PullRequestOptions{Head: "feat/x", Base: "main", Title: "T"}); err != nil || pr.Number != 3 {
+		t.Errorf("CreatePullRequest = %+v, %v", pr, err)
+	}
+	if state, err := p.CIStatus("feat/x"); err != nil || state != CIFailure {
+		t.Errorf("CIStatus = %q, %v", state, err)
+	}
+	if err := p.RequestReview(3, []string{"a2"}); err != nil {
+		t.Fatal(err)
+	}
+	if reviewers, _ := put["reviewers"].([]any); len(reviewers) != 2 || put["title"] != "T" {
+		t.Errorf("PUT body = %v", put)
+	}
+}
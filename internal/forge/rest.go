@@ -0,0 +1,330 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// bitbucketAPIURL is Bitbucket Cloud's REST API base URL.
+const bitbucketAPIURL = "https://api.bitbucket.org/2.0"
+
+// errNotFound is wrapped by api errors for HTTP 404 responses.
+var errNotFound = errors.New("not found")
+
+// api is a small JSON REST client.
+type api struct {
+	base   string
+	auth   string // Authorization header value
+	client *http.Client
+}
+
+func newAPI(base, auth string) *api {
+	return &api{base: base, auth: auth, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func basicAuth(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+// do sends in (if non-nil) as JSON to path and decodes the response into
+// out (if non-nil).
+func (a *api) do(method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, a.base+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", a.auth)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s %s: %w", method, path, errNotFound)
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: HTTP %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+// giteaProvider drives Gitea (and Forgejo) over its REST API.
+type giteaProvider struct {
+	repoPath string
+	run      runner
+	remote   Remote
+	api      *api
+}
+
+func (p *giteaProvider) Kind() Kind { return Gitea }
+
+func (p *giteaProvider) Push(branch string) error { return push(p.run, p.repoPath, branch) }
+
+func (p *giteaProvider) repoPathAPI() string {
+	return "/repos/" + url.PathEscape(p.remote.Owner) + "/" + url.PathEscape(p.remote.Repo)
+}
+
+func (p *giteaProvider) CreatePullRequest(opts PullRequestOptions) (PullRequest, error) {
+	title := opts.Title
+	if opts.Draft {
+		// Gitea marks pull requests as work in progress by title prefix.
+		title = "WIP: " + title
+	}
+	body := map[string]any{"head": opts.Head, "base": opts.Base, "title": title, "body": opts.Body}
+	if len(opts.Labels) > 0 {
+		ids, err := p.labelIDs(opts.Labels)
+		if err != nil {
+			return PullRequest{}, err
+		}
+		body["labels"] = ids
+	}
+	var pr struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := p.api.do(http.MethodPost, p.repoPathAPI()+"/pulls", body, &pr); err != nil {
+		return PullRequest{}, fmt.Errorf("gitea: %w", err)
+	}
+	return PullRequest{Number: pr.Number, URL: pr.HTMLURL}, nil
+}
+
+// labelIDs resolves label names to the IDs Gitea's pull request API
+// takes.
+func (p *giteaProvider) labelIDs(names []string) ([]int64, error) {
+	var labels []struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := p.api.do(http.MethodGet, p.repoPathAPI()+"/labels?limit=100", nil, &labels); err != nil {
+		return nil, fmt.Errorf("gitea: %w", err)
+	}
+	var ids []int64
+	for _, name := range names {
+		found := false
+		for _, l := range labels {
+			if strings.EqualFold(l.Name, name) {
+				ids = append(ids, l.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("gitea: no label %q in %s/%s", name, p.remote.Owner, p.remote.Repo)
+		}
+	}
+	return ids, nil
+}
+
+func (p *giteaProvider) CIStatus(branch string) (CIState, error) {
+	sha, err := revParse(p.run, p.repoPath, branch)
+	if err != nil {
+		return CINone, err
+	}
+	var status struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := p.api.do(http.MethodGet, p.repoPathAPI()+"/commits/"+sha+"/status", nil, &status); err != nil {
+		return CINone, fmt.Errorf("gitea: %w", err)
+	}
+	if status.TotalCount == 0 {
+		return CINone, nil
+	}
+	switch status.State {
+	case "success", "warning":
+		return CISuccess, nil
+	case "failure", "error":
+		return CIFailure, nil
+	default:
+		return CIPending, nil
+	}
+}
+
+func (p *giteaProvider) RequestReview(number int, reviewers []string) error {
+	err := p.api.do(http.MethodPost, fmt.Sprintf("%s/pulls/%d/requested_reviewers", p.repoPathAPI(), number),
+		map[string][]string{"reviewers": reviewers}, nil)
+	if err != nil {
+		return fmt.Errorf("gitea: %w", err)
+	}
+	return nil
+}
+
+func (p *giteaProvider) FindPullRequest(head, base string) (PullRequest, bool, error) {
+	var pr struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+		State   string `json:"state"`
+		Merged  bool   `json:"merged"`
+		Head    struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	err := p.api.do(http.MethodGet, p.repoPathAPI()+"/pulls/"+url.PathEscape(base)+"/"+url.PathEscape(head), nil, &pr)
+	if errors.Is(err, errNotFound) {
+		return PullRequest{}, false, nil
+	}
+	if err != nil {
+		return PullRequest{}, false, fmt.Errorf("gitea: %w", err)
+	}
+	state := PROpen
+	switch {
+	case pr.Merged:
+		state = PRMerged
+	case pr.State == "closed":
+		state = PRClosed
+	}
+	return PullRequest{Number: pr.Number, URL: pr.HTMLURL, State: state, HeadOID: pr.Head.SHA}, true, nil
+}
+
+// bitbucketProvider drives Bitbucket Cloud over its REST API.
+type bitbucketProvider struct {
+	repoPath string
+	run      runner
+	remote   Remote
+	api      *api
+}
+
+func (p *bitbucketProvider) Kind() Kind { return Bitbucket }
+
+func (p *bitbucketProvider) Push(branch string) error { return push(p.run, p.repoPath, branch) }
+
+func (p *bitbucketProvider) repoPathAPI() string {
+	return "/repositories/" + url.PathEscape(p.remote.Owner) + "/" + url.PathEscape(p.remote.Repo)
+}
+
+func (p *bitbucketProvider) CreatePullRequest(opts PullRequestOptions) (PullRequest, error) {
+	var pr struct {
+		ID    int `json:"id"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	err := p.api.do(http.MethodPost, p.repoPathAPI()+"/pullrequests", map[string]any{
+		"title":       opts.Title,
+		"description": opts.Body,
+		"draft":       opts.Draft,
+		"source":      map[string]any{"branch": map[string]string{"name": opts.Head}},
+		"destination": map[string]any{"branch": map[string]string{"name": opts.Base}},
+	}, &pr)
+	if err != nil {
+		return PullRequest{}, fmt.Errorf("bitbucket: %w", err)
+	}
+	return PullRequest{Number: pr.ID, URL: pr.Links.HTML.Href}, nil
+}
+
+func (p *bitbucketProvider) CIStatus(branch string) (CIState, error) {
+	sha, err := revParse(p.run, p.repoPath, branch)
+	if err != nil {
+		return CINone, err
+	}
+	var statuses struct {
+		Values []struct {
+			State string `json:"state"`
+		} `json:"values"`
+	}
+	if err := p.api.do(http.MethodGet, p.repoPathAPI()+"/commit/"+sha+"/statuses", nil, &statuses); err != nil {
+		return CINone, fmt.Errorf("bitbucket: %w", err)
+	}
+	if len(statuses.Values) == 0 {
+		return CINone, nil
+	}
+	state := CISuccess
+	for _, s := range statuses.Values {
+		switch s.State {
+		case "FAILED", "STOPPED":
+			return CIFailure, nil
+		case "INPROGRESS":
+			state = CIPending
+		}
+	}
+	return state, nil
+}
+
+// RequestReview adds reviewers, given as Bitbucket account IDs, to the
+// pull request's existing reviewers.
+func (p *bitbucketProvider) RequestReview(number int, reviewers []string) error {
+	path := fmt.Sprintf("%s/pullrequests/%d", p.repoPathAPI(), number)
+	type reviewer struct {
+		AccountID string `json:"account_id"`
+	}
+	var pr struct {
+		Title     string     `json:"title"`
+		Reviewers []reviewer `json:"reviewers"`
+	}
+	if err := p.api.do(http.MethodGet, path, nil, &pr); err != nil {
+		return fmt.Errorf("bitbucket: %w", err)
+	}
+	for _, r := range reviewers {
+		pr.Reviewers = append(pr.Reviewers, reviewer{AccountID: r})
+	}
+	if err := p.api.do(http.MethodPut, path, pr, nil); err != nil {
+		return fmt.Errorf("bitbucket: %w", err)
+	}
+	return nil
+}
+
+func (p *bitbucketProvider) FindPullRequest(head, base string) (PullRequest, bool, error) {
+	q := fmt.Sprintf(`source.branch.name="%s" AND destination.branch.name="%s"`, head, base)
+	var prs struct {
+		Values []struct {
+			ID     int    `json:"id"`
+			State  string `json:"state"`
+			Source struct {
+				Commit struct {
+					Hash string `json:"hash"`
+				} `json:"commit"`
+			} `json:"source"`
+			Links struct {
+				HTML struct {
+					Href string `json:"href"`
+				} `json:"html"`
+			} `json:"links"`
+		} `json:"values"`
+	}
+	// Without explicit states Bitbucket only lists open pull requests.
+	path := p.repoPathAPI() + "/pullrequests?pagelen=1&sort=-created_on&state=OPEN&state=MERGED&state=DECLINED&state=SUPERSEDED&q=" + url.QueryEscape(q)
+	if err := p.api.do(http.MethodGet, path, nil, &prs); err != nil {
+		return PullRequest{}, false, fmt.Errorf("bitbucket: %w", err)
+	}
+	if len(prs.Values) == 0 {
+		return PullRequest{}, false, nil
+	}
+	pr := prs.Values[0]
+	state := PROpen
+	switch pr.State {
+	case "MERGED":
+		state = PRMerged
+	case "DECLINED", "SUPERSEDED":
+		state = PRClosed
+	}
+	return PullRequest{Number: pr.ID, URL: pr.Links.HTML.Href, State: state, HeadOID: pr.Source.Commit.Hash}, true, nil
+}
@@ -0,0 +1,128 @@
+package git
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// archivePrefix namespaces archived branches:
+// refs/mastermind/archive/<date>/<branch>. Refs outside refs/heads are
+// left out of branch lists but keep their commits from being collected.
+const archivePrefix = "refs/mastermind/archive/"
+
+// ArchivedBranch is a branch moved into the archive namespace.
+type ArchivedBranch struct {
+	Ref    string
+	Branch string
+	// Date is the day the branch was archived, as 2006-01-02, with a
+	// "-<n>" suffix when it was archived more than once that day.
+	Date      string
+	Commit    string
+	Subject   string
+	Committed time.Time
+}
+
+// ArchiveBranch moves branch to refs/mastermind/archive/<date>/<branch>
+// and returns the new ref. Its commits stay reachable, so RestoreArchived
+// can bring it back.
+func ArchiveBranch(repoPath, branch string) (string, error) {
+	out, err := output("-C", repoPath, "rev-parse", "--verify", "refs/heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("branch %s not found: %w", branch, err)
+	}
+	commit := strings.TrimSpace(string(out))
+
+	date := time.Now().Format("2006-01-02")
+	ref := archivePrefix + date + "/" + branch
+	for n := 2; run("-C", repoPath, "rev-parse", "--verify", "--quiet", ref) == nil; n++ {
+		ref = fmt.Sprintf("%s%s-%d/%s", archivePrefix, date, n, branch)
+	}
+	// An empty old value makes update-ref refuse to overwrite a ref.
+	if err := run("-C", repoPath, "update-ref", ref, commit, ""); err != nil {
+		return "", fmt.Errorf("failed to archive branch %s: %w", branch, err)
+	}
+	if err := DeleteBranch(repoPath, branch); err != nil {
+		run("-C", repoPath, "update-ref", "-d", ref)
+		return "", err
+	}
+	return ref, nil
+}
+
+// ListArchived returns the archived branches, most recently archived
+// first.
+func ListArchived(repoPath string) ([]ArchivedBranch, error) {
+	out, err := output("-C", repoPath, "for-each-ref",
+		"--format=%(refname)%00%(objectname:short)%00%(committerdate:unix)%00%(contents:subject)", archivePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived branches: %w", err)
+	}
+	var archived []ArchivedBranch
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		date, branch, ok := strings.Cut(strings.TrimPrefix(fields[0], archivePrefix), "/")
+		if !ok {
+			continue
+		}
+		unix, _ := strconv.ParseInt(fields[2], 10, 64)
+		archived = append(archived, ArchivedBranch{
+			Ref:       fields[0],
+			Branch:    branch,
+			Date:      date,
+			Commit:    fields[1],
+			Subject:   fields[3],
+			Committed: time.Unix(unix, 0),
+		})
+	}
+	slices.SortStableFunc(archived, func(a, b ArchivedBranch) int {
+		return archiveOrder(b.Date) - archiveOrder(a.Date)
+	})
+	return archived, nil
+}
+
+// archiveOrder orders archive dates chronologically, counting a -<n>
+// suffix as archived later that day.
+func archiveOrder(date string) int {
+	day, n := date, 1
+	if len(date) > len("2006-01-02") {
+		day = date[:len("2006-01-02")]
+		n, _ = strconv.Atoi(date[len("2006-01-02")+1:])
+	}
+	t, _ := time.Parse("2006-01-02", day)
+	return int(t.Unix()/86400)*1000 + n
+}
+
+// RestoreArchived recreates the branch archived as ref and removes it
+// from the archive. It fails if a branch of that name exists again.
+func RestoreArchived(repoPath, ref string) (string, error) {
+	_, branch, ok := strings.Cut(strings.TrimPrefix(ref, archivePrefix), "/")
+	if !strings.HasPrefix(ref, archivePrefix) || !ok {
+		return "", fmt.Errorf("%s is not an archived branch", ref)
+	}
+	if BranchExists(repoPath, "refs/heads/"+branch) {
+		return "", fmt.Errorf("branch %s already exists", branch)
+	}
+	if err := run("-C", repoPath, "branch", branch, ref); err != nil {
+		return "", fmt.Errorf("failed to restore branch %s: %w", branch, err)
+	}
+	if err := DeleteArchived(repoPath, ref); err != nil {
+		return "", err
+	}
+	return branch, nil
+}
+
+// DeleteArchived removes ref from the archive for good.
+func DeleteArchived(repoPath, ref string) error {
+	if !strings.HasPrefix(ref, archivePrefix) {
+		return fmt.Errorf("%s is not an archived branch", ref)
+	}
+	if err := run("-C", repoPath, "update-ref", "-d", ref); err != nil {
+		return fmt.Errorf("failed to delete archived branch %s: %w", ref, err)
+	}
+	return nil
+}
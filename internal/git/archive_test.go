@@ -0,0 +1,62 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveBranch_RoundTrip(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+	CreateBranch(repo, "feat/x", defaultBranch)
+	head := mustHeadCommit(t, repo, "feat/x")
+
+	ref, err := ArchiveBranch(repo, "feat/x")
+	if err != nil {
+		t.Fatalf("ArchiveBranch: %v", err)
+	}
+	want := archivePrefix + time.Now().Format("2006-01-02") + "/feat/x"
+	if ref != want {
+		t.Errorf("ref = %q, want %q", ref, want)
+	}
+	if BranchExists(repo, "refs/heads/feat/x") {
+		t.Error("archived branch still listed as a branch")
+	}
+
+	// Archiving a branch of the same name again the same day keeps both.
+	CreateBranch(repo, "feat/x", defaultBranch)
+	ref2, err := ArchiveBranch(repo, "feat/x")
+	if err != nil {
+		t.Fatalf("ArchiveBranch again: %v", err)
+	}
+	if ref2 == ref || !strings.HasSuffix(ref2, "-2/feat/x") {
+		t.Errorf("second ref = %q, want a -2 date suffix", ref2)
+	}
+
+	archived, err := ListArchived(repo)
+	if err != nil {
+		t.Fatalf("ListArchived: %v", err)
+	}
+	if len(archived) != 2 || archived[0].Ref != ref2 || archived[0].Branch != "feat/x" || archived[0].Subject != "initial commit" {
+		t.Fatalf("archived = %+v, want both, newest first", archived)
+	}
+
+	branch, err := RestoreArchived(repo, ref)
+	if err != nil {
+		t.Fatalf("RestoreArchived: %v", err)
+	}
+	if branch != "feat/x" || mustHeadCommit(t, repo, "feat/x") != head {
+		t.Errorf("restored %q at %s, want feat/x at %s", branch, mustHeadCommit(t, repo, "feat/x"), head)
+	}
+	if _, err := RestoreArchived(repo, ref2); err == nil {
+		t.Error("expected error restoring over an existing branch")
+	}
+
+	if err := DeleteArchived(repo, ref2); err != nil {
+		t.Fatalf("DeleteArchived: %v", err)
+	}
+	if archived, _ := ListArchived(repo); len(archived) != 0 {
+		t.Errorf("archived = %+v, want none left", archived)
+	}
+}
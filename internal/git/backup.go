@@ -0,0 +1,98 @@
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BackupPatchName is the file in a backup holding the diff of the tracked
+// files against HEAD, which `git apply` restores.
+const BackupPatchName = "changes.patch"
+
+// BackupChanges saves the uncommitted changes in wtPath to dir: the diff
+// of tracked files against HEAD as BackupPatchName, and a copy of every
+// changed or untracked (non-ignored) file under dir/files. It reports
+// false, creating nothing, when there are no changes.
+func BackupChanges(wtPath, dir string) (bool, error) {
+	diff, err := output("-C", wtPath, "diff", "HEAD", "--binary")
+	if err != nil {
+		return false, fmt.Errorf("diff uncommitted changes: %w", err)
+	}
+	files, err := changedFiles(wtPath)
+	if err != nil {
+		return false, err
+	}
+	if len(diff) == 0 && len(files) == 0 {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, err
+	}
+	if len(diff) > 0 {
+		if err := os.WriteFile(filepath.Join(dir, BackupPatchName), diff, 0o644); err != nil {
+			return false, err
+		}
+	}
+	for _, rel := range files {
+		src := filepath.Join(wtPath, rel)
+		if _, err := os.Lstat(src); os.IsNotExist(err) {
+			continue // deleted; the patch records it
+		}
+		if err := copyFile(src, filepath.Join(dir, "files", rel)); err != nil {
+			return false, fmt.Errorf("back up %s: %w", rel, err)
+		}
+	}
+	return true, nil
+}
+
+// ChangeSnapshot maps each file in wtPath that differs from HEAD or is
+// untracked (and not ignored) to a hash of its content, or to "" when it
+// was deleted. Comparing two snapshots tells which files were changed in
+// between.
+func ChangeSnapshot(wtPath string) (map[string]string, error) {
+	files, err := changedFiles(wtPath)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(map[string]string, len(files))
+	for _, rel := range files {
+		data, err := os.ReadFile(filepath.Join(wtPath, rel))
+		if os.IsNotExist(err) {
+			snapshot[rel] = ""
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", rel, err)
+		}
+		sum := sha256.Sum256(data)
+		snapshot[rel] = hex.EncodeToString(sum[:])
+	}
+	return snapshot, nil
+}
+
+// changedFiles lists the files in wtPath that differ from HEAD, then the
+// untracked (non-ignored) ones.
+func changedFiles(wtPath string) ([]string, error) {
+	changedOut, err := output("-C", wtPath, "diff", "HEAD", "--name-only", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("list changed files: %w", err)
+	}
+	untrackedOut, err := output("-C", wtPath, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("list untracked files: %w", err)
+	}
+	var files []string
+	for _, out := range [][]byte{changedOut, untrackedOut} {
+		for _, rel := range strings.Split(string(out), "\x00") {
+			if rel != "" {
+				files = append(files, rel)
+			}
+		}
+	}
+	return files, nil
+}
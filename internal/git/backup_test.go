@@ -0,0 +1,73 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBackupChanges(t *testing.T) {
+	repo := setupTestRepo(t)
+	commitFile(t, repo, "a.txt", "one\n", "add a")
+	commitFile(t, repo, "gone.txt", "bye\n", "add gone")
+	dir := filepath.Join(t.TempDir(), "backup")
+
+	if saved, err := BackupChanges(repo, dir); err != nil || saved {
+		t.Fatalf("BackupChanges on a clean tree = %v, %v, want nothing saved", saved, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("nothing should be created for a clean tree")
+	}
+
+	os.WriteFile(filepath.Join(repo, "a.txt"), []byte("two\n"), 0o644)
+	os.Remove(filepath.Join(repo, "gone.txt"))
+	os.MkdirAll(filepath.Join(repo, "sub"), 0o755)
+	os.WriteFile(filepath.Join(repo, "sub", "new.txt"), []byte("new\n"), 0o644)
+	os.WriteFile(filepath.Join(repo, "my notes.txt"), []byte("notes\n"), 0o644)
+
+	if saved, err := BackupChanges(repo, dir); err != nil || !saved {
+		t.Fatalf("BackupChanges = %v, %v, want saved", saved, err)
+	}
+	for path, want := range map[string]string{"files/a.txt": "two\n", "files/sub/new.txt": "new\n", "files/my notes.txt": "notes\n"} {
+		if got, err := os.ReadFile(filepath.Join(dir, path)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", path, got, err, want)
+		}
+	}
+	patch, err := os.ReadFile(filepath.Join(dir, BackupPatchName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"+two", "deleted file mode"} {
+		if !strings.Contains(string(patch), want) {
+			t.Errorf("patch should contain %q:\n%s", want, patch)
+		}
+	}
+}
+
+func TestChangeSnapshot(t *testing.T) {
+	repo := setupTestRepo(t)
+	commitFile(t, repo, "a.txt", "one\n", "add a")
+	commitFile(t, repo, "gone.txt", "bye\n", "add gone")
+
+	os.WriteFile(filepath.Join(repo, "a.txt"), []byte("two\n"), 0o644)
+	os.Remove(filepath.Join(repo, "gone.txt"))
+	os.WriteFile(filepath.Join(repo, "new file.txt"), []byte("new\n"), 0o644)
+
+	before, err := ChangeSnapshot(repo)
+	if err != nil {
+		t.Fatalf("ChangeSnapshot: %v", err)
+	}
+	if len(before) != 3 || before["a.txt"] == "" || before["gone.txt"] != "" || before["new file.txt"] == "" {
+		t.Fatalf("snapshot = %v, want a.txt and new file.txt hashed, gone.txt deleted", before)
+	}
+
+	os.WriteFile(filepath.Join(repo, "a.txt"), []byte("three\n"), 0o644)
+	after, err := ChangeSnapshot(repo)
+	if err != nil {
+		t.Fatalf("ChangeSnapshot: %v", err)
+	}
+	if after["a.txt"] == before["a.txt"] || after["new file.txt"] != before["new file.txt"] {
+		t.Errorf("only a.txt should hash differently: before %v, after %v", before, after)
+	}
+}
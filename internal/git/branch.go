@@ -2,11 +2,14 @@ package git
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -16,7 +19,7 @@ type Branch struct {
 }
 
 func ListBranches(repoPath string) ([]Branch, error) {
-	out, err := exec.Command("git", "-C", repoPath, "branch", "--format=%(HEAD)|%(refname:short)").Output()
+	out, err := output("-C", repoPath, "branch", "--format=%(HEAD)|%(refname:short)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -39,7 +42,7 @@ func ListBranches(repoPath string) ([]Branch, error) {
 }
 
 func CreateBranch(repoPath, branchName, baseBranch string) error {
-	err := exec.Command("git", "-C", repoPath, "branch", branchName, baseBranch).Run()
+	err := run("-C", repoPath, "branch", branchName, baseBranch)
 	if err != nil {
 		return fmt.Errorf("failed to create branch %s from %s: %w", branchName, baseBranch, err)
 	}
@@ -64,8 +67,80 @@ func IsBranchMerged(repoPath, branch, baseBranch string) bool {
 	return err == nil
 }
 
+// UnmergedCommits counts the commits on branch that no other branch, tag
+// or remote-tracking branch reaches: the commits deleting it with `git
+// branch -D` would lose.
+func UnmergedCommits(repoPath, branch string) (int, error) {
+	out, err := output("-C", repoPath, "rev-list", "--count", "refs/heads/"+branch,
+		"--not", "--exclude="+branch, "--branches", "--tags", "--remotes")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unmerged commits of %s: %w", branch, err)
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(string(out)))
+	return n, nil
+}
+
+// PushUpstream pushes branch to the branch it tracks on its remote.
+func PushUpstream(repoPath, branch string) error {
+	remote, err := output("-C", repoPath, "config", "branch."+branch+".remote")
+	if err != nil {
+		return fmt.Errorf("%s has no upstream branch", branch)
+	}
+	merge, err := output("-C", repoPath, "config", "branch."+branch+".merge")
+	if err != nil {
+		return fmt.Errorf("%s has no upstream branch", branch)
+	}
+	refspec := "refs/heads/" + branch + ":" + strings.TrimSpace(string(merge))
+	if err := run("-C", repoPath, "push", strings.TrimSpace(string(remote)), refspec); err != nil {
+		return fmt.Errorf("failed to push %s: %w", branch, err)
+	}
+	return nil
+}
+
+// FastForwardFromOrigin fetches branch from origin and fast-forwards the
+// local branch to it, in the worktree it is checked out in, if any. It
+// reports whether the branch moved, and fails when the local branch has
+// commits origin's does not.
+func FastForwardFromOrigin(repoPath, branch string) (bool, error) {
+	before, err := HeadCommit(repoPath, "refs/heads/"+branch)
+	if err != nil {
+		return false, err
+	}
+	if err := run("-C", repoPath, "fetch", "--quiet", "origin", "refs/heads/"+branch); err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", branch, err)
+	}
+	fetched, err := HeadCommit(repoPath, "FETCH_HEAD")
+	if err != nil {
+		return false, err
+	}
+	if fetched == before || IsAncestor(repoPath, fetched, before) {
+		return false, nil
+	}
+	if !IsAncestor(repoPath, before, fetched) {
+		return false, fmt.Errorf("%s has diverged from origin/%s, not fast-forwarding it", branch, branch)
+	}
+	if wt := WorktreeForBranch(repoPath, branch); wt != "" {
+		if err := run("-C", wt, "merge", "--ff-only", "--quiet", fetched); err != nil {
+			return false, fmt.Errorf("failed to fast-forward %s: %w", branch, err)
+		}
+		return true, nil
+	}
+	if err := run("-C", repoPath, "update-ref", "refs/heads/"+branch, fetched, before); err != nil {
+		return false, fmt.Errorf("failed to fast-forward %s: %w", branch, err)
+	}
+	return true, nil
+}
+
+// FetchBranch fetches branch from origin into origin/<branch>.
+func FetchBranch(repoPath, branch string) error {
+	if err := run("-C", repoPath, "fetch", "--quiet", "origin", "refs/heads/"+branch+":refs/remotes/origin/"+branch); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", branch, err)
+	}
+	return nil
+}
+
 func CurrentBranch(repoPath string) (string, error) {
-	out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	out, err := output("-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
@@ -73,7 +148,7 @@ func CurrentBranch(repoPath string) (string, error) {
 }
 
 func HeadCommit(repoOrWtPath, ref string) (string, error) {
-	out, err := exec.Command("git", "-C", repoOrWtPath, "rev-parse", ref).Output()
+	out, err := output("-C", repoOrWtPath, "rev-parse", ref)
 	if err != nil {
 		return "", fmt.Errorf("failed to rev-parse %s: %w", ref, err)
 	}
@@ -85,9 +160,24 @@ func IsAncestor(repoPath, ancestor, descendant string) bool {
 	return err == nil
 }
 
-func UpdateBranchRef(repoPath, branch, targetCommit string) error {
-	err := exec.Command("git", "-C", repoPath, "update-ref", "refs/heads/"+branch, targetCommit).Run()
-	if err != nil {
+// ErrRefMoved is returned by UpdateBranchRef when the branch no longer
+// points at the commit it was expected to.
+var ErrRefMoved = errors.New("branch moved")
+
+// UpdateBranchRef points branch at targetCommit. With oldCommit set, the
+// update only happens if branch still points at it, atomically, and
+// fails with ErrRefMoved otherwise.
+func UpdateBranchRef(repoPath, branch, targetCommit, oldCommit string) error {
+	args := []string{"-C", repoPath, "update-ref", "refs/heads/" + branch, targetCommit}
+	if oldCommit != "" {
+		args = append(args, oldCommit)
+	}
+	if err := run(args...); err != nil {
+		if oldCommit != "" {
+			if head, herr := HeadCommit(repoPath, "refs/heads/"+branch); herr == nil && head != oldCommit {
+				err = ErrRefMoved
+			}
+		}
 		return fmt.Errorf("failed to update-ref %s to %s: %w", branch, targetCommit, err)
 	}
 	return nil
@@ -101,10 +191,20 @@ func CheckoutBranch(wtPath, branch string) error {
 	return nil
 }
 
-func MergeInWorktree(wtPath, mergeBranch string) (conflicted bool, err error) {
-	out, err := exec.Command("git", "-C", wtPath, "merge", mergeBranch).CombinedOutput()
+// MergeInWorktree merges mergeBranch into the branch checked out in
+// wtPath, with rerere when rerere is true (see rerereArgs). On conflicts
+// conflicted is true and the merge is left in progress, with any
+// resolutions rerere replayed staged for review. git's output, including
+// that of any custom merge driver, is logged on conflicts.
+func MergeInWorktree(wtPath, mergeBranch string, rerere bool) (conflicted bool, err error) {
+	args := []string{"-C", wtPath}
+	if rerere {
+		args = append(args, rerereArgs(wtPath)...)
+	}
+	out, err := exec.Command("git", append(args, "merge", mergeBranch)...).CombinedOutput()
 	if err != nil {
 		if strings.Contains(string(out), "CONFLICT") {
+			slog.Info("merge conflicted", "path", wtPath, "branch", mergeBranch, "output", strings.TrimSpace(string(out)))
 			return true, nil
 		}
 		return false, fmt.Errorf("failed to merge %s: %s (%w)", mergeBranch, strings.TrimSpace(string(out)), err)
@@ -128,6 +228,43 @@ func MergeFFOnly(wtPath, branch string) error {
 	return nil
 }
 
+// Rebase rebases the branch checked out in wtPath onto onto. On
+// conflicts the rebase is aborted, leaving the branch untouched, and
+// conflicted is true.
+func Rebase(wtPath, onto string) (conflicted bool, err error) {
+	return rebase(wtPath, onto, onto)
+}
+
+// RebaseOnto is Rebase for only the commits of the branch checked out in
+// wtPath that upstream doesn't have, e.g. when upstream was squashed into
+// onto and its own commits must not be replayed.
+func RebaseOnto(wtPath, onto, upstream string) (conflicted bool, err error) {
+	return rebase(wtPath, onto, "--onto", onto, upstream)
+}
+
+func rebase(wtPath, onto string, args ...string) (conflicted bool, err error) {
+	out, err := exec.Command("git", append([]string{"-C", wtPath, "rebase"}, args...)...).CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "CONFLICT") {
+			_ = exec.Command("git", "-C", wtPath, "rebase", "--abort").Run()
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to rebase onto %s: %s (%w)", onto, strings.TrimSpace(string(out)), err)
+	}
+	return false, nil
+}
+
+// SquashCommit commits the tree checked out in wtPath as a single commit
+// on top of parent and returns it. Neither the worktree nor any branch is
+// changed; the caller moves a branch to the commit.
+func SquashCommit(wtPath, parent, message string) (string, error) {
+	out, err := output("-C", wtPath, "commit-tree", "HEAD^{tree}", "-p", parent, "-m", message)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit squashed changes: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 func WorktreeForBranch(repoPath, branch string) string {
 	worktrees, err := ListWorktrees(repoPath)
 	if err != nil {
@@ -142,7 +279,7 @@ func WorktreeForBranch(repoPath, branch string) string {
 }
 
 func ConflictFiles(wtPath string) ([]string, error) {
-	out, err := exec.Command("git", "-C", wtPath, "diff", "--name-only", "--diff-filter=U").Output()
+	out, err := output("-C", wtPath, "diff", "--name-only", "--diff-filter=U")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list conflict files: %w", err)
 	}
@@ -155,13 +292,29 @@ func ConflictFiles(wtPath string) ([]string, error) {
 	return files, nil
 }
 
+// ChangedFiles lists the files changed on branch since it diverged from
+// base.
+func ChangedFiles(repoPath, base, branch string) ([]string, error) {
+	out, err := output("-C", repoPath, "diff", "--name-only", base+"..."+branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
 // CopyUncommittedChanges generates a diff of all uncommitted changes (staged
 // and unstaged) in srcWT and applies it to dstWT. Untracked files (newly
 // created, non-ignored) are also copied. Returns nil when there are no
 // uncommitted changes to copy.
 func CopyUncommittedChanges(srcWT, dstWT string) error {
 	// Apply tracked-file diffs (staged + unstaged).
-	diff, err := exec.Command("git", "-C", srcWT, "diff", "HEAD").Output()
+	diff, err := output("-C", srcWT, "diff", "HEAD")
 	if err != nil {
 		return fmt.Errorf("diff uncommitted changes: %w", err)
 	}
@@ -175,7 +328,7 @@ func CopyUncommittedChanges(srcWT, dstWT string) error {
 	}
 
 	// Copy untracked (newly created, non-ignored) files.
-	untrackedOut, err := exec.Command("git", "-C", srcWT, "ls-files", "--others", "--exclude-standard").Output()
+	untrackedOut, err := output("-C", srcWT, "ls-files", "--others", "--exclude-standard")
 	if err != nil {
 		return fmt.Errorf("list untracked files: %w", err)
 	}
@@ -190,6 +343,18 @@ func CopyUncommittedChanges(srcWT, dstWT string) error {
 	return nil
 }
 
+// ApplyPatch applies a unified diff to the working tree at wtPath,
+// leaving the result as uncommitted changes.
+func ApplyPatch(wtPath string, patch []byte) error {
+	cmd := exec.Command("git", "-C", wtPath, "apply", "--whitespace=nowarn")
+	cmd.Stdin = bytes.NewReader(patch)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git apply: %s (%w)", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
 // copyFile copies a single file from src to dst, creating parent directories
 // as needed and preserving the source file's permissions.
 func copyFile(src, dst string) error {
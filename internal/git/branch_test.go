@@ -1,9 +1,11 @@
 package git
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -46,6 +48,22 @@ func commitFile(t *testing.T, dir, filename, content, message string) {
 	}
 }
 
+func TestCreateBranch_ErrorIncludesStderr(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	err := CreateBranch(repo, "feat/x", "no-such-base")
+	if err == nil {
+		t.Fatal("expected error for missing base branch")
+	}
+	var gitErr *Error
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("error %T does not wrap *git.Error", err)
+	}
+	if !strings.Contains(err.Error(), "no-such-base") || gitErr.Stderr == "" {
+		t.Errorf("error = %q, want git's stderr attached", err)
+	}
+}
+
 func TestCreateBranch(t *testing.T) {
 	repo := setupTestRepo(t)
 
@@ -158,7 +176,8 @@ func TestUpdateBranchRef(t *testing.T) {
 	commitFile(t, repo, "f.txt", "data", "advance")
 	newHead, _ := HeadCommit(repo, "HEAD")
 
-	if err := UpdateBranchRef(repo, "target", newHead); err != nil {
+	oldHead, _ := HeadCommit(repo, "target")
+	if err := UpdateBranchRef(repo, "target", newHead, oldHead); err != nil {
 		t.Fatalf("UpdateBranchRef: %v", err)
 	}
 
@@ -166,6 +185,14 @@ func TestUpdateBranchRef(t *testing.T) {
 	if targetHead != newHead {
 		t.Errorf("target HEAD = %q, want %q", targetHead, newHead)
 	}
+
+	// The branch is no longer at oldHead, so it is left alone.
+	if err := UpdateBranchRef(repo, "target", oldHead, oldHead); !errors.Is(err, ErrRefMoved) {
+		t.Errorf("UpdateBranchRef from a stale commit: err = %v, want ErrRefMoved", err)
+	}
+	if head, _ := HeadCommit(repo, "target"); head != newHead {
+		t.Errorf("target HEAD = %q, want it unchanged at %q", head, newHead)
+	}
 }
 
 func TestIsBranchMerged(t *testing.T) {
@@ -180,7 +207,7 @@ func TestIsBranchMerged(t *testing.T) {
 
 	// Advance feat past the default branch
 	commitFile(t, repo, "f.txt", "data", "advance on default")
-	UpdateBranchRef(repo, "feat", mustHeadCommit(t, repo, "HEAD"))
+	UpdateBranchRef(repo, "feat", mustHeadCommit(t, repo, "HEAD"), "")
 
 	// Now create a commit on default that diverges
 	// Actually let's just check: feat is ahead of the default branch baseline
@@ -195,7 +222,7 @@ func TestIsBranchMerged(t *testing.T) {
 	featHead, _ := HeadCommit(repo2, "HEAD")
 	// Go back to default
 	exec.Command("git", "-C", repo2, "checkout", defaultBranch2).Run()
-	UpdateBranchRef(repo2, "feat2", featHead)
+	UpdateBranchRef(repo2, "feat2", featHead, "")
 
 	// feat2 is ahead of default — it is NOT merged into default
 	// Actually IsBranchMerged checks if branch is ancestor of base
@@ -216,6 +243,102 @@ func TestIsBranchMerged(t *testing.T) {
 	}
 }
 
+func TestUnmergedCommits(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+	CreateBranch(repo, "feat", defaultBranch)
+	wtDir := filepath.Join(t.TempDir(), "feat-wt")
+	if out, err := exec.Command("git", "-C", repo, "worktree", "add", wtDir, "feat").CombinedOutput(); err != nil {
+		t.Fatalf("worktree add: %s (%v)", out, err)
+	}
+	commitFile(t, wtDir, "a.txt", "a", "first")
+	commitFile(t, wtDir, "b.txt", "b", "second")
+
+	// The worktree's HEAD doesn't count as another ref.
+	if n, err := UnmergedCommits(repo, "feat"); err != nil || n != 2 {
+		t.Errorf("UnmergedCommits = %d, %v, want 2", n, err)
+	}
+
+	// A commit another branch has is not lost.
+	CreateBranch(repo, "keep", "feat~1")
+	if n, err := UnmergedCommits(repo, "feat"); err != nil || n != 1 {
+		t.Errorf("UnmergedCommits = %d, %v after branching from it, want 1", n, err)
+	}
+
+	if _, err := UnmergedCommits(repo, "no-such-branch"); err == nil {
+		t.Error("expected error for missing branch")
+	}
+}
+
+func TestPushUpstream(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+	if err := PushUpstream(repo, defaultBranch); err == nil || !strings.Contains(err.Error(), "no upstream") {
+		t.Errorf("PushUpstream without upstream = %v, want no upstream error", err)
+	}
+
+	remote := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--bare", remote},
+		{"-C", repo, "remote", "add", "origin", remote},
+		{"-C", repo, "push", "--set-upstream", "origin", defaultBranch},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s (%v)", args, out, err)
+		}
+	}
+	commitFile(t, repo, "a.txt", "a", "merged")
+
+	if err := PushUpstream(repo, defaultBranch); err != nil {
+		t.Fatalf("PushUpstream: %v", err)
+	}
+	local, _ := HeadCommit(repo, defaultBranch)
+	pushed, _ := HeadCommit(remote, defaultBranch)
+	if local != pushed {
+		t.Errorf("remote %s = %s, want %s", defaultBranch, pushed, local)
+	}
+}
+
+func TestFastForwardFromOrigin(t *testing.T) {
+	origin := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(origin)
+	CreateBranch(origin, "develop", defaultBranch)
+	repo := filepath.Join(t.TempDir(), "clone")
+	if out, err := exec.Command("git", "clone", "--quiet", origin, repo).CombinedOutput(); err != nil {
+		t.Fatalf("clone: %s (%v)", out, err)
+	}
+	exec.Command("git", "-C", repo, "config", "user.email", "test@test.com").Run()
+	exec.Command("git", "-C", repo, "config", "user.name", "Test").Run()
+	exec.Command("git", "-C", repo, "branch", "develop", "origin/develop").Run()
+
+	if moved, err := FastForwardFromOrigin(repo, defaultBranch); err != nil || moved {
+		t.Fatalf("FastForwardFromOrigin up to date = %v, %v, want no move", moved, err)
+	}
+
+	// Both the checked-out branch and one that is not follow origin.
+	commitFile(t, origin, "a.txt", "a", "upstream work")
+	exec.Command("git", "-C", origin, "branch", "-f", "develop", defaultBranch).Run()
+	for _, branch := range []string{defaultBranch, "develop"} {
+		if moved, err := FastForwardFromOrigin(repo, branch); err != nil || !moved {
+			t.Fatalf("FastForwardFromOrigin(%s) = %v, %v, want moved", branch, moved, err)
+		}
+		local, _ := HeadCommit(repo, branch)
+		upstream, _ := HeadCommit(origin, defaultBranch)
+		if local != upstream {
+			t.Errorf("%s = %s, want origin's %s", branch, local, upstream)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo, "a.txt")); err != nil {
+		t.Error("the checked-out branch's worktree should be updated")
+	}
+
+	commitFile(t, origin, "b.txt", "b", "more upstream work")
+	commitFile(t, repo, "c.txt", "c", "local work")
+	if _, err := FastForwardFromOrigin(repo, defaultBranch); err == nil || !strings.Contains(err.Error(), "diverged") {
+		t.Errorf("FastForwardFromOrigin diverged = %v, want an error", err)
+	}
+}
+
 func TestMergeInWorktree_NoConflict(t *testing.T) {
 	repo := setupTestRepo(t)
 	defaultBranch, _ := CurrentBranch(repo)
@@ -231,7 +354,7 @@ func TestMergeInWorktree_NoConflict(t *testing.T) {
 	commitFile(t, wtDir, "feat.txt", "feature", "feat change")
 
 	// Merge default into feat (no conflicts since no changes on default)
-	conflicted, err := MergeInWorktree(wtDir, defaultBranch)
+	conflicted, err := MergeInWorktree(wtDir, defaultBranch, false)
 	if err != nil {
 		t.Fatalf("MergeInWorktree: %v", err)
 	}
@@ -256,7 +379,7 @@ func TestMergeInWorktree_WithConflict(t *testing.T) {
 
 	commitFile(t, wtDir, "shared.txt", "feat version", "feat change")
 
-	conflicted, err := MergeInWorktree(wtDir, defaultBranch)
+	conflicted, err := MergeInWorktree(wtDir, defaultBranch, false)
 	if err != nil {
 		t.Fatalf("MergeInWorktree: %v", err)
 	}
@@ -308,7 +431,7 @@ func TestConflictFiles(t *testing.T) {
 	commitFile(t, wtDir, "a.txt", "feat", "feat a")
 	commitFile(t, wtDir, "b.txt", "feat", "feat b")
 
-	conflicted, _ := MergeInWorktree(wtDir, defaultBranch)
+	conflicted, _ := MergeInWorktree(wtDir, defaultBranch, false)
 	if !conflicted {
 		t.Fatal("expected conflicts")
 	}
@@ -322,6 +445,153 @@ func TestConflictFiles(t *testing.T) {
 	}
 }
 
+func TestChangedFiles(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+	CreateBranch(repo, "feat", defaultBranch)
+
+	// A commit on the base branch after the fork is not the branch's change.
+	commitFile(t, repo, "base.txt", "base", "base change")
+
+	wtDir := filepath.Join(t.TempDir(), "feat-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "feat").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+	commitFile(t, wtDir, "feat.txt", "feat", "feat change")
+
+	files, err := ChangedFiles(repo, defaultBranch, "feat")
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	if len(files) != 1 || files[0] != "feat.txt" {
+		t.Errorf("ChangedFiles = %v, want [feat.txt]", files)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	repo := setupTestRepo(t)
+	commitFile(t, repo, "a.txt", "one\n", "add a")
+
+	patch := "--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-one\n+two\n"
+	if err := ApplyPatch(repo, []byte(patch)); err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(repo, "a.txt"))
+	if string(data) != "two\n" {
+		t.Errorf("a.txt = %q, want %q", data, "two\n")
+	}
+
+	if err := ApplyPatch(repo, []byte(patch)); err == nil {
+		t.Error("expected error re-applying patch")
+	}
+}
+
+func TestRebase_Stacked(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	CreateBranch(repo, "parent", defaultBranch)
+	CreateBranch(repo, "child", defaultBranch)
+	wtDir := filepath.Join(t.TempDir(), "child-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "child").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+
+	commitFile(t, repo, "base.txt", "base", "advance base")
+	commitFile(t, wtDir, "child.txt", "child", "child work")
+
+	conflicted, err := Rebase(wtDir, defaultBranch)
+	if err != nil || conflicted {
+		t.Fatalf("Rebase = %v, %v", conflicted, err)
+	}
+	if !IsBranchMerged(repo, defaultBranch, "child") {
+		t.Error("child should contain the new base after rebase")
+	}
+}
+
+func TestRebaseOnto_AfterSquash(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	CreateBranch(repo, "parent", defaultBranch)
+	parentWt := filepath.Join(t.TempDir(), "parent-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", parentWt, "parent").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", parentWt, "--force").Run()
+	commitFile(t, parentWt, "a.txt", "one", "parent wip")
+	commitFile(t, parentWt, "a.txt", "two", "parent done")
+	parentHead := mustHeadCommit(t, repo, "parent")
+
+	CreateBranch(repo, "child", "parent")
+	wtDir := filepath.Join(t.TempDir(), "child-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "child").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+	commitFile(t, wtDir, "child.txt", "child", "child work")
+
+	// The parent lands on base as a single commit.
+	commitFile(t, repo, "a.txt", "two", "squash parent")
+
+	conflicted, err := RebaseOnto(wtDir, defaultBranch, parentHead)
+	if err != nil || conflicted {
+		t.Fatalf("RebaseOnto = %v, %v", conflicted, err)
+	}
+	out, err := exec.Command("git", "-C", repo, "rev-list", "--count", defaultBranch+"..child").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.TrimSpace(string(out)); n != "1" {
+		t.Errorf("child has %s commits on top of base, want only its own", n)
+	}
+}
+
+func TestRebase_ConflictAborts(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	CreateBranch(repo, "child", defaultBranch)
+	wtDir := filepath.Join(t.TempDir(), "child-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "child").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+
+	commitFile(t, repo, "a.txt", "base", "base a")
+	commitFile(t, wtDir, "a.txt", "child", "child a")
+	before := mustHeadCommit(t, wtDir, "HEAD")
+
+	conflicted, err := Rebase(wtDir, defaultBranch)
+	if err != nil || !conflicted {
+		t.Fatalf("Rebase = %v, %v; want conflict", conflicted, err)
+	}
+	if after := mustHeadCommit(t, wtDir, "HEAD"); after != before {
+		t.Error("branch should be untouched after aborted rebase")
+	}
+}
+
+func TestSquashCommit(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+	base := mustHeadCommit(t, repo, "HEAD")
+
+	CreateBranch(repo, "feat", defaultBranch)
+	wtDir := filepath.Join(t.TempDir(), "feat-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "feat").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+	commitFile(t, wtDir, "a.txt", "a", "wip")
+	commitFile(t, wtDir, "b.txt", "b", "wip again")
+	head := mustHeadCommit(t, wtDir, "HEAD")
+
+	commit, err := SquashCommit(wtDir, base, "Add a and b")
+	if err != nil {
+		t.Fatalf("SquashCommit: %v", err)
+	}
+	out, _ := exec.Command("git", "-C", repo, "log", "--format=%P %s", "-1", commit).Output()
+	if got := strings.TrimSpace(string(out)); got != base+" Add a and b" {
+		t.Errorf("squash commit = %q, want parent %s and the message", got, base)
+	}
+	if out, _ := exec.Command("git", "-C", repo, "diff", "--stat", head, commit).Output(); len(out) != 0 {
+		t.Errorf("squash commit differs from the branch:\n%s", out)
+	}
+	if mustHeadCommit(t, wtDir, "HEAD") != head {
+		t.Error("branch should be untouched")
+	}
+}
+
 func mustHeadCommit(t *testing.T, repo, ref string) string {
 	t.Helper()
 	h, err := HeadCommit(repo, ref)
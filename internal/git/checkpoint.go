@@ -0,0 +1,135 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkpointPrefix namespaces checkpoint tags: checkpoint/<branch>/<time>.
+const checkpointPrefix = "checkpoint/"
+
+// Checkpoint is a tagged snapshot of a branch and its worktree. Commit
+// records the full working tree, uncommitted and untracked files included,
+// on top of the branch head at the time, which is its parent.
+type Checkpoint struct {
+	Tag     string
+	Commit  string
+	Label   string
+	Created time.Time
+}
+
+// CreateCheckpoint snapshots the worktree at wtPath, which has branch
+// checked out, and tags the snapshot. The worktree, its index and the
+// branch are left untouched. label is the tag message; empty uses a
+// default.
+func CreateCheckpoint(wtPath, branch, label string) (Checkpoint, error) {
+	if label == "" {
+		label = "checkpoint of " + branch
+	}
+	commit, err := snapshotWorktree(wtPath, label)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	now := time.Now()
+	base := checkpointPrefix + branch + "/" + now.Format("20060102-150405")
+	tag := base
+	for n := 2; run("-C", wtPath, "rev-parse", "--verify", "--quiet", "refs/tags/"+tag) == nil; n++ {
+		tag = fmt.Sprintf("%s-%d", base, n)
+	}
+	if err := run("-C", wtPath, "tag", "-a", "-m", label, tag, commit); err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to tag checkpoint: %w", err)
+	}
+	return Checkpoint{Tag: tag, Commit: commit, Label: label, Created: now}, nil
+}
+
+// snapshotWorktree commits the worktree's full state with HEAD as parent,
+// staging through a temporary index so the real one is not disturbed.
+// Files ignored by git are left out.
+func snapshotWorktree(wtPath, message string) (string, error) {
+	tmp, err := os.MkdirTemp("", "mastermind-checkpoint-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index")}
+
+	if _, err := outputEnv(env, "-C", wtPath, "read-tree", "HEAD"); err != nil {
+		return "", fmt.Errorf("failed to snapshot worktree: %w", err)
+	}
+	if _, err := outputEnv(env, "-C", wtPath, "add", "--all"); err != nil {
+		return "", fmt.Errorf("failed to snapshot worktree: %w", err)
+	}
+	tree, err := outputEnv(env, "-C", wtPath, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot worktree: %w", err)
+	}
+	commit, err := output("-C", wtPath, "commit-tree", strings.TrimSpace(string(tree)), "-p", "HEAD", "-m", message)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	return strings.TrimSpace(string(commit)), nil
+}
+
+// ListCheckpoints returns the checkpoints of branch, newest first.
+func ListCheckpoints(repoPath, branch string) ([]Checkpoint, error) {
+	prefix := "refs/tags/" + checkpointPrefix + branch + "/"
+	out, err := output("-C", repoPath, "for-each-ref", "--sort=-creatordate",
+		"--format=%(refname)%00%(*objectname)%00%(creatordate:unix)%00%(contents:subject)", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	var cps []Checkpoint
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		// Tags of a nested branch (checkpoint/feat/x/... for feat) share
+		// the prefix.
+		if strings.Contains(strings.TrimPrefix(fields[0], prefix), "/") {
+			continue
+		}
+		unix, _ := strconv.ParseInt(fields[2], 10, 64)
+		cps = append(cps, Checkpoint{
+			Tag:     strings.TrimPrefix(fields[0], "refs/tags/"),
+			Commit:  fields[1],
+			Label:   fields[3],
+			Created: time.Unix(unix, 0),
+		})
+	}
+	return cps, nil
+}
+
+// RestoreCheckpoint rolls the worktree at wtPath back to the checkpoint
+// tag: the checked-out branch is reset to the commit it was taken on and
+// the files that were uncommitted then are put back as uncommitted
+// changes. Current commits past that point, uncommitted changes and
+// untracked files are discarded, so callers checkpoint first to keep them.
+func RestoreCheckpoint(wtPath, tag string) error {
+	snap, err := output("-C", wtPath, "rev-parse", "--verify", tag+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("checkpoint %s not found: %w", tag, err)
+	}
+	commit := strings.TrimSpace(string(snap))
+
+	if err := run("-C", wtPath, "reset", "--hard", commit+"^"); err != nil {
+		return fmt.Errorf("failed to reset to checkpoint: %w", err)
+	}
+	if err := run("-C", wtPath, "clean", "-fd"); err != nil {
+		return fmt.Errorf("failed to remove untracked files: %w", err)
+	}
+	// Check out the snapshot's files, then unstage them so they are
+	// uncommitted changes on top of the branch again.
+	if err := run("-C", wtPath, "read-tree", "-u", "--reset", commit); err != nil {
+		return fmt.Errorf("failed to restore checkpoint files: %w", err)
+	}
+	if err := run("-C", wtPath, "reset", "-q"); err != nil {
+		return fmt.Errorf("failed to restore checkpoint files: %w", err)
+	}
+	return nil
+}
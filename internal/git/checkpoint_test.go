@@ -0,0 +1,123 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateCheckpoint(t *testing.T) {
+	repo := setupTestRepo(t)
+	commitFile(t, repo, "a.txt", "one\n", "add a")
+	os.WriteFile(filepath.Join(repo, "a.txt"), []byte("two\n"), 0o644)
+	os.WriteFile(filepath.Join(repo, "new.txt"), []byte("untracked\n"), 0o644)
+	branch, _ := CurrentBranch(repo)
+	head, _ := HeadCommit(repo, "HEAD")
+	statusBefore, _ := output("-C", repo, "status", "--porcelain")
+
+	cp, err := CreateCheckpoint(repo, branch, "")
+	if err != nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+	if !strings.HasPrefix(cp.Tag, "checkpoint/"+branch+"/") {
+		t.Errorf("tag = %q", cp.Tag)
+	}
+
+	for file, want := range map[string]string{"a.txt": "two\n", "new.txt": "untracked\n"} {
+		got, err := output("-C", repo, "show", cp.Tag+":"+file)
+		if err != nil || string(got) != want {
+			t.Errorf("%s in checkpoint = %q, %v; want %q", file, got, err, want)
+		}
+	}
+	if parent, _ := HeadCommit(repo, cp.Tag+"^"); parent != head {
+		t.Errorf("checkpoint parent = %s, want branch head %s", parent, head)
+	}
+	if now, _ := HeadCommit(repo, "HEAD"); now != head {
+		t.Error("branch moved")
+	}
+	if statusAfter, _ := output("-C", repo, "status", "--porcelain"); string(statusAfter) != string(statusBefore) {
+		t.Errorf("worktree status changed:\n%s\nwant:\n%s", statusAfter, statusBefore)
+	}
+
+	again, err := CreateCheckpoint(repo, branch, "second")
+	if err != nil || again.Tag == cp.Tag {
+		t.Errorf("second checkpoint = %+v, %v; want a distinct tag", again, err)
+	}
+}
+
+func TestRestoreCheckpoint(t *testing.T) {
+	repo := setupTestRepo(t)
+	commitFile(t, repo, "a.txt", "one\n", "add a")
+	commitFile(t, repo, "gone.txt", "kept\n", "add gone")
+	os.WriteFile(filepath.Join(repo, "a.txt"), []byte("two\n"), 0o644)
+	os.Remove(filepath.Join(repo, "gone.txt"))
+	os.WriteFile(filepath.Join(repo, "new.txt"), []byte("untracked\n"), 0o644)
+	branch, _ := CurrentBranch(repo)
+	head, _ := HeadCommit(repo, "HEAD")
+	statusBefore, _ := output("-C", repo, "status", "--porcelain")
+
+	cp, err := CreateCheckpoint(repo, branch, "known good")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The agent goes off the rails.
+	run("-C", repo, "add", "--all")
+	run("-C", repo, "commit", "-q", "-m", "bad")
+	commitFile(t, repo, "b.txt", "bad\n", "worse")
+	os.WriteFile(filepath.Join(repo, "a.txt"), []byte("bad\n"), 0o644)
+	os.WriteFile(filepath.Join(repo, "junk.txt"), []byte("junk\n"), 0o644)
+
+	if err := RestoreCheckpoint(repo, cp.Tag); err != nil {
+		t.Fatalf("RestoreCheckpoint: %v", err)
+	}
+	if now, _ := HeadCommit(repo, "HEAD"); now != head {
+		t.Errorf("HEAD = %s, want %s", now, head)
+	}
+	if got, _ := os.ReadFile(filepath.Join(repo, "a.txt")); string(got) != "two\n" {
+		t.Errorf("a.txt = %q, want the uncommitted content of the checkpoint", got)
+	}
+	for _, file := range []string{"b.txt", "junk.txt", "gone.txt"} {
+		if _, err := os.Stat(filepath.Join(repo, file)); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist after rollback", file)
+		}
+	}
+	if statusAfter, _ := output("-C", repo, "status", "--porcelain"); string(statusAfter) != string(statusBefore) {
+		t.Errorf("status after rollback:\n%s\nwant:\n%s", statusAfter, statusBefore)
+	}
+
+	if err := RestoreCheckpoint(repo, "checkpoint/nope/1"); err == nil {
+		t.Error("expected an error for a missing checkpoint")
+	}
+}
+
+func TestListCheckpoints(t *testing.T) {
+	repo := setupTestRepo(t)
+	// feat/x may have existed before feat; its checkpoints outlive it.
+	first, _ := CreateCheckpoint(repo, "feat", "first")
+	second, _ := CreateCheckpoint(repo, "feat", "second")
+	CreateCheckpoint(repo, "feat/x", "nested")
+
+	cps, err := ListCheckpoints(repo, "feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cps) != 2 {
+		t.Fatalf("ListCheckpoints = %+v, want the two checkpoints of feat", cps)
+	}
+	tags := map[string]string{}
+	for _, cp := range cps {
+		tags[cp.Tag] = cp.Label
+		if cp.Commit == "" || cp.Created.IsZero() {
+			t.Errorf("incomplete checkpoint %+v", cp)
+		}
+	}
+	if tags[first.Tag] != "first" || tags[second.Tag] != "second" {
+		t.Errorf("checkpoints = %+v", cps)
+	}
+
+	if cps, err := ListCheckpoints(repo, "other"); err != nil || len(cps) != 0 {
+		t.Errorf("no checkpoints: %+v, %v", cps, err)
+	}
+}
@@ -0,0 +1,55 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Error is returned when a git command exits unsuccessfully. It carries
+// git's stderr so callers see why the command failed rather than just
+// "exit status 128".
+type Error struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Stderr == "" {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + ": " + e.Stderr
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// output runs git with args and returns its stdout. On failure the error is
+// an *Error holding the trimmed stderr.
+func output(args ...string) ([]byte, error) {
+	return outputEnv(nil, args...)
+}
+
+// outputEnv is output with env added to git's environment.
+func outputEnv(env []string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, &Error{Args: args, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return out, nil
+}
+
+// run runs git with args, discarding stdout.
+func run(args ...string) error {
+	_, err := output(args...)
+	return err
+}
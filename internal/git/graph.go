@@ -0,0 +1,47 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// AheadBehind returns how many commits branch has that base doesn't
+// (ahead) and how many base has that branch doesn't (behind).
+func AheadBehind(repoPath, branch, base string) (ahead, behind int, err error) {
+	out, err := output("-C", repoPath, "rev-list", "--left-right", "--count", base+"..."+branch)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count commits between %s and %s: %w", base, branch, err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", strings.TrimSpace(string(out)))
+	}
+	behind, _ = strconv.Atoi(fields[0])
+	ahead, _ = strconv.Atoi(fields[1])
+	return ahead, behind, nil
+}
+
+// BranchGraph renders a compact commit graph of refs with `git log
+// --graph`, keeping only commits that are branch tips or merge points so
+// the topology of many branches fits on screen. At most limit lines are
+// returned.
+func BranchGraph(repoPath string, refs []string, limit int) ([]string, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	args := []string{"-C", repoPath, "log", "--graph", "--oneline", "--decorate=short",
+		"--simplify-by-decoration", "--color=never", "--max-count", strconv.Itoa(limit)}
+	args = append(args, refs...)
+	args = append(args, "--")
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to render branch graph: %s (%w)", strings.TrimSpace(string(out)), err)
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) > limit {
+		lines = lines[:limit]
+	}
+	return lines, nil
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAheadBehind(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	CreateBranch(repo, "feat", defaultBranch)
+	wtDir := filepath.Join(t.TempDir(), "feat-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "feat").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+
+	commitFile(t, wtDir, "a.txt", "a", "feat a")
+	commitFile(t, wtDir, "b.txt", "b", "feat b")
+	commitFile(t, repo, "c.txt", "c", "base c")
+
+	ahead, behind, err := AheadBehind(repo, "feat", defaultBranch)
+	if err != nil {
+		t.Fatalf("AheadBehind: %v", err)
+	}
+	if ahead != 2 || behind != 1 {
+		t.Errorf("ahead/behind = %d/%d, want 2/1", ahead, behind)
+	}
+}
+
+func TestBranchGraph(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	CreateBranch(repo, "feat", defaultBranch)
+	wtDir := filepath.Join(t.TempDir(), "feat-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "feat").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+
+	commitFile(t, wtDir, "a.txt", "a", "feat work")
+	commitFile(t, repo, "b.txt", "b", "base work")
+
+	lines, err := BranchGraph(repo, []string{defaultBranch, "feat"}, 20)
+	if err != nil {
+		t.Fatalf("BranchGraph: %v", err)
+	}
+	graph := strings.Join(lines, "\n")
+	for _, want := range []string{"feat work", "base work", "*"} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("graph missing %q:\n%s", want, graph)
+		}
+	}
+}
@@ -6,21 +6,59 @@ type GitOps interface {
 	DeleteBranch(repoPath, branchName string) error
 	IsBranchCheckedOut(repoPath, branch string) (bool, error)
 	IsBranchMerged(repoPath, branch, baseBranch string) bool
-	CreateWorktree(repoPath, worktreeDir, branch string) (string, error)
+	UnmergedCommits(repoPath, branch string) (int, error)
+	AheadBehind(repoPath, branch, base string) (ahead, behind int, err error)
+	ArchiveBranch(repoPath, branch string) (string, error)
+	ListArchived(repoPath string) ([]ArchivedBranch, error)
+	RestoreArchived(repoPath, ref string) (string, error)
+	DeleteArchived(repoPath, ref string) error
+	CreateWorktree(repoPath, wtPath, branch string) (string, error)
 	RemoveWorktree(repoPath, wtPath string) error
+	MoveWorktree(repoPath, from, to string) error
 	HasChanges(wtPath string) bool
+	DiscardChanges(wtPath string) error
+	StashChanges(wtPath, message string, paths ...string) (string, error)
+	ChangeSnapshot(wtPath string) (map[string]string, error)
 	HeadCommit(repoOrWtPath, ref string) (string, error)
-	UpdateBranchRef(repoPath, branch, targetCommit string) error
-	MergeInWorktree(wtPath, mergeBranch string) (bool, error)
+	UpdateBranchRef(repoPath, branch, targetCommit, oldCommit string) error
+	MergeInWorktree(wtPath, mergeBranch string, rerere bool) (bool, error)
 	MergeAbort(wtPath string) error
 	MergeFFOnly(wtPath, branch string) error
 	CheckoutBranch(wtPath, branch string) error
 	CurrentBranch(repoPath string) (string, error)
+	FastForwardFromOrigin(repoPath, branch string) (bool, error)
+	FetchBranch(repoPath, branch string) error
 	BranchExists(repoPath, branchName string) bool
 	ConflictFiles(wtPath string) ([]string, error)
+	ChangedFiles(repoPath, base, branch string) ([]string, error)
+	Commits(repoPath, base, branch string, limit int) ([]string, int, error)
+	WorktreeDiffStat(wtPath, base string) (DiffStat, error)
+	WorktreeDiff(wtPath, base string) ([]byte, error)
+	PreviewMerge(repoPath, base, branch string) (MergePreview, error)
+	IsAncestor(repoPath, ancestor, descendant string) bool
+	MergeDriverProblems(wtPath string, files []string) []string
+	ConflictDetails(wtPath string) ([]Conflict, error)
+	ResolveConflict(wtPath, path string, side ConflictSide) error
+	CommitMerge(wtPath string) error
 	WorktreeForBranch(repoPath, branch string) string
 	ListBranches(repoPath string) ([]Branch, error)
 	CopyUncommittedChanges(srcWT, dstWT string) error
+	BackupChanges(wtPath, dir string) (bool, error)
+	ApplyPatch(wtPath string, patch []byte) error
+	Rebase(wtPath, onto string) (bool, error)
+	RebaseOnto(wtPath, onto, upstream string) (bool, error)
+	SquashCommit(wtPath, parent, message string) (string, error)
+	PushUpstream(repoPath, branch string) error
+	ListWorktrees(repoPath string) ([]Worktree, error)
+	PruneWorktrees(repoPath string) ([]string, error)
+	GCAuto(repoPath string) error
+	IsWorktreeBroken(wtPath string) bool
+	RepairWorktrees(repoPath string, wtPaths []string) error
+	CreateCheckpoint(wtPath, branch, label string) (Checkpoint, error)
+	ListCheckpoints(repoPath, branch string) ([]Checkpoint, error)
+	RestoreCheckpoint(wtPath, tag string) error
+	InstallPushGuard(repoPath string) error
+	SetPushBlocked(wtPath string, blocked bool) error
 }
 
 // RealGit delegates to the package-level functions.
@@ -42,28 +80,68 @@ func (RealGit) IsBranchMerged(repoPath, branch, baseBranch string) bool {
 	return IsBranchMerged(repoPath, branch, baseBranch)
 }
 
-func (RealGit) CreateWorktree(repoPath, worktreeDir, branch string) (string, error) {
-	return CreateWorktree(repoPath, worktreeDir, branch)
+func (RealGit) UnmergedCommits(repoPath, branch string) (int, error) {
+	return UnmergedCommits(repoPath, branch)
+}
+
+func (RealGit) AheadBehind(repoPath, branch, base string) (int, int, error) {
+	return AheadBehind(repoPath, branch, base)
+}
+
+func (RealGit) ArchiveBranch(repoPath, branch string) (string, error) {
+	return ArchiveBranch(repoPath, branch)
+}
+
+func (RealGit) ListArchived(repoPath string) ([]ArchivedBranch, error) {
+	return ListArchived(repoPath)
+}
+
+func (RealGit) RestoreArchived(repoPath, ref string) (string, error) {
+	return RestoreArchived(repoPath, ref)
+}
+
+func (RealGit) DeleteArchived(repoPath, ref string) error {
+	return DeleteArchived(repoPath, ref)
+}
+
+func (RealGit) CreateWorktree(repoPath, wtPath, branch string) (string, error) {
+	return CreateWorktree(repoPath, wtPath, branch)
 }
 
 func (RealGit) RemoveWorktree(repoPath, wtPath string) error {
 	return RemoveWorktree(repoPath, wtPath)
 }
 
+func (RealGit) MoveWorktree(repoPath, from, to string) error {
+	return MoveWorktree(repoPath, from, to)
+}
+
 func (RealGit) HasChanges(wtPath string) bool {
 	return HasChanges(wtPath)
 }
 
+func (RealGit) DiscardChanges(wtPath string) error {
+	return DiscardChanges(wtPath)
+}
+
+func (RealGit) StashChanges(wtPath, message string, paths ...string) (string, error) {
+	return StashChanges(wtPath, message, paths...)
+}
+
+func (RealGit) ChangeSnapshot(wtPath string) (map[string]string, error) {
+	return ChangeSnapshot(wtPath)
+}
+
 func (RealGit) HeadCommit(repoOrWtPath, ref string) (string, error) {
 	return HeadCommit(repoOrWtPath, ref)
 }
 
-func (RealGit) UpdateBranchRef(repoPath, branch, targetCommit string) error {
-	return UpdateBranchRef(repoPath, branch, targetCommit)
+func (RealGit) UpdateBranchRef(repoPath, branch, targetCommit, oldCommit string) error {
+	return UpdateBranchRef(repoPath, branch, targetCommit, oldCommit)
 }
 
-func (RealGit) MergeInWorktree(wtPath, mergeBranch string) (bool, error) {
-	return MergeInWorktree(wtPath, mergeBranch)
+func (RealGit) MergeInWorktree(wtPath, mergeBranch string, rerere bool) (bool, error) {
+	return MergeInWorktree(wtPath, mergeBranch, rerere)
 }
 
 func (RealGit) MergeAbort(wtPath string) error {
@@ -78,6 +156,14 @@ func (RealGit) CheckoutBranch(wtPath, branch string) error {
 	return CheckoutBranch(wtPath, branch)
 }
 
+func (RealGit) FastForwardFromOrigin(repoPath, branch string) (bool, error) {
+	return FastForwardFromOrigin(repoPath, branch)
+}
+
+func (RealGit) FetchBranch(repoPath, branch string) error {
+	return FetchBranch(repoPath, branch)
+}
+
 func (RealGit) CurrentBranch(repoPath string) (string, error) {
 	return CurrentBranch(repoPath)
 }
@@ -90,6 +176,46 @@ func (RealGit) ConflictFiles(wtPath string) ([]string, error) {
 	return ConflictFiles(wtPath)
 }
 
+func (RealGit) ChangedFiles(repoPath, base, branch string) ([]string, error) {
+	return ChangedFiles(repoPath, base, branch)
+}
+
+func (RealGit) Commits(repoPath, base, branch string, limit int) ([]string, int, error) {
+	return Commits(repoPath, base, branch, limit)
+}
+
+func (RealGit) WorktreeDiffStat(wtPath, base string) (DiffStat, error) {
+	return WorktreeDiffStat(wtPath, base)
+}
+
+func (RealGit) WorktreeDiff(wtPath, base string) ([]byte, error) {
+	return WorktreeDiff(wtPath, base)
+}
+
+func (RealGit) PreviewMerge(repoPath, base, branch string) (MergePreview, error) {
+	return PreviewMerge(repoPath, base, branch)
+}
+
+func (RealGit) IsAncestor(repoPath, ancestor, descendant string) bool {
+	return IsAncestor(repoPath, ancestor, descendant)
+}
+
+func (RealGit) MergeDriverProblems(wtPath string, files []string) []string {
+	return MergeDriverProblems(wtPath, files)
+}
+
+func (RealGit) ConflictDetails(wtPath string) ([]Conflict, error) {
+	return ConflictDetails(wtPath)
+}
+
+func (RealGit) ResolveConflict(wtPath, path string, side ConflictSide) error {
+	return ResolveConflict(wtPath, path, side)
+}
+
+func (RealGit) CommitMerge(wtPath string) error {
+	return CommitMerge(wtPath)
+}
+
 func (RealGit) WorktreeForBranch(repoPath, branch string) string {
 	return WorktreeForBranch(repoPath, branch)
 }
@@ -101,3 +227,67 @@ func (RealGit) ListBranches(repoPath string) ([]Branch, error) {
 func (RealGit) CopyUncommittedChanges(srcWT, dstWT string) error {
 	return CopyUncommittedChanges(srcWT, dstWT)
 }
+
+func (RealGit) BackupChanges(wtPath, dir string) (bool, error) {
+	return BackupChanges(wtPath, dir)
+}
+
+func (RealGit) ApplyPatch(wtPath string, patch []byte) error {
+	return ApplyPatch(wtPath, patch)
+}
+
+func (RealGit) Rebase(wtPath, onto string) (bool, error) {
+	return Rebase(wtPath, onto)
+}
+
+func (RealGit) RebaseOnto(wtPath, onto, upstream string) (bool, error) {
+	return RebaseOnto(wtPath, onto, upstream)
+}
+
+func (RealGit) SquashCommit(wtPath, parent, message string) (string, error) {
+	return SquashCommit(wtPath, parent, message)
+}
+
+func (RealGit) PushUpstream(repoPath, branch string) error {
+	return PushUpstream(repoPath, branch)
+}
+
+func (RealGit) ListWorktrees(repoPath string) ([]Worktree, error) {
+	return ListWorktrees(repoPath)
+}
+
+func (RealGit) PruneWorktrees(repoPath string) ([]string, error) {
+	return PruneWorktrees(repoPath)
+}
+
+func (RealGit) GCAuto(repoPath string) error {
+	return GCAuto(repoPath)
+}
+
+func (RealGit) IsWorktreeBroken(wtPath string) bool {
+	return IsWorktreeBroken(wtPath)
+}
+
+func (RealGit) RepairWorktrees(repoPath string, wtPaths []string) error {
+	return RepairWorktrees(repoPath, wtPaths)
+}
+
+func (RealGit) CreateCheckpoint(wtPath, branch, label string) (Checkpoint, error) {
+	return CreateCheckpoint(wtPath, branch, label)
+}
+
+func (RealGit) ListCheckpoints(repoPath, branch string) ([]Checkpoint, error) {
+	return ListCheckpoints(repoPath, branch)
+}
+
+func (RealGit) RestoreCheckpoint(wtPath, tag string) error {
+	return RestoreCheckpoint(wtPath, tag)
+}
+
+func (RealGit) InstallPushGuard(repoPath string) error {
+	return InstallPushGuard(repoPath)
+}
+
+func (RealGit) SetPushBlocked(wtPath string, blocked bool) error {
+	return SetPushBlocked(wtPath, blocked)
+}
@@ -0,0 +1,174 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Commits returns "<hash> <subject>" for the commits on branch that base
+// doesn't have, newest first and at most limit of them, along with how
+// many there are in all.
+func Commits(repoPath, base, branch string, limit int) ([]string, int, error) {
+	rng := base + ".." + branch
+	out, err := output("-C", repoPath, "rev-list", "--count", rng)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count commits in %s: %w", rng, err)
+	}
+	total, _ := strconv.Atoi(strings.TrimSpace(string(out)))
+	if total == 0 || limit <= 0 {
+		return nil, total, nil
+	}
+	out, err = output("-C", repoPath, "log", "--format=%h %s", "--max-count", strconv.Itoa(limit), rng, "--")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list commits in %s: %w", rng, err)
+	}
+	return strings.Split(strings.TrimRight(string(out), "\n"), "\n"), total, nil
+}
+
+// DiffStat summarises a diff.
+type DiffStat struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// String formats the stat like "3 files changed, +10 -2".
+func (s DiffStat) String() string {
+	files := "files"
+	if s.Files == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%d %s changed, +%d -%d", s.Files, files, s.Insertions, s.Deletions)
+}
+
+var shortStatRe = regexp.MustCompile(`(\d+) (file|insertion|deletion)`)
+
+// parseShortStat parses the output of `git diff --shortstat`, e.g.
+// " 3 files changed, 10 insertions(+), 2 deletions(-)".
+func parseShortStat(out string) DiffStat {
+	var s DiffStat
+	for _, m := range shortStatRe.FindAllStringSubmatch(out, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "file":
+			s.Files = n
+		case "insertion":
+			s.Insertions = n
+		case "deletion":
+			s.Deletions = n
+		}
+	}
+	return s
+}
+
+// WorktreeDiffStat returns the diff stat of the worktree, including
+// uncommitted changes to tracked files, against the commit its branch
+// forked from base at.
+func WorktreeDiffStat(wtPath, base string) (DiffStat, error) {
+	out, err := output("-C", wtPath, "merge-base", base, "HEAD")
+	if err != nil {
+		return DiffStat{}, fmt.Errorf("failed to find merge base with %s: %w", base, err)
+	}
+	forkPoint := strings.TrimSpace(string(out))
+	out, err = output("-C", wtPath, "diff", "--shortstat", forkPoint, "--")
+	if err != nil {
+		return DiffStat{}, fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	return parseShortStat(string(out)), nil
+}
+
+// WorktreeDiff returns the diff of the worktree at wtPath against the
+// commit it forked from base at: its commits and uncommitted changes,
+// with untracked (non-ignored) files as new files. Nothing is checked out
+// or staged.
+func WorktreeDiff(wtPath, base string) ([]byte, error) {
+	out, err := output("-C", wtPath, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base with %s: %w", base, err)
+	}
+	forkPoint := strings.TrimSpace(string(out))
+	diff, err := output("-C", wtPath, "diff", forkPoint, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	out, err = output("-C", wtPath, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	for _, path := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if path == "" {
+			continue
+		}
+		// --no-index exits 1 when the files differ, which they always do.
+		fileDiff, err := output("-C", wtPath, "diff", "--no-index", "--", os.DevNull, path)
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return nil, fmt.Errorf("failed to diff untracked %s: %w", path, err)
+		}
+		diff = append(diff, fileDiff...)
+	}
+	return diff, nil
+}
+
+// FileStat is the diff stat of one file. Binary files have no line counts.
+type FileStat struct {
+	Path       string
+	Insertions int
+	Deletions  int
+	Binary     bool
+}
+
+// MergePreview is what merging a branch into its base would bring in.
+type MergePreview struct {
+	Stat  DiffStat
+	Files []FileStat
+	// FastForward is set when base is an ancestor of the branch, so base
+	// can move to the branch without a merge commit.
+	FastForward bool
+}
+
+// PreviewMerge returns the changes branch would land on base, i.e. the
+// diff since they forked (`git diff base...branch`), and whether the merge
+// is a fast-forward.
+func PreviewMerge(repoPath, base, branch string) (MergePreview, error) {
+	out, err := output("-C", repoPath, "diff", "--numstat", base+"..."+branch, "--")
+	if err != nil {
+		return MergePreview{}, fmt.Errorf("failed to diff %s...%s: %w", base, branch, err)
+	}
+	p := MergePreview{
+		Files:       parseNumstat(string(out)),
+		FastForward: IsAncestor(repoPath, base, branch),
+	}
+	p.Stat.Files = len(p.Files)
+	for _, f := range p.Files {
+		p.Stat.Insertions += f.Insertions
+		p.Stat.Deletions += f.Deletions
+	}
+	return p, nil
+}
+
+// parseNumstat parses the output of `git diff --numstat`, whose lines are
+// "<insertions>\t<deletions>\t<path>", with "-" counts for binary files.
+func parseNumstat(out string) []FileStat {
+	var files []FileStat
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		f := FileStat{Path: fields[2]}
+		if fields[0] == "-" {
+			f.Binary = true
+		} else {
+			f.Insertions, _ = strconv.Atoi(fields[0])
+			f.Deletions, _ = strconv.Atoi(fields[1])
+		}
+		files = append(files, f)
+	}
+	return files
+}
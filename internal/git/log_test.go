@@ -0,0 +1,133 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommitsAndWorktreeDiffStat(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	CreateBranch(repo, "feat", defaultBranch)
+	wtDir := filepath.Join(t.TempDir(), "feat-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "feat").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+
+	commitFile(t, wtDir, "a.txt", "a\n", "feat a")
+	commitFile(t, wtDir, "b.txt", "b\n", "feat b")
+	commitFile(t, repo, "c.txt", "c\n", "base c")
+	// Uncommitted changes count too.
+	if err := os.WriteFile(filepath.Join(wtDir, "a.txt"), []byte("a\nmore\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	commits, total, err := Commits(repo, defaultBranch, "feat", 1)
+	if err != nil {
+		t.Fatalf("Commits: %v", err)
+	}
+	if total != 2 || len(commits) != 1 || !strings.HasSuffix(commits[0], " feat b") {
+		t.Errorf("Commits = %q, %d; want the newest of 2", commits, total)
+	}
+
+	stat, err := WorktreeDiffStat(wtDir, defaultBranch)
+	if err != nil {
+		t.Fatalf("WorktreeDiffStat: %v", err)
+	}
+	if want := (DiffStat{Files: 2, Insertions: 3}); stat != want {
+		t.Errorf("WorktreeDiffStat = %+v, want %+v", stat, want)
+	}
+	if got := stat.String(); got != "2 files changed, +3 -0" {
+		t.Errorf("String = %q", got)
+	}
+}
+
+func TestWorktreeDiff(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	CreateBranch(repo, "feat", defaultBranch)
+	wtDir := filepath.Join(t.TempDir(), "feat-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "feat").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+
+	commitFile(t, wtDir, "a.txt", "a\n", "feat a")
+	commitFile(t, repo, "c.txt", "c\n", "base c")
+	os.WriteFile(filepath.Join(wtDir, "a.txt"), []byte("a\nmore\n"), 0o644)
+	os.WriteFile(filepath.Join(wtDir, "new.txt"), []byte("new\n"), 0o644)
+	head, _ := HeadCommit(repo, defaultBranch)
+
+	diff, err := WorktreeDiff(wtDir, defaultBranch)
+	if err != nil {
+		t.Fatalf("WorktreeDiff: %v", err)
+	}
+	for _, want := range []string{"+++ b/a.txt", "+more", "+++ b/new.txt", "+new"} {
+		if !strings.Contains(string(diff), want) {
+			t.Errorf("diff should contain %q:\n%s", want, diff)
+		}
+	}
+	// Base's own changes since the fork are not the agent's.
+	if strings.Contains(string(diff), "c.txt") {
+		t.Errorf("diff should not contain base's changes:\n%s", diff)
+	}
+	if out, _ := exec.Command("git", "-C", wtDir, "status", "--porcelain").Output(); !strings.Contains(string(out), "?? new.txt") {
+		t.Errorf("status = %q, want the untracked file left unstaged", out)
+	}
+	if now, _ := HeadCommit(repo, defaultBranch); now != head {
+		t.Error("base should not move")
+	}
+}
+
+func TestParseShortStat(t *testing.T) {
+	got := parseShortStat(" 1 file changed, 1 deletion(-)\n")
+	if want := (DiffStat{Files: 1, Deletions: 1}); got != want {
+		t.Errorf("parseShortStat = %+v, want %+v", got, want)
+	}
+}
+
+func TestPreviewMerge(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	CreateBranch(repo, "feat", defaultBranch)
+	wtDir := filepath.Join(t.TempDir(), "feat-wt")
+	exec.Command("git", "-C", repo, "worktree", "add", wtDir, "feat").Run()
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wtDir, "--force").Run()
+
+	commitFile(t, wtDir, "a.txt", "a\nb\n", "feat a")
+
+	p, err := PreviewMerge(repo, defaultBranch, "feat")
+	if err != nil {
+		t.Fatalf("PreviewMerge: %v", err)
+	}
+	if !p.FastForward {
+		t.Error("want a fast-forward while base hasn't moved")
+	}
+	if len(p.Files) != 1 || p.Files[0] != (FileStat{Path: "a.txt", Insertions: 2}) {
+		t.Errorf("Files = %+v", p.Files)
+	}
+	if want := (DiffStat{Files: 1, Insertions: 2}); p.Stat != want {
+		t.Errorf("Stat = %+v, want %+v", p.Stat, want)
+	}
+
+	// Changes on base are not part of what the branch lands.
+	commitFile(t, repo, "c.txt", "c\n", "base c")
+	p, err = PreviewMerge(repo, defaultBranch, "feat")
+	if err != nil {
+		t.Fatalf("PreviewMerge: %v", err)
+	}
+	if p.FastForward || len(p.Files) != 1 {
+		t.Errorf("PreviewMerge = %+v, want a merge commit bringing in a.txt only", p)
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	got := parseNumstat("3\t1\tmain.go\n-\t-\tlogo.png\n")
+	want := []FileStat{{Path: "main.go", Insertions: 3, Deletions: 1}, {Path: "logo.png", Binary: true}}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("parseNumstat = %+v, want %+v", got, want)
+	}
+}
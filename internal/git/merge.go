@@ -0,0 +1,190 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// rerereArgs are the options MergeInWorktree passes to git merge to use
+// rerere: a conflict resolution recorded in one worktree is replayed, and
+// staged, when the same conflict comes up in another, since all worktrees
+// share the resolutions. They are passed per command so the repository's
+// config is left alone; a rerere setting the user has made wins.
+func rerereArgs(wtPath string) []string {
+	var args []string
+	for _, key := range []string{"rerere.enabled", "rerere.autoUpdate"} {
+		// git config --get exits 1 when the key is unset.
+		if _, err := output("-C", wtPath, "config", "--get", key); err == nil {
+			continue
+		}
+		args = append(args, "-c", key+"=true")
+	}
+	return args
+}
+
+// builtinMergeDrivers are the values of the merge attribute git handles
+// itself.
+var builtinMergeDrivers = map[string]bool{"text": true, "binary": true, "union": true}
+
+// MergeDriverProblems explains conflicts in files that .gitattributes
+// hands to a custom merge driver: the driver may be missing from the git
+// config, in which case git quietly merged the file as text, or it failed
+// or left the conflict to the user.
+func MergeDriverProblems(wtPath string, files []string) []string {
+	if len(files) == 0 {
+		return nil
+	}
+	out, err := output(append([]string{"-C", wtPath, "check-attr", "merge", "--"}, files...)...)
+	if err != nil {
+		return nil
+	}
+	var problems []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		// "<path>: merge: <value>"
+		path, value, ok := strings.Cut(line, ": merge: ")
+		if !ok || value == "unspecified" || value == "set" || value == "unset" || builtinMergeDrivers[value] {
+			continue
+		}
+		cmd, err := output("-C", wtPath, "config", "--get", "merge."+value+".driver")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: merge driver %q from .gitattributes is not configured (merge.%s.driver), so it was merged as text", path, value, value))
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s: merge driver %q (%s) failed or left a conflict; see mastermind.log for its output", path, value, strings.TrimSpace(string(cmd))))
+	}
+	return problems
+}
+
+// Conflict describes a file left conflicted by a merge.
+type Conflict struct {
+	Path   string
+	Binary bool
+	// Size is the size in bytes of the larger of the two sides.
+	Size int64
+}
+
+// ConflictSide picks a side of a conflict to keep. In an agent's
+// worktree, where base is merged into the agent's branch, ours is the
+// agent's version and theirs is base's.
+type ConflictSide string
+
+const (
+	Ours   ConflictSide = "ours"
+	Theirs ConflictSide = "theirs"
+)
+
+// unmergedStages returns the blob of each stage of the conflicted files
+// in wtPath, or of paths if given: 1 is the common ancestor, 2 ours and 3
+// theirs. A side that deleted the file has no stage.
+func unmergedStages(wtPath string, paths ...string) (map[string]map[string]string, []string, error) {
+	out, err := output(append([]string{"-C", wtPath, "ls-files", "-u", "-z", "--"}, paths...)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list unmerged files: %w", err)
+	}
+	stages := make(map[string]map[string]string)
+	var order []string
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// "<mode> <object> <stage>\t<path>"
+		meta, path, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 {
+			continue
+		}
+		if stages[path] == nil {
+			stages[path] = make(map[string]string)
+			order = append(order, path)
+		}
+		stages[path][fields[2]] = fields[1]
+	}
+	return stages, order, nil
+}
+
+// ConflictDetails lists the conflicted files in wtPath, noting which are
+// binary and how large they are.
+func ConflictDetails(wtPath string) ([]Conflict, error) {
+	stages, paths, err := unmergedStages(wtPath)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []Conflict
+	for _, path := range paths {
+		c := Conflict{Path: path}
+		for _, stage := range []string{"2", "3"} {
+			blob, ok := stages[path][stage]
+			if !ok {
+				continue
+			}
+			if out, err := output("-C", wtPath, "cat-file", "-s", blob); err == nil {
+				if n, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil && n > c.Size {
+					c.Size = n
+				}
+			}
+			if !c.Binary {
+				c.Binary = isBinaryBlob(wtPath, blob)
+			}
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts, nil
+}
+
+// isBinaryBlob applies git's heuristic: a blob is binary if its first
+// 8000 bytes contain a NUL.
+func isBinaryBlob(wtPath, blob string) bool {
+	cmd := exec.Command("git", "-C", wtPath, "cat-file", "blob", blob)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return false
+	}
+	if err := cmd.Start(); err != nil {
+		return false
+	}
+	buf := make([]byte, 8000)
+	n, _ := io.ReadFull(stdout, buf)
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	return bytes.IndexByte(buf[:n], 0) >= 0
+}
+
+// ResolveConflict resolves the conflicted file path in wtPath by keeping
+// side's version, or deleting the file if side deleted it, and stages
+// the result.
+func ResolveConflict(wtPath, path string, side ConflictSide) error {
+	stages, _, err := unmergedStages(wtPath, path)
+	if err != nil {
+		return err
+	}
+	if stages[path] == nil {
+		return fmt.Errorf("%s is not conflicted", path)
+	}
+	stage := "2"
+	if side == Theirs {
+		stage = "3"
+	}
+	if _, ok := stages[path][stage]; !ok {
+		if err := run("-C", wtPath, "rm", "--quiet", "--", path); err != nil {
+			return fmt.Errorf("failed to take %s deletion of %s: %w", side, path, err)
+		}
+		return nil
+	}
+	if err := run("-C", wtPath, "checkout", "--"+string(side), "--", path); err != nil {
+		return fmt.Errorf("failed to take %s version of %s: %w", side, path, err)
+	}
+	if err := run("-C", wtPath, "add", "--", path); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	return nil
+}
+
+// CommitMerge concludes the merge in progress in wtPath once all its
+// conflicts are resolved.
+func CommitMerge(wtPath string) error {
+	if err := run("-C", wtPath, "commit", "--no-edit"); err != nil {
+		return fmt.Errorf("failed to commit merge: %w", err)
+	}
+	return nil
+}
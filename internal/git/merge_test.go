@@ -0,0 +1,136 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeInWorktree_ReplaysResolutionsAcrossWorktrees(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	commitFile(t, repo, "f.txt", "a\n", "base")
+	CreateBranch(repo, "feat1", defaultBranch)
+	CreateBranch(repo, "feat2", defaultBranch)
+	commitFile(t, repo, "f.txt", "main\n", "main change")
+
+	var wts []string
+	for _, b := range []string{"feat1", "feat2"} {
+		wt := filepath.Join(t.TempDir(), b)
+		if out, err := exec.Command("git", "-C", repo, "worktree", "add", wt, b).CombinedOutput(); err != nil {
+			t.Fatalf("worktree add: %s", out)
+		}
+		defer exec.Command("git", "-C", repo, "worktree", "remove", wt, "--force").Run()
+		commitFile(t, wt, "f.txt", "agent\n", "agent change")
+		wts = append(wts, wt)
+	}
+
+	// The first agent's conflict is resolved by hand and recorded...
+	conflicted, err := MergeInWorktree(wts[0], defaultBranch, true)
+	if err != nil || !conflicted {
+		t.Fatalf("MergeInWorktree = %v, %v; want a conflict", conflicted, err)
+	}
+	commitFile(t, wts[0], "f.txt", "resolved\n", "resolve")
+
+	// ...and replayed for the second, staged for review.
+	conflicted, err = MergeInWorktree(wts[1], defaultBranch, true)
+	if err != nil || !conflicted {
+		t.Fatalf("MergeInWorktree = %v, %v; want the merge left for review", conflicted, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wts[1], "f.txt")); string(data) != "resolved\n" {
+		t.Errorf("f.txt = %q, want the recorded resolution", data)
+	}
+	if files, _ := ConflictFiles(wts[1]); len(files) != 0 {
+		t.Errorf("conflict files = %v, want the resolution staged", files)
+	}
+
+	if out, _ := exec.Command("git", "-C", repo, "config", "--get", "rerere.enabled").Output(); len(out) != 0 {
+		t.Errorf("rerere.enabled = %q, want the repository config left alone", out)
+	}
+}
+
+func TestMergeInWorktree_KeepsUserRerereSetting(t *testing.T) {
+	repo := setupTestRepo(t)
+	exec.Command("git", "-C", repo, "config", "rerere.enabled", "false").Run()
+	if args := rerereArgs(repo); len(args) != 2 || args[1] != "rerere.autoUpdate=true" {
+		t.Errorf("rerereArgs = %q, want only autoUpdate set", args)
+	}
+}
+
+func TestMergeDriverProblems(t *testing.T) {
+	repo := setupTestRepo(t)
+	commitFile(t, repo, ".gitattributes", "*.lock merge=lockfile\n*.json merge=jsondriver\n*.txt merge=union\n", "attributes")
+	exec.Command("git", "-C", repo, "config", "merge.jsondriver.driver", "json-merge %O %A %B").Run()
+
+	problems := MergeDriverProblems(repo, []string{"deps.lock", "data.json", "notes.txt", "main.go"})
+	if len(problems) != 2 {
+		t.Fatalf("problems = %q, want the lockfile and json drivers", problems)
+	}
+	if !strings.Contains(problems[0], "deps.lock") || !strings.Contains(problems[0], "merge.lockfile.driver") {
+		t.Errorf("problems[0] = %q, want the missing lockfile driver", problems[0])
+	}
+	if !strings.Contains(problems[1], "data.json") || !strings.Contains(problems[1], "json-merge") {
+		t.Errorf("problems[1] = %q, want the json driver's command", problems[1])
+	}
+}
+
+func TestConflictDetailsAndResolveConflict(t *testing.T) {
+	repo := setupTestRepo(t)
+	defaultBranch, _ := CurrentBranch(repo)
+
+	commitFile(t, repo, "f.txt", "a\n", "text")
+	commitFile(t, repo, "logo.png", "\x89PNG\x00base", "binary")
+	CreateBranch(repo, "feat", defaultBranch)
+	commitFile(t, repo, "f.txt", "main\n", "main text")
+	commitFile(t, repo, "logo.png", "\x89PNG\x00main, and longer", "main binary")
+
+	wt := filepath.Join(t.TempDir(), "feat")
+	if out, err := exec.Command("git", "-C", repo, "worktree", "add", wt, "feat").CombinedOutput(); err != nil {
+		t.Fatalf("worktree add: %s", out)
+	}
+	defer exec.Command("git", "-C", repo, "worktree", "remove", wt, "--force").Run()
+	commitFile(t, wt, "f.txt", "agent\n", "agent text")
+	commitFile(t, wt, "logo.png", "\x89PNG\x00agent", "agent binary")
+
+	if conflicted, err := MergeInWorktree(wt, defaultBranch, false); err != nil || !conflicted {
+		t.Fatalf("MergeInWorktree = %v, %v; want a conflict", conflicted, err)
+	}
+
+	conflicts, err := ConflictDetails(wt)
+	if err != nil {
+		t.Fatalf("ConflictDetails: %v", err)
+	}
+	want := []Conflict{{Path: "f.txt", Size: 6}, {Path: "logo.png", Binary: true, Size: 21}}
+	if len(conflicts) != 2 || conflicts[0] != want[0] || conflicts[1] != want[1] {
+		t.Fatalf("ConflictDetails = %+v, want %+v", conflicts, want)
+	}
+
+	if err := ResolveConflict(wt, "logo.png", Theirs); err != nil {
+		t.Fatalf("ResolveConflict: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wt, "logo.png")); string(data) != "\x89PNG\x00main, and longer" {
+		t.Errorf("logo.png = %q, want base's version", data)
+	}
+	if err := ResolveConflict(wt, "f.txt", Ours); err != nil {
+		t.Fatalf("ResolveConflict: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wt, "f.txt")); string(data) != "agent\n" {
+		t.Errorf("f.txt = %q, want the agent's version", data)
+	}
+	if files, _ := ConflictFiles(wt); len(files) != 0 {
+		t.Errorf("ConflictFiles = %v, want none left", files)
+	}
+	if err := ResolveConflict(wt, "f.txt", Ours); err == nil {
+		t.Error("resolving a file that isn't conflicted should fail")
+	}
+
+	if err := CommitMerge(wt); err != nil {
+		t.Fatalf("CommitMerge: %v", err)
+	}
+	if !IsAncestor(repo, defaultBranch, "feat") {
+		t.Error("the merge should be committed on feat")
+	}
+}
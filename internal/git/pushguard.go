@@ -0,0 +1,110 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pushGuardMarker is created in the git directory of a worktree that may
+// not push. Each worktree has its own git directory, so the marker applies
+// to that worktree only, while the hook is shared by all of them.
+const pushGuardMarker = "mastermind-no-push"
+
+// pushGuardTag identifies a pre-push hook installed by InstallPushGuard.
+const pushGuardTag = "# mastermind push guard"
+
+// pushGuardChained is the name the repository's own pre-push hook is moved
+// to when the guard is installed in its place. The guard runs it after
+// letting a push through.
+const pushGuardChained = "pre-push.mastermind-chained"
+
+const pushGuardHook = `#!/bin/sh
+` + pushGuardTag + `
+# Refuses pushes from agent worktrees that mastermind has not allowed to
+# push. Toggle it per agent from the mastermind dashboard.
+if [ -f "$(git rev-parse --git-dir)/` + pushGuardMarker + `" ]; then
+	echo "mastermind: pushing from this agent worktree is blocked; allow it from the dashboard" >&2
+	exit 1
+fi
+chained="$(dirname "$0")/` + pushGuardChained + `"
+if [ -x "$chained" ]; then
+	exec "$chained" "$@"
+fi
+exit 0
+`
+
+// InstallPushGuard installs the pre-push hook that enforces SetPushBlocked
+// in the repository's hooks directory, honouring core.hooksPath. A
+// pre-push hook that mastermind did not write is moved aside and run by
+// the guard for the pushes it lets through. One in a hooks directory
+// outside the git directory, e.g. one tracked in the repository, is left
+// alone and reported as an error.
+func InstallPushGuard(repoPath string) error {
+	out, err := output("-C", repoPath, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return fmt.Errorf("failed to locate hooks directory: %w", err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoPath, dir)
+	}
+	path := filepath.Join(dir, "pre-push")
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil && string(data) == pushGuardHook:
+		return nil
+	case err == nil && !strings.Contains(string(data), pushGuardTag):
+		if err := chainPrePush(repoPath, dir); err != nil {
+			return err
+		}
+	case err != nil && !os.IsNotExist(err):
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(pushGuardHook), 0o755)
+}
+
+// chainPrePush moves the repository's own pre-push hook in hooks dir
+// aside for the guard to run.
+func chainPrePush(repoPath, dir string) error {
+	path := filepath.Join(dir, "pre-push")
+	out, err := output("-C", repoPath, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	gitDir := strings.TrimSpace(string(out))
+	if rel, err := filepath.Rel(gitDir, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s already exists outside the git directory; not moving it to install the push guard", path)
+	}
+	chained := filepath.Join(dir, pushGuardChained)
+	if _, err := os.Stat(chained); err == nil {
+		return fmt.Errorf("%s already exists and was not installed by mastermind, and %s is taken; not replacing it", path, chained)
+	}
+	if err := os.Rename(path, chained); err != nil {
+		return fmt.Errorf("move %s aside: %w", path, err)
+	}
+	return nil
+}
+
+// SetPushBlocked blocks or allows pushing from the worktree at wtPath
+// through the hook installed by InstallPushGuard.
+func SetPushBlocked(wtPath string, blocked bool) error {
+	out, err := output("-C", wtPath, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	marker := filepath.Join(strings.TrimSpace(string(out)), pushGuardMarker)
+	if !blocked {
+		if err := os.Remove(marker); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(marker, nil, 0o644)
+}
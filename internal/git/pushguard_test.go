@@ -0,0 +1,94 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPushGuard(t *testing.T) {
+	repo := setupTestRepo(t)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if err := run("init", "--bare", "-q", remote); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("-C", repo, "remote", "add", "origin", remote); err != nil {
+		t.Fatal(err)
+	}
+	CreateBranch(repo, "feat/x", "HEAD")
+	wtPath, err := CreateWorktree(repo, filepath.Join(t.TempDir(), WorktreeDirName("feat/x")), "feat/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InstallPushGuard(repo); err != nil {
+		t.Fatalf("InstallPushGuard: %v", err)
+	}
+	if err := InstallPushGuard(repo); err != nil {
+		t.Fatalf("reinstalling: %v", err)
+	}
+	if err := SetPushBlocked(wtPath, true); err != nil {
+		t.Fatal(err)
+	}
+
+	err = run("-C", wtPath, "push", "-q", "origin", "feat/x")
+	if err == nil || !strings.Contains(err.Error(), "pushing from this agent worktree is blocked") {
+		t.Errorf("push from blocked worktree: err = %v", err)
+	}
+	main, _ := CurrentBranch(repo)
+	if err := run("-C", repo, "push", "-q", "origin", main); err != nil {
+		t.Errorf("push from the main worktree: %v", err)
+	}
+
+	if err := SetPushBlocked(wtPath, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("-C", wtPath, "push", "-q", "origin", "feat/x"); err != nil {
+		t.Errorf("push after allowing: %v", err)
+	}
+}
+
+func TestInstallPushGuard_ChainsForeignHook(t *testing.T) {
+	repo := setupTestRepo(t)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if err := run("init", "--bare", "-q", remote); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("-C", repo, "remote", "add", "origin", remote); err != nil {
+		t.Fatal(err)
+	}
+	hook := filepath.Join(repo, ".git", "hooks", "pre-push")
+	os.MkdirAll(filepath.Dir(hook), 0o755)
+	os.WriteFile(hook, []byte("#!/bin/sh\necho project hook >&2\nexit 1\n"), 0o755)
+
+	if err := InstallPushGuard(repo); err != nil {
+		t.Fatalf("InstallPushGuard: %v", err)
+	}
+	if err := InstallPushGuard(repo); err != nil {
+		t.Fatalf("reinstalling: %v", err)
+	}
+	// Pushes the guard lets through still go through the project's hook.
+	main, _ := CurrentBranch(repo)
+	err := run("-C", repo, "push", "-q", "origin", main)
+	if err == nil || !strings.Contains(err.Error(), "project hook") {
+		t.Errorf("push: err = %v, want the project's hook to run", err)
+	}
+}
+
+func TestInstallPushGuard_KeepsHookOutsideGitDir(t *testing.T) {
+	repo := setupTestRepo(t)
+	if err := run("-C", repo, "config", "core.hooksPath", ".githooks"); err != nil {
+		t.Fatal(err)
+	}
+	hook := filepath.Join(repo, ".githooks", "pre-push")
+	os.MkdirAll(filepath.Dir(hook), 0o755)
+	os.WriteFile(hook, []byte("#!/bin/sh\nexit 0\n"), 0o755)
+
+	if err := InstallPushGuard(repo); err == nil {
+		t.Error("expected an error for a pre-push hook outside the git directory")
+	}
+	if data, _ := os.ReadFile(hook); string(data) != "#!/bin/sh\nexit 0\n" {
+		t.Errorf("hook replaced:\n%s", data)
+	}
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// ValidateBranchName reports why name cannot be used as a new branch. It
+// asks `git check-ref-format --branch`, whose rules also keep names safe
+// to join into a worktree path: no whitespace, "~", ":" or other special
+// characters, no ".." and no component starting with ".".
+func ValidateBranchName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("branch name is empty")
+	case name == "@", strings.HasPrefix(name, "@{-"):
+		// check-ref-format expands these as revisions; git branch refuses them.
+		return fmt.Errorf("%q is not a valid branch name", name)
+	}
+	if err := run("check-ref-format", "--branch", name); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("failed to check branch name %q: %w", name, err)
+		}
+		return fmt.Errorf("%q is not a valid branch name", name)
+	}
+	return nil
+}
+
+// unsafeRefRun matches runs of characters that cannot appear in a branch
+// name.
+var unsafeRefRun = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]+`)
+
+// SanitizeBranchName turns name into a valid branch name by replacing
+// unsafe characters with '-' and dropping what cannot be fixed that way.
+// It returns "" when nothing usable is left.
+func SanitizeBranchName(name string) string {
+	name = unsafeRefRun.ReplaceAllString(strings.TrimSpace(name), "-")
+	name = strings.ReplaceAll(name, "@{", "-")
+	for strings.Contains(name, "..") {
+		name = strings.ReplaceAll(name, "..", ".")
+	}
+
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		part = strings.TrimLeft(part, ".-")
+		for strings.HasSuffix(part, ".lock") {
+			part = strings.TrimSuffix(part, ".lock")
+		}
+		part = strings.TrimRight(part, ".-")
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	name = strings.Join(parts, "/")
+	if ValidateBranchName(name) != nil {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,41 @@
+package git
+
+import "testing"
+
+func TestValidateBranchName(t *testing.T) {
+	for _, name := range []string{"feat/x", "fix-123", "user/ENG-42-login", "a.b", "v1.2.3"} {
+		if err := ValidateBranchName(name); err != nil {
+			t.Errorf("ValidateBranchName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{
+		"", "@", "@{-1}", "HEAD", "-x", "/x", "x/", "a//b", "a..b", "../etc", "a@{1}", "x.",
+		"has space", "tilde~1", "c:drive", "why?", "glob*", "br[1]", "back\\slash", "ctl\x01",
+		"feat/.hidden", "x.lock", "feat/y.lock/z",
+	} {
+		if ValidateBranchName(name) == nil {
+			t.Errorf("ValidateBranchName(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestSanitizeBranchName(t *testing.T) {
+	for in, want := range map[string]string{
+		"feat/x":               "feat/x",
+		"Fix login page":       "Fix-login-page",
+		"feat: add ~ thing":    "feat-add-thing",
+		"../../etc/passwd":     "etc/passwd",
+		"feat//.hidden/x.lock": "feat/hidden/x",
+		"-leading.":            "leading",
+		"a..b":                 "a.b",
+		"~~~":                  "",
+	} {
+		got := SanitizeBranchName(in)
+		if got != want {
+			t.Errorf("SanitizeBranchName(%q) = %q, want %q", in, got, want)
+		}
+		if got != "" && ValidateBranchName(got) != nil {
+			t.Errorf("SanitizeBranchName(%q) = %q, which is invalid", in, got)
+		}
+	}
+}
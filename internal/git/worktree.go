@@ -9,16 +9,22 @@ import (
 	"strings"
 )
 
-func CreateWorktree(repoPath, worktreeDir, branch string) (string, error) {
-	wtPath := filepath.Join(worktreeDir, branch)
-	out, err := exec.Command("git", "-C", repoPath, "worktree", "add", wtPath, branch).CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("failed to create worktree at %s for branch %s: %w\n%s", wtPath, branch, err, out)
+// WorktreeDirName returns the directory name for branch's worktree. Slashes
+// are flattened to "__" so "feat" and "feat/x" get sibling directories
+// rather than one nested inside the other.
+func WorktreeDirName(branch string) string {
+	return strings.ReplaceAll(branch, "/", "__")
+}
+
+// CreateWorktree checks out branch in a new worktree at wtPath.
+func CreateWorktree(repoPath, wtPath, branch string) (string, error) {
+	if err := run("-C", repoPath, "worktree", "add", wtPath, branch); err != nil {
+		return "", fmt.Errorf("failed to create worktree at %s for branch %s: %w", wtPath, branch, err)
 	}
 
 	// Verify the worktree actually checked out the requested branch.
 	// This guards against git silently checking out a different branch.
-	headOut, err := exec.Command("git", "-C", wtPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	headOut, err := output("-C", wtPath, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		// Cleanup the possibly-bad worktree
 		_ = exec.Command("git", "-C", repoPath, "worktree", "remove", wtPath, "--force").Run()
@@ -34,7 +40,7 @@ func CreateWorktree(repoPath, worktreeDir, branch string) (string, error) {
 }
 
 func RemoveWorktree(repoPath, wtPath string) error {
-	err := exec.Command("git", "-C", repoPath, "worktree", "remove", wtPath, "--force").Run()
+	err := run("-C", repoPath, "worktree", "remove", wtPath, "--force")
 	if err != nil {
 		return fmt.Errorf("failed to remove worktree %s: %w", wtPath, err)
 	}
@@ -49,6 +55,19 @@ func RemoveWorktree(repoPath, wtPath string) error {
 	return nil
 }
 
+// MoveWorktree relocates the worktree at from to to, creating to's parent
+// directories and removing any left empty under the worktrees root.
+func MoveWorktree(repoPath, from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return err
+	}
+	if err := run("-C", repoPath, "worktree", "move", from, to); err != nil {
+		return fmt.Errorf("failed to move worktree %s to %s: %w", from, to, err)
+	}
+	removeEmptyParents(from, filepath.Join(repoPath, ".worktrees"))
+	return nil
+}
+
 // removeEmptyParents removes empty directories starting from dir, walking up
 // to (but not including) stopAt.
 func removeEmptyParents(dir, stopAt string) {
@@ -65,11 +84,136 @@ func removeEmptyParents(dir, stopAt string) {
 	}
 }
 
+// ExcludeFromRepo adds pattern to the repository's info/exclude file,
+// which applies to the main working tree and all worktrees, unless an
+// equivalent pattern is already listed there.
+func ExcludeFromRepo(repoPath, pattern string) error {
+	out, err := output("-C", repoPath, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	gitDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(repoPath, gitDir)
+	}
+	path := filepath.Join(gitDir, "info", "exclude")
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.Trim(strings.TrimSpace(line), "/") == strings.Trim(pattern, "/") {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	entry := pattern + "\n"
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		entry = "\n" + entry
+	}
+	_, err = f.WriteString(entry)
+	return err
+}
+
 type Worktree struct {
 	Path   string
 	Branch string
 }
 
+// PruneWorktrees removes administrative data for worktrees whose
+// directories no longer exist and returns git's description of each
+// pruned entry.
+func PruneWorktrees(repoPath string) ([]string, error) {
+	out, err := exec.Command("git", "-C", repoPath, "worktree", "prune", "--verbose").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune worktrees: %s (%w)", strings.TrimSpace(string(out)), err)
+	}
+	var pruned []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			pruned = append(pruned, line)
+		}
+	}
+	return pruned, nil
+}
+
+// IsWorktreeBroken reports whether wtPath is a linked worktree whose .git
+// file points to a gitdir that no longer exists, as happens after the main
+// repository is moved. Directories without a .git file are not considered
+// broken.
+func IsWorktreeBroken(wtPath string) bool {
+	data, err := os.ReadFile(filepath.Join(wtPath, ".git"))
+	if err != nil {
+		return false
+	}
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return false
+	}
+	gitdir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(wtPath, gitdir)
+	}
+	_, err = os.Stat(gitdir)
+	return os.IsNotExist(err)
+}
+
+// RepairWorktrees runs `git worktree repair` from the main repository so the
+// links between it and the given worktrees point at their current locations.
+func RepairWorktrees(repoPath string, wtPaths []string) error {
+	args := append([]string{"-C", repoPath, "worktree", "repair"}, wtPaths...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to repair worktrees: %s (%w)", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// GCAuto runs `git gc --auto`, which only does work when the repository
+// needs housekeeping.
+func GCAuto(repoPath string) error {
+	out, err := exec.Command("git", "-C", repoPath, "gc", "--auto", "--quiet").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to gc: %s (%w)", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// DiscardChanges resets the worktree at wtPath to HEAD, dropping its
+// uncommitted changes and untracked (non-ignored) files.
+func DiscardChanges(wtPath string) error {
+	if err := run("-C", wtPath, "reset", "--hard", "-q"); err != nil {
+		return fmt.Errorf("failed to reset changes: %w", err)
+	}
+	if err := run("-C", wtPath, "clean", "-fd"); err != nil {
+		return fmt.Errorf("failed to remove untracked files: %w", err)
+	}
+	return nil
+}
+
+// StashChanges stashes the changes to paths in the worktree at wtPath,
+// untracked files included, under message, and returns the stash commit.
+func StashChanges(wtPath, message string, paths ...string) (string, error) {
+	args := append([]string{"-C", wtPath, "stash", "push", "--include-untracked", "-m", message, "--"}, paths...)
+	if err := run(args...); err != nil {
+		return "", fmt.Errorf("failed to stash changes: %w", err)
+	}
+	out, err := output("-C", wtPath, "rev-parse", "--short", "stash@{0}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve stash: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // HasChanges returns true if the worktree at wtPath has any uncommitted changes
 // (staged, unstaged, or untracked files).
 func HasChanges(wtPath string) bool {
@@ -82,7 +226,7 @@ func HasChanges(wtPath string) bool {
 }
 
 func ListWorktrees(repoPath string) ([]Worktree, error) {
-	out, err := exec.Command("git", "-C", repoPath, "worktree", "list", "--porcelain").Output()
+	out, err := output("-C", repoPath, "worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ func TestCreateAndListWorktrees(t *testing.T) {
 
 	CreateBranch(repo, "feat/wt-test", "HEAD")
 
-	wtPath, err := CreateWorktree(repo, wtDir, "feat/wt-test")
+	wtPath, err := CreateWorktree(repo, filepath.Join(wtDir, WorktreeDirName("feat/wt-test")), "feat/wt-test")
 	if err != nil {
 		t.Fatalf("CreateWorktree: %v", err)
 	}
@@ -47,7 +48,7 @@ func TestRemoveWorktree(t *testing.T) {
 	os.MkdirAll(wtDir, 0o755)
 
 	CreateBranch(repo, "feat/rm-test", "HEAD")
-	wtPath, _ := CreateWorktree(repo, wtDir, "feat/rm-test")
+	wtPath, _ := CreateWorktree(repo, filepath.Join(wtDir, WorktreeDirName("feat/rm-test")), "feat/rm-test")
 
 	if err := RemoveWorktree(repo, wtPath); err != nil {
 		t.Fatalf("RemoveWorktree: %v", err)
@@ -58,6 +59,112 @@ func TestRemoveWorktree(t *testing.T) {
 	}
 }
 
+func TestMoveWorktree(t *testing.T) {
+	repo := setupTestRepo(t)
+	wtDir := filepath.Join(repo, ".worktrees")
+
+	CreateBranch(repo, "feat/nested", "HEAD")
+	legacy, err := CreateWorktree(repo, filepath.Join(wtDir, "feat/nested"), "feat/nested")
+	if err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+
+	flat := filepath.Join(wtDir, WorktreeDirName("feat/nested"))
+	if err := MoveWorktree(repo, legacy, flat); err != nil {
+		t.Fatalf("MoveWorktree: %v", err)
+	}
+	if got := WorktreeForBranch(repo, "feat/nested"); got != flat {
+		t.Errorf("worktree for branch = %q, want %q", got, flat)
+	}
+	if _, err := os.Stat(filepath.Join(wtDir, "feat")); !os.IsNotExist(err) {
+		t.Error("empty legacy parent directory should be removed")
+	}
+}
+
+func TestPruneWorktrees(t *testing.T) {
+	repo := setupTestRepo(t)
+	wtDir := filepath.Join(t.TempDir(), "worktrees")
+	os.MkdirAll(wtDir, 0o755)
+
+	CreateBranch(repo, "feat/gone", "HEAD")
+	wtPath, _ := CreateWorktree(repo, filepath.Join(wtDir, WorktreeDirName("feat/gone")), "feat/gone")
+	os.RemoveAll(wtPath)
+
+	pruned, err := PruneWorktrees(repo)
+	if err != nil {
+		t.Fatalf("PruneWorktrees: %v", err)
+	}
+	if len(pruned) != 1 {
+		t.Errorf("pruned = %v, want one entry", pruned)
+	}
+	if err := GCAuto(repo); err != nil {
+		t.Errorf("GCAuto: %v", err)
+	}
+}
+
+func TestRepairWorktrees_AfterRepoMove(t *testing.T) {
+	repo := setupTestRepo(t)
+	wtDir := filepath.Join(t.TempDir(), "worktrees")
+	os.MkdirAll(wtDir, 0o755)
+
+	CreateBranch(repo, "feat/moved", "HEAD")
+	wtPath, err := CreateWorktree(repo, filepath.Join(wtDir, WorktreeDirName("feat/moved")), "feat/moved")
+	if err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	if IsWorktreeBroken(wtPath) {
+		t.Fatal("fresh worktree should not be broken")
+	}
+
+	moved := repo + "-moved"
+	if err := os.Rename(repo, moved); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(moved) })
+	if !IsWorktreeBroken(wtPath) {
+		t.Fatal("worktree should be broken after the repo moved")
+	}
+
+	if err := RepairWorktrees(moved, []string{wtPath}); err != nil {
+		t.Fatalf("RepairWorktrees: %v", err)
+	}
+	if IsWorktreeBroken(wtPath) {
+		t.Error("worktree still broken after repair")
+	}
+	if branch, err := CurrentBranch(wtPath); err != nil || branch != "feat/moved" {
+		t.Errorf("CurrentBranch = %q, %v; want feat/moved", branch, err)
+	}
+}
+
+func TestExcludeFromRepo(t *testing.T) {
+	repo := setupTestRepo(t)
+	exclude := filepath.Join(repo, ".git", "info", "exclude")
+	os.MkdirAll(filepath.Dir(exclude), 0o755)
+	os.WriteFile(exclude, []byte("*.log"), 0o644)
+
+	for i := 0; i < 2; i++ {
+		if err := ExcludeFromRepo(repo, "/.worktrees/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(exclude)
+	if string(data) != "*.log\n/.worktrees/\n" {
+		t.Errorf("exclude = %q, want the pattern appended once", data)
+	}
+
+	os.MkdirAll(filepath.Join(repo, ".worktrees", "feat"), 0o755)
+	os.WriteFile(filepath.Join(repo, ".worktrees", "feat", "a.txt"), []byte("x"), 0o644)
+	if HasChanges(repo) {
+		t.Error("files under .worktrees show up in git status")
+	}
+}
+
+func TestIsWorktreeBroken_PlainDir(t *testing.T) {
+	if IsWorktreeBroken(t.TempDir()) {
+		t.Error("directory without .git should not be reported broken")
+	}
+}
+
 func TestHasChanges_Clean(t *testing.T) {
 	repo := setupTestRepo(t)
 
@@ -75,13 +182,56 @@ func TestHasChanges_Dirty(t *testing.T) {
 	}
 }
 
+func TestDiscardChanges(t *testing.T) {
+	repo := setupTestRepo(t)
+	commitFile(t, repo, "README.md", "original", "add readme")
+
+	os.WriteFile(filepath.Join(repo, "README.md"), []byte("changed"), 0o644)
+	os.MkdirAll(filepath.Join(repo, "new"), 0o755)
+	os.WriteFile(filepath.Join(repo, "new", "file.txt"), []byte("new"), 0o644)
+	if err := DiscardChanges(repo); err != nil {
+		t.Fatalf("DiscardChanges: %v", err)
+	}
+	if HasChanges(repo) {
+		t.Error("changes and untracked files should be gone")
+	}
+}
+
+func TestStashChanges(t *testing.T) {
+	repo := setupTestRepo(t)
+	commitFile(t, repo, "a.txt", "one", "add a")
+	commitFile(t, repo, "b.txt", "one", "add b")
+
+	os.WriteFile(filepath.Join(repo, "a.txt"), []byte("two"), 0o644)
+	os.WriteFile(filepath.Join(repo, "b.txt"), []byte("two"), 0o644)
+	os.WriteFile(filepath.Join(repo, "new.txt"), []byte("new"), 0o644)
+	stash, err := StashChanges(repo, "keep these", "a.txt", "new.txt")
+	if err != nil || stash == "" {
+		t.Fatalf("StashChanges = %q, %v", stash, err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(repo, "a.txt")); string(data) != "one" {
+		t.Errorf("a.txt = %q, want it stashed", data)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "new.txt")); !os.IsNotExist(err) {
+		t.Error("new.txt should be stashed")
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, "b.txt")); string(data) != "two" {
+		t.Errorf("b.txt = %q, want it left alone", data)
+	}
+	out, _ := exec.Command("git", "-C", repo, "stash", "list").Output()
+	if !strings.Contains(string(out), "keep these") {
+		t.Errorf("stash list = %q, want the message", out)
+	}
+}
+
 func TestWorktreeForBranch(t *testing.T) {
 	repo := setupTestRepo(t)
 	wtDir := filepath.Join(t.TempDir(), "worktrees")
 	os.MkdirAll(wtDir, 0o755)
 
 	CreateBranch(repo, "feat/find-me", "HEAD")
-	wtPath, _ := CreateWorktree(repo, wtDir, "feat/find-me")
+	wtPath, _ := CreateWorktree(repo, filepath.Join(wtDir, WorktreeDirName("feat/find-me")), "feat/find-me")
 	defer exec.Command("git", "-C", repo, "worktree", "remove", wtPath, "--force").Run()
 
 	found := WorktreeForBranch(repo, "feat/find-me")
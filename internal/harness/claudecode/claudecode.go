@@ -12,6 +12,7 @@ import (
 	"github.com/simonbystrom/mastermind/internal/config"
 	"github.com/simonbystrom/mastermind/internal/harness"
 	"github.com/simonbystrom/mastermind/internal/hook"
+	"github.com/simonbystrom/mastermind/internal/streamjson"
 )
 
 // Harness implements the Claude Code integration.
@@ -37,9 +38,26 @@ func (h *Harness) Setup(worktreePath string, opts harness.SetupOptions) error {
 
 func (h *Harness) Command(opts harness.Options) []string {
 	cmd := []string{"claude"}
+	relayed := false
+	if opts.StreamJSON {
+		if exe, err := os.Executable(); err == nil {
+			cmd = []string{exe, "stream-relay"}
+			if opts.Prompt != "" {
+				cmd = append(cmd, "--task", opts.Prompt)
+			}
+			cmd = append(cmd, "--", "claude", "-p", "--output-format", "stream-json", "--verbose")
+			relayed = true
+		}
+	}
 	if opts.SkipPermissions {
 		cmd = append(cmd, "--dangerously-skip-permissions")
 	}
+	if opts.Context != "" {
+		cmd = append(cmd, "--append-system-prompt", opts.Context)
+	}
+	if opts.Prompt != "" && !relayed {
+		cmd = append(cmd, opts.Prompt)
+	}
 	return cmd
 }
 
@@ -61,7 +79,8 @@ func (h *Harness) ReadMetrics(worktreePath string) (*harness.MetricsData, error)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			// Stream-json agents have no statusline; use the relay state.
+			return readStreamMetrics(worktreePath)
 		}
 		return nil, fmt.Errorf("read claude status: %w", err)
 	}
@@ -109,13 +128,29 @@ func (h *Harness) ReadMetrics(worktreePath string) (*harness.MetricsData, error)
 	return md, nil
 }
 
+// readStreamMetrics converts the stream relay state into metrics.
+func readStreamMetrics(worktreePath string) (*harness.MetricsData, error) {
+	st, err := streamjson.ReadState(worktreePath)
+	if err != nil || st == nil {
+		return nil, err
+	}
+	return &harness.MetricsData{
+		Model:        st.Model,
+		CostUSD:      st.CostUSD,
+		SessionID:    st.SessionID,
+		InputTokens:  st.InputTokens,
+		OutputTokens: st.OutputTokens,
+	}, nil
+}
+
 func (h *Harness) StalenessThreshold() time.Duration {
 	return hook.StalenessThreshold
 }
 
-// writeProjectSettings writes .claude/settings.json in the worktree
-// to configure Claude Code's statusline for this agent. It also ensures the
-// .claude/ directory and .claude-status.json sidecar are git-ignored.
+// writeProjectSettings merges mastermind's keys into .claude/settings.local.json
+// in the worktree to configure Claude Code's statusline for this agent. The project
+// may track settings.json, so the keys stay out of it lest the agent commit them.
+// It also ensures the .claude/ directory and .claude-status.json sidecar are git-ignored.
 func (h *Harness) writeProjectSettings(wtPath string, opts harness.SetupOptions) error {
 	dir := filepath.Join(wtPath, ".claude")
 	if err := os.MkdirAll(dir, 0o755); err != nil {
@@ -129,6 +164,8 @@ func (h *Harness) writeProjectSettings(wtPath string, opts harness.SetupOptions)
 
 	// Also gitignore the sidecar file at the worktree root
 	_ = appendGitExclude(wtPath, ".claude-status.json")
+	_ = appendGitExclude(wtPath, hook.HeartbeatFileName)
+	_ = appendGitExclude(wtPath, streamjson.StateFileName)
 
 	settings := map[string]interface{}{
 		"statusLine": map[string]string{
@@ -146,12 +183,7 @@ func (h *Harness) writeProjectSettings(wtPath string, opts harness.SetupOptions)
 		settings["teammateMode"] = opts.TeammateMode
 	}
 
-	data, err := json.MarshalIndent(settings, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(filepath.Join(dir, "settings.json"), data, 0o644)
+	return hook.MergeSettings(filepath.Join(dir, "settings.local.json"), settings)
 }
 
 // appendGitExclude adds a pattern to .git/info/exclude for the given worktree
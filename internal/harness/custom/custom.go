@@ -0,0 +1,128 @@
+// Package custom runs an arbitrary agent CLI, such as aider or codex,
+// configured under [[harness.agents]]. Such tools write no status or
+// metrics sidecars, so their status comes from matching the bottom of the
+// agent's pane against the configured patterns.
+package custom
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/harness"
+)
+
+// Spec describes an agent CLI.
+type Spec struct {
+	// Name identifies the harness, e.g. "aider"; it is the agent's
+	// harness type.
+	Name string
+	// Command is the executable and Args its arguments.
+	Command string
+	Args    []string
+	// PromptFlag passes an initial prompt, e.g. "--message"; empty appends
+	// the prompt as the last argument.
+	PromptFlag string
+	// InputPatterns and PermissionPatterns are regular expressions; a
+	// line at the bottom of the pane matching one means the agent waits
+	// for input or for permission.
+	InputPatterns      []string
+	PermissionPatterns []string
+}
+
+// Harness implements harness.Harness for a Spec.
+type Harness struct {
+	spec       Spec
+	input      []*regexp.Regexp
+	permission []*regexp.Regexp
+}
+
+// New validates spec and compiles its patterns.
+func New(spec Spec) (*Harness, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("agent command has no name")
+	}
+	switch harness.Type(spec.Name) {
+	case harness.TypeClaudeCode, harness.TypeOpenCode:
+		return nil, fmt.Errorf("agent command %q: name is taken by a built-in harness", spec.Name)
+	}
+	if strings.TrimSpace(spec.Command) == "" {
+		return nil, fmt.Errorf("agent command %q: command is empty", spec.Name)
+	}
+	h := &Harness{spec: spec}
+	var err error
+	if h.input, err = compile(spec.InputPatterns); err != nil {
+		return nil, fmt.Errorf("agent command %q: input pattern: %w", spec.Name, err)
+	}
+	if h.permission, err = compile(spec.PermissionPatterns); err != nil {
+		return nil, fmt.Errorf("agent command %q: permission pattern: %w", spec.Name, err)
+	}
+	return h, nil
+}
+
+func compile(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func (h *Harness) Type() harness.Type {
+	return harness.Type(h.spec.Name)
+}
+
+// Setup does nothing: the tool needs no hooks or plugins.
+func (h *Harness) Setup(worktreePath string, opts harness.SetupOptions) error {
+	return nil
+}
+
+func (h *Harness) Command(opts harness.Options) []string {
+	cmd := append([]string{h.spec.Command}, h.spec.Args...)
+	if opts.Prompt != "" {
+		if h.spec.PromptFlag != "" {
+			cmd = append(cmd, h.spec.PromptFlag)
+		}
+		cmd = append(cmd, opts.Prompt)
+	}
+	return cmd
+}
+
+func (h *Harness) ReadStatus(worktreePath string) (*harness.StatusFile, error) {
+	return nil, nil
+}
+
+func (h *Harness) ReadMetrics(worktreePath string) (*harness.MetricsData, error) {
+	return nil, nil
+}
+
+func (h *Harness) StalenessThreshold() time.Duration {
+	return 0
+}
+
+// ClassifyPane reports what the agent waits for from the bottom lines of
+// its pane: "permission", "input", or "" while it works. Permission
+// patterns are checked first, as a permission prompt usually sits above an
+// input line.
+func (h *Harness) ClassifyPane(lines []string) string {
+	for _, line := range lines {
+		for _, re := range h.permission {
+			if re.MatchString(line) {
+				return "permission"
+			}
+		}
+	}
+	for _, line := range lines {
+		for _, re := range h.input {
+			if re.MatchString(line) {
+				return "input"
+			}
+		}
+	}
+	return ""
+}
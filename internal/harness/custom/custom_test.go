@@ -0,0 +1,64 @@
+package custom
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/harness"
+)
+
+func TestNew_Validates(t *testing.T) {
+	for _, spec := range []Spec{
+		{Command: "aider"},
+		{Name: "claude", Command: "aider"},
+		{Name: "aider"},
+		{Name: "aider", Command: "aider", InputPatterns: []string{"("}},
+	} {
+		if _, err := New(spec); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	h, err := New(Spec{Name: "aider", Command: "aider", Args: []string{"--no-auto-commits"}, PromptFlag: "--message"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.Command(harness.Options{}), []string{"aider", "--no-auto-commits"}; !slices.Equal(got, want) {
+		t.Errorf("Command() = %q, want %q", got, want)
+	}
+	got := h.Command(harness.Options{Prompt: "fix it"})
+	if want := []string{"aider", "--no-auto-commits", "--message", "fix it"}; !slices.Equal(got, want) {
+		t.Errorf("Command(prompt) = %q, want %q", got, want)
+	}
+
+	h, _ = New(Spec{Name: "codex", Command: "codex"})
+	if got, want := h.Command(harness.Options{Prompt: "fix it"}), []string{"codex", "fix it"}; !slices.Equal(got, want) {
+		t.Errorf("Command(prompt) without flag = %q, want %q", got, want)
+	}
+}
+
+func TestClassifyPane(t *testing.T) {
+	h, err := New(Spec{
+		Name:               "aider",
+		Command:            "aider",
+		InputPatterns:      []string{`^> $`},
+		PermissionPatterns: []string{`\(Y\)es/\(N\)o`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		lines []string
+		want  string
+	}{
+		{[]string{"Editing main.go…"}, ""},
+		{[]string{"Done.", "> "}, "input"},
+		{[]string{"Create new file? (Y)es/(N)o [Yes]:", "> "}, "permission"},
+	} {
+		if got := h.ClassifyPane(tc.lines); got != tc.want {
+			t.Errorf("ClassifyPane(%q) = %q, want %q", tc.lines, got, tc.want)
+		}
+	}
+}
@@ -34,6 +34,8 @@ type MetricsData struct {
 	LinesAdded   int     `json:"lines_added"`
 	LinesRemoved int     `json:"lines_removed"`
 	SessionID    string  `json:"session_id"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
 }
 
 // SetupOptions configure harness setup behavior.
@@ -49,6 +51,14 @@ type SetupOptions struct {
 // Options passed when launching the harness command.
 type Options struct {
 	SkipPermissions bool
+	// StreamJSON runs the assistant non-interactively with streaming JSON
+	// output relayed through `mastermind stream-relay` (Claude Code only).
+	StreamJSON bool
+	// Prompt is an initial task handed to the assistant on launch.
+	Prompt string
+	// Context is background appended to the assistant's system prompt,
+	// e.g. the ticket the work is for (Claude Code only).
+	Context string
 	// Future: model selection, resume session, etc.
 }
 
@@ -80,3 +90,12 @@ type Harness interface {
 	// StalenessThreshold returns how old status data can be before falling back to tmux polling.
 	StalenessThreshold() time.Duration
 }
+
+// PaneClassifier is implemented by harnesses whose tools write no status
+// files, so what the agent is doing is read from its pane.
+type PaneClassifier interface {
+	// ClassifyPane returns "permission" or "input" when the bottom lines
+	// of the pane show the agent waiting on the user, and "" while it
+	// works.
+	ClassifyPane(lines []string) string
+}
@@ -41,6 +41,9 @@ func (h *Harness) Setup(worktreePath string, opts harness.SetupOptions) error {
 func (h *Harness) Command(opts harness.Options) []string {
 	// OpenCode doesn't have a direct --skip-permissions equivalent
 	// Permissions are configured via opencode.json
+	if opts.Prompt != "" {
+		return []string{"opencode", "--prompt", opts.Prompt}
+	}
 	return []string{"opencode"}
 }
 
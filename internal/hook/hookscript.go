@@ -1,7 +1,6 @@
 package hook
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,8 +14,16 @@ set -e
 # Read hook event JSON from stdin
 INPUT=$(cat)
 
-# Extract hook event name from CLAUDE_HOOK_EVENT_NAME env var
-EVENT="$CLAUDE_HOOK_EVENT_NAME"
+# Any event shows Claude is alive, even ones that don't change the status
+touch "${CLAUDE_WORKING_DIRECTORY:-.}/.mastermind-heartbeat" 2>/dev/null || true
+
+# field prints the first string value of the named key in the payload
+field() {
+  echo "$INPUT" | grep -o "\"$1\"[[:space:]]*:[[:space:]]*\"[^\"]*\"" | head -1 | sed "s/.*\"$1\"[[:space:]]*:[[:space:]]*\"\([^\"]*\)\".*/\1/" | tr -d '\\'
+}
+
+# Hook event name from CLAUDE_HOOK_EVENT_NAME, or the payload
+EVENT="${CLAUDE_HOOK_EVENT_NAME:-$(field hook_event_name)}"
 
 # Determine status based on hook event
 STATUS=""
@@ -26,7 +33,7 @@ case "$EVENT" in
     ;;
   Notification)
     # Check the notification type from the JSON payload
-    TYPE=$(echo "$INPUT" | grep -o '"type"[[:space:]]*:[[:space:]]*"[^"]*"' | head -1 | sed 's/.*"type"[[:space:]]*:[[:space:]]*"\([^"]*\)".*/\1/')
+    TYPE=$(field type)
     case "$TYPE" in
       permission_prompt)
         STATUS="waiting_permission"
@@ -56,11 +63,19 @@ if [ -z "$STATUS" ]; then
   exit 0
 fi
 
+SESSION=$(field session_id)
+TOOL=$(field tool_name)
+ERROR=false
+if echo "$INPUT" | grep -q '"is_error"[[:space:]]*:[[:space:]]*true'; then
+  ERROR=true
+fi
+
 # Write status file atomically to the working directory
 TS=$(date +%s)
 STATUS_FILE="${CLAUDE_WORKING_DIRECTORY:-.}/.mastermind-status"
 TMP_FILE=$(mktemp "${STATUS_FILE}.XXXXXX")
-printf '{"status":"%s","ts":%s}\n' "$STATUS" "$TS" > "$TMP_FILE"
+printf '{"v":2,"status":"%s","ts":%s,"event":"%s","session_id":"%s","tool":"%s","error":%s}\n' \
+  "$STATUS" "$TS" "$EVENT" "$SESSION" "$TOOL" "$ERROR" > "$TMP_FILE"
 mv "$TMP_FILE" "$STATUS_FILE"
 `
 
@@ -85,7 +100,7 @@ echo "$TODOS" > "$TMP_FILE"
 mv "$TMP_FILE" "$TODOS_FILE"
 `
 
-// settingsJSONMap is the .claude/settings.local.json content that registers hooks.
+// settingsJSONMap is merged into .claude/settings.local.json to register hooks.
 var settingsJSONMap = map[string]interface{}{
 	"hooks": map[string]interface{}{
 		"PreToolUse": []map[string]interface{}{
@@ -127,17 +142,6 @@ var settingsJSONMap = map[string]interface{}{
 	},
 }
 
-// settingsJSONBytes is the pre-computed JSON encoding of settingsJSONMap.
-var settingsJSONBytes []byte
-
-func init() {
-	var err error
-	settingsJSONBytes, err = json.MarshalIndent(settingsJSONMap, "", "  ")
-	if err != nil {
-		panic("hook: failed to marshal settings JSON: " + err.Error())
-	}
-}
-
 // WriteHookFiles writes the hook script and settings.local.json into the
 // worktree so that Claude Code instances spawned there report status via hooks.
 func WriteHookFiles(worktreePath string) error {
@@ -157,11 +161,9 @@ func WriteHookFiles(worktreePath string) error {
 		return fmt.Errorf("write todos hook script: %w", err)
 	}
 
-	// Write settings.local.json (uses pre-computed bytes)
-	claudeDir := filepath.Join(worktreePath, ".claude")
-	settingsPath := filepath.Join(claudeDir, "settings.local.json")
-
-	if err := os.WriteFile(settingsPath, settingsJSONBytes, 0o644); err != nil {
+	// Register the hooks next to any in the project's settings.local.json
+	settingsPath := filepath.Join(worktreePath, ".claude", "settings.local.json")
+	if err := MergeSettings(settingsPath, settingsJSONMap); err != nil {
 		return fmt.Errorf("write settings: %w", err)
 	}
 
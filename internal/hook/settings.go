@@ -0,0 +1,129 @@
+package hook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// settingsBackupSuffix marks the copy of a project's own Claude Code
+// settings file taken before mastermind first merged its keys into it.
+// An empty copy means the project had no such file.
+const settingsBackupSuffix = ".mastermind-orig"
+
+// settingsFiles are the worktree's Claude Code settings files mastermind
+// merges its keys into. Only the untracked settings.local.json is written
+// now; settings.json is still restored for worktrees set up before.
+var settingsFiles = []string{
+	filepath.Join(".claude", "settings.json"),
+	filepath.Join(".claude", "settings.local.json"),
+}
+
+// MergeSettings merges settings into the JSON object in the Claude Code
+// settings file at path, creating it if needed. Objects are merged key by
+// key, arrays gain the elements they don't have yet (so the project's own
+// hooks keep running next to mastermind's) and other values are replaced.
+// The first merge keeps the original next to the file for RestoreSettings.
+func MergeSettings(path string, settings map[string]interface{}) error {
+	orig, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	merged := map[string]interface{}{}
+	if len(orig) > 0 {
+		if err := json.Unmarshal(orig, &merged); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	// Round-trip mastermind's settings so they compare equal to values
+	// decoded from the file.
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	var add map[string]interface{}
+	if err := json.Unmarshal(data, &add); err != nil {
+		return err
+	}
+	mergeJSON(merged, add)
+
+	if data, err = json.MarshalIndent(merged, "", "  "); err != nil {
+		return err
+	}
+	backup := path + settingsBackupSuffix
+	if _, err := os.Stat(backup); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(backup, orig, 0o644); err != nil {
+			return fmt.Errorf("back up %s: %w", path, err)
+		}
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// mergeJSON merges src into dst as described for MergeSettings.
+func mergeJSON(dst, src map[string]interface{}) {
+	for k, v := range src {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if d, ok := dst[k].(map[string]interface{}); ok {
+				mergeJSON(d, v)
+				continue
+			}
+		case []interface{}:
+			if d, ok := dst[k].([]interface{}); ok {
+				for _, e := range v {
+					if !containsJSON(d, e) {
+						d = append(d, e)
+					}
+				}
+				dst[k] = d
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+func containsJSON(list []interface{}, v interface{}) bool {
+	for _, e := range list {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// RestoreSettings puts the worktree's Claude Code settings files that
+// MergeSettings changed back the way the project had them, removing the
+// ones it created.
+func RestoreSettings(worktreePath string) error {
+	var errs []error
+	for _, name := range settingsFiles {
+		if err := restoreSettingsFile(filepath.Join(worktreePath, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func restoreSettingsFile(path string) error {
+	backup := path + settingsBackupSuffix
+	orig, err := os.ReadFile(backup)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(orig) == 0 {
+		err = os.Remove(path)
+	} else {
+		err = os.WriteFile(path, orig, 0o644)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("restore %s: %w", path, err)
+	}
+	return os.Remove(backup)
+}
@@ -0,0 +1,71 @@
+package hook
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeSettings_KeepsProjectSettings(t *testing.T) {
+	dir := t.TempDir()
+	claudeDir := filepath.Join(dir, ".claude")
+	if err := os.MkdirAll(claudeDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(claudeDir, "settings.local.json")
+	orig := `{"permissions":{"allow":["Bash(make:*)"]},"hooks":{"Stop":[{"hooks":[{"type":"command","command":"./notify.sh"}]}]}}`
+	if err := os.WriteFile(path, []byte(orig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setting up twice, e.g. on resume, must not add mastermind's hooks twice.
+	for i := 0; i < 2; i++ {
+		if err := WriteHookFiles(dir); err != nil {
+			t.Fatalf("WriteHookFiles: %v", err)
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	var settings struct {
+		Permissions struct {
+			Allow []string `json:"allow"`
+		} `json:"permissions"`
+		Hooks map[string][]json.RawMessage `json:"hooks"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("invalid JSON in settings: %v", err)
+	}
+	if len(settings.Permissions.Allow) != 1 {
+		t.Errorf("permissions.allow = %v, want the project's", settings.Permissions.Allow)
+	}
+	if n := len(settings.Hooks["Stop"]); n != 2 {
+		t.Errorf("Stop has %d hook groups, want the project's and mastermind's", n)
+	}
+	if n := len(settings.Hooks["PostToolUse"]); n != 2 {
+		t.Errorf("PostToolUse has %d hook groups, want 2", n)
+	}
+
+	if err := RestoreSettings(dir); err != nil {
+		t.Fatalf("RestoreSettings: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != orig {
+		t.Errorf("restored settings = %s, want %s", data, orig)
+	}
+	if _, err := os.Stat(path + settingsBackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("backup left behind: %v", err)
+	}
+}
+
+func TestRestoreSettings_RemovesCreatedFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteHookFiles(dir); err != nil {
+		t.Fatalf("WriteHookFiles: %v", err)
+	}
+	if err := RestoreSettings(dir); err != nil {
+		t.Fatalf("RestoreSettings: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".claude", "settings.local.json")); !os.IsNotExist(err) {
+		t.Errorf("settings.local.json should be removed, stat err = %v", err)
+	}
+}
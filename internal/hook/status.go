@@ -15,8 +15,16 @@ const (
 	StatusIdle              = "idle"
 	StatusStopped           = "stopped"
 
-	// statusFileName is written by the hook script into the worktree root.
-	statusFileName = ".mastermind-status"
+	// ProtocolVersion is the version of the status file the hook script
+	// writes. Version 1 files (from older scripts and the OpenCode plugin)
+	// only hold the status and timestamp.
+	ProtocolVersion = 2
+
+	// StatusFileName is written by the hook script into the worktree root.
+	StatusFileName = ".mastermind-status"
+
+	// HeartbeatFileName is touched by the hook script on every hook event.
+	HeartbeatFileName = ".mastermind-heartbeat"
 
 	// StalenessThreshold is how old a status file can be before we consider
 	// it stale and fall back to tmux polling.
@@ -25,8 +33,19 @@ const (
 
 // StatusFile represents the JSON written by the hook script.
 type StatusFile struct {
+	Version   int    `json:"v,omitempty"`
 	Status    string `json:"status"`
 	Timestamp int64  `json:"ts"`
+	// Event is the Claude Code hook event that wrote the file, e.g.
+	// "PreToolUse" or "Stop".
+	Event string `json:"event,omitempty"`
+	// SessionID identifies the Claude session the event came from. With
+	// agent teams, teammates share the lead's worktree and status file.
+	SessionID string `json:"session_id,omitempty"`
+	// Tool names the tool a PreToolUse or PostToolUse event is about.
+	Tool string `json:"tool,omitempty"`
+	// Error is set when the tool call failed.
+	Error bool `json:"error,omitempty"`
 }
 
 // IsStale returns true if the status file timestamp is older than the threshold.
@@ -37,7 +56,7 @@ func (sf *StatusFile) IsStale() bool {
 // ReadStatus reads and parses the .mastermind-status file from the given worktree path.
 // Returns nil, nil if the file does not exist.
 func ReadStatus(worktreePath string) (*StatusFile, error) {
-	path := filepath.Join(worktreePath, statusFileName)
+	path := filepath.Join(worktreePath, StatusFileName)
 	return readStatusFile(path)
 }
 
@@ -57,3 +76,13 @@ func readStatusFile(path string) (*StatusFile, error) {
 
 	return &sf, nil
 }
+
+// ReadHeartbeat returns when the hook script last touched the heartbeat
+// file in the given worktree, or the zero time if it never did.
+func ReadHeartbeat(worktreePath string) time.Time {
+	info, err := os.Stat(filepath.Join(worktreePath, HeartbeatFileName))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
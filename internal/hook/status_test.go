@@ -24,7 +24,7 @@ func TestReadStatus(t *testing.T) {
 	t.Run("valid status file", func(t *testing.T) {
 		ts := time.Now().Unix()
 		data, _ := json.Marshal(StatusFile{Status: StatusRunning, Timestamp: ts})
-		if err := os.WriteFile(filepath.Join(dir, statusFileName), data, 0o644); err != nil {
+		if err := os.WriteFile(filepath.Join(dir, StatusFileName), data, 0o644); err != nil {
 			t.Fatal(err)
 		}
 
@@ -43,8 +43,24 @@ func TestReadStatus(t *testing.T) {
 		}
 	})
 
+	t.Run("protocol v2 fields", func(t *testing.T) {
+		data := `{"v":2,"status":"running","ts":1700000000,"event":"PostToolUse","session_id":"abc","tool":"Bash","error":true}`
+		if err := os.WriteFile(filepath.Join(dir, StatusFileName), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		sf, err := ReadStatus(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := StatusFile{Version: 2, Status: StatusRunning, Timestamp: 1700000000, Event: "PostToolUse", SessionID: "abc", Tool: "Bash", Error: true}
+		if sf == nil || *sf != want {
+			t.Errorf("got %+v, want %+v", sf, want)
+		}
+	})
+
 	t.Run("invalid JSON", func(t *testing.T) {
-		if err := os.WriteFile(filepath.Join(dir, statusFileName), []byte("not json"), 0o644); err != nil {
+		if err := os.WriteFile(filepath.Join(dir, StatusFileName), []byte("not json"), 0o644); err != nil {
 			t.Fatal(err)
 		}
 
@@ -0,0 +1,181 @@
+// Package instance registers running mastermind TUIs in a runtime
+// directory shared by all of a user's instances, so an instance, or
+// `mastermind switch`, can list the others and focus one of them. Each
+// instance holds a lock on its registration file, so one that crashed is
+// never listed.
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/tmux"
+)
+
+// Instance describes a running mastermind TUI.
+type Instance struct {
+	PID       int       `json:"pid"`
+	Name      string    `json:"name,omitempty"` // [instance] name
+	Repo      string    `json:"repo"`
+	Session   string    `json:"session"`
+	PaneID    string    `json:"pane_id"`
+	WindowID  string    `json:"window_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Label is the instance's name, or the name of its repository if it has
+// none.
+func (i Instance) Label() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	return filepath.Base(i.Repo)
+}
+
+// Dir returns the directory instances register in: mastermind/instances
+// in $XDG_RUNTIME_DIR, or in a per-user directory under the system's
+// temporary directory.
+func Dir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "mastermind", "instances")
+	}
+	return filepath.Join(os.TempDir(), "mastermind-"+strconv.Itoa(os.Getuid()), "instances")
+}
+
+// Registration is a running instance's hold on its registration file.
+type Registration struct {
+	f *os.File
+}
+
+// Register records inst in Dir and locks its file until Remove is called
+// or the process exits.
+func Register(inst Instance) (*Registration, error) {
+	dir := Dir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create instance dir: %w", err)
+	}
+	data, err := json.Marshal(inst)
+	if err != nil {
+		return nil, fmt.Errorf("encode instance: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, strconv.Itoa(inst.PID)+".json"), os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open instance file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock instance file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write instance file: %w", err)
+	}
+	return &Registration{f: f}, nil
+}
+
+// Remove deletes the registration and drops its lock.
+func (r *Registration) Remove() {
+	os.Remove(r.f.Name())
+	r.f.Close()
+}
+
+// List returns the running instances, ordered by label. Registrations
+// left behind by instances that are gone are deleted.
+func List() ([]Instance, error) {
+	entries, err := os.ReadDir(Dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read instance dir: %w", err)
+	}
+	var instances []Instance
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		inst, ok := read(filepath.Join(Dir(), e.Name()))
+		if ok {
+			instances = append(instances, inst)
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].Label() != instances[j].Label() {
+			return instances[i].Label() < instances[j].Label()
+		}
+		return instances[i].PID < instances[j].PID
+	})
+	return instances, nil
+}
+
+// read returns the instance registered in path if it is still running,
+// and deletes the registration if it isn't.
+func read(path string) (Instance, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Instance{}, false
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err == nil {
+		os.Remove(path)
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		return Instance{}, false
+	}
+	var inst Instance
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &inst) != nil {
+		return Instance{}, false
+	}
+	return inst, true
+}
+
+// Find returns the running instance whose name, repository name or
+// repository path is query.
+func Find(query string) (Instance, error) {
+	instances, err := List()
+	if err != nil {
+		return Instance{}, err
+	}
+	var found []Instance
+	for _, inst := range instances {
+		if inst.Name == query || filepath.Base(inst.Repo) == query || inst.Repo == query {
+			found = append(found, inst)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Instance{}, fmt.Errorf("no running instance matches %q", query)
+	case 1:
+		return found[0], nil
+	}
+	return Instance{}, fmt.Errorf("%d running instances match %q; give them names with [instance] name", len(found), query)
+}
+
+// ErrNoPane is returned by Focus for an instance that wasn't started in
+// a tmux pane.
+var ErrNoPane = errors.New("instance has no tmux pane")
+
+// Focus switches the tmux client to the instance's session and selects
+// its window and pane.
+func Focus(inst Instance) error {
+	if inst.PaneID == "" {
+		return ErrNoPane
+	}
+	if err := tmux.SwitchClient(inst.PaneID); err != nil {
+		return err
+	}
+	if inst.WindowID != "" {
+		if err := tmux.SelectWindow(inst.WindowID); err != nil {
+			return err
+		}
+	}
+	return tmux.SelectPane(inst.PaneID)
+}
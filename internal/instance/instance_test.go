@@ -0,0 +1,57 @@
+package instance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegisterListFind(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	api, err := Register(Instance{PID: 101, Name: "api", Repo: "/src/backend", PaneID: "%1"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	web, err := Register(Instance{PID: 102, Repo: "/src/web", PaneID: "%2"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer web.Remove()
+
+	// A registration nobody holds a lock on was left by a crashed instance.
+	stale := filepath.Join(Dir(), "103.json")
+	if err := os.WriteFile(stale, []byte(`{"pid":103,"repo":"/src/gone"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	instances, err := List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var labels []string
+	for _, inst := range instances {
+		labels = append(labels, inst.Label())
+	}
+	if got := strings.Join(labels, ","); got != "api,web" {
+		t.Errorf("labels = %s, want api,web", got)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale registration kept: %v", err)
+	}
+
+	for _, query := range []string{"api", "backend", "/src/backend"} {
+		if inst, err := Find(query); err != nil || inst.PID != 101 {
+			t.Errorf("Find(%q) = %+v, %v; want the api instance", query, inst, err)
+		}
+	}
+	if _, err := Find("gone"); err == nil {
+		t.Error("Find(gone) should fail")
+	}
+
+	api.Remove()
+	if instances, _ := List(); len(instances) != 1 || instances[0].PID != 102 {
+		t.Errorf("List after Remove = %+v, want only web", instances)
+	}
+}
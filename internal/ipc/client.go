@@ -0,0 +1,166 @@
+package ipc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// ErrUnavailable is returned when no daemon is listening on the socket.
+var ErrUnavailable = errors.New("daemon not reachable")
+
+// Client talks to a daemon. Each call opens its own connection, so a
+// client keeps working across daemon restarts and is safe for concurrent
+// use.
+type Client struct {
+	path string
+}
+
+// NewClient returns a client for the socket at path.
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+func (c *Client) dial() (net.Conn, error) {
+	nc, err := net.Dial("unix", c.path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nc, nil
+}
+
+// Call sends one request and decodes the result into result, which may be
+// nil. An error reported by the daemon is returned as an error.
+func (c *Client) Call(op string, params, result any) error {
+	nc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+
+	req := Request{V: Version, ID: 1, Op: op}
+	if params != nil {
+		if req.Params, err = json.Marshal(params); err != nil {
+			return fmt.Errorf("marshal %s params: %w", op, err)
+		}
+	}
+	if err := json.NewEncoder(nc).Encode(req); err != nil {
+		return fmt.Errorf("send %s: %w", op, err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(nc).Decode(&resp); err != nil {
+		return fmt.Errorf("read %s response: %w", op, err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("decode %s result: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// Hello checks the daemon is reachable and returns its version.
+func (c *Client) Hello() (HelloResult, error) {
+	var res HelloResult
+	err := c.Call(OpHello, nil, &res)
+	return res, err
+}
+
+// Spawn asks the daemon to spawn an agent.
+func (c *Client) Spawn(p SpawnParams) error {
+	return c.Call(OpSpawn, p, nil)
+}
+
+// Merge asks the daemon to merge an agent's branch.
+func (c *Client) Merge(p MergeParams) (MergeResult, error) {
+	var res MergeResult
+	err := c.Call(OpMerge, p, &res)
+	return res, err
+}
+
+// Dismiss asks the daemon to dismiss an agent.
+func (c *Client) Dismiss(p DismissParams) error {
+	return c.Call(OpDismiss, p, nil)
+}
+
+// PullRequest asks the daemon to open a pull request from an agent's
+// branch.
+func (c *Client) PullRequest(p PullRequestParams) (PullRequestResult, error) {
+	var res PullRequestResult
+	err := c.Call(OpPullRequest, p, &res)
+	return res, err
+}
+
+// Clone asks the daemon to spawn a new agent from an existing one.
+func (c *Client) Clone(p CloneParams) error {
+	return c.Call(OpClone, p, nil)
+}
+
+// AllowPush asks the daemon to allow or block pushes from an agent's
+// worktree.
+func (c *Client) AllowPush(p AllowPushParams) error {
+	return c.Call(OpAllowPush, p, nil)
+}
+
+// Subscribe streams the daemon's monitor events until ctx is cancelled or
+// the connection drops, when the channel is closed.
+func (c *Client) Subscribe(ctx context.Context) (<-chan monitor.Event, error) {
+	nc, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	if err := json.NewEncoder(nc).Encode(Request{V: Version, ID: 1, Op: OpSubscribe}); err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("send subscribe: %w", err)
+	}
+	dec := json.NewDecoder(bufio.NewReader(nc))
+	var ack Response
+	if err := dec.Decode(&ack); err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("read subscribe response: %w", err)
+	}
+	if ack.Error != "" {
+		nc.Close()
+		return nil, errors.New(ack.Error)
+	}
+
+	events := make(chan monitor.Event, 64)
+	go func() {
+		<-ctx.Done()
+		nc.Close()
+	}()
+	go func() {
+		defer close(events)
+		defer nc.Close()
+		for {
+			var resp Response
+			if err := dec.Decode(&resp); err != nil {
+				return
+			}
+			if resp.Event == nil {
+				continue
+			}
+			ev, err := monitor.UnmarshalEvent(resp.Event.Type, resp.Event.Data)
+			if err != nil {
+				slog.Warn("ipc event dropped", "error", err)
+				continue
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
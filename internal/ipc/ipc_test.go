@@ -0,0 +1,207 @@
+package ipc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+type fakeBackend struct {
+	mu       sync.Mutex
+	spawned  []SpawnParams
+	cloned   []CloneParams
+	pushes   []AllowPushParams
+	dismiss  error
+	bus      *monitor.Bus
+	subbed   chan struct{}
+	mergeRes MergeResult
+}
+
+func (b *fakeBackend) Repo() string { return "/repo" }
+
+func (b *fakeBackend) Agents() []agent.PersistedAgent {
+	return []agent.PersistedAgent{{ID: "a1", Branch: "feat/x"}}
+}
+
+func (b *fakeBackend) Spawn(p SpawnParams) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spawned = append(b.spawned, p)
+	return nil
+}
+
+func (b *fakeBackend) Merge(p MergeParams) MergeResult { return b.mergeRes }
+
+func (b *fakeBackend) Dismiss(p DismissParams) error { return b.dismiss }
+
+func (b *fakeBackend) PullRequest(p PullRequestParams) (PullRequestResult, error) {
+	return PullRequestResult{Number: 5, URL: "https://forge.test/pr/5"}, nil
+}
+
+func (b *fakeBackend) Clone(p CloneParams) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cloned = append(b.cloned, p)
+	return nil
+}
+
+func (b *fakeBackend) AllowPush(p AllowPushParams) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pushes = append(b.pushes, p)
+	return nil
+}
+
+func (b *fakeBackend) Subscribe(buffer int) (<-chan monitor.Event, func()) {
+	ch := b.bus.Subscribe(buffer)
+	b.subbed <- struct{}{}
+	return ch, func() { b.bus.Unsubscribe(ch) }
+}
+
+// startServer serves b on a socket in a temp dir and returns a client.
+func startServer(t *testing.T, b *fakeBackend) *Client {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "s.sock")
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go NewServer(b).Serve(ctx, ln)
+	return NewClient(path)
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{bus: monitor.NewBus(), subbed: make(chan struct{}, 4)}
+}
+
+func TestClient_HelloAndAgents(t *testing.T) {
+	c := startServer(t, newFakeBackend())
+
+	hello, err := c.Hello()
+	if err != nil {
+		t.Fatalf("Hello: %v", err)
+	}
+	if hello.Version != Version || hello.Repo != "/repo" {
+		t.Errorf("hello = %+v", hello)
+	}
+
+	var res AgentsResult
+	if err := c.Call(OpAgents, nil, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Agents) != 1 || res.Agents[0].ID != "a1" {
+		t.Errorf("agents = %+v", res.Agents)
+	}
+}
+
+func TestClient_SpawnMergeDismiss(t *testing.T) {
+	b := newFakeBackend()
+	b.mergeRes = MergeResult{Conflict: true, ConflictFiles: []string{"a.go"}}
+	b.dismiss = fmt.Errorf("agent a9 not found")
+	c := startServer(t, b)
+
+	if err := c.Spawn(SpawnParams{Branch: "feat/y", BaseBranch: "main", CreateBranch: true, Session: "noise"}); err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	if len(b.spawned) != 1 || b.spawned[0].Branch != "feat/y" || b.spawned[0].Session != "noise" {
+		t.Errorf("spawned = %+v", b.spawned)
+	}
+
+	res, err := c.Merge(MergeParams{ID: "a1"})
+	if err != nil || !res.Conflict || len(res.ConflictFiles) != 1 {
+		t.Errorf("Merge = %+v, %v", res, err)
+	}
+
+	if err := c.Dismiss(DismissParams{ID: "a9"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Dismiss err = %v, want the backend's error", err)
+	}
+
+	if pr, err := c.PullRequest(PullRequestParams{ID: "a1"}); err != nil || pr.Number != 5 {
+		t.Errorf("PullRequest = %+v, %v", pr, err)
+	}
+
+	if err := c.Clone(CloneParams{ID: "a1", Branch: "feat/x-alt", Fresh: true}); err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if len(b.cloned) != 1 || b.cloned[0].Branch != "feat/x-alt" || !b.cloned[0].Fresh {
+		t.Errorf("cloned = %+v", b.cloned)
+	}
+
+	if err := c.AllowPush(AllowPushParams{ID: "a1", Allow: true}); err != nil {
+		t.Fatalf("AllowPush: %v", err)
+	}
+	if len(b.pushes) != 1 || !b.pushes[0].Allow {
+		t.Errorf("pushes = %+v", b.pushes)
+	}
+}
+
+func TestServer_RejectsNewerProtocol(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s.sock")
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewServer(newFakeBackend()).Serve(ctx, ln)
+
+	nc, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	json.NewEncoder(nc).Encode(Request{V: Version + 1, ID: 7, Op: OpHello})
+	var resp Response
+	if err := json.NewDecoder(nc).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != 7 || !strings.Contains(resp.Error, "unsupported protocol version") {
+		t.Errorf("response = %+v, want a version error for request 7", resp)
+	}
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	b := newFakeBackend()
+	c := startServer(t, b)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := c.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	<-b.subbed
+	b.bus.Publish(monitor.AgentWaiting{AgentID: "a1", WaitingFor: "permission"})
+
+	select {
+	case ev := <-events:
+		if ev != (monitor.AgentWaiting{AgentID: "a1", WaitingFor: "permission"}) {
+			t.Errorf("event = %#v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestClient_UnavailableWithoutDaemon(t *testing.T) {
+	c := NewClient(filepath.Join(t.TempDir(), "missing.sock"))
+	if _, err := c.Hello(); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("err = %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Package ipc is the protocol frontends use to talk to a mastermind
+// daemon over a unix socket. Messages are newline-delimited JSON objects
+// carrying a protocol version. A client sends requests; the server answers
+// each with a response echoing the request ID. A subscribe request is
+// answered once and then followed by event messages on the same
+// connection until the client disconnects.
+package ipc
+
+import (
+	"encoding/json"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// Version is the protocol version spoken by this build. The server rejects
+// requests from newer clients; requests without a version are treated as
+// version 1.
+const Version = 1
+
+// Operations a client can request.
+const (
+	OpHello       = "hello"
+	OpAgents      = "agents"
+	OpSpawn       = "spawn"
+	OpMerge       = "merge"
+	OpDismiss     = "dismiss"
+	OpPullRequest = "pull_request"
+	OpClone       = "clone"
+	OpAllowPush   = "allow_push"
+	OpSubscribe   = "subscribe"
+)
+
+// Request is a client message.
+type Request struct {
+	V      int             `json:"v"`
+	ID     int64           `json:"id"`
+	Op     string          `json:"op"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a server message: either the answer to a request, or an
+// event for a subscription, in which case ID is the subscribe request's.
+type Response struct {
+	V      int             `json:"v"`
+	ID     int64           `json:"id"`
+	Error  string          `json:"error,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Event  *Event          `json:"event,omitempty"`
+}
+
+// Event is a monitor event as sent to subscribers.
+type Event struct {
+	Type  string          `json:"type"`
+	Agent string          `json:"agent"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// HelloResult answers OpHello.
+type HelloResult struct {
+	Version int    `json:"version"`
+	Repo    string `json:"repo"`
+}
+
+// AgentsResult answers OpAgents.
+type AgentsResult struct {
+	Agents []agent.PersistedAgent `json:"agents"`
+}
+
+// SpawnParams are the parameters of OpSpawn.
+type SpawnParams struct {
+	Branch       string `json:"branch"`
+	BaseBranch   string `json:"base_branch"`
+	CreateBranch bool   `json:"create_branch"`
+	Harness      string `json:"harness,omitempty"`
+	Session      string `json:"session,omitempty"`
+	Ticket       string `json:"ticket,omitempty"`
+	TicketTitle  string `json:"ticket_title,omitempty"`
+	// ReuseWorktree spawns in the branch's existing worktree.
+	ReuseWorktree bool `json:"reuse_worktree,omitempty"`
+	// ReadOnly spawns a research agent that is never merged or pushed.
+	ReadOnly bool `json:"read_only,omitempty"`
+	// Report spawns a read-only agent whose report is collected on finish.
+	Report bool `json:"report,omitempty"`
+	// Playbook is the path of a playbook file the agent runs.
+	Playbook string `json:"playbook,omitempty"`
+	// TeamTask spawns the lead of an agent team working on the task.
+	TeamTask string `json:"team_task,omitempty"`
+	// Task is typed into the agent's pane once it is ready for input.
+	Task string `json:"task,omitempty"`
+	// FetchBase fast-forwards the base branch from origin first; nil uses
+	// the configured default.
+	FetchBase *bool `json:"fetch_base,omitempty"`
+}
+
+// MergeParams are the parameters of OpMerge.
+type MergeParams struct {
+	ID             string `json:"id"`
+	DeleteBranch   bool   `json:"delete_branch"`
+	RemoveWorktree bool   `json:"remove_worktree"`
+	Push           bool   `json:"push,omitempty"`     // push base to its upstream after merging
+	Strategy       string `json:"strategy,omitempty"` // "merge", "rebase" or "squash"; empty uses the configured one
+	Message        string `json:"message,omitempty"`  // commit message of a squash merge; empty generates one
+	// Defaults merges with the cleanup options, strategy and push the
+	// merge wizard would start with, in place of the ones above.
+	Defaults bool `json:"defaults,omitempty"`
+}
+
+// MergeResult answers OpMerge. A merge that fails or conflicts is still a
+// successful request; the outcome is described here.
+type MergeResult struct {
+	Success       bool     `json:"success"`
+	Conflict      bool     `json:"conflict"`
+	ConflictFiles []string `json:"conflict_files,omitempty"`
+	ConflictNotes []string `json:"conflict_notes,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	PushError     string   `json:"push_error,omitempty"`
+}
+
+// DismissParams are the parameters of OpDismiss.
+type DismissParams struct {
+	ID           string `json:"id"`
+	DeleteBranch bool   `json:"delete_branch"`
+}
+
+// PullRequestParams are the parameters of OpPullRequest.
+type PullRequestParams struct {
+	ID string `json:"id"`
+}
+
+// CloneParams are the parameters of OpClone.
+type CloneParams struct {
+	ID     string `json:"id"`
+	Branch string `json:"branch"`
+	// Fresh starts the clone from the agent's base branch with its prompt
+	// instead of from a copy of its branch.
+	Fresh bool `json:"fresh,omitempty"`
+}
+
+// AllowPushParams are the parameters of OpAllowPush.
+type AllowPushParams struct {
+	ID    string `json:"id"`
+	Allow bool   `json:"allow"`
+}
+
+// PullRequestResult answers OpPullRequest.
+type PullRequestResult struct {
+	Number    int      `json:"number"`
+	URL       string   `json:"url"`
+	Reviewers []string `json:"reviewers,omitempty"`
+	// Warning describes a step that failed after the pull request was
+	// opened, such as requesting reviewers.
+	Warning string `json:"warning,omitempty"`
+}
@@ -0,0 +1,278 @@
+package ipc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// Backend carries out the operations requested by clients. The daemon's
+// orchestrator provides it.
+type Backend interface {
+	Repo() string
+	Agents() []agent.PersistedAgent
+	Spawn(p SpawnParams) error
+	Merge(p MergeParams) MergeResult
+	Dismiss(p DismissParams) error
+	PullRequest(p PullRequestParams) (PullRequestResult, error)
+	Clone(p CloneParams) error
+	AllowPush(p AllowPushParams) error
+	// Subscribe returns a channel of monitor events and a function that
+	// ends the subscription.
+	Subscribe(buffer int) (<-chan monitor.Event, func())
+}
+
+// Server answers clients connecting to a unix socket. Requests from any
+// number of connections are served concurrently, but operations that
+// change agents run one at a time.
+type Server struct {
+	backend Backend
+	mutate  sync.Mutex
+}
+
+// NewServer returns a server for backend.
+func NewServer(backend Backend) *Server {
+	return &Server{backend: backend}
+}
+
+// Listen creates the socket at path, replacing a stale one left by a
+// daemon that crashed. The caller must hold the daemon lock.
+func Listen(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("remove stale socket: %w", err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("restrict socket permissions: %w", err)
+	}
+	return ln, nil
+}
+
+// Serve accepts connections on ln until ctx is cancelled, then closes ln.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("accept: %w", err)
+		}
+		go s.serveConn(ctx, conn)
+	}
+}
+
+// conn is one client connection. Responses and subscription events are
+// written from different goroutines, so writes are serialized.
+type conn struct {
+	net.Conn
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (c *conn) send(resp Response) error {
+	resp.V = Version
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enc.Encode(resp)
+}
+
+func (s *Server) serveConn(ctx context.Context, nc net.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer nc.Close()
+	go func() {
+		<-ctx.Done()
+		nc.Close()
+	}()
+
+	c := &conn{Conn: nc, enc: json.NewEncoder(nc)}
+	scanner := bufio.NewScanner(nc)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			c.send(Response{Error: fmt.Sprintf("malformed request: %v", err)})
+			continue
+		}
+		if req.V > Version {
+			c.send(Response{ID: req.ID, Error: fmt.Sprintf("unsupported protocol version %d (server speaks %d)", req.V, Version)})
+			continue
+		}
+		if req.Op == OpSubscribe {
+			events, stop := s.backend.Subscribe(64)
+			go func() {
+				<-ctx.Done()
+				stop()
+			}()
+			c.send(Response{ID: req.ID})
+			go s.forwardEvents(c, req.ID, events)
+			continue
+		}
+		resp := s.handle(req)
+		resp.ID = req.ID
+		if err := c.send(resp); err != nil {
+			slog.Debug("ipc write failed", "error", err)
+			return
+		}
+	}
+}
+
+// forwardEvents streams events to a subscriber until the subscription ends.
+func (s *Server) forwardEvents(c *conn, id int64, events <-chan monitor.Event) {
+	for ev := range events {
+		typ, data, err := monitor.MarshalEvent(ev)
+		if err != nil {
+			slog.Warn("ipc event not sent", "error", err)
+			continue
+		}
+		if err := c.send(Response{ID: id, Event: &Event{Type: typ, Agent: ev.AgentRef(), Data: data}}); err != nil {
+			c.Close()
+			return
+		}
+	}
+}
+
+// handle runs a request other than subscribe.
+func (s *Server) handle(req Request) Response {
+	b := s.Backend()
+	switch req.Op {
+	case OpHello:
+		return result(HelloResult{Version: Version, Repo: b.Repo()})
+	case OpAgents:
+		return result(AgentsResult{Agents: b.Agents()})
+	case OpSpawn:
+		var p SpawnParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return Response{Error: fmt.Sprintf("invalid spawn params: %v", err)}
+		}
+		if err := b.Spawn(p); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{}
+	case OpMerge:
+		var p MergeParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return Response{Error: fmt.Sprintf("invalid merge params: %v", err)}
+		}
+		return result(b.Merge(p))
+	case OpDismiss:
+		var p DismissParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return Response{Error: fmt.Sprintf("invalid dismiss params: %v", err)}
+		}
+		if err := b.Dismiss(p); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{}
+	case OpPullRequest:
+		var p PullRequestParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return Response{Error: fmt.Sprintf("invalid pull request params: %v", err)}
+		}
+		res, err := b.PullRequest(p)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return result(res)
+	case OpClone:
+		var p CloneParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return Response{Error: fmt.Sprintf("invalid clone params: %v", err)}
+		}
+		if err := b.Clone(p); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{}
+	case OpAllowPush:
+		var p AllowPushParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return Response{Error: fmt.Sprintf("invalid allow push params: %v", err)}
+		}
+		if err := b.AllowPush(p); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{}
+	}
+	return Response{Error: fmt.Sprintf("unknown op %q", req.Op)}
+}
+
+// Backend returns the server's backend with the operations that change
+// agents run one at a time with those of clients, for frontends that
+// share it in-process, such as Slack.
+func (s *Server) Backend() Backend {
+	return serialized{s}
+}
+
+// serialized is a Backend whose changing operations hold the server's
+// mutate lock.
+type serialized struct {
+	s *Server
+}
+
+func (b serialized) Repo() string                   { return b.s.backend.Repo() }
+func (b serialized) Agents() []agent.PersistedAgent { return b.s.backend.Agents() }
+func (b serialized) Subscribe(buffer int) (<-chan monitor.Event, func()) {
+	return b.s.backend.Subscribe(buffer)
+}
+
+func (b serialized) Spawn(p SpawnParams) error {
+	b.s.mutate.Lock()
+	defer b.s.mutate.Unlock()
+	return b.s.backend.Spawn(p)
+}
+
+func (b serialized) Merge(p MergeParams) MergeResult {
+	b.s.mutate.Lock()
+	defer b.s.mutate.Unlock()
+	return b.s.backend.Merge(p)
+}
+
+func (b serialized) Dismiss(p DismissParams) error {
+	b.s.mutate.Lock()
+	defer b.s.mutate.Unlock()
+	return b.s.backend.Dismiss(p)
+}
+
+func (b serialized) PullRequest(p PullRequestParams) (PullRequestResult, error) {
+	b.s.mutate.Lock()
+	defer b.s.mutate.Unlock()
+	return b.s.backend.PullRequest(p)
+}
+
+func (b serialized) Clone(p CloneParams) error {
+	b.s.mutate.Lock()
+	defer b.s.mutate.Unlock()
+	return b.s.backend.Clone(p)
+}
+
+func (b serialized) AllowPush(p AllowPushParams) error {
+	b.s.mutate.Lock()
+	defer b.s.mutate.Unlock()
+	return b.s.backend.AllowPush(p)
+}
+
+func result(v any) Response {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Response{Error: fmt.Sprintf("marshal result: %v", err)}
+	}
+	return Response{Result: data}
+}
@@ -0,0 +1,397 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// Event is a status transition observed by the monitor. Concrete event
+// types are plain structs so frontends can switch on them directly (the
+// TUI receives them as tea messages).
+type Event interface {
+	// AgentRef returns the ID of the agent the event concerns.
+	AgentRef() string
+}
+
+// AgentFinished is emitted when an agent's process exits or it goes idle.
+type AgentFinished struct {
+	AgentID    string
+	ExitCode   int
+	HasChanges bool
+}
+
+// AgentWaiting is emitted when an agent starts waiting on the user.
+type AgentWaiting struct {
+	AgentID    string
+	WaitingFor string // "permission", "input", or "" (no longer waiting)
+}
+
+// AgentGone is emitted when an agent's pane disappears from tmux.
+type AgentGone struct {
+	AgentID string
+}
+
+// LazygitClosed is emitted when the lazygit pane of a reviewing or
+// conflicted agent exits. Status is the agent's status at that moment.
+type LazygitClosed struct {
+	AgentID string
+	Status  agent.Status
+}
+
+// Attention is emitted when an agent transitions into a state the user
+// should look at.
+type Attention struct {
+	AgentID string
+	Message string
+}
+
+// SessionIDChanged is emitted when an agent's harness reports a new session ID.
+type SessionIDChanged struct {
+	AgentID   string
+	SessionID string
+}
+
+// PullRequestMerged is emitted when the pull request from an agent's
+// branch is merged on the hosting provider. Dismissed reports that the
+// agent was cleaned up automatically, and Kept why it wasn't although it
+// would have been.
+type PullRequestMerged struct {
+	AgentID   string
+	Number    int
+	URL       string
+	Dismissed bool
+	Kept      string
+}
+
+// PlaybookFinished is emitted when the checks of a finished playbook agent
+// have run. Failed names the first failing check and Output holds its
+// output; Merged reports that the agent was merged automatically, and
+// Error why an automatic merge failed.
+type PlaybookFinished struct {
+	AgentID string
+	Branch  string
+	Checks  int
+	Failed  string
+	Output  string
+	Merged  bool
+	Error   string
+}
+
+// AutoCleanup is emitted when a cleanup policy dismissed an agent, or
+// failed to. Rule is the policy that applied: "done" for agents done for
+// too long, "merged" for the daily cleanup of merged branches.
+// DeleteBranch reports that the branch was to be deleted too.
+type AutoCleanup struct {
+	AgentID      string
+	Branch       string
+	Rule         string
+	DeleteBranch bool
+	Error        string
+}
+
+// OrphanProcesses is emitted when processes are still running in an
+// agent's worktree after its window was killed. The worktree, and the
+// branch with DeleteBranch, are kept until the processes are killed.
+type OrphanProcesses struct {
+	AgentID      string
+	Branch       string
+	WorktreePath string
+	DeleteBranch bool
+	Processes    []Process
+}
+
+// Process is a running process, as listed in OrphanProcesses.
+type Process struct {
+	PID     int
+	Command string
+}
+
+func (e AgentFinished) AgentRef() string     { return e.AgentID }
+func (e AgentWaiting) AgentRef() string      { return e.AgentID }
+func (e AgentGone) AgentRef() string         { return e.AgentID }
+func (e LazygitClosed) AgentRef() string     { return e.AgentID }
+func (e Attention) AgentRef() string         { return e.AgentID }
+func (e SessionIDChanged) AgentRef() string  { return e.AgentID }
+func (e PullRequestMerged) AgentRef() string { return e.AgentID }
+func (e PlaybookFinished) AgentRef() string  { return e.AgentID }
+func (e AutoCleanup) AgentRef() string       { return e.AgentID }
+func (e OrphanProcesses) AgentRef() string   { return e.AgentID }
+
+// Event type names used when events are serialized, e.g. in the daemon's
+// event log and the IPC protocol.
+const (
+	TypeFinished      = "finished"
+	TypeWaiting       = "waiting"
+	TypeGone          = "gone"
+	TypeLazygitClosed = "lazygit_closed"
+	TypeAttention     = "attention"
+	TypeSessionID     = "session_id"
+	TypePRMerged      = "pr_merged"
+	TypePlaybook      = "playbook"
+	TypeAutoCleanup   = "auto_cleanup"
+	TypeOrphans       = "orphans"
+)
+
+// MarshalEvent encodes ev as its type name and JSON body.
+func MarshalEvent(ev Event) (string, []byte, error) {
+	var typ string
+	switch ev.(type) {
+	case AgentFinished:
+		typ = TypeFinished
+	case AgentWaiting:
+		typ = TypeWaiting
+	case AgentGone:
+		typ = TypeGone
+	case LazygitClosed:
+		typ = TypeLazygitClosed
+	case Attention:
+		typ = TypeAttention
+	case SessionIDChanged:
+		typ = TypeSessionID
+	case PullRequestMerged:
+		typ = TypePRMerged
+	case PlaybookFinished:
+		typ = TypePlaybook
+	case AutoCleanup:
+		typ = TypeAutoCleanup
+	case OrphanProcesses:
+		typ = TypeOrphans
+	default:
+		return "", nil, fmt.Errorf("unsupported event %T", ev)
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return "", nil, fmt.Errorf("marshal event: %w", err)
+	}
+	return typ, data, nil
+}
+
+// UnmarshalEvent decodes an event encoded by MarshalEvent.
+func UnmarshalEvent(typ string, data []byte) (Event, error) {
+	var ev Event
+	var err error
+	switch typ {
+	case TypeFinished:
+		var e AgentFinished
+		err = json.Unmarshal(data, &e)
+		ev = e
+	case TypeWaiting:
+		var e AgentWaiting
+		err = json.Unmarshal(data, &e)
+		ev = e
+	case TypeGone:
+		var e AgentGone
+		err = json.Unmarshal(data, &e)
+		ev = e
+	case TypeLazygitClosed:
+		var e LazygitClosed
+		err = json.Unmarshal(data, &e)
+		ev = e
+	case TypeAttention:
+		var e Attention
+		err = json.Unmarshal(data, &e)
+		ev = e
+	case TypeSessionID:
+		var e SessionIDChanged
+		err = json.Unmarshal(data, &e)
+		ev = e
+	case TypePRMerged:
+		var e PullRequestMerged
+		err = json.Unmarshal(data, &e)
+		ev = e
+	case TypePlaybook:
+		var e PlaybookFinished
+		err = json.Unmarshal(data, &e)
+		ev = e
+	case TypeAutoCleanup:
+		var e AutoCleanup
+		err = json.Unmarshal(data, &e)
+		ev = e
+	case TypeOrphans:
+		var e OrphanProcesses
+		err = json.Unmarshal(data, &e)
+		ev = e
+	default:
+		return nil, fmt.Errorf("unknown event type %q", typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal %s event: %w", typ, err)
+	}
+	return ev, nil
+}
+
+// Bus fans events out to any number of subscribers. Publishing never
+// blocks. A subscriber from Subscribe whose buffer is full misses the
+// event, which suits best-effort consumers such as web clients; one from
+// SubscribeAll queues it instead, for frontends that must see every
+// lifecycle event.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   []subscriber
+	closed bool
+}
+
+// subscriber is a channel handed out by the bus, with the queue feeding
+// it when it is lossless.
+type subscriber struct {
+	ch chan Event
+	q  *eventQueue // nil drops events when ch is full
+}
+
+// NewBus returns an empty event bus.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe returns a channel receiving every event published after the
+// call that fits in its buffer. The channel is closed when the bus is
+// closed.
+func (b *Bus) Subscribe(buffer int) <-chan Event {
+	return b.subscribe(subscriber{ch: make(chan Event, buffer)})
+}
+
+// SubscribeAll is Subscribe without dropping events: those the reader
+// isn't ready for are queued, however many there are. The channel is
+// closed when the bus is closed and the queue is drained.
+func (b *Bus) SubscribeAll() <-chan Event {
+	sub := subscriber{ch: make(chan Event), q: newEventQueue()}
+	go sub.q.forward(sub.ch)
+	return b.subscribe(sub)
+}
+
+func (b *Bus) subscribe(sub subscriber) <-chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		sub.close()
+		return sub.ch
+	}
+	b.subs = append(b.subs, sub)
+	return sub.ch
+}
+
+// Unsubscribe stops delivery to ch, a channel returned by Subscribe or
+// SubscribeAll, and closes it. Events still queued for it are dropped.
+func (b *Bus) Unsubscribe(ch <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, sub := range b.subs {
+		if sub.ch == ch {
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			if sub.q != nil {
+				close(sub.q.stop)
+			} else {
+				close(sub.ch)
+			}
+			return
+		}
+	}
+}
+
+// Publish delivers ev to all subscribers.
+func (b *Bus) Publish(ev Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, sub := range b.subs {
+		if sub.q != nil {
+			sub.q.push(ev)
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			slog.Warn("event dropped, subscriber full", "agent", ev.AgentRef(), "event", ev)
+		}
+	}
+}
+
+// Close closes all subscriber channels. Further publishes are ignored.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, sub := range b.subs {
+		sub.close()
+	}
+	b.subs = nil
+}
+
+// close ends the subscription once its queued events are delivered.
+func (s subscriber) close() {
+	if s.q != nil {
+		s.q.close()
+		return
+	}
+	close(s.ch)
+}
+
+// eventQueue is the unbounded queue of a SubscribeAll subscriber.
+type eventQueue struct {
+	mu     sync.Mutex
+	events []Event
+	closed bool
+	wake   chan struct{} // signalled when events are pushed or the queue closes
+	stop   chan struct{} // closed when the subscriber unsubscribes
+}
+
+func newEventQueue() *eventQueue {
+	return &eventQueue{wake: make(chan struct{}, 1), stop: make(chan struct{})}
+}
+
+func (q *eventQueue) push(ev Event) {
+	q.mu.Lock()
+	q.events = append(q.events, ev)
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *eventQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *eventQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// forward delivers the queued events to ch in order, closing it when the
+// queue is closed and drained or the subscriber unsubscribes.
+func (q *eventQueue) forward(ch chan<- Event) {
+	defer close(ch)
+	for {
+		q.mu.Lock()
+		events, closed := q.events, q.closed
+		q.events = nil
+		q.mu.Unlock()
+		for _, ev := range events {
+			select {
+			case ch <- ev:
+			case <-q.stop:
+				return
+			}
+		}
+		if closed {
+			return
+		}
+		select {
+		case <-q.wake:
+		case <-q.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,695 @@
+// Package monitor polls agent panes and sidecar files, derives status
+// transitions and reports them as typed events. It knows nothing about
+// the TUI, so any frontend can consume its events and the transition
+// logic can be tested without tmux.
+package monitor
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/harness"
+	"github.com/simonbystrom/mastermind/internal/hook"
+	"github.com/simonbystrom/mastermind/internal/shim"
+	"github.com/simonbystrom/mastermind/internal/streamjson"
+	"github.com/simonbystrom/mastermind/internal/team"
+	"github.com/simonbystrom/mastermind/internal/tmux"
+)
+
+// DefaultGoneAfter is how many consecutive polls an agent's pane must be
+// missing before the agent is reported gone.
+const DefaultGoneAfter = 3
+
+// mtimeEntry caches the result of a file read keyed by its mtime.
+type mtimeEntry struct {
+	mtime  time.Time
+	result interface{}
+}
+
+// Monitor derives agent status from tmux and hook sidecar files.
+// Poll and RefreshSidecars must be called from a single goroutine.
+type Monitor struct {
+	store     *agent.Store
+	git       git.GitOps
+	tmux      tmux.TmuxOps
+	panes     tmux.PaneStatusChecker
+	harnesses map[harness.Type]harness.Harness
+	teams     team.TeamReader
+	emit      func(Event)
+
+	providers     []StatusProvider // consulted in priority order
+	providerOrder []string
+
+	tmuxDown   bool           // the last ListAllPanes call failed
+	serverPID  int            // tmux server PID seen by the last pane listing
+	goneAfter  int            // consecutive misses before AgentGone
+	goneMisses map[string]int // agentID → consecutive polls with the pane missing
+
+	// Performance caches (poll goroutine only, no mutex needed)
+	idleHasChanges       map[string]*bool      // agentID → cached HasChanges result for idle agents
+	statuslineMtimeCache map[string]mtimeEntry // worktreePath → cached statusline data
+	todosMtimeCache      map[string]mtimeEntry // worktreePath → cached todos data
+}
+
+// Option configures a Monitor.
+type Option func(*Monitor)
+
+// WithGit overrides the default git implementation.
+func WithGit(g git.GitOps) Option {
+	return func(m *Monitor) { m.git = g }
+}
+
+// WithTmux overrides the default tmux implementation.
+func WithTmux(t tmux.TmuxOps) Option {
+	return func(m *Monitor) { m.tmux = t }
+}
+
+// WithPaneChecker overrides the default pane content checker.
+func WithPaneChecker(c tmux.PaneStatusChecker) Option {
+	return func(m *Monitor) { m.panes = c }
+}
+
+// WithHarnesses sets the harnesses used to read agent metrics.
+func WithHarnesses(h map[harness.Type]harness.Harness) Option {
+	return func(m *Monitor) { m.harnesses = h }
+}
+
+// WithTeamReader overrides where agent teams led by Claude Code agents
+// are read from.
+func WithTeamReader(r team.TeamReader) Option {
+	return func(m *Monitor) { m.teams = r }
+}
+
+// WithEmitter sets the function every event is passed to. It is called
+// synchronously from the polling goroutine.
+func WithEmitter(fn func(Event)) Option {
+	return func(m *Monitor) { m.emit = fn }
+}
+
+// WithGoneAfter sets how many consecutive polls an agent's pane must be
+// missing before the agent is reported gone. Values below 1 mean 1.
+func WithGoneAfter(n int) Option {
+	return func(m *Monitor) { m.goneAfter = max(n, 1) }
+}
+
+// WithProviderOrder selects the built-in status providers to use, by
+// name, highest priority first. Unknown names are skipped, and the
+// defaults are used if none is known.
+func WithProviderOrder(names ...string) Option {
+	return func(m *Monitor) { m.providerOrder = names }
+}
+
+// WithProviders replaces the status providers entirely, highest priority
+// first. It takes precedence over WithProviderOrder.
+func WithProviders(providers ...StatusProvider) Option {
+	return func(m *Monitor) { m.providers = providers }
+}
+
+// New returns a Monitor watching the agents in store.
+func New(store *agent.Store, opts ...Option) *Monitor {
+	m := &Monitor{
+		store:                store,
+		git:                  git.RealGit{},
+		tmux:                 tmux.RealTmux{},
+		panes:                tmux.NewPaneMonitor(),
+		harnesses:            map[harness.Type]harness.Harness{},
+		teams:                team.NewReader(),
+		emit:                 func(Event) {},
+		goneAfter:            DefaultGoneAfter,
+		goneMisses:           make(map[string]int),
+		idleHasChanges:       make(map[string]*bool),
+		statuslineMtimeCache: make(map[string]mtimeEntry),
+		todosMtimeCache:      make(map[string]mtimeEntry),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.providers == nil {
+		order := m.providerOrder
+		if len(order) == 0 {
+			order = DefaultProviderOrder
+		}
+		m.providers = m.buildProviders(order)
+		if len(m.providers) == 0 {
+			// Without a provider no agent's status would ever change.
+			slog.Warn("no known status provider configured, using the defaults", "providers", order, "defaults", DefaultProviderOrder)
+			m.providers = m.buildProviders(DefaultProviderOrder)
+		}
+	}
+	return m
+}
+
+// Poll runs one monitoring pass over all agents in the store.
+func (m *Monitor) Poll() {
+	agents := m.store.All()
+
+	// Batch-fetch all panes on the server (1 subprocess) — now includes
+	// dead/exit status. Pane IDs are unique server-wide, so agents are
+	// still found after the mastermind pane moves to another session.
+	allPanes, paneListErr := m.tmux.ListAllPanes("")
+	if paneListErr == nil && len(allPanes) == 0 {
+		// The mastermind pane itself is on the server, so an empty list
+		// means the server is restarting rather than that every agent
+		// window was closed. Whether it was is told by its PID once it
+		// lists panes again.
+		paneListErr = fmt.Errorf("no panes on tmux server")
+	}
+	if paneListErr != nil {
+		// Without the pane list every agent would look gone. Mark them
+		// unknown and wait for tmux to answer again instead of dismissing.
+		if !m.tmuxDown {
+			slog.Warn("ListAllPanes failed, agent pane state unknown", "error", paneListErr)
+		}
+		m.tmuxDown = true
+		m.setPaneUnknown(agents, true)
+		return
+	}
+	if m.tmuxDown {
+		slog.Info("ListAllPanes recovered")
+		m.tmuxDown = false
+	}
+	m.setPaneUnknown(agents, false)
+	restarted := m.serverRestarted(allPanes)
+
+	// paneInWindow checks if a pane exists in the expected window.
+	paneInWindow := func(paneID, windowID string) bool {
+		info, ok := allPanes[paneID]
+		return ok && info.WindowID == windowID
+	}
+
+	// paneDeadFromBatch returns the pane's dead status from the batch result.
+	paneDeadFromBatch := func(paneID string) (dead bool, exitCode int, err error) {
+		if info, ok := allPanes[paneID]; ok {
+			return info.Dead, info.ExitCode, nil
+		}
+		// Pane not in batch = gone
+		return false, 0, fmt.Errorf("pane not on server")
+	}
+
+	for _, a := range agents {
+		snap := a.Snapshot()
+
+		// Handle lazygit pane detection for reviewing/conflicts agents
+		if (snap.Status == agent.StatusReviewing || snap.Status == agent.StatusConflicts) && snap.LazygitPaneID != "" {
+			lgGone := !paneInWindow(snap.LazygitPaneID, a.TmuxWindow)
+			if !lgGone {
+				// Pane exists but may be dead (remain-on-exit keeps it around).
+				dead, _, err := paneDeadFromBatch(snap.LazygitPaneID)
+				lgGone = err != nil || dead
+			}
+			if lgGone {
+				m.tmux.KillPane(snap.LazygitPaneID)
+				m.emit(LazygitClosed{AgentID: a.ID, Status: snap.Status})
+			}
+			continue
+		}
+
+		switch snap.Status {
+		case agent.StatusRunning, agent.StatusWaiting,
+			agent.StatusReviewReady, agent.StatusDone:
+			// These statuses need monitoring
+		default:
+			continue
+		}
+
+		if restarted {
+			// The pane went with the old server, and a pane with its ID
+			// on the new one belongs to something else.
+			m.markGone(a)
+			continue
+		}
+
+		// Check if pane still exists
+		if !paneInWindow(a.TmuxPaneID, a.TmuxWindow) {
+			m.handlePaneMissing(a, snap.Status)
+			continue
+		}
+		if n := m.goneMisses[a.ID]; n > 0 {
+			slog.Info("agent pane reappeared, re-adopting", "id", a.ID, "pane", a.TmuxPaneID, "missedPolls", n)
+			delete(m.goneMisses, a.ID)
+			a.SetPaneUnknown(false)
+		}
+
+		// Check for dead pane from batch result (no extra subprocess)
+		dead, exitCode, err := paneDeadFromBatch(a.TmuxPaneID)
+		if err != nil {
+			m.handlePaneMissing(a, snap.Status)
+			continue
+		}
+
+		if dead {
+			// The shim's record is more reliable than pane_dead_status.
+			if res := readShimResult(a); res != nil {
+				exitCode = res.ExitCode
+			}
+			m.handleAgentFinished(a, exitCode)
+			continue
+		}
+
+		// Ask status providers in priority order (hook files before
+		// pane content polling by default).
+		a.SetHeartbeat(hook.ReadHeartbeat(a.WorktreePath))
+		state, source := m.observe(a)
+		a.SetStatusSource(source)
+		switch state {
+		case StateGone:
+			m.markGone(a)
+			continue
+		case StateRunning:
+			a.SetEverActive(true)
+			delete(m.idleHasChanges, a.ID)
+			if snap.Status != agent.StatusRunning {
+				a.SetStatus(agent.StatusRunning)
+				a.SetWaitingFor("")
+				a.SetAttentionReason(nil)
+				m.store.MarkDirty()
+				slog.Debug("agent status change", "id", a.ID, "status", "running", "source", source)
+			}
+		case StateWaitingPermission:
+			a.SetEverActive(true)
+			if snap.Status != agent.StatusWaiting || snap.WaitingFor != "permission" {
+				m.handlePermission(a, source)
+			}
+		case StateIdle:
+			if snap.PendingTask != "" {
+				m.sendTask(a, snap.PendingTask)
+			} else if snap.EverActive {
+				m.handleAgentIdle(a)
+			}
+		}
+
+		m.RefreshSidecars(a)
+	}
+}
+
+// setPaneUnknown flags or clears the unknown pane state on agents that
+// have a live pane to check.
+func (m *Monitor) setPaneUnknown(agents []*agent.Agent, unknown bool) {
+	for _, a := range agents {
+		if unknown {
+			switch a.GetStatus() {
+			case agent.StatusDismissed, agent.StatusOrphaned:
+				continue
+			}
+		}
+		if a.GetPaneUnknown() != unknown {
+			a.SetPaneUnknown(unknown)
+		}
+	}
+}
+
+// serverRestarted records the PID of the tmux server that listed panes,
+// and reports whether it differs from the one seen before: the server was
+// restarted, so every agent window is gone and pane IDs start over.
+func (m *Monitor) serverRestarted(panes map[string]tmux.PaneInfo) bool {
+	pid := 0
+	for _, p := range panes {
+		pid = p.ServerPID
+		break
+	}
+	if pid == 0 {
+		return false
+	}
+	prev := m.serverPID
+	m.serverPID = pid
+	if prev == 0 || prev == pid {
+		return false
+	}
+	slog.Warn("tmux server restarted, agent windows are gone", "oldPID", prev, "pid", pid)
+	return true
+}
+
+// handlePaneMissing handles an agent whose pane is not in the pane list.
+// If the agent was still working and the shim recorded an exit, the
+// process ended normally (e.g. without remain-on-exit) and the agent
+// finished. Otherwise the window was probably closed, but a pane can be
+// missing from a listing for a moment, so the agent is only reported gone
+// after its pane has been missing for goneAfter polls in a row.
+func (m *Monitor) handlePaneMissing(a *agent.Agent, status agent.Status) {
+	if status == agent.StatusRunning || status == agent.StatusWaiting {
+		if res := readShimResult(a); res != nil {
+			delete(m.goneMisses, a.ID)
+			m.panes.Remove(a.TmuxPaneID)
+			m.handleAgentFinished(a, res.ExitCode)
+			return
+		}
+	}
+	m.goneMisses[a.ID]++
+	if m.goneMisses[a.ID] < m.goneAfter {
+		slog.Debug("agent pane missing", "id", a.ID, "pane", a.TmuxPaneID, "missedPolls", m.goneMisses[a.ID])
+		a.SetPaneUnknown(true)
+		return
+	}
+	m.markGone(a)
+}
+
+// readShimResult returns the agent's shim exit record, or nil.
+func readShimResult(a *agent.Agent) *shim.Result {
+	res, err := shim.ReadResult(a.WorktreePath)
+	if err != nil {
+		slog.Debug("shim result read error", "id", a.ID, "error", err)
+		return nil
+	}
+	if res != nil {
+		slog.Debug("shim result", "id", a.ID, "exitCode", res.ExitCode, "runtime", res.Runtime(), "cwd", res.CWD)
+	}
+	return res
+}
+
+// markGone marks an agent whose pane disappeared as dismissed.
+func (m *Monitor) markGone(a *agent.Agent) {
+	slog.Debug("pane gone, marking dismissed", "id", a.ID, "pane", a.TmuxPaneID)
+	m.panes.Remove(a.TmuxPaneID)
+	a.SetStatus(agent.StatusDismissed)
+	m.store.MarkDirty()
+	delete(m.idleHasChanges, a.ID)
+	delete(m.goneMisses, a.ID)
+	m.emit(AgentGone{AgentID: a.ID})
+}
+
+// observe returns the first reading from the status providers that is
+// not StateUnknown, along with the name of the provider that produced it.
+func (m *Monitor) observe(a *agent.Agent) (State, string) {
+	for _, p := range m.providers {
+		if state := p.Observe(a); state != StateUnknown {
+			return state, p.Name()
+		}
+	}
+	return StateUnknown, ""
+}
+
+// attentionLines is how many lines from the bottom of an agent's pane are
+// kept to show why it is waiting.
+const attentionLines = 6
+
+// handlePermission moves an agent into the waiting-for-permission state,
+// keeping the bottom of its pane, which shows the prompt. source names
+// the provider that detected it, for logging.
+func (m *Monitor) handlePermission(a *agent.Agent, source string) {
+	a.SetStatus(agent.StatusWaiting)
+	a.SetWaitingFor("permission")
+	a.SetAttentionReason(m.panes.Tail(a.TmuxPaneID, attentionLines))
+	m.store.MarkDirty()
+	slog.Debug("agent status change", "id", a.ID, "status", "waiting", "waitingFor", "permission", "source", source)
+	m.emit(Attention{AgentID: a.ID, Message: fmt.Sprintf("Agent %s needs permission", a.ID)})
+	m.emit(AgentWaiting{AgentID: a.ID, WaitingFor: "permission"})
+}
+
+// sendTask types the task the agent was spawned with into its pane, now
+// that it is ready for input, and submits it. The task is only tried
+// once; if it can't be sent the user is told to paste it themselves.
+func (m *Monitor) sendTask(a *agent.Agent, task string) {
+	a.SetPendingTask("")
+	m.store.MarkDirty()
+	err := m.tmux.PasteText(a.TmuxPaneID, task)
+	if err == nil {
+		err = m.tmux.SendKeys(a.TmuxPaneID, "Enter")
+	}
+	if err != nil {
+		slog.Warn("failed to send task to agent", "id", a.ID, "error", err)
+		m.emit(Attention{AgentID: a.ID, Message: fmt.Sprintf("Agent %s: could not send its task: %v", a.ID, err)})
+		return
+	}
+	slog.Info("task sent to agent", "id", a.ID)
+}
+
+// RefreshSidecars reloads the agent's statusline metrics and todos from
+// their sidecar files, and the agent team it leads. Unchanged files (by
+// mtime) are not re-read.
+func (m *Monitor) RefreshSidecars(a *agent.Agent) {
+	m.readStatuslineCached(a)
+	m.readTodosCached(a)
+	m.readTeam(a)
+}
+
+// readTeam looks up the agent team a Claude Code agent leads, found by
+// its session ID once the lead has created the team.
+func (m *Monitor) readTeam(a *agent.Agent) {
+	if a.Harness == harness.TypeOpenCode {
+		return
+	}
+	sessionID := a.GetSessionID()
+	if sessionID == "" {
+		return
+	}
+	info, err := m.teams.FindTeamForSession(sessionID)
+	if err != nil {
+		slog.Debug("failed to read agent team", "agent", a.ID, "error", err)
+		return
+	}
+	a.SetTeam(info)
+	m.readTeamCosts(a, info)
+}
+
+// readTeamCosts adds up what the sessions in a team lead's worktree have
+// spent: the lead's own and its teammates', which its statusline alone
+// does not include.
+func (m *Monitor) readTeamCosts(a *agent.Agent, info *team.TeamInfo) {
+	if info == nil {
+		a.SetTeamCosts(nil)
+		return
+	}
+	sessions, err := agent.ReadSessionStatuslines(a.WorktreePath)
+	if err != nil {
+		slog.Debug("failed to read session statuslines", "agent", a.ID, "error", err)
+		return
+	}
+	names := make(map[string]string, len(info.Members))
+	for _, member := range info.Members {
+		names[member.AgentID] = member.Name
+	}
+	leadID := a.GetSessionID()
+	var lead, teammates []agent.SessionCost
+	for _, sd := range sessions {
+		if sd.SessionID == leadID {
+			lead = append(lead, agent.SessionCost{Name: "lead", CostUSD: sd.CostUSD})
+			continue
+		}
+		name := names[sd.SessionID]
+		if name == "" {
+			name = "session " + shortID(sd.SessionID)
+		}
+		teammates = append(teammates, agent.SessionCost{Name: name, CostUSD: sd.CostUSD})
+	}
+	if len(teammates) == 0 {
+		// Nothing to add to the lead's own statusline cost.
+		a.SetTeamCosts(nil)
+		return
+	}
+	sort.Slice(teammates, func(i, j int) bool { return teammates[i].Name < teammates[j].Name })
+	if lead == nil {
+		// The lead's session file is missing, e.g. it ran before the
+		// statusline script kept one: fall back to its statusline.
+		if sd := a.GetStatuslineData(); sd != nil {
+			lead = append(lead, agent.SessionCost{Name: "lead", CostUSD: sd.CostUSD})
+		}
+	}
+	a.SetTeamCosts(append(lead, teammates...))
+}
+
+// shortID shortens a session ID for display.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// readStatuslineCached reads the metrics sidecar file, using mtime to skip re-reads.
+// The sidecar filename depends on the agent's harness type.
+func (m *Monitor) readStatuslineCached(a *agent.Agent) {
+	// Determine metrics file path based on harness type
+	var metricsFile string
+	switch a.Harness {
+	case harness.TypeOpenCode:
+		metricsFile = ".opencode-status.json"
+	default:
+		metricsFile = ".claude-status.json"
+	}
+	metricsPath := filepath.Join(a.WorktreePath, metricsFile)
+
+	info, err := os.Stat(metricsPath)
+	if err != nil && a.Harness != harness.TypeOpenCode {
+		// Stream-json agents report metrics through the relay state file.
+		info, err = os.Stat(filepath.Join(a.WorktreePath, streamjson.StateFileName))
+	}
+	if err != nil {
+		m.readStatuslineFromPane(a)
+		return
+	}
+	mtime := info.ModTime()
+	if cached, ok := m.statuslineMtimeCache[a.WorktreePath]; ok && cached.mtime.Equal(mtime) {
+		if sd, ok := cached.result.(*agent.StatuslineData); ok && sd != nil {
+			a.SetStatuslineData(sd)
+		}
+		return
+	}
+
+	// Use harness ReadMetrics to parse the correct sidecar file
+	h, ok := m.harnesses[a.Harness]
+	if !ok {
+		return
+	}
+	md, err := h.ReadMetrics(a.WorktreePath)
+	if err != nil || md == nil {
+		m.statuslineMtimeCache[a.WorktreePath] = mtimeEntry{mtime: mtime, result: (*agent.StatuslineData)(nil)}
+		return
+	}
+
+	sd := &agent.StatuslineData{
+		Model:        md.Model,
+		CostUSD:      md.CostUSD,
+		ContextPct:   md.ContextPct,
+		LinesAdded:   md.LinesAdded,
+		LinesRemoved: md.LinesRemoved,
+		SessionID:    md.SessionID,
+		InputTokens:  md.InputTokens,
+		OutputTokens: md.OutputTokens,
+	}
+
+	prevSessionID := a.GetSessionID()
+	a.SetStatuslineData(sd)
+	if sd.SessionID != "" && sd.SessionID != prevSessionID {
+		m.emit(SessionIDChanged{AgentID: a.ID, SessionID: sd.SessionID})
+	}
+	m.store.MarkDirty()
+	m.statuslineMtimeCache[a.WorktreePath] = mtimeEntry{mtime: mtime, result: sd}
+}
+
+// readStatuslineFromPane parses statusline metrics from the agent's pane
+// for agents without a metrics sidecar, e.g. because the statusline script
+// is not installed. The pane provider's capture from this poll is parsed
+// when there is one. Fields only the sidecar has are kept from the last
+// reading.
+func (m *Monitor) readStatuslineFromPane(a *agent.Agent) {
+	if a.TmuxPaneID == "" {
+		return
+	}
+	p := m.panes.Statusline(a.TmuxPaneID)
+	if p == nil {
+		return
+	}
+	sd := agent.StatuslineData{
+		Model:        p.Model,
+		CostUSD:      p.CostUSD,
+		ContextPct:   p.ContextPct,
+		LinesAdded:   p.LinesAdded,
+		LinesRemoved: p.LinesRemoved,
+	}
+	if prev := a.GetStatuslineData(); prev != nil {
+		if sd.Model == "" {
+			// OpenCode's overview doesn't name the model.
+			sd.Model = prev.Model
+		}
+		sd.SessionID = prev.SessionID
+		sd.InputTokens = prev.InputTokens
+		sd.OutputTokens = prev.OutputTokens
+		if *prev == sd {
+			return
+		}
+	}
+	a.SetStatuslineData(&sd)
+	m.store.MarkDirty()
+}
+
+// readTodosCached reads the todos sidecar file, using mtime to skip re-reads.
+func (m *Monitor) readTodosCached(a *agent.Agent) {
+	path := filepath.Join(a.WorktreePath, ".mastermind-todos")
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	mtime := info.ModTime()
+	if cached, ok := m.todosMtimeCache[a.WorktreePath]; ok && cached.mtime.Equal(mtime) {
+		if todos, ok := cached.result.([]hook.TodoItem); ok && todos != nil {
+			a.SetTodos(todos)
+		}
+		return
+	}
+	todos, err := hook.ReadTodos(a.WorktreePath)
+	if err != nil {
+		m.todosMtimeCache[a.WorktreePath] = mtimeEntry{mtime: mtime, result: ([]hook.TodoItem)(nil)}
+		return
+	}
+	a.SetTodos(todos)
+	m.todosMtimeCache[a.WorktreePath] = mtimeEntry{mtime: mtime, result: todos}
+}
+
+// hasChanges reports whether a finished agent left changes to review.
+// Report agents deliver a document instead and skip review.
+func (m *Monitor) hasChanges(a *agent.Agent) bool {
+	return a.ReportFile == "" && m.git.HasChanges(a.WorktreePath)
+}
+
+func (m *Monitor) handleAgentFinished(a *agent.Agent, exitCode int) {
+	a.SetFinished(exitCode, time.Now())
+
+	hasChanges := m.hasChanges(a)
+	// Cache the result for subsequent idle checks
+	hc := hasChanges
+	m.idleHasChanges[a.ID] = &hc
+
+	if hasChanges {
+		a.SetStatus(agent.StatusReviewReady)
+	} else {
+		a.SetStatus(agent.StatusDone)
+	}
+	m.store.MarkDirty()
+
+	slog.Info("agent finished", "id", a.ID, "exitCode", exitCode, "hasChanges", hasChanges)
+
+	if hasChanges {
+		m.emit(Attention{AgentID: a.ID, Message: fmt.Sprintf("Agent %s finished with changes", a.ID)})
+	} else {
+		m.emit(Attention{AgentID: a.ID, Message: fmt.Sprintf("Agent %s finished", a.ID)})
+	}
+
+	m.emit(AgentFinished{
+		AgentID:    a.ID,
+		ExitCode:   exitCode,
+		HasChanges: hasChanges,
+	})
+}
+
+func (m *Monitor) handleAgentIdle(a *agent.Agent) {
+	// Don't overwrite reviewed status — it must stick until merge or manual change
+	if a.GetStatus() == agent.StatusReviewed {
+		return
+	}
+
+	// Use cached HasChanges result for idle agents to avoid redundant git status calls
+	var hasChanges bool
+	if cached := m.idleHasChanges[a.ID]; cached != nil {
+		hasChanges = *cached
+	} else {
+		hasChanges = m.hasChanges(a)
+		hc := hasChanges
+		m.idleHasChanges[a.ID] = &hc
+	}
+
+	if hasChanges {
+		if a.GetStatus() != agent.StatusReviewReady {
+			a.SetStatus(agent.StatusReviewReady)
+			a.SetFinished(a.GetExitCode(), time.Now())
+			m.store.MarkDirty()
+			slog.Info("agent idle with changes", "id", a.ID)
+			m.emit(Attention{AgentID: a.ID, Message: fmt.Sprintf("Agent %s ready for review", a.ID)})
+			m.emit(AgentFinished{AgentID: a.ID, HasChanges: true})
+		}
+	} else {
+		if a.GetStatus() != agent.StatusDone {
+			a.SetStatus(agent.StatusDone)
+			a.SetFinished(a.GetExitCode(), time.Now())
+			m.store.MarkDirty()
+			slog.Info("agent idle without changes", "id", a.ID)
+			m.emit(Attention{AgentID: a.ID, Message: fmt.Sprintf("Agent %s finished", a.ID)})
+			m.emit(AgentFinished{AgentID: a.ID, HasChanges: false})
+		}
+	}
+}
@@ -0,0 +1,857 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/harness"
+	"github.com/simonbystrom/mastermind/internal/harness/custom"
+	"github.com/simonbystrom/mastermind/internal/hook"
+	"github.com/simonbystrom/mastermind/internal/shim"
+	"github.com/simonbystrom/mastermind/internal/streamjson"
+	"github.com/simonbystrom/mastermind/internal/team"
+	"github.com/simonbystrom/mastermind/internal/tmux"
+)
+
+// --- Mock implementations ---
+
+// mockGit only implements the methods the monitor calls; the embedded
+// interface panics on anything else.
+type mockGit struct {
+	git.GitOps
+	hasChanges      bool
+	hasChangesCalls int
+}
+
+func (m *mockGit) HasChanges(wtPath string) bool {
+	m.hasChangesCalls++
+	return m.hasChanges
+}
+
+type mockTmux struct {
+	tmux.TmuxOps
+	panes   map[string]tmux.PaneInfo
+	listErr error
+	killed  []string
+	sent    []string // pasted text and keys, in order
+}
+
+func (m *mockTmux) ListAllPanes(session string) (map[string]tmux.PaneInfo, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	return m.panes, nil
+}
+
+func (m *mockTmux) KillPane(paneID string) error {
+	m.killed = append(m.killed, paneID)
+	return nil
+}
+
+func (m *mockTmux) PasteText(paneID, text string) error {
+	m.sent = append(m.sent, "paste:"+text)
+	return nil
+}
+
+func (m *mockTmux) SendKeys(paneID string, keys ...string) error {
+	m.sent = append(m.sent, "keys:"+strings.Join(keys, " "))
+	return nil
+}
+
+type mockPanes struct {
+	status     tmux.PaneStatus
+	statusline *tmux.StatuslineFromPane
+	tail       []string
+	removed    []string
+}
+
+func (m *mockPanes) GetPaneStatus(paneID string) (tmux.PaneStatus, error) {
+	return m.status, nil
+}
+
+func (m *mockPanes) Statusline(paneID string) *tmux.StatuslineFromPane {
+	return m.statusline
+}
+
+func (m *mockPanes) Tail(paneID string, n int) []string {
+	return m.tail
+}
+
+func (m *mockPanes) Remove(paneID string) {
+	m.removed = append(m.removed, paneID)
+}
+
+// mockTeams returns the team led by each session.
+type mockTeams struct {
+	teams map[string]*team.TeamInfo
+}
+
+func (m *mockTeams) FindTeamForSession(sessionID string) (*team.TeamInfo, error) {
+	return m.teams[sessionID], nil
+}
+
+// --- Helpers ---
+
+type fixture struct {
+	mon    *Monitor
+	store  *agent.Store
+	git    *mockGit
+	tmux   *mockTmux
+	panes  *mockPanes
+	teams  *mockTeams
+	events []Event
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{
+		store: agent.NewStore(),
+		git:   &mockGit{},
+		// %0 is the mastermind pane, which is always on the server.
+		tmux:  &mockTmux{panes: map[string]tmux.PaneInfo{"%0": {WindowID: "@0"}}},
+		panes: &mockPanes{},
+		teams: &mockTeams{},
+	}
+	f.mon = New(f.store,
+		WithGit(f.git),
+		WithTmux(f.tmux),
+		WithPaneChecker(f.panes),
+		WithTeamReader(f.teams),
+		WithEmitter(func(ev Event) { f.events = append(f.events, ev) }),
+	)
+	return f
+}
+
+func (f *fixture) addAgent(t *testing.T, status agent.Status) *agent.Agent {
+	t.Helper()
+	a := agent.NewAgent("feat/x", "main", t.TempDir(), "@1", "%1", "claude")
+	a.SetStatus(status)
+	f.store.Add(a)
+	f.tmux.panes["%1"] = tmux.PaneInfo{WindowID: "@1"}
+	return a
+}
+
+func writeHookStatus(t *testing.T, dir, status string) {
+	t.Helper()
+	data, _ := json.Marshal(hook.StatusFile{Status: status, Timestamp: time.Now().Unix()})
+	if err := os.WriteFile(filepath.Join(dir, ".mastermind-status"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeShimResult(t *testing.T, dir string, exitCode int) {
+	t.Helper()
+	data, _ := json.Marshal(shim.Result{ExitCode: exitCode, StartedAt: time.Now(), FinishedAt: time.Now()})
+	if err := os.WriteFile(filepath.Join(dir, shim.ResultFileName), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasEvent[T Event](events []Event) (T, bool) {
+	for _, ev := range events {
+		if e, ok := ev.(T); ok {
+			return e, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// --- Tests ---
+
+func TestPoll_DeadPaneWithChanges(t *testing.T) {
+	f := newFixture(t)
+	f.git.hasChanges = true
+	a := f.addAgent(t, agent.StatusRunning)
+	f.tmux.panes["%1"] = tmux.PaneInfo{WindowID: "@1", Dead: true, ExitCode: 2}
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusReviewReady {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusReviewReady)
+	}
+	fin, ok := hasEvent[AgentFinished](f.events)
+	if !ok {
+		t.Fatal("expected AgentFinished event")
+	}
+	if fin.ExitCode != 2 || !fin.HasChanges {
+		t.Errorf("event = %+v, want exit 2 with changes", fin)
+	}
+	if _, ok := hasEvent[Attention](f.events); !ok {
+		t.Error("expected Attention event")
+	}
+}
+
+func TestPoll_ReportAgentSkipsReview(t *testing.T) {
+	f := newFixture(t)
+	f.git.hasChanges = true
+	a := f.addAgent(t, agent.StatusRunning)
+	a.ReportFile = "REPORT.md"
+	f.tmux.panes["%1"] = tmux.PaneInfo{WindowID: "@1", Dead: true}
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusDone {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusDone)
+	}
+	if fin, ok := hasEvent[AgentFinished](f.events); !ok || fin.HasChanges {
+		t.Errorf("AgentFinished = %+v, %v; want one without changes to review", fin, ok)
+	}
+}
+
+func TestPoll_DeadPaneNoChanges(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	f.tmux.panes["%1"] = tmux.PaneInfo{WindowID: "@1", Dead: true}
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusDone {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusDone)
+	}
+}
+
+func TestPoll_PaneGone(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	delete(f.tmux.panes, "%1")
+
+	for i := 1; i < DefaultGoneAfter; i++ {
+		f.mon.Poll()
+		if a.GetStatus() == agent.StatusDismissed || !a.GetPaneUnknown() {
+			t.Fatalf("poll %d: status = %q, unknown = %v; want running and unknown until the debounce expires",
+				i, a.GetStatus(), a.GetPaneUnknown())
+		}
+	}
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusDismissed {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusDismissed)
+	}
+	if _, ok := hasEvent[AgentGone](f.events); !ok {
+		t.Error("expected AgentGone event")
+	}
+	if len(f.panes.removed) != 1 || f.panes.removed[0] != "%1" {
+		t.Errorf("removed = %v, want [%%1]", f.panes.removed)
+	}
+}
+
+func TestPoll_PaneReappearsIsReadopted(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	delete(f.tmux.panes, "%1")
+	f.mon.Poll()
+
+	f.tmux.panes["%1"] = tmux.PaneInfo{WindowID: "@1"}
+	f.mon.Poll()
+	delete(f.tmux.panes, "%1")
+	for i := 1; i < DefaultGoneAfter; i++ {
+		f.mon.Poll()
+	}
+
+	if a.GetStatus() == agent.StatusDismissed {
+		t.Error("misses before the pane reappeared should not count towards the debounce")
+	}
+	if _, ok := hasEvent[AgentGone](f.events); ok {
+		t.Error("unexpected AgentGone event")
+	}
+}
+
+func TestPoll_EmptyPaneListMarksUnknown(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	f.tmux.panes = map[string]tmux.PaneInfo{}
+
+	for i := 0; i < DefaultGoneAfter+1; i++ {
+		f.mon.Poll()
+	}
+
+	if a.GetStatus() != agent.StatusRunning || !a.GetPaneUnknown() {
+		t.Errorf("status = %q, unknown = %v; want running and unknown", a.GetStatus(), a.GetPaneUnknown())
+	}
+}
+
+func TestPoll_ServerRestartMarksAgentsGone(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	f.tmux.panes = map[string]tmux.PaneInfo{
+		"%0": {WindowID: "@0", ServerPID: 100},
+		"%1": {WindowID: "@1", ServerPID: 100},
+	}
+	f.mon.Poll()
+
+	// The server restarts: its first listing is empty, then a new server
+	// hands out the agent's pane ID to another window's pane.
+	f.tmux.panes = map[string]tmux.PaneInfo{}
+	f.mon.Poll()
+	if a.GetStatus() != agent.StatusRunning {
+		t.Fatalf("status = %q, want running while the server restarts", a.GetStatus())
+	}
+	f.tmux.panes = map[string]tmux.PaneInfo{
+		"%0": {WindowID: "@0", ServerPID: 200},
+		"%1": {WindowID: "@1", ServerPID: 200},
+	}
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusDismissed {
+		t.Errorf("status = %q, want the agent gone with the old server", a.GetStatus())
+	}
+	if _, ok := hasEvent[AgentGone](f.events); !ok {
+		t.Error("expected AgentGone event")
+	}
+}
+
+func TestPoll_ListPanesFailureMarksUnknown(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	f.tmux.listErr = errors.New("server not responding")
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusRunning {
+		t.Errorf("status = %q, want running to be kept", a.GetStatus())
+	}
+	if !a.GetPaneUnknown() {
+		t.Error("agent should be marked unknown while tmux is unreachable")
+	}
+	if _, ok := hasEvent[AgentGone](f.events); ok {
+		t.Error("a failed pane listing should not report the agent gone")
+	}
+
+	f.tmux.listErr = nil
+	f.mon.Poll()
+	if a.GetPaneUnknown() {
+		t.Error("unknown flag should clear once tmux answers again")
+	}
+}
+
+func TestPoll_DeadPanePrefersShimExitCode(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	f.tmux.panes["%1"] = tmux.PaneInfo{WindowID: "@1", Dead: true, ExitCode: 0}
+	writeShimResult(t, a.WorktreePath, 3)
+
+	f.mon.Poll()
+
+	fin, ok := hasEvent[AgentFinished](f.events)
+	if !ok || fin.ExitCode != 3 {
+		t.Errorf("event = %+v, want exit 3 from shim", fin)
+	}
+}
+
+func TestPoll_PaneGoneWithShimResultFinishes(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	delete(f.tmux.panes, "%1")
+	writeShimResult(t, a.WorktreePath, 1)
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusDone {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusDone)
+	}
+	if _, ok := hasEvent[AgentGone](f.events); ok {
+		t.Error("unexpected AgentGone event")
+	}
+	if fin, ok := hasEvent[AgentFinished](f.events); !ok || fin.ExitCode != 1 {
+		t.Errorf("event = %+v, want exit 1", fin)
+	}
+}
+
+func TestPoll_PanePermissionPrompt(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	f.panes.status = tmux.PaneStatus{WaitingFor: "permission"}
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusWaiting || a.GetWaitingFor() != "permission" {
+		t.Errorf("status = %q/%q, want waiting/permission", a.GetStatus(), a.GetWaitingFor())
+	}
+	if _, ok := hasEvent[AgentWaiting](f.events); !ok {
+		t.Error("expected AgentWaiting event")
+	}
+
+	// A second pass in the same state must not re-emit.
+	f.events = nil
+	f.mon.Poll()
+	if len(f.events) != 0 {
+		t.Errorf("expected no events on unchanged state, got %v", f.events)
+	}
+}
+
+func TestPoll_IdleRequiresEverActive(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	f.panes.status = tmux.PaneStatus{WaitingFor: "input"}
+
+	f.mon.Poll()
+	if a.GetStatus() != agent.StatusRunning {
+		t.Errorf("status = %q, want running before agent was ever active", a.GetStatus())
+	}
+
+	a.SetEverActive(true)
+	f.mon.Poll()
+	if a.GetStatus() != agent.StatusDone {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusDone)
+	}
+}
+
+func TestPoll_SendsPendingTaskOnceReady(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	a.SetPendingTask("Fix the login bug.\nAdd a test.")
+	// Claude Code drawing its UI on startup looks like activity.
+	a.SetEverActive(true)
+
+	f.panes.status = tmux.PaneStatus{}
+	f.mon.Poll()
+	if len(f.tmux.sent) != 0 {
+		t.Fatalf("sent %q before the agent was ready for input", f.tmux.sent)
+	}
+
+	f.panes.status = tmux.PaneStatus{WaitingFor: "input"}
+	f.mon.Poll()
+	want := []string{"paste:Fix the login bug.\nAdd a test.", "keys:Enter"}
+	if strings.Join(f.tmux.sent, "|") != strings.Join(want, "|") {
+		t.Errorf("sent = %q, want %q", f.tmux.sent, want)
+	}
+	if a.GetPendingTask() != "" {
+		t.Errorf("pending task = %q after sending, want none", a.GetPendingTask())
+	}
+	if a.GetStatus() != agent.StatusRunning {
+		t.Errorf("status = %q, want running while the task is handed over", a.GetStatus())
+	}
+
+	f.mon.Poll()
+	if len(f.tmux.sent) != 2 {
+		t.Errorf("task sent again: %q", f.tmux.sent)
+	}
+}
+
+func TestPoll_CustomHarnessPatterns(t *testing.T) {
+	f := newFixture(t)
+	h, err := custom.New(custom.Spec{
+		Name:               "aider",
+		Command:            "aider",
+		InputPatterns:      []string{`^> $`},
+		PermissionPatterns: []string{`\(Y\)es/\(N\)o`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	WithHarnesses(map[harness.Type]harness.Harness{"aider": h})(f.mon)
+	a := agent.NewAgent("feat/aider", "main", t.TempDir(), "@1", "%1", "aider")
+	a.SetStatus(agent.StatusRunning)
+	f.store.Add(a)
+	f.tmux.panes["%1"] = tmux.PaneInfo{WindowID: "@1"}
+	// Claude's pane detection must not apply to other tools.
+	f.panes.status = tmux.PaneStatus{WaitingFor: "input"}
+
+	f.panes.tail = []string{"Applied edit to main.go", "Run tests? (Y)es/(N)o [Yes]:"}
+	f.mon.Poll()
+	if a.GetStatus() != agent.StatusWaiting || a.GetWaitingFor() != "permission" {
+		t.Errorf("status = %q/%q, want waiting/permission", a.GetStatus(), a.GetWaitingFor())
+	}
+
+	f.panes.tail = []string{"Thinking…"}
+	f.mon.Poll()
+	if a.GetStatus() != agent.StatusRunning {
+		t.Errorf("status = %q, want running", a.GetStatus())
+	}
+}
+
+func TestPoll_IdleCachesHasChanges(t *testing.T) {
+	f := newFixture(t)
+	f.git.hasChanges = true
+	a := f.addAgent(t, agent.StatusRunning)
+	a.SetEverActive(true)
+	f.panes.status = tmux.PaneStatus{WaitingFor: "input"}
+
+	f.mon.Poll()
+	f.mon.Poll()
+
+	if f.git.hasChangesCalls != 1 {
+		t.Errorf("HasChanges called %d times, want 1", f.git.hasChangesCalls)
+	}
+}
+
+func TestPoll_HookStatusTakesPrecedence(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	writeHookStatus(t, a.WorktreePath, hook.StatusWaitingPermission)
+	// Pane content says running; the fresh hook status must win.
+	f.panes.status = tmux.PaneStatus{}
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusWaiting {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusWaiting)
+	}
+}
+
+func TestPoll_PermissionKeepsPaneTail(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	a.SetEverActive(true)
+	f.panes.status = tmux.PaneStatus{WaitingFor: "permission"}
+	f.panes.tail = []string{"Bash(rm -rf build)", "Do you want to proceed?", "❯ 1. Yes"}
+
+	f.mon.Poll()
+	if got := a.GetAttentionReason(); len(got) != 3 || got[0] != "Bash(rm -rf build)" {
+		t.Errorf("attention reason = %q, want the pane tail", got)
+	}
+
+	f.panes.status = tmux.PaneStatus{}
+	f.mon.Poll()
+	if got := a.GetAttentionReason(); got != nil {
+		t.Errorf("attention reason = %q once running again, want none", got)
+	}
+}
+
+func TestRefreshSidecars_StatuslineFromPane(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	a.SetStatuslineData(&agent.StatuslineData{Model: "Opus", SessionID: "s1"})
+	// No .claude-status.json sidecar: the pane's statusline is used.
+	f.panes.statusline = &tmux.StatuslineFromPane{CostUSD: 1.25, ContextPct: 40, LinesAdded: 3}
+
+	f.mon.RefreshSidecars(a)
+
+	want := agent.StatuslineData{Model: "Opus", CostUSD: 1.25, ContextPct: 40, LinesAdded: 3, SessionID: "s1"}
+	if sd := a.GetStatuslineData(); sd == nil || *sd != want {
+		t.Errorf("statusline = %+v, want %+v", sd, want)
+	}
+}
+
+func TestRefreshSidecars_Team(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	info := &team.TeamInfo{TeamName: "feat-x", MemberCount: 2}
+	f.teams.teams = map[string]*team.TeamInfo{"s1": info}
+
+	// The team is looked up by session ID, which is not known yet.
+	f.mon.RefreshSidecars(a)
+	if got := a.GetTeam(); got != nil {
+		t.Fatalf("team before session = %+v, want nil", got)
+	}
+
+	a.SetSessionID("s1")
+	f.mon.RefreshSidecars(a)
+	if got := a.GetTeam(); got != info {
+		t.Errorf("team = %+v, want %+v", got, info)
+	}
+}
+
+func TestRefreshSidecars_TeamCosts(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	a.SetSessionID("s-lead")
+	f.teams.teams = map[string]*team.TeamInfo{"s-lead": {
+		TeamName: "feat-x",
+		Members:  []team.Member{{Name: "lead", AgentID: "s-lead", AgentType: "lead"}, {Name: "backend", AgentID: "s-backend", AgentType: "teammate"}},
+	}}
+	dir := filepath.Join(a.WorktreePath, agent.SessionStatuslineDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for session, cost := range map[string]float64{"s-lead": 1.5, "s-backend": 0.75, "s-unknown0123": 0.25} {
+		data := fmt.Sprintf(`{"session_id":%q,"cost":{"total_cost_usd":%g}}`, session, cost)
+		if err := os.WriteFile(filepath.Join(dir, session+".json"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f.mon.RefreshSidecars(a)
+
+	want := []agent.SessionCost{{Name: "lead", CostUSD: 1.5}, {Name: "backend", CostUSD: 0.75}, {Name: "session s-unknow", CostUSD: 0.25}}
+	if got := a.GetTeamCosts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("team costs = %+v, want %+v", got, want)
+	}
+	if total, ok := a.TeamCostUSD(); !ok || total != 2.5 {
+		t.Errorf("TeamCostUSD = %v, %v; want 2.5", total, ok)
+	}
+}
+
+func TestPoll_TeammateHookStatus(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	a.SetEverActive(true)
+	a.SetSessionID("lead")
+	f.panes.status = tmux.PaneStatus{WaitingFor: "input"}
+
+	write := func(session, status string, age time.Duration) {
+		t.Helper()
+		path := filepath.Join(a.WorktreePath, hook.StatusFileName)
+		data, _ := json.Marshal(hook.StatusFile{Version: hook.ProtocolVersion, Status: status, Timestamp: time.Now().Unix(), SessionID: session})
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Distinct mtimes, so the provider's cache sees every write.
+		mtime := time.Now().Add(age)
+		os.Chtimes(path, mtime, mtime)
+	}
+
+	write("lead", hook.StatusRunning, -3*time.Second)
+	f.mon.Poll()
+	// A teammate finishing must not mark the lead idle.
+	write("mate", hook.StatusIdle, -2*time.Second)
+	f.mon.Poll()
+	if a.GetStatus() != agent.StatusRunning {
+		t.Errorf("after teammate idle: status = %q, want %q", a.GetStatus(), agent.StatusRunning)
+	}
+	// A teammate asking for permission needs the user all the same.
+	write("mate", hook.StatusWaitingPermission, -time.Second)
+	f.mon.Poll()
+	if a.GetStatus() != agent.StatusWaiting {
+		t.Errorf("after teammate permission: status = %q, want %q", a.GetStatus(), agent.StatusWaiting)
+	}
+}
+
+func TestPoll_LazygitClosed(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusReviewing)
+	a.SetLazygitPaneID("%2")
+	f.tmux.panes["%2"] = tmux.PaneInfo{WindowID: "@1", Dead: true}
+
+	f.mon.Poll()
+
+	ev, ok := hasEvent[LazygitClosed](f.events)
+	if !ok {
+		t.Fatal("expected LazygitClosed event")
+	}
+	if ev.Status != agent.StatusReviewing {
+		t.Errorf("event status = %q, want %q", ev.Status, agent.StatusReviewing)
+	}
+	if len(f.tmux.killed) != 1 || f.tmux.killed[0] != "%2" {
+		t.Errorf("killed = %v, want [%%2]", f.tmux.killed)
+	}
+}
+
+func TestBus_FanOut(t *testing.T) {
+	b := NewBus()
+	ch1 := b.Subscribe(1)
+	ch2 := b.Subscribe(1)
+
+	b.Publish(AgentGone{AgentID: "a1"})
+
+	for _, ch := range []<-chan Event{ch1, ch2} {
+		if ev := <-ch; ev.AgentRef() != "a1" {
+			t.Errorf("got %v, want event for a1", ev)
+		}
+	}
+}
+
+func TestBus_FullSubscriberDoesNotBlock(t *testing.T) {
+	b := NewBus()
+	ch := b.Subscribe(1)
+
+	b.Publish(AgentGone{AgentID: "a1"})
+	b.Publish(AgentGone{AgentID: "a2"}) // dropped
+
+	if ev := <-ch; ev.AgentRef() != "a1" {
+		t.Errorf("got %v, want event for a1", ev)
+	}
+	select {
+	case ev := <-ch:
+		t.Errorf("unexpected event %v", ev)
+	default:
+	}
+}
+
+func TestBus_SubscribeAllKeepsEvents(t *testing.T) {
+	b := NewBus()
+	ch := b.SubscribeAll()
+
+	for i := range 100 {
+		b.Publish(AgentGone{AgentID: fmt.Sprintf("a%d", i)})
+	}
+	b.Close()
+
+	var got int
+	for ev := range ch {
+		if want := fmt.Sprintf("a%d", got); ev.AgentRef() != want {
+			t.Fatalf("event %d is for %s, want %s", got, ev.AgentRef(), want)
+		}
+		got++
+	}
+	if got != 100 {
+		t.Errorf("got %d events, want all 100 before the channel closed", got)
+	}
+}
+
+func TestBus_UnsubscribeAll(t *testing.T) {
+	b := NewBus()
+	ch := b.SubscribeAll()
+	b.Publish(AgentGone{AgentID: "a1"})
+	b.Unsubscribe(ch)
+
+	for range ch {
+		// The queued event may or may not be delivered; the channel closes.
+	}
+	b.Publish(AgentGone{AgentID: "a2"})
+}
+
+func TestBus_CloseClosesSubscribers(t *testing.T) {
+	b := NewBus()
+	ch := b.Subscribe(1)
+	b.Close()
+
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed")
+	}
+	// Publishing and subscribing after close must be safe.
+	b.Publish(AgentGone{AgentID: "a1"})
+	if _, ok := <-b.Subscribe(1); ok {
+		t.Error("expected subscription after close to be closed")
+	}
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	b := NewBus()
+	ch := b.Subscribe(1)
+	b.Unsubscribe(ch)
+
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed")
+	}
+	b.Publish(AgentGone{AgentID: "a1"}) // must not panic on the closed channel
+	b.Close()
+}
+
+func TestMarshalEvent_RoundTrip(t *testing.T) {
+	want := LazygitClosed{AgentID: "a1", Status: agent.StatusReviewing}
+	typ, data, err := MarshalEvent(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalEvent(typ, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("round trip = %#v, want %#v", got, want)
+	}
+}
+
+type fixedProvider struct {
+	name  string
+	state State
+	calls int
+}
+
+func (p *fixedProvider) Name() string { return p.name }
+
+func (p *fixedProvider) Observe(a *agent.Agent) State {
+	p.calls++
+	return p.state
+}
+
+func TestPoll_ProviderPriority(t *testing.T) {
+	f := newFixture(t)
+	first := &fixedProvider{name: "first", state: StateUnknown}
+	second := &fixedProvider{name: "second", state: StateWaitingPermission}
+	third := &fixedProvider{name: "third", state: StateRunning}
+	f.mon.providers = []StatusProvider{first, second, third}
+	a := f.addAgent(t, agent.StatusRunning)
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusWaiting {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusWaiting)
+	}
+	if first.calls != 1 || second.calls != 1 || third.calls != 0 {
+		t.Errorf("calls = %d/%d/%d, want 1/1/0", first.calls, second.calls, third.calls)
+	}
+}
+
+func TestPoll_ProviderReportsGone(t *testing.T) {
+	f := newFixture(t)
+	f.mon.providers = []StatusProvider{&fixedProvider{name: "gone", state: StateGone}}
+	a := f.addAgent(t, agent.StatusRunning)
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusDismissed {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusDismissed)
+	}
+}
+
+func TestWithProviderOrder_PaneOnlyIgnoresHook(t *testing.T) {
+	store := agent.NewStore()
+	panes := &mockPanes{}
+	mt := &mockTmux{panes: map[string]tmux.PaneInfo{"%1": {WindowID: "@1"}}}
+	mon := New(store,
+		WithTmux(mt),
+		WithPaneChecker(panes),
+		WithProviderOrder("pane", "bogus", "pane"),
+	)
+	if len(mon.providers) != 1 || mon.providers[0].Name() != ProviderPane {
+		t.Fatalf("providers = %v, want [pane]", mon.providers)
+	}
+
+	a := agent.NewAgent("feat/x", "main", t.TempDir(), "@1", "%1", "claude")
+	store.Add(a)
+	a.SetStatus(agent.StatusRunning)
+	writeHookStatus(t, a.WorktreePath, hook.StatusWaitingPermission)
+
+	mon.Poll()
+
+	if a.GetStatus() != agent.StatusRunning {
+		t.Errorf("status = %q, want %q (hook provider disabled)", a.GetStatus(), agent.StatusRunning)
+	}
+}
+
+func TestNew_DefaultProviderOrder(t *testing.T) {
+	mon := New(agent.NewStore())
+	var names []string
+	for _, p := range mon.providers {
+		names = append(names, p.Name())
+	}
+	if len(names) != 3 || names[0] != ProviderStream || names[1] != ProviderHook || names[2] != ProviderPane {
+		t.Errorf("providers = %v, want [stream hook pane]", names)
+	}
+}
+
+func TestPoll_StreamStateOutranksHook(t *testing.T) {
+	f := newFixture(t)
+	a := f.addAgent(t, agent.StatusRunning)
+	a.SetEverActive(true)
+	writeHookStatus(t, a.WorktreePath, hook.StatusRunning)
+	data, _ := json.Marshal(streamjson.State{Status: streamjson.StatusIdle, Timestamp: time.Now().Unix()})
+	if err := os.WriteFile(filepath.Join(a.WorktreePath, streamjson.StateFileName), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f.mon.Poll()
+
+	if a.GetStatus() != agent.StatusDone {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusDone)
+	}
+}
+
+func TestNew_UnknownProvidersFallBackToDefaults(t *testing.T) {
+	m := New(agent.NewStore(), WithProviderOrder("hooks", "otel"))
+	var names []string
+	for _, p := range m.providers {
+		names = append(names, p.Name())
+	}
+	if !slices.Equal(names, DefaultProviderOrder) {
+		t.Errorf("providers = %v, want the defaults %v", names, DefaultProviderOrder)
+	}
+
+	m = New(agent.NewStore(), WithProviderOrder("otel", ProviderPane))
+	if len(m.providers) != 1 || m.providers[0].Name() != ProviderPane {
+		t.Errorf("providers = %v, want only the known pane provider", m.providers)
+	}
+}
@@ -0,0 +1,250 @@
+package monitor
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/harness"
+	"github.com/simonbystrom/mastermind/internal/hook"
+	"github.com/simonbystrom/mastermind/internal/streamjson"
+)
+
+// State is an agent's activity as reported by a StatusProvider.
+type State int
+
+const (
+	// StateUnknown means the provider has no usable reading; the next
+	// provider in priority order is consulted.
+	StateUnknown State = iota
+	StateRunning
+	StateWaitingPermission
+	// StateIdle covers finished turns and input prompts alike; whether the
+	// agent is review ready or done is derived from the worktree.
+	StateIdle
+	// StateGone means the agent's pane can no longer be observed.
+	StateGone
+)
+
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StateWaitingPermission:
+		return "waiting_permission"
+	case StateIdle:
+		return "idle"
+	case StateGone:
+		return "gone"
+	default:
+		return "unknown"
+	}
+}
+
+// StatusProvider is one mechanism for detecting what a live agent is
+// doing. The monitor asks providers in priority order and uses the first
+// reading that is not StateUnknown.
+type StatusProvider interface {
+	// Name identifies the provider in config and logs.
+	Name() string
+	// Observe returns the agent's current state.
+	Observe(a *agent.Agent) State
+}
+
+// Provider names accepted by WithProviderOrder and the [monitor] config.
+const (
+	ProviderStream = "stream"
+	ProviderHook   = "hook"
+	ProviderPane   = "pane"
+)
+
+// DefaultProviderOrder is used when no order is configured: the exact
+// stream-json relay state when present, then hook files, with pane
+// content polling as the fallback.
+var DefaultProviderOrder = []string{ProviderStream, ProviderHook, ProviderPane}
+
+// providerFactories builds the named providers for a monitor. New
+// detection mechanisms register here.
+var providerFactories = map[string]func(m *Monitor) StatusProvider{
+	ProviderStream: func(*Monitor) StatusProvider { return newStreamProvider() },
+	ProviderHook:   func(*Monitor) StatusProvider { return newHookProvider() },
+	ProviderPane:   func(m *Monitor) StatusProvider { return &paneProvider{m: m} },
+}
+
+// buildProviders resolves names into providers, skipping unknown or
+// duplicate names.
+func (m *Monitor) buildProviders(names []string) []StatusProvider {
+	var providers []StatusProvider
+	seen := make(map[string]bool)
+	for _, name := range names {
+		factory, ok := providerFactories[name]
+		if !ok {
+			slog.Warn("unknown status provider, skipping", "provider", name)
+			continue
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		providers = append(providers, factory(m))
+	}
+	return providers
+}
+
+// hookProvider reads the .mastermind-status file written by harness
+// hooks and plugins. Readings older than the staleness threshold are
+// ignored.
+type hookProvider struct {
+	mtimeCache map[string]mtimeEntry       // worktreePath → cached hook status
+	own        map[string]*hook.StatusFile // agentID → last reading from the agent's own session
+}
+
+func newHookProvider() *hookProvider {
+	return &hookProvider{
+		mtimeCache: make(map[string]mtimeEntry),
+		own:        make(map[string]*hook.StatusFile),
+	}
+}
+
+func (p *hookProvider) Name() string { return ProviderHook }
+
+func (p *hookProvider) Observe(a *agent.Agent) State {
+	latest := p.readCached(a.WorktreePath)
+	a.SetHookStatus(latest)
+	sf := p.sessionReading(a, latest)
+	if sf == nil || sf.IsStale() {
+		return StateUnknown
+	}
+	switch sf.Status {
+	case hook.StatusRunning:
+		return StateRunning
+	case hook.StatusWaitingPermission:
+		return StateWaitingPermission
+	case hook.StatusWaitingInput, hook.StatusIdle, hook.StatusStopped:
+		return StateIdle
+	default:
+		return StateUnknown
+	}
+}
+
+// sessionReading picks the reading that describes the agent when agent
+// team members share its worktree. A teammate that is working or needs
+// permission keeps the agent busy, but a teammate stopping must not mark
+// the agent idle, so the agent's own last reading is used instead.
+// Readings without a session ID, or before the agent's session is known,
+// are taken as the agent's own.
+func (p *hookProvider) sessionReading(a *agent.Agent, sf *hook.StatusFile) *hook.StatusFile {
+	if sf == nil {
+		return nil
+	}
+	session := a.GetSessionID()
+	if sf.SessionID == "" || session == "" || sf.SessionID == session {
+		p.own[a.ID] = sf
+		return sf
+	}
+	switch sf.Status {
+	case hook.StatusRunning, hook.StatusWaitingPermission:
+		return sf
+	}
+	slog.Debug("ignoring teammate hook status", "id", a.ID, "session", sf.SessionID, "status", sf.Status, "event", sf.Event)
+	return p.own[a.ID]
+}
+
+// readCached reads the hook status file, using mtime to skip re-reads.
+func (p *hookProvider) readCached(worktreePath string) *hook.StatusFile {
+	path := filepath.Join(worktreePath, ".mastermind-status")
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	mtime := info.ModTime()
+	if cached, ok := p.mtimeCache[worktreePath]; ok && cached.mtime.Equal(mtime) {
+		if sf, ok := cached.result.(*hook.StatusFile); ok {
+			return sf
+		}
+		return nil
+	}
+	sf, err := hook.ReadStatus(worktreePath)
+	if err != nil {
+		slog.Debug("hook status read error", "path", worktreePath, "error", err)
+		p.mtimeCache[worktreePath] = mtimeEntry{mtime: mtime, result: (*hook.StatusFile)(nil)}
+		return nil
+	}
+	p.mtimeCache[worktreePath] = mtimeEntry{mtime: mtime, result: sf}
+	return sf
+}
+
+// streamProvider reads the state file written by `mastermind
+// stream-relay`. The relay sees every event, so its reading is never
+// considered stale while the file exists.
+type streamProvider struct {
+	mtimeCache map[string]mtimeEntry // worktreePath → cached relay state
+}
+
+func newStreamProvider() *streamProvider {
+	return &streamProvider{mtimeCache: make(map[string]mtimeEntry)}
+}
+
+func (p *streamProvider) Name() string { return ProviderStream }
+
+func (p *streamProvider) Observe(a *agent.Agent) State {
+	path := filepath.Join(a.WorktreePath, streamjson.StateFileName)
+	info, err := os.Stat(path)
+	if err != nil {
+		return StateUnknown
+	}
+	mtime := info.ModTime()
+	st, _ := p.mtimeCache[a.WorktreePath].result.(*streamjson.State)
+	if cached, ok := p.mtimeCache[a.WorktreePath]; !ok || !cached.mtime.Equal(mtime) {
+		st, err = streamjson.ReadState(a.WorktreePath)
+		if err != nil {
+			slog.Debug("stream state read error", "path", a.WorktreePath, "error", err)
+			st = nil
+		}
+		p.mtimeCache[a.WorktreePath] = mtimeEntry{mtime: mtime, result: st}
+	}
+	if st == nil {
+		return StateUnknown
+	}
+	switch st.Status {
+	case streamjson.StatusRunning:
+		return StateRunning
+	case streamjson.StatusIdle:
+		return StateIdle
+	default:
+		return StateUnknown
+	}
+}
+
+// paneProvider classifies the agent's tmux pane content.
+type paneProvider struct {
+	m *Monitor
+}
+
+func (p *paneProvider) Name() string { return ProviderPane }
+
+// classifyLines is how many lines from the bottom of a pane are matched
+// against a harness's own patterns.
+const classifyLines = 10
+
+func (p *paneProvider) Observe(a *agent.Agent) State {
+	waitingFor := ""
+	if c, ok := p.m.harnesses[a.Harness].(harness.PaneClassifier); ok {
+		waitingFor = c.ClassifyPane(p.m.panes.Tail(a.TmuxPaneID, classifyLines))
+	} else {
+		ps, err := p.m.panes.GetPaneStatus(a.TmuxPaneID)
+		if err != nil {
+			return StateGone
+		}
+		waitingFor = ps.WaitingFor
+	}
+	switch waitingFor {
+	case "":
+		return StateRunning
+	case "permission":
+		return StateWaitingPermission
+	default:
+		return StateIdle
+	}
+}
@@ -0,0 +1,34 @@
+package orchestrator
+
+import (
+	"log/slog"
+	"time"
+)
+
+// aheadBehindInterval is how often the monitor loop counts the commits
+// each agent's branch is ahead of and behind its base.
+const aheadBehindInterval = 10 * time.Second
+
+// refreshAheadBehind counts, at most every aheadBehindInterval, how many
+// commits each agent's branch is ahead of and behind its base, and keeps
+// them on the agent for the dashboard. Agents without a base, e.g. those
+// on an existing branch, are skipped.
+func (o *Orchestrator) refreshAheadBehind() {
+	if time.Since(o.aheadBehindAt) < aheadBehindInterval {
+		return
+	}
+	o.aheadBehindAt = time.Now()
+	for _, a := range o.store.All() {
+		base := a.GetBaseBranch()
+		if base == "" || a.Branch == "" {
+			continue
+		}
+		ahead, behind, err := o.git.AheadBehind(o.repoPath, a.Branch, base)
+		if err != nil {
+			// The branch may be gone, e.g. while the agent is dismissed.
+			slog.Debug("failed to count commits ahead of and behind base", "id", a.ID, "branch", a.Branch, "base", base, "error", err)
+			continue
+		}
+		a.SetAheadBehind(ahead, behind)
+	}
+}
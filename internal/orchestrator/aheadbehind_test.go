@@ -0,0 +1,34 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefreshAheadBehind(t *testing.T) {
+	mg := &mockGit{ahead: 2, behind: 5}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+	if _, _, ok := a.GetAheadBehind(); ok {
+		t.Fatal("counts known before they were counted")
+	}
+
+	o.refreshAheadBehind()
+	if ahead, behind, ok := a.GetAheadBehind(); !ok || ahead != 2 || behind != 5 {
+		t.Errorf("GetAheadBehind = %d, %d, %v, want 2, 5", ahead, behind, ok)
+	}
+
+	// Counted again only once the interval has passed.
+	mg.ahead = 4
+	o.refreshAheadBehind()
+	if ahead, _, _ := a.GetAheadBehind(); ahead != 2 {
+		t.Errorf("ahead = %d, want the cached 2", ahead)
+	}
+	o.aheadBehindAt = time.Now().Add(-aheadBehindInterval)
+	o.refreshAheadBehind()
+	if ahead, _, _ := a.GetAheadBehind(); ahead != 4 {
+		t.Errorf("ahead = %d, want 4 after the interval", ahead)
+	}
+}
@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+// WithArchiveBranches makes dismissals and merges archive agent branches
+// under refs/mastermind/archive/<date>/ instead of deleting them, so they
+// can be restored from the archive browser.
+func WithArchiveBranches(enabled bool) Option {
+	return func(o *Orchestrator) { o.archiveBranches = enabled }
+}
+
+// ArchivesBranches reports whether deleted agent branches are archived.
+func (o *Orchestrator) ArchivesBranches() bool {
+	return o.archiveBranches
+}
+
+// removeBranch deletes an agent's branch, or archives it when branches
+// are archived.
+func (o *Orchestrator) removeBranch(branch string) error {
+	if !o.archiveBranches {
+		return o.git.DeleteBranch(o.repoPath, branch)
+	}
+	ref, err := o.git.ArchiveBranch(o.repoPath, branch)
+	if err != nil {
+		return err
+	}
+	slog.Info("archived branch", "branch", branch, "ref", ref)
+	return nil
+}
+
+// ArchivedBranches lists the archived branches, most recently archived
+// first.
+func (o *Orchestrator) ArchivedBranches() ([]git.ArchivedBranch, error) {
+	return o.git.ListArchived(o.repoPath)
+}
+
+// RestoreArchivedBranch recreates the branch archived as ref and returns
+// its name.
+func (o *Orchestrator) RestoreArchivedBranch(ref string) (string, error) {
+	branch, err := o.git.RestoreArchived(o.repoPath, ref)
+	if err != nil {
+		return "", fmt.Errorf("restore %s: %w", ref, err)
+	}
+	slog.Info("restored archived branch", "ref", ref, "branch", branch)
+	return branch, nil
+}
+
+// DeleteArchivedBranch drops ref from the archive; its commits are then
+// left to git's garbage collection.
+func (o *Orchestrator) DeleteArchivedBranch(ref string) error {
+	if err := o.git.DeleteArchived(o.repoPath, ref); err != nil {
+		return err
+	}
+	slog.Info("deleted archived branch", "ref", ref)
+	return nil
+}
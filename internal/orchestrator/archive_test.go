@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+func TestDismissAgent_ArchivesBranch(t *testing.T) {
+	mg := &mockGit{unmergedCommits: 2}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	o.archiveBranches = true
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	if err := o.DismissAgent(a.ID, true); err != nil {
+		t.Fatalf("DismissAgent: %v", err)
+	}
+	if !mg.hasCalled("ArchiveBranch:feat/x") || mg.hasCalled("DeleteBranch:feat/x") {
+		t.Errorf("calls = %v, want the branch archived, not deleted", mg.calls)
+	}
+}
+
+func TestMergeAgent_ArchivesBranch(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123"}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	o.archiveBranches = true
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	if result := o.MergeAgent(a.ID, true, true, false, MergeCommit, ""); !result.Success {
+		t.Fatalf("MergeAgent = %+v, want success", result)
+	}
+	if !mg.hasCalled("ArchiveBranch:feat/x") || mg.hasCalled("DeleteBranch:feat/x") {
+		t.Errorf("calls = %v, want the branch archived, not deleted", mg.calls)
+	}
+}
+
+func TestRestoreArchivedBranch(t *testing.T) {
+	ref := "refs/mastermind/archive/2026-01-02/feat/x"
+	mg := &mockGit{archived: []git.ArchivedBranch{{Ref: ref, Branch: "feat/x", Date: "2026-01-02"}}}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+
+	archived, err := o.ArchivedBranches()
+	if err != nil || len(archived) != 1 {
+		t.Fatalf("ArchivedBranches = %v, %v", archived, err)
+	}
+	branch, err := o.RestoreArchivedBranch(ref)
+	if err != nil || branch != "feat/x" {
+		t.Errorf("RestoreArchivedBranch = %q, %v, want feat/x", branch, err)
+	}
+	if _, err := o.RestoreArchivedBranch("refs/mastermind/archive/2026-01-02/feat/y"); err == nil {
+		t.Error("expected error for a ref not in the archive")
+	}
+}
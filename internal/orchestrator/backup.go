@@ -0,0 +1,114 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+// backupTimeFormat names a backup directory after when it was taken.
+const backupTimeFormat = "20060102-150405"
+
+// WithBackupRetention sets how long backups of uncommitted changes,
+// taken before a worktree is removed or a preview discards its changes,
+// are kept in .worktrees/backups. Zero takes no backups.
+func WithBackupRetention(d time.Duration) Option {
+	return func(o *Orchestrator) { o.backupRetention = d }
+}
+
+// backupChanges saves the uncommitted changes in wtPath to
+// <backups>/<name>/<timestamp> before they are discarded, and returns
+// the backup's directory, or "" when there was nothing to save.
+func (o *Orchestrator) backupChanges(name, wtPath string) (string, error) {
+	if o.backupRetention <= 0 {
+		return "", nil
+	}
+	base := filepath.Join(o.backupsDir, git.WorktreeDirName(name), time.Now().Format(backupTimeFormat))
+	dir := base
+	for n := 2; ; n++ {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			break
+		}
+		dir = fmt.Sprintf("%s-%d", base, n)
+	}
+	saved, err := o.git.BackupChanges(wtPath, dir)
+	if err != nil {
+		return "", err
+	}
+	if !saved {
+		return "", nil
+	}
+	slog.Info("backed up uncommitted changes", "path", wtPath, "backup", dir)
+	o.pruneBackups()
+	return dir, nil
+}
+
+// backupPreview backs up the changes in the main worktree before a
+// preview of agent id discards them, under the agent's branch. A failed
+// backup is logged and does not stop the preview.
+func (o *Orchestrator) backupPreview(id string) {
+	name := o.agentBranch(id)
+	if name == "" {
+		name = previewBranch(id)
+	}
+	if _, err := o.backupChanges(name, o.repoPath); err != nil {
+		slog.Error("failed to back up uncommitted changes", "path", o.repoPath, "error", err)
+	}
+}
+
+// removeWorktree backs up the uncommitted changes in the worktree of
+// branch, then force-removes it. The worktree is kept if the backup
+// fails, as its changes would be lost.
+func (o *Orchestrator) removeWorktree(branch, wtPath string) error {
+	if _, err := o.backupChanges(branch, wtPath); err != nil {
+		return fmt.Errorf("back up uncommitted changes, keeping the worktree: %w", err)
+	}
+	return o.git.RemoveWorktree(o.repoPath, wtPath)
+}
+
+// pruneBackups deletes the backups older than the retention period, and
+// the directories of agents left without any.
+func (o *Orchestrator) pruneBackups() {
+	if o.backupRetention <= 0 {
+		return
+	}
+	agents, err := os.ReadDir(o.backupsDir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-o.backupRetention)
+	for _, a := range agents {
+		if !a.IsDir() {
+			continue
+		}
+		agentDir := filepath.Join(o.backupsDir, a.Name())
+		backups, err := os.ReadDir(agentDir)
+		if err != nil {
+			continue
+		}
+		kept := len(backups)
+		for _, b := range backups {
+			// A suffix tells apart backups taken in the same second.
+			stamp := b.Name()
+			if len(stamp) > len(backupTimeFormat) {
+				stamp = stamp[:len(backupTimeFormat)]
+			}
+			taken, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+			if err != nil || !taken.Before(cutoff) {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(agentDir, b.Name())); err != nil {
+				slog.Warn("failed to remove expired backup", "backup", filepath.Join(agentDir, b.Name()), "error", err)
+				continue
+			}
+			kept--
+		}
+		if kept == 0 {
+			os.Remove(agentDir)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDismissAgent_BacksUpChanges(t *testing.T) {
+	mg := &mockGit{hasChangesResult: true}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	if err := o.DismissAgent(a.ID, false); err != nil {
+		t.Fatalf("DismissAgent: %v", err)
+	}
+	backup := slices.Index(mg.calls, "BackupChanges:"+a.WorktreePath)
+	remove := slices.Index(mg.calls, "RemoveWorktree:"+a.WorktreePath)
+	if backup < 0 || remove < backup {
+		t.Errorf("calls = %v, want the changes backed up before the worktree is removed", mg.calls)
+	}
+}
+
+func TestDismissAgent_KeepsWorktreeWhenBackupFails(t *testing.T) {
+	mg := &mockGit{hasChangesResult: true, backupErr: errors.New("disk full")}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	if err := o.DismissAgent(a.ID, true); err != nil {
+		t.Fatalf("DismissAgent: %v", err)
+	}
+	if mg.hasCalled("RemoveWorktree:"+a.WorktreePath) || mg.hasCalled("DeleteBranch:feat/x") {
+		t.Errorf("calls = %v, want the worktree and its branch kept", mg.calls)
+	}
+}
+
+func TestDismissAgent_NoBackupsWhenDisabled(t *testing.T) {
+	mg := &mockGit{hasChangesResult: true}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	o.backupRetention = 0
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	if err := o.DismissAgent(a.ID, false); err != nil {
+		t.Fatalf("DismissAgent: %v", err)
+	}
+	if mg.hasCalled("BackupChanges:" + a.WorktreePath) {
+		t.Errorf("calls = %v, want no backup", mg.calls)
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	o.backupRetention = 24 * time.Hour
+
+	old := time.Now().Add(-48 * time.Hour).Format(backupTimeFormat)
+	recent := time.Now().Add(-time.Hour).Format(backupTimeFormat)
+	for _, dir := range []string{"feat__x/" + old, "feat__x/" + recent, "feat__y/" + old, "feat__y/" + old + "-2"} {
+		if err := os.MkdirAll(filepath.Join(o.backupsDir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o.pruneBackups()
+
+	if _, err := os.Stat(filepath.Join(o.backupsDir, "feat__x", recent)); err != nil {
+		t.Errorf("recent backup should be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(o.backupsDir, "feat__x", old)); !os.IsNotExist(err) {
+		t.Error("expired backup should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(o.backupsDir, "feat__y")); !os.IsNotExist(err) {
+		t.Error("an agent's directory should be removed with its last backup")
+	}
+}
@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// DoneAgent is an agent a bulk dismissal offers to dismiss.
+type DoneAgent struct {
+	AgentID string
+	Branch  string
+	// Merged means the agent is not done but its branch is already merged
+	// into its base, e.g. by a merge done outside mastermind.
+	Merged bool
+}
+
+// BulkDismissMsg reports the outcome of DismissAgents.
+type BulkDismissMsg struct {
+	Dismissed []string
+	Errors    []string
+}
+
+// DoneAgents lists the agents in StatusDone, then the idle agents waiting
+// for review whose branch is merged into their base, which linger once
+// their work landed.
+func (o *Orchestrator) DoneAgents() []DoneAgent {
+	agents := o.store.All()
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+	var done, merged []DoneAgent
+	for _, a := range agents {
+		switch a.GetStatus() {
+		case agent.StatusDone:
+			done = append(done, DoneAgent{AgentID: a.ID, Branch: a.Branch})
+		case agent.StatusReviewReady, agent.StatusReviewed:
+			base := a.GetBaseBranch()
+			if base != "" && o.git.IsBranchMerged(o.repoPath, a.Branch, base) {
+				merged = append(merged, DoneAgent{AgentID: a.ID, Branch: a.Branch, Merged: true})
+			}
+		}
+	}
+	return append(done, merged...)
+}
+
+// DismissAgents dismisses the agents with the given IDs one after the
+// other, carrying on past the ones that fail. Agents run here are stopped
+// in parallel first, rather than waited for one by one.
+func (o *Orchestrator) DismissAgents(ids []string, deleteBranch bool) BulkDismissMsg {
+	var msg BulkDismissMsg
+	if o.remote == nil {
+		o.stopAgents(ids)
+	}
+	for _, id := range ids {
+		if err := o.DismissAgent(id, deleteBranch); err != nil {
+			msg.Errors = append(msg.Errors, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		msg.Dismissed = append(msg.Dismissed, id)
+	}
+	slog.Info("bulk dismissed agents", "dismissed", len(msg.Dismissed), "failed", len(msg.Errors), "deleteBranch", deleteBranch)
+	return msg
+}
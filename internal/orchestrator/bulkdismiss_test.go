@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func TestDoneAgents(t *testing.T) {
+	mg := &mockGit{isBranchMergedResult: true}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	for _, tc := range []struct {
+		id     string
+		status agent.Status
+	}{
+		{"1", agent.StatusReviewed},
+		{"2", agent.StatusDone},
+		{"3", agent.StatusRunning},
+		{"4", agent.StatusReviewReady},
+	} {
+		a := agent.NewAgent("feat/"+tc.id, "main", "/wt"+tc.id, "@"+tc.id, "%"+tc.id, "claude")
+		a.ID = tc.id
+		a.SetStatus(tc.status)
+		o.store.Add(a)
+	}
+
+	got := o.DoneAgents()
+	want := []DoneAgent{
+		{AgentID: "2", Branch: "feat/2"},
+		{AgentID: "1", Branch: "feat/1", Merged: true},
+		{AgentID: "4", Branch: "feat/4", Merged: true},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("DoneAgents() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDismissAgents_ContinuesPastFailures(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	a.SetStatus(agent.StatusDone)
+	o.store.Add(a)
+
+	msg := o.DismissAgents([]string{"missing", a.ID}, false)
+	if !slices.Equal(msg.Dismissed, []string{a.ID}) || len(msg.Errors) != 1 {
+		t.Errorf("DismissAgents = %+v, want %s dismissed and one error", msg, a.ID)
+	}
+	if _, ok := o.store.Get(a.ID); ok {
+		t.Error("agent still tracked after bulk dismiss")
+	}
+}
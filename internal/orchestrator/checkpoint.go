@@ -0,0 +1,82 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+// CheckpointMsg reports the outcome of CheckpointAgent.
+type CheckpointMsg struct {
+	AgentID string
+	Tag     string
+	Error   string
+}
+
+// CheckpointAgent tags a snapshot of the agent's branch and worktree,
+// uncommitted changes included, to roll back to if the agent goes off the
+// rails. The agent keeps working undisturbed.
+func (o *Orchestrator) CheckpointAgent(id, label string) CheckpointMsg {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return CheckpointMsg{AgentID: id, Error: fmt.Sprintf("agent %s not found", id)}
+	}
+	cp, err := o.git.CreateCheckpoint(a.WorktreePath, a.Branch, label)
+	if err != nil {
+		return CheckpointMsg{AgentID: id, Error: err.Error()}
+	}
+	slog.Info("checkpoint created", "agent", id, "tag", cp.Tag, "commit", cp.Commit)
+	return CheckpointMsg{AgentID: id, Tag: cp.Tag}
+}
+
+// RollbackMsg reports the outcome of RollbackAgent. Saved is the
+// checkpoint holding the agent's state from before the rollback.
+type RollbackMsg struct {
+	AgentID string
+	Tag     string
+	Saved   string
+	Error   string
+}
+
+// Checkpoints lists the agent's checkpoints, newest first.
+func (o *Orchestrator) Checkpoints(id string) ([]git.Checkpoint, error) {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("agent %s not found", id)
+	}
+	return o.git.ListCheckpoints(o.repoPath, a.Branch)
+}
+
+// RollbackAgent resets the agent's branch and worktree to the checkpoint
+// tag. The current state is checkpointed first, so the rollback can itself
+// be undone, and the agent is told that its history was rewound.
+func (o *Orchestrator) RollbackAgent(id, tag string) RollbackMsg {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return RollbackMsg{AgentID: id, Error: fmt.Sprintf("agent %s not found", id)}
+	}
+	switch status := a.GetStatus(); status {
+	case agent.StatusPreviewing, agent.StatusConflicts:
+		return RollbackMsg{AgentID: id, Error: fmt.Sprintf("agent %s cannot be rolled back while %s", id, status)}
+	}
+
+	saved, err := o.git.CreateCheckpoint(a.WorktreePath, a.Branch, "before rollback to "+tag)
+	if err != nil {
+		return RollbackMsg{AgentID: id, Error: fmt.Sprintf("save current state: %v", err)}
+	}
+	if err := o.git.RestoreCheckpoint(a.WorktreePath, tag); err != nil {
+		return RollbackMsg{AgentID: id, Saved: saved.Tag, Error: err.Error()}
+	}
+	slog.Info("agent rolled back", "agent", id, "tag", tag, "saved", saved.Tag)
+
+	if a.TmuxPaneID != "" && o.tmux.PaneExistsInWindow(a.TmuxPaneID, a.TmuxWindow) {
+		notice := fmt.Sprintf("Your branch %s was rolled back to checkpoint %s. Commits and file changes made since then are gone "+
+			"(saved as %s). Re-read the files you are working on before continuing.", a.Branch, tag, saved.Tag)
+		if err := o.tmux.SendKeys(a.TmuxPaneID, notice, "Enter"); err != nil {
+			slog.Warn("failed to notify agent of rollback", "agent", id, "error", err)
+		}
+	}
+	return RollbackMsg{AgentID: id, Tag: tag, Saved: saved.Tag}
+}
@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func TestCheckpointAgent(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	a := agent.NewAgent("feat/x", "main", "/wt/feat__x", "@1", "%1", "claude")
+	o.store.Add(a)
+
+	msg := o.CheckpointAgent(a.ID, "")
+	if msg.Error != "" || msg.Tag != "checkpoint/feat/x/1" {
+		t.Errorf("CheckpointAgent = %+v", msg)
+	}
+
+	mg.checkpointErr = fmt.Errorf("commit-tree failed")
+	if msg := o.CheckpointAgent(a.ID, ""); msg.Error == "" {
+		t.Error("expected the git error to be reported")
+	}
+	if msg := o.CheckpointAgent("nope", ""); msg.Error == "" {
+		t.Error("expected an error for an unknown agent")
+	}
+}
+
+func TestRollbackAgent(t *testing.T) {
+	mg := &mockGit{}
+	mt := &mockTmux{paneExistsResult: true}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+	a := agent.NewAgent("feat/x", "main", "/wt/feat__x", "@1", "%1", "claude")
+	o.store.Add(a)
+
+	msg := o.RollbackAgent(a.ID, "checkpoint/feat/x/0")
+	if msg.Error != "" || msg.Saved != "checkpoint/feat/x/1" {
+		t.Fatalf("RollbackAgent = %+v", msg)
+	}
+	if !mg.hasCalled("CreateCheckpoint:feat/x:before rollback to checkpoint/feat/x/0") {
+		t.Error("expected the current state to be checkpointed first")
+	}
+	if !mg.hasCalled("RestoreCheckpoint:/wt/feat__x:checkpoint/feat/x/0") {
+		t.Error("expected the checkpoint to be restored")
+	}
+	if len(mt.sentKeys) != 1 || !strings.Contains(mt.sentKeys[0], "rolled back to checkpoint checkpoint/feat/x/0") ||
+		!strings.HasSuffix(mt.sentKeys[0], "Enter") {
+		t.Errorf("sent keys = %q, want a rollback notice", mt.sentKeys)
+	}
+}
+
+func TestRollbackAgent_Refuses(t *testing.T) {
+	mg := &mockGit{}
+	mt := &mockTmux{paneExistsResult: true}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+	a := agent.NewAgent("feat/x", "main", "/wt/feat__x", "@1", "%1", "claude")
+	o.store.Add(a)
+
+	a.SetStatus(agent.StatusConflicts)
+	if msg := o.RollbackAgent(a.ID, "checkpoint/feat/x/0"); msg.Error == "" {
+		t.Error("expected rollback to be refused during a conflicted merge")
+	}
+
+	a.SetStatus(agent.StatusRunning)
+	mg.checkpointErr = fmt.Errorf("disk full")
+	if msg := o.RollbackAgent(a.ID, "checkpoint/feat/x/0"); msg.Error == "" {
+		t.Error("expected rollback to fail when the current state cannot be saved")
+	}
+	if mg.hasCalled("RestoreCheckpoint:/wt/feat__x:checkpoint/feat/x/0") {
+		t.Error("restored without saving the current state")
+	}
+	if len(mt.sentKeys) != 0 {
+		t.Errorf("agent notified of a rollback that did not happen: %q", mt.sentKeys)
+	}
+}
@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// cleanupInterval is how often the cleanup policy is applied.
+const cleanupInterval = time.Minute
+
+// CleanupPolicy selects the agents the monitor dismisses on its own.
+type CleanupPolicy struct {
+	// DoneAfter dismisses agents that have been done this long. 0
+	// disables it.
+	DoneAfter time.Duration
+	// DeleteBranches also deletes the branches of agents dismissed for
+	// being done.
+	DeleteBranches bool
+	// Merged dismisses the agents whose branch is merged into their base
+	// once a day, MergedAt past midnight, and deletes their branches.
+	Merged   bool
+	MergedAt time.Duration
+}
+
+// WithCleanupPolicy makes the monitor dismiss agents by policy. Each
+// dismissal is reported as a monitor.AutoCleanup event, which the daemon
+// writes to its event log.
+func WithCleanupPolicy(p CleanupPolicy) Option {
+	return func(o *Orchestrator) { o.cleanup = p }
+}
+
+// watchCleanup applies the cleanup policy every cleanupInterval until the
+// orchestrator's context is cancelled. It runs apart from the monitor loop
+// because a dismissal waits for the agent's processes to exit.
+func (o *Orchestrator) watchCleanup() {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	nextMerged := nextDaily(time.Now(), o.cleanup.MergedAt)
+	for {
+		select {
+		case <-o.ctx.Done():
+			return
+		case now := <-ticker.C:
+			o.cleanupDone(now)
+			if o.cleanup.Merged && !now.Before(nextMerged) {
+				o.cleanupMerged()
+				nextMerged = nextDaily(now, o.cleanup.MergedAt)
+			}
+		}
+	}
+}
+
+// nextDaily returns the first time after now that is at past midnight.
+func nextDaily(now time.Time, at time.Duration) time.Time {
+	y, m, d := now.Date()
+	mins := int(at / time.Minute)
+	t := time.Date(y, m, d, 0, mins, 0, 0, now.Location())
+	if !t.After(now) {
+		t = time.Date(y, m, d+1, 0, mins, 0, 0, now.Location())
+	}
+	return t
+}
+
+// cleanupDone dismisses the agents that have been done for longer than
+// the policy allows.
+func (o *Orchestrator) cleanupDone(now time.Time) {
+	if o.cleanup.DoneAfter <= 0 {
+		return
+	}
+	for _, a := range o.store.All() {
+		if a.GetStatus() != agent.StatusDone || o.cleanupFailed[a.ID] {
+			continue
+		}
+		since := a.GetReadyAt()
+		if since.IsZero() {
+			since = a.GetFinishedAt()
+		}
+		if since.IsZero() || now.Sub(since) < o.cleanup.DoneAfter {
+			continue
+		}
+		o.autoDismiss(a, "done", o.cleanup.DeleteBranches)
+	}
+}
+
+// cleanupMerged dismisses the finished agents whose branch is merged into
+// their base and deletes their branches.
+func (o *Orchestrator) cleanupMerged() {
+	for _, a := range o.store.All() {
+		switch a.GetStatus() {
+		case agent.StatusDone, agent.StatusReviewReady, agent.StatusReviewed:
+		default:
+			continue
+		}
+		base := a.GetBaseBranch()
+		if o.cleanupFailed[a.ID] || base == "" || !o.git.IsBranchMerged(o.repoPath, a.Branch, base) {
+			continue
+		}
+		o.autoDismiss(a, "merged", true)
+	}
+}
+
+// autoDismiss dismisses a on behalf of a cleanup rule and reports it. An
+// agent that fails to dismiss is not tried again, so the event log does
+// not fill up with the same failure.
+func (o *Orchestrator) autoDismiss(a *agent.Agent, rule string, deleteBranch bool) {
+	ev := monitor.AutoCleanup{AgentID: a.ID, Branch: a.Branch, Rule: rule, DeleteBranch: deleteBranch}
+	if err := o.dismissAgent(a.ID, deleteBranch); err != nil {
+		slog.Warn("auto cleanup failed", "id", a.ID, "rule", rule, "error", err)
+		if o.cleanupFailed == nil {
+			o.cleanupFailed = make(map[string]bool)
+		}
+		o.cleanupFailed[a.ID] = true
+		ev.Error = err.Error()
+	} else {
+		slog.Info("auto cleanup dismissed agent", "id", a.ID, "branch", a.Branch, "rule", rule, "deleteBranch", deleteBranch)
+	}
+	o.handleMonitorEvent(ev)
+}
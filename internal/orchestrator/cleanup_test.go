@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+func TestCleanupDone_DismissesAfterDelay(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	o.cleanup = CleanupPolicy{DoneAfter: 30 * time.Minute}
+	now := time.Now()
+
+	old := agent.NewAgent("feat/old", "main", "/wt1", "@1", "%1", "claude")
+	old.SetStatus(agent.StatusDone)
+	old.SetReadyAt(now.Add(-time.Hour))
+	o.store.Add(old)
+	recent := agent.NewAgent("feat/recent", "main", "/wt2", "@2", "%2", "claude")
+	recent.SetStatus(agent.StatusDone)
+	recent.SetReadyAt(now.Add(-10 * time.Minute))
+	o.store.Add(recent)
+	review := agent.NewAgent("feat/review", "main", "/wt3", "@3", "%3", "claude")
+	review.SetStatus(agent.StatusReviewReady)
+	review.SetReadyAt(now.Add(-time.Hour))
+	o.store.Add(review)
+
+	events := o.Events()
+	o.cleanupDone(now)
+
+	if _, ok := o.store.Get(old.ID); ok {
+		t.Error("agent done for an hour still tracked")
+	}
+	for _, a := range []*agent.Agent{recent, review} {
+		if _, ok := o.store.Get(a.ID); !ok {
+			t.Errorf("agent %s (%s) dismissed, want it kept", a.ID, a.GetStatus())
+		}
+	}
+	ev := (<-events).(monitor.AutoCleanup)
+	if ev.AgentID != old.ID || ev.Rule != "done" || ev.DeleteBranch || ev.Error != "" {
+		t.Errorf("event = %+v, want agent %s dismissed by the done rule", ev, old.ID)
+	}
+	if mg.hasCalled("DeleteBranch:feat/old") {
+		t.Error("branch deleted without delete_branches")
+	}
+}
+
+func TestCleanupMerged_DeletesMergedBranches(t *testing.T) {
+	mg := &mockGit{isBranchMergedResult: true}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	o.cleanup = CleanupPolicy{Merged: true}
+
+	reviewed := agent.NewAgent("feat/landed", "main", "/wt1", "@1", "%1", "claude")
+	reviewed.SetStatus(agent.StatusReviewed)
+	o.store.Add(reviewed)
+	running := agent.NewAgent("feat/busy", "main", "/wt2", "@2", "%2", "claude")
+	running.SetStatus(agent.StatusRunning)
+	o.store.Add(running)
+
+	o.cleanupMerged()
+
+	if _, ok := o.store.Get(reviewed.ID); ok {
+		t.Error("merged agent still tracked")
+	}
+	if !mg.hasCalled("DeleteBranch:feat/landed") {
+		t.Errorf("calls = %v, want the merged branch deleted", mg.calls)
+	}
+	if _, ok := o.store.Get(running.ID); !ok {
+		t.Error("running agent dismissed by the merged rule")
+	}
+}
+
+func TestNextDaily(t *testing.T) {
+	at := 3 * time.Hour
+	for _, tc := range []struct {
+		now, want time.Time
+	}{
+		{time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 3, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 3, 0, 0, 0, time.UTC)},
+	} {
+		if got := nextDaily(tc.now, at); !got.Equal(tc.want) {
+			t.Errorf("nextDaily(%s) = %s, want %s", tc.now, got, tc.want)
+		}
+	}
+}
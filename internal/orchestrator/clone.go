@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/playbook"
+)
+
+// CloneMode selects what a cloned agent starts from.
+type CloneMode int
+
+const (
+	// CloneFork branches off the agent's branch, carrying over its
+	// uncommitted changes, so the clone continues from where it is.
+	CloneFork CloneMode = iota
+	// CloneFresh branches off the agent's base and gives the clone the
+	// agent's initial prompt, to attempt the same task another way.
+	CloneFresh
+)
+
+// CloneAgent spawns a new agent on branch from the agent with the given
+// ID, leaving the original untouched. Either way the clone has the same
+// base branch, harness, ticket, playbook and read-only or report mode, so
+// it merges where the original would.
+func (o *Orchestrator) CloneAgent(id, branch string, mode CloneMode) error {
+	err := o.cloneAgent(id, branch, mode)
+	o.record(ipc.OpClone, o.agentBranch(id), ipc.CloneParams{ID: id, Branch: branch, Fresh: mode == CloneFresh}, err)
+	return err
+}
+
+func (o *Orchestrator) cloneAgent(id, branch string, mode CloneMode) error {
+	handled, err := o.remoteCall(func(c *ipc.Client) error {
+		return c.Clone(ipc.CloneParams{ID: id, Branch: branch, Fresh: mode == CloneFresh})
+	})
+	if handled {
+		return err
+	}
+
+	a, ok := o.store.Get(id)
+	if !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	base := a.GetBaseBranch()
+	req := spawnRequest{
+		branch:       branch,
+		baseBranch:   base,
+		createBranch: true,
+		harness:      a.Harness,
+		ticket:       a.Ticket,
+		readOnly:     a.ReadOnly,
+		report:       a.ReportFile != "",
+	}
+	if a.Playbook != "" {
+		p, err := playbook.Load(a.Playbook)
+		if err != nil {
+			return fmt.Errorf("load playbook: %w", err)
+		}
+		req.playbook = &p
+	}
+	switch mode {
+	case CloneFork:
+		req.startPoint = a.Branch
+		req.copyFrom = a.WorktreePath
+	case CloneFresh:
+		if base == "" {
+			return fmt.Errorf("agent %s has no base branch to start a fresh clone from", id)
+		}
+		req.prompt = a.Prompt
+	}
+	if err := o.spawnAgent(req); err != nil {
+		return err
+	}
+	slog.Info("agent cloned", "from", id, "branch", branch, "fresh", mode == CloneFresh)
+	return nil
+}
+
+// CloneBranchName suggests a free branch name for a clone of branch.
+func (o *Orchestrator) CloneBranchName(branch string) string {
+	inUse := o.AgentBranches()
+	name := branch + "-alt"
+	for n := 2; o.git.BranchExists(o.repoPath, name) || inUse[name] != ""; n++ {
+		name = fmt.Sprintf("%s-alt%d", branch, n)
+	}
+	return name
+}
@@ -0,0 +1,76 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func TestCloneAgent_Fork(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@2"}, &mockMonitor{})
+	orig := agent.NewAgent("feat/x", "main", "/wt/feat__x", "@1", "%1", "claude")
+	orig.Ticket = "ENG-1"
+	o.store.Add(orig)
+
+	if err := o.CloneAgent(orig.ID, "feat/x-alt", CloneFork); err != nil {
+		t.Fatalf("CloneAgent: %v", err)
+	}
+	if mg.createBranchFrom != "feat/x" {
+		t.Errorf("clone branched from %q, want the agent's branch", mg.createBranchFrom)
+	}
+	if !mg.hasCalled("CopyUncommittedChanges") {
+		t.Error("expected the agent's uncommitted changes to be copied")
+	}
+	var clone *agent.Agent
+	for _, a := range o.store.All() {
+		if a.Branch == "feat/x-alt" {
+			clone = a
+		}
+	}
+	if clone == nil {
+		t.Fatal("clone not added to the store")
+	}
+	if clone.GetBaseBranch() != "main" || clone.Ticket != "ENG-1" {
+		t.Errorf("clone base = %q, ticket = %q; want main, ENG-1", clone.GetBaseBranch(), clone.Ticket)
+	}
+}
+
+func TestCloneAgent_Fresh(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@2"}, &mockMonitor{})
+	orig := agent.NewAgent("feat/x", "main", "/wt/feat__x", "@1", "%1", "claude")
+	orig.Prompt = "Fix the flaky login test"
+	o.store.Add(orig)
+
+	if err := o.CloneAgent(orig.ID, "feat/x-alt", CloneFresh); err != nil {
+		t.Fatalf("CloneAgent: %v", err)
+	}
+	if mg.createBranchFrom != "main" {
+		t.Errorf("clone branched from %q, want the base", mg.createBranchFrom)
+	}
+	if mg.hasCalled("CopyUncommittedChanges") {
+		t.Error("a fresh clone should not copy uncommitted changes")
+	}
+	for _, a := range o.store.All() {
+		if a.Branch == "feat/x-alt" && a.Prompt != orig.Prompt {
+			t.Errorf("clone prompt = %q, want %q", a.Prompt, orig.Prompt)
+		}
+	}
+
+	noBase := agent.NewAgent("fix/y", "", "/wt/fix__y", "@3", "%3", "claude")
+	o.store.Add(noBase)
+	if err := o.CloneAgent(noBase.ID, "fix/y-alt", CloneFresh); err == nil || !strings.Contains(err.Error(), "no base branch") {
+		t.Errorf("err = %v, want refusal without a base branch", err)
+	}
+}
+
+func TestCloneBranchName(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	o.store.Add(agent.NewAgent("feat/x-alt", "main", "/wt/a", "@1", "%1", "claude"))
+
+	if got := o.CloneBranchName("feat/x"); got != "feat/x-alt2" {
+		t.Errorf("CloneBranchName = %q, want feat/x-alt2", got)
+	}
+}
@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+// ConflictFile is a file left conflicted by an agent's merge.
+type ConflictFile struct {
+	git.Conflict
+	// Large is set when a side is bigger than the WithLargeConflictSize
+	// threshold, so resolving it in an editor is impractical.
+	Large bool
+}
+
+// WithLargeConflictSize sets the size in bytes above which a conflicted
+// file is marked large. Zero marks none.
+func WithLargeConflictSize(n int64) Option {
+	return func(o *Orchestrator) { o.largeConflict = n }
+}
+
+// WithConflictEditor sets the command that opens a conflicted file, in
+// which {file} is replaced by the file's path and {line} by the line of
+// its first conflict marker. Empty uses $VISUAL or $EDITOR with
+// "+{line} {file}".
+func WithConflictEditor(command string) Option {
+	return func(o *Orchestrator) { o.conflictEditor = command }
+}
+
+// ConflictDetails lists the files conflicted in agent id's merge, noting
+// which are binary or large.
+func (o *Orchestrator) ConflictDetails(id string) ([]ConflictFile, error) {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("agent %s not found", id)
+	}
+	conflicts, err := o.git.ConflictDetails(a.WorktreePath)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]ConflictFile, len(conflicts))
+	for i, c := range conflicts {
+		files[i] = ConflictFile{Conflict: c, Large: o.largeConflict > 0 && c.Size > o.largeConflict}
+	}
+	return files, nil
+}
+
+// ResolveConflict resolves a conflicted file in agent id's merge by
+// keeping one side, git.Ours for the agent's version or git.Theirs for
+// base's. Once no conflicts remain the merge is committed and completed
+// as if the user had resolved it in lazygit.
+func (o *Orchestrator) ResolveConflict(id, path string, side git.ConflictSide) (MergeResultMsg, error) {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return MergeResultMsg{}, fmt.Errorf("agent %s not found", id)
+	}
+	if a.GetStatus() != agent.StatusConflicts {
+		return MergeResultMsg{}, fmt.Errorf("agent %s has no merge conflicts", id)
+	}
+	if err := o.git.ResolveConflict(a.WorktreePath, path, side); err != nil {
+		return MergeResultMsg{}, err
+	}
+	slog.Info("conflict resolved", "id", id, "path", path, "side", side)
+
+	remaining, err := o.git.ConflictFiles(a.WorktreePath)
+	if err != nil {
+		return MergeResultMsg{}, err
+	}
+	if len(remaining) > 0 {
+		return o.conflictResult(a), nil
+	}
+	if err := o.git.CommitMerge(a.WorktreePath); err != nil {
+		return MergeResultMsg{}, err
+	}
+	return o.finishConflictedMerge(a), nil
+}
+
+// OpenConflictInEditor opens a file conflicted in agent id's merge in the
+// conflict editor, at its first conflict marker, in a split of the
+// agent's window.
+func (o *Orchestrator) OpenConflictInEditor(id, path string) error {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	file := filepath.Join(a.WorktreePath, path)
+	command := editorCommand(o.conflictEditor, file, firstConflictLine(file))
+	if len(command) == 0 {
+		return fmt.Errorf("no conflict editor configured and $EDITOR is not set")
+	}
+	if err := o.tmux.SelectWindow(a.TmuxWindow); err != nil {
+		return fmt.Errorf("select window: %w", err)
+	}
+	if _, err := o.tmux.SplitWindow(a.TmuxPaneID, a.WorktreePath, true, o.LazygitSplit(), command); err != nil {
+		return fmt.Errorf("split window for editor: %w", err)
+	}
+	slog.Info("conflict opened in editor", "id", id, "path", path, "command", command)
+	return nil
+}
+
+// editorCommand builds the command opening file at line from template,
+// falling back to $VISUAL or $EDITOR.
+func editorCommand(template, file string, line int) []string {
+	if template == "" {
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			return nil
+		}
+		template = editor + " +{line} {file}"
+	}
+	r := strings.NewReplacer("{file}", file, "{line}", strconv.Itoa(line))
+	var command []string
+	for _, field := range strings.Fields(template) {
+		command = append(command, r.Replace(field))
+	}
+	return command
+}
+
+// firstConflictLine returns the line of the first conflict marker in
+// file, or 1 if there is none.
+func firstConflictLine(file string) int {
+	f, err := os.Open(file)
+	if err != nil {
+		return 1
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if strings.HasPrefix(scanner.Text(), "<<<<<<<") {
+			return n
+		}
+	}
+	return 1
+}
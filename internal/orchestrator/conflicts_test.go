@@ -0,0 +1,110 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+func TestResolveConflict(t *testing.T) {
+	mg := &mockGit{
+		headCommitResult:        "abc123",
+		mergeInWorktreeConflict: true,
+		conflictFilesResult:     []string{"logo.png", "dump.sql"},
+		conflictDetails: []git.Conflict{
+			{Path: "logo.png", Binary: true, Size: 2048},
+			{Path: "dump.sql", Size: 5 << 20},
+		},
+	}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	id := o.store.All()[0].ID
+	if res := o.MergeAgent(id, true, true, false, MergeCommit, ""); !res.Conflict {
+		t.Fatalf("MergeAgent = %+v, want conflicts", res)
+	}
+
+	files, err := o.ConflictDetails(id)
+	if err != nil {
+		t.Fatalf("ConflictDetails: %v", err)
+	}
+	if len(files) != 2 || files[0].Large || !files[1].Large {
+		t.Errorf("ConflictDetails = %+v, want dump.sql marked large", files)
+	}
+
+	res, err := o.ResolveConflict(id, "logo.png", git.Theirs)
+	if err != nil || !res.Conflict || len(res.ConflictFiles) != 1 {
+		t.Fatalf("ResolveConflict = %+v, %v; want dump.sql still conflicted", res, err)
+	}
+
+	mg.mergeInWorktreeConflict = false
+	res, err = o.ResolveConflict(id, "dump.sql", git.Ours)
+	if err != nil || !res.Success {
+		t.Fatalf("ResolveConflict = %+v, %v; want the merge completed", res, err)
+	}
+	if !mg.hasCalled("ResolveConflict:dump.sql:ours") || !mg.hasCalled("CommitMerge") {
+		t.Errorf("calls = %v, want the last conflict resolved and the merge committed", mg.calls)
+	}
+	if len(o.store.All()) != 0 {
+		t.Error("agent should be cleaned up after the merge")
+	}
+
+	if _, err := o.ResolveConflict(id, "dump.sql", git.Ours); err == nil {
+		t.Error("resolving a conflict of an unknown agent should fail")
+	}
+}
+
+func TestOpenConflictInEditor(t *testing.T) {
+	mt := &mockTmux{windowIDForPane: "@1"}
+	o := newTestOrch(t, &mockGit{headCommitResult: "abc123"}, mt, &mockMonitor{})
+	o.conflictEditor = "code --goto {file}:{line}"
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+
+	if err := o.OpenConflictInEditor(a.ID, "main.go"); err != nil {
+		t.Fatalf("OpenConflictInEditor: %v", err)
+	}
+	if !mt.hasCalled("SplitWindow:" + a.TmuxPaneID) {
+		t.Errorf("calls = %v, want the editor opened in a split", mt.calls)
+	}
+	if err := o.OpenConflictInEditor("nope", "main.go"); err == nil {
+		t.Error("opening a conflict of an unknown agent should fail")
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.go")
+	content := "package main\n\nfunc main() {\n<<<<<<< HEAD\n\tours()\n=======\n\ttheirs()\n>>>>>>> main\n}\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	line := firstConflictLine(file)
+	if line != 4 {
+		t.Errorf("firstConflictLine = %d, want 4", line)
+	}
+	if got := firstConflictLine(filepath.Join(t.TempDir(), "missing")); got != 1 {
+		t.Errorf("firstConflictLine of a missing file = %d, want 1", got)
+	}
+
+	got := editorCommand("code --goto {file}:{line}", file, line)
+	if want := []string{"code", "--goto", file + ":4"}; !slices.Equal(got, want) {
+		t.Errorf("editorCommand = %q, want %q", got, want)
+	}
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "nvim")
+	got = editorCommand("", file, line)
+	if want := []string{"nvim", "+4", file}; !slices.Equal(got, want) {
+		t.Errorf("editorCommand from $EDITOR = %q, want %q", got, want)
+	}
+	t.Setenv("EDITOR", "")
+	if got := editorCommand("", file, line); got != nil {
+		t.Errorf("editorCommand without an editor = %q, want none", got)
+	}
+}
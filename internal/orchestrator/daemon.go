@@ -0,0 +1,250 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"os"
+	"syscall"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/daemon"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// WithDaemon runs the orchestrator as the background monitor for the
+// repository. Every monitor event is appended to events, which clients
+// replay so their dashboards still show notifications, and changes a TUI
+// client writes to the state file are picked up on each poll. Both sides
+// merge the state file per agent under a file lock, so concurrent writers
+// don't drop each other's agents.
+func WithDaemon(events *daemon.EventLog) Option {
+	return func(o *Orchestrator) {
+		o.daemonMode = true
+		o.eventLog = events
+	}
+}
+
+// WithDaemonClient makes the orchestrator a thin client of a running
+// daemon: it stops polling tmux itself, follows the state file the daemon
+// writes and forwards the events logged at eventsPath to the TUI. Its own
+// changes are pushed back to the state file, merged as WithDaemon
+// describes. Spawns, merges and dismissals are sent to the daemon over
+// the socket at socketPath.
+func WithDaemonClient(eventsPath, socketPath string) Option {
+	return func(o *Orchestrator) {
+		o.daemonClient = true
+		o.eventsPath = eventsPath
+		o.eventsOffset = daemon.EndOffset(eventsPath)
+		o.remote = ipc.NewClient(socketPath)
+	}
+}
+
+// DaemonClient reports whether monitoring is left to a background daemon.
+func (o *Orchestrator) DaemonClient() bool {
+	return o.daemonClient
+}
+
+// followDaemon is the client's monitor tick: local changes are written
+// first so the daemon sees user actions, then the daemon's state and
+// events are pulled in. Nothing is written before the first pull, so a
+// client never overwrites the daemon's state with its own stale view.
+func (o *Orchestrator) followDaemon() {
+	o.syncMu.Lock()
+	synced := o.syncedAgents != nil
+	o.syncMu.Unlock()
+	if synced {
+		o.pushState()
+	}
+	o.pullState()
+
+	events, offset, err := daemon.ReadEvents(o.eventsPath, o.eventsOffset)
+	if err != nil {
+		slog.Warn("read daemon events failed", "error", err)
+		return
+	}
+	o.eventsOffset = offset
+	for _, ev := range events {
+		// The daemon already sent the OS notification; only the overview
+		// window belongs to this client.
+		if _, ok := ev.(monitor.Attention); ok {
+			o.markOverviewWindow()
+		}
+		o.bus.Publish(ev)
+	}
+}
+
+// pullState merges the state file into the store if someone else has
+// rewritten it since it was last read or written here.
+func (o *Orchestrator) pullState() {
+	o.syncMu.Lock()
+	defer o.syncMu.Unlock()
+	unlock := o.lockStateFile()
+	defer unlock()
+	o.pullStateLocked()
+}
+
+// pullStateLocked is pullState for a caller holding syncMu and the state
+// file lock.
+func (o *Orchestrator) pullStateLocked() {
+	info, err := os.Stat(o.statePath)
+	if err != nil || info.ModTime().Equal(o.stateModTime) {
+		return
+	}
+	persisted, err := agent.LoadState(o.statePath)
+	if err != nil {
+		slog.Warn("reload shared state failed", "error", err)
+		return
+	}
+	o.stateModTime = info.ModTime()
+	o.mergeState(persisted)
+	o.syncedAgents = make(map[string]string, len(persisted))
+	for _, pa := range persisted {
+		o.syncedAgents[pa.ID] = persistedKey(pa)
+	}
+	o.store.ClearDirty()
+}
+
+// pushState writes the store to the state file if it changed since the
+// file was last read or written here. What the other side wrote in the
+// meantime is merged in first, under the state file lock, so neither
+// side's changes are overwritten.
+func (o *Orchestrator) pushState() {
+	o.syncMu.Lock()
+	defer o.syncMu.Unlock()
+	unlock := o.lockStateFile()
+	defer unlock()
+	o.pullStateLocked()
+
+	local := make(map[string]string)
+	for _, a := range o.store.All() {
+		local[a.ID] = persistedKey(a.Persisted())
+	}
+	if o.syncedAgents != nil && maps.Equal(local, o.syncedAgents) {
+		return
+	}
+	o.doSaveState()
+	o.syncedAgents = local
+	if info, err := os.Stat(o.statePath); err == nil {
+		o.stateModTime = info.ModTime()
+	}
+	o.store.ClearDirty()
+}
+
+// lockStateFile takes an exclusive lock shared by every process syncing
+// the state file, and returns its release. A lock that can't be taken is
+// logged and syncing goes on without it.
+func (o *Orchestrator) lockStateFile() (unlock func()) {
+	f, err := os.OpenFile(o.statePath+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		slog.Warn("open state lock failed", "error", err)
+		return func() {}
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		slog.Warn("lock state file failed", "error", err)
+		f.Close()
+		return func() {}
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}
+}
+
+// mergeState merges persisted, as read from the state file, into the
+// store agent by agent, against the agents as last synced: an agent
+// changed here since then keeps its changes, one unchanged here takes the
+// file's, and one missing from the file is removed only if it was synced
+// before, i.e. the other side removed it. Agents this side created or
+// removed since the last sync stay so. Unlike RecoverAgents it trusts the
+// file, since the writer validated the agents.
+func (o *Orchestrator) mergeState(persisted []agent.PersistedAgent) {
+	seen := make(map[string]bool, len(persisted))
+	for _, pa := range persisted {
+		seen[pa.ID] = true
+		synced, wasSynced := o.syncedAgents[pa.ID]
+		if a, ok := o.store.Get(pa.ID); ok {
+			if wasSynced && persistedKey(a.Persisted()) != synced {
+				continue // changed here; pushed next
+			}
+			applyPersisted(a, pa)
+			continue
+		}
+		if wasSynced {
+			continue // removed here; dropped from the file next push
+		}
+		a := agentFromPersisted(pa)
+		o.store.Add(a)
+		o.mon.RefreshSidecars(a)
+		slog.Info("picked up agent from shared state", "id", a.ID, "branch", a.Branch)
+	}
+	for _, a := range o.store.All() {
+		if _, wasSynced := o.syncedAgents[a.ID]; !seen[a.ID] && wasSynced {
+			o.store.Remove(a.ID)
+			slog.Info("agent removed in shared state", "id", a.ID)
+		}
+	}
+}
+
+// persistedKey serializes pa to tell whether an agent changed.
+func persistedKey(pa agent.PersistedAgent) string {
+	data, _ := json.Marshal(pa)
+	return string(data)
+}
+
+// agentFromPersisted rebuilds an agent from its persisted form.
+func agentFromPersisted(pa agent.PersistedAgent) *agent.Agent {
+	a := &agent.Agent{
+		ID:           pa.ID,
+		Branch:       pa.Branch,
+		BaseBranch:   pa.BaseBranch,
+		WorktreePath: pa.WorktreePath,
+		TmuxWindow:   pa.TmuxWindow,
+		TmuxPaneID:   pa.TmuxPaneID,
+		Harness:      pa.Harness,
+		Ticket:       pa.Ticket,
+		Prompt:       pa.Prompt,
+		ReadOnly:     pa.ReadOnly,
+		ReportFile:   pa.ReportFile,
+		Playbook:     pa.Playbook,
+		Team:         pa.Team,
+		StartedAt:    pa.StartedAt,
+	}
+	applyPersisted(a, pa)
+	return a
+}
+
+// applyPersisted copies the mutable fields of pa onto a.
+func applyPersisted(a *agent.Agent, pa agent.PersistedAgent) {
+	a.SetBaseBranch(pa.BaseBranch)
+	a.SetStatus(pa.Status)
+	a.SetWaitingFor(pa.WaitingFor)
+	a.SetAttentionReason(pa.AttentionReason)
+	a.SetPendingTask(pa.PendingTask)
+	a.SetEverActive(pa.EverActive)
+	if !pa.FinishedAt.IsZero() {
+		a.SetFinished(pa.ExitCode, pa.FinishedAt)
+	}
+	a.SetLazygitPaneID(pa.LazygitPaneID)
+	a.SetShellPaneID(pa.ShellPaneID)
+	a.SetPreReviewCommit(pa.PreReviewCommit)
+	if pa.SessionID != "" {
+		a.SetSessionID(pa.SessionID)
+	}
+	a.SetDurationState(pa.AccumulatedDuration, pa.RunningStartedAt)
+	a.SetReadyAt(pa.ReadyAt)
+	a.SetPullRequest(pa.PullRequest)
+	a.SetAllowPush(pa.AllowPush)
+	a.SetReportPath(pa.ReportPath)
+}
+
+// logEvent records ev in the daemon's event log.
+func (o *Orchestrator) logEvent(ev monitor.Event) {
+	if o.eventLog == nil {
+		return
+	}
+	if err := o.eventLog.Append(ev); err != nil {
+		slog.Warn("write event log failed", "error", err)
+	}
+}
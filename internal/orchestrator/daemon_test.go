@@ -0,0 +1,226 @@
+package orchestrator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/daemon"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+func TestFollowDaemon_PullsStateAndEvents(t *testing.T) {
+	mt := &mockTmux{}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	eventsPath := daemon.EventsPath(o.worktreeDir)
+	WithDaemonClient(eventsPath, daemon.SocketPath(o.worktreeDir))(o)
+	o.overviewWindowID, o.overviewWindowName = "@0", "mastermind"
+	events := o.Events()
+
+	// The daemon has written an agent and an attention event.
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	a.ID = "a1"
+	a.SetStatus(agent.StatusWaiting)
+	if err := agent.SaveState(o.statePath, []*agent.Agent{a}); err != nil {
+		t.Fatal(err)
+	}
+	log, err := daemon.CreateEventLog(eventsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	if err := log.Append(monitor.Attention{AgentID: "a1", Message: "waiting"}); err != nil {
+		t.Fatal(err)
+	}
+
+	o.followDaemon()
+
+	got, ok := o.store.Get("a1")
+	if !ok {
+		t.Fatal("agent from the daemon's state was not picked up")
+	}
+	if got.GetStatus() != agent.StatusWaiting {
+		t.Errorf("status = %q, want waiting", got.GetStatus())
+	}
+	select {
+	case ev := <-events:
+		if _, ok := ev.(monitor.Attention); !ok {
+			t.Errorf("event = %#v, want Attention", ev)
+		}
+	case <-time.After(time.Second):
+		t.Error("daemon event was not forwarded")
+	}
+	if !mt.hasCalled("RenameWindow:@0:mastermind *") {
+		t.Error("attention should mark the client's overview window")
+	}
+}
+
+func TestFollowDaemon_PushesLocalChanges(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	WithDaemonClient(daemon.EventsPath(o.worktreeDir), daemon.SocketPath(o.worktreeDir))(o)
+
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	a.ID = "a1"
+	if err := agent.SaveState(o.statePath, []*agent.Agent{a}); err != nil {
+		t.Fatal(err)
+	}
+	o.followDaemon()
+
+	// A user action in the TUI changes the agent without a save.
+	got, _ := o.store.Get("a1")
+	got.SetStatus(agent.StatusReviewing)
+	o.followDaemon()
+
+	persisted, err := agent.LoadState(o.statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(persisted) != 1 || persisted[0].Status != agent.StatusReviewing {
+		t.Errorf("state file = %+v, want a1 reviewing", persisted)
+	}
+}
+
+func TestPullState_RemovesAgentsDroppedByOtherSide(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	o.store.Add(a)
+	o.pushState()
+
+	// The other side dismissed the agent. Back-date our view of the file
+	// so the rewrite is noticed regardless of timestamp granularity.
+	if err := agent.SaveState(o.statePath, nil); err != nil {
+		t.Fatal(err)
+	}
+	o.stateModTime = time.Time{}
+	o.pullState()
+
+	if _, ok := o.store.Get(a.ID); ok {
+		t.Error("agent removed from the state file should leave the store")
+	}
+}
+
+func TestPushState_KeepsAgentsTheOtherSideAdded(t *testing.T) {
+	d := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	a1 := agent.NewAgent("feat/x", "main", "/wt1", "@1", "%1", "claude")
+	a1.ID = "a1"
+	d.store.Add(a1)
+	d.pushState()
+
+	c := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	c.statePath = d.statePath
+	c.pullState()
+
+	// The client spawns an agent between the daemon's pull and its push,
+	// in which the daemon has an update of its own.
+	a2 := agent.NewAgent("feat/y", "main", "/wt2", "@2", "%2", "claude")
+	a2.ID = "a2"
+	c.store.Add(a2)
+	c.pushState()
+	a1.SetStatus(agent.StatusDone)
+	d.stateModTime = time.Time{}
+	d.pushState()
+
+	persisted, err := agent.LoadState(d.statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(persisted) != 2 || persisted[0].Status != agent.StatusDone {
+		t.Fatalf("state file = %+v, want a1 done and a2", persisted)
+	}
+	c.stateModTime = time.Time{}
+	c.pullState()
+	if _, ok := c.store.Get("a2"); !ok {
+		t.Error("the client's new agent was dropped")
+	}
+	if got, _ := c.store.Get("a1"); got.GetStatus() != agent.StatusDone {
+		t.Errorf("a1 status = %s, want the daemon's done", got.GetStatus())
+	}
+}
+
+func TestPullState_KeepsAgentsCreatedSinceLastSync(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	if err := agent.SaveState(o.statePath, nil); err != nil {
+		t.Fatal(err)
+	}
+	o.pullState()
+
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	o.store.Add(a)
+	if err := agent.SaveState(o.statePath, nil); err != nil {
+		t.Fatal(err)
+	}
+	o.stateModTime = time.Time{}
+	o.pullState()
+
+	if _, ok := o.store.Get(a.ID); !ok {
+		t.Error("an agent created here after the last pull must not be removed")
+	}
+}
+
+func TestHandleMonitorEvent_DaemonLogsAndDropsGoneAgent(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	eventsPath := daemon.EventsPath(o.worktreeDir)
+	log, err := daemon.CreateEventLog(eventsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	WithDaemon(log)(o)
+
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	o.store.Add(a)
+	o.handleMonitorEvent(monitor.AgentGone{AgentID: a.ID})
+
+	if _, ok := o.store.Get(a.ID); ok {
+		t.Error("daemon should drop a gone agent from the store")
+	}
+	logged, _, err := daemon.ReadEvents(eventsPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != 1 || logged[0] != (monitor.AgentGone{AgentID: a.ID}) {
+		t.Errorf("event log = %#v, want the AgentGone event", logged)
+	}
+}
+
+func TestDismissAgent_ForwardedToDaemon(t *testing.T) {
+	dt := &mockTmux{}
+	d := newTestOrch(t, &mockGit{}, dt, &mockMonitor{})
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	d.store.Add(a)
+	d.saveState()
+
+	sock := filepath.Join(t.TempDir(), "d.sock")
+	ln, err := ipc.Listen(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ipc.NewServer(d.IPCBackend()).Serve(ctx, ln)
+
+	ct := &mockTmux{}
+	c := newTestOrch(t, &mockGit{}, ct, &mockMonitor{})
+	c.statePath = d.statePath
+	WithDaemonClient(daemon.EventsPath(d.worktreeDir), sock)(c)
+	c.followDaemon()
+	if _, ok := c.store.Get(a.ID); !ok {
+		t.Fatal("client should have picked up the daemon's agent")
+	}
+
+	if err := c.DismissAgent(a.ID, false); err != nil {
+		t.Fatalf("DismissAgent: %v", err)
+	}
+	if !dt.hasCalled("KillWindow:@1") {
+		t.Error("the daemon should have dismissed the agent")
+	}
+	if ct.hasCalled("KillWindow:@1") {
+		t.Error("the client should not touch tmux itself")
+	}
+	if _, ok := c.store.Get(a.ID); ok {
+		t.Error("client store should reflect the dismissal immediately")
+	}
+}
@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"log/slog"
+)
+
+// BaseFetchedMsg reports fast-forwarding a base branch from origin before
+// an agent was spawned from it. A failure does not stop the spawn.
+type BaseFetchedMsg struct {
+	Base  string
+	Moved bool
+	Error string
+}
+
+// WithFetchBase makes spawns fetch the base branch and fast-forward it to
+// origin's before branching from it, so agents start from the latest
+// base. FetchBase overrides it per spawn.
+func WithFetchBase(enabled bool) Option {
+	return func(o *Orchestrator) { o.fetchBase = enabled }
+}
+
+// FetchesBase reports whether spawns fast-forward the base branch from
+// origin unless told otherwise.
+func (o *Orchestrator) FetchesBase() bool {
+	return o.fetchBase
+}
+
+// FetchBase sets whether the base branch is fast-forwarded from origin
+// before the agent's branch is created from it.
+func FetchBase(enabled bool) SpawnOption {
+	return func(r *spawnRequest) { r.fetchBase = &enabled }
+}
+
+// fastForwardBase fast-forwards base to origin's. Failing to, e.g. when
+// offline or base has diverged, is reported and the agent starts from the
+// local base.
+func (o *Orchestrator) fastForwardBase(base string) {
+	moved, err := o.git.FastForwardFromOrigin(o.repoPath, base)
+	msg := BaseFetchedMsg{Base: base, Moved: moved}
+	switch {
+	case err != nil:
+		slog.Warn("failed to fast-forward base from origin", "base", base, "error", err)
+		msg.Error = err.Error()
+	case moved:
+		slog.Info("fast-forwarded base from origin", "base", base)
+	default:
+		return
+	}
+	if o.program != nil {
+		o.program.Send(msg)
+	}
+}
@@ -0,0 +1,41 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSpawnAgent_FetchBase(t *testing.T) {
+	mg := &mockGit{fastForwardMoved: true}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	o.fetchBase = true
+
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	if !mg.hasCalled("FastForwardFromOrigin:main") {
+		t.Errorf("calls = %v, want main fast-forwarded first", mg.calls)
+	}
+
+	mg.mu.Lock()
+	mg.calls = nil
+	mg.mu.Unlock()
+	if err := o.SpawnAgent("feat/y", "main", true, "claude", FetchBase(false)); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	if mg.hasCalled("FastForwardFromOrigin:main") {
+		t.Error("FetchBase(false) should skip the fetch")
+	}
+}
+
+func TestSpawnAgent_FetchBaseFails(t *testing.T) {
+	mg := &mockGit{fastForwardErr: errors.New("could not resolve host")}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	if err := o.SpawnAgent("feat/x", "main", true, "claude", FetchBase(true)); err != nil {
+		t.Fatalf("SpawnAgent = %v, want the spawn to go ahead from the local base", err)
+	}
+	if !mg.hasCalled("FastForwardFromOrigin:main") || !mg.hasCalled("CreateBranch:feat/x") {
+		t.Errorf("calls = %v", mg.calls)
+	}
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/simonbystrom/mastermind/internal/ci"
+	"github.com/simonbystrom/mastermind/internal/forge"
+)
+
+// WithForge configures the git hosting provider. It is resolved from the
+// origin remote on first use.
+func WithForge(cfg forge.Config) Option {
+	return func(o *Orchestrator) { o.forgeCfg = cfg }
+}
+
+// WithForgeProvider overrides the git hosting provider.
+func WithForgeProvider(p forge.Provider) Option {
+	return func(o *Orchestrator) { o.forge = p }
+}
+
+// Forge returns the repository's git hosting provider.
+func (o *Orchestrator) Forge() (forge.Provider, error) {
+	o.forgeOnce.Do(func() {
+		if o.forge == nil {
+			o.forge, o.forgeErr = forge.New(o.repoPath, o.forgeCfg)
+		}
+	})
+	return o.forge, o.forgeErr
+}
+
+// runLister returns the provider if it can list failed CI runs.
+func (o *Orchestrator) runLister() (forge.RunLister, error) {
+	p, err := o.Forge()
+	if err != nil {
+		return nil, err
+	}
+	l, ok := p.(forge.RunLister)
+	if !ok {
+		return nil, fmt.Errorf("fixing CI runs is not supported on %s", p.Kind())
+	}
+	return l, nil
+}
+
+// FailedRuns returns the most recent failed CI runs.
+func (o *Orchestrator) FailedRuns(limit int) ([]ci.Run, error) {
+	l, err := o.runLister()
+	if err != nil {
+		return nil, err
+	}
+	return l.FailedRuns(limit)
+}
+
+// FailedLogs returns the logs of the failed steps of a CI run.
+func (o *Orchestrator) FailedLogs(runID int64) (string, error) {
+	l, err := o.runLister()
+	if err != nil {
+		return "", err
+	}
+	return l.FailedLogs(runID)
+}
@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/forge"
+)
+
+// fakeForge is a git hosting provider that records calls.
+type fakeForge struct {
+	mu    sync.Mutex
+	calls []string
+	ci    forge.CIState
+
+	reviewErr error
+	found     forge.PullRequest // returned by FindPullRequest when Number != 0
+	created   forge.PullRequestOptions
+}
+
+func (f *fakeForge) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeForge) Kind() forge.Kind { return forge.GitLab }
+
+func (f *fakeForge) Push(branch string) error {
+	f.record("Push:" + branch)
+	return nil
+}
+
+func (f *fakeForge) CreatePullRequest(opts forge.PullRequestOptions) (forge.PullRequest, error) {
+	f.record("CreatePullRequest:" + opts.Head + "->" + opts.Base + " " + strings.Join(opts.Labels, ","))
+	f.created = opts
+	return forge.PullRequest{Number: 1, URL: "https://forge.test/pr/1"}, nil
+}
+
+func (f *fakeForge) CIStatus(branch string) (forge.CIState, error) {
+	f.record("CIStatus:" + branch)
+	return f.ci, nil
+}
+
+func (f *fakeForge) RequestReview(number int, reviewers []string) error {
+	f.record("RequestReview:" + strings.Join(reviewers, ","))
+	return f.reviewErr
+}
+
+func (f *fakeForge) FindPullRequest(head, base string) (forge.PullRequest, bool, error) {
+	f.record("FindPullRequest:" + head + "->" + base)
+	return f.found, f.found.Number != 0, nil
+}
+
+func TestFailedRuns_UnsupportedProvider(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	WithForgeProvider(&fakeForge{})(o)
+
+	_, err := o.FailedRuns(10)
+	if err == nil || !strings.Contains(err.Error(), "not supported on gitlab") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -142,7 +142,7 @@ func TestFullLifecycle_SpawnMergeCycle(t *testing.T) {
 	baseHeadBefore, _ := git.HeadCommit(repo, defaultBranch)
 
 	// Merge
-	result := o.MergeAgent(a.ID, true, true)
+	result := o.MergeAgent(a.ID, true, true, false, MergeCommit, "")
 	if !result.Success {
 		t.Fatalf("merge failed: %s", result.Error)
 	}
@@ -0,0 +1,132 @@
+package orchestrator
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/harness"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+	"github.com/simonbystrom/mastermind/internal/playbook"
+)
+
+// IPCBackend returns the operations the daemon serves on its socket.
+func (o *Orchestrator) IPCBackend() ipc.Backend {
+	return ipcBackend{o: o}
+}
+
+type ipcBackend struct {
+	o *Orchestrator
+}
+
+func (b ipcBackend) Repo() string {
+	return b.o.repoPath
+}
+
+func (b ipcBackend) Agents() []agent.PersistedAgent {
+	agents := b.o.store.All()
+	persisted := make([]agent.PersistedAgent, len(agents))
+	for i, a := range agents {
+		persisted[i] = a.Persisted()
+	}
+	sort.Slice(persisted, func(i, j int) bool { return persisted[i].ID < persisted[j].ID })
+	return persisted
+}
+
+func (b ipcBackend) Spawn(p ipc.SpawnParams) error {
+	h := harness.Type(p.Harness)
+	if h == "" {
+		h = b.o.defaultHarness
+	}
+	opts := []SpawnOption{InSession(p.Session), WithTicket(p.Ticket, p.TicketTitle)}
+	if p.ReuseWorktree {
+		opts = append(opts, ReuseWorktree())
+	}
+	if p.ReadOnly {
+		opts = append(opts, ReadOnly())
+	}
+	if p.Report {
+		opts = append(opts, Report())
+	}
+	if p.Playbook != "" {
+		pb, err := playbook.Load(p.Playbook)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, FromPlaybook(pb))
+	}
+	if p.TeamTask != "" {
+		opts = append(opts, AsTeam(p.TeamTask))
+	}
+	if p.Task != "" {
+		opts = append(opts, WithTask(p.Task))
+	}
+	if p.FetchBase != nil {
+		opts = append(opts, FetchBase(*p.FetchBase))
+	}
+	return b.o.SpawnAgent(p.Branch, p.BaseBranch, p.CreateBranch, h, opts...)
+}
+
+func (b ipcBackend) Merge(p ipc.MergeParams) ipc.MergeResult {
+	if p.Defaults {
+		d := b.o.MergeDefaults()
+		p.DeleteBranch, p.RemoveWorktree, p.Push, p.Strategy = d.DeleteBranch, d.RemoveWorktree, d.Push, string(d.Strategy)
+	}
+	msg := b.o.MergeAgent(p.ID, p.DeleteBranch, p.RemoveWorktree, p.Push, MergeStrategy(p.Strategy), p.Message)
+	return ipc.MergeResult{
+		Success:       msg.Success,
+		Conflict:      msg.Conflict,
+		ConflictFiles: msg.ConflictFiles,
+		ConflictNotes: msg.ConflictNotes,
+		Error:         msg.Error,
+		PushError:     msg.PushError,
+	}
+}
+
+func (b ipcBackend) Dismiss(p ipc.DismissParams) error {
+	return b.o.DismissAgent(p.ID, p.DeleteBranch)
+}
+
+func (b ipcBackend) PullRequest(p ipc.PullRequestParams) (ipc.PullRequestResult, error) {
+	msg := b.o.OpenPullRequest(p.ID)
+	if msg.Error != "" {
+		return ipc.PullRequestResult{}, errors.New(msg.Error)
+	}
+	return ipc.PullRequestResult{Number: msg.Number, URL: msg.URL, Reviewers: msg.Reviewers, Warning: msg.Warning}, nil
+}
+
+func (b ipcBackend) Clone(p ipc.CloneParams) error {
+	mode := CloneFork
+	if p.Fresh {
+		mode = CloneFresh
+	}
+	return b.o.CloneAgent(p.ID, p.Branch, mode)
+}
+
+func (b ipcBackend) AllowPush(p ipc.AllowPushParams) error {
+	return b.o.SetAllowPush(p.ID, p.Allow)
+}
+
+func (b ipcBackend) Subscribe(buffer int) (<-chan monitor.Event, func()) {
+	ch := b.o.bus.Subscribe(buffer)
+	return ch, func() { b.o.bus.Unsubscribe(ch) }
+}
+
+// remoteCall runs call against the daemon when the orchestrator is its
+// client, then pulls the state the daemon wrote so the dashboard reflects
+// the change immediately. handled is false when there is no daemon to
+// forward to and the caller should carry out the operation itself.
+func (o *Orchestrator) remoteCall(call func(*ipc.Client) error) (handled bool, err error) {
+	if o.remote == nil {
+		return false, nil
+	}
+	err = call(o.remote)
+	if errors.Is(err, ipc.ErrUnavailable) {
+		slog.Warn("daemon unreachable, running operation locally", "error", err)
+		return false, nil
+	}
+	o.pullState()
+	return true, err
+}
@@ -0,0 +1,195 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/simonbystrom/mastermind/internal/hook"
+)
+
+// MaintenanceOptions selects which maintenance tasks to run.
+type MaintenanceOptions struct {
+	RepairWorktrees bool // git worktree repair for broken agent worktrees
+	PruneWorktrees  bool // git worktree prune
+	GC              bool // git gc --auto
+	PreviewBranches bool // delete preview/* branches not backing an active preview
+	StatusFiles     bool // remove status files from worktrees no agent owns
+}
+
+// MaintenanceReport lists what a maintenance run removed.
+type MaintenanceReport struct {
+	RepairedWorktrees []string
+	PrunedWorktrees   []string
+	DeletedBranches   []string
+	RemovedFiles      []string
+	GCRan             bool
+	Errors            []string
+}
+
+// Empty reports whether the run found nothing to do.
+func (r MaintenanceReport) Empty() bool {
+	return len(r.RepairedWorktrees) == 0 && len(r.PrunedWorktrees) == 0 && len(r.DeletedBranches) == 0 &&
+		len(r.RemovedFiles) == 0 && len(r.Errors) == 0
+}
+
+type MaintenanceResultMsg struct {
+	Report MaintenanceReport
+}
+
+// RunMaintenance performs the selected repository housekeeping tasks.
+// Failures are collected in the report rather than aborting the run.
+func (o *Orchestrator) RunMaintenance(opts MaintenanceOptions) MaintenanceReport {
+	var r MaintenanceReport
+	fail := func(task string, err error) {
+		slog.Warn("maintenance task failed", "task", task, "error", err)
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", task, err))
+	}
+
+	// Repair before pruning so moved worktrees are relinked rather than
+	// having their administrative data pruned away.
+	if opts.RepairWorktrees {
+		if broken := o.BrokenWorktrees(); len(broken) > 0 {
+			if err := o.git.RepairWorktrees(o.repoPath, broken); err != nil {
+				fail("worktree repair", err)
+			} else {
+				r.RepairedWorktrees = broken
+			}
+		}
+	}
+
+	if opts.PruneWorktrees {
+		pruned, err := o.git.PruneWorktrees(o.repoPath)
+		if err != nil {
+			fail("worktree prune", err)
+		}
+		r.PrunedWorktrees = pruned
+	}
+
+	if opts.PreviewBranches {
+		r.DeletedBranches = o.deleteStalePreviewBranches(fail)
+	}
+
+	if opts.StatusFiles {
+		r.RemovedFiles = o.removeOrphanedStatusFiles(fail)
+	}
+
+	// gc last so it can collect anything freed by the steps above.
+	if opts.GC {
+		if err := o.git.GCAuto(o.repoPath); err != nil {
+			fail("gc", err)
+		} else {
+			r.GCRan = true
+		}
+	}
+
+	slog.Info("maintenance completed",
+		"repairedWorktrees", len(r.RepairedWorktrees),
+		"prunedWorktrees", len(r.PrunedWorktrees),
+		"deletedBranches", len(r.DeletedBranches),
+		"removedFiles", len(r.RemovedFiles),
+		"errors", len(r.Errors))
+	return r
+}
+
+// BrokenWorktrees returns the agent worktrees whose link to the repository
+// is broken, covering both tracked agents and directories in the worktree
+// dir.
+func (o *Orchestrator) BrokenWorktrees() []string {
+	seen := make(map[string]bool)
+	var broken []string
+	check := func(path string) {
+		path = filepath.Clean(path)
+		if path == "." || seen[path] {
+			return
+		}
+		seen[path] = true
+		if o.git.IsWorktreeBroken(path) {
+			broken = append(broken, path)
+		}
+	}
+	for _, a := range o.store.All() {
+		check(a.WorktreePath)
+	}
+	if entries, err := os.ReadDir(o.worktreeDir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				check(filepath.Join(o.worktreeDir, e.Name()))
+			}
+		}
+	}
+	sort.Strings(broken)
+	return broken
+}
+
+// deleteStalePreviewBranches removes the preview branches mastermind
+// created for previews that were never stopped cleanly. preview/*
+// branches it didn't create are left alone.
+func (o *Orchestrator) deleteStalePreviewBranches(fail func(string, error)) []string {
+	branches, err := o.git.ListBranches(o.repoPath)
+	if err != nil {
+		fail("list branches", err)
+		return nil
+	}
+	active := ""
+	if id := o.GetPreviewAgentID(); id != "" {
+		active = previewBranch(id)
+	}
+	// Whether each branch is checked out in the main worktree.
+	current := make(map[string]bool, len(branches))
+	for _, b := range branches {
+		current[b.Name] = b.Current
+	}
+
+	var deleted []string
+	for _, name := range o.loadPreviewBranches() {
+		checkedOut, ok := current[name]
+		if !ok {
+			o.forgetPreviewBranch(name) // deleted by hand
+			continue
+		}
+		if name == active || checkedOut {
+			continue
+		}
+		if err := o.deletePreviewBranch(name); err != nil {
+			fail("delete "+name, err)
+			continue
+		}
+		deleted = append(deleted, name)
+	}
+	return deleted
+}
+
+// removeOrphanedStatusFiles deletes hook status and heartbeat files from
+// worktrees that no tracked agent owns, including the main working tree.
+func (o *Orchestrator) removeOrphanedStatusFiles(fail func(string, error)) []string {
+	worktrees, err := o.git.ListWorktrees(o.repoPath)
+	if err != nil {
+		fail("list worktrees", err)
+		return nil
+	}
+	owned := make(map[string]bool)
+	for _, a := range o.store.All() {
+		owned[filepath.Clean(a.WorktreePath)] = true
+	}
+
+	var removed []string
+	for _, wt := range worktrees {
+		if owned[filepath.Clean(wt.Path)] {
+			continue
+		}
+		for _, name := range []string{hook.StatusFileName, hook.HeartbeatFileName} {
+			path := filepath.Join(wt.Path, name)
+			if err := os.Remove(path); err != nil {
+				if !os.IsNotExist(err) {
+					fail("remove "+path, err)
+				}
+				continue
+			}
+			removed = append(removed, path)
+		}
+	}
+	return removed
+}
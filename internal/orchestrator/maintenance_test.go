@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/hook"
+)
+
+func TestRunMaintenance(t *testing.T) {
+	owned := t.TempDir()
+	orphan := t.TempDir()
+	for _, dir := range []string{owned, orphan} {
+		if err := os.WriteFile(filepath.Join(dir, hook.StatusFileName), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mg := &mockGit{
+		pruneWorktreesResult: []string{"Removing worktrees/old: gitdir file points to non-existent location"},
+		listBranchesResult: []git.Branch{
+			{Name: "main", Current: true},
+			{Name: "preview/a7"},
+			{Name: "preview/landing-page"},
+			{Name: "feat/x"},
+		},
+		listWorktreesResult: []git.Worktree{{Path: owned}, {Path: orphan}},
+	}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	// preview/a7 is left from a preview that never stopped; the user's
+	// own preview/landing-page and a preview branch deleted by hand are
+	// not for maintenance to touch.
+	o.createPreviewBranch("a7", "main")
+	o.createPreviewBranch("a9", "main")
+	o.store.Add(agent.NewAgent("feat/x", "main", owned, "@1", "%1", "claude"))
+
+	r := o.RunMaintenance(MaintenanceOptions{PruneWorktrees: true, GC: true, PreviewBranches: true, StatusFiles: true})
+
+	if len(r.PrunedWorktrees) != 1 {
+		t.Errorf("pruned = %v", r.PrunedWorktrees)
+	}
+	if len(r.DeletedBranches) != 1 || r.DeletedBranches[0] != "preview/a7" {
+		t.Errorf("deleted branches = %v, want [preview/a7]", r.DeletedBranches)
+	}
+	if mg.hasCalled("DeleteBranch:preview/landing-page") || mg.hasCalled("DeleteBranch:preview/a9") {
+		t.Error("only preview branches mastermind left behind may be deleted")
+	}
+	if got := o.loadPreviewBranches(); len(got) != 0 {
+		t.Errorf("recorded preview branches = %v, want none left", got)
+	}
+	if !r.GCRan {
+		t.Error("expected gc to run")
+	}
+	if len(r.RemovedFiles) != 1 || r.RemovedFiles[0] != filepath.Join(orphan, hook.StatusFileName) {
+		t.Errorf("removed files = %v, want orphan status file", r.RemovedFiles)
+	}
+	if _, err := os.Stat(filepath.Join(owned, hook.StatusFileName)); err != nil {
+		t.Error("owned agent's status file should be kept")
+	}
+}
+
+func TestRunMaintenance_KeepsActivePreviewBranch(t *testing.T) {
+	mg := &mockGit{listBranchesResult: []git.Branch{{Name: "preview/a1"}}}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	o.createPreviewBranch("a1", "main")
+	o.previewAgentID = "a1"
+
+	r := o.RunMaintenance(MaintenanceOptions{PreviewBranches: true})
+
+	if len(r.DeletedBranches) != 0 || mg.hasCalled("DeleteBranch:preview/a1") {
+		t.Error("active preview branch must not be deleted")
+	}
+	if !r.Empty() {
+		t.Errorf("report = %+v, want empty", r)
+	}
+}
+
+func TestRunMaintenance_RepairsBrokenWorktrees(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	broken := filepath.Join(o.worktreeDir, "feat-moved")
+	healthy := filepath.Join(o.worktreeDir, "feat-ok")
+	for _, dir := range []string{broken, healthy} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mg.brokenWorktrees = map[string]bool{broken: true}
+
+	if got := o.BrokenWorktrees(); len(got) != 1 || got[0] != broken {
+		t.Fatalf("BrokenWorktrees() = %v, want [%s]", got, broken)
+	}
+
+	r := o.RunMaintenance(MaintenanceOptions{RepairWorktrees: true})
+
+	if !mg.hasCalled("RepairWorktrees:" + broken) {
+		t.Error("expected git worktree repair for the broken worktree")
+	}
+	if len(r.RepairedWorktrees) != 1 || r.RepairedWorktrees[0] != broken {
+		t.Errorf("repaired = %v, want [%s]", r.RepairedWorktrees, broken)
+	}
+	if len(o.BrokenWorktrees()) != 0 {
+		t.Error("no worktrees should be broken after repair")
+	}
+}
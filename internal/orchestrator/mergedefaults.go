@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// MergeStrategy is how an agent's branch lands on its base.
+type MergeStrategy string
+
+const (
+	// MergeCommit merges base into the branch, creating a merge commit
+	// when base has moved on, and fast-forwards base.
+	MergeCommit MergeStrategy = "merge"
+	// MergeRebase rebases the branch onto base and fast-forwards base,
+	// keeping history linear.
+	MergeRebase MergeStrategy = "rebase"
+	// MergeSquash merges base into the branch like MergeCommit, then
+	// lands all of the branch's changes on base as a single commit.
+	MergeSquash MergeStrategy = "squash"
+)
+
+// MergeStrategies lists the strategies in the order the merge wizard
+// cycles through them.
+var MergeStrategies = []MergeStrategy{MergeCommit, MergeRebase, MergeSquash}
+
+// ErrRebaseConflicts is returned by a rebase merge when the branch does
+// not rebase cleanly onto base. The rebase is aborted.
+var ErrRebaseConflicts = errors.New("the branch conflicts with base when rebased; merge it with a merge commit or rebase it yourself")
+
+// MergeChoices are the strategy and cleanup options of a merge.
+type MergeChoices struct {
+	DeleteBranch   bool          `json:"delete_branch"`
+	RemoveWorktree bool          `json:"remove_worktree"`
+	Push           bool          `json:"push"`
+	Strategy       MergeStrategy `json:"strategy,omitempty"`
+}
+
+// WithMergeDefaults sets the cleanup options the merge wizard starts
+// with. With remember set, the choices last confirmed in the wizard, saved
+// per repository by RememberMergeChoices, take their place.
+func WithMergeDefaults(deleteBranch, removeWorktree, remember bool) Option {
+	return func(o *Orchestrator) {
+		o.mergeDefaults.DeleteBranch = deleteBranch
+		o.mergeDefaults.RemoveWorktree = removeWorktree
+		o.rememberMerge = remember
+	}
+}
+
+// WithMergeStrategy sets the strategy merges use unless the merge wizard
+// picks another. An empty strategy keeps MergeCommit.
+func WithMergeStrategy(s MergeStrategy) Option {
+	return func(o *Orchestrator) {
+		if s != "" {
+			o.mergeDefaults.Strategy = s
+		}
+	}
+}
+
+// WithMergePush sets whether merges push base to its upstream once they
+// land, unless the merge wizard chooses otherwise.
+func WithMergePush(enabled bool) Option {
+	return func(o *Orchestrator) { o.mergeDefaults.Push = enabled }
+}
+
+// WithMergeRetries sets how many times a merge merges base into the
+// agent's branch again when base moves before it can be fast-forwarded.
+func WithMergeRetries(n int) Option {
+	return func(o *Orchestrator) { o.mergeRetries = n }
+}
+
+func (o *Orchestrator) mergeChoicesPath() string {
+	return filepath.Join(o.worktreeDir, "mastermind-merge.json")
+}
+
+// MergeDefaults returns the cleanup options a merge defaults to: the
+// choices last remembered for the repository, or the configured ones.
+func (o *Orchestrator) MergeDefaults() MergeChoices {
+	if !o.rememberMerge {
+		return o.mergeDefaults
+	}
+	data, err := os.ReadFile(o.mergeChoicesPath())
+	if err != nil {
+		return o.mergeDefaults
+	}
+	var saved struct {
+		MergeChoices
+		Push *bool `json:"push"` // shadows MergeChoices.Push to tell if it was saved
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		slog.Warn("ignoring unreadable merge choices", "path", o.mergeChoicesPath(), "error", err)
+		return o.mergeDefaults
+	}
+	// Choices saved before strategies and pushing existed leave them to
+	// the config.
+	c := saved.MergeChoices
+	if c.Strategy == "" {
+		c.Strategy = o.mergeDefaults.Strategy
+	}
+	c.Push = o.mergeDefaults.Push
+	if saved.Push != nil {
+		c.Push = *saved.Push
+	}
+	return c
+}
+
+// RememberMergeChoices saves the cleanup options the user merged with as
+// the repository's defaults, unless remembering is turned off.
+func (o *Orchestrator) RememberMergeChoices(c MergeChoices) {
+	if !o.rememberMerge {
+		return
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		slog.Error("failed to marshal merge choices", "error", err)
+		return
+	}
+	if err := os.WriteFile(o.mergeChoicesPath(), data, 0o644); err != nil {
+		slog.Error("failed to save merge choices", "error", err)
+	}
+}
@@ -0,0 +1,43 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func TestMergeDefaults(t *testing.T) {
+	o := New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir(), WithMergeDefaults(false, true, true))
+
+	if got := o.MergeDefaults(); got != (MergeChoices{RemoveWorktree: true, Strategy: MergeCommit}) {
+		t.Errorf("MergeDefaults = %+v, want the configured defaults", got)
+	}
+	o.RememberMergeChoices(MergeChoices{DeleteBranch: true, Strategy: MergeRebase})
+	if got := o.MergeDefaults(); got != (MergeChoices{DeleteBranch: true, Strategy: MergeRebase}) {
+		t.Errorf("MergeDefaults = %+v, want the remembered choices", got)
+	}
+	// Choices remembered without a strategy take the configured one.
+	o.RememberMergeChoices(MergeChoices{DeleteBranch: true})
+	if got := o.MergeDefaults(); got != (MergeChoices{DeleteBranch: true, Strategy: MergeCommit}) {
+		t.Errorf("MergeDefaults = %+v, want the configured strategy", got)
+	}
+
+	// Choices saved before pushing existed take the configured push.
+	o.mergeDefaults.Push = true
+	if err := os.WriteFile(o.mergeChoicesPath(), []byte(`{"delete_branch": true, "strategy": "merge"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := o.MergeDefaults(); got != (MergeChoices{DeleteBranch: true, Push: true, Strategy: MergeCommit}) {
+		t.Errorf("MergeDefaults = %+v, want the configured push", got)
+	}
+
+	// Without remembering, the configured defaults always apply.
+	o = New(context.Background(), agent.NewStore(), "/repo", "test", o.worktreeDir,
+		WithMergeDefaults(false, true, false), WithMergeStrategy(MergeRebase))
+	o.RememberMergeChoices(MergeChoices{DeleteBranch: true, RemoveWorktree: true})
+	if got := o.MergeDefaults(); got != (MergeChoices{RemoveWorktree: true, Strategy: MergeRebase}) {
+		t.Errorf("MergeDefaults = %+v, want the configured defaults", got)
+	}
+}
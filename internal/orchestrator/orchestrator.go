@@ -1,13 +1,16 @@
 package orchestrator
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -15,30 +18,42 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/ci"
 	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/daemon"
+	"github.com/simonbystrom/mastermind/internal/forge"
 	"github.com/simonbystrom/mastermind/internal/git"
 	"github.com/simonbystrom/mastermind/internal/harness"
 	"github.com/simonbystrom/mastermind/internal/harness/claudecode"
 	"github.com/simonbystrom/mastermind/internal/harness/opencode"
 	"github.com/simonbystrom/mastermind/internal/hook"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/monitor"
 	"github.com/simonbystrom/mastermind/internal/notify"
+	"github.com/simonbystrom/mastermind/internal/playbook"
+	"github.com/simonbystrom/mastermind/internal/prompt"
+	"github.com/simonbystrom/mastermind/internal/recorder"
+	"github.com/simonbystrom/mastermind/internal/shim"
+	"github.com/simonbystrom/mastermind/internal/streamjson"
+	"github.com/simonbystrom/mastermind/internal/ticket"
 	"github.com/simonbystrom/mastermind/internal/tmux"
 )
 
-type AgentFinishedMsg struct {
-	AgentID    string
-	ExitCode   int
-	HasChanges bool
-}
-
-type AgentWaitingMsg struct {
-	AgentID    string
-	WaitingFor string // "permission", "input", or "" (no longer waiting)
-}
+// Monitor events are delivered to the TUI as-is; these aliases keep the
+// message names the UI switches on.
+type (
+	AgentFinishedMsg     = monitor.AgentFinished
+	AgentWaitingMsg      = monitor.AgentWaiting
+	AgentGoneMsg         = monitor.AgentGone
+	PullRequestMergedMsg = monitor.PullRequestMerged
+	PlaybookFinishedMsg  = monitor.PlaybookFinished
+	AutoCleanupMsg       = monitor.AutoCleanup
+	OrphanProcessesMsg   = monitor.OrphanProcesses
+)
 
-type AgentGoneMsg struct {
-	AgentID string
-}
+// StoreChangedMsg is sent to the TUI when an agent is added to or removed
+// from the store, or changes status or base branch.
+type StoreChangedMsg = agent.Change
 
 type AgentReviewedMsg struct {
 	AgentID    string
@@ -51,6 +66,13 @@ type MergeResultMsg struct {
 	Conflict      bool
 	Error         string
 	ConflictFiles []string
+	// ConflictNotes explain conflicts custom merge drivers are involved in.
+	ConflictNotes []string
+	// PushError is why pushing base failed after a successful merge.
+	PushError string
+	// Synced is set on the results of SyncAgent, which brings base into
+	// the agent's branch rather than landing the branch on base.
+	Synced bool
 }
 
 type CleanupResult struct {
@@ -69,52 +91,115 @@ type PruneResultMsg struct {
 	HasUncommitted bool
 }
 
+// StackRestackedMsg reports that an agent stacked on a merged branch was
+// re-parented onto that branch's base.
+type StackRestackedMsg struct {
+	AgentID string
+	Parent  string // the merged branch the agent was stacked on
+	NewBase string
+	Rebased bool
+	// Reason explains why the worktree was not rebased.
+	Reason string
+}
+
 type PreviewStartedMsg struct{ AgentID string }
-type PreviewStoppedMsg struct{ AgentID string }
-type PreviewErrorMsg struct {
+
+// PreviewStoppedMsg reports that a preview ended. Stash names the stash
+// holding the changes made to Stashed in the main worktree during it.
+type PreviewStoppedMsg struct {
 	AgentID string
-	Error   string
+	Stash   string
+	Stashed []string
 }
 
-// mtimeEntry caches the result of a file read keyed by its mtime.
-type mtimeEntry struct {
-	mtime  time.Time
-	result interface{}
+// PreviewStaleMsg reports that the branch of the agent being previewed
+// moved on since it was merged into the preview.
+type PreviewStaleMsg struct{ AgentID string }
+
+// PreviewRefreshedMsg reports that the preview merged the agent's branch
+// again.
+type PreviewRefreshedMsg struct{ AgentID string }
+
+type PreviewErrorMsg struct {
+	AgentID string
+	Error   string
 }
 
 type Orchestrator struct {
 	ctx              context.Context
 	store            *agent.Store
 	repoPath         string
-	session          string
 	worktreeDir      string
 	program          *tea.Program
 	monitor          tmux.PaneStatusChecker
 	statePath        string
+	promptPath       string
 	git              git.GitOps
 	tmux             tmux.TmuxOps
 	lazygitSplit     int
+	splitMu          sync.Mutex // guards lazygitSplit, which the TUI can change
 	agentTeams       bool
 	teammateMode     string
 	skipPermissions  bool
 	promptEditor     bool
 	promptEditorSize int
+	streamJSON       bool
+	useShim          bool
+	pushGuard        bool
+	reportFile       string
+	reportsDir       string
+	playbooksDir     string
+	maxRunning       int
+	windowPanes      []config.Pane
+
+	// Merge wizard defaults; see mergedefaults.go.
+	mergeDefaults MergeChoices
+	rememberMerge bool
+	mergeRetries  int  // times base is merged in again when it moves during a merge
+	rerere        bool // merge with rerere so conflict resolutions are shared by agents
+
+	archiveBranches bool // archive agent branches instead of deleting them; see archive.go
+	fetchBase       bool // fast-forward base from origin before spawning; see fetchbase.go
+
+	// Backups of uncommitted changes; see backup.go.
+	backupsDir      string
+	backupRetention time.Duration // zero disables backups
+
+	largeConflict  int64  // bytes above which a conflicted file is large; see conflicts.go
+	conflictEditor string // command template opening a conflicted file; see conflicts.go
+
+	// Stopping agents on dismiss; see stop.go
+	procs       ProcessOps
+	stopTimeout time.Duration
+	stoppedMu   sync.Mutex
+	stopped     map[string]string // signals stopAgents sent, by agent ID
+	heldMu      sync.Mutex        // guards the held worktrees file; see orphans.go
+
+	// Records the user's actions, if set
+	recorder *recorder.Recorder
+
+	// Agents whose playbook checks are running
+	playbookRuns sync.Map
 
 	// Harness support
 	harnesses      map[harness.Type]harness.Harness
 	defaultHarness harness.Type
 
-	// Performance caches (monitor loop only, no mutex needed)
-	idleHasChanges       map[string]*bool      // agentID → cached HasChanges result for idle agents
-	hookMtimeCache       map[string]mtimeEntry // worktreePath → cached hook status
-	statuslineMtimeCache map[string]mtimeEntry // worktreePath → cached statusline data
-	todosMtimeCache      map[string]mtimeEntry // worktreePath → cached todos data
-	lastSaveTime         time.Time             // debounce state persistence
+	// Status monitoring
+	statusProviders []string // provider names, highest priority first
+	goneAfter       int      // polls a pane must be missing before the agent is gone
+	mon             *monitor.Monitor
+	bus             *monitor.Bus
+	lastSaveTime    time.Time // debounce state persistence
 
 	previewMu         sync.RWMutex
-	previewAgentID    string       // ID of agent being previewed (empty = no preview)
-	previewPrevBranch string       // branch the main worktree was on before preview
-	previewPrevStatus agent.Status // agent's status before preview started
+	previewBranchesMu sync.Mutex        // guards the preview branches file; see preview.go
+	previewAgentID    string            // ID of agent being previewed (empty = no preview)
+	previewPrevBranch string            // branch the main worktree was on before preview
+	previewPrevStatus agent.Status      // agent's status before preview started
+	previewHead       string            // agent branch's commit merged into the preview
+	previewStale      bool              // the agent branch moved on since previewHead
+	previewSnapshot   map[string]string // changes the preview made in the main worktree
 
 	previewCleanupOnce sync.Once // ensures shutdown cleanup runs exactly once
 
@@ -123,6 +208,58 @@ type Orchestrator struct {
 	overviewWindowID   string // tmux window ID of the TUI window (e.g. "@0")
 	overviewWindowName string // original window name (without " *" suffix)
 	attentionActive    bool   // true when " *" suffix is currently appended
+	tmuxBell           bool   // ring the overview window's bell on attention
+	markWindows        bool   // prefix agent windows waiting for permission
+	windowStatus       bool   // prefix every agent window with a status glyph
+
+	// windowNames holds the name last given to each agent's window by
+	// syncWindowNames. Only touched from the monitor goroutine.
+	windowNames map[string]string
+
+	// The tmux session new agent windows are created in. It follows the
+	// mastermind pane across session renames and moves; see syncSession.
+	selfPane    string // the mastermind pane
+	sessionMu   sync.RWMutex
+	session     string // tmux target; see currentSession
+	sessionID   string // resolved session ID, e.g. "$3"
+	sessionName string
+
+	spawnSessions []string // extra sessions offered in the spawn wizard
+
+	// Git hosting provider, resolved on first use; see forge.go.
+	forgeCfg  forge.Config
+	forgeOnce sync.Once
+	forge     forge.Provider
+	forgeErr  error
+
+	// Pull request defaults; see pullrequest.go.
+	prReviewers    []string
+	prCodeowners   bool
+	prLabels       []string
+	prPollInterval time.Duration
+	prAutoDismiss  bool
+
+	// Cleanup policy; see cleanup.go.
+	cleanup       CleanupPolicy
+	cleanupFailed map[string]bool // agents the policy failed to dismiss; only touched by watchCleanup
+
+	aheadBehindAt time.Time // when refreshAheadBehind last counted; only touched by the monitor loop
+
+	// Ticket tracker; see ticket.go.
+	tracker          ticket.Tracker
+	ticketInProgress string // state a ticket moves to when its agent spawns
+	ticketInReview   string // state a ticket moves to when its agent is ready for review
+
+	// Daemon support; see daemon.go.
+	daemonMode   bool              // headless monitor sharing state with TUI clients
+	daemonClient bool              // a daemon monitors; follow its state and events
+	eventLog     *daemon.EventLog  // daemon: where monitor events are appended
+	eventsPath   string            // client: the daemon's event log
+	eventsOffset int64             // client: read position in eventsPath
+	remote       *ipc.Client       // client: forwards spawn/merge/dismiss to the daemon
+	syncMu       sync.Mutex        // guards stateModTime and syncedAgents
+	stateModTime time.Time         // mtime of the state file when last synced
+	syncedAgents map[string]string // each agent as last read from or written to the file; see persistedKey
 }
 
 // Option configures an Orchestrator.
@@ -143,11 +280,38 @@ func WithMonitor(m tmux.PaneStatusChecker) Option {
 	return func(o *Orchestrator) { o.monitor = m }
 }
 
+// WithStatusProviders sets the status detection providers, by name,
+// highest priority first.
+func WithStatusProviders(names []string) Option {
+	return func(o *Orchestrator) { o.statusProviders = names }
+}
+
+// WithGoneAfter sets how many consecutive monitor polls an agent's pane
+// must be missing before the agent is treated as closed.
+func WithGoneAfter(n int) Option {
+	return func(o *Orchestrator) { o.goneAfter = n }
+}
+
 // WithLazygitSplit sets the lazygit pane size percentage.
 func WithLazygitSplit(pct int) Option {
 	return func(o *Orchestrator) { o.lazygitSplit = pct }
 }
 
+// SetLazygitSplit changes the lazygit pane size percentage. Panes already
+// open keep their size.
+func (o *Orchestrator) SetLazygitSplit(pct int) {
+	o.splitMu.Lock()
+	defer o.splitMu.Unlock()
+	o.lazygitSplit = pct
+}
+
+// LazygitSplit returns the lazygit pane size percentage.
+func (o *Orchestrator) LazygitSplit() int {
+	o.splitMu.Lock()
+	defer o.splitMu.Unlock()
+	return o.lazygitSplit
+}
+
 // WithAgentTeams enables or disables Claude Code agent teams.
 func WithAgentTeams(enabled bool) Option {
 	return func(o *Orchestrator) { o.agentTeams = enabled }
@@ -173,6 +337,66 @@ func WithPromptEditorSize(pct int) Option {
 	return func(o *Orchestrator) { o.promptEditorSize = pct }
 }
 
+// WithStreamJSON runs Claude Code agents non-interactively with streaming
+// JSON output relayed through mastermind.
+func WithStreamJSON(enabled bool) Option {
+	return func(o *Orchestrator) { o.streamJSON = enabled }
+}
+
+// WithShim launches agents through `mastermind shim`, which records
+// their exit code independently of tmux.
+func WithShim(enabled bool) Option {
+	return func(o *Orchestrator) { o.useShim = enabled }
+}
+
+// WithPushGuard blocks pushes from agent worktrees unless allowed per
+// agent with SetAllowPush.
+func WithPushGuard(enabled bool) Option {
+	return func(o *Orchestrator) { o.pushGuard = enabled }
+}
+
+// WithRerere merges agent branches with git's rerere, so a conflict
+// resolved for one agent is resolved the same way, and staged for review,
+// for the next.
+func WithRerere(enabled bool) Option {
+	return func(o *Orchestrator) { o.rerere = enabled }
+}
+
+// WithReports sets the file report agents write their report to and the
+// directory reports are collected into, relative to the repository unless
+// absolute. Empty values keep the defaults.
+func WithReports(file, dir string) Option {
+	return func(o *Orchestrator) {
+		if file != "" {
+			o.reportFile = file
+		}
+		if dir != "" {
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(o.repoPath, dir)
+			}
+			o.reportsDir = dir
+		}
+	}
+}
+
+// WithMaxRunning caps how many agents may be working at once; spawning
+// beyond it fails with ErrMaxRunning. Zero means no cap.
+func WithMaxRunning(n int) Option {
+	return func(o *Orchestrator) { o.maxRunning = n }
+}
+
+// WithWindowLayout sets extra panes opened next to the agent pane in every
+// new agent window.
+func WithWindowLayout(panes []config.Pane) Option {
+	return func(o *Orchestrator) { o.windowPanes = panes }
+}
+
+// WithHarness registers an additional harness, e.g. a custom agent command,
+// agents can be spawned with.
+func WithHarness(h harness.Harness) Option {
+	return func(o *Orchestrator) { o.harnesses[h.Type()] = h }
+}
+
 // WithDefaultHarness sets the default harness type for new agents.
 func WithDefaultHarness(ht harness.Type) Option {
 	return func(o *Orchestrator) { o.defaultHarness = ht }
@@ -192,47 +416,353 @@ func WithOverviewWindow(windowID, windowName string) Option {
 	}
 }
 
+// WithTmuxBell rings the bell in the mastermind window on attention events
+// so tmux flags it in the status line.
+func WithTmuxBell(enabled bool) Option {
+	return func(o *Orchestrator) { o.tmuxBell = enabled }
+}
+
+// WithMarkWindows prefixes agent window names with tmux.AttentionPrefix
+// while the agent waits for permission.
+func WithMarkWindows(enabled bool) Option {
+	return func(o *Orchestrator) { o.markWindows = enabled }
+}
+
+// WithWindowStatus keeps agent window names prefixed with a glyph for the
+// agent's current status.
+func WithWindowStatus(enabled bool) Option {
+	return func(o *Orchestrator) { o.windowStatus = enabled }
+}
+
 func New(ctx context.Context, store *agent.Store, repoPath, session, worktreeDir string, opts ...Option) *Orchestrator {
 	o := &Orchestrator{
 		ctx:              ctx,
 		store:            store,
 		repoPath:         repoPath,
 		session:          session,
+		sessionName:      session,
 		worktreeDir:      worktreeDir,
 		monitor:          tmux.NewPaneMonitor(),
 		statePath:        worktreeDir + "/mastermind-state.json",
+		promptPath:       worktreeDir + "/" + prompt.FileName,
 		git:              git.RealGit{},
 		tmux:             tmux.RealTmux{},
 		lazygitSplit:     80,
 		promptEditorSize: 50,
 		agentTeams:       true,
 		teammateMode:     "in-process",
+		useShim:          true,
+		pushGuard:        true,
+		reportFile:       "REPORT.md",
+		reportsDir:       filepath.Join(worktreeDir, "reports"),
+		backupsDir:       filepath.Join(worktreeDir, "backups"),
+		backupRetention:  14 * 24 * time.Hour,
+		playbooksDir:     filepath.Join(repoPath, ".mastermind", "playbooks"),
+		mergeDefaults:    MergeChoices{DeleteBranch: true, RemoveWorktree: true, Strategy: MergeCommit},
+		rememberMerge:    true,
+		mergeRetries:     3,
+		rerere:           true,
+		largeConflict:    1 << 20,
 		harnesses: map[harness.Type]harness.Harness{
 			harness.TypeClaudeCode: &claudecode.Harness{},
 			harness.TypeOpenCode:   &opencode.Harness{},
 		},
-		defaultHarness:       harness.TypeClaudeCode,
-		notifier:             notify.NoopNotifier{},
-		idleHasChanges:       make(map[string]*bool),
-		hookMtimeCache:       make(map[string]mtimeEntry),
-		statuslineMtimeCache: make(map[string]mtimeEntry),
-		todosMtimeCache:      make(map[string]mtimeEntry),
+		defaultHarness: harness.TypeClaudeCode,
+		notifier:       notify.NoopNotifier{},
+		bus:            monitor.NewBus(),
+		windowNames:    make(map[string]string),
+		goneAfter:      monitor.DefaultGoneAfter,
+		procs:          RealProcesses{},
+		stopTimeout:    5 * time.Second,
 	}
 	for _, opt := range opts {
 		opt(o)
 	}
+	o.mon = monitor.New(store,
+		monitor.WithGit(o.git),
+		monitor.WithTmux(o.tmux),
+		monitor.WithPaneChecker(o.monitor),
+		monitor.WithHarnesses(o.harnesses),
+		monitor.WithEmitter(o.handleMonitorEvent),
+		monitor.WithProviderOrder(o.statusProviders...),
+		monitor.WithGoneAfter(o.goneAfter),
+	)
 	return o
 }
 
+// SetProgram attaches the TUI. Monitor events and changes to the agent
+// store are forwarded to it as tea messages. No event is dropped while
+// the TUI is busy.
 func (o *Orchestrator) SetProgram(p *tea.Program) {
 	o.program = p
+	events := o.bus.SubscribeAll()
+	go func() {
+		for ev := range events {
+			p.Send(ev)
+		}
+	}()
+	changes := o.store.Subscribe(64)
+	go func() {
+		defer o.store.Unsubscribe(changes)
+		for {
+			select {
+			case <-o.ctx.Done():
+				return
+			case c := <-changes:
+				p.Send(StoreChangedMsg(c))
+			}
+		}
+	}()
+}
+
+// ErrMaxRunning is returned when spawning would exceed the cap set with
+// WithMaxRunning.
+var ErrMaxRunning = errors.New("too many agents working")
+
+// ErrBaseMoved is returned by a merge when the base branch gained commits
+// the agent's branch doesn't have after base was merged into it, e.g.
+// because another agent merged concurrently. Moving base would discard
+// them, so it is left alone.
+var ErrBaseMoved = errors.New("base branch moved during the merge")
+
+// Working returns how many agents are running or waiting on the user,
+// the agents counted against the WithMaxRunning cap.
+func (o *Orchestrator) Working() int {
+	n := 0
+	for _, a := range o.store.All() {
+		if s := a.GetStatus(); s == agent.StatusRunning || s == agent.StatusWaiting {
+			n++
+		}
+	}
+	return n
+}
+
+// HasCapacity reports whether another agent may be spawned under the
+// WithMaxRunning cap.
+func (o *Orchestrator) HasCapacity() bool {
+	return o.maxRunning <= 0 || o.Working() < o.maxRunning
+}
+
+// AgentBranches maps each tracked agent's branch to its agent ID.
+func (o *Orchestrator) AgentBranches() map[string]string {
+	branches := make(map[string]string)
+	for _, a := range o.store.All() {
+		branches[a.Branch] = a.ID
+	}
+	return branches
 }
 
 func (o *Orchestrator) DefaultHarness() harness.Type {
 	return o.defaultHarness
 }
 
-func (o *Orchestrator) SpawnAgent(branch, baseBranch string, createBranch bool, harnessType harness.Type) error {
+// Harnesses returns the harness types agents can be spawned with: Claude
+// Code and OpenCode, then any others registered with WithHarness by name.
+func (o *Orchestrator) Harnesses() []harness.Type {
+	types := []harness.Type{harness.TypeClaudeCode, harness.TypeOpenCode}
+	var others []harness.Type
+	for t := range o.harnesses {
+		if t != harness.TypeClaudeCode && t != harness.TypeOpenCode {
+			others = append(others, t)
+		}
+	}
+	slices.Sort(others)
+	return append(types, others...)
+}
+
+// HasHarness reports whether agents can be spawned with harness t.
+func (o *Orchestrator) HasHarness(t harness.Type) bool {
+	_, ok := o.harnesses[t]
+	return ok
+}
+
+// patchPrompt is the initial task for agents spawned from a patch.
+const patchPrompt = "A patch with unfinished work has been applied to this worktree as uncommitted changes. " +
+	"Review it with `git diff`, then finish and fix this work."
+
+// spawnRequest describes an agent to spawn.
+type spawnRequest struct {
+	branch       string
+	baseBranch   string
+	createBranch bool
+	harness      harness.Type
+	// patch is applied to the new worktree before the agent starts.
+	patch []byte
+	// prompt is the agent's initial task.
+	prompt string
+	// task is typed into the agent's pane once it is ready for input.
+	task string
+	// files are written into the worktree (and excluded from git)
+	// before the agent starts, keyed by relative path.
+	files map[string]string
+	// session is the tmux session the agent window opens in. Empty means
+	// mastermind's own session.
+	session string
+	// ticket links the agent to a tracker ticket; ticketTitle is its
+	// title, if known.
+	ticket      string
+	ticketTitle string
+	// startPoint is where a new branch is created from; empty means
+	// baseBranch.
+	startPoint string
+	// copyFrom is a worktree whose uncommitted changes are copied into the
+	// new worktree before the agent starts.
+	copyFrom string
+	// reuseWorktree adopts the branch's existing worktree (see
+	// ReusableWorktree) instead of creating one.
+	reuseWorktree bool
+	// readOnly marks the agent as a research agent whose branch is never
+	// merged, pushed or deleted by mastermind.
+	readOnly bool
+	// report makes the agent a report agent: read-only, with its report
+	// collected when it finishes instead of a review.
+	report bool
+	// playbook is the playbook the agent runs, if any.
+	playbook *playbook.Playbook
+	// team is the name of the agent team the agent is spawned to lead, if
+	// any, and teamTask the team's task.
+	team     string
+	teamTask string
+	// fetchBase fast-forwards baseBranch from origin before the branch is
+	// created from it; nil uses WithFetchBase.
+	fetchBase *bool
+}
+
+// SpawnOption adjusts how an agent is spawned.
+type SpawnOption func(*spawnRequest)
+
+// InSession opens the agent's window in the named tmux session, creating
+// the session if it does not exist. An empty name means mastermind's own
+// session.
+func InSession(name string) SpawnOption {
+	return func(r *spawnRequest) { r.session = name }
+}
+
+// ReuseWorktree spawns the agent in the branch's existing worktree under
+// the worktree dir, e.g. one left behind by a crashed session, instead of
+// refusing the branch as checked out. It only applies to existing branches.
+func ReuseWorktree() SpawnOption {
+	return func(r *spawnRequest) { r.reuseWorktree = true }
+}
+
+// WithTask types task, which may span several lines, into the agent's
+// pane once it has started and is ready for input, as if pasted by the
+// user.
+func WithTask(task string) SpawnOption {
+	return func(r *spawnRequest) { r.task = task }
+}
+
+// ReadOnly spawns a research agent whose output is only wanted as a
+// report: it cannot be merged or push, and dismissing it keeps its branch.
+func ReadOnly() SpawnOption {
+	return func(r *spawnRequest) { r.readOnly = true }
+}
+
+// Report spawns a read-only agent whose deliverable is a document: it is
+// asked to write the report file, which is collected into the reports
+// directory when it finishes, skipping review and merge.
+func Report() SpawnOption {
+	return func(r *spawnRequest) {
+		r.readOnly = true
+		r.report = true
+	}
+}
+
+func (o *Orchestrator) SpawnAgent(branch, baseBranch string, createBranch bool, harnessType harness.Type, opts ...SpawnOption) error {
+	req := spawnRequest{
+		branch:       branch,
+		baseBranch:   baseBranch,
+		createBranch: createBranch,
+		harness:      harnessType,
+	}
+	r := req
+	for _, opt := range opts {
+		opt(&r)
+	}
+	params := ipc.SpawnParams{
+		Branch:        r.branch,
+		BaseBranch:    r.baseBranch,
+		CreateBranch:  r.createBranch,
+		Harness:       string(r.harness),
+		Session:       r.session,
+		Ticket:        r.ticket,
+		TicketTitle:   r.ticketTitle,
+		ReuseWorktree: r.reuseWorktree,
+		ReadOnly:      r.readOnly,
+		Report:        r.report,
+		Playbook:      playbookPath(r.playbook),
+		TeamTask:      r.teamTask,
+		Task:          r.task,
+		FetchBase:     r.fetchBase,
+	}
+	handled, err := o.remoteCall(func(c *ipc.Client) error {
+		return c.Spawn(params)
+	})
+	if !handled {
+		err = o.spawnAgent(req, opts...)
+	}
+	o.record(ipc.OpSpawn, branch, params, err)
+	return err
+}
+
+// SpawnAgentFromPatch creates branch from baseBranch, applies patch to
+// the new worktree as uncommitted changes and starts an agent instructed
+// to finish the work.
+func (o *Orchestrator) SpawnAgentFromPatch(branch, baseBranch string, patch []byte, harnessType harness.Type, opts ...SpawnOption) error {
+	if len(bytes.TrimSpace(patch)) == 0 {
+		return fmt.Errorf("patch is empty")
+	}
+	return o.spawnAgent(spawnRequest{
+		branch:       branch,
+		baseBranch:   baseBranch,
+		createBranch: true,
+		harness:      harnessType,
+		patch:        patch,
+		prompt:       patchPrompt,
+	}, opts...)
+}
+
+// SpawnAgentFromCI starts an agent on a failing run's branch with the
+// failure logs in its prompt. A branch that only exists on the remote is
+// fetched and created from origin.
+func (o *Orchestrator) SpawnAgentFromCI(run ci.Run, logs string, harnessType harness.Type, opts ...SpawnOption) error {
+	if run.Branch == "" {
+		return fmt.Errorf("run %d has no branch", run.ID)
+	}
+	created := false
+	if !o.git.BranchExists(o.repoPath, run.Branch) {
+		// origin/<branch> may predate the run, or not exist yet.
+		if err := o.git.FetchBranch(o.repoPath, run.Branch); err != nil {
+			return fmt.Errorf("fetch branch from origin: %w", err)
+		}
+		if err := o.git.CreateBranch(o.repoPath, run.Branch, "origin/"+run.Branch); err != nil {
+			return fmt.Errorf("create branch from origin: %w", err)
+		}
+		created = true
+	}
+	err := o.spawnAgent(spawnRequest{
+		branch:  run.Branch,
+		harness: harnessType,
+		prompt:  ci.Prompt(run),
+		files:   map[string]string{ci.LogFileName: logs},
+	}, opts...)
+	if err != nil && created {
+		o.git.DeleteBranch(o.repoPath, run.Branch)
+	}
+	return err
+}
+
+func (o *Orchestrator) spawnAgent(req spawnRequest, opts ...SpawnOption) error {
+	for _, opt := range opts {
+		opt(&req)
+	}
+	branch, baseBranch, createBranch, harnessType := req.branch, req.baseBranch, req.createBranch, req.harness
+	reuse := req.reuseWorktree && !createBranch
+
+	if !o.HasCapacity() {
+		return fmt.Errorf("%w: %d of max_running = %d", ErrMaxRunning, o.Working(), o.maxRunning)
+	}
+
 	// Guard against worktree name collision
 	for _, existing := range o.store.All() {
 		if existing.Branch == branch {
@@ -241,33 +771,111 @@ func (o *Orchestrator) SpawnAgent(branch, baseBranch string, createBranch bool,
 	}
 
 	// Guard against branch already checked out in another worktree (e.g. the main working tree)
-	if !createBranch {
+	if !createBranch && !reuse {
 		if checkedOut, err := o.git.IsBranchCheckedOut(o.repoPath, branch); err == nil && checkedOut {
 			return fmt.Errorf("branch %q is already checked out in another worktree", branch)
 		}
 	}
 
 	if createBranch {
-		if err := o.git.CreateBranch(o.repoPath, branch, baseBranch); err != nil {
-			return fmt.Errorf("create branch: %w", err)
+		if err := git.ValidateBranchName(branch); err != nil {
+			return fmt.Errorf("invalid branch name: %w", err)
 		}
 	}
 
-	wtPath, err := o.git.CreateWorktree(o.repoPath, o.worktreeDir, branch)
-	if err != nil {
-		return fmt.Errorf("create worktree: %w", err)
-	}
-
-	// Get the harness implementation
 	h, ok := o.harnesses[harnessType]
 	if !ok {
-		o.git.RemoveWorktree(o.repoPath, wtPath)
 		return fmt.Errorf("unknown harness type: %s", harnessType)
 	}
+	if req.team != "" && harnessType != harness.TypeClaudeCode {
+		return fmt.Errorf("agent teams need Claude Code, not %s", harnessType)
+	}
+
+	// Until the agent's window is open, a failure undoes what this call
+	// created. A reused worktree and an existing branch predate it and
+	// are left in place.
+	var createdBranch, createdWorktree, spawned bool
+	var wtPath string
+	defer func() {
+		if spawned {
+			return
+		}
+		if createdWorktree {
+			o.git.RemoveWorktree(o.repoPath, wtPath)
+		}
+		if createdBranch {
+			o.git.DeleteBranch(o.repoPath, branch)
+		}
+	}()
+
+	if reuse {
+		var err error
+		if wtPath, err = o.ReusableWorktree(branch); err != nil {
+			return err
+		}
+		if wtPath == "" {
+			return fmt.Errorf("no worktree of branch %q to reuse", branch)
+		}
+		slog.Info("reusing existing worktree", "branch", branch, "path", wtPath)
+	} else {
+		wtPath = o.worktreePathFor(branch)
+		if err := o.checkWorktreeNesting(wtPath); err != nil {
+			return err
+		}
+
+		if createBranch {
+			startPoint := req.startPoint
+			if startPoint == "" {
+				startPoint = baseBranch
+				fetch := o.fetchBase
+				if req.fetchBase != nil {
+					fetch = *req.fetchBase
+				}
+				if fetch {
+					o.fastForwardBase(baseBranch)
+				}
+			}
+			if err := o.git.CreateBranch(o.repoPath, branch, startPoint); err != nil {
+				return fmt.Errorf("create branch: %w", err)
+			}
+			createdBranch = true
+		}
+
+		var err error
+		if wtPath, err = o.git.CreateWorktree(o.repoPath, wtPath, branch); err != nil {
+			return fmt.Errorf("create worktree: %w", err)
+		}
+		createdWorktree = true
+	}
+
+	if req.patch != nil {
+		if err := o.git.ApplyPatch(wtPath, req.patch); err != nil {
+			return fmt.Errorf("apply patch: %w", err)
+		}
+	}
+
+	if req.copyFrom != "" {
+		if err := o.git.CopyUncommittedChanges(req.copyFrom, wtPath); err != nil {
+			return fmt.Errorf("copy uncommitted changes: %w", err)
+		}
+	}
+
+	for name, content := range req.files {
+		if err := os.WriteFile(filepath.Join(wtPath, name), []byte(content), 0o644); err != nil {
+			slog.Warn("failed to write agent file", "path", name, "error", err)
+			continue
+		}
+		if err := appendGitExclude(wtPath, name, ""); err != nil {
+			slog.Warn("failed to exclude agent file from git", "path", name, "error", err)
+		}
+	}
 
+	if err := o.guardPushes(wtPath, req.readOnly); err != nil {
+		return err
+	}
 	// Setup harness (writes hooks/plugins/config)
 	setupOpts := harness.SetupOptions{
-		AgentTeams:   o.agentTeams,
+		AgentTeams:   o.agentTeams || req.team != "",
 		TeammateMode: o.teammateMode,
 	}
 	if err := h.Setup(wtPath, setupOpts); err != nil {
@@ -277,20 +885,56 @@ func (o *Orchestrator) SpawnAgent(branch, baseBranch string, createBranch bool,
 	// Build command
 	cmdOpts := harness.Options{
 		SkipPermissions: o.skipPermissions,
+		StreamJSON:      o.streamJSON,
+		Prompt:          req.prompt,
 	}
-	cmd := h.Command(cmdOpts)
+	if req.ticket != "" {
+		cmdOpts.Context = ticket.Context(req.ticket, req.ticketTitle)
+	}
+	if req.playbook != nil {
+		cmdOpts.Context = strings.TrimSpace(cmdOpts.Context + "\n\n" + req.playbook.Context())
+	}
+	if req.report {
+		cmdOpts.Context = strings.TrimSpace(cmdOpts.Context + "\n\n" + reportContext(o.reportFile))
+		if err := appendGitExclude(wtPath, o.reportFile, ""); err != nil {
+			slog.Warn("failed to exclude report file from git", "path", wtPath, "error", err)
+		}
+	}
+	cmd := o.wrapCommand(wtPath, h.Command(cmdOpts))
 
 	// Launch in tmux
-	paneID, err := o.tmux.NewWindow(o.session, branch, wtPath, cmd)
+	session, err := o.spawnSession(req.session)
+	if err != nil {
+		return err
+	}
+	paneID, err := o.tmux.NewWindow(session, branch, wtPath, cmd)
 	if err != nil {
-		o.git.RemoveWorktree(o.repoPath, wtPath)
 		return fmt.Errorf("create tmux window: %w", err)
 	}
+	spawned = true
 
 	windowID, _ := o.tmux.WindowIDForPane(paneID)
 
 	a := agent.NewAgent(branch, baseBranch, wtPath, windowID, paneID, harnessType)
+	a.Ticket = req.ticket
+	a.Prompt = req.prompt
+	if req.task != "" {
+		a.SetPendingTask(req.task)
+		if a.Prompt == "" {
+			a.Prompt = req.task
+		}
+	}
+	a.ReadOnly = req.readOnly
+	if req.report {
+		a.ReportFile = o.reportFile
+	}
+	if req.playbook != nil {
+		a.Playbook = req.playbook.Path
+	}
+	a.Team = req.team
 	o.store.Add(a)
+	o.moveTicket(a.Ticket, o.ticketInProgress)
+	o.openLayoutPanes(paneID, wtPath)
 
 	// Open prompt editor split pane if enabled
 	if o.promptEditor {
@@ -309,7 +953,7 @@ func (o *Orchestrator) SpawnAgent(branch, baseBranch string, createBranch bool,
 	}
 
 	// Write agent metadata so orphaned worktrees can be rediscovered
-	writeAgentMetadata(wtPath, baseBranch, "", harnessType)
+	writeAgentMetadata(wtPath, branch, baseBranch, "", harnessType)
 	if err := appendGitExclude(wtPath, agentMetadataFile, ""); err != nil {
 		slog.Warn("failed to exclude agent metadata from git", "path", wtPath, "error", err)
 	}
@@ -320,6 +964,37 @@ func (o *Orchestrator) SpawnAgent(branch, baseBranch string, createBranch bool,
 	return nil
 }
 
+// openLayoutPanes splits the agent pane according to the window layout
+// template. The agent pane stays active; failures only cost the pane.
+func (o *Orchestrator) openLayoutPanes(agentPaneID, wtPath string) {
+	for _, p := range o.windowPanes {
+		size := p.Size
+		if size <= 0 || size >= 100 {
+			size = 30
+		}
+		var cmd []string
+		if p.Command != "" {
+			cmd = []string{p.Command}
+		}
+		if _, err := o.tmux.SplitWindowDetached(agentPaneID, wtPath, p.Split != "below", size, cmd); err != nil {
+			slog.Warn("failed to open layout pane", "command", p.Command, "error", err)
+		}
+	}
+}
+
+// wrapCommand routes an agent command through the exit-code shim when
+// enabled, clearing any result left by a previous run in the worktree.
+func (o *Orchestrator) wrapCommand(wtPath string, cmd []string) []string {
+	if !o.useShim {
+		return cmd
+	}
+	shim.RemoveResult(wtPath)
+	if err := appendGitExclude(wtPath, shim.ResultFileName, ""); err != nil {
+		slog.Warn("failed to exclude shim result from git", "path", wtPath, "error", err)
+	}
+	return shim.Wrap(cmd)
+}
+
 // ResumeAgent reopens a tmux window for an orphaned agent and resumes
 // the Claude Code session using the stored session ID.
 func (o *Orchestrator) ResumeAgent(id string) error {
@@ -337,6 +1012,32 @@ func (o *Orchestrator) ResumeAgent(id string) error {
 		return fmt.Errorf("worktree directory no longer exists: %s", a.WorktreePath)
 	}
 
+	sessionID := a.GetSessionID()
+	paneID, err := o.tmux.NewWindow(o.currentSession(), a.Branch, a.WorktreePath, o.resumeCommand(a))
+	if err != nil {
+		return fmt.Errorf("create tmux window: %w", err)
+	}
+
+	windowID, _ := o.tmux.WindowIDForPane(paneID)
+
+	// Update the agent's tmux references (these are immutable fields, but
+	// for orphan recovery we need to set them on the existing agent)
+	a.TmuxWindow = windowID
+	a.TmuxPaneID = paneID
+	a.SetStatus(agent.StatusRunning)
+	o.openLayoutPanes(paneID, a.WorktreePath)
+
+	o.store.MarkDirty()
+	o.saveState()
+	slog.Info("resumed orphaned agent", "id", a.ID, "branch", a.Branch, "sessionID", sessionID)
+
+	return nil
+}
+
+// resumeCommand prepares an agent's worktree for its Claude Code session
+// to be resumed and returns the command resuming it, or starting a new
+// session if none was recorded.
+func (o *Orchestrator) resumeCommand(a *agent.Agent) []string {
 	// Write Claude Code project settings and hooks
 	if err := o.writeClaudeProjectSettings(a.WorktreePath); err != nil {
 		slog.Warn("failed to write claude project settings", "error", err)
@@ -345,37 +1046,77 @@ func (o *Orchestrator) ResumeAgent(id string) error {
 		slog.Warn("failed to write hook files", "error", err)
 	}
 
+	// Resumed sessions are interactive; a leftover stream relay state
+	// file would otherwise outrank the hook status.
+	_ = os.Remove(filepath.Join(a.WorktreePath, streamjson.StateFileName))
+
 	// Build claude command with optional resume flag
 	claudeCmd := []string{"claude"}
 	if o.skipPermissions {
 		claudeCmd = append(claudeCmd, "--dangerously-skip-permissions")
 	}
-	sessionID := a.GetSessionID()
-	if sessionID != "" {
+	if sessionID := a.GetSessionID(); sessionID != "" {
 		claudeCmd = append(claudeCmd, "--resume", sessionID)
 	}
+	return o.wrapCommand(a.WorktreePath, claudeCmd)
+}
 
-	paneID, err := o.tmux.NewWindow(o.session, a.Branch, a.WorktreePath, claudeCmd)
-	if err != nil {
-		return fmt.Errorf("create tmux window: %w", err)
+// resumeDeadPane restarts the Claude Code session of a recovered agent
+// whose process died while it was working, e.g. when the machine slept
+// or the tmux server hiccuped, in the pane remain-on-exit kept. Without
+// it the monitor would take the dead pane for a finished agent. It
+// reports whether the session was resumed.
+func (o *Orchestrator) resumeDeadPane(a *agent.Agent) bool {
+	switch a.GetStatus() {
+	case agent.StatusRunning, agent.StatusWaiting:
+	default:
+		return false
+	}
+	if a.Harness != harness.TypeClaudeCode && a.Harness != "" {
+		return false
+	}
+	sessionID := a.GetSessionID()
+	if sessionID == "" {
+		return false
+	}
+	ps, err := o.monitor.GetPaneStatus(a.TmuxPaneID)
+	if err != nil || !ps.Dead {
+		return false
+	}
+	// An exit the shim recorded as clean was the agent quitting, not dying.
+	if res, _ := shim.ReadResult(a.WorktreePath); res != nil && res.ExitCode == 0 {
+		return false
 	}
 
-	windowID, _ := o.tmux.WindowIDForPane(paneID)
-
-	// Update the agent's tmux references (these are immutable fields, but
-	// for orphan recovery we need to set them on the existing agent)
-	a.TmuxWindow = windowID
-	a.TmuxPaneID = paneID
+	if err := o.tmux.RespawnPaneWith(a.TmuxPaneID, a.WorktreePath, o.resumeCommand(a)); err != nil {
+		slog.Warn("failed to resume session of recovered agent", "id", a.ID, "sessionID", sessionID, "error", err)
+		return false
+	}
 	a.SetStatus(agent.StatusRunning)
-
+	a.SetWaitingFor("")
+	a.SetAttentionReason(nil)
 	o.store.MarkDirty()
-	o.saveState()
-	slog.Info("resumed orphaned agent", "id", a.ID, "branch", a.Branch, "sessionID", sessionID)
-
-	return nil
+	slog.Info("resumed session of recovered agent", "id", a.ID, "branch", a.Branch, "sessionID", sessionID)
+	return true
 }
 
 func (o *Orchestrator) DismissAgent(id string, deleteBranch bool) error {
+	branch := o.agentBranch(id)
+	err := o.dismissAgent(id, deleteBranch)
+	o.record(ipc.OpDismiss, branch, ipc.DismissParams{ID: id, DeleteBranch: deleteBranch}, err)
+	return err
+}
+
+// dismissAgent dismisses an agent without recording it as the user's
+// action.
+func (o *Orchestrator) dismissAgent(id string, deleteBranch bool) error {
+	handled, err := o.remoteCall(func(c *ipc.Client) error {
+		return c.Dismiss(ipc.DismissParams{ID: id, DeleteBranch: deleteBranch})
+	})
+	if handled {
+		return err
+	}
+
 	a, ok := o.store.Get(id)
 	if !ok {
 		return fmt.Errorf("agent %s not found", id)
@@ -385,51 +1126,64 @@ func (o *Orchestrator) DismissAgent(id string, deleteBranch bool) error {
 		o.monitor.Remove(a.TmuxPaneID)
 	}
 
-	// Gracefully stop Claude if the pane is still alive
-	if a.TmuxPaneID != "" && o.tmux.PaneExistsInWindow(a.TmuxPaneID, a.TmuxWindow) {
-		status := a.GetStatus()
-		if status == agent.StatusRunning || status == agent.StatusWaiting {
-			// Send Ctrl+C to interrupt, then /exit to quit cleanly
-			o.tmux.SendKeys(a.TmuxPaneID, "C-c")
-			o.tmux.SendKeys(a.TmuxPaneID, "/exit", "Enter")
-			// Give Claude a moment to shut down
-			time.Sleep(500 * time.Millisecond)
-		}
-	}
-
-	// Kill lazygit pane if open
-	if lgPane := a.GetLazygitPaneID(); lgPane != "" {
-		if err := o.tmux.KillPane(lgPane); err != nil {
-			slog.Warn("failed to kill lazygit pane", "id", id, "pane", lgPane, "error", err)
-		}
-	}
+	// Gracefully stop Claude if the pane is still alive, killing its
+	// processes if it won't exit
+	forceKilled := o.stopAgent(a)
 
-	if a.TmuxWindow != "" {
-		if err := o.tmux.KillWindow(a.TmuxWindow); err != nil {
-			slog.Warn("failed to kill tmux window", "id", id, "window", a.TmuxWindow, "error", err)
-		}
-	}
+	o.killAgentWindow(a)
 
 	if a.WorktreePath != "" {
-		if err := o.git.RemoveWorktree(o.repoPath, a.WorktreePath); err != nil {
+		o.restoreClaudeSettings(a)
+		if o.holdForOrphans(a, deleteBranch) {
+			// The branch is checked out in the worktree we keep.
+			deleteBranch = false
+		} else if err := o.removeWorktree(a.Branch, a.WorktreePath); err != nil {
 			slog.Warn("failed to remove worktree", "id", id, "path", a.WorktreePath, "error", err)
+			// The branch is checked out in the worktree left behind.
+			deleteBranch = false
 		}
 	}
 
+	if a.ReadOnly {
+		deleteBranch = false
+	}
 	if deleteBranch && a.Branch != "" {
-		if err := o.git.DeleteBranch(o.repoPath, a.Branch); err != nil {
+		if n, err := o.git.UnmergedCommits(o.repoPath, a.Branch); err == nil && n > 0 && !o.archiveBranches {
+			slog.Warn("deleting branch with unmerged commits", "id", id, "branch", a.Branch, "commits", n)
+		}
+		if err := o.removeBranch(a.Branch); err != nil {
 			slog.Warn("failed to delete branch", "id", id, "branch", a.Branch, "error", err)
 		}
 	}
 
 	o.store.Remove(id)
 
-	slog.Info("agent dismissed", "id", id, "deleteBranch", deleteBranch)
+	slog.Info("agent dismissed", "id", id, "deleteBranch", deleteBranch, "forceKilled", forceKilled)
 	o.saveState()
 
 	return nil
 }
 
+// killAgentWindow kills the agent's lazygit and scratch shell panes, if
+// open, and its tmux window.
+func (o *Orchestrator) killAgentWindow(a *agent.Agent) {
+	if lgPane := a.GetLazygitPaneID(); lgPane != "" {
+		if err := o.tmux.KillPane(lgPane); err != nil {
+			slog.Warn("failed to kill lazygit pane", "id", a.ID, "pane", lgPane, "error", err)
+		}
+	}
+	if shPane := a.GetShellPaneID(); shPane != "" {
+		if err := o.tmux.KillPane(shPane); err != nil {
+			slog.Warn("failed to kill shell pane", "id", a.ID, "pane", shPane, "error", err)
+		}
+	}
+	if a.TmuxWindow != "" {
+		if err := o.tmux.KillWindow(a.TmuxWindow); err != nil {
+			slog.Warn("failed to kill tmux window", "id", a.ID, "window", a.TmuxWindow, "error", err)
+		}
+	}
+}
+
 func (o *Orchestrator) PruneAgent(id string) PruneResultMsg {
 	a, ok := o.store.Get(id)
 	if !ok {
@@ -444,32 +1198,18 @@ func (o *Orchestrator) PruneAgent(id string) PruneResultMsg {
 		o.monitor.Remove(a.TmuxPaneID)
 	}
 
-	// Gracefully stop Claude if the pane is still alive
-	if a.TmuxPaneID != "" && o.tmux.PaneExistsInWindow(a.TmuxPaneID, a.TmuxWindow) {
-		status := a.GetStatus()
-		if status == agent.StatusRunning || status == agent.StatusWaiting {
-			o.tmux.SendKeys(a.TmuxPaneID, "C-c")
-			o.tmux.SendKeys(a.TmuxPaneID, "/exit", "Enter")
-			time.Sleep(500 * time.Millisecond)
-		}
-	}
-
-	// Kill lazygit pane if open
-	if lgPane := a.GetLazygitPaneID(); lgPane != "" {
-		if err := o.tmux.KillPane(lgPane); err != nil {
-			slog.Warn("failed to kill lazygit pane", "id", id, "pane", lgPane, "error", err)
-		}
-	}
+	// Gracefully stop Claude if the pane is still alive, killing its
+	// processes if it won't exit
+	o.stopAgent(a)
 
-	if a.TmuxWindow != "" {
-		if err := o.tmux.KillWindow(a.TmuxWindow); err != nil {
-			slog.Warn("failed to kill tmux window", "id", id, "window", a.TmuxWindow, "error", err)
-		}
-	}
+	o.killAgentWindow(a)
 
 	if a.WorktreePath != "" {
-		if err := o.git.RemoveWorktree(o.repoPath, a.WorktreePath); err != nil {
-			slog.Warn("failed to remove worktree", "id", id, "path", a.WorktreePath, "error", err)
+		o.restoreClaudeSettings(a)
+		if !o.holdForOrphans(a, false) {
+			if err := o.removeWorktree(a.Branch, a.WorktreePath); err != nil {
+				slog.Warn("failed to remove worktree", "id", id, "path", a.WorktreePath, "error", err)
+			}
 		}
 	}
 
@@ -487,12 +1227,20 @@ func (o *Orchestrator) FocusAgent(id string) error {
 		return fmt.Errorf("agent %s not found", id)
 	}
 
+	if o.inOtherSession(a) {
+		if err := o.tmux.SwitchClient(a.TmuxWindow); err != nil {
+			return fmt.Errorf("switch client: %w", err)
+		}
+	}
 	if err := o.tmux.SelectWindow(a.TmuxWindow); err != nil {
 		return fmt.Errorf("select window: %w", err)
 	}
 	return o.tmux.SelectPane(a.TmuxPaneID)
 }
 
+// shellSplit is the percentage of the agent pane given to a scratch shell.
+const shellSplit = 30
+
 func (o *Orchestrator) OpenLazyGit(id string) error {
 	a, ok := o.store.Get(id)
 	if !ok {
@@ -512,426 +1260,155 @@ func (o *Orchestrator) OpenLazyGit(id string) error {
 
 	shell := os.Getenv("SHELL")
 	if shell == "" {
-		shell = "/bin/bash"
-	}
-	paneID, err := o.tmux.SplitWindow(a.TmuxPaneID, a.WorktreePath, true, o.lazygitSplit, []string{shell, "-lc", "export GPG_TTY=$(tty); exec lazygit"})
-	if err != nil {
-		return fmt.Errorf("split window for lazygit: %w", err)
-	}
-
-	a.SetLazygitPaneID(paneID)
-	// Callers set the status themselves (allows StatusConflicts to open lazygit without change)
-	return nil
-}
-
-func (o *Orchestrator) StartMonitor() {
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-o.ctx.Done():
-			// Force-save on shutdown regardless of debounce
-			if o.store.IsDirty() {
-				o.doSaveState()
-			}
-			slog.Info("monitor stopped: context cancelled")
-			return
-		case <-ticker.C:
-		}
-
-		agents := o.store.All()
-
-		// Batch-fetch all panes in the session (1 subprocess) — now includes dead/exit status
-		allPanes, paneListErr := o.tmux.ListAllPanes(o.session)
-		if paneListErr != nil {
-			slog.Debug("ListAllPanes failed, falling back to per-agent checks", "error", paneListErr)
-			allPanes = nil // nil signals fallback
-		}
-
-		// paneInWindow checks if a pane exists in the expected window,
-		// using the batch result when available.
-		paneInWindow := func(paneID, windowID string) bool {
-			if allPanes != nil {
-				info, ok := allPanes[paneID]
-				return ok && info.WindowID == windowID
-			}
-			return o.tmux.PaneExistsInWindow(paneID, windowID)
-		}
-
-		// paneDeadFromBatch returns dead status from batch result, or falls back to GetPaneStatus.
-		paneDeadFromBatch := func(paneID string) (dead bool, exitCode int, err error) {
-			if allPanes != nil {
-				if info, ok := allPanes[paneID]; ok {
-					return info.Dead, info.ExitCode, nil
-				}
-				// Pane not in batch = gone
-				return false, 0, fmt.Errorf("pane not in session")
-			}
-			// Fallback: individual subprocess call
-			ps, err := o.monitor.GetPaneStatus(paneID)
-			if err != nil {
-				return false, 0, err
-			}
-			return ps.Dead, ps.ExitCode, nil
-		}
-
-		for _, a := range agents {
-			snap := a.Snapshot()
-
-			// Handle lazygit pane detection for reviewing/conflicts agents
-			if (snap.Status == agent.StatusReviewing || snap.Status == agent.StatusConflicts) && snap.LazygitPaneID != "" {
-				lgGone := !paneInWindow(snap.LazygitPaneID, a.TmuxWindow)
-				if !lgGone {
-					// Pane exists but may be dead (remain-on-exit keeps it around).
-					dead, _, err := paneDeadFromBatch(snap.LazygitPaneID)
-					lgGone = err != nil || dead
-				}
-				if lgGone {
-					o.tmux.KillPane(snap.LazygitPaneID)
-					o.handleLazygitClosed(a, snap.Status)
-				}
-				continue
-			}
-
-			switch snap.Status {
-			case agent.StatusRunning, agent.StatusWaiting,
-				agent.StatusReviewReady, agent.StatusDone:
-				// These statuses need monitoring
-			default:
-				continue
-			}
-
-			// Check if pane still exists
-			if !paneInWindow(a.TmuxPaneID, a.TmuxWindow) {
-				slog.Debug("pane gone, marking dismissed", "id", a.ID, "pane", a.TmuxPaneID)
-				o.monitor.Remove(a.TmuxPaneID)
-				a.SetStatus(agent.StatusDismissed)
-				o.store.MarkDirty()
-				delete(o.idleHasChanges, a.ID)
-				if o.program != nil {
-					o.program.Send(AgentGoneMsg{AgentID: a.ID})
-				}
-				continue
-			}
-
-			// Check for dead pane from batch result (no extra subprocess)
-			dead, exitCode, err := paneDeadFromBatch(a.TmuxPaneID)
-			if err != nil {
-				slog.Debug("pane gone, marking dismissed", "id", a.ID, "pane", a.TmuxPaneID)
-				o.monitor.Remove(a.TmuxPaneID)
-				a.SetStatus(agent.StatusDismissed)
-				o.store.MarkDirty()
-				delete(o.idleHasChanges, a.ID)
-				if o.program != nil {
-					o.program.Send(AgentGoneMsg{AgentID: a.ID})
-				}
-				continue
-			}
-
-			if dead {
-				o.handleAgentFinished(a, exitCode)
-				continue
-			}
-
-			// Try hook-based status detection first (skip tmux capture if fresh)
-			if o.handleHookStatus(a, snap.Status) {
-				o.readStatuslineCached(a)
-				o.readTodosCached(a)
-				continue
-			}
-
-			// Fall back to tmux content polling
-			paneStatus, err := o.monitor.GetPaneStatus(a.TmuxPaneID)
-			if err != nil {
-				slog.Debug("pane status error, marking dismissed", "id", a.ID, "pane", a.TmuxPaneID)
-				o.monitor.Remove(a.TmuxPaneID)
-				a.SetStatus(agent.StatusDismissed)
-				o.store.MarkDirty()
-				delete(o.idleHasChanges, a.ID)
-				if o.program != nil {
-					o.program.Send(AgentGoneMsg{AgentID: a.ID})
-				}
-				continue
-			}
-
-			if paneStatus.WaitingFor == "" {
-				// Claude is actively working
-				a.SetEverActive(true)
-				delete(o.idleHasChanges, a.ID)
-				if snap.Status != agent.StatusRunning {
-					a.SetStatus(agent.StatusRunning)
-					a.SetWaitingFor("")
-					o.store.MarkDirty()
-					slog.Debug("agent status change (tmux)", "id", a.ID, "status", "running")
-				}
-			} else if paneStatus.WaitingFor == "permission" {
-				a.SetEverActive(true)
-				if snap.Status != agent.StatusWaiting || snap.WaitingFor != "permission" {
-					a.SetStatus(agent.StatusWaiting)
-					a.SetWaitingFor("permission")
-					o.store.MarkDirty()
-					slog.Debug("agent status change (tmux)", "id", a.ID, "status", "waiting", "waitingFor", "permission")
-					o.triggerAttention(a.ID, fmt.Sprintf("Agent %s needs permission", a.ID))
-					if o.program != nil {
-						o.program.Send(AgentWaitingMsg{
-							AgentID:    a.ID,
-							WaitingFor: "permission",
-						})
-					}
-				}
-			} else if snap.EverActive {
-				o.handleAgentIdle(a)
-			}
-
-			o.readStatuslineCached(a)
-			o.readTodosCached(a)
-		}
-
-		if o.store.IsDirty() {
-			o.saveStateDebounced()
-			o.store.ClearDirty()
-		}
-	}
-}
-
-// handleHookStatus reads the hook status file for the agent and updates
-// state accordingly. Returns true if hook status was available and handled,
-// false if we should fall back to tmux polling.
-func (o *Orchestrator) handleHookStatus(a *agent.Agent, status agent.Status) bool {
-	sf := o.readHookStatusCached(a.WorktreePath)
-	if sf == nil || sf.IsStale() {
-		return false
-	}
-
-	switch sf.Status {
-	case hook.StatusRunning:
-		a.SetEverActive(true)
-		delete(o.idleHasChanges, a.ID)
-		if status != agent.StatusRunning {
-			a.SetStatus(agent.StatusRunning)
-			a.SetWaitingFor("")
-			o.store.MarkDirty()
-			slog.Debug("agent status change (hook)", "id", a.ID, "status", "running")
-		}
-
-	case hook.StatusWaitingPermission:
-		a.SetEverActive(true)
-		if status != agent.StatusWaiting || a.GetWaitingFor() != "permission" {
-			a.SetStatus(agent.StatusWaiting)
-			a.SetWaitingFor("permission")
-			o.store.MarkDirty()
-			slog.Debug("agent status change (hook)", "id", a.ID, "status", "waiting", "waitingFor", "permission")
-			o.triggerAttention(a.ID, fmt.Sprintf("Agent %s needs permission", a.ID))
-			if o.program != nil {
-				o.program.Send(AgentWaitingMsg{
-					AgentID:    a.ID,
-					WaitingFor: "permission",
-				})
-			}
-		}
-
-	case hook.StatusWaitingInput:
-		if a.GetEverActive() {
-			o.handleAgentIdle(a)
-		}
-
-	case hook.StatusIdle:
-		if a.GetEverActive() {
-			o.handleAgentIdle(a)
-		}
-
-	case hook.StatusStopped:
-		if a.GetEverActive() {
-			o.handleAgentIdle(a)
-		}
-
-	default:
-		return false
-	}
-
-	return true
-}
-
-// readHookStatusCached reads the hook status file, using mtime to skip re-reads.
-func (o *Orchestrator) readHookStatusCached(worktreePath string) *hook.StatusFile {
-	path := filepath.Join(worktreePath, ".mastermind-status")
-	info, err := os.Stat(path)
-	if err != nil {
-		return nil
-	}
-	mtime := info.ModTime()
-	if cached, ok := o.hookMtimeCache[worktreePath]; ok && cached.mtime.Equal(mtime) {
-		if sf, ok := cached.result.(*hook.StatusFile); ok {
-			return sf
-		}
-		return nil
-	}
-	sf, err := hook.ReadStatus(worktreePath)
-	if err != nil {
-		slog.Debug("hook status read error", "path", worktreePath, "error", err)
-		o.hookMtimeCache[worktreePath] = mtimeEntry{mtime: mtime, result: (*hook.StatusFile)(nil)}
-		return nil
-	}
-	o.hookMtimeCache[worktreePath] = mtimeEntry{mtime: mtime, result: sf}
-	return sf
-}
-
-// readStatuslineCached reads the metrics sidecar file, using mtime to skip re-reads.
-// The sidecar filename depends on the agent's harness type.
-func (o *Orchestrator) readStatuslineCached(a *agent.Agent) {
-	// Determine metrics file path based on harness type
-	var metricsFile string
-	switch a.Harness {
-	case harness.TypeOpenCode:
-		metricsFile = ".opencode-status.json"
-	default:
-		metricsFile = ".claude-status.json"
-	}
-	metricsPath := filepath.Join(a.WorktreePath, metricsFile)
-
-	info, err := os.Stat(metricsPath)
-	if err != nil {
-		return
+		shell = "/bin/bash"
 	}
-	mtime := info.ModTime()
-	if cached, ok := o.statuslineMtimeCache[a.WorktreePath]; ok && cached.mtime.Equal(mtime) {
-		if sd, ok := cached.result.(*agent.StatuslineData); ok && sd != nil {
-			a.SetStatuslineData(sd)
-		}
-		return
+	paneID, err := o.tmux.SplitWindow(a.TmuxPaneID, a.WorktreePath, true, o.LazygitSplit(), []string{shell, "-lc", "export GPG_TTY=$(tty); exec lazygit"})
+	if err != nil {
+		return fmt.Errorf("split window for lazygit: %w", err)
 	}
 
-	// Use harness ReadMetrics to parse the correct sidecar file
-	h, ok := o.harnesses[a.Harness]
+	a.SetLazygitPaneID(paneID)
+	// Callers set the status themselves (allows StatusConflicts to open lazygit without change)
+	return nil
+}
+
+// ToggleShell opens a scratch shell split in the agent's worktree, or
+// closes it if one is already open. It reports whether a shell is now open.
+func (o *Orchestrator) ToggleShell(id string) (bool, error) {
+	a, ok := o.store.Get(id)
 	if !ok {
-		return
+		return false, fmt.Errorf("agent %s not found", id)
 	}
-	md, err := h.ReadMetrics(a.WorktreePath)
-	if err != nil || md == nil {
-		o.statuslineMtimeCache[a.WorktreePath] = mtimeEntry{mtime: mtime, result: (*agent.StatuslineData)(nil)}
-		return
-	}
-
-	sd := &agent.StatuslineData{
-		Model:        md.Model,
-		CostUSD:      md.CostUSD,
-		ContextPct:   md.ContextPct,
-		LinesAdded:   md.LinesAdded,
-		LinesRemoved: md.LinesRemoved,
-		SessionID:    md.SessionID,
+	if a.TmuxWindow == "" {
+		return false, fmt.Errorf("agent %s has no tmux window", id)
 	}
 
-	prevSessionID := a.GetSessionID()
-	a.SetStatuslineData(sd)
-	// Update agent metadata file with session ID for orphan recovery
-	if sd.SessionID != "" && sd.SessionID != prevSessionID {
-		writeAgentMetadata(a.WorktreePath, a.BaseBranch, sd.SessionID, a.Harness)
+	if shPane := a.GetShellPaneID(); shPane != "" {
+		a.SetShellPaneID("")
+		o.store.MarkDirty()
+		if o.tmux.PaneExistsInWindow(shPane, a.TmuxWindow) {
+			if err := o.tmux.KillPane(shPane); err != nil {
+				return false, fmt.Errorf("close shell: %w", err)
+			}
+			return false, nil
+		}
+		// The shell exited on its own; open a fresh one.
 	}
-	o.store.MarkDirty()
-	o.statuslineMtimeCache[a.WorktreePath] = mtimeEntry{mtime: mtime, result: sd}
-}
 
-// readTodosCached reads the todos sidecar file, using mtime to skip re-reads.
-func (o *Orchestrator) readTodosCached(a *agent.Agent) {
-	path := filepath.Join(a.WorktreePath, ".mastermind-todos")
-	info, err := os.Stat(path)
-	if err != nil {
-		return
-	}
-	mtime := info.ModTime()
-	if cached, ok := o.todosMtimeCache[a.WorktreePath]; ok && cached.mtime.Equal(mtime) {
-		if todos, ok := cached.result.([]hook.TodoItem); ok && todos != nil {
-			a.SetTodos(todos)
-		}
-		return
+	if err := o.tmux.SelectWindow(a.TmuxWindow); err != nil {
+		return false, fmt.Errorf("select window: %w", err)
 	}
-	todos, err := hook.ReadTodos(a.WorktreePath)
+	paneID, err := o.tmux.SplitWindow(a.TmuxPaneID, a.WorktreePath, false, shellSplit, nil)
 	if err != nil {
-		o.todosMtimeCache[a.WorktreePath] = mtimeEntry{mtime: mtime, result: ([]hook.TodoItem)(nil)}
-		return
+		return false, fmt.Errorf("split window for shell: %w", err)
 	}
-	a.SetTodos(todos)
-	o.todosMtimeCache[a.WorktreePath] = mtimeEntry{mtime: mtime, result: todos}
+	a.SetShellPaneID(paneID)
+	o.store.MarkDirty()
+	return true, nil
 }
 
-func (o *Orchestrator) handleAgentFinished(a *agent.Agent, exitCode int) {
-	a.SetFinished(exitCode, time.Now())
-
-	hasChanges := o.git.HasChanges(a.WorktreePath)
-	// Cache the result for subsequent idle checks
-	hc := hasChanges
-	o.idleHasChanges[a.ID] = &hc
+// StartMonitor polls agent status every 2s until the orchestrator's
+// context is cancelled, persisting state whenever the store is dirty.
+func (o *Orchestrator) StartMonitor() {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	defer o.bus.Close()
 
-	if hasChanges {
-		a.SetStatus(agent.StatusReviewReady)
-	} else {
-		a.SetStatus(agent.StatusDone)
+	// A daemon client leaves pull request polling to the daemon.
+	if o.prPollInterval > 0 && !o.daemonClient {
+		go o.watchPullRequests()
 	}
-	o.store.MarkDirty()
+	// So does a client with the cleanup policy.
+	if (o.cleanup.DoneAfter > 0 || o.cleanup.Merged) && !o.daemonClient {
+		go o.watchCleanup()
+	}
+	o.pruneBackups()
 
-	slog.Info("agent finished", "id", a.ID, "exitCode", exitCode, "hasChanges", hasChanges)
+	for {
+		select {
+		case <-o.ctx.Done():
+			// Force-save on shutdown regardless of debounce
+			if o.store.IsDirty() {
+				o.doSaveState()
+			}
+			slog.Info("monitor stopped: context cancelled")
+			return
+		case <-ticker.C:
+		}
 
-	if hasChanges {
-		o.triggerAttention(a.ID, fmt.Sprintf("Agent %s finished with changes", a.ID))
-	} else {
-		o.triggerAttention(a.ID, fmt.Sprintf("Agent %s finished", a.ID))
-	}
+		o.syncSession()
+		o.checkPreviewStale()
+		o.refreshAheadBehind()
+		if o.daemonClient {
+			o.followDaemon()
+			continue
+		}
+		if o.daemonMode {
+			o.pullState()
+		}
+		o.mon.Poll()
+		o.syncWindowNames()
 
-	if o.program != nil {
-		o.program.Send(AgentFinishedMsg{
-			AgentID:    a.ID,
-			ExitCode:   exitCode,
-			HasChanges: hasChanges,
-		})
+		if o.daemonMode {
+			o.pushState()
+		} else if o.store.IsDirty() {
+			o.saveStateDebounced()
+			o.store.ClearDirty()
+		}
 	}
 }
 
-func (o *Orchestrator) handleAgentIdle(a *agent.Agent) {
-	// Don't overwrite reviewed status — it must stick until merge or manual change
-	if a.GetStatus() == agent.StatusReviewed {
-		return
-	}
-
-	// Use cached HasChanges result for idle agents to avoid redundant git status calls
-	var hasChanges bool
-	if cached := o.idleHasChanges[a.ID]; cached != nil {
-		hasChanges = *cached
-	} else {
-		hasChanges = o.git.HasChanges(a.WorktreePath)
-		hc := hasChanges
-		o.idleHasChanges[a.ID] = &hc
-	}
+// Events returns a channel receiving every monitor event after the
+// orchestrator has handled it, none dropped however slowly it is read.
+// It is closed when the monitor stops.
+func (o *Orchestrator) Events() <-chan monitor.Event {
+	return o.bus.SubscribeAll()
+}
 
-	if hasChanges {
-		if a.GetStatus() != agent.StatusReviewReady {
-			a.SetStatus(agent.StatusReviewReady)
-			a.SetFinished(a.GetExitCode(), time.Now())
-			o.store.MarkDirty()
-			slog.Info("agent idle with changes", "id", a.ID)
-			o.triggerAttention(a.ID, fmt.Sprintf("Agent %s ready for review", a.ID))
-			if o.program != nil {
-				o.program.Send(AgentFinishedMsg{
-					AgentID:    a.ID,
-					HasChanges: true,
-				})
-			}
+// handleMonitorEvent applies the orchestrator-side effects of a monitor
+// event, then publishes it to subscribers. Side effects run first so a
+// frontend never observes an event before e.g. the merge it triggered.
+func (o *Orchestrator) handleMonitorEvent(ev monitor.Event) {
+	switch ev := ev.(type) {
+	case monitor.Attention:
+		o.triggerAttention(ev.AgentID, ev.Message)
+	case monitor.LazygitClosed:
+		if a, ok := o.store.Get(ev.AgentID); ok {
+			o.handleLazygitClosed(a, ev.Status)
 		}
-	} else {
-		if a.GetStatus() != agent.StatusDone {
-			a.SetStatus(agent.StatusDone)
-			a.SetFinished(a.GetExitCode(), time.Now())
-			o.store.MarkDirty()
-			slog.Info("agent idle without changes", "id", a.ID)
-			o.triggerAttention(a.ID, fmt.Sprintf("Agent %s finished", a.ID))
-			if o.program != nil {
-				o.program.Send(AgentFinishedMsg{
-					AgentID:    a.ID,
-					HasChanges: false,
-				})
+	case monitor.SessionIDChanged:
+		// Update agent metadata file with session ID for orphan recovery
+		if a, ok := o.store.Get(ev.AgentID); ok {
+			writeAgentMetadata(a.WorktreePath, a.Branch, a.GetBaseBranch(), ev.SessionID, a.Harness)
+		}
+	case monitor.AgentFinished:
+		if a, ok := o.store.Get(ev.AgentID); ok {
+			if a.ReportFile != "" {
+				o.collectReport(a)
+			} else if ev.HasChanges {
+				o.moveTicket(a.Ticket, o.ticketInReview)
 			}
+			if a.Playbook != "" {
+				go o.finishPlaybook(a)
+			}
+		}
+	case monitor.PlaybookFinished:
+		o.triggerAttention(ev.AgentID, playbookMessage(ev))
+	case monitor.OrphanProcesses:
+		o.triggerAttention(ev.AgentID, fmt.Sprintf("Processes are still running in the worktree of agent %s", ev.AgentID))
+	case monitor.PullRequestMerged:
+		if !ev.Dismissed {
+			o.triggerAttention(ev.AgentID, fmt.Sprintf("Pull request #%d of agent %s was merged", ev.Number, ev.AgentID))
+		}
+	case monitor.AgentGone:
+		// Without a dashboard to clean up after it, the daemon drops the
+		// agent itself.
+		if o.daemonMode {
+			o.store.Remove(ev.AgentID)
 		}
 	}
+	o.logEvent(ev)
+	o.bus.Publish(ev)
 }
 
 // ClearAttentionMsg is sent to the TUI when the " *" window indicator is cleared.
@@ -941,13 +1418,23 @@ type ClearAttentionMsg struct{}
 // window name. It is safe to call from the monitor goroutine.
 func (o *Orchestrator) triggerAttention(agentID, message string) {
 	o.notifier.Notify("Mastermind", message)
+	o.markOverviewWindow()
+}
 
+// markOverviewWindow appends " *" to the overview window name and rings
+// its bell if configured.
+func (o *Orchestrator) markOverviewWindow() {
 	if o.overviewWindowID != "" && !o.attentionActive {
 		o.attentionActive = true
 		if err := o.tmux.RenameWindow(o.overviewWindowID, o.overviewWindowName+" *"); err != nil {
 			slog.Error("rename window failed", "error", err)
 		}
 	}
+	if o.tmuxBell && o.overviewWindowID != "" {
+		if err := o.tmux.RingBell(o.overviewWindowID); err != nil {
+			slog.Error("ring bell failed", "error", err)
+		}
+	}
 }
 
 // ClearAttentionIndicator returns a tea.Cmd that removes the " *" suffix from
@@ -990,60 +1477,197 @@ func (o *Orchestrator) handleLazygitClosed(a *agent.Agent, status agent.Status)
 	} else if status == agent.StatusConflicts {
 		if !o.git.HasChanges(a.WorktreePath) {
 			// Conflicts were resolved and committed on agent's branch.
-			// Fast-forward base to the agent's HEAD before cleanup.
-			if err := o.ffMergeBase(a); err != nil {
-				slog.Error("ff merge base after conflict resolution failed", "id", a.ID, "error", err)
-			}
-			if err := o.cleanupAfterMerge(a); err != nil {
-				slog.Error("cleanup after merge failed", "id", a.ID, "error", err)
-			}
+			msg := o.finishConflictedMerge(a)
 			if o.program != nil {
-				o.program.Send(MergeResultMsg{AgentID: a.ID, Success: true})
+				o.program.Send(msg)
 			}
 		}
 		// If still dirty, stay in StatusConflicts
 	}
 }
 
-func (o *Orchestrator) MergeAgent(id string, deleteBranch, removeWorktree bool) MergeResultMsg {
+// MergeAgent lands agent id's branch on its base with strategy, the
+// configured one if empty, and cleans up as asked. With push, base is
+// then pushed to its upstream. message is the commit message of a squash
+// merge; empty generates one with SquashMessage.
+func (o *Orchestrator) MergeAgent(id string, deleteBranch, removeWorktree, push bool, strategy MergeStrategy, message string) MergeResultMsg {
+	branch := o.agentBranch(id)
+	if strategy == "" {
+		strategy = o.mergeDefaults.Strategy
+	}
+	msg := o.mergeAgent(id, deleteBranch, removeWorktree, push, strategy, message)
+	o.record(ipc.OpMerge, branch, ipc.MergeParams{ID: id, DeleteBranch: deleteBranch, RemoveWorktree: removeWorktree, Push: push, Strategy: string(strategy), Message: message}, mergeError(msg))
+	return msg
+}
+
+// mergeAgent merges an agent without recording it as the user's action.
+func (o *Orchestrator) mergeAgent(id string, deleteBranch, removeWorktree, push bool, strategy MergeStrategy, message string) MergeResultMsg {
+	var res ipc.MergeResult
+	handled, err := o.remoteCall(func(c *ipc.Client) (err error) {
+		res, err = c.Merge(ipc.MergeParams{ID: id, DeleteBranch: deleteBranch, RemoveWorktree: removeWorktree, Push: push, Strategy: string(strategy), Message: message})
+		return err
+	})
+	if handled {
+		if err != nil {
+			return MergeResultMsg{AgentID: id, Error: err.Error()}
+		}
+		return MergeResultMsg{
+			AgentID:       id,
+			Success:       res.Success,
+			Conflict:      res.Conflict,
+			ConflictFiles: res.ConflictFiles,
+			ConflictNotes: res.ConflictNotes,
+			Error:         res.Error,
+			PushError:     res.PushError,
+		}
+	}
+
 	a, ok := o.store.Get(id)
 	if !ok {
 		return MergeResultMsg{AgentID: id, Error: "agent not found"}
 	}
+	if a.ReadOnly {
+		return MergeResultMsg{AgentID: id, Error: fmt.Sprintf("agent %s is read-only and cannot be merged", id)}
+	}
 
 	// Store cleanup preferences on the agent so conflict resolution path can read them
 	a.SetMergeDeleteBranch(deleteBranch)
 	a.SetMergeRemoveWorktree(removeWorktree)
+	a.SetMergePush(push)
+	a.SetSyncStatus("")
 
 	if o.git.HasChanges(a.WorktreePath) {
 		return MergeResultMsg{AgentID: id, Error: "uncommitted changes in worktree — commit or discard them first"}
 	}
 
-	// Merge base into the agent's branch. If base is already an ancestor
-	// this is a no-op ("Already up to date"). Otherwise it creates a merge
-	// commit on the agent's branch, making it a superset of base. Either
-	// way the agent branch ends up FF-able onto base.
-	conflicted, err := o.git.MergeInWorktree(a.WorktreePath, a.BaseBranch)
-	if err != nil {
-		return MergeResultMsg{AgentID: id, Error: fmt.Sprintf("merge: %v", err)}
+	if strategy == MergeSquash && message == "" {
+		if message, err = o.SquashMessage(id); err != nil {
+			return MergeResultMsg{AgentID: id, Error: err.Error()}
+		}
+	}
+	if strategy != MergeSquash {
+		message = ""
 	}
+	a.SetMergeSquashMessage(message)
 
+	conflicted, err := o.mergeIntoBase(a, strategy)
+	if err != nil {
+		return MergeResultMsg{AgentID: id, Error: err.Error()}
+	}
 	if conflicted {
 		a.SetStatus(agent.StatusConflicts)
-		conflictFiles, _ := o.git.ConflictFiles(a.WorktreePath)
-		return MergeResultMsg{AgentID: id, Conflict: true, ConflictFiles: conflictFiles}
+		return o.conflictResult(a)
 	}
 
-	// Fast-forward base to the agent's HEAD.
-	if err := o.ffMergeBase(a); err != nil {
-		return MergeResultMsg{AgentID: id, Error: err.Error()}
+	slog.Info("merge completed", "id", a.ID, "branch", a.Branch, "base", a.GetBaseBranch(), "strategy", strategy)
+	pushErr := o.pushAfterMerge(a)
+	if err := o.cleanupAfterMerge(a); err != nil {
+		return MergeResultMsg{AgentID: id, Error: fmt.Sprintf("cleanup: %v", err), PushError: pushErr}
+	}
+	return MergeResultMsg{AgentID: id, Success: true, PushError: pushErr}
+}
+
+// pushAfterMerge pushes base to its upstream if the merge that just landed
+// asked to, and returns why that failed, or "".
+func (o *Orchestrator) pushAfterMerge(a *agent.Agent) string {
+	if !a.GetMergePush() {
+		return ""
+	}
+	base := a.GetBaseBranch()
+	if err := o.git.PushUpstream(o.repoPath, base); err != nil {
+		slog.Warn("failed to push base after merge", "id", a.ID, "base", base, "error", err)
+		return err.Error()
+	}
+	slog.Info("pushed base after merge", "id", a.ID, "base", base)
+	return ""
+}
+
+// mergeIntoBase merges base into the agent's branch, or rebases the
+// branch onto base with MergeRebase, and fast-forwards base to the result,
+// or with MergeSquash to a single commit of it. If base moves in between,
+// e.g. because another agent merged, this is done again, up to
+// mergeRetries times. Only a merge leaves conflicts to resolve; a rebase
+// that conflicts fails with ErrRebaseConflicts.
+func (o *Orchestrator) mergeIntoBase(a *agent.Agent, strategy MergeStrategy) (conflicted bool, err error) {
+	for attempt := 0; ; attempt++ {
+		if strategy == MergeRebase {
+			// Replays the agent's commits on top of base, so base can be
+			// fast-forwarded without a merge commit.
+			conflicted, err := o.git.Rebase(a.WorktreePath, a.GetBaseBranch())
+			if err != nil {
+				return false, fmt.Errorf("rebase: %v", err)
+			}
+			if conflicted {
+				return false, ErrRebaseConflicts
+			}
+		} else {
+			// Merge base into the agent's branch. If base is already an ancestor
+			// this is a no-op ("Already up to date"). Otherwise it creates a merge
+			// commit on the agent's branch, making it a superset of base. Either
+			// way the agent branch ends up FF-able onto base.
+			conflicted, err := o.git.MergeInWorktree(a.WorktreePath, a.GetBaseBranch(), o.rerere)
+			if err != nil {
+				return false, fmt.Errorf("merge: %v", err)
+			}
+			if conflicted {
+				return true, nil
+			}
+		}
+
+		if strategy == MergeSquash {
+			err = o.squashIntoBase(a)
+		} else {
+			// Fast-forward base to the agent's HEAD.
+			err = o.ffMergeBase(a)
+		}
+		if err == nil || !errors.Is(err, ErrBaseMoved) || attempt >= o.mergeRetries {
+			return false, err
+		}
+		slog.Info("base moved during merge, merging it again", "id", a.ID, "base", a.GetBaseBranch(), "strategy", strategy, "attempt", attempt+1)
 	}
+}
 
-	slog.Info("merge completed", "id", a.ID, "branch", a.Branch, "base", a.BaseBranch)
+// finishConflictedMerge completes the merge of an agent whose conflicts
+// have been resolved and committed on its branch: base is fast-forwarded
+// to the agent's HEAD, merging base in again if it has moved meanwhile,
+// and the agent is cleaned up. The conflicts of a sync only end it.
+func (o *Orchestrator) finishConflictedMerge(a *agent.Agent) MergeResultMsg {
+	if a.GetSyncStatus() != "" {
+		return o.finishConflictedSync(a)
+	}
+	strategy := MergeCommit
+	if a.GetMergeSquashMessage() != "" {
+		strategy = MergeSquash
+	}
+	conflicted, err := o.mergeIntoBase(a, strategy)
+	if conflicted {
+		return o.conflictResult(a)
+	}
+	if err != nil {
+		slog.Error("ff merge base after conflict resolution failed", "id", a.ID, "error", err)
+		a.SetStatus(agent.StatusReviewed)
+		return MergeResultMsg{AgentID: a.ID, Error: err.Error()}
+	}
+	pushErr := o.pushAfterMerge(a)
 	if err := o.cleanupAfterMerge(a); err != nil {
-		return MergeResultMsg{AgentID: id, Error: fmt.Sprintf("cleanup: %v", err)}
+		slog.Error("cleanup after merge failed", "id", a.ID, "error", err)
 	}
-	return MergeResultMsg{AgentID: id, Success: true}
+	return MergeResultMsg{AgentID: a.ID, Success: true, PushError: pushErr}
+}
+
+// conflictResult reports the conflicts of a merge left in progress in the
+// agent's worktree.
+func (o *Orchestrator) conflictResult(a *agent.Agent) MergeResultMsg {
+	files, _ := o.git.ConflictFiles(a.WorktreePath)
+	notes := o.git.MergeDriverProblems(a.WorktreePath, files)
+	for _, n := range notes {
+		slog.Warn("merge driver problem", "id", a.ID, "problem", n)
+	}
+	if len(files) == 0 {
+		// rerere replayed a recorded resolution for every conflict.
+		notes = append(notes, "every conflict was resolved from a recorded resolution (rerere) and staged; review it and commit")
+	}
+	return MergeResultMsg{AgentID: a.ID, Conflict: true, ConflictFiles: files, ConflictNotes: notes}
 }
 
 // ffMergeBase fast-forwards the base branch to the agent's current HEAD.
@@ -1054,14 +1678,67 @@ func (o *Orchestrator) ffMergeBase(a *agent.Agent) error {
 	if err != nil {
 		return fmt.Errorf("get agent HEAD: %v", err)
 	}
-	if wtPath := o.git.WorktreeForBranch(o.repoPath, a.BaseBranch); wtPath != "" {
-		if err := o.git.MergeFFOnly(wtPath, a.Branch); err != nil {
+	return o.ffBaseTo(a, a.Branch, agentHead)
+}
+
+// squashIntoBase commits the tree of the agent's HEAD, which has base
+// merged in, as a single commit on top of base and fast-forwards base to
+// it. The agent's branch keeps its own commits.
+func (o *Orchestrator) squashIntoBase(a *agent.Agent) error {
+	base := a.GetBaseBranch()
+	baseHead, err := o.git.HeadCommit(o.repoPath, base)
+	if err != nil {
+		return fmt.Errorf("get base HEAD: %v", err)
+	}
+	agentHead, err := o.git.HeadCommit(a.WorktreePath, "HEAD")
+	if err != nil {
+		return fmt.Errorf("get agent HEAD: %v", err)
+	}
+	// The squash commit takes the agent's tree as is, so it would revert
+	// whatever base gained since it was merged in.
+	if !o.git.IsAncestor(o.repoPath, baseHead, agentHead) {
+		slog.Warn("squash aborted: base moved", "id", a.ID, "base", base, "branch", a.Branch, "head", agentHead)
+		return fmt.Errorf("squash: %w: %s is not an ancestor of %s", ErrBaseMoved, base, a.Branch)
+	}
+	commit, err := o.git.SquashCommit(a.WorktreePath, baseHead, a.GetMergeSquashMessage())
+	if err != nil {
+		return fmt.Errorf("squash: %v", err)
+	}
+	return o.ffBaseTo(a, commit, commit)
+}
+
+// ffBaseTo fast-forwards the agent's base branch to commit, which rev
+// names for a merge in a worktree that has base checked out.
+func (o *Orchestrator) ffBaseTo(a *agent.Agent, rev, commit string) error {
+	// Make sure base hasn't moved on since it was merged into the agent's
+	// branch. Base is resolved once, and update-ref only moves it from
+	// that commit, so it can't move in between; checking first tells a
+	// moved base from other fast-forward failures.
+	base := a.GetBaseBranch()
+	baseHead, err := o.git.HeadCommit(o.repoPath, base)
+	if err != nil {
+		return fmt.Errorf("get base HEAD: %v", err)
+	}
+	moved := func() error {
+		slog.Warn("fast-forward aborted: base moved", "id", a.ID, "base", base, "branch", a.Branch, "head", commit)
+		return fmt.Errorf("fast-forward: %w: %s is not an ancestor of %s", ErrBaseMoved, base, a.Branch)
+	}
+	if !o.git.IsAncestor(o.repoPath, baseHead, commit) {
+		return moved()
+	}
+	if wtPath := o.git.WorktreeForBranch(o.repoPath, base); wtPath != "" {
+		// merge --ff-only refuses to move base anywhere but forward.
+		if err := o.git.MergeFFOnly(wtPath, rev); err != nil {
+			if head, herr := o.git.HeadCommit(o.repoPath, base); herr == nil && head != baseHead {
+				return moved()
+			}
 			return fmt.Errorf("fast-forward merge: %v", err)
 		}
-	} else {
-		if err := o.git.UpdateBranchRef(o.repoPath, a.BaseBranch, agentHead); err != nil {
-			return fmt.Errorf("fast-forward update: %v", err)
+	} else if err := o.git.UpdateBranchRef(o.repoPath, base, commit, baseHead); err != nil {
+		if errors.Is(err, git.ErrRefMoved) {
+			return moved()
 		}
+		return fmt.Errorf("fast-forward update: %v", err)
 	}
 	return nil
 }
@@ -1073,6 +1750,7 @@ func (o *Orchestrator) cleanupAfterMerge(a *agent.Agent) error {
 	if a.TmuxPaneID != "" {
 		o.monitor.Remove(a.TmuxPaneID)
 	}
+	o.restoreClaudeSettings(a)
 	if removeWorktree {
 		if a.TmuxWindow != "" {
 			if err := o.tmux.KillWindow(a.TmuxWindow); err != nil {
@@ -1080,13 +1758,30 @@ func (o *Orchestrator) cleanupAfterMerge(a *agent.Agent) error {
 			}
 		}
 		if a.WorktreePath != "" {
-			if err := o.git.RemoveWorktree(o.repoPath, a.WorktreePath); err != nil {
+			if o.holdForOrphans(a, deleteBranch) {
+				deleteBranch = false
+			} else if err := o.removeWorktree(a.Branch, a.WorktreePath); err != nil {
 				slog.Warn("cleanup: failed to remove worktree", "id", a.ID, "path", a.WorktreePath, "error", err)
+				deleteBranch = false
 			}
 		}
 	}
+	// Move stacked agents onto our base before the branch goes away.
+	o.restackChildren(a)
+	if deleteBranch && a.Branch != "" && !o.archiveBranches && a.GetMergeSquashMessage() == "" {
+		// The merge landed the branch on base, so only commits made since,
+		// e.g. by the agent while the merge ran, are unmerged. Keep the
+		// branch rather than lose them. A squash merge leaves all of its
+		// commits unmerged, their changes landed as one.
+		if n, err := o.git.UnmergedCommits(o.repoPath, a.Branch); err != nil {
+			slog.Warn("cleanup: failed to count unmerged commits", "id", a.ID, "branch", a.Branch, "error", err)
+		} else if n > 0 {
+			slog.Warn("cleanup: keeping branch with unmerged commits", "id", a.ID, "branch", a.Branch, "commits", n)
+			deleteBranch = false
+		}
+	}
 	if deleteBranch && a.Branch != "" {
-		if err := o.git.DeleteBranch(o.repoPath, a.Branch); err != nil {
+		if err := o.removeBranch(a.Branch); err != nil {
 			slog.Warn("cleanup: failed to delete branch", "id", a.ID, "branch", a.Branch, "error", err)
 		}
 	}
@@ -1096,6 +1791,65 @@ func (o *Orchestrator) cleanupAfterMerge(a *agent.Agent) error {
 	return nil
 }
 
+// restackChildren re-parents agents stacked on a's branch onto a's base
+// and rebases their worktrees. Busy or dirty worktrees are re-parented
+// only; the user is told to rebase them. It must run before a's branch
+// is deleted.
+func (o *Orchestrator) restackChildren(a *agent.Agent) {
+	newBase := a.GetBaseBranch()
+	if newBase == "" {
+		return
+	}
+	children := o.store.StackedOn(a.Branch)
+	if len(children) == 0 {
+		return
+	}
+	// A squash merge leaves a's commits out of base, so a plain rebase
+	// would replay them onto the squash commit. Only the children's own
+	// commits, those after a's head, are moved instead.
+	var parentHead string
+	if a.GetMergeSquashMessage() != "" {
+		head, err := o.git.HeadCommit(o.repoPath, a.Branch)
+		if err != nil {
+			slog.Warn("restack: failed to get squashed parent's head", "id", a.ID, "branch", a.Branch, "error", err)
+		}
+		parentHead = head
+	}
+	for _, child := range children {
+		child.SetBaseBranch(newBase)
+		writeAgentMetadata(child.WorktreePath, child.Branch, newBase, child.GetSessionID(), child.Harness)
+		o.store.MarkDirty()
+
+		msg := StackRestackedMsg{AgentID: child.ID, Parent: a.Branch, NewBase: newBase}
+		switch status := child.GetStatus(); {
+		case status == agent.StatusRunning || status == agent.StatusWaiting:
+			msg.Reason = "agent is busy"
+		case o.git.HasChanges(child.WorktreePath):
+			msg.Reason = "uncommitted changes"
+		default:
+			var conflicted bool
+			var err error
+			if parentHead != "" {
+				conflicted, err = o.git.RebaseOnto(child.WorktreePath, newBase, parentHead)
+			} else {
+				conflicted, err = o.git.Rebase(child.WorktreePath, newBase)
+			}
+			switch {
+			case err != nil:
+				msg.Reason = err.Error()
+			case conflicted:
+				msg.Reason = "rebase conflicts"
+			default:
+				msg.Rebased = true
+			}
+		}
+		slog.Info("restacked agent", "id", child.ID, "parent", a.Branch, "base", newBase, "rebased", msg.Rebased, "reason", msg.Reason)
+		if o.program != nil {
+			o.program.Send(msg)
+		}
+	}
+}
+
 func (o *Orchestrator) CleanupDeadAgents() []CleanupResult {
 	var results []CleanupResult
 	for _, a := range o.store.All() {
@@ -1106,12 +1860,12 @@ func (o *Orchestrator) CleanupDeadAgents() []CleanupResult {
 			reason = "pane gone"
 		} else if _, err := os.Stat(a.WorktreePath); os.IsNotExist(err) {
 			reason = "worktree missing"
-		} else if a.BaseBranch != "" && o.git.IsBranchMerged(o.repoPath, a.Branch, a.BaseBranch) {
+		} else if a.GetBaseBranch() != "" && o.git.IsBranchMerged(o.repoPath, a.Branch, a.GetBaseBranch()) {
 			reason = "branch merged"
 		}
 
 		if reason != "" {
-			o.DismissAgent(a.ID, false)
+			o.dismissAgent(a.ID, false)
 			results = append(results, CleanupResult{AgentName: name, Reason: reason})
 		}
 	}
@@ -1125,6 +1879,8 @@ type previewState struct {
 	AgentID    string       `json:"agent_id"`
 	PrevBranch string       `json:"prev_branch"`
 	PrevStatus agent.Status `json:"prev_status"`
+	// Snapshot is the changes the preview made in the main worktree.
+	Snapshot map[string]string `json:"snapshot"`
 }
 
 func (o *Orchestrator) previewStatePath() string {
@@ -1137,6 +1893,7 @@ func (o *Orchestrator) savePreviewState() {
 		AgentID:    o.previewAgentID,
 		PrevBranch: o.previewPrevBranch,
 		PrevStatus: o.previewPrevStatus,
+		Snapshot:   o.previewSnapshot,
 	}
 	o.previewMu.RUnlock()
 	data, err := json.MarshalIndent(ps, "", "  ")
@@ -1209,31 +1966,31 @@ func (o *Orchestrator) PreviewAgent(id string) error {
 		return fmt.Errorf("get current branch: %w", err)
 	}
 
-	previewBranch := "preview/" + id
-	if err := o.git.CreateBranch(o.repoPath, previewBranch, a.BaseBranch); err != nil {
+	previewBranch := previewBranch(id)
+	if err := o.createPreviewBranch(id, a.GetBaseBranch()); err != nil {
 		resetSentinel()
 		return fmt.Errorf("create preview branch: %w", err)
 	}
 
 	if err := o.git.CheckoutBranch(o.repoPath, previewBranch); err != nil {
-		o.git.DeleteBranch(o.repoPath, previewBranch)
+		o.deletePreviewBranch(previewBranch)
 		resetSentinel()
 		return fmt.Errorf("checkout preview branch: %w", err)
 	}
 
-	conflicted, err := o.git.MergeInWorktree(o.repoPath, a.Branch)
+	conflicted, err := o.git.MergeInWorktree(o.repoPath, a.Branch, false)
 	if err != nil {
 		o.git.CheckoutBranch(o.repoPath, prevBranch)
-		o.git.DeleteBranch(o.repoPath, previewBranch)
+		o.deletePreviewBranch(previewBranch)
 		resetSentinel()
 		return fmt.Errorf("merge agent branch: %w", err)
 	}
 	if conflicted {
 		o.git.MergeAbort(o.repoPath)
 		o.git.CheckoutBranch(o.repoPath, prevBranch)
-		o.git.DeleteBranch(o.repoPath, previewBranch)
+		o.deletePreviewBranch(previewBranch)
 		resetSentinel()
-		return fmt.Errorf("merge conflicts between %s and %s — cannot preview", a.BaseBranch, a.Branch)
+		return fmt.Errorf("merge conflicts between %s and %s — cannot preview", a.GetBaseBranch(), a.Branch)
 	}
 
 	// Copy any uncommitted changes from the agent's worktree so the preview
@@ -1244,10 +2001,19 @@ func (o *Orchestrator) PreviewAgent(id string) error {
 		}
 	}
 
+	head, err := o.git.HeadCommit(o.repoPath, a.Branch)
+	if err != nil {
+		slog.Warn("failed to resolve previewed branch", "branch", a.Branch, "error", err)
+	}
+	snapshot := o.snapshotPreview()
+
 	o.previewMu.Lock()
 	o.previewAgentID = id
 	o.previewPrevBranch = prevBranch
 	o.previewPrevStatus = status
+	o.previewHead = head
+	o.previewStale = false
+	o.previewSnapshot = snapshot
 	o.previewMu.Unlock()
 	a.SetStatus(agent.StatusPreviewing)
 	o.savePreviewState()
@@ -1271,19 +2037,20 @@ func (o *Orchestrator) StopPreview() error {
 	prevStatus := o.previewPrevStatus
 	o.previewMu.Unlock()
 
-	previewBranch := "preview/" + agentID
+	previewBranch := previewBranch(agentID)
 
 	// Discard any uncommitted changes that were applied during preview,
 	// otherwise checkout back to the previous branch may fail.
-	if o.git.HasChanges(o.repoPath) {
-		exec.Command("git", "-C", o.repoPath, "checkout", ".").Run()
+	stash, stashed, err := o.clearPreviewChanges(agentID)
+	if err != nil {
+		return err
 	}
 
 	if err := o.git.CheckoutBranch(o.repoPath, prevBranch); err != nil {
 		return fmt.Errorf("checkout previous branch: %w", err)
 	}
 
-	if err := o.git.DeleteBranch(o.repoPath, previewBranch); err != nil {
+	if err := o.deletePreviewBranch(previewBranch); err != nil {
 		slog.Warn("failed to delete preview branch", "branch", previewBranch, "error", err)
 	}
 
@@ -1296,12 +2063,15 @@ func (o *Orchestrator) StopPreview() error {
 	o.previewAgentID = ""
 	o.previewPrevBranch = ""
 	o.previewPrevStatus = ""
+	o.previewHead = ""
+	o.previewStale = false
+	o.previewSnapshot = nil
 	o.previewMu.Unlock()
 	o.deletePreviewState()
 
 	slog.Info("preview stopped", "agent", agentID)
 	if o.program != nil {
-		o.program.Send(PreviewStoppedMsg{AgentID: agentID})
+		o.program.Send(PreviewStoppedMsg{AgentID: agentID, Stash: stash, Stashed: stashed})
 	}
 	return nil
 }
@@ -1322,7 +2092,11 @@ func (o *Orchestrator) ResetPreviewCleanup() {
 	o.previewCleanupOnce = sync.Once{}
 }
 
-func (o *Orchestrator) doCleanupPreview() {
+// doCleanupPreview restores the main worktree from the active preview,
+// or the one recorded on disk by an earlier session, and reports it; the
+// AgentID is empty when there was none. When the previous branch cannot
+// be checked out again the preview is kept, so cleaning up can be retried.
+func (o *Orchestrator) doCleanupPreview() (PreviewStoppedMsg, error) {
 	o.previewMu.Lock()
 	// Try to restore from persisted state if not already loaded
 	if o.previewAgentID == "" {
@@ -1330,12 +2104,13 @@ func (o *Orchestrator) doCleanupPreview() {
 			o.previewAgentID = ps.AgentID
 			o.previewPrevBranch = ps.PrevBranch
 			o.previewPrevStatus = ps.PrevStatus
+			o.previewSnapshot = ps.Snapshot
 		}
 	}
 
 	if o.previewAgentID == "" {
 		o.previewMu.Unlock()
-		return
+		return PreviewStoppedMsg{}, nil
 	}
 
 	agentID := o.previewAgentID
@@ -1343,21 +2118,26 @@ func (o *Orchestrator) doCleanupPreview() {
 	prevStatus := o.previewPrevStatus
 	o.previewMu.Unlock()
 
-	previewBranch := "preview/" + agentID
+	previewBranch := previewBranch(agentID)
+	stopped := PreviewStoppedMsg{AgentID: agentID}
 
 	// Discard uncommitted preview changes before switching back.
-	if o.git.HasChanges(o.repoPath) {
-		exec.Command("git", "-C", o.repoPath, "checkout", ".").Run()
+	var err error
+	if stopped.Stash, stopped.Stashed, err = o.clearPreviewChanges(agentID); err != nil {
+		slog.Error("cleanup: failed to clear preview changes", "error", err)
 	}
 
 	if err := o.git.CheckoutBranch(o.repoPath, prevBranch); err != nil {
 		slog.Error("cleanup: failed to checkout previous branch", "branch", prevBranch, "error", err)
+		return stopped, fmt.Errorf("checkout previous branch %s: %w", prevBranch, err)
 	}
 
 	if o.git.BranchExists(o.repoPath, previewBranch) {
-		if err := o.git.DeleteBranch(o.repoPath, previewBranch); err != nil {
+		if err := o.deletePreviewBranch(previewBranch); err != nil {
 			slog.Error("cleanup: failed to delete preview branch", "branch", previewBranch, "error", err)
 		}
+	} else {
+		o.forgetPreviewBranch(previewBranch)
 	}
 
 	if a, ok := o.store.Get(agentID); ok {
@@ -1368,10 +2148,34 @@ func (o *Orchestrator) doCleanupPreview() {
 	o.previewAgentID = ""
 	o.previewPrevBranch = ""
 	o.previewPrevStatus = ""
+	o.previewHead = ""
+	o.previewStale = false
+	o.previewSnapshot = nil
 	o.previewMu.Unlock()
 	o.deletePreviewState()
 	o.saveState()
 	slog.Info("preview cleaned up")
+	return stopped, nil
+}
+
+// RecoverPreview restores the main worktree from a preview left behind
+// by a session that could not clean it up, e.g. one that was killed or
+// hung, as recorded in .worktrees/mastermind-preview.json. The agents
+// should be loaded first so the previewed agent gets its status back.
+func (o *Orchestrator) RecoverPreview() (PreviewStoppedMsg, error) {
+	return o.doCleanupPreview()
+}
+
+// LoadAgents fills the store from the persisted state as it is, without
+// checking tmux or the worktrees and without writing anything back. It is
+// for looking at the agents, e.g. rendering a screenshot of the dashboard.
+func (o *Orchestrator) LoadAgents() error {
+	persisted, err := agent.LoadState(o.statePath)
+	if err != nil {
+		return err
+	}
+	o.mergeState(persisted)
+	return nil
 }
 
 // RecoverAgents restores agents from persisted state, validating that
@@ -1395,39 +2199,20 @@ func (o *Orchestrator) RecoverAgents() {
 			slog.Debug("skipping stale agent, worktree gone", "id", pa.ID, "path", pa.WorktreePath)
 			continue
 		}
-
-		a := &agent.Agent{
-			ID:           pa.ID,
-			Branch:       pa.Branch,
-			BaseBranch:   pa.BaseBranch,
-			WorktreePath: pa.WorktreePath,
-			TmuxWindow:   pa.TmuxWindow,
-			TmuxPaneID:   pa.TmuxPaneID,
-			StartedAt:    pa.StartedAt,
-		}
-		a.SetStatus(pa.Status)
-		a.SetWaitingFor(pa.WaitingFor)
-		a.SetEverActive(pa.EverActive)
-		if !pa.FinishedAt.IsZero() {
-			a.SetFinished(pa.ExitCode, pa.FinishedAt)
-		}
-		if pa.LazygitPaneID != "" {
-			a.SetLazygitPaneID(pa.LazygitPaneID)
+		if o.git.IsWorktreeBroken(pa.WorktreePath) {
+			slog.Warn("recovered agent has a broken worktree", "id", pa.ID, "path", pa.WorktreePath)
 		}
-		if pa.PreReviewCommit != "" {
-			a.SetPreReviewCommit(pa.PreReviewCommit)
-		}
-		if pa.SessionID != "" {
-			a.SetSessionID(pa.SessionID)
+
+		a := agentFromPersisted(pa)
+		o.store.Add(a)
+		o.resetWindowName(a)
+		if !o.daemonClient {
+			o.resumeDeadPane(a)
 		}
-		a.SetDurationState(pa.AccumulatedDuration, pa.RunningStartedAt)
 
 		// Read sidecar files immediately so recovered agents have
 		// statusline data and todos available before the first monitor tick.
-		o.readStatuslineCached(a)
-		o.readTodosCached(a)
-
-		o.store.Add(a)
+		o.mon.RefreshSidecars(a)
 		recovered++
 		slog.Info("recovered agent", "id", a.ID, "branch", a.Branch, "status", pa.Status)
 	}
@@ -1436,12 +2221,21 @@ func (o *Orchestrator) RecoverAgents() {
 		slog.Info("agent recovery complete", "recovered", recovered, "total", len(persisted))
 	}
 
+	// A daemon client leaves moving worktrees to the daemon.
+	if !o.daemonClient {
+		o.migrateWorktreeLayout()
+	}
+
 	// Discover orphaned worktrees that have tmux windows but aren't in state
 	if discovered := o.discoverOrphanedAgents(); discovered > 0 {
 		slog.Info("orphan discovery complete", "discovered", discovered)
 		o.saveState()
 	}
 
+	if broken := o.BrokenWorktrees(); len(broken) > 0 {
+		slog.Warn("broken worktrees detected, run maintenance to repair", "paths", broken)
+	}
+
 	// Recover preview state
 	if ps := o.loadPreviewState(); ps != nil && ps.AgentID != "" {
 		o.previewMu.Lock()
@@ -1453,8 +2247,148 @@ func (o *Orchestrator) RecoverAgents() {
 	}
 }
 
+// worktreePathFor picks the directory for branch's new worktree: its
+// flattened name (see git.WorktreeDirName), suffixed with -2, -3, ... while
+// that is taken, e.g. by a branch literally named "feat__x" or by a nested
+// directory left from an older layout.
+func (o *Orchestrator) worktreePathFor(branch string) string {
+	name := git.WorktreeDirName(branch)
+	path := filepath.Join(o.worktreeDir, name)
+	for n := 2; o.worktreePathTaken(path); n++ {
+		path = filepath.Join(o.worktreeDir, fmt.Sprintf("%s-%d", name, n))
+	}
+	return path
+}
+
+func (o *Orchestrator) worktreePathTaken(path string) bool {
+	if _, err := os.Lstat(path); err == nil {
+		return true
+	}
+	for _, a := range o.store.All() {
+		if filepath.Clean(a.WorktreePath) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateWorktreeLayout moves worktrees that older versions nested by
+// branch name (.worktrees/feat/x) to flattened directories. Worktrees of
+// recovered agents keep their path, which their state records and their
+// running harness depends on; they are removed on dismissal as before.
+func (o *Orchestrator) migrateWorktreeLayout() {
+	worktrees, err := o.git.ListWorktrees(o.repoPath)
+	if err != nil {
+		slog.Debug("failed to list worktrees for layout migration", "error", err)
+		return
+	}
+	inUse := make(map[string]bool)
+	for _, a := range o.store.All() {
+		inUse[filepath.Clean(a.WorktreePath)] = true
+	}
+	for i, wt := range worktrees {
+		// git lists the main working tree first.
+		if i == 0 || wt.Branch == "" || inUse[filepath.Clean(wt.Path)] || !isWithin(wt.Path, o.worktreeDir) {
+			continue
+		}
+		if filepath.Dir(wt.Path) == filepath.Clean(o.worktreeDir) {
+			continue // already flat
+		}
+		to := o.worktreePathFor(wt.Branch)
+		if err := o.git.MoveWorktree(o.repoPath, wt.Path, to); err != nil {
+			slog.Warn("failed to migrate nested worktree", "branch", wt.Branch, "path", wt.Path, "error", err)
+			continue
+		}
+		// The directory name no longer spells the branch; record it.
+		if meta := readAgentMetadata(to); meta != nil && meta.Branch == "" {
+			writeAgentMetadata(to, wt.Branch, meta.BaseBranch, meta.SessionID, meta.HarnessType)
+		}
+		slog.Info("migrated nested worktree", "branch", wt.Branch, "from", wt.Path, "to", to)
+	}
+}
+
+// LeftoverWorktrees maps branches to their worktrees under the worktree
+// dir that no agent works in, e.g. ones left behind by a crashed session.
+func (o *Orchestrator) LeftoverWorktrees() map[string]string {
+	worktrees, err := o.git.ListWorktrees(o.repoPath)
+	if err != nil {
+		slog.Debug("failed to list worktrees", "error", err)
+		return nil
+	}
+	owned := make(map[string]bool)
+	for _, a := range o.store.All() {
+		owned[filepath.Clean(a.WorktreePath)] = true
+	}
+	leftover := make(map[string]string)
+	for _, wt := range worktrees {
+		if wt.Branch != "" && isWithin(wt.Path, o.worktreeDir) && !owned[filepath.Clean(wt.Path)] {
+			leftover[wt.Branch] = wt.Path
+		}
+	}
+	return leftover
+}
+
+// ReusableWorktree returns branch's leftover worktree (see
+// LeftoverWorktrees), or "" when there is none. It returns an error when
+// the worktree cannot be reused safely: its link to the repository is
+// broken, it is not on the branch, or it has unresolved merge conflicts.
+func (o *Orchestrator) ReusableWorktree(branch string) (string, error) {
+	path := o.LeftoverWorktrees()[branch]
+	if path == "" {
+		return "", nil
+	}
+	if o.git.IsWorktreeBroken(path) {
+		return "", fmt.Errorf("worktree %s is broken; repair it from the maintenance menu first", path)
+	}
+	head, err := o.git.CurrentBranch(path)
+	if err != nil {
+		return "", fmt.Errorf("worktree %s: %w", path, err)
+	}
+	if head != branch {
+		return "", fmt.Errorf("worktree %s is not on branch %q (HEAD: %s)", path, branch, head)
+	}
+	if conflicts, err := o.git.ConflictFiles(path); err == nil && len(conflicts) > 0 {
+		return "", fmt.Errorf("worktree %s has %d unresolved conflicts", path, len(conflicts))
+	}
+	return path, nil
+}
+
+// checkWorktreeNesting refuses a new worktree at wtPath that would sit
+// inside another worktree, or contain one, where each would show up as
+// untracked files of the other. The main working tree, which holds
+// .worktrees, is exempt.
+func (o *Orchestrator) checkWorktreeNesting(wtPath string) error {
+	worktrees, err := o.git.ListWorktrees(o.repoPath)
+	if err != nil {
+		slog.Warn("failed to list worktrees for nesting check", "error", err)
+		return nil
+	}
+	for i, wt := range worktrees {
+		// git lists the main working tree first.
+		if i == 0 {
+			continue
+		}
+		switch {
+		case wt.Path == wtPath:
+			return fmt.Errorf("a worktree already exists at %s", wtPath)
+		case isWithin(wtPath, wt.Path):
+			return fmt.Errorf("worktree %s would be inside the worktree of branch %q at %s", wtPath, wt.Branch, wt.Path)
+		case isWithin(wt.Path, wtPath):
+			return fmt.Errorf("worktree %s would contain the worktree of branch %q at %s", wtPath, wt.Branch, wt.Path)
+		}
+	}
+	return nil
+}
+
+// isWithin reports whether path lies strictly inside dir.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // agentMetadata is written to each worktree so orphaned agents can be rediscovered.
 type agentMetadata struct {
+	Branch      string       `json:"branch,omitempty"` // absent in files written before flattened worktree dirs
 	BaseBranch  string       `json:"base_branch"`
 	SessionID   string       `json:"session_id,omitempty"`
 	HarnessType harness.Type `json:"harness,omitempty"`
@@ -1462,8 +2396,9 @@ type agentMetadata struct {
 
 const agentMetadataFile = ".mastermind-agent.json"
 
-func writeAgentMetadata(wtPath, baseBranch, sessionID string, harnessType harness.Type) {
+func writeAgentMetadata(wtPath, branch, baseBranch, sessionID string, harnessType harness.Type) {
 	data, err := json.Marshal(agentMetadata{
+		Branch:      branch,
 		BaseBranch:  baseBranch,
 		SessionID:   sessionID,
 		HarnessType: harnessType,
@@ -1510,11 +2445,19 @@ func (o *Orchestrator) discoverOrphanedAgents() int {
 	}
 
 	// Get all tmux windows in this session
-	windows, err := o.tmux.ListWindows(o.session)
+	windows, err := o.tmux.ListWindows(o.currentSession())
 	if err != nil {
 		slog.Debug("ListWindows failed, skipping orphan discovery", "error", err)
 		return 0
 	}
+	// Windows renamed with a status glyph by a previous session still
+	// belong to their branch.
+	for name, info := range windows {
+		if branch := branchFromWindowName(name); branch != name {
+			delete(windows, name)
+			windows[branch] = info
+		}
+	}
 
 	// Scan worktree directory for subdirectories
 	entries, err := os.ReadDir(o.worktreeDir)
@@ -1528,18 +2471,23 @@ func (o *Orchestrator) discoverOrphanedAgents() int {
 		if !entry.IsDir() {
 			continue
 		}
-		branch := entry.Name()
-		if tracked[branch] {
-			continue
-		}
-
-		wtPath := filepath.Join(o.worktreeDir, branch)
+		wtPath := filepath.Join(o.worktreeDir, entry.Name())
 
 		// Read metadata — if no metadata file exists, this isn't a mastermind-managed worktree
 		meta := readAgentMetadata(wtPath)
 		if meta == nil {
 			continue
 		}
+		// Directories are named after their branch with slashes
+		// flattened; older metadata lacks the branch but was only
+		// written to unflattened directories.
+		branch := meta.Branch
+		if branch == "" {
+			branch = entry.Name()
+		}
+		if tracked[branch] {
+			continue
+		}
 		baseBranch := meta.BaseBranch
 
 		// Determine harness type from metadata, default to Claude Code for backwards compat
@@ -1556,7 +2504,7 @@ func (o *Orchestrator) discoverOrphanedAgents() int {
 			paneID := winInfo.PaneID
 
 			// Check if pane is dead
-			allPanes, paneErr := o.tmux.ListAllPanes(o.session)
+			allPanes, paneErr := o.tmux.ListAllPanes(o.currentSession())
 			if paneErr == nil {
 				if info, ok := allPanes[paneID]; ok && info.Dead {
 					if o.git.HasChanges(wtPath) {
@@ -1593,10 +2541,9 @@ func (o *Orchestrator) discoverOrphanedAgents() int {
 				a.SetSessionID(meta.SessionID)
 			}
 
-			o.readStatuslineCached(a)
-			o.readTodosCached(a)
-
 			o.store.Add(a)
+			o.resetWindowName(a)
+			o.mon.RefreshSidecars(a)
 			discovered++
 			slog.Info("discovered orphaned agent", "id", a.ID, "branch", branch, "status", status)
 		} else {
@@ -1606,8 +2553,10 @@ func (o *Orchestrator) discoverOrphanedAgents() int {
 				continue
 			}
 
-			// Only recover if the worktree has uncommitted changes (work worth saving)
-			if !o.git.HasChanges(wtPath) {
+			// Only recover if the worktree has uncommitted changes (work worth
+			// saving). A broken worktree can't be inspected until it is
+			// repaired, so keep it rather than dropping it silently.
+			if !o.git.IsWorktreeBroken(wtPath) && !o.git.HasChanges(wtPath) {
 				continue
 			}
 
@@ -1618,10 +2567,8 @@ func (o *Orchestrator) discoverOrphanedAgents() int {
 				a.SetSessionID(meta.SessionID)
 			}
 
-			o.readStatuslineCached(a)
-			o.readTodosCached(a)
-
 			o.store.Add(a)
+			o.mon.RefreshSidecars(a)
 			discovered++
 			slog.Info("discovered orphaned agent (no tmux window)", "id", a.ID, "branch", branch, "sessionID", meta.SessionID)
 		}
@@ -1648,11 +2595,24 @@ func (o *Orchestrator) doSaveState() {
 	if err := agent.SaveState(o.statePath, agents); err != nil {
 		slog.Error("failed to save state", "error", err)
 	}
+	if err := prompt.Write(o.promptPath, prompt.Summarize(agents)); err != nil {
+		slog.Error("failed to write prompt status", "error", err)
+	}
 	o.lastSaveTime = time.Now()
 }
 
-// writeClaudeProjectSettings writes .claude/settings.json in the worktree
-// to configure Claude Code's statusline for this agent. It also ensures the
+// RemovePromptStatus deletes the shell prompt status file so prompts stop
+// showing agents once mastermind exits.
+func (o *Orchestrator) RemovePromptStatus() {
+	if err := os.Remove(o.promptPath); err != nil && !os.IsNotExist(err) {
+		slog.Warn("failed to remove prompt status", "error", err)
+	}
+}
+
+// writeClaudeProjectSettings merges mastermind's keys into
+// .claude/settings.local.json in the worktree to configure Claude Code's
+// statusline for this agent. The project may track settings.json, so the
+// keys stay out of it lest the agent commit them. It also ensures the
 // .claude/ directory and .claude-status.json sidecar are git-ignored.
 func (o *Orchestrator) writeClaudeProjectSettings(wtPath string) error {
 	dir := filepath.Join(wtPath, ".claude")
@@ -1668,6 +2628,7 @@ func (o *Orchestrator) writeClaudeProjectSettings(wtPath string) error {
 	// Also gitignore the sidecar file at the worktree root
 	statusIgnorePath := filepath.Join(wtPath, ".claude-status.json")
 	_ = appendGitExclude(wtPath, ".claude-status.json", statusIgnorePath)
+	_ = appendGitExclude(wtPath, hook.HeartbeatFileName, "")
 
 	settings := map[string]interface{}{
 		"statusLine": map[string]string{
@@ -1685,12 +2646,18 @@ func (o *Orchestrator) writeClaudeProjectSettings(wtPath string) error {
 		settings["teammateMode"] = o.teammateMode
 	}
 
-	data, err := json.MarshalIndent(settings, "", "  ")
-	if err != nil {
-		return err
-	}
+	return hook.MergeSettings(filepath.Join(dir, "settings.local.json"), settings)
+}
 
-	return os.WriteFile(filepath.Join(dir, "settings.json"), data, 0o644)
+// restoreClaudeSettings puts the project's own Claude Code settings back
+// in the agent's worktree once mastermind is done with it.
+func (o *Orchestrator) restoreClaudeSettings(a *agent.Agent) {
+	if a.WorktreePath == "" {
+		return
+	}
+	if err := hook.RestoreSettings(a.WorktreePath); err != nil {
+		slog.Warn("failed to restore claude settings", "id", a.ID, "path", a.WorktreePath, "error", err)
+	}
 }
 
 // appendGitExclude adds a pattern to .git/info/exclude for the given worktree
@@ -3,16 +3,22 @@ package orchestrator
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/ci"
+	"github.com/simonbystrom/mastermind/internal/config"
 	"github.com/simonbystrom/mastermind/internal/git"
 	"github.com/simonbystrom/mastermind/internal/notify"
+	"github.com/simonbystrom/mastermind/internal/prompt"
 	"github.com/simonbystrom/mastermind/internal/tmux"
 )
 
@@ -22,18 +28,31 @@ type mockGit struct {
 	mu    sync.Mutex
 	calls []string
 
+	changeSnapshot   map[string]string
+	fastForwardMoved bool
+	fastForwardErr   error
+	fetchBranchErr   error
+	backupErr        error
+	ahead, behind    int
+
 	createBranchErr         error
 	createWorktreeResult    string
 	createWorktreeErr       error
 	removeWorktreeErr       error
 	isBranchCheckedOut      bool
 	isBranchMergedResult    bool
+	unmergedCommits         int
+	archived                []git.ArchivedBranch
+	restoreArchivedErr      error
+	pushUpstreamErr         error
+	worktreeDiffResult      string
 	hasChangesResult        bool
 	headCommitResult        string
 	headCommitErr           error
 	mergeInWorktreeConflict bool
 	mergeInWorktreeErr      error
 	conflictFilesResult     []string
+	changedFilesResult      []string
 	worktreeForBranch       string
 	listBranchesResult      []git.Branch
 	checkoutBranchErr       error
@@ -41,6 +60,25 @@ type mockGit struct {
 	currentBranchErr        error
 	branchExistsResult      bool
 	mergeAbortErr           error
+	applyPatchErr           error
+	rebaseConflict          bool
+	listWorktreesResult     []git.Worktree
+	createBranchFrom        string // start point of the last CreateBranch
+	checkpointErr           error
+	checkpoints             []git.Checkpoint
+	restoreErr              error
+	pushGuardErr            error
+	updateRefErr            error
+	pruneWorktreesResult    []string
+	brokenWorktrees         map[string]bool
+	repairWorktreesErr      error
+	commitsResult           []string
+	commitsTotal            int
+	diffStatResult          git.DiffStat
+	previewMergeResult      git.MergePreview
+	baseMoves               int // times IsAncestor reports base moved past the agent's HEAD
+	mergeDriverProblems     []string
+	conflictDetails         []git.Conflict
 }
 
 func (m *mockGit) record(call string) {
@@ -49,6 +87,18 @@ func (m *mockGit) record(call string) {
 	m.calls = append(m.calls, call)
 }
 
+func (m *mockGit) callCount(call string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, c := range m.calls {
+		if c == call {
+			n++
+		}
+	}
+	return n
+}
+
 func (m *mockGit) hasCalled(call string) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -62,6 +112,7 @@ func (m *mockGit) hasCalled(call string) bool {
 
 func (m *mockGit) CreateBranch(repoPath, branchName, baseBranch string) error {
 	m.record("CreateBranch:" + branchName)
+	m.createBranchFrom = baseBranch
 	return m.createBranchErr
 }
 
@@ -80,18 +131,64 @@ func (m *mockGit) IsBranchMerged(repoPath, branch, baseBranch string) bool {
 	return m.isBranchMergedResult
 }
 
-func (m *mockGit) CreateWorktree(repoPath, worktreeDir, branch string) (string, error) {
+func (m *mockGit) UnmergedCommits(repoPath, branch string) (int, error) {
+	m.record("UnmergedCommits:" + branch)
+	return m.unmergedCommits, nil
+}
+
+func (m *mockGit) AheadBehind(repoPath, branch, base string) (int, int, error) {
+	m.record("AheadBehind:" + branch + ":" + base)
+	return m.ahead, m.behind, nil
+}
+
+func (m *mockGit) ArchiveBranch(repoPath, branch string) (string, error) {
+	m.record("ArchiveBranch:" + branch)
+	return "refs/mastermind/archive/2026-01-02/" + branch, nil
+}
+
+func (m *mockGit) ListArchived(repoPath string) ([]git.ArchivedBranch, error) {
+	m.record("ListArchived")
+	return m.archived, nil
+}
+
+func (m *mockGit) RestoreArchived(repoPath, ref string) (string, error) {
+	m.record("RestoreArchived:" + ref)
+	if m.restoreArchivedErr != nil {
+		return "", m.restoreArchivedErr
+	}
+	for _, a := range m.archived {
+		if a.Ref == ref {
+			return a.Branch, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not an archived branch", ref)
+}
+
+func (m *mockGit) DeleteArchived(repoPath, ref string) error {
+	m.record("DeleteArchived:" + ref)
+	return nil
+}
+
+func (m *mockGit) CreateWorktree(repoPath, wtPath, branch string) (string, error) {
 	m.record("CreateWorktree:" + branch)
 	if m.createWorktreeErr != nil {
 		return "", m.createWorktreeErr
 	}
 	result := m.createWorktreeResult
 	if result == "" {
-		result = worktreeDir + "/" + branch
+		result = wtPath
 	}
 	return result, nil
 }
 
+func (m *mockGit) MoveWorktree(repoPath, from, to string) error {
+	m.record("MoveWorktree:" + from + "->" + to)
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(from, to)
+}
+
 func (m *mockGit) RemoveWorktree(repoPath, wtPath string) error {
 	m.record("RemoveWorktree:" + wtPath)
 	return m.removeWorktreeErr
@@ -102,6 +199,36 @@ func (m *mockGit) HasChanges(wtPath string) bool {
 	return m.hasChangesResult
 }
 
+func (m *mockGit) FastForwardFromOrigin(repoPath, branch string) (bool, error) {
+	m.record("FastForwardFromOrigin:" + branch)
+	return m.fastForwardMoved, m.fastForwardErr
+}
+
+func (m *mockGit) FetchBranch(repoPath, branch string) error {
+	m.record("FetchBranch:" + branch)
+	return m.fetchBranchErr
+}
+
+func (m *mockGit) DiscardChanges(wtPath string) error {
+	m.record("DiscardChanges:" + wtPath)
+	return nil
+}
+
+func (m *mockGit) StashChanges(wtPath, message string, paths ...string) (string, error) {
+	m.record("StashChanges:" + strings.Join(paths, ","))
+	return "stash12", nil
+}
+
+func (m *mockGit) ChangeSnapshot(wtPath string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make(map[string]string, len(m.changeSnapshot))
+	for path, hash := range m.changeSnapshot {
+		snapshot[path] = hash
+	}
+	return snapshot, nil
+}
+
 func (m *mockGit) HeadCommit(repoOrWtPath, ref string) (string, error) {
 	m.record("HeadCommit:" + ref)
 	if m.headCommitErr != nil {
@@ -114,16 +241,29 @@ func (m *mockGit) HeadCommit(repoOrWtPath, ref string) (string, error) {
 	return result, nil
 }
 
-func (m *mockGit) UpdateBranchRef(repoPath, branch, targetCommit string) error {
+func (m *mockGit) UpdateBranchRef(repoPath, branch, targetCommit, oldCommit string) error {
 	m.record("UpdateBranchRef:" + branch)
-	return nil
+	return m.updateRefErr
 }
 
-func (m *mockGit) MergeInWorktree(wtPath, mergeBranch string) (bool, error) {
+func (m *mockGit) MergeInWorktree(wtPath, mergeBranch string, rerere bool) (bool, error) {
 	m.record("MergeInWorktree:" + mergeBranch)
+	if rerere {
+		m.record("MergeInWorktreeRerere:" + mergeBranch)
+	}
 	return m.mergeInWorktreeConflict, m.mergeInWorktreeErr
 }
 
+func (m *mockGit) SquashCommit(wtPath, parent, message string) (string, error) {
+	m.record("SquashCommit:" + parent + ":" + message)
+	return "squash123", nil
+}
+
+func (m *mockGit) PushUpstream(repoPath, branch string) error {
+	m.record("PushUpstream:" + branch)
+	return m.pushUpstreamErr
+}
+
 func (m *mockGit) MergeFFOnly(wtPath, branch string) error {
 	m.record("MergeFFOnly:" + branch)
 	return nil
@@ -171,11 +311,156 @@ func (m *mockGit) ListBranches(repoPath string) ([]git.Branch, error) {
 	return m.listBranchesResult, nil
 }
 
+func (m *mockGit) ChangedFiles(repoPath, base, branch string) ([]string, error) {
+	m.record("ChangedFiles:" + base + "..." + branch)
+	return m.changedFilesResult, nil
+}
+
 func (m *mockGit) CopyUncommittedChanges(srcWT, dstWT string) error {
 	m.record("CopyUncommittedChanges")
 	return nil
 }
 
+func (m *mockGit) BackupChanges(wtPath, dir string) (bool, error) {
+	m.record("BackupChanges:" + wtPath)
+	return m.hasChangesResult, m.backupErr
+}
+
+func (m *mockGit) ApplyPatch(wtPath string, patch []byte) error {
+	m.record("ApplyPatch")
+	return m.applyPatchErr
+}
+
+func (m *mockGit) ListWorktrees(repoPath string) ([]git.Worktree, error) {
+	m.record("ListWorktrees")
+	return m.listWorktreesResult, nil
+}
+
+func (m *mockGit) PruneWorktrees(repoPath string) ([]string, error) {
+	m.record("PruneWorktrees")
+	return m.pruneWorktreesResult, nil
+}
+
+func (m *mockGit) GCAuto(repoPath string) error {
+	m.record("GCAuto")
+	return nil
+}
+
+func (m *mockGit) IsWorktreeBroken(wtPath string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.brokenWorktrees[wtPath]
+}
+
+func (m *mockGit) CreateCheckpoint(wtPath, branch, label string) (git.Checkpoint, error) {
+	m.record("CreateCheckpoint:" + branch + ":" + label)
+	if m.checkpointErr != nil {
+		return git.Checkpoint{}, m.checkpointErr
+	}
+	return git.Checkpoint{Tag: "checkpoint/" + branch + "/1", Commit: "snap1", Label: label, Created: time.Now()}, nil
+}
+
+func (m *mockGit) ListCheckpoints(repoPath, branch string) ([]git.Checkpoint, error) {
+	m.record("ListCheckpoints:" + branch)
+	return m.checkpoints, nil
+}
+
+func (m *mockGit) RestoreCheckpoint(wtPath, tag string) error {
+	m.record("RestoreCheckpoint:" + wtPath + ":" + tag)
+	return m.restoreErr
+}
+
+func (m *mockGit) InstallPushGuard(repoPath string) error {
+	m.record("InstallPushGuard")
+	return m.pushGuardErr
+}
+
+func (m *mockGit) SetPushBlocked(wtPath string, blocked bool) error {
+	m.record(fmt.Sprintf("SetPushBlocked:%s:%t", wtPath, blocked))
+	return nil
+}
+
+func (m *mockGit) RepairWorktrees(repoPath string, wtPaths []string) error {
+	m.record("RepairWorktrees:" + strings.Join(wtPaths, ","))
+	if m.repairWorktreesErr != nil {
+		return m.repairWorktreesErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range wtPaths {
+		delete(m.brokenWorktrees, p)
+	}
+	return nil
+}
+
+func (m *mockGit) Commits(repoPath, base, branch string, limit int) ([]string, int, error) {
+	m.record("Commits:" + base + ".." + branch)
+	return m.commitsResult, m.commitsTotal, nil
+}
+
+func (m *mockGit) WorktreeDiffStat(wtPath, base string) (git.DiffStat, error) {
+	m.record("WorktreeDiffStat:" + wtPath)
+	return m.diffStatResult, nil
+}
+
+func (m *mockGit) WorktreeDiff(wtPath, base string) ([]byte, error) {
+	m.record("WorktreeDiff:" + wtPath + ":" + base)
+	return []byte(m.worktreeDiffResult), nil
+}
+
+func (m *mockGit) PreviewMerge(repoPath, base, branch string) (git.MergePreview, error) {
+	m.record("PreviewMerge:" + base + "..." + branch)
+	return m.previewMergeResult, nil
+}
+
+func (m *mockGit) IsAncestor(repoPath, ancestor, descendant string) bool {
+	m.record("IsAncestor:" + ancestor + ":" + descendant)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.baseMoves > 0 {
+		m.baseMoves--
+		return false
+	}
+	return true
+}
+
+func (m *mockGit) MergeDriverProblems(wtPath string, files []string) []string {
+	return m.mergeDriverProblems
+}
+
+func (m *mockGit) ConflictDetails(wtPath string) ([]git.Conflict, error) {
+	return m.conflictDetails, nil
+}
+
+func (m *mockGit) ResolveConflict(wtPath, path string, side git.ConflictSide) error {
+	m.record("ResolveConflict:" + path + ":" + string(side))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var left []string
+	for _, f := range m.conflictFilesResult {
+		if f != path {
+			left = append(left, f)
+		}
+	}
+	m.conflictFilesResult = left
+	return nil
+}
+
+func (m *mockGit) CommitMerge(wtPath string) error {
+	m.record("CommitMerge")
+	return nil
+}
+
+func (m *mockGit) Rebase(wtPath, onto string) (bool, error) {
+	m.record("Rebase:" + wtPath + ":" + onto)
+	return m.rebaseConflict, nil
+}
+
+func (m *mockGit) RebaseOnto(wtPath, onto, upstream string) (bool, error) {
+	m.record("RebaseOnto:" + wtPath + ":" + onto + ":" + upstream)
+	return m.rebaseConflict, nil
+}
+
 type mockTmux struct {
 	mu    sync.Mutex
 	calls []string
@@ -190,6 +475,16 @@ type mockTmux struct {
 	listWindowsResult       map[string]tmux.WindowInfo
 	listAllPanesResult      map[string]tmux.PaneInfo
 	currentWindowNameResult string
+	newWindowCommand        []string
+	respawnCommand          []string
+	newWindowSession        string
+	sessionID               string
+	sessionName             string
+	paneSessions            map[string]string // paneID → session ID, overrides sessionID
+	listSessionsResult      []string
+	sentKeys                []string // keys of each SendKeys call, space-joined
+	pasted                  []string // text of each PasteText call
+	panePID                 int
 }
 
 func (m *mockTmux) record(call string) {
@@ -211,6 +506,10 @@ func (m *mockTmux) hasCalled(call string) bool {
 
 func (m *mockTmux) NewWindow(session, name, dir string, command []string) (string, error) {
 	m.record("NewWindow:" + name)
+	m.mu.Lock()
+	m.newWindowCommand = command
+	m.newWindowSession = session
+	m.mu.Unlock()
 	if m.newWindowErr != nil {
 		return "", m.newWindowErr
 	}
@@ -233,6 +532,14 @@ func (m *mockTmux) SplitWindow(paneID, dir string, horizontal bool, sizePercent
 	return result, nil
 }
 
+func (m *mockTmux) SplitWindowDetached(paneID, dir string, horizontal bool, sizePercent int, command []string) (string, error) {
+	m.record(fmt.Sprintf("SplitWindowDetached:%s:%t:%d:%s", paneID, horizontal, sizePercent, strings.Join(command, " ")))
+	if m.splitWindowErr != nil {
+		return "", m.splitWindowErr
+	}
+	return "%9", nil
+}
+
 func (m *mockTmux) KillWindow(target string) error {
 	m.record("KillWindow:" + target)
 	return nil
@@ -243,11 +550,43 @@ func (m *mockTmux) KillPane(paneID string) error {
 	return nil
 }
 
+func (m *mockTmux) RespawnPane(paneID string) error {
+	m.record("RespawnPane:" + paneID)
+	return nil
+}
+
+func (m *mockTmux) RespawnPaneWith(paneID, dir string, command []string) error {
+	m.record("RespawnPaneWith:" + paneID)
+	m.mu.Lock()
+	m.respawnCommand = command
+	m.mu.Unlock()
+	return nil
+}
+
 func (m *mockTmux) SendKeys(paneID string, keys ...string) error {
 	m.record("SendKeys:" + paneID)
+	m.mu.Lock()
+	m.sentKeys = append(m.sentKeys, strings.Join(keys, " "))
+	m.mu.Unlock()
 	return nil
 }
 
+func (m *mockTmux) PasteText(paneID, text string) error {
+	m.record("PasteText:" + paneID)
+	m.mu.Lock()
+	m.pasted = append(m.pasted, text)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *mockTmux) PanePID(paneID string) (int, error) {
+	m.record("PanePID:" + paneID)
+	if m.panePID == 0 {
+		return 0, fmt.Errorf("no pid for pane %s", paneID)
+	}
+	return m.panePID, nil
+}
+
 func (m *mockTmux) SelectWindow(target string) error {
 	m.record("SelectWindow:" + target)
 	return nil
@@ -294,6 +633,39 @@ func (m *mockTmux) RenameWindow(target, name string) error {
 	return nil
 }
 
+func (m *mockTmux) RingBell(target string) error {
+	m.record("RingBell:" + target)
+	return nil
+}
+
+func (m *mockTmux) SessionForPane(paneID string) (string, string, error) {
+	m.record("SessionForPane:" + paneID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id, ok := m.paneSessions[paneID]; ok {
+		return id, "", nil
+	}
+	if m.sessionID == "" {
+		return "", "", fmt.Errorf("not available in mock")
+	}
+	return m.sessionID, m.sessionName, nil
+}
+
+func (m *mockTmux) ListSessions() ([]string, error) {
+	m.record("ListSessions")
+	return m.listSessionsResult, nil
+}
+
+func (m *mockTmux) CreateSession(name string) error {
+	m.record("CreateSession:" + name)
+	return nil
+}
+
+func (m *mockTmux) SwitchClient(target string) error {
+	m.record("SwitchClient:" + target)
+	return nil
+}
+
 func (m *mockTmux) CurrentWindowName(target string) (string, error) {
 	m.record("CurrentWindowName:" + target)
 	if m.currentWindowNameResult != "" {
@@ -321,12 +693,44 @@ func (m *mockMonitor) GetPaneStatus(paneID string) (tmux.PaneStatus, error) {
 	return m.paneStatus, m.paneStatusErr
 }
 
+func (m *mockMonitor) Statusline(paneID string) *tmux.StatuslineFromPane {
+	return nil
+}
+
+func (m *mockMonitor) Tail(paneID string, n int) []string {
+	return nil
+}
+
 func (m *mockMonitor) Remove(paneID string) {
 	m.record("Remove:" + paneID)
 }
 
 // --- Helper ---
 
+// finishAgent marks the agent's pane as exited and runs one monitor pass.
+func finishAgent(o *Orchestrator, mt *mockTmux, a *agent.Agent) {
+	mt.mu.Lock()
+	mt.listAllPanesResult = map[string]tmux.PaneInfo{
+		a.TmuxPaneID: {WindowID: a.TmuxWindow, Dead: true},
+	}
+	mt.mu.Unlock()
+	o.mon.Poll()
+}
+
+// idleAgent makes the agent's pane show an input prompt and runs one
+// monitor pass.
+func idleAgent(o *Orchestrator, mt *mockTmux, mm *mockMonitor, a *agent.Agent) {
+	mt.mu.Lock()
+	mt.listAllPanesResult = map[string]tmux.PaneInfo{
+		a.TmuxPaneID: {WindowID: a.TmuxWindow},
+	}
+	mt.mu.Unlock()
+	mm.mu.Lock()
+	mm.paneStatus = tmux.PaneStatus{WaitingFor: "input"}
+	mm.mu.Unlock()
+	o.mon.Poll()
+}
+
 func newTestOrch(t *testing.T, mg *mockGit, mt *mockTmux, mm *mockMonitor) *Orchestrator {
 	t.Helper()
 	dir := t.TempDir()
@@ -378,6 +782,59 @@ func TestSpawnAgent_Success(t *testing.T) {
 	}
 }
 
+func TestSpawnAgent_WithTask(t *testing.T) {
+	mt := &mockTmux{windowIDForPane: "@1"}
+	mm := &mockMonitor{}
+	o := newTestOrch(t, &mockGit{}, mt, mm)
+	task := "Fix the flaky login test.\nKeep the fix small."
+
+	if err := o.SpawnAgent("feat/task", "main", true, "claude", WithTask(task)); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	if slices.Contains(mt.newWindowCommand, task) {
+		t.Errorf("command = %q, want the task sent to the pane instead", mt.newWindowCommand)
+	}
+	if a.GetPendingTask() != task || a.Prompt != task {
+		t.Errorf("pending task = %q, prompt = %q; want the task", a.GetPendingTask(), a.Prompt)
+	}
+
+	idleAgent(o, mt, mm, a)
+
+	if len(mt.pasted) != 1 || mt.pasted[0] != task {
+		t.Errorf("pasted = %q, want the task", mt.pasted)
+	}
+	if !slices.Contains(mt.sentKeys, "Enter") {
+		t.Errorf("sent keys = %q, want the task submitted", mt.sentKeys)
+	}
+	if a.GetPendingTask() != "" {
+		t.Errorf("pending task = %q after it was sent", a.GetPendingTask())
+	}
+}
+
+func TestSpawnAgent_WindowLayout(t *testing.T) {
+	mt := &mockTmux{windowIDForPane: "@1"}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	o.windowPanes = []config.Pane{
+		{Size: 30},
+		{Command: "npm test --watch", Split: "below", Size: 25},
+	}
+
+	if err := o.SpawnAgent("feat/layout", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+
+	if !mt.hasCalled("SplitWindowDetached:%1:true:30:") {
+		t.Error("expected a shell pane to the right of the agent")
+	}
+	if !mt.hasCalled("SplitWindowDetached:%1:false:25:npm test --watch") {
+		t.Error("expected the test watcher pane below the agent")
+	}
+	if a := o.store.All()[0]; a.TmuxPaneID != "%1" {
+		t.Errorf("agent pane = %q, want the original pane %%1", a.TmuxPaneID)
+	}
+}
+
 func TestSpawnAgent_DuplicateBranch(t *testing.T) {
 	mg := &mockGit{}
 	mt := &mockTmux{windowIDForPane: "@1"}
@@ -391,6 +848,123 @@ func TestSpawnAgent_DuplicateBranch(t *testing.T) {
 	}
 }
 
+func TestSpawnAgent_InvalidBranchName(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+
+	err := o.SpawnAgent("feat/../../etc", "main", true, "claude")
+	if err == nil || !strings.Contains(err.Error(), "invalid branch name") {
+		t.Fatalf("err = %v, want invalid branch name", err)
+	}
+	if mg.hasCalled("CreateBranch:feat/../../etc") {
+		t.Error("branch created despite the invalid name")
+	}
+}
+
+func TestSpawnAgent_NestedWorktree(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	// A worktree added by hand with the nested layout.
+	mg.listWorktreesResult = []git.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: o.worktreeDir + "/feat/x", Branch: "feat/x"},
+	}
+
+	err := o.SpawnAgent("feat", "main", true, "claude")
+	if err == nil || !strings.Contains(err.Error(), "would contain the worktree of branch \"feat/x\"") {
+		t.Fatalf("err = %v, want nested worktree refused", err)
+	}
+	if mg.hasCalled("CreateBranch:feat") {
+		t.Error("branch created despite refusing the worktree")
+	}
+
+	// The main working tree contains .worktrees and never counts.
+	if err := o.SpawnAgent("fix/y", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+}
+
+func TestSpawnAgent_FlattensWorktreeDir(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	// A branch literally named feat__x already owns the flattened name.
+	if err := os.MkdirAll(filepath.Join(o.worktreeDir, "feat__x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	if want := filepath.Join(o.worktreeDir, "feat__x-2"); a.WorktreePath != want {
+		t.Errorf("worktree path = %q, want %q", a.WorktreePath, want)
+	}
+}
+
+func TestSpawnAgent_ReusesWorktree(t *testing.T) {
+	mg := &mockGit{isBranchCheckedOut: true, currentBranchResult: "feat/x"}
+	mt := &mockTmux{newWindowErr: fmt.Errorf("tmux error")}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+	wtPath := filepath.Join(o.worktreeDir, "feat__x")
+	mg.listWorktreesResult = []git.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: wtPath, Branch: "feat/x"},
+	}
+
+	// A failed spawn leaves the reused worktree alone.
+	if err := o.SpawnAgent("feat/x", "", false, "claude", ReuseWorktree()); err == nil {
+		t.Fatal("expected tmux error")
+	}
+	if mg.hasCalled("RemoveWorktree:" + wtPath) {
+		t.Error("reused worktree removed after a failed spawn")
+	}
+
+	mt.newWindowErr = nil
+	mt.windowIDForPane = "@1"
+	if err := o.SpawnAgent("feat/x", "", false, "claude", ReuseWorktree()); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	if mg.hasCalled("CreateWorktree:feat/x") {
+		t.Error("expected the existing worktree to be reused")
+	}
+	if a := o.store.All()[0]; a.WorktreePath != wtPath {
+		t.Errorf("worktree path = %q, want %q", a.WorktreePath, wtPath)
+	}
+	if len(o.LeftoverWorktrees()) != 0 {
+		t.Error("worktree still reported as leftover after the agent took it")
+	}
+}
+
+func TestReusableWorktree_Verifies(t *testing.T) {
+	mg := &mockGit{currentBranchResult: "feat/x"}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	wtPath := filepath.Join(o.worktreeDir, "feat__x")
+	mg.listWorktreesResult = []git.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: wtPath, Branch: "feat/x"},
+	}
+
+	if path, err := o.ReusableWorktree("feat/x"); err != nil || path != wtPath {
+		t.Fatalf("ReusableWorktree = %q, %v; want %q", path, err, wtPath)
+	}
+	if path, err := o.ReusableWorktree("main"); err != nil || path != "" {
+		t.Errorf("main working tree offered for reuse: %q, %v", path, err)
+	}
+
+	mg.conflictFilesResult = []string{"a.go"}
+	if _, err := o.ReusableWorktree("feat/x"); err == nil || !strings.Contains(err.Error(), "unresolved conflicts") {
+		t.Errorf("conflicts: err = %v", err)
+	}
+	mg.currentBranchResult = "other"
+	if _, err := o.ReusableWorktree("feat/x"); err == nil || !strings.Contains(err.Error(), "not on branch") {
+		t.Errorf("detached: err = %v", err)
+	}
+	mg.brokenWorktrees = map[string]bool{wtPath: true}
+	if _, err := o.ReusableWorktree("feat/x"); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("broken: err = %v", err)
+	}
+}
+
 func TestSpawnAgent_BranchCheckedOut(t *testing.T) {
 	mg := &mockGit{isBranchCheckedOut: true}
 	mt := &mockTmux{}
@@ -426,12 +1000,140 @@ func TestSpawnAgent_TmuxFails_CleansUpWorktree(t *testing.T) {
 	if !found {
 		t.Error("expected RemoveWorktree call for cleanup")
 	}
+	if !mg.hasCalled("DeleteBranch:feat/x") {
+		t.Error("expected the branch the spawn created to be deleted, so a retry can create it again")
+	}
+
+	if len(o.store.All()) != 0 {
+		t.Error("store should be empty after failed spawn")
+	}
+}
+
+func TestSpawnAgent_FailureRollsBackOnlyWhatItCreated(t *testing.T) {
+	mg := &mockGit{createWorktreeErr: fmt.Errorf("worktree error")}
+	mt := &mockTmux{newWindowErr: fmt.Errorf("tmux error")}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err == nil {
+		t.Fatal("expected the worktree error")
+	}
+	if !mg.hasCalled("DeleteBranch:feat/x") || mg.hasCalled("RemoveWorktree:"+filepath.Join(o.worktreeDir, "feat__x")) {
+		t.Errorf("calls = %v, want only the new branch deleted", mg.calls)
+	}
+
+	// An existing branch predates the spawn and is kept.
+	mg.mu.Lock()
+	mg.createWorktreeErr = nil
+	mg.calls = nil
+	mg.mu.Unlock()
+	if err := o.SpawnAgent("feat/y", "", false, "claude"); err == nil {
+		t.Fatal("expected the tmux error")
+	}
+	if mg.hasCalled("DeleteBranch:feat/y") {
+		t.Error("existing branch deleted after a failed spawn")
+	}
+}
+
+func TestSpawnAgentFromPatch(t *testing.T) {
+	mg := &mockGit{}
+	mt := &mockTmux{windowIDForPane: "@1"}
+	mm := &mockMonitor{}
+	o := newTestOrch(t, mg, mt, mm)
+
+	if err := o.SpawnAgentFromPatch("feat/x", "main", []byte("diff"), "claude"); err != nil {
+		t.Fatalf("SpawnAgentFromPatch: %v", err)
+	}
 
+	if !mg.hasCalled("CreateBranch:feat/x") || !mg.hasCalled("ApplyPatch") {
+		t.Errorf("calls = %v, want CreateBranch and ApplyPatch", mg.calls)
+	}
+	cmd := mt.newWindowCommand
+	if len(cmd) == 0 || cmd[len(cmd)-1] != patchPrompt {
+		t.Errorf("command = %v, want patch prompt as last argument", cmd)
+	}
+}
+
+func TestSpawnAgentFromPatch_ApplyFails_CleansUp(t *testing.T) {
+	mg := &mockGit{applyPatchErr: fmt.Errorf("does not apply")}
+	mt := &mockTmux{windowIDForPane: "@1"}
+	mm := &mockMonitor{}
+	o := newTestOrch(t, mg, mt, mm)
+
+	err := o.SpawnAgentFromPatch("feat/x", "main", []byte("diff"), "claude")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !mg.hasCalled("DeleteBranch:feat/x") {
+		t.Error("expected created branch to be deleted")
+	}
+	if mt.hasCalled("NewWindow:feat/x") {
+		t.Error("agent should not be launched")
+	}
 	if len(o.store.All()) != 0 {
 		t.Error("store should be empty after failed spawn")
 	}
 }
 
+func TestSpawnAgentFromPatch_Empty(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+
+	if err := o.SpawnAgentFromPatch("feat/x", "main", []byte("  \n"), "claude"); err == nil {
+		t.Fatal("expected error for empty patch")
+	}
+	if mg.hasCalled("CreateBranch:feat/x") {
+		t.Error("should not create a branch for an empty patch")
+	}
+}
+
+func TestSpawnAgentFromCI_RemoteBranch(t *testing.T) {
+	wt := t.TempDir()
+	mg := &mockGit{createWorktreeResult: wt}
+	mt := &mockTmux{windowIDForPane: "@1"}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+
+	run := ci.Run{ID: 9, Branch: "feat/red", Workflow: "CI"}
+	if err := o.SpawnAgentFromCI(run, "FAIL: TestX\n", "claude"); err != nil {
+		t.Fatalf("SpawnAgentFromCI: %v", err)
+	}
+
+	if !mg.hasCalled("FetchBranch:feat/red") || !mg.hasCalled("CreateBranch:feat/red") {
+		t.Errorf("calls = %v, want the branch fetched and created from origin", mg.calls)
+	}
+	logs, err := os.ReadFile(filepath.Join(wt, ci.LogFileName))
+	if err != nil || string(logs) != "FAIL: TestX\n" {
+		t.Errorf("log file = %q, %v", logs, err)
+	}
+	cmd := mt.newWindowCommand
+	if len(cmd) == 0 || !strings.Contains(cmd[len(cmd)-1], ci.LogFileName) || strings.Contains(strings.Join(cmd, " "), "FAIL: TestX") {
+		t.Errorf("command = %v, want a prompt pointing at the log file rather than holding the log", cmd)
+	}
+}
+
+func TestSpawnAgentFromCI_LocalBranch(t *testing.T) {
+	mg := &mockGit{branchExistsResult: true, createWorktreeResult: t.TempDir()}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	if err := o.SpawnAgentFromCI(ci.Run{ID: 9, Branch: "feat/red"}, "log", "claude"); err != nil {
+		t.Fatalf("SpawnAgentFromCI: %v", err)
+	}
+	if mg.hasCalled("CreateBranch:feat/red") {
+		t.Error("should reuse the existing local branch")
+	}
+}
+
+func TestSpawnAgentFromCI_FetchFails(t *testing.T) {
+	mg := &mockGit{fetchBranchErr: errors.New("offline"), createWorktreeResult: t.TempDir()}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	if err := o.SpawnAgentFromCI(ci.Run{ID: 9, Branch: "feat/red"}, "log", "claude"); err == nil {
+		t.Fatal("expected the fetch failure")
+	}
+	if mg.hasCalled("CreateBranch:feat/red") || len(o.store.All()) != 0 {
+		t.Error("nothing should be created from a stale origin branch")
+	}
+}
+
 func TestDismissAgent_Success(t *testing.T) {
 	mg := &mockGit{}
 	mt := &mockTmux{windowIDForPane: "@1", paneExistsResult: true}
@@ -455,6 +1157,48 @@ func TestDismissAgent_Success(t *testing.T) {
 	}
 }
 
+func TestToggleShell(t *testing.T) {
+	mt := &mockTmux{windowIDForPane: "@1", splitWindowResult: "%4", paneExistsResult: true}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	a := agent.NewAgent("feat/sh", "main", "/wt", "@1", "%1", "claude")
+	o.store.Add(a)
+
+	open, err := o.ToggleShell(a.ID)
+	if err != nil || !open {
+		t.Fatalf("ToggleShell() = %v, %v; want open", open, err)
+	}
+	if a.GetShellPaneID() != "%4" {
+		t.Errorf("shell pane = %q, want %%4", a.GetShellPaneID())
+	}
+
+	open, err = o.ToggleShell(a.ID)
+	if err != nil || open {
+		t.Fatalf("second ToggleShell() = %v, %v; want closed", open, err)
+	}
+	if !mt.hasCalled("KillPane:%4") {
+		t.Error("expected the shell pane to be killed")
+	}
+	if a.GetShellPaneID() != "" {
+		t.Error("shell pane should be cleared")
+	}
+}
+
+func TestDismissAgent_KillsShellPane(t *testing.T) {
+	mt := &mockTmux{}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	a := agent.NewAgent("feat/sh", "main", "/wt", "@1", "%1", "claude")
+	a.SetStatus(agent.StatusDone)
+	a.SetShellPaneID("%4")
+	o.store.Add(a)
+
+	if err := o.DismissAgent(a.ID, false); err != nil {
+		t.Fatalf("DismissAgent: %v", err)
+	}
+	if !mt.hasCalled("KillPane:%4") {
+		t.Error("expected the shell pane to be killed on dismiss")
+	}
+}
+
 func TestDismissAgent_NotFound(t *testing.T) {
 	mg := &mockGit{}
 	mt := &mockTmux{}
@@ -493,7 +1237,7 @@ func TestMergeAgent_NoConflicts(t *testing.T) {
 	agents := o.store.All()
 	id := agents[0].ID
 
-	result := o.MergeAgent(id, true, true)
+	result := o.MergeAgent(id, true, true, false, MergeCommit, "")
 	if !result.Success {
 		t.Errorf("expected success, got error: %s", result.Error)
 	}
@@ -507,6 +1251,244 @@ func TestMergeAgent_NoConflicts(t *testing.T) {
 	}
 }
 
+func TestMergeAgent_KeepsBranchWithNewCommits(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123", unmergedCommits: 1}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	if result := o.MergeAgent(a.ID, true, true, false, MergeCommit, ""); !result.Success {
+		t.Fatalf("MergeAgent = %+v, want success", result)
+	}
+	if mg.hasCalled("DeleteBranch:feat/x") {
+		t.Error("branch with commits base doesn't have was deleted")
+	}
+}
+
+func TestMergeAgent_Squash(t *testing.T) {
+	mg := &mockGit{
+		headCommitResult: "abc123",
+		commitsResult:    []string{"bbb2222 more wip", "aaa1111 wip"},
+		commitsTotal:     2,
+		unmergedCommits:  2,
+	}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	result := o.MergeAgent(a.ID, true, true, false, MergeSquash, "")
+	if !result.Success {
+		t.Fatalf("MergeAgent = %+v, want success", result)
+	}
+	if !mg.hasCalled("MergeInWorktree:main") {
+		t.Error("expected base merged into the branch first")
+	}
+	if !mg.hasCalled("SquashCommit:abc123:Squash feat/x (2 commits)\n\n* wip\n* more wip") {
+		t.Errorf("calls = %v, want one commit on base with the generated message", mg.calls)
+	}
+	if !mg.hasCalled("UpdateBranchRef:main") {
+		t.Error("expected base moved to the squash commit")
+	}
+	// Its commits are unmerged, but their changes landed.
+	if !mg.hasCalled("DeleteBranch:feat/x") {
+		t.Error("expected the squashed branch deleted")
+	}
+}
+
+func TestMergeAgent_Push(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123"}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	o.SpawnAgent("feat/x", "main", true, "claude")
+
+	result := o.MergeAgent(o.store.All()[0].ID, true, true, true, MergeCommit, "")
+	if !result.Success || result.PushError != "" {
+		t.Fatalf("MergeAgent = %+v, want success", result)
+	}
+	if !mg.hasCalled("PushUpstream:main") {
+		t.Errorf("calls = %v, want base pushed", mg.calls)
+	}
+}
+
+func TestMergeAgent_PushFails(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123", pushUpstreamErr: fmt.Errorf("main has no upstream branch")}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	o.SpawnAgent("feat/x", "main", true, "claude")
+
+	// The merge stands; only the push is reported as failed.
+	result := o.MergeAgent(o.store.All()[0].ID, true, true, true, MergeCommit, "")
+	if !result.Success || result.PushError != "main has no upstream branch" {
+		t.Errorf("MergeAgent = %+v, want success with the push error", result)
+	}
+	if !mg.hasCalled("DeleteBranch:feat/x") {
+		t.Error("expected cleanup to run despite the failed push")
+	}
+}
+
+func TestSquashMessage_SingleCommit(t *testing.T) {
+	mg := &mockGit{commitsResult: []string{"aaa1111 Add sign-in"}, commitsTotal: 1}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	o.SpawnAgent("feat/x", "main", true, "claude")
+
+	if msg, err := o.SquashMessage(o.store.All()[0].ID); err != nil || msg != "Add sign-in" {
+		t.Errorf("SquashMessage = %q, %v, want the only commit's subject", msg, err)
+	}
+}
+
+func TestMergeAgent_Rebase(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123"}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	result := o.MergeAgent(a.ID, true, true, false, MergeRebase, "")
+	if !result.Success {
+		t.Fatalf("MergeAgent = %+v, want success", result)
+	}
+	if !mg.hasCalled("Rebase:"+a.WorktreePath+":main") || mg.hasCalled("MergeInWorktree:main") {
+		t.Errorf("calls = %v, want the branch rebased onto base instead of merged", mg.calls)
+	}
+	if !mg.hasCalled("UpdateBranchRef:main") {
+		t.Error("expected base to be fast-forwarded")
+	}
+}
+
+func TestMergeAgent_RebaseConflicts(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123", rebaseConflict: true}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	result := o.MergeAgent(a.ID, true, true, false, MergeRebase, "")
+	if result.Success || result.Conflict || result.Error != ErrRebaseConflicts.Error() {
+		t.Fatalf("MergeAgent = %+v, want the rebase conflict reported", result)
+	}
+	if mg.hasCalled("UpdateBranchRef:main") {
+		t.Error("base must not be moved")
+	}
+	if a.GetStatus() == agent.StatusConflicts {
+		t.Error("an aborted rebase leaves no conflicts to resolve")
+	}
+}
+
+func TestMergeAgent_BaseMovedDuringUpdate(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123", updateRefErr: fmt.Errorf("update-ref: %w", git.ErrRefMoved)}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	id := o.store.All()[0].ID
+
+	result := o.MergeAgent(id, true, true, false, MergeCommit, "")
+	if result.Success || !strings.Contains(result.Error, ErrBaseMoved.Error()) {
+		t.Fatalf("MergeAgent = %+v, want a base-moved error", result)
+	}
+	if len(o.store.All()) != 1 {
+		t.Error("agent should be kept when the merge is aborted")
+	}
+}
+
+func TestMergeAgent_BaseMoved(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123", baseMoves: 10}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	id := o.store.All()[0].ID
+
+	result := o.MergeAgent(id, true, true, false, MergeCommit, "")
+	if result.Success || !strings.Contains(result.Error, ErrBaseMoved.Error()) {
+		t.Fatalf("MergeAgent = %+v, want a base-moved error", result)
+	}
+	if !mg.hasCalled("IsAncestor:abc123:abc123") {
+		t.Error("expected base to be checked against the agent's HEAD")
+	}
+	if mg.hasCalled("UpdateBranchRef:main") {
+		t.Error("base must not be moved")
+	}
+	if len(o.store.All()) != 1 {
+		t.Error("agent should be kept when the merge is aborted")
+	}
+}
+
+func TestMergeAgent_RetriesWhenBaseMoves(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123", baseMoves: 2}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	id := o.store.All()[0].ID
+
+	result := o.MergeAgent(id, true, true, false, MergeCommit, "")
+	if !result.Success {
+		t.Fatalf("MergeAgent = %+v, want success after merging base again", result)
+	}
+	merges := 0
+	for _, c := range mg.calls {
+		if strings.HasPrefix(c, "MergeInWorktree:") {
+			merges++
+		}
+	}
+	if merges != 3 {
+		t.Errorf("base merged into the branch %d times, want 3", merges)
+	}
+}
+
+func TestMergeAgent_RestacksChildren(t *testing.T) {
+	mg := &mockGit{}
+	mt := &mockTmux{windowIDForPane: "@1"}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+
+	parent := agent.NewAgent("feat/a", "main", t.TempDir(), "@1", "%1", "claude")
+	idle := agent.NewAgent("feat/b", "feat/a", t.TempDir(), "@2", "%2", "claude")
+	idle.SetStatus(agent.StatusReviewReady)
+	busy := agent.NewAgent("feat/c", "feat/a", t.TempDir(), "@3", "%3", "claude")
+	o.store.Add(parent)
+	o.store.Add(idle)
+	o.store.Add(busy)
+
+	if result := o.MergeAgent(parent.ID, true, true, false, MergeCommit, ""); !result.Success {
+		t.Fatalf("merge failed: %s", result.Error)
+	}
+
+	for _, child := range []*agent.Agent{idle, busy} {
+		if got := child.GetBaseBranch(); got != "main" {
+			t.Errorf("%s base = %q, want %q", child.Branch, got, "main")
+		}
+	}
+	if !mg.hasCalled("Rebase:" + idle.WorktreePath + ":main") {
+		t.Error("expected idle child to be rebased onto main")
+	}
+	if mg.hasCalled("Rebase:" + busy.WorktreePath + ":main") {
+		t.Error("running child should not be rebased")
+	}
+	if meta := readAgentMetadata(idle.WorktreePath); meta == nil || meta.BaseBranch != "main" {
+		t.Errorf("metadata = %+v, want base main", meta)
+	}
+}
+
+func TestMergeAgent_SquashRestacksChildOntoSquash(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123"}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	parent := agent.NewAgent("feat/a", "main", t.TempDir(), "@1", "%1", "claude")
+	child := agent.NewAgent("feat/b", "feat/a", t.TempDir(), "@2", "%2", "claude")
+	child.SetStatus(agent.StatusReviewReady)
+	o.store.Add(parent)
+	o.store.Add(child)
+
+	if result := o.MergeAgent(parent.ID, true, true, false, MergeSquash, "squash a"); !result.Success {
+		t.Fatalf("merge failed: %s", result.Error)
+	}
+	// Only the child's own commits, after the parent's head, are replayed.
+	if !mg.hasCalled("RebaseOnto:" + child.WorktreePath + ":main:abc123") {
+		t.Errorf("calls = %v, want the child rebased onto main past the parent's head", mg.calls)
+	}
+	if mg.hasCalled("Rebase:" + child.WorktreePath + ":main") {
+		t.Error("a plain rebase would replay the squashed parent's commits")
+	}
+}
+
 func TestMergeAgent_WithConflicts(t *testing.T) {
 	mg := &mockGit{
 		mergeInWorktreeConflict: true,
@@ -520,7 +1502,7 @@ func TestMergeAgent_WithConflicts(t *testing.T) {
 	agents := o.store.All()
 	id := agents[0].ID
 
-	result := o.MergeAgent(id, true, true)
+	result := o.MergeAgent(id, true, true, false, MergeCommit, "")
 	if result.Success {
 		t.Error("should not succeed with conflicts")
 	}
@@ -541,6 +1523,24 @@ func TestMergeAgent_WithConflicts(t *testing.T) {
 	}
 }
 
+func TestMergeAgent_MergeDriverProblems(t *testing.T) {
+	mg := &mockGit{
+		mergeInWorktreeConflict: true,
+		conflictFilesResult:     []string{"deps.lock"},
+		mergeDriverProblems:     []string{`deps.lock: merge driver "lockfile" from .gitattributes is not configured`},
+	}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	result := o.MergeAgent(o.store.All()[0].ID, true, true, false, MergeCommit, "")
+	if !result.Conflict || len(result.ConflictNotes) != 1 {
+		t.Errorf("MergeAgent = %+v, want the merge driver problem", result)
+	}
+	if !mg.hasCalled("MergeInWorktreeRerere:main") {
+		t.Error("expected base merged with rerere")
+	}
+}
+
 func TestMergeAgent_UncommittedChanges(t *testing.T) {
 	mg := &mockGit{hasChangesResult: true}
 	mt := &mockTmux{windowIDForPane: "@1"}
@@ -551,44 +1551,12 @@ func TestMergeAgent_UncommittedChanges(t *testing.T) {
 	agents := o.store.All()
 	id := agents[0].ID
 
-	result := o.MergeAgent(id, true, true)
+	result := o.MergeAgent(id, true, true, false, MergeCommit, "")
 	if result.Error == "" {
 		t.Error("expected error for uncommitted changes")
 	}
 }
 
-func TestHandleAgentFinished_WithChanges(t *testing.T) {
-	mg := &mockGit{hasChangesResult: true}
-	mt := &mockTmux{windowIDForPane: "@1"}
-	mm := &mockMonitor{}
-	o := newTestOrch(t, mg, mt, mm)
-
-	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
-	o.store.Add(a)
-
-	o.handleAgentFinished(a, 0)
-
-	if a.GetStatus() != agent.StatusReviewReady {
-		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusReviewReady)
-	}
-}
-
-func TestHandleAgentFinished_NoChanges(t *testing.T) {
-	mg := &mockGit{hasChangesResult: false}
-	mt := &mockTmux{windowIDForPane: "@1"}
-	mm := &mockMonitor{}
-	o := newTestOrch(t, mg, mt, mm)
-
-	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
-	o.store.Add(a)
-
-	o.handleAgentFinished(a, 0)
-
-	if a.GetStatus() != agent.StatusDone {
-		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusDone)
-	}
-}
-
 func TestHandleLazygitClosed_NewCommits(t *testing.T) {
 	mg := &mockGit{headCommitResult: "newcommit"}
 	mt := &mockTmux{}
@@ -628,6 +1596,28 @@ func TestHandleLazygitClosed_NoNewCommits(t *testing.T) {
 	}
 }
 
+func TestHandleLazygitClosed_ConflictsResolvedButBaseMoved(t *testing.T) {
+	mg := &mockGit{headCommitResult: "resolved", baseMoves: 10}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	a.SetStatus(agent.StatusConflicts)
+	a.SetMergeDeleteBranch(true)
+	o.store.Add(a)
+
+	o.handleLazygitClosed(a, agent.StatusConflicts)
+
+	if mg.hasCalled("DeleteBranch:feat/x") {
+		t.Error("the branch must be kept when base could not be fast-forwarded")
+	}
+	if _, ok := o.store.Get(a.ID); !ok {
+		t.Error("agent should be kept")
+	}
+	if a.GetStatus() != agent.StatusReviewed {
+		t.Errorf("status = %q, want %q", a.GetStatus(), agent.StatusReviewed)
+	}
+}
+
 func TestCleanupDeadAgents(t *testing.T) {
 	mg := &mockGit{}
 	mt := &mockTmux{paneExistsResult: false} // panes don't exist
@@ -691,6 +1681,69 @@ func TestRecoverAgents(t *testing.T) {
 	}
 }
 
+func TestRecoverAgents_ResumesDeadSession(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		sessionID string
+		status    agent.Status
+		resumed   bool
+	}{
+		{"working", "sess-1", agent.StatusRunning, true},
+		{"no session", "", agent.StatusRunning, false},
+		{"finished", "sess-1", agent.StatusReviewReady, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := &mockTmux{paneExistsResult: true}
+			mm := &mockMonitor{paneStatus: tmux.PaneStatus{Dead: true, ExitCode: 129}}
+			o := newTestOrch(t, &mockGit{}, mt, mm)
+
+			a := agent.NewAgent("feat/r", "main", t.TempDir(), "@1", "%1", "claude")
+			a.SetSessionID(tc.sessionID)
+			a.SetStatus(tc.status)
+			if err := agent.SaveState(o.statePath, []*agent.Agent{a}); err != nil {
+				t.Fatal(err)
+			}
+
+			o.RecoverAgents()
+
+			if got := mt.hasCalled("RespawnPaneWith:%1"); got != tc.resumed {
+				t.Fatalf("pane respawned = %v, want %v", got, tc.resumed)
+			}
+			if !tc.resumed {
+				return
+			}
+			if cmd := strings.Join(mt.respawnCommand, " "); !strings.Contains(cmd, "claude --resume sess-1") {
+				t.Errorf("command = %q, want the session resumed", cmd)
+			}
+			if got := o.store.All()[0].GetStatus(); got != agent.StatusRunning {
+				t.Errorf("status = %s, want running", got)
+			}
+		})
+	}
+}
+
+func TestSaveState_WritesPromptStatus(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	a := agent.NewAgent("feat/p", "main", "/wt", "@1", "%1", "claude")
+	a.SetStatus(agent.StatusReviewReady)
+	o.store.Add(a)
+
+	o.saveState()
+
+	st, err := prompt.Read(filepath.Join(o.worktreeDir, prompt.FileName))
+	if err != nil {
+		t.Fatalf("read prompt status: %v", err)
+	}
+	if st.Total != 1 || st.Attention != 1 {
+		t.Errorf("status = %+v, want 1 agent needing attention", st)
+	}
+
+	o.RemovePromptStatus()
+	if _, err := os.Stat(filepath.Join(o.worktreeDir, prompt.FileName)); !os.IsNotExist(err) {
+		t.Error("prompt status should be removed")
+	}
+}
+
 func TestDiscoverOrphanedAgents(t *testing.T) {
 	mg := &mockGit{hasChangesResult: true}
 	mt := &mockTmux{
@@ -784,7 +1837,7 @@ func TestDiscoverOrphanedAgents_DeadPane(t *testing.T) {
 	if err := os.MkdirAll(wtDir, 0o755); err != nil {
 		t.Fatal(err)
 	}
-	writeAgentMetadata(wtDir, "main", "", "claude")
+	writeAgentMetadata(wtDir, filepath.Base(wtDir), "main", "", "claude")
 
 	o.RecoverAgents()
 
@@ -798,6 +1851,29 @@ func TestDiscoverOrphanedAgents_DeadPane(t *testing.T) {
 	}
 }
 
+func TestDiscoverOrphanedAgents_KeepsBrokenWorktree(t *testing.T) {
+	mg := &mockGit{}
+	mt := &mockTmux{listWindowsResult: map[string]tmux.WindowInfo{}}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+
+	wtDir := filepath.Join(o.worktreeDir, "moved-agent")
+	if err := os.MkdirAll(wtDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeAgentMetadata(wtDir, filepath.Base(wtDir), "main", "sess-1", "claude")
+	mg.brokenWorktrees = map[string]bool{wtDir: true}
+
+	o.RecoverAgents()
+
+	agents := o.store.All()
+	if len(agents) != 1 {
+		t.Fatalf("expected broken worktree to be recovered, got %d agents", len(agents))
+	}
+	if agents[0].GetStatus() != agent.StatusOrphaned {
+		t.Errorf("status = %q, want %q", agents[0].GetStatus(), agent.StatusOrphaned)
+	}
+}
+
 func TestDiscoverOrphanedAgents_SkipsTracked(t *testing.T) {
 	mg := &mockGit{}
 	mt := &mockTmux{
@@ -838,7 +1914,7 @@ func TestDiscoverOrphanedAgents_SkipsTracked(t *testing.T) {
 
 func TestWriteAndReadAgentMetadata(t *testing.T) {
 	dir := t.TempDir()
-	writeAgentMetadata(dir, "feature-branch", "test-session-123", "claude")
+	writeAgentMetadata(dir, "feat/x", "feature-branch", "test-session-123", "claude")
 
 	meta := readAgentMetadata(dir)
 	if meta == nil {
@@ -850,6 +1926,67 @@ func TestWriteAndReadAgentMetadata(t *testing.T) {
 	if meta.SessionID != "test-session-123" {
 		t.Errorf("session id = %q, want %q", meta.SessionID, "test-session-123")
 	}
+	if meta.Branch != "feat/x" {
+		t.Errorf("branch = %q, want %q", meta.Branch, "feat/x")
+	}
+}
+
+func TestDiscoverOrphanedAgents_FlattenedDir(t *testing.T) {
+	mt := &mockTmux{
+		listWindowsResult:  map[string]tmux.WindowInfo{"feat/x": {ID: "@5", PaneID: "%10"}},
+		listAllPanesResult: map[string]tmux.PaneInfo{"%10": {WindowID: "@5"}},
+	}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	wtDir := filepath.Join(o.worktreeDir, "feat__x")
+	if err := os.MkdirAll(wtDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeAgentMetadata(wtDir, "feat/x", "main", "", "claude")
+
+	o.RecoverAgents()
+
+	agents := o.store.All()
+	if len(agents) != 1 || agents[0].Branch != "feat/x" || agents[0].WorktreePath != wtDir {
+		t.Fatalf("agents = %+v, want feat/x at %s", agents, wtDir)
+	}
+}
+
+func TestRecoverAgents_MigratesNestedWorktrees(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{paneExistsResult: true}, &mockMonitor{})
+
+	// An agent recovered from state keeps its nested worktree.
+	kept := filepath.Join(o.worktreeDir, "fix", "live")
+	live := agent.NewAgent("fix/live", "main", kept, "@1", "%1", "claude")
+	if err := agent.SaveState(o.statePath, []*agent.Agent{live}); err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := filepath.Join(o.worktreeDir, "feat", "x")
+	for _, dir := range []string{kept, legacy} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeAgentMetadata(legacy, "", "main", "sess-1", "claude")
+	mg.listWorktreesResult = []git.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: kept, Branch: "fix/live"},
+		{Path: legacy, Branch: "feat/x"},
+	}
+
+	o.RecoverAgents()
+
+	flat := filepath.Join(o.worktreeDir, "feat__x")
+	if !mg.hasCalled("MoveWorktree:" + legacy + "->" + flat) {
+		t.Errorf("expected %s to move to %s, calls = %v", legacy, flat, mg.calls)
+	}
+	if mg.hasCalled("MoveWorktree:" + kept + "->" + filepath.Join(o.worktreeDir, "fix__live")) {
+		t.Error("worktree of a recovered agent was moved")
+	}
+	if meta := readAgentMetadata(flat); meta == nil || meta.Branch != "feat/x" || meta.SessionID != "sess-1" {
+		t.Errorf("metadata after move = %+v, want branch recorded", meta)
+	}
 }
 
 func TestReadAgentMetadata_Missing(t *testing.T) {
@@ -931,7 +2068,7 @@ func TestTriggerAttention_AppendsStarToWindowName(t *testing.T) {
 	}
 
 	// Simulate agent finishing with changes
-	o.handleAgentFinished(agents[0], 0)
+	finishAgent(o, mt, agents[0])
 
 	// Verify window was renamed with " *"
 	if !mt.hasCalled("RenameWindow:@0:mastermind *") {
@@ -956,7 +2093,7 @@ func TestTriggerAttention_NotifiesMacOS(t *testing.T) {
 	}
 	agents := o.store.All()
 
-	o.handleAgentFinished(agents[0], 0)
+	finishAgent(o, mt, agents[0])
 
 	if mn.callCount() != 1 {
 		t.Fatalf("expected 1 notification, got %d", mn.callCount())
@@ -979,8 +2116,8 @@ func TestTriggerAttention_DoesNotDoubleAppendStar(t *testing.T) {
 	agents := o.store.All()
 
 	// Trigger twice
-	o.handleAgentFinished(agents[0], 0)
-	o.handleAgentFinished(agents[0], 0)
+	finishAgent(o, mt, agents[0])
+	finishAgent(o, mt, agents[0])
 
 	// Count RenameWindow calls — should only be 1 (not 2)
 	mt.mu.Lock()
@@ -1010,7 +2147,7 @@ func TestClearAttentionIndicator_RemovesStar(t *testing.T) {
 	agents := o.store.All()
 
 	// Trigger attention
-	o.handleAgentFinished(agents[0], 0)
+	finishAgent(o, mt, agents[0])
 	if !o.attentionActive {
 		t.Fatal("expected attentionActive to be true after trigger")
 	}
@@ -1070,7 +2207,7 @@ func TestTriggerAttention_AgentFinishedNoChanges(t *testing.T) {
 	}
 	agents := o.store.All()
 
-	o.handleAgentFinished(agents[0], 0)
+	finishAgent(o, mt, agents[0])
 
 	// Should still trigger attention (done state)
 	if !o.attentionActive {
@@ -1136,7 +2273,7 @@ func TestTriggerAttention_NoWindowID(t *testing.T) {
 	}
 	agents := o.store.All()
 
-	o.handleAgentFinished(agents[0], 0)
+	finishAgent(o, mt, agents[0])
 
 	// Notification should still fire
 	if mn.callCount() != 1 {
@@ -1173,7 +2310,7 @@ func TestTriggerAttention_AgentIdleWithChanges(t *testing.T) {
 	a.SetEverActive(true)
 
 	// Simulate agent going idle with changes
-	o.handleAgentIdle(a)
+	idleAgent(o, mt, mm, a)
 
 	if a.GetStatus() != agent.StatusReviewReady {
 		t.Errorf("expected status review_ready, got %s", a.GetStatus())
@@ -1200,7 +2337,7 @@ func TestTriggerAttention_AgentIdleNoChanges(t *testing.T) {
 	a := agents[0]
 	a.SetEverActive(true)
 
-	o.handleAgentIdle(a)
+	idleAgent(o, mt, mm, a)
 
 	if a.GetStatus() != agent.StatusDone {
 		t.Errorf("expected status done, got %s", a.GetStatus())
@@ -1238,7 +2375,7 @@ func TestClearAttentionIndicator_CycleAttentionOnOff(t *testing.T) {
 	agents := o.store.All()
 
 	// Trigger → clear → trigger again → should rename again
-	o.handleAgentFinished(agents[0], 0)
+	finishAgent(o, mt, agents[0])
 	if !o.attentionActive {
 		t.Fatal("expected attentionActive after first trigger")
 	}
@@ -1250,10 +2387,9 @@ func TestClearAttentionIndicator_CycleAttentionOnOff(t *testing.T) {
 	}
 
 	// Trigger again — should re-append " *"
-	// Reset status so handleAgentFinished fires the status transition again
+	// Reset status so the monitor fires the status transition again
 	agents[0].SetStatus(agent.StatusRunning)
-	delete(o.idleHasChanges, agents[0].ID)
-	o.handleAgentFinished(agents[0], 0)
+	finishAgent(o, mt, agents[0])
 	if !o.attentionActive {
 		t.Error("expected attentionActive after second trigger")
 	}
@@ -1273,5 +2409,70 @@ func TestClearAttentionIndicator_CycleAttentionOnOff(t *testing.T) {
 	}
 }
 
+func TestTriggerAttention_RingsBellWhenEnabled(t *testing.T) {
+	mt := &mockTmux{}
+	o := newTestOrchWithNotifier(t, &mockGit{}, mt, &mockMonitor{}, &mockNotifier{})
+
+	o.triggerAttention("a1", "Agent a1 needs permission")
+	if mt.hasCalled("RingBell:@0") {
+		t.Error("bell should be off by default")
+	}
+
+	o.tmuxBell = true
+	o.triggerAttention("a1", "Agent a1 needs permission")
+	if !mt.hasCalled("RingBell:@0") {
+		t.Error("expected RingBell on the overview window")
+	}
+}
+
+func TestDiscoverOrphanedAgents_MarkedWindow(t *testing.T) {
+	branch := "marked-agent"
+	mt := &mockTmux{
+		listWindowsResult: map[string]tmux.WindowInfo{
+			tmux.AttentionPrefix + branch: {ID: "@7", PaneID: "%7"},
+		},
+		listAllPanesResult:      map[string]tmux.PaneInfo{"%7": {WindowID: "@7"}},
+		currentWindowNameResult: tmux.AttentionPrefix + branch,
+	}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	wtDir := filepath.Join(o.worktreeDir, branch)
+	if err := os.MkdirAll(wtDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeAgentMetadata(wtDir, filepath.Base(wtDir), "main", "", "claude")
+
+	o.RecoverAgents()
+
+	if len(o.store.All()) != 1 {
+		t.Fatalf("expected the marked window to be discovered, got %d agents", len(o.store.All()))
+	}
+	if !mt.hasCalled("RenameWindow:@7:" + branch) {
+		t.Error("expected the stale attention prefix to be removed")
+	}
+}
+
 // Ensure the time import is used (test timestamp formatting uses time.Now)
 var _ = time.Now
+
+func TestSpawnAgent_MaxRunning(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	WithMaxRunning(1)(o)
+
+	if err := o.SpawnAgent("feat/a", "main", true, "claude"); err != nil {
+		t.Fatalf("first spawn: %v", err)
+	}
+	err := o.SpawnAgent("feat/b", "main", true, "claude")
+	if !errors.Is(err, ErrMaxRunning) || o.HasCapacity() {
+		t.Fatalf("second spawn = %v, want ErrMaxRunning", err)
+	}
+	if mg.hasCalled("CreateBranch:feat/b") {
+		t.Error("a refused spawn must not create its branch")
+	}
+
+	// A finished agent frees its slot.
+	o.store.All()[0].SetStatus(agent.StatusReviewReady)
+	if err := o.SpawnAgent("feat/b", "main", true, "claude"); err != nil {
+		t.Errorf("spawn after the first finished: %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+package orchestrator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// Process is a running process, as listed by ProcessOps.InDir.
+type Process = monitor.Process
+
+// InDir lists the processes whose working directory is dir or below it,
+// other than mastermind itself. It reads /proc on Linux and asks lsof
+// elsewhere.
+func (RealProcesses) InDir(dir string) ([]Process, error) {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	var procs []procCwd
+	var err error
+	if runtime.GOOS == "linux" {
+		procs, err = procCwds()
+	} else {
+		procs, err = lsofCwds()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var in []Process
+	for _, p := range procs {
+		if p.PID == os.Getpid() || (p.cwd != dir && !strings.HasPrefix(p.cwd, dir+string(filepath.Separator))) {
+			continue
+		}
+		in = append(in, p.Process)
+	}
+	return in, nil
+}
+
+func (RealProcesses) SignalPID(pid int, sig syscall.Signal) error {
+	if err := syscall.Kill(pid, sig); err != nil && err != syscall.ESRCH {
+		return fmt.Errorf("signal process %d: %w", pid, err)
+	}
+	return nil
+}
+
+// procCwd is a process with its working directory.
+type procCwd struct {
+	Process
+	cwd string
+}
+
+// procCwds lists the processes whose cwd /proc shows.
+func procCwds() ([]procCwd, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+	var procs []procCwd
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		cwd, err := os.Readlink(filepath.Join("/proc", e.Name(), "cwd"))
+		if err != nil {
+			continue // gone, or another user's
+		}
+		comm, _ := os.ReadFile(filepath.Join("/proc", e.Name(), "comm"))
+		procs = append(procs, procCwd{Process{PID: pid, Command: strings.TrimSpace(string(comm))}, cwd})
+	}
+	return procs, nil
+}
+
+// lsofCwds lists the processes whose cwd lsof shows.
+func lsofCwds() ([]procCwd, error) {
+	out, err := exec.Command("lsof", "-n", "-d", "cwd", "-F", "pcn").Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("lsof: %w", err)
+	}
+	var procs []procCwd
+	var cur Process
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			cur = Process{}
+			cur.PID, _ = strconv.Atoi(line[1:])
+		case 'c':
+			cur.Command = line[1:]
+		case 'n':
+			procs = append(procs, procCwd{cur, line[1:]})
+		}
+	}
+	return procs, nil
+}
+
+// OrphansKilledMsg reports the outcome of KillOrphans.
+type OrphansKilledMsg struct {
+	AgentID string
+	Branch  string
+	Error   string
+}
+
+// waitOrphans lists the processes still running in a's worktree, giving
+// them the stop timeout to exit after the window was killed.
+func (o *Orchestrator) waitOrphans(a *agent.Agent) []Process {
+	deadline := time.Now().Add(o.stopTimeout)
+	for {
+		procs, err := o.procs.InDir(a.WorktreePath)
+		if err != nil {
+			slog.Warn("failed to list processes in worktree", "id", a.ID, "path", a.WorktreePath, "error", err)
+			return nil
+		}
+		if len(procs) == 0 || time.Now().After(deadline) {
+			return procs
+		}
+		time.Sleep(stopPoll)
+	}
+}
+
+// holdForOrphans reports whether processes outlived a's window in its
+// worktree. If so the worktree is left in place, as removing it would fail
+// or pull files from under them, and recorded in
+// .worktrees/mastermind-orphans.json. The OrphanProcessesMsg event asks
+// the TUI, or the daemon's clients, whether to kill them; HeldWorktrees
+// offers them again later, e.g. to `mastermind cleanup`.
+func (o *Orchestrator) holdForOrphans(a *agent.Agent, deleteBranch bool) bool {
+	procs := o.waitOrphans(a)
+	if len(procs) == 0 {
+		return false
+	}
+	slog.Warn("processes still running in worktree, keeping it", "id", a.ID, "path", a.WorktreePath, "processes", procs)
+	held := OrphanProcessesMsg{
+		AgentID:      a.ID,
+		Branch:       a.Branch,
+		WorktreePath: a.WorktreePath,
+		DeleteBranch: deleteBranch && !a.ReadOnly,
+		Processes:    procs,
+	}
+	o.updateHeldWorktrees(func(hs []OrphanProcessesMsg) []OrphanProcessesMsg {
+		return append(hs, held)
+	})
+	o.handleMonitorEvent(held)
+	return true
+}
+
+func (o *Orchestrator) heldWorktreesPath() string {
+	return filepath.Join(o.worktreeDir, "mastermind-orphans.json")
+}
+
+// loadHeldWorktrees reads the worktrees kept for processes running in
+// them.
+func (o *Orchestrator) loadHeldWorktrees() ([]OrphanProcessesMsg, error) {
+	data, err := os.ReadFile(o.heldWorktreesPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var held []OrphanProcessesMsg
+	if err := json.Unmarshal(data, &held); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", o.heldWorktreesPath(), err)
+	}
+	return held, nil
+}
+
+// updateHeldWorktrees rewrites the held worktrees with what update makes
+// of them, removing the file once none are left.
+func (o *Orchestrator) updateHeldWorktrees(update func([]OrphanProcessesMsg) []OrphanProcessesMsg) {
+	o.heldMu.Lock()
+	defer o.heldMu.Unlock()
+	held, err := o.loadHeldWorktrees()
+	if err != nil {
+		slog.Warn("ignoring unreadable held worktrees", "error", err)
+	}
+	held = update(held)
+	if len(held) == 0 {
+		if err := os.Remove(o.heldWorktreesPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Error("failed to remove held worktrees", "error", err)
+		}
+		return
+	}
+	data, err := json.MarshalIndent(held, "", "  ")
+	if err != nil {
+		slog.Error("failed to marshal held worktrees", "error", err)
+		return
+	}
+	if err := os.WriteFile(o.heldWorktreesPath(), data, 0o644); err != nil {
+		slog.Error("failed to save held worktrees", "error", err)
+	}
+}
+
+// HeldWorktrees returns the worktrees of dismissed agents that are still
+// kept for processes running in them, with those processes. Worktrees
+// whose processes have exited since are removed now, with their branch if
+// it was to be deleted, and not returned.
+func (o *Orchestrator) HeldWorktrees() []OrphanProcessesMsg {
+	var still []OrphanProcessesMsg
+	o.updateHeldWorktrees(func(held []OrphanProcessesMsg) []OrphanProcessesMsg {
+		still = nil
+		for _, h := range held {
+			procs, err := o.procs.InDir(h.WorktreePath)
+			if err != nil {
+				slog.Warn("failed to list processes in worktree", "id", h.AgentID, "path", h.WorktreePath, "error", err)
+				still = append(still, h)
+				continue
+			}
+			if len(procs) > 0 {
+				h.Processes = procs
+				still = append(still, h)
+				continue
+			}
+			if err := o.finishHeldRemoval(h); err != nil {
+				slog.Warn("failed to remove held worktree", "id", h.AgentID, "path", h.WorktreePath, "error", err)
+				still = append(still, h)
+			}
+		}
+		return still
+	})
+	return still
+}
+
+// finishHeldRemoval removes a held worktree once nothing runs in it, and
+// the branch if it was to be deleted.
+func (o *Orchestrator) finishHeldRemoval(h OrphanProcessesMsg) error {
+	if err := o.removeWorktree(h.Branch, h.WorktreePath); err != nil {
+		return err
+	}
+	if h.DeleteBranch && h.Branch != "" {
+		return o.removeBranch(h.Branch)
+	}
+	return nil
+}
+
+// KillOrphans kills the processes left in a dismissed agent's worktree,
+// with SIGTERM and then SIGKILL for any still running after the stop
+// timeout, and removes the worktree, and the branch if asked to.
+func (o *Orchestrator) KillOrphans(msg OrphanProcessesMsg) OrphansKilledMsg {
+	result := OrphansKilledMsg{AgentID: msg.AgentID, Branch: msg.Branch}
+	for _, sig := range []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL} {
+		procs, err := o.procs.InDir(msg.WorktreePath)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if len(procs) == 0 {
+			break
+		}
+		for _, p := range procs {
+			if err := o.procs.SignalPID(p.PID, sig); err != nil {
+				slog.Warn("failed to signal orphaned process", "pid", p.PID, "command", p.Command, "signal", signalName(sig), "error", err)
+			}
+		}
+		deadline := time.Now().Add(o.stopTimeout)
+		for time.Now().Before(deadline) {
+			if procs, err := o.procs.InDir(msg.WorktreePath); err == nil && len(procs) == 0 {
+				break
+			}
+			time.Sleep(stopPoll)
+		}
+	}
+	if procs, err := o.procs.InDir(msg.WorktreePath); err == nil && len(procs) > 0 {
+		result.Error = fmt.Sprintf("%d processes still running after SIGKILL", len(procs))
+		return result
+	}
+
+	if err := o.finishHeldRemoval(msg); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	o.updateHeldWorktrees(func(held []OrphanProcessesMsg) []OrphanProcessesMsg {
+		return slices.DeleteFunc(held, func(h OrphanProcessesMsg) bool { return h.WorktreePath == msg.WorktreePath })
+	})
+	slog.Info("orphaned processes killed and worktree removed", "id", msg.AgentID, "path", msg.WorktreePath)
+	return result
+}
@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"context"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func newOrphanOrch(t *testing.T, procs *mockProcs) (*Orchestrator, *mockGit, *agent.Agent) {
+	t.Helper()
+	mg := &mockGit{}
+	o := New(context.Background(), agent.NewStore(), "/repo", "test-session", t.TempDir(),
+		WithGit(mg),
+		WithTmux(&mockTmux{}),
+		WithMonitor(&mockMonitor{}),
+		WithProcesses(procs),
+		WithStopTimeout(20*time.Millisecond),
+	)
+	a := agent.NewAgent("feat/leaky", "main", "/wt", "@1", "%1", "claude")
+	a.SetStatus(agent.StatusDone)
+	o.store.Add(a)
+	return o, mg, a
+}
+
+func TestDismiss_KeepsWorktreeWithOrphans(t *testing.T) {
+	procs := &mockProcs{orphans: []Process{{PID: 300, Command: "node"}}}
+	o, mg, a := newOrphanOrch(t, procs)
+	events := o.Events()
+
+	if err := o.dismissAgent(a.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if mg.hasCalled("RemoveWorktree:/wt") || mg.hasCalled("DeleteBranch:feat/leaky") {
+		t.Errorf("calls = %v, want the worktree and branch kept while processes run in it", mg.calls)
+	}
+	if _, ok := o.store.Get(a.ID); ok {
+		t.Error("agent still tracked after dismiss")
+	}
+	// The event reaches the daemon's clients as well as the TUI.
+	if ev, ok := (<-events).(OrphanProcessesMsg); !ok || ev.WorktreePath != "/wt" || len(ev.Processes) != 1 {
+		t.Errorf("event = %#v, want the orphaned processes reported", ev)
+	}
+}
+
+func TestHeldWorktrees_OfferedUntilProcessesExit(t *testing.T) {
+	procs := &mockProcs{orphans: []Process{{PID: 300, Command: "node"}}}
+	o, mg, a := newOrphanOrch(t, procs)
+	if err := o.dismissAgent(a.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	held := o.HeldWorktrees()
+	if len(held) != 1 || held[0].Branch != "feat/leaky" || !held[0].DeleteBranch {
+		t.Fatalf("held = %+v, want the dismissed agent's worktree", held)
+	}
+
+	procs.mu.Lock()
+	procs.orphans = nil
+	procs.mu.Unlock()
+	if held := o.HeldWorktrees(); len(held) != 0 {
+		t.Errorf("held = %+v, want none once the processes exited", held)
+	}
+	if !mg.hasCalled("RemoveWorktree:/wt") || !mg.hasCalled("DeleteBranch:feat/leaky") {
+		t.Errorf("calls = %v, want the worktree and branch removed", mg.calls)
+	}
+}
+
+func TestDismiss_RemovesWorktreeWithoutOrphans(t *testing.T) {
+	o, mg, a := newOrphanOrch(t, &mockProcs{})
+
+	if err := o.dismissAgent(a.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if !mg.hasCalled("RemoveWorktree:/wt") || !mg.hasCalled("DeleteBranch:feat/leaky") {
+		t.Errorf("calls = %v, want the worktree and branch removed", mg.calls)
+	}
+}
+
+func TestKillOrphans_TerminatesThenRemoves(t *testing.T) {
+	procs := &mockProcs{
+		exitOn:  []syscall.Signal{syscall.SIGKILL},
+		orphans: []Process{{PID: 300, Command: "node"}, {PID: 301, Command: "claude"}},
+	}
+	o, mg, a := newOrphanOrch(t, procs)
+	if err := o.dismissAgent(a.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	res := o.KillOrphans(OrphanProcessesMsg{AgentID: "1", Branch: "feat/leaky", WorktreePath: "/wt", DeleteBranch: true})
+	if res.Error != "" {
+		t.Fatalf("KillOrphans error = %q", res.Error)
+	}
+	// Both got SIGTERM, which they ignored, then SIGKILL.
+	if want := []int{300, 301, 300, 301}; !slices.Equal(procs.killed, want) {
+		t.Errorf("signalled %v, want %v", procs.killed, want)
+	}
+	if !mg.hasCalled("RemoveWorktree:/wt") || !mg.hasCalled("DeleteBranch:feat/leaky") {
+		t.Errorf("calls = %v, want the worktree and branch removed", mg.calls)
+	}
+	if held := o.HeldWorktrees(); len(held) != 0 {
+		t.Errorf("held = %+v, want the worktree forgotten", held)
+	}
+}
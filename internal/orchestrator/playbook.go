@@ -0,0 +1,132 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/harness"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+	"github.com/simonbystrom/mastermind/internal/playbook"
+)
+
+// checkOutputLimit is how much of a failed check's output, from the end,
+// is kept in its event.
+const checkOutputLimit = 4000
+
+// WithPlaybooks sets the directory playbooks are listed from, relative to
+// the repository unless absolute. Empty keeps .mastermind/playbooks.
+func WithPlaybooks(dir string) Option {
+	return func(o *Orchestrator) {
+		if dir == "" {
+			return
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(o.repoPath, dir)
+		}
+		o.playbooksDir = dir
+	}
+}
+
+// FromPlaybook spawns the agent with the playbook's prompt. When the agent
+// finishes, the playbook's checks run in its worktree and, if its policy
+// allows, it is merged.
+func FromPlaybook(p playbook.Playbook) SpawnOption {
+	return func(r *spawnRequest) {
+		r.prompt = p.Prompt
+		r.playbook = &p
+	}
+}
+
+// Playbooks returns the playbooks in the playbooks directory. Playbooks
+// that fail to parse are left out and reported in the error.
+func (o *Orchestrator) Playbooks() ([]playbook.Playbook, error) {
+	return playbook.List(o.playbooksDir)
+}
+
+// PlaybooksDir returns the directory playbooks are listed from.
+func (o *Orchestrator) PlaybooksDir() string {
+	return o.playbooksDir
+}
+
+// RunPlaybook spawns an agent on a new branch running p. The playbook's
+// base is used, or the repository's current branch when it has none.
+func (o *Orchestrator) RunPlaybook(p playbook.Playbook, branch string, harnessType harness.Type, opts ...SpawnOption) error {
+	base := p.Base
+	if base == "" {
+		var err error
+		if base, err = o.git.CurrentBranch(o.repoPath); err != nil {
+			return fmt.Errorf("current branch: %w", err)
+		}
+	}
+	return o.SpawnAgent(branch, base, true, harnessType, append(opts, FromPlaybook(p))...)
+}
+
+// finishPlaybook runs the checks of a finished playbook agent and merges
+// it when they pass and the playbook's policy says so. Checks can take a
+// while, so it runs off the monitor goroutine; an agent finishing again
+// while its checks run is ignored.
+func (o *Orchestrator) finishPlaybook(a *agent.Agent) {
+	if _, running := o.playbookRuns.LoadOrStore(a.ID, true); running {
+		return
+	}
+	defer o.playbookRuns.Delete(a.ID)
+
+	ev := monitor.PlaybookFinished{AgentID: a.ID, Branch: a.Branch}
+	p, err := playbook.Load(a.Playbook)
+	if err != nil {
+		ev.Error = fmt.Sprintf("load playbook: %v", err)
+		o.handleMonitorEvent(ev)
+		return
+	}
+
+	ev.Checks = len(p.Checks)
+	results := playbook.RunChecks(o.ctx, a.WorktreePath, p.Checks)
+	if failed, ok := playbook.Failed(results); ok {
+		ev.Failed = failed.Command
+		ev.Output = failed.Output
+		if len(ev.Output) > checkOutputLimit {
+			ev.Output = ev.Output[len(ev.Output)-checkOutputLimit:]
+		}
+		slog.Info("playbook check failed", "id", a.ID, "playbook", p.Name, "check", failed.Command, "error", failed.Err)
+		o.handleMonitorEvent(ev)
+		return
+	}
+	slog.Info("playbook checks passed", "id", a.ID, "playbook", p.Name, "checks", len(p.Checks))
+
+	if p.AutoMerge == playbook.MergeOnChecks {
+		d := o.MergeDefaults()
+		res := o.mergeAgent(a.ID, d.DeleteBranch, d.RemoveWorktree, d.Push, d.Strategy, "")
+		switch {
+		case res.Success:
+			ev.Merged = true
+		case res.Conflict:
+			ev.Error = fmt.Sprintf("merge conflicts in %d file(s), resolve them with m", len(res.ConflictFiles))
+		default:
+			ev.Error = res.Error
+		}
+	}
+	o.handleMonitorEvent(ev)
+}
+
+// playbookPath returns the file of p, or "" without a playbook.
+func playbookPath(p *playbook.Playbook) string {
+	if p == nil {
+		return ""
+	}
+	return p.Path
+}
+
+// playbookMessage describes a PlaybookFinished event for notifications.
+func playbookMessage(ev monitor.PlaybookFinished) string {
+	switch {
+	case ev.Failed != "":
+		return fmt.Sprintf("Agent %s: check %q failed", ev.AgentID, ev.Failed)
+	case ev.Error != "":
+		return fmt.Sprintf("Agent %s: playbook: %s", ev.AgentID, ev.Error)
+	case ev.Merged:
+		return fmt.Sprintf("Agent %s: %d check(s) passed, merged %s", ev.AgentID, ev.Checks, ev.Branch)
+	}
+	return fmt.Sprintf("Agent %s: %d check(s) passed", ev.AgentID, ev.Checks)
+}
@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/monitor"
+	"github.com/simonbystrom/mastermind/internal/playbook"
+)
+
+func TestRunPlaybook_ChecksThenMerges(t *testing.T) {
+	mg := &mockGit{}
+	mt := &mockTmux{windowIDForPane: "@1"}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+
+	dir := t.TempDir()
+	WithPlaybooks(dir)(o)
+	if err := os.WriteFile(filepath.Join(dir, "deps.yml"), []byte("prompt: Upgrade deps.\nchecks: [test -f ok]\nauto_merge: checks\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	playbooks, err := o.Playbooks()
+	if err != nil || len(playbooks) != 1 {
+		t.Fatalf("Playbooks = %v, %v", playbooks, err)
+	}
+
+	if err := o.RunPlaybook(playbooks[0], "playbook/deps", "claude"); err != nil {
+		t.Fatalf("RunPlaybook: %v", err)
+	}
+	a := o.store.All()[0]
+	if a.GetBaseBranch() != "main" || a.Prompt != "Upgrade deps." || a.Playbook != filepath.Join(dir, "deps.yml") {
+		t.Errorf("agent base %q, prompt %q, playbook %q", a.GetBaseBranch(), a.Prompt, a.Playbook)
+	}
+	if cmd := strings.Join(mt.newWindowCommand, " "); !strings.Contains(cmd, "test -f ok") {
+		t.Errorf("command = %q, want the checks in the agent's context", cmd)
+	}
+	if err := os.MkdirAll(a.WorktreePath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	events := o.Events()
+	o.finishPlaybook(a)
+	ev := (<-events).(monitor.PlaybookFinished)
+	if ev.Failed != "test -f ok" || ev.Merged {
+		t.Errorf("event = %+v, want the failing check and no merge", ev)
+	}
+	if mg.hasCalled("MergeInWorktree:main") {
+		t.Error("an agent with failing checks must not be merged")
+	}
+
+	if err := os.WriteFile(filepath.Join(a.WorktreePath, "ok"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o.finishPlaybook(a)
+	ev = (<-events).(monitor.PlaybookFinished)
+	if !ev.Merged || ev.Checks != 1 || ev.Error != "" {
+		t.Errorf("event = %+v, want merged after passing checks", ev)
+	}
+	if _, ok := o.store.Get(a.ID); ok {
+		t.Error("merged agent should be cleaned up")
+	}
+}
+
+func TestFinishPlaybook_ReadOnlyNotMerged(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	path := filepath.Join(t.TempDir(), "audit.md")
+	if err := os.WriteFile(path, []byte("---\nauto_merge: checks\n---\nAudit the code.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := playbook.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.SpawnAgent("research/audit", "main", true, "claude", FromPlaybook(p), ReadOnly()); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+
+	events := o.Events()
+	o.finishPlaybook(a)
+	ev := (<-events).(monitor.PlaybookFinished)
+	if ev.Merged || !strings.Contains(ev.Error, "read-only") {
+		t.Errorf("event = %+v, want a read-only agent left unmerged", ev)
+	}
+}
@@ -0,0 +1,264 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// checkPreviewStale marks the preview stale, once, when the previewed
+// agent's branch moves on from the commit merged into it, so the
+// dashboard can offer to refresh it.
+func (o *Orchestrator) checkPreviewStale() {
+	o.previewMu.RLock()
+	id, head, stale := o.previewAgentID, o.previewHead, o.previewStale
+	o.previewMu.RUnlock()
+	if head == "" || stale {
+		return
+	}
+	a, ok := o.store.Get(id)
+	if !ok {
+		return
+	}
+	current, err := o.git.HeadCommit(o.repoPath, a.Branch)
+	if err != nil || current == head {
+		return
+	}
+
+	o.previewMu.Lock()
+	if o.previewAgentID != id || o.previewHead != head {
+		o.previewMu.Unlock()
+		return
+	}
+	o.previewStale = true
+	o.previewMu.Unlock()
+
+	slog.Info("preview stale", "agent", id, "branch", a.Branch, "previewed", head, "head", current)
+	if o.program != nil {
+		o.program.Send(PreviewStaleMsg{AgentID: id})
+	}
+}
+
+// PreviewStale reports whether the previewed agent's branch has moved on
+// since it was merged into the preview.
+func (o *Orchestrator) PreviewStale() bool {
+	o.previewMu.RLock()
+	defer o.previewMu.RUnlock()
+	return o.previewStale
+}
+
+// RefreshPreview merges the previewed agent's branch into the preview
+// again, with its current uncommitted changes. The changes copied into
+// the main worktree before are backed up and discarded first; it refuses
+// to refresh when files were changed there during the preview.
+func (o *Orchestrator) RefreshPreview() error {
+	o.previewMu.RLock()
+	id, head, snapshot := o.previewAgentID, o.previewHead, o.previewSnapshot
+	o.previewMu.RUnlock()
+	a, ok := o.store.Get(id)
+	if id == "" || !ok {
+		return fmt.Errorf("no preview is active")
+	}
+
+	if o.git.HasChanges(o.repoPath) {
+		changed, err := o.externalPreviewChanges(snapshot)
+		if err != nil {
+			return fmt.Errorf("check the main worktree for changes made during the preview: %w", err)
+		}
+		if len(changed) > 0 {
+			return fmt.Errorf("%s changed in the main worktree during the preview — commit or stash them before refreshing", strings.Join(changed, ", "))
+		}
+		o.backupPreview(id)
+		if err := o.git.DiscardChanges(o.repoPath); err != nil {
+			return fmt.Errorf("discard previewed changes: %w", err)
+		}
+	}
+
+	conflicted, err := o.git.MergeInWorktree(o.repoPath, a.Branch, false)
+	if err != nil {
+		return fmt.Errorf("merge agent branch: %w", err)
+	}
+	if conflicted {
+		o.git.MergeAbort(o.repoPath)
+		return fmt.Errorf("merge conflicts between the preview and %s — stop the preview and start it again", a.Branch)
+	}
+	if o.git.HasChanges(a.WorktreePath) {
+		if err := o.git.CopyUncommittedChanges(a.WorktreePath, o.repoPath); err != nil {
+			slog.Warn("failed to copy uncommitted changes to preview", "agent", id, "error", err)
+		}
+	}
+
+	if current, err := o.git.HeadCommit(o.repoPath, a.Branch); err == nil {
+		head = current
+	}
+	snapshot = o.snapshotPreview()
+	o.previewMu.Lock()
+	if o.previewAgentID == id {
+		o.previewHead = head
+		o.previewStale = false
+		o.previewSnapshot = snapshot
+	}
+	o.previewMu.Unlock()
+	o.savePreviewState()
+
+	slog.Info("preview refreshed", "agent", id, "head", head)
+	if o.program != nil {
+		o.program.Send(PreviewRefreshedMsg{AgentID: id})
+	}
+	return nil
+}
+
+// snapshotPreview records the changes a preview made in the main
+// worktree, so those made there by anyone else during the preview can be
+// told apart from them. It returns nil when they cannot be read.
+func (o *Orchestrator) snapshotPreview() map[string]string {
+	snapshot, err := o.git.ChangeSnapshot(o.repoPath)
+	if err != nil {
+		slog.Warn("failed to snapshot preview changes", "error", err)
+		return nil
+	}
+	return snapshot
+}
+
+// externalPreviewChanges lists the files in the main worktree changed
+// since snapshot was taken, sorted. Without a snapshot every changed file
+// counts.
+func (o *Orchestrator) externalPreviewChanges(snapshot map[string]string) ([]string, error) {
+	current, err := o.git.ChangeSnapshot(o.repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for path, hash := range current {
+		if before, ok := snapshot[path]; !ok || before != hash {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// clearPreviewChanges empties the main worktree of the changes the
+// preview of agent id made, so the previous branch can be checked out
+// again. Files changed there during the preview, e.g. by an editor, are
+// stashed rather than discarded; it returns the stash and the files.
+func (o *Orchestrator) clearPreviewChanges(id string) (string, []string, error) {
+	if !o.git.HasChanges(o.repoPath) {
+		return "", nil, nil
+	}
+	o.previewMu.RLock()
+	snapshot := o.previewSnapshot
+	o.previewMu.RUnlock()
+
+	changed, err := o.externalPreviewChanges(snapshot)
+	if err != nil {
+		return "", nil, fmt.Errorf("check the main worktree for changes made during the preview: %w", err)
+	}
+	var stash string
+	if len(changed) > 0 {
+		stash, err = o.git.StashChanges(o.repoPath, fmt.Sprintf("mastermind: changes made during the preview of %s", id), changed...)
+		if err != nil {
+			return "", nil, fmt.Errorf("stash changes made during the preview: %w", err)
+		}
+		slog.Info("stashed changes made during preview", "agent", id, "stash", stash, "files", changed)
+	}
+
+	if o.git.HasChanges(o.repoPath) {
+		o.backupPreview(id)
+		exec.Command("git", "-C", o.repoPath, "checkout", ".").Run()
+	}
+	return stash, changed, nil
+}
+
+// previewBranch returns the branch a preview of agent id checks out.
+func previewBranch(id string) string {
+	return "preview/" + id
+}
+
+func (o *Orchestrator) previewBranchesPath() string {
+	return filepath.Join(o.worktreeDir, "mastermind-preview-branches.json")
+}
+
+// createPreviewBranch creates the preview branch of agent id at base and
+// records it, so that maintenance deletes it if a preview that never
+// stopped cleanly leaves it behind, and never touches other preview/*
+// branches.
+func (o *Orchestrator) createPreviewBranch(id, base string) error {
+	if err := o.git.CreateBranch(o.repoPath, previewBranch(id), base); err != nil {
+		return err
+	}
+	o.updatePreviewBranches(func(bs []string) []string {
+		if slices.Contains(bs, previewBranch(id)) {
+			return bs
+		}
+		return append(bs, previewBranch(id))
+	})
+	return nil
+}
+
+// deletePreviewBranch deletes a preview branch created by
+// createPreviewBranch and forgets it.
+func (o *Orchestrator) deletePreviewBranch(branch string) error {
+	if err := o.git.DeleteBranch(o.repoPath, branch); err != nil {
+		return err
+	}
+	o.forgetPreviewBranch(branch)
+	return nil
+}
+
+func (o *Orchestrator) forgetPreviewBranch(branch string) {
+	o.updatePreviewBranches(func(bs []string) []string {
+		return slices.DeleteFunc(bs, func(b string) bool { return b == branch })
+	})
+}
+
+// loadPreviewBranches returns the preview branches mastermind created and
+// has not deleted.
+func (o *Orchestrator) loadPreviewBranches() []string {
+	o.previewBranchesMu.Lock()
+	defer o.previewBranchesMu.Unlock()
+	return o.readPreviewBranches()
+}
+
+func (o *Orchestrator) readPreviewBranches() []string {
+	data, err := os.ReadFile(o.previewBranchesPath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to read preview branches", "error", err)
+		}
+		return nil
+	}
+	var branches []string
+	if err := json.Unmarshal(data, &branches); err != nil {
+		slog.Warn("ignoring unreadable preview branches", "path", o.previewBranchesPath(), "error", err)
+		return nil
+	}
+	return branches
+}
+
+func (o *Orchestrator) updatePreviewBranches(update func([]string) []string) {
+	o.previewBranchesMu.Lock()
+	defer o.previewBranchesMu.Unlock()
+	branches := update(o.readPreviewBranches())
+	if len(branches) == 0 {
+		if err := os.Remove(o.previewBranchesPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Error("failed to remove preview branches", "error", err)
+		}
+		return
+	}
+	data, err := json.MarshalIndent(branches, "", "  ")
+	if err != nil {
+		slog.Error("failed to marshal preview branches", "error", err)
+		return
+	}
+	if err := os.WriteFile(o.previewBranchesPath(), data, 0o644); err != nil {
+		slog.Error("failed to save preview branches", "error", err)
+	}
+}
@@ -0,0 +1,216 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func TestPreviewStale_Refresh(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123"}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	a.SetStatus(agent.StatusReviewReady)
+
+	if err := o.PreviewAgent(a.ID); err != nil {
+		t.Fatalf("PreviewAgent: %v", err)
+	}
+	o.checkPreviewStale()
+	if o.PreviewStale() {
+		t.Fatal("preview should not be stale while the branch stays put")
+	}
+
+	mg.mu.Lock()
+	mg.headCommitResult = "def456"
+	mg.mu.Unlock()
+	o.checkPreviewStale()
+	if !o.PreviewStale() {
+		t.Fatal("preview should be stale once the branch moved on")
+	}
+
+	mg.mu.Lock()
+	mg.calls = nil
+	mg.mu.Unlock()
+	if err := o.RefreshPreview(); err != nil {
+		t.Fatalf("RefreshPreview: %v", err)
+	}
+	if !mg.hasCalled("MergeInWorktree:feat/x") {
+		t.Errorf("calls = %v, want the branch merged again", mg.calls)
+	}
+	if o.PreviewStale() {
+		t.Error("refresh should clear the stale mark")
+	}
+	o.checkPreviewStale()
+	if o.PreviewStale() {
+		t.Error("preview should follow the refreshed head")
+	}
+
+	if err := o.StopPreview(); err != nil {
+		t.Fatalf("StopPreview: %v", err)
+	}
+	if err := o.RefreshPreview(); err == nil {
+		t.Error("RefreshPreview without a preview succeeded")
+	}
+}
+
+func TestRefreshPreview_Conflicts(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	a.SetStatus(agent.StatusReviewReady)
+	if err := o.PreviewAgent(a.ID); err != nil {
+		t.Fatalf("PreviewAgent: %v", err)
+	}
+
+	mg.mu.Lock()
+	mg.mergeInWorktreeConflict = true
+	mg.mu.Unlock()
+	if err := o.RefreshPreview(); err == nil {
+		t.Fatal("RefreshPreview with conflicts succeeded")
+	}
+	if !mg.hasCalled("MergeAbort") {
+		t.Errorf("calls = %v, want the conflicted merge aborted", mg.calls)
+	}
+	if o.GetPreviewAgentID() != a.ID {
+		t.Error("the preview should stay active")
+	}
+}
+
+func TestRefreshPreview_BacksUpOnce(t *testing.T) {
+	mg := &mockGit{changeSnapshot: map[string]string{"a.go": "copied"}}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	a.SetStatus(agent.StatusReviewReady)
+	if err := o.PreviewAgent(a.ID); err != nil {
+		t.Fatalf("PreviewAgent: %v", err)
+	}
+
+	mg.mu.Lock()
+	mg.hasChangesResult = true
+	mg.calls = nil
+	mg.mu.Unlock()
+	if err := o.RefreshPreview(); err != nil {
+		t.Fatalf("RefreshPreview: %v", err)
+	}
+	if n := mg.callCount("BackupChanges:" + o.repoPath); n != 1 {
+		t.Errorf("backed up the previewed changes %d times, want once", n)
+	}
+}
+
+func TestStopPreview_StashesExternalChanges(t *testing.T) {
+	mg := &mockGit{changeSnapshot: map[string]string{"a.go": "copied"}}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	a.SetStatus(agent.StatusReviewReady)
+	if err := o.PreviewAgent(a.ID); err != nil {
+		t.Fatalf("PreviewAgent: %v", err)
+	}
+
+	// An editor saves a new file and touches the previewed one.
+	mg.mu.Lock()
+	mg.hasChangesResult = true
+	mg.changeSnapshot = map[string]string{"a.go": "edited", "b.go": "copied", "notes.txt": "new"}
+	mg.calls = nil
+	mg.mu.Unlock()
+	if err := o.RefreshPreview(); err == nil || !strings.Contains(err.Error(), "notes.txt") {
+		t.Errorf("RefreshPreview = %v, want it refused over the changed files", err)
+	}
+	if mg.hasCalled("DiscardChanges:"+o.repoPath) || mg.hasCalled("MergeInWorktree:feat/x") {
+		t.Errorf("calls = %v, a refused refresh should leave the worktree alone", mg.calls)
+	}
+
+	if err := o.StopPreview(); err != nil {
+		t.Fatalf("StopPreview: %v", err)
+	}
+	if !mg.hasCalled("StashChanges:a.go,b.go,notes.txt") {
+		t.Errorf("calls = %v, want the files changed during the preview stashed", mg.calls)
+	}
+}
+
+func TestStopPreview_DiscardsOwnChanges(t *testing.T) {
+	mg := &mockGit{changeSnapshot: map[string]string{"a.go": "copied"}}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	a.SetStatus(agent.StatusReviewReady)
+	if err := o.PreviewAgent(a.ID); err != nil {
+		t.Fatalf("PreviewAgent: %v", err)
+	}
+
+	mg.mu.Lock()
+	mg.hasChangesResult = true
+	mg.mu.Unlock()
+	if err := o.StopPreview(); err != nil {
+		t.Fatalf("StopPreview: %v", err)
+	}
+	for _, c := range mg.calls {
+		if strings.HasPrefix(c, "StashChanges") {
+			t.Errorf("calls = %v, want nothing stashed", mg.calls)
+		}
+	}
+}
+
+func TestRecoverPreview_FromDisk(t *testing.T) {
+	mg := &mockGit{currentBranchResult: "develop", branchExistsResult: true}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	a.SetStatus(agent.StatusReviewReady)
+	if err := o.PreviewAgent(a.ID); err != nil {
+		t.Fatalf("PreviewAgent: %v", err)
+	}
+	o.saveState()
+
+	// The TUI was killed: a new process only has the files to go on.
+	recovered := New(context.Background(), agent.NewStore(), "/repo", "", o.worktreeDir, WithGit(mg), WithTmux(&mockTmux{}), WithMonitor(&mockMonitor{}))
+	if err := recovered.LoadAgents(); err != nil {
+		t.Fatalf("LoadAgents: %v", err)
+	}
+
+	mg.mu.Lock()
+	mg.checkoutBranchErr = errors.New("local changes would be overwritten")
+	mg.mu.Unlock()
+	if _, err := recovered.RecoverPreview(); err == nil {
+		t.Fatal("RecoverPreview with a failing checkout succeeded")
+	}
+	if _, err := os.Stat(recovered.previewStatePath()); err != nil {
+		t.Error("the preview state should be kept so cleanup can be retried")
+	}
+
+	mg.mu.Lock()
+	mg.checkoutBranchErr = nil
+	mg.mu.Unlock()
+	stopped, err := recovered.RecoverPreview()
+	if err != nil || stopped.AgentID != a.ID {
+		t.Fatalf("RecoverPreview = %+v, %v, want the preview of %s", stopped, err, a.ID)
+	}
+	if !mg.hasCalled("CheckoutBranch:develop") || !mg.hasCalled("DeleteBranch:preview/"+a.ID) {
+		t.Errorf("calls = %v, want develop checked out and the preview branch deleted", mg.calls)
+	}
+	if ra, ok := recovered.store.Get(a.ID); !ok || ra.GetStatus() != agent.StatusReviewReady {
+		t.Error("the agent should get its status back")
+	}
+	if stopped, err := recovered.RecoverPreview(); err != nil || stopped.AgentID != "" {
+		t.Errorf("second RecoverPreview = %+v, %v, want nothing to do", stopped, err)
+	}
+}
@@ -0,0 +1,269 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/forge"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// PullRequestMsg reports the outcome of OpenPullRequest.
+type PullRequestMsg struct {
+	AgentID   string
+	Number    int
+	URL       string
+	Reviewers []string
+	Error     string
+	// Warning describes a step that failed after the pull request was
+	// opened, such as requesting reviewers.
+	Warning string
+}
+
+// WithPullRequests sets who is asked to review pull requests opened from
+// agent branches and which labels they get. With codeowners, the owners
+// of the changed files in the repository's CODEOWNERS file are asked too.
+func WithPullRequests(reviewers []string, codeowners bool, labels []string) Option {
+	return func(o *Orchestrator) {
+		o.prReviewers = reviewers
+		o.prCodeowners = codeowners
+		o.prLabels = labels
+	}
+}
+
+// WithPullRequestPolling checks the pull requests of finished agents every
+// interval, so pull requests merged or closed on the hosting provider are
+// noticed. With autoDismiss, an agent whose pull request was merged is
+// dismissed and its branch deleted. A zero interval disables polling.
+func WithPullRequestPolling(interval time.Duration, autoDismiss bool) Option {
+	return func(o *Orchestrator) {
+		o.prPollInterval = interval
+		o.prAutoDismiss = autoDismiss
+	}
+}
+
+// OpenPullRequest pushes an agent's branch, opens a pull request into its
+// base branch, requests reviewers and records the pull request on the
+// agent.
+func (o *Orchestrator) OpenPullRequest(id string) PullRequestMsg {
+	msg := o.openPullRequest(id)
+	var err error
+	if msg.Error != "" {
+		err = errors.New(msg.Error)
+	}
+	o.record(ipc.OpPullRequest, o.agentBranch(id), ipc.PullRequestParams{ID: id}, err)
+	return msg
+}
+
+func (o *Orchestrator) openPullRequest(id string) PullRequestMsg {
+	var res ipc.PullRequestResult
+	handled, err := o.remoteCall(func(c *ipc.Client) (err error) {
+		res, err = c.PullRequest(ipc.PullRequestParams{ID: id})
+		return err
+	})
+	if handled {
+		if err != nil {
+			return PullRequestMsg{AgentID: id, Error: err.Error()}
+		}
+		return PullRequestMsg{AgentID: id, Number: res.Number, URL: res.URL, Reviewers: res.Reviewers, Warning: res.Warning}
+	}
+
+	a, ok := o.store.Get(id)
+	if !ok {
+		return PullRequestMsg{AgentID: id, Error: "agent not found"}
+	}
+	if a.ReadOnly {
+		return PullRequestMsg{AgentID: id, Error: fmt.Sprintf("agent %s is read-only and cannot be pushed", id)}
+	}
+	if pr := a.GetPullRequest(); pr != nil && pr.State != agent.PRClosed {
+		return PullRequestMsg{AgentID: id, Error: fmt.Sprintf("pull request #%d is already %s: %s", pr.Number, pr.State, pr.URL)}
+	}
+	p, err := o.Forge()
+	if err != nil {
+		return PullRequestMsg{AgentID: id, Error: err.Error()}
+	}
+	if err := p.Push(a.Branch); err != nil {
+		return PullRequestMsg{AgentID: id, Error: fmt.Sprintf("push failed: %v", err)}
+	}
+
+	title, body := o.pullRequestText(a)
+	pr, err := p.CreatePullRequest(forge.PullRequestOptions{
+		Head:   a.Branch,
+		Base:   a.GetBaseBranch(),
+		Title:  title,
+		Body:   body,
+		Labels: o.prLabels,
+	})
+	if err != nil {
+		return PullRequestMsg{AgentID: id, Error: fmt.Sprintf("failed to open pull request: %v", err)}
+	}
+	slog.Info("pull request opened", "id", id, "number", pr.Number, "url", pr.URL)
+
+	msg := PullRequestMsg{AgentID: id, Number: pr.Number, URL: pr.URL}
+	rec := &agent.PullRequest{Number: pr.Number, URL: pr.URL, State: agent.PROpen, Labels: o.prLabels}
+	if reviewers := o.pullRequestReviewers(a); len(reviewers) > 0 {
+		if err := p.RequestReview(pr.Number, reviewers); err != nil {
+			slog.Warn("requesting reviewers failed", "id", id, "reviewers", reviewers, "error", err)
+			msg.Warning = fmt.Sprintf("requesting reviewers failed: %v", err)
+		} else {
+			msg.Reviewers = reviewers
+			rec.Reviewers = reviewers
+		}
+	}
+	a.SetPullRequest(rec)
+	o.saveState()
+	return msg
+}
+
+// pullRequestText returns the title and body of the agent's pull request:
+// the subject of its only commit, or its branch, and the subjects of its
+// commits followed by its ticket. Commits that can't be listed leave the
+// branch and ticket.
+func (o *Orchestrator) pullRequestText(a *agent.Agent) (title, body string) {
+	title = a.Branch
+	subjects, total, err := o.commitSubjects(a)
+	if err != nil {
+		slog.Warn("failed to list commits for pull request", "id", a.ID, "error", err)
+	}
+	var parts []string
+	if len(subjects) == 1 {
+		title = subjects[0]
+	} else if list := commitList(subjects, total); list != "" {
+		parts = append(parts, list)
+	}
+	if a.Ticket != "" {
+		parts = append(parts, "Ticket: "+a.Ticket)
+	}
+	return title, strings.Join(parts, "\n\n")
+}
+
+// pullRequestReviewers returns the configured reviewers followed by the
+// CODEOWNERS owners of the files the agent changed, without duplicates.
+func (o *Orchestrator) pullRequestReviewers(a *agent.Agent) []string {
+	reviewers := append([]string(nil), o.prReviewers...)
+	if o.prCodeowners {
+		owners, err := o.codeowners(a)
+		if err != nil {
+			slog.Warn("reading code owners failed", "id", a.ID, "error", err)
+		}
+		reviewers = append(reviewers, owners...)
+	}
+	seen := make(map[string]bool)
+	unique := reviewers[:0]
+	for _, r := range reviewers {
+		if !seen[r] {
+			seen[r] = true
+			unique = append(unique, r)
+		}
+	}
+	return unique
+}
+
+// codeowners returns the owners of the files changed on the agent's branch.
+func (o *Orchestrator) codeowners(a *agent.Agent) ([]string, error) {
+	owners, err := forge.ReadCodeowners(a.WorktreePath)
+	if err != nil || owners == nil {
+		return nil, err
+	}
+	files, err := o.git.ChangedFiles(o.repoPath, a.GetBaseBranch(), a.Branch)
+	if err != nil {
+		return nil, err
+	}
+	return owners.Owners(files), nil
+}
+
+// workNotInPullRequest tells why dismissing agent a, whose pull request
+// pr was merged, would lose work, or returns "" if everything in its
+// worktree is in the pull request.
+func (o *Orchestrator) workNotInPullRequest(a *agent.Agent, pr forge.PullRequest) string {
+	if o.git.HasChanges(a.WorktreePath) {
+		return "it has uncommitted changes"
+	}
+	head, err := o.git.HeadCommit(a.WorktreePath, "HEAD")
+	if err != nil {
+		return fmt.Sprintf("its HEAD can't be read: %v", err)
+	}
+	if pr.HeadOID == "" || !strings.HasPrefix(head, pr.HeadOID) {
+		return "it has commits the pull request doesn't"
+	}
+	return ""
+}
+
+// watchPullRequests polls pull request state until the orchestrator's
+// context is cancelled. It runs apart from the monitor loop because every
+// check is a network round trip.
+func (o *Orchestrator) watchPullRequests() {
+	ticker := time.NewTicker(o.prPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.ctx.Done():
+			return
+		case <-ticker.C:
+			o.checkPullRequests()
+		}
+	}
+}
+
+// checkPullRequests looks up the pull request of every agent that is
+// finished or has one open, records its state and reports merges. Pull
+// requests opened outside mastermind are picked up too.
+func (o *Orchestrator) checkPullRequests() {
+	p, err := o.Forge()
+	if err != nil {
+		slog.Debug("pull request polling skipped", "error", err)
+		return
+	}
+	for _, a := range o.store.All() {
+		prev := a.GetPullRequest()
+		if prev != nil && prev.State != agent.PROpen {
+			continue
+		}
+		if prev == nil {
+			switch a.GetStatus() {
+			case agent.StatusReviewReady, agent.StatusReviewed, agent.StatusDone:
+			default:
+				continue
+			}
+		}
+		pr, ok, err := p.FindPullRequest(a.Branch, a.GetBaseBranch())
+		if err != nil {
+			slog.Warn("pull request lookup failed", "id", a.ID, "branch", a.Branch, "error", err)
+			continue
+		}
+		if !ok || (prev != nil && prev.Number == pr.Number && prev.State == string(pr.State)) {
+			continue
+		}
+
+		rec := &agent.PullRequest{Number: pr.Number, URL: pr.URL, State: string(pr.State)}
+		if prev != nil && prev.Number == pr.Number {
+			rec.Reviewers, rec.Labels = prev.Reviewers, prev.Labels
+		}
+		a.SetPullRequest(rec)
+		o.store.MarkDirty()
+		slog.Info("pull request state changed", "id", a.ID, "number", pr.Number, "state", pr.State)
+
+		if pr.State != forge.PRMerged {
+			continue
+		}
+		ev := monitor.PullRequestMerged{AgentID: a.ID, Number: pr.Number, URL: pr.URL}
+		if o.prAutoDismiss {
+			if kept := o.workNotInPullRequest(a, pr); kept != "" {
+				// The pull request is recorded as merged; the branch and
+				// worktree stay for the user to deal with.
+				slog.Warn("not auto-dismissing agent after merge", "id", a.ID, "reason", kept)
+				ev.Kept = kept
+			} else if err := o.dismissAgent(a.ID, true); err != nil {
+				slog.Warn("auto-dismiss after merge failed", "id", a.ID, "error", err)
+			} else {
+				ev.Dismissed = true
+			}
+		}
+		o.handleMonitorEvent(ev)
+	}
+}
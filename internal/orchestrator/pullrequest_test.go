@@ -0,0 +1,178 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/forge"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+func TestOpenPullRequest_RequestsReviewersAndRecordsPR(t *testing.T) {
+	wt := t.TempDir()
+	os.WriteFile(filepath.Join(wt, "CODEOWNERS"), []byte("* @core\n/docs/ @ann @writers\n"), 0o644)
+
+	mg := &mockGit{changedFilesResult: []string{"docs/guide.md", "main.go"}}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	ff := &fakeForge{}
+	WithForgeProvider(ff)(o)
+	WithPullRequests([]string{"ann", "lead"}, true, []string{"agent"})(o)
+	a := agent.NewAgent("feat/x", "main", wt, "@1", "%1", "claude")
+	o.store.Add(a)
+
+	msg := o.OpenPullRequest(a.ID)
+	if msg.Error != "" || msg.Warning != "" || msg.Number != 1 {
+		t.Fatalf("msg = %+v", msg)
+	}
+	want := []string{"Push:feat/x", "CreatePullRequest:feat/x->main agent", "RequestReview:ann,lead,writers,core"}
+	if got := strings.Join(ff.calls, "|"); got != strings.Join(want, "|") {
+		t.Errorf("forge calls = %q, want %q", ff.calls, want)
+	}
+	if !mg.hasCalled("ChangedFiles:main...feat/x") {
+		t.Errorf("git calls = %v, want ChangedFiles", mg.calls)
+	}
+	pr := a.GetPullRequest()
+	if pr == nil || pr.State != agent.PROpen || pr.URL != "https://forge.test/pr/1" || len(pr.Reviewers) != 4 {
+		t.Errorf("recorded PR = %+v", pr)
+	}
+
+	if msg := o.OpenPullRequest(a.ID); !strings.Contains(msg.Error, "already open") {
+		t.Errorf("second open: msg = %+v", msg)
+	}
+}
+
+func TestOpenPullRequest_ReviewFailureIsAWarning(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	WithForgeProvider(&fakeForge{reviewErr: errors.New("no such user")})(o)
+	WithPullRequests([]string{"ghost"}, false, nil)(o)
+	a := agent.NewAgent("feat/x", "main", t.TempDir(), "@1", "%1", "claude")
+	o.store.Add(a)
+
+	msg := o.OpenPullRequest(a.ID)
+	if msg.Error != "" || !strings.Contains(msg.Warning, "no such user") {
+		t.Fatalf("msg = %+v", msg)
+	}
+	if pr := a.GetPullRequest(); pr == nil || len(pr.Reviewers) != 0 {
+		t.Errorf("recorded PR = %+v", pr)
+	}
+}
+
+func TestOpenPullRequest_TextFromCommits(t *testing.T) {
+	mg := &mockGit{commitsResult: []string{"bbb2222 Cover sign-in", "aaa1111 Add sign-in"}, commitsTotal: 2}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	ff := &fakeForge{}
+	WithForgeProvider(ff)(o)
+	a := agent.NewAgent("feat/x", "main", t.TempDir(), "@1", "%1", "claude")
+	a.Ticket = "ENG-1"
+	o.store.Add(a)
+
+	if msg := o.OpenPullRequest(a.ID); msg.Error != "" {
+		t.Fatalf("msg = %+v", msg)
+	}
+	if ff.created.Title != "feat/x" || ff.created.Body != "* Add sign-in\n* Cover sign-in\n\nTicket: ENG-1" {
+		t.Errorf("title = %q, body = %q, want the branch and the commits oldest first", ff.created.Title, ff.created.Body)
+	}
+
+	mg.commitsResult, mg.commitsTotal = []string{"aaa1111 Add sign-in"}, 1
+	if title, body := o.pullRequestText(a); title != "Add sign-in" || body != "Ticket: ENG-1" {
+		t.Errorf("pullRequestText = %q, %q, want the only commit's subject as title", title, body)
+	}
+}
+
+func TestCheckPullRequests_MarksExternalMerge(t *testing.T) {
+	o := newTestOrch(t, &mockGit{}, &mockTmux{}, &mockMonitor{})
+	ff := &fakeForge{found: forge.PullRequest{Number: 9, URL: "https://forge.test/pr/9", State: forge.PRMerged}}
+	WithForgeProvider(ff)(o)
+	events := o.Events()
+
+	ready := agent.NewAgent("feat/x", "main", "/wt/x", "@1", "%1", "claude")
+	ready.SetStatus(agent.StatusReviewReady)
+	running := agent.NewAgent("feat/y", "main", "/wt/y", "@2", "%2", "claude")
+	o.store.Add(ready)
+	o.store.Add(running)
+
+	o.checkPullRequests()
+
+	if len(ff.calls) != 1 || ff.calls[0] != "FindPullRequest:feat/x->main" {
+		t.Errorf("forge calls = %q, want only the finished agent looked up", ff.calls)
+	}
+	if pr := ready.GetPullRequest(); pr == nil || pr.State != agent.PRMerged || pr.Number != 9 {
+		t.Errorf("recorded PR = %+v", pr)
+	}
+	select {
+	case ev := <-events:
+		if got, ok := ev.(monitor.PullRequestMerged); !ok || got.AgentID != ready.ID || got.Dismissed {
+			t.Errorf("event = %#v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no PullRequestMerged event")
+	}
+
+	// A merged pull request is final and not looked up again.
+	o.checkPullRequests()
+	if len(ff.calls) != 1 {
+		t.Errorf("forge calls = %q, want no further lookups", ff.calls)
+	}
+}
+
+func TestCheckPullRequests_AutoDismiss(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123"}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	WithForgeProvider(&fakeForge{found: forge.PullRequest{Number: 9, State: forge.PRMerged, HeadOID: "abc123"}})(o)
+	WithPullRequestPolling(time.Minute, true)(o)
+	events := o.Events()
+
+	a := agent.NewAgent("feat/x", "main", "/wt/x", "@1", "%1", "claude")
+	a.SetPullRequest(&agent.PullRequest{Number: 9, State: agent.PROpen})
+	o.store.Add(a)
+
+	o.checkPullRequests()
+
+	if _, ok := o.store.Get(a.ID); ok {
+		t.Error("agent still tracked after its pull request merged")
+	}
+	if !mg.hasCalled("DeleteBranch:feat/x") {
+		t.Errorf("git calls = %v, want DeleteBranch", mg.calls)
+	}
+	if ev := <-events; !ev.(monitor.PullRequestMerged).Dismissed {
+		t.Errorf("event = %#v, want Dismissed", ev)
+	}
+}
+
+func TestCheckPullRequests_AutoDismissKeepsUnpushedWork(t *testing.T) {
+	for name, mg := range map[string]*mockGit{
+		"new commits":         {headCommitResult: "def456"},
+		"uncommitted changes": {headCommitResult: "abc123", hasChangesResult: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+			WithForgeProvider(&fakeForge{found: forge.PullRequest{Number: 9, State: forge.PRMerged, HeadOID: "abc123"}})(o)
+			WithPullRequestPolling(time.Minute, true)(o)
+			events := o.Events()
+
+			a := agent.NewAgent("feat/x", "main", "/wt/x", "@1", "%1", "claude")
+			a.SetPullRequest(&agent.PullRequest{Number: 9, State: agent.PROpen})
+			o.store.Add(a)
+
+			o.checkPullRequests()
+
+			if _, ok := o.store.Get(a.ID); !ok {
+				t.Fatal("agent with work the pull request lacks was dismissed")
+			}
+			if pr := a.GetPullRequest(); pr == nil || pr.State != agent.PRMerged {
+				t.Errorf("recorded PR = %+v, want merged", pr)
+			}
+			if mg.hasCalled("DeleteBranch:feat/x") {
+				t.Error("branch deleted")
+			}
+			if ev := (<-events).(monitor.PullRequestMerged); ev.Dismissed || ev.Kept == "" {
+				t.Errorf("event = %#v, want the agent kept with a reason", ev)
+			}
+		})
+	}
+}
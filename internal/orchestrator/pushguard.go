@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonbystrom/mastermind/internal/ipc"
+)
+
+// guardPushes blocks pushes from a new agent's worktree when the push
+// guard is enabled or the agent is read-only, installing the repository's
+// pre-push hook if needed. An agent whose pushes can't be blocked must not
+// start thinking they are, so that is an error. A worktree reused from an
+// earlier agent may still carry its setting, so it is cleared otherwise.
+func (o *Orchestrator) guardPushes(wtPath string, readOnly bool) error {
+	if !o.pushGuard && !readOnly {
+		if err := o.git.SetPushBlocked(wtPath, false); err != nil {
+			slog.Warn("failed to clear push guard", "path", wtPath, "error", err)
+		}
+		return nil
+	}
+	if err := o.git.InstallPushGuard(o.repoPath); err != nil {
+		return fmt.Errorf("install push guard (set [git] push_guard = false to spawn without it): %w", err)
+	}
+	if err := o.git.SetPushBlocked(wtPath, true); err != nil {
+		return fmt.Errorf("block pushes: %w", err)
+	}
+	return nil
+}
+
+// SetAllowPush allows or blocks pushes from the agent's worktree.
+func (o *Orchestrator) SetAllowPush(id string, allow bool) error {
+	err := o.setAllowPush(id, allow)
+	o.record(ipc.OpAllowPush, o.agentBranch(id), ipc.AllowPushParams{ID: id, Allow: allow}, err)
+	return err
+}
+
+func (o *Orchestrator) setAllowPush(id string, allow bool) error {
+	handled, err := o.remoteCall(func(c *ipc.Client) error {
+		return c.AllowPush(ipc.AllowPushParams{ID: id, Allow: allow})
+	})
+	if handled {
+		return err
+	}
+
+	a, ok := o.store.Get(id)
+	if !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	if a.ReadOnly {
+		return fmt.Errorf("agent %s is read-only and cannot push", id)
+	}
+	if !o.pushGuard {
+		return fmt.Errorf("the push guard is disabled; agents can already push")
+	}
+	if err := o.git.SetPushBlocked(a.WorktreePath, !allow); err != nil {
+		return fmt.Errorf("set push guard: %w", err)
+	}
+	a.SetAllowPush(allow)
+	slog.Info("agent push guard changed", "agent", id, "allow", allow)
+	o.saveState()
+	return nil
+}
@@ -0,0 +1,71 @@
+package orchestrator
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func TestSpawnAgent_BlocksPushes(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	wtPath := filepath.Join(o.worktreeDir, "feat__x")
+	if !mg.hasCalled("InstallPushGuard") || !mg.hasCalled("SetPushBlocked:"+wtPath+":true") {
+		t.Errorf("expected the new worktree to be push-guarded, calls = %v", mg.calls)
+	}
+
+	o.pushGuard = false
+	if err := o.SpawnAgent("feat/y", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	if !mg.hasCalled("SetPushBlocked:" + filepath.Join(o.worktreeDir, "feat__y") + ":false") {
+		t.Error("expected pushes to be allowed with the guard disabled")
+	}
+}
+
+func TestSpawnAgent_FailsWithoutPushGuard(t *testing.T) {
+	mg := &mockGit{pushGuardErr: errors.New("pre-push exists")}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err == nil {
+		t.Fatal("expected the spawn to fail when pushes can't be blocked")
+	}
+	wtPath := filepath.Join(o.worktreeDir, "feat__x")
+	if mg.hasCalled("SetPushBlocked:" + wtPath + ":true") {
+		t.Error("worktree marked as push-guarded without the guard installed")
+	}
+	if !mg.hasCalled("RemoveWorktree:"+wtPath) || !mg.hasCalled("DeleteBranch:feat/x") {
+		t.Errorf("calls = %v, want the worktree and branch cleaned up", mg.calls)
+	}
+	if len(o.store.All()) != 0 {
+		t.Error("agent added despite the failed spawn")
+	}
+}
+
+func TestSetAllowPush(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{}, &mockMonitor{})
+	a := agent.NewAgent("feat/x", "main", "/wt/feat__x", "@1", "%1", "claude")
+	o.store.Add(a)
+
+	if err := o.SetAllowPush(a.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if !a.GetAllowPush() || !mg.hasCalled("SetPushBlocked:/wt/feat__x:false") {
+		t.Error("expected the agent to be allowed to push")
+	}
+	if err := o.SetAllowPush(a.ID, false); err != nil || a.GetAllowPush() || !mg.hasCalled("SetPushBlocked:/wt/feat__x:true") {
+		t.Errorf("expected pushes to be blocked again, err = %v", err)
+	}
+
+	o.pushGuard = false
+	if err := o.SetAllowPush(a.ID, true); err == nil {
+		t.Error("expected an error with the push guard disabled")
+	}
+}
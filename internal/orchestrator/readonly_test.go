@@ -0,0 +1,41 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSpawnAgent_ReadOnly(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	o.pushGuard = false
+
+	if err := o.SpawnAgent("research/x", "main", true, "claude", ReadOnly()); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	if !a.ReadOnly || !a.Persisted().ReadOnly {
+		t.Fatal("expected a read-only agent")
+	}
+	if !mg.hasCalled("SetPushBlocked:" + filepath.Join(o.worktreeDir, "research__x") + ":true") {
+		t.Error("read-only agents cannot push even with the push guard disabled")
+	}
+
+	if msg := o.MergeAgent(a.ID, true, true, false, MergeCommit, ""); !strings.Contains(msg.Error, "read-only") {
+		t.Errorf("MergeAgent = %+v, want a read-only error", msg)
+	}
+	if msg := o.OpenPullRequest(a.ID); !strings.Contains(msg.Error, "read-only") {
+		t.Errorf("OpenPullRequest = %+v, want a read-only error", msg)
+	}
+	if err := o.SetAllowPush(a.ID, true); err == nil {
+		t.Error("expected read-only agents to stay blocked")
+	}
+
+	if err := o.DismissAgent(a.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if mg.hasCalled("DeleteBranch:research/x") {
+		t.Error("dismissing a read-only agent must keep its branch")
+	}
+}
@@ -0,0 +1,44 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/simonbystrom/mastermind/internal/recorder"
+)
+
+// WithRecorder records the user's actions on agents, such as spawning,
+// merging and dismissing them, into r's script. Merges and dismissals
+// mastermind makes on its own, like a playbook's auto-merge, are not
+// recorded: a replay makes them again by itself.
+func WithRecorder(r *recorder.Recorder) Option {
+	return func(o *Orchestrator) { o.recorder = r }
+}
+
+// record adds an action on the agent on branch to the recording, if any.
+func (o *Orchestrator) record(op, branch string, params any, err error) {
+	if o.recorder == nil {
+		return
+	}
+	o.recorder.Record(op, branch, params, err)
+}
+
+// agentBranch returns the branch of the agent with the given ID, or "" if
+// there is no such agent.
+func (o *Orchestrator) agentBranch(id string) string {
+	if a, ok := o.store.Get(id); ok {
+		return a.Branch
+	}
+	return ""
+}
+
+// mergeError describes a merge that did not succeed, or returns nil.
+func mergeError(msg MergeResultMsg) error {
+	switch {
+	case msg.Conflict:
+		return fmt.Errorf("merge conflicts in %d file(s)", len(msg.ConflictFiles))
+	case !msg.Success:
+		return errors.New(msg.Error)
+	}
+	return nil
+}
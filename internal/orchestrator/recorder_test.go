@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/recorder"
+)
+
+func TestRecorder_RecordsUserActions(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123"}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	path := filepath.Join(t.TempDir(), "session.json")
+	WithRecorder(recorder.New(path, "/repo"))(o)
+
+	if err := o.SpawnAgent("feat/x", "main", true, "claude", ReadOnly()); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	if err := o.SpawnAgent("feat/y", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	x, _ := o.store.Get(o.AgentBranches()["feat/x"])
+	y, _ := o.store.Get(o.AgentBranches()["feat/y"])
+	o.MergeAgent(x.ID, true, true, false, MergeCommit, "") // read-only, so it fails
+	o.MergeAgent(y.ID, true, true, false, MergeCommit, "")
+	// Cleaning up is not the user's action.
+	o.spawnAgent(spawnRequest{branch: "feat/z", baseBranch: "main", createBranch: true, harness: "claude"})
+	o.dismissAgent(o.AgentBranches()["feat/z"], false)
+
+	s, err := recorder.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []struct{ op, branch string }{
+		{ipc.OpSpawn, "feat/x"},
+		{ipc.OpSpawn, "feat/y"},
+		{ipc.OpMerge, "feat/x"},
+		{ipc.OpMerge, "feat/y"},
+	}
+	if len(s.Actions) != len(want) {
+		t.Fatalf("recorded %d actions, want %d: %+v", len(s.Actions), len(want), s.Actions)
+	}
+	for i, w := range want {
+		if a := s.Actions[i]; a.Op != w.op || a.Branch != w.branch {
+			t.Errorf("action %d = %s %s, want %s %s", i, a.Op, a.Branch, w.op, w.branch)
+		}
+	}
+	if s.Actions[2].Error == "" || s.Actions[3].Error != "" {
+		t.Errorf("merge errors = %q, %q; want only the read-only merge to fail", s.Actions[2].Error, s.Actions[3].Error)
+	}
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+// ReportCollectedMsg is sent to the TUI when a report agent's report has
+// been copied into the reports directory.
+type ReportCollectedMsg struct {
+	AgentID string
+	Path    string
+}
+
+// ReportFile returns the file report agents are asked to write.
+func (o *Orchestrator) ReportFile() string {
+	return o.reportFile
+}
+
+// reportContext tells a report agent where to put its deliverable.
+func reportContext(file string) string {
+	return fmt.Sprintf("Your deliverable is a written report, not code changes. When you are done, write it to %s "+
+		"in the root of this worktree; it is collected when you finish. Do not commit it.", file)
+}
+
+// collectReport copies the report a finished report agent wrote into the
+// reports directory, named after its branch and the time, so it outlives
+// the worktree.
+func (o *Orchestrator) collectReport(a *agent.Agent) {
+	data, err := os.ReadFile(filepath.Join(a.WorktreePath, a.ReportFile))
+	if err != nil {
+		slog.Warn("report agent finished without a report", "agent", a.ID, "file", a.ReportFile, "error", err)
+		o.triggerAttention(a.ID, fmt.Sprintf("Agent %s finished without writing %s", a.ID, a.ReportFile))
+		return
+	}
+
+	name := fmt.Sprintf("%s-%s%s", git.WorktreeDirName(a.Branch), time.Now().Format("20060102-150405"), filepath.Ext(a.ReportFile))
+	path := filepath.Join(o.reportsDir, name)
+	if err := os.MkdirAll(o.reportsDir, 0o755); err != nil {
+		slog.Error("failed to create reports directory", "dir", o.reportsDir, "error", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		slog.Error("failed to collect report", "agent", a.ID, "path", path, "error", err)
+		return
+	}
+
+	a.SetReportPath(path)
+	slog.Info("report collected", "agent", a.ID, "path", path)
+	o.saveState()
+	if o.program != nil {
+		o.program.Send(ReportCollectedMsg{AgentID: a.ID, Path: path})
+	}
+}
@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+func TestSpawnAgent_ReportCollectedOnFinish(t *testing.T) {
+	mg := &mockGit{}
+	mt := &mockTmux{windowIDForPane: "@1"}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+
+	if err := o.SpawnAgent("research/x", "main", true, "claude", Report()); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	if !a.ReadOnly || a.ReportFile != "REPORT.md" {
+		t.Fatalf("agent = read-only %v, report %q; want a read-only report agent", a.ReadOnly, a.ReportFile)
+	}
+	if cmd := strings.Join(mt.newWindowCommand, " "); !strings.Contains(cmd, "write it to REPORT.md") {
+		t.Errorf("command = %q, want the report instructions", cmd)
+	}
+
+	// Without a report there is nothing to collect.
+	o.handleMonitorEvent(monitor.AgentFinished{AgentID: a.ID})
+	if a.GetReportPath() != "" {
+		t.Errorf("report path = %q without a report", a.GetReportPath())
+	}
+
+	if err := os.MkdirAll(a.WorktreePath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(a.WorktreePath, "REPORT.md"), []byte("# Findings\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o.handleMonitorEvent(monitor.AgentFinished{AgentID: a.ID})
+
+	path := a.GetReportPath()
+	if filepath.Dir(path) != filepath.Join(o.worktreeDir, "reports") || !strings.HasPrefix(filepath.Base(path), "research__x-") {
+		t.Errorf("report path = %q", path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "# Findings\n" {
+		t.Errorf("collected report = %q, %v", data, err)
+	}
+}
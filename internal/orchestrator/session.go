@@ -0,0 +1,134 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// SessionChangedMsg is sent to the TUI when the tmux session mastermind
+// runs in was renamed or the mastermind pane moved to another session.
+type SessionChangedMsg struct {
+	Name  string
+	Moved bool // the pane moved to a different session, rather than a rename
+}
+
+// WithSelfPane sets the tmux pane mastermind runs in. The orchestrator
+// watches which session the pane belongs to and follows renames and moves.
+func WithSelfPane(paneID string) Option {
+	return func(o *Orchestrator) { o.selfPane = paneID }
+}
+
+// WithSpawnSessions adds session names offered in the spawn wizard even
+// when they do not exist yet, e.g. a "noise" session for low-priority
+// agents. They are created on first use.
+func WithSpawnSessions(names []string) Option {
+	return func(o *Orchestrator) { o.spawnSessions = names }
+}
+
+// currentSession returns the tmux target for the session new agent windows
+// are created in: a session ID such as "$3" once resolved, otherwise the
+// session name mastermind was started with.
+func (o *Orchestrator) currentSession() string {
+	o.sessionMu.RLock()
+	defer o.sessionMu.RUnlock()
+	return o.session
+}
+
+// syncSession re-resolves the session containing the mastermind pane and
+// retargets tmux calls at it. Targeting the session by ID keeps working
+// after a rename; a changed ID means the pane was moved. Agent windows left
+// in the old session are still monitored, since pane IDs are unique across
+// the tmux server.
+func (o *Orchestrator) syncSession() {
+	if o.selfPane == "" {
+		return
+	}
+	id, name, err := o.tmux.SessionForPane(o.selfPane)
+	if err != nil {
+		slog.Debug("resolve mastermind session failed", "pane", o.selfPane, "error", err)
+		return
+	}
+
+	o.sessionMu.Lock()
+	prevID, prevName := o.sessionID, o.sessionName
+	o.session, o.sessionID, o.sessionName = id, id, name
+	o.sessionMu.Unlock()
+
+	if prevID == "" {
+		// First resolution: only switch from the name to the ID.
+		return
+	}
+	if id == prevID && name == prevName {
+		return
+	}
+	moved := id != prevID
+	slog.Info("tmux session changed", "from", prevName, "to", name, "id", id, "moved", moved)
+	if o.program != nil {
+		o.program.Send(SessionChangedMsg{Name: name, Moved: moved})
+	}
+}
+
+// SessionName returns the name of the tmux session mastermind runs in.
+func (o *Orchestrator) SessionName() string {
+	o.sessionMu.RLock()
+	defer o.sessionMu.RUnlock()
+	return o.sessionName
+}
+
+// Sessions returns the sessions agents can be spawned into: mastermind's
+// own session first, then the other sessions on the server, then configured
+// spawn sessions that do not exist yet.
+func (o *Orchestrator) Sessions() []string {
+	own := o.SessionName()
+	names := []string{own}
+	existing, err := o.tmux.ListSessions()
+	if err != nil {
+		slog.Debug("list tmux sessions failed", "error", err)
+	}
+	for _, name := range append(existing, o.spawnSessions...) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// spawnSession returns the tmux target for a new agent window in the named
+// session, creating the session if needed.
+func (o *Orchestrator) spawnSession(name string) (string, error) {
+	if name == "" || name == o.SessionName() {
+		return o.currentSession(), nil
+	}
+	existing, err := o.tmux.ListSessions()
+	if err != nil {
+		return "", fmt.Errorf("list tmux sessions: %w", err)
+	}
+	if !slices.Contains(existing, name) {
+		if err := o.tmux.CreateSession(name); err != nil {
+			return "", fmt.Errorf("create tmux session: %w", err)
+		}
+		slog.Info("created tmux session for agents", "session", name)
+	}
+	return name, nil
+}
+
+// inOtherSession reports whether the agent's window lives in a different
+// tmux session from the mastermind pane, so focusing it needs the client
+// to switch sessions.
+func (o *Orchestrator) inOtherSession(a *agent.Agent) bool {
+	if o.selfPane == "" {
+		return false
+	}
+	own, _, err := o.tmux.SessionForPane(o.selfPane)
+	if err != nil {
+		return false
+	}
+	agentSession, _, err := o.tmux.SessionForPane(a.TmuxPaneID)
+	if err != nil {
+		return false
+	}
+	return agentSession != own
+}
@@ -0,0 +1,95 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func TestSyncSession_FollowsRenameAndMove(t *testing.T) {
+	mt := &mockTmux{sessionID: "$1", sessionName: "work"}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	o.selfPane = "%0"
+
+	o.syncSession()
+	if got := o.currentSession(); got != "$1" {
+		t.Fatalf("session = %q, want the session ID $1", got)
+	}
+
+	mt.mu.Lock()
+	mt.sessionName = "renamed"
+	mt.mu.Unlock()
+	o.syncSession()
+	if got := o.currentSession(); got != "$1" {
+		t.Errorf("after rename session = %q, want $1", got)
+	}
+	if o.sessionName != "renamed" {
+		t.Errorf("sessionName = %q, want renamed", o.sessionName)
+	}
+
+	mt.mu.Lock()
+	mt.sessionID, mt.sessionName = "$2", "other"
+	mt.mu.Unlock()
+	o.syncSession()
+	if got := o.currentSession(); got != "$2" {
+		t.Errorf("after move session = %q, want $2", got)
+	}
+}
+
+func TestSyncSession_KeepsTargetWhenPaneUnknown(t *testing.T) {
+	mt := &mockTmux{}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	o.selfPane = "%0"
+
+	o.syncSession()
+	if got := o.currentSession(); got != o.sessionName {
+		t.Errorf("session = %q, want the configured name %q", got, o.sessionName)
+	}
+}
+
+func TestSpawnAgent_InSessionCreatesMissingSession(t *testing.T) {
+	mt := &mockTmux{windowIDForPane: "@1", listSessionsResult: []string{"test-session"}}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+
+	if err := o.SpawnAgent("feat/noise", "main", true, "claude", InSession("noise")); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	if !mt.hasCalled("CreateSession:noise") {
+		t.Error("expected the missing session to be created")
+	}
+	if mt.newWindowSession != "noise" {
+		t.Errorf("window opened in %q, want noise", mt.newWindowSession)
+	}
+}
+
+func TestSessions_OwnSessionFirst(t *testing.T) {
+	mt := &mockTmux{listSessionsResult: []string{"other", "test-session"}}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	o.spawnSessions = []string{"noise", "other"}
+
+	got := o.Sessions()
+	want := []string{"test-session", "other", "noise"}
+	if len(got) != len(want) {
+		t.Fatalf("Sessions() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Sessions() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFocusAgent_SwitchesClientAcrossSessions(t *testing.T) {
+	mt := &mockTmux{paneSessions: map[string]string{"%0": "$1", "%1": "$2"}}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	o.selfPane = "%0"
+	a := agent.NewAgent("feat/noise", "main", "/wt", "@5", "%1", "claude")
+	o.store.Add(a)
+
+	if err := o.FocusAgent(a.ID); err != nil {
+		t.Fatalf("FocusAgent: %v", err)
+	}
+	if !mt.hasCalled("SwitchClient:@5") {
+		t.Error("expected the client to switch to the agent's session")
+	}
+}
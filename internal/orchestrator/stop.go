@@ -0,0 +1,183 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// stopPoll is how often an agent's processes are checked while waiting
+// for them to exit.
+const stopPoll = 100 * time.Millisecond
+
+// ProcessOps inspects and signals the process group of an agent pane.
+// tmux starts each pane's command as a session leader, so its process
+// group ID is the pane's PID and holds everything the agent started that
+// didn't detach.
+type ProcessOps interface {
+	// Alive reports whether any process of the group is still running.
+	Alive(pgid int) bool
+	// Signal sends sig to every process of the group.
+	Signal(pgid int, sig syscall.Signal) error
+	// InDir lists the processes running in dir or below it.
+	InDir(dir string) ([]Process, error)
+	// SignalPID sends sig to a single process.
+	SignalPID(pid int, sig syscall.Signal) error
+}
+
+// RealProcesses signals process groups with kill(2).
+type RealProcesses struct{}
+
+func (RealProcesses) Alive(pgid int) bool {
+	return syscall.Kill(-pgid, 0) == nil
+}
+
+func (RealProcesses) Signal(pgid int, sig syscall.Signal) error {
+	if err := syscall.Kill(-pgid, sig); err != nil {
+		return fmt.Errorf("signal process group %d: %w", pgid, err)
+	}
+	return nil
+}
+
+// WithProcesses overrides how agent processes are inspected and signalled.
+func WithProcesses(p ProcessOps) Option {
+	return func(o *Orchestrator) { o.procs = p }
+}
+
+// WithStopTimeout sets how long a dismissed agent gets to exit after being
+// asked to, and then to handle SIGTERM, before its processes are killed.
+func WithStopTimeout(d time.Duration) Option {
+	return func(o *Orchestrator) { o.stopTimeout = d }
+}
+
+// AgentForceKilledMsg reports that a dismissed agent ignored /exit and its
+// processes were killed with Signal ("SIGTERM" or "SIGKILL").
+type AgentForceKilledMsg struct {
+	AgentID string
+	Branch  string
+	Signal  string
+	Error   string
+}
+
+// stopAgent asks a busy agent to quit with Ctrl+C and /exit, then waits
+// for its pane's processes to exit. Processes still running after the stop
+// timeout get SIGTERM, and SIGKILL if that times out too, so killing the
+// window afterwards leaves nothing behind. It returns the signal the
+// processes had to be sent, or "" if they exited by themselves.
+func (o *Orchestrator) stopAgent(a *agent.Agent) string {
+	if a.TmuxPaneID == "" || !o.tmux.PaneExistsInWindow(a.TmuxPaneID, a.TmuxWindow) {
+		return ""
+	}
+	if signal, ok := o.takeStopped(a.ID); ok {
+		return signal
+	}
+	status := a.GetStatus()
+	if status != agent.StatusRunning && status != agent.StatusWaiting {
+		return ""
+	}
+	// The PID is read first: once the agent exits, tmux may close the pane.
+	pid, err := o.tmux.PanePID(a.TmuxPaneID)
+	if err == nil && pid <= 1 {
+		// Signalling group 0 or 1 would hit mastermind or everything.
+		err = fmt.Errorf("invalid pane pid %d", pid)
+	}
+	o.tmux.SendKeys(a.TmuxPaneID, "C-c")
+	o.tmux.SendKeys(a.TmuxPaneID, "/exit", "Enter")
+	if err != nil {
+		// Without the PID all we can do is give the agent a moment.
+		slog.Warn("failed to read agent pane PID", "id", a.ID, "pane", a.TmuxPaneID, "error", err)
+		time.Sleep(500 * time.Millisecond)
+		return ""
+	}
+	if o.waitExit(pid) {
+		return ""
+	}
+	for _, sig := range []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL} {
+		name := signalName(sig)
+		slog.Warn("agent did not exit, signalling its processes", "id", a.ID, "pgid", pid, "signal", name)
+		if err := o.procs.Signal(pid, sig); err != nil {
+			o.sendForceKilled(a, name, err)
+			return name
+		}
+		if o.waitExit(pid) {
+			o.sendForceKilled(a, name, nil)
+			return name
+		}
+	}
+	err = fmt.Errorf("processes still running after SIGKILL")
+	o.sendForceKilled(a, "SIGKILL", err)
+	return "SIGKILL"
+}
+
+// stopAgents stops the agents with the given IDs in parallel, as each may
+// take up to twice the stop timeout to exit. The signals they had to be
+// sent are kept for the stopAgent call of their dismissal.
+func (o *Orchestrator) stopAgents(ids []string) {
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		a, ok := o.store.Get(id)
+		if !ok {
+			continue
+		}
+		if a.TmuxPaneID != "" {
+			o.monitor.Remove(a.TmuxPaneID)
+		}
+		wg.Go(func() {
+			signal := o.stopAgent(a)
+			o.stoppedMu.Lock()
+			defer o.stoppedMu.Unlock()
+			if o.stopped == nil {
+				o.stopped = make(map[string]string)
+			}
+			o.stopped[a.ID] = signal
+		})
+	}
+	wg.Wait()
+}
+
+// takeStopped returns, and forgets, the signal stopAgents had to send
+// agent id's processes; ok is false if it didn't stop the agent.
+func (o *Orchestrator) takeStopped(id string) (signal string, ok bool) {
+	o.stoppedMu.Lock()
+	defer o.stoppedMu.Unlock()
+	signal, ok = o.stopped[id]
+	delete(o.stopped, id)
+	return signal, ok
+}
+
+// waitExit polls until the process group pgid is gone or the stop timeout
+// passes, reporting whether it is gone.
+func (o *Orchestrator) waitExit(pgid int) bool {
+	deadline := time.Now().Add(o.stopTimeout)
+	for o.procs.Alive(pgid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(stopPoll)
+	}
+	return true
+}
+
+func (o *Orchestrator) sendForceKilled(a *agent.Agent, signal string, err error) {
+	msg := AgentForceKilledMsg{AgentID: a.ID, Branch: a.Branch, Signal: signal}
+	if err != nil {
+		msg.Error = err.Error()
+		slog.Warn("failed to kill agent processes", "id", a.ID, "signal", signal, "error", err)
+	} else {
+		slog.Info("agent processes killed", "id", a.ID, "signal", signal)
+	}
+	if o.program != nil {
+		o.program.Send(msg)
+	}
+}
+
+func signalName(sig syscall.Signal) string {
+	if sig == syscall.SIGKILL {
+		return "SIGKILL"
+	}
+	return "SIGTERM"
+}
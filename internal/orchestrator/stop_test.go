@@ -0,0 +1,174 @@
+package orchestrator
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// mockProcs is a process group that exits after the first signal in
+// exitOn, or when asked to with exitAfter polls.
+type mockProcs struct {
+	mu        sync.Mutex
+	exitOn    []syscall.Signal
+	exitAfter int // Alive calls before the group exits by itself; 0 never
+	polls     int
+	gone      bool
+	signals   []syscall.Signal
+	// orphans run in the worktree until killed with a signal in exitOn.
+	orphans []Process
+	killed  []int
+}
+
+func (m *mockProcs) Alive(pgid int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.polls++
+	if m.exitAfter > 0 && m.polls >= m.exitAfter {
+		m.gone = true
+	}
+	return !m.gone
+}
+
+func (m *mockProcs) Signal(pgid int, sig syscall.Signal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.signals = append(m.signals, sig)
+	if slices.Contains(m.exitOn, sig) {
+		m.gone = true
+	}
+	return nil
+}
+
+func (m *mockProcs) InDir(dir string) ([]Process, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.orphans), nil
+}
+
+func (m *mockProcs) SignalPID(pid int, sig syscall.Signal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.killed = append(m.killed, pid)
+	if slices.Contains(m.exitOn, sig) {
+		m.orphans = slices.DeleteFunc(m.orphans, func(p Process) bool { return p.PID == pid })
+	}
+	return nil
+}
+
+func newStopOrch(t *testing.T, procs *mockProcs) (*Orchestrator, *agent.Agent) {
+	t.Helper()
+	mt := &mockTmux{paneExistsResult: true, panePID: 4242}
+	o := New(context.Background(), agent.NewStore(), "/repo", "test-session", t.TempDir(),
+		WithGit(&mockGit{}),
+		WithTmux(mt),
+		WithMonitor(&mockMonitor{}),
+		WithProcesses(procs),
+		WithStopTimeout(20*time.Millisecond),
+	)
+	a := agent.NewAgent("feat/stuck", "main", "/wt", "@1", "%1", "claude")
+	o.store.Add(a)
+	return o, a
+}
+
+func TestStopAgent_ExitsByItself(t *testing.T) {
+	procs := &mockProcs{exitAfter: 2}
+	o, a := newStopOrch(t, procs)
+
+	if got := o.stopAgent(a); got != "" {
+		t.Errorf("stopAgent = %q, want no signal", got)
+	}
+	if len(procs.signals) != 0 {
+		t.Errorf("signals = %v, want none", procs.signals)
+	}
+}
+
+func TestStopAgent_TerminatesThenKills(t *testing.T) {
+	procs := &mockProcs{exitOn: []syscall.Signal{syscall.SIGKILL}}
+	o, a := newStopOrch(t, procs)
+
+	if got := o.stopAgent(a); got != "SIGKILL" {
+		t.Errorf("stopAgent = %q, want SIGKILL", got)
+	}
+	want := []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL}
+	if !slices.Equal(procs.signals, want) {
+		t.Errorf("signals = %v, want %v", procs.signals, want)
+	}
+}
+
+func TestStopAgent_SkipsIdleAgent(t *testing.T) {
+	procs := &mockProcs{}
+	o, a := newStopOrch(t, procs)
+	a.SetStatus(agent.StatusDone)
+
+	if got := o.stopAgent(a); got != "" || procs.polls != 0 {
+		t.Errorf("stopAgent = %q after %d polls, want an idle agent left to the window kill", got, procs.polls)
+	}
+}
+
+func TestStopAgent_ReadsPIDBeforeExit(t *testing.T) {
+	o, a := newStopOrch(t, &mockProcs{exitAfter: 1})
+	mt := o.tmux.(*mockTmux)
+
+	o.stopAgent(a)
+	pid := slices.Index(mt.calls, "PanePID:%1")
+	keys := slices.Index(mt.calls, "SendKeys:%1")
+	if pid < 0 || keys < 0 || pid > keys {
+		t.Errorf("calls = %v, want the pane PID read before /exit is sent", mt.calls)
+	}
+}
+
+// gatheringProcs are process groups that exit once n agents are waiting
+// for theirs at the same time.
+type gatheringProcs struct {
+	mockProcs
+	n       int
+	mu      sync.Mutex
+	waiting int
+	all     chan struct{}
+	once    sync.Once
+}
+
+func (g *gatheringProcs) Alive(pgid int) bool {
+	g.mu.Lock()
+	g.waiting++
+	if g.waiting >= g.n {
+		g.once.Do(func() { close(g.all) })
+	}
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.waiting--
+		g.mu.Unlock()
+	}()
+	select {
+	case <-g.all:
+		return false
+	case <-time.After(100 * time.Millisecond):
+		return true
+	}
+}
+
+func TestDismissAgents_StopsInParallel(t *testing.T) {
+	procs := &gatheringProcs{n: 2, all: make(chan struct{})}
+	o, a := newStopOrch(t, &procs.mockProcs)
+	o.procs = procs
+	b := agent.NewAgent("feat/stuck2", "main", "/wt2", "@2", "%2", "claude")
+	o.store.Add(b)
+
+	msg := o.DismissAgents([]string{a.ID, b.ID}, false)
+	if len(msg.Dismissed) != 2 {
+		t.Fatalf("DismissAgents = %+v, want both dismissed", msg)
+	}
+	if len(procs.signals) != 0 {
+		t.Errorf("signals = %v, want both agents to exit while stopped together", procs.signals)
+	}
+	if len(o.stopped) != 0 {
+		t.Errorf("stopped = %v, want every stop used by its dismissal", o.stopped)
+	}
+}
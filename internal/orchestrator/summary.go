@@ -0,0 +1,165 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/hook"
+)
+
+// summaryCommits is how many commit subjects a WorkSummary lists.
+const summaryCommits = 5
+
+// squashCommits is how many commit subjects a generated squash message
+// lists.
+const squashCommits = 50
+
+// WorkSummary is what an agent has done, shown before it is dismissed so
+// that work isn't thrown away by accident.
+type WorkSummary struct {
+	// Commits are the newest commits on the agent's branch that its base
+	// doesn't have, as "<hash> <subject>"; CommitCount counts all of them.
+	Commits     []string
+	CommitCount int
+	// Unmerged counts the commits on the branch that no other branch or
+	// tag has, which deleting the branch would lose.
+	Unmerged int
+	// Diff is the diff stat of the worktree against its base, including
+	// uncommitted changes to tracked files.
+	Diff        git.DiffStat
+	Uncommitted bool
+	CostUSD     float64
+	HasCost     bool
+	// HookStatus is the last hook status seen for the agent, or nil.
+	HookStatus *hook.StatusFile
+}
+
+// WorkSummary summarises the commits, changes and cost of agent id. Git
+// failures leave the affected fields empty rather than failing the
+// summary, so a broken worktree can still be dismissed.
+func (o *Orchestrator) WorkSummary(id string) (WorkSummary, error) {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return WorkSummary{}, fmt.Errorf("agent %s not found", id)
+	}
+
+	var s WorkSummary
+	if base := a.GetBaseBranch(); base != "" {
+		commits, total, err := o.git.Commits(o.repoPath, base, a.Branch, summaryCommits)
+		if err != nil {
+			slog.Warn("failed to list commits for summary", "agent", id, "error", err)
+		}
+		s.Commits, s.CommitCount = commits, total
+		if diff, err := o.git.WorktreeDiffStat(a.WorktreePath, base); err != nil {
+			slog.Warn("failed to diff worktree for summary", "agent", id, "error", err)
+		} else {
+			s.Diff = diff
+		}
+	}
+	if n, err := o.git.UnmergedCommits(o.repoPath, a.Branch); err != nil {
+		slog.Warn("failed to count unmerged commits for summary", "agent", id, "error", err)
+	} else {
+		s.Unmerged = n
+	}
+	s.Uncommitted = o.git.HasChanges(a.WorktreePath)
+
+	if total, ok := a.TeamCostUSD(); ok {
+		s.CostUSD, s.HasCost = total, true
+	} else if sd := a.GetStatuslineData(); sd != nil {
+		s.CostUSD, s.HasCost = sd.CostUSD, true
+	}
+	s.HookStatus = a.GetHookStatus()
+	return s, nil
+}
+
+// PreviewMerge returns what merging agent id would land on its base
+// branch, and whether base can be fast-forwarded to it.
+func (o *Orchestrator) PreviewMerge(id string) (git.MergePreview, error) {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return git.MergePreview{}, fmt.Errorf("agent %s not found", id)
+	}
+	base := a.GetBaseBranch()
+	if base == "" {
+		return git.MergePreview{}, fmt.Errorf("agent %s has no base branch to merge into", id)
+	}
+	return o.git.PreviewMerge(o.repoPath, base, a.Branch)
+}
+
+// DiffAgent returns the diff of agent id's worktree against its base:
+// its commits and uncommitted changes, including untracked files. Unlike
+// PreviewAgent it never touches the main worktree.
+func (o *Orchestrator) DiffAgent(id string) (string, error) {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return "", fmt.Errorf("agent %s not found", id)
+	}
+	base := a.GetBaseBranch()
+	if base == "" {
+		return "", fmt.Errorf("agent %s has no base branch to diff against", id)
+	}
+	diff, err := o.git.WorktreeDiff(a.WorktreePath, base)
+	if err != nil {
+		return "", err
+	}
+	return string(diff), nil
+}
+
+// SquashMessage generates the commit message of a squash merge of agent
+// id: the subject of its only commit, or a subject naming the branch
+// followed by the subjects of its commits, oldest first.
+func (o *Orchestrator) SquashMessage(id string) (string, error) {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return "", fmt.Errorf("agent %s not found", id)
+	}
+	subjects, total, err := o.commitSubjects(a)
+	if err != nil {
+		return "", err
+	}
+	if len(subjects) == 1 {
+		return subjects[0], nil
+	}
+	msg := fmt.Sprintf("Squash %s (%d commits)", a.Branch, total)
+	if list := commitList(subjects, total); list != "" {
+		msg += "\n\n" + list
+	}
+	return msg, nil
+}
+
+// commitSubjects returns the subjects of up to squashCommits of the
+// newest commits on the agent's branch that its base lacks, oldest first,
+// and how many commits that is in all.
+func (o *Orchestrator) commitSubjects(a *agent.Agent) ([]string, int, error) {
+	base := a.GetBaseBranch()
+	if base == "" {
+		return nil, 0, fmt.Errorf("agent %s has no base branch to merge into", a.ID)
+	}
+	commits, total, err := o.git.Commits(o.repoPath, base, a.Branch, squashCommits)
+	if err != nil {
+		return nil, 0, err
+	}
+	subjects := make([]string, len(commits))
+	for i, c := range commits {
+		// Commits are "<hash> <subject>", newest first.
+		_, subject, _ := strings.Cut(c, " ")
+		subjects[len(commits)-1-i] = subject
+	}
+	return subjects, total, nil
+}
+
+// commitList formats commit subjects as a bulleted list, noting the total
+// commits it leaves out.
+func commitList(subjects []string, total int) string {
+	var b strings.Builder
+	for _, s := range subjects {
+		fmt.Fprintf(&b, "* %s\n", s)
+	}
+	if more := total - len(subjects); more > 0 {
+		fmt.Fprintf(&b, "* ... and %d earlier commits\n", more)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
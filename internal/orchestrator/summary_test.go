@@ -0,0 +1,90 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/hook"
+)
+
+func TestWorkSummary(t *testing.T) {
+	mg := &mockGit{
+		commitsResult:    []string{"abc1234 feat b", "def5678 feat a"},
+		commitsTotal:     2,
+		unmergedCommits:  1,
+		diffStatResult:   git.DiffStat{Files: 3, Insertions: 10, Deletions: 2},
+		hasChangesResult: true,
+	}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	a.SetStatuslineData(&agent.StatuslineData{CostUSD: 1.25})
+	a.SetHookStatus(&hook.StatusFile{Status: "waiting", Event: "Stop"})
+
+	s, err := o.WorkSummary(a.ID)
+	if err != nil {
+		t.Fatalf("WorkSummary: %v", err)
+	}
+	if !mg.hasCalled("Commits:main..feat/x") {
+		t.Error("expected the commits since main to be listed")
+	}
+	if s.CommitCount != 2 || len(s.Commits) != 2 {
+		t.Errorf("commits = %d %v, want 2", s.CommitCount, s.Commits)
+	}
+	if s.Unmerged != 1 {
+		t.Errorf("unmerged = %d, want 1", s.Unmerged)
+	}
+	if s.Diff.Files != 3 || !s.Uncommitted {
+		t.Errorf("diff = %+v uncommitted = %v", s.Diff, s.Uncommitted)
+	}
+	if !s.HasCost || s.CostUSD != 1.25 {
+		t.Errorf("cost = %v %v, want 1.25", s.CostUSD, s.HasCost)
+	}
+	if s.HookStatus == nil || s.HookStatus.Event != "Stop" {
+		t.Errorf("hook status = %+v, want Stop", s.HookStatus)
+	}
+
+	if _, err := o.WorkSummary("nope"); err == nil {
+		t.Error("WorkSummary of an unknown agent succeeded")
+	}
+}
+
+func TestPreviewMerge(t *testing.T) {
+	mg := &mockGit{previewMergeResult: git.MergePreview{FastForward: true}}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+
+	p, err := o.PreviewMerge(a.ID)
+	if err != nil || !p.FastForward {
+		t.Fatalf("PreviewMerge = %+v, %v", p, err)
+	}
+	if !mg.hasCalled("PreviewMerge:main...feat/x") {
+		t.Error("expected the diff of feat/x since main")
+	}
+}
+
+func TestDiffAgent(t *testing.T) {
+	mg := &mockGit{worktreeDiffResult: "diff --git a/x b/x\n"}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+
+	diff, err := o.DiffAgent(a.ID)
+	if err != nil || diff != "diff --git a/x b/x\n" {
+		t.Fatalf("DiffAgent = %q, %v", diff, err)
+	}
+	if !mg.hasCalled("WorktreeDiff:" + a.WorktreePath + ":main") {
+		t.Errorf("calls = %v, want the worktree diffed against main", mg.calls)
+	}
+	if mg.hasCalled("CheckoutBranch:feat/x") {
+		t.Error("the diff should leave the main worktree alone")
+	}
+}
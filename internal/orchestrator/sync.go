@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// SyncAgent brings the latest base into agent id's branch, so that it
+// doesn't drift from base until merge time. The branch is rebased onto
+// base when merges default to MergeRebase, and base is merged into it
+// otherwise. The conflicts of a merge are left to resolve as those of
+// MergeAgent are, after which the agent goes back to its status. Base
+// itself is never changed.
+func (o *Orchestrator) SyncAgent(id string) MergeResultMsg {
+	msg := o.syncAgent(id)
+	msg.Synced = true
+	return msg
+}
+
+func (o *Orchestrator) syncAgent(id string) MergeResultMsg {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return MergeResultMsg{AgentID: id, Error: "agent not found"}
+	}
+	if a.ReadOnly {
+		return MergeResultMsg{AgentID: id, Error: fmt.Sprintf("agent %s is read-only and cannot be synced", id)}
+	}
+	base := a.GetBaseBranch()
+	if base == "" {
+		return MergeResultMsg{AgentID: id, Error: fmt.Sprintf("agent %s has no base branch to sync with", id)}
+	}
+	status := a.GetStatus()
+	if !Syncable(status) {
+		return MergeResultMsg{AgentID: id, Error: fmt.Sprintf("agent %s cannot be synced while %s", id, status)}
+	}
+	if o.git.HasChanges(a.WorktreePath) {
+		return MergeResultMsg{AgentID: id, Error: "uncommitted changes in worktree — commit or discard them first"}
+	}
+
+	strategy := o.MergeDefaults().Strategy
+	if strategy == MergeRebase {
+		conflicted, err := o.git.Rebase(a.WorktreePath, base)
+		if err != nil {
+			return MergeResultMsg{AgentID: id, Error: fmt.Sprintf("rebase: %v", err)}
+		}
+		if conflicted {
+			return MergeResultMsg{AgentID: id, Error: ErrRebaseConflicts.Error()}
+		}
+	} else {
+		conflicted, err := o.git.MergeInWorktree(a.WorktreePath, base, o.rerere)
+		if err != nil {
+			return MergeResultMsg{AgentID: id, Error: fmt.Sprintf("merge: %v", err)}
+		}
+		if conflicted {
+			slog.Info("sync with base has conflicts", "id", id, "branch", a.Branch, "base", base)
+			a.SetSyncStatus(status)
+			a.SetStatus(agent.StatusConflicts)
+			return o.conflictResult(a)
+		}
+	}
+	slog.Info("agent synced with base", "id", id, "branch", a.Branch, "base", base, "strategy", strategy)
+	return MergeResultMsg{AgentID: id, Success: true}
+}
+
+// Syncable reports whether an agent in status can be synced with its
+// base: it is working or done, and not being reviewed, previewed or
+// merged.
+func Syncable(status agent.Status) bool {
+	switch status {
+	case agent.StatusRunning, agent.StatusWaiting, agent.StatusDone, agent.StatusReviewReady, agent.StatusReviewed:
+		return true
+	}
+	return false
+}
+
+// finishConflictedSync ends the sync of an agent whose conflicts with
+// base have been resolved and committed on its branch.
+func (o *Orchestrator) finishConflictedSync(a *agent.Agent) MergeResultMsg {
+	status := a.GetSyncStatus()
+	a.SetSyncStatus("")
+	a.SetStatus(status)
+	slog.Info("agent synced with base after resolving conflicts", "id", a.ID, "branch", a.Branch, "base", a.GetBaseBranch())
+	return MergeResultMsg{AgentID: a.ID, Success: true, Synced: true}
+}
@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func TestSyncAgent_Merge(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123"}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+	a.SetStatus(agent.StatusReviewReady)
+
+	result := o.SyncAgent(a.ID)
+	if !result.Success || !result.Synced {
+		t.Fatalf("SyncAgent = %+v, want a successful sync", result)
+	}
+	if !mg.hasCalled("MergeInWorktree:main") {
+		t.Errorf("calls = %v, want base merged into the agent's worktree", mg.calls)
+	}
+	if mg.hasCalled("MergeFFOnly:feat/x") || mg.hasCalled("UpdateBranchRef:main") {
+		t.Error("base must not be moved")
+	}
+	if a.GetStatus() != agent.StatusReviewReady {
+		t.Errorf("status = %s, want it unchanged", a.GetStatus())
+	}
+}
+
+func TestSyncAgent_Rebase(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123"}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	o.mergeDefaults.Strategy = MergeRebase
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	if result := o.SyncAgent(a.ID); !result.Success {
+		t.Fatalf("SyncAgent = %+v, want success", result)
+	}
+	if !mg.hasCalled("Rebase:"+a.WorktreePath+":main") || mg.hasCalled("MergeInWorktree:main") {
+		t.Errorf("calls = %v, want the branch rebased onto base", mg.calls)
+	}
+
+	mg.rebaseConflict = true
+	if result := o.SyncAgent(a.ID); result.Success || result.Error != ErrRebaseConflicts.Error() {
+		t.Errorf("SyncAgent = %+v, want the rebase conflict reported", result)
+	}
+}
+
+func TestSyncAgent_Refuses(t *testing.T) {
+	mg := &mockGit{hasChangesResult: true}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+
+	if result := o.SyncAgent(a.ID); result.Error == "" {
+		t.Error("expected error for uncommitted changes")
+	}
+	mg.hasChangesResult = false
+	a.SetStatus(agent.StatusPreviewing)
+	if result := o.SyncAgent(a.ID); result.Error == "" {
+		t.Error("expected error for a previewed agent")
+	}
+	if mg.hasCalled("MergeInWorktree:main") {
+		t.Error("nothing should have been merged")
+	}
+}
+
+func TestSyncAgent_ConflictsResolved(t *testing.T) {
+	mg := &mockGit{headCommitResult: "abc123", mergeInWorktreeConflict: true, conflictFilesResult: []string{"a.go"}}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	o.SpawnAgent("feat/x", "main", true, "claude")
+	a := o.store.All()[0]
+	a.SetStatus(agent.StatusReviewed)
+
+	result := o.SyncAgent(a.ID)
+	if !result.Conflict || !result.Synced || len(result.ConflictFiles) != 1 {
+		t.Fatalf("SyncAgent = %+v, want the conflicts reported", result)
+	}
+	if a.GetStatus() != agent.StatusConflicts {
+		t.Fatalf("status = %s, want conflicts", a.GetStatus())
+	}
+
+	// Resolving the conflicts ends the sync, without merging into base.
+	o.handleLazygitClosed(a, agent.StatusConflicts)
+	if a.GetStatus() != agent.StatusReviewed || a.GetSyncStatus() != "" {
+		t.Errorf("status = %s, sync status = %q, want the agent back to reviewed", a.GetStatus(), a.GetSyncStatus())
+	}
+	if _, ok := o.store.Get(a.ID); !ok {
+		t.Error("agent was removed as if merged")
+	}
+	if mg.hasCalled("MergeFFOnly:feat/x") || mg.hasCalled("UpdateBranchRef:main") {
+		t.Error("base must not be moved")
+	}
+}
@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// teamBriefFile is the scaffolding written into a team lead's worktree:
+// the team's name, its task and how it is expected to work.
+const teamBriefFile = ".mastermind-team.md"
+
+// AsTeam spawns the agent as the lead of a Claude Code agent team working
+// on task. Agent teams are enabled in the worktree whatever the
+// agent_teams setting, and the lead is told to form the team, named after
+// the branch, and split the task between its teammates.
+func AsTeam(task string) SpawnOption {
+	return func(r *spawnRequest) {
+		r.team = teamName(r.branch)
+		r.teamTask = task
+		if r.files == nil {
+			r.files = map[string]string{}
+		}
+		r.files[teamBriefFile] = teamBrief(r.team, r.branch, task)
+		r.prompt = teamPrompt(r.team)
+	}
+}
+
+// teamName derives an agent team's name from the lead's branch, e.g.
+// "feat-login" for feat/login.
+func teamName(branch string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, branch)
+	return strings.Trim(name, "-")
+}
+
+// teamBrief is the content of teamBriefFile.
+func teamBrief(name, branch, task string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Team %s\n\n", name)
+	fmt.Fprintf(&b, "## Task\n\n%s\n\n", strings.TrimSpace(task))
+	b.WriteString("## Working agreement\n\n")
+	fmt.Fprintf(&b, "- All work lands on branch `%s` in this worktree; teammates commit small, focused changes.\n", branch)
+	b.WriteString("- Each task has one owner. Claim it before starting and mark it completed when done.\n")
+	b.WriteString("- Teammates working in parallel must not edit the same files.\n")
+	b.WriteString("- The lead reviews the combined result and reports when the whole task is done.\n")
+	return b.String()
+}
+
+// teamPrompt is the initial task of a team lead.
+func teamPrompt(name string) string {
+	return fmt.Sprintf("You are the lead of an agent team. Read %s for the task and working agreement. "+
+		"Create the team with the name %q, break the task into independent tasks, spawn teammates to work on them "+
+		"and coordinate them until the task is done. Do not commit %s.", teamBriefFile, name, teamBriefFile)
+}
+
+// KillTeammate kills the tmux pane of the named teammate in the agent team
+// agent id leads, e.g. because it hangs. The lead sees the teammate stop
+// responding and can reassign its tasks.
+func (o *Orchestrator) KillTeammate(id, name string) error {
+	a, paneID, err := o.teammatePane(id, name)
+	if err != nil {
+		return err
+	}
+	if err := o.tmux.KillPane(paneID); err != nil {
+		return fmt.Errorf("kill teammate %s: %w", name, err)
+	}
+	a.SetTeammateAction(name, agent.TeammateAction{Action: agent.TeammateKilled, At: time.Now()})
+	o.store.MarkDirty()
+	slog.Info("teammate killed", "agent", id, "teammate", name, "pane", paneID)
+	return nil
+}
+
+// RestartTeammate restarts the named teammate's tmux pane with the
+// command it was started with.
+func (o *Orchestrator) RestartTeammate(id, name string) error {
+	a, paneID, err := o.teammatePane(id, name)
+	if err != nil {
+		return err
+	}
+	if err := o.tmux.RespawnPane(paneID); err != nil {
+		return fmt.Errorf("restart teammate %s: %w", name, err)
+	}
+	a.SetTeammateAction(name, agent.TeammateAction{Action: agent.TeammateRestarted, At: time.Now()})
+	o.store.MarkDirty()
+	slog.Info("teammate restarted", "agent", id, "teammate", name, "pane", paneID)
+	return nil
+}
+
+// teammatePane finds the tmux pane of the named teammate in the agent team
+// agent id leads.
+func (o *Orchestrator) teammatePane(id, name string) (*agent.Agent, string, error) {
+	a, ok := o.store.Get(id)
+	if !ok {
+		return nil, "", fmt.Errorf("agent %s not found", id)
+	}
+	info := a.GetTeam()
+	if info == nil {
+		return nil, "", fmt.Errorf("agent %s leads no agent team", id)
+	}
+	for _, m := range info.Members {
+		if m.Name != name || m.AgentType == "lead" {
+			continue
+		}
+		if m.TmuxPaneID == "" {
+			return nil, "", fmt.Errorf("teammate %s runs in-process and has no pane; use teammate_mode = \"tmux\"", name)
+		}
+		return a, m.TmuxPaneID, nil
+	}
+	return nil, "", fmt.Errorf("agent %s has no teammate %q", id, name)
+}
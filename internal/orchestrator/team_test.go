@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/team"
+)
+
+func TestSpawnAgent_AsTeam(t *testing.T) {
+	wt := t.TempDir()
+	mg := &mockGit{createWorktreeResult: wt}
+	mt := &mockTmux{windowIDForPane: "@1"}
+	o := newTestOrch(t, mg, mt, &mockMonitor{})
+	o.agentTeams = false
+
+	if err := o.SpawnAgent("feat/Login", "main", true, "claude", AsTeam("Add OAuth login")); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	if a.Team != "feat-login" || a.Persisted().Team != "feat-login" {
+		t.Errorf("team = %q, want feat-login", a.Team)
+	}
+	if cmd := strings.Join(mt.newWindowCommand, " "); !strings.Contains(cmd, `"feat-login"`) {
+		t.Errorf("command = %q, want the team-formation prompt", cmd)
+	}
+	brief, err := os.ReadFile(filepath.Join(wt, teamBriefFile))
+	if err != nil || !strings.Contains(string(brief), "Add OAuth login") {
+		t.Errorf("team brief = %q (%v), want the task", brief, err)
+	}
+	settings, _ := os.ReadFile(filepath.Join(wt, ".claude", "settings.local.json"))
+	if !strings.Contains(string(settings), "CLAUDE_CODE_EXPERIMENTAL_AGENT_TEAMS") {
+		t.Errorf("settings = %s, want agent teams enabled even with agent_teams off", settings)
+	}
+}
+
+func TestSpawnAgent_AsTeamNeedsClaude(t *testing.T) {
+	mg := &mockGit{}
+	o := newTestOrch(t, mg, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+
+	err := o.SpawnAgent("feat/x", "main", true, "opencode", AsTeam("task"))
+	if err == nil || !strings.Contains(err.Error(), "Claude Code") {
+		t.Fatalf("SpawnAgent = %v, want an error", err)
+	}
+	if mg.hasCalled("CreateBranch:feat/x") || mg.hasCalled("CreateWorktree:feat/x") {
+		t.Errorf("calls = %v, want the team checked before touching git", mg.calls)
+	}
+}
+
+func TestKillAndRestartTeammate(t *testing.T) {
+	mt := &mockTmux{windowIDForPane: "@1"}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	a := o.store.All()[0]
+	a.SetTeam(&team.TeamInfo{Members: []team.Member{
+		{Name: "lead", AgentType: "lead", TmuxPaneID: "%1"},
+		{Name: "backend", AgentType: "teammate", TmuxPaneID: "%7"},
+		{Name: "docs", AgentType: "teammate"},
+	}})
+
+	if err := o.KillTeammate(a.ID, "backend"); err != nil {
+		t.Fatalf("KillTeammate: %v", err)
+	}
+	if !mt.hasCalled("KillPane:%7") {
+		t.Error("expected the teammate's pane to be killed")
+	}
+	if act, _ := a.GetTeammateAction("backend"); act.Action != agent.TeammateKilled {
+		t.Errorf("action = %q, want killed", act.Action)
+	}
+
+	if err := o.RestartTeammate(a.ID, "backend"); err != nil {
+		t.Fatalf("RestartTeammate: %v", err)
+	}
+	if !mt.hasCalled("RespawnPane:%7") {
+		t.Error("expected the teammate's pane to be respawned")
+	}
+	if act, _ := a.GetTeammateAction("backend"); act.Action != agent.TeammateRestarted {
+		t.Errorf("action = %q, want restarted", act.Action)
+	}
+
+	for _, name := range []string{"docs", "lead", "nobody"} {
+		if err := o.KillTeammate(a.ID, name); err == nil {
+			t.Errorf("KillTeammate(%q) succeeded, want an error", name)
+		}
+	}
+}
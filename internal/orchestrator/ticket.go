@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"log/slog"
+
+	"github.com/simonbystrom/mastermind/internal/ticket"
+)
+
+// WithTickets moves linked tickets in tracker to inProgress when their
+// agent is spawned and to inReview when it is ready for review. An empty
+// state skips that transition.
+func WithTickets(tracker ticket.Tracker, inProgress, inReview string) Option {
+	return func(o *Orchestrator) {
+		o.tracker = tracker
+		o.ticketInProgress = inProgress
+		o.ticketInReview = inReview
+	}
+}
+
+// WithTicket links the spawned agent to a ticket. title may be empty.
+func WithTicket(id, title string) SpawnOption {
+	return func(r *spawnRequest) {
+		r.ticket = id
+		r.ticketTitle = title
+	}
+}
+
+// TicketTitle looks up a ticket's title. It returns "" without error when
+// no tracker is configured.
+func (o *Orchestrator) TicketTitle(id string) (string, error) {
+	if o.tracker == nil {
+		return "", nil
+	}
+	return o.tracker.Title(id)
+}
+
+// moveTicket transitions a ticket to state in the background. Failures
+// are logged; they never hold up the agent.
+func (o *Orchestrator) moveTicket(id, state string) {
+	if o.tracker == nil || id == "" || state == "" {
+		return
+	}
+	go func() {
+		if err := o.tracker.Transition(id, state); err != nil {
+			slog.Warn("ticket transition failed", "ticket", id, "state", state, "error", err)
+			return
+		}
+		slog.Info("ticket moved", "ticket", id, "state", state)
+	}()
+}
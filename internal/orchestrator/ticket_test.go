@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+type fakeTracker struct {
+	moved chan string // "ID:state"
+}
+
+func (f *fakeTracker) Title(id string) (string, error) { return "Add login page", nil }
+
+func (f *fakeTracker) Transition(id, state string) error {
+	f.moved <- id + ":" + state
+	return nil
+}
+
+func waitMoved(t *testing.T, f *fakeTracker) string {
+	t.Helper()
+	select {
+	case m := <-f.moved:
+		return m
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for ticket transition")
+		return ""
+	}
+}
+
+func TestSpawnAgent_WithTicket(t *testing.T) {
+	tr := &fakeTracker{moved: make(chan string, 2)}
+	mt := &mockTmux{windowIDForPane: "@1"}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	WithTickets(tr, "In Progress", "In Review")(o)
+
+	if err := o.SpawnAgent("eng-7-login", "main", true, "claude", WithTicket("ENG-7", "Add login page")); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+
+	agents := o.store.All()
+	if len(agents) != 1 || agents[0].Ticket != "ENG-7" {
+		t.Fatalf("agents = %+v", agents)
+	}
+	if got := waitMoved(t, tr); got != "ENG-7:In Progress" {
+		t.Errorf("transition = %q", got)
+	}
+	i := slices.Index(mt.newWindowCommand, "--append-system-prompt")
+	if i < 0 || !strings.Contains(mt.newWindowCommand[i+1], "ENG-7 (Add login page)") {
+		t.Errorf("command = %q, want ticket context", mt.newWindowCommand)
+	}
+
+	o.handleMonitorEvent(monitor.AgentFinished{AgentID: agents[0].ID, HasChanges: true})
+	if got := waitMoved(t, tr); got != "ENG-7:In Review" {
+		t.Errorf("transition = %q", got)
+	}
+}
+
+func TestSpawnAgent_NoTicketNoTransition(t *testing.T) {
+	tr := &fakeTracker{moved: make(chan string, 2)}
+	o := newTestOrch(t, &mockGit{}, &mockTmux{windowIDForPane: "@1"}, &mockMonitor{})
+	WithTickets(tr, "In Progress", "In Review")(o)
+
+	if err := o.SpawnAgent("feat/x", "main", true, "claude"); err != nil {
+		t.Fatalf("SpawnAgent: %v", err)
+	}
+	o.handleMonitorEvent(monitor.AgentFinished{AgentID: o.store.All()[0].ID, HasChanges: true})
+
+	select {
+	case m := <-tr.moved:
+		t.Errorf("unexpected transition %q", m)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
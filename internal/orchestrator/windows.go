@@ -0,0 +1,94 @@
+package orchestrator
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/tmux"
+)
+
+// statusGlyphs prefix agent window names when window status is enabled.
+// Agents waiting for permission use tmux.AttentionPrefix instead.
+var statusGlyphs = map[agent.Status]string{
+	agent.StatusRunning:     "✻",
+	agent.StatusWaiting:     "?",
+	agent.StatusReviewReady: "●",
+	agent.StatusReviewing:   "◐",
+	agent.StatusReviewed:    "◆",
+	agent.StatusPreviewing:  "◉",
+	agent.StatusConflicts:   "✖",
+	agent.StatusDone:        "✔",
+	agent.StatusOrphaned:    "○",
+}
+
+// windowName returns the tmux window name an agent should currently have.
+func (o *Orchestrator) windowName(a *agent.Agent, snap agent.AgentSnapshot) string {
+	if snap.Status == agent.StatusWaiting && snap.WaitingFor == "permission" && (o.markWindows || o.windowStatus) {
+		return tmux.AttentionPrefix + a.Branch
+	}
+	if o.windowStatus {
+		if glyph, ok := statusGlyphs[snap.Status]; ok {
+			return glyph + " " + a.Branch
+		}
+	}
+	return a.Branch
+}
+
+// branchFromWindowName strips a status prefix added by syncWindowNames.
+// Branch names cannot contain spaces, so a prefix is never ambiguous.
+func branchFromWindowName(name string) string {
+	if branch, ok := strings.CutPrefix(name, tmux.AttentionPrefix); ok {
+		return branch
+	}
+	for _, glyph := range statusGlyphs {
+		if branch, ok := strings.CutPrefix(name, glyph+" "); ok {
+			return branch
+		}
+	}
+	return name
+}
+
+// syncWindowNames renames agent windows whose status prefix is out of date.
+// Windows start out named after their branch at spawn.
+func (o *Orchestrator) syncWindowNames() {
+	if !o.markWindows && !o.windowStatus {
+		return
+	}
+	for _, a := range o.store.All() {
+		snap := a.Snapshot()
+		if snap.Status == agent.StatusDismissed || a.TmuxWindow == "" {
+			delete(o.windowNames, a.ID)
+			continue
+		}
+		current, ok := o.windowNames[a.ID]
+		if !ok {
+			current = a.Branch
+		}
+		name := o.windowName(a, snap)
+		if name == current {
+			continue
+		}
+		if err := o.tmux.RenameWindow(a.TmuxWindow, name); err != nil {
+			slog.Error("rename agent window failed", "id", a.ID, "error", err)
+			continue
+		}
+		o.windowNames[a.ID] = name
+	}
+}
+
+// resetWindowName strips a status prefix left on an agent's window by a
+// previous session, restoring the name it was spawned with.
+func (o *Orchestrator) resetWindowName(a *agent.Agent) {
+	name, err := o.tmux.CurrentWindowName(a.TmuxWindow)
+	if err != nil {
+		return
+	}
+	branch := branchFromWindowName(name)
+	if branch == name {
+		return
+	}
+	if err := o.tmux.RenameWindow(a.TmuxWindow, branch); err != nil {
+		slog.Warn("reset agent window name failed", "id", a.ID, "error", err)
+	}
+}
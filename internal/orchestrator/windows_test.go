@@ -0,0 +1,76 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/tmux"
+)
+
+func TestSyncWindowNames_MarkWindows(t *testing.T) {
+	mt := &mockTmux{}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	o.markWindows = true
+	a := agent.NewAgent("feat/perm", "main", "/wt", "@3", "%3", "claude")
+	o.store.Add(a)
+
+	o.syncWindowNames()
+	if len(mt.calls) != 0 {
+		t.Fatalf("running agent should keep its spawn name, got calls %v", mt.calls)
+	}
+
+	a.SetStatus(agent.StatusWaiting)
+	a.SetWaitingFor("permission")
+	o.syncWindowNames()
+	if !mt.hasCalled("RenameWindow:@3:" + tmux.AttentionPrefix + "feat/perm") {
+		t.Fatal("expected window to be marked while waiting for permission")
+	}
+
+	a.SetStatus(agent.StatusRunning)
+	a.SetWaitingFor("")
+	o.syncWindowNames()
+	if !mt.hasCalled("RenameWindow:@3:feat/perm") {
+		t.Error("expected mark to be removed once running again")
+	}
+}
+
+func TestSyncWindowNames_WindowStatus(t *testing.T) {
+	mt := &mockTmux{}
+	o := newTestOrch(t, &mockGit{}, mt, &mockMonitor{})
+	o.windowStatus = true
+	a := agent.NewAgent("feat-auth", "main", "/wt", "@4", "%4", "claude")
+	o.store.Add(a)
+
+	o.syncWindowNames()
+	if !mt.hasCalled("RenameWindow:@4:✻ feat-auth") {
+		t.Fatal("expected running glyph")
+	}
+
+	a.SetStatus(agent.StatusDone)
+	o.syncWindowNames()
+	o.syncWindowNames()
+	renames := 0
+	for _, c := range mt.calls {
+		if c == "RenameWindow:@4:✔ feat-auth" {
+			renames++
+		}
+	}
+	if renames != 1 {
+		t.Errorf("expected exactly one rename to the done glyph, got %d", renames)
+	}
+}
+
+func TestBranchFromWindowName(t *testing.T) {
+	tests := map[string]string{
+		"feat/x":                        "feat/x",
+		tmux.AttentionPrefix + "feat/x": "feat/x",
+		"✔ feat/x":                      "feat/x",
+		"✻ feat/x":                      "feat/x",
+		"mastermind *":                  "mastermind *",
+	}
+	for name, want := range tests {
+		if got := branchFromWindowName(name); got != want {
+			t.Errorf("branchFromWindowName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package playbook reads task playbooks: reusable agent tasks with a
+// prompt, a base branch, checks to run when the agent finishes and a
+// policy for merging it automatically.
+package playbook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+// MergePolicy says whether a playbook agent is merged without review.
+type MergePolicy string
+
+const (
+	// MergeNever leaves the agent for review, like any other agent.
+	MergeNever MergePolicy = "never"
+	// MergeOnChecks merges the agent once all its checks pass.
+	MergeOnChecks MergePolicy = "checks"
+)
+
+// Playbook is a task definition, read from a .yml/.yaml file or from a
+// .md file whose YAML front matter holds the fields and whose body is the
+// prompt.
+type Playbook struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Branch is the agent's branch; empty derives one from the name.
+	Branch string `yaml:"branch"`
+	// Base is the branch the agent starts from and merges into; empty
+	// asks for one in the spawn wizard and uses the current branch
+	// headlessly.
+	Base   string `yaml:"base"`
+	Prompt string `yaml:"prompt"`
+	// Checks are shell commands run in the worktree, in order, when the
+	// agent finishes.
+	Checks    []string    `yaml:"checks"`
+	AutoMerge MergePolicy `yaml:"auto_merge"`
+
+	// Path is the file the playbook was read from.
+	Path string `yaml:"-"`
+}
+
+// Load reads the playbook at path.
+func Load(path string) (Playbook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Playbook{}, err
+	}
+	p, err := parse(data, filepath.Ext(path))
+	if err != nil {
+		return Playbook{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	p.Path = path
+	return p, nil
+}
+
+func parse(data []byte, ext string) (Playbook, error) {
+	var p Playbook
+	switch ext {
+	case ".yml", ".yaml":
+		if err := yaml.Unmarshal(data, &p); err != nil {
+			return p, err
+		}
+	case ".md":
+		front, body, err := splitFrontMatter(data)
+		if err != nil {
+			return p, err
+		}
+		if err := yaml.Unmarshal(front, &p); err != nil {
+			return p, err
+		}
+		if p.Prompt == "" {
+			p.Prompt = body
+		}
+	default:
+		return p, fmt.Errorf("unsupported playbook format %q", ext)
+	}
+
+	p.Prompt = strings.TrimSpace(p.Prompt)
+	if p.Prompt == "" {
+		return p, errors.New("playbook has no prompt")
+	}
+	switch p.AutoMerge {
+	case "":
+		p.AutoMerge = MergeNever
+	case MergeNever, MergeOnChecks:
+	default:
+		return p, fmt.Errorf("unknown auto_merge policy %q (want %q or %q)", p.AutoMerge, MergeNever, MergeOnChecks)
+	}
+	if p.Branch != "" {
+		if err := git.ValidateBranchName(p.Branch); err != nil {
+			return p, fmt.Errorf("invalid branch: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// splitFrontMatter splits a markdown file into the YAML between its
+// leading "---" lines and the body after them. A file without front
+// matter is all body.
+func splitFrontMatter(data []byte) (front []byte, body string, err error) {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(data, []byte("---\n")) {
+		return nil, string(data), nil
+	}
+	rest := data[len("---\n"):]
+	end := bytes.Index(rest, []byte("\n---\n"))
+	switch {
+	case bytes.HasPrefix(rest, []byte("---\n")):
+		return nil, string(rest[len("---\n"):]), nil
+	case end >= 0:
+		return rest[:end+1], string(rest[end+len("\n---\n"):]), nil
+	case bytes.HasSuffix(rest, []byte("\n---")):
+		return rest[:len(rest)-len("---")], "", nil
+	}
+	return nil, "", errors.New("front matter is not closed with ---")
+}
+
+// List returns the playbooks in dir sorted by name. A missing directory
+// has none. Files that fail to parse are skipped and reported in the
+// returned error alongside the playbooks that did parse.
+func List(dir string) ([]Playbook, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var playbooks []Playbook
+	var errs []error
+	for _, e := range entries {
+		switch filepath.Ext(e.Name()) {
+		case ".yml", ".yaml", ".md":
+		default:
+			continue
+		}
+		if e.IsDir() {
+			continue
+		}
+		p, err := Load(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		playbooks = append(playbooks, p)
+	}
+	sort.Slice(playbooks, func(i, j int) bool { return playbooks[i].Name < playbooks[j].Name })
+	return playbooks, errors.Join(errs...)
+}
+
+// BranchName returns the branch an agent running the playbook works on:
+// the playbook's branch, or one derived from its name and now so repeated
+// runs don't collide.
+func (p Playbook) BranchName(now time.Time) string {
+	if p.Branch != "" {
+		return p.Branch
+	}
+	name := git.SanitizeBranchName(strings.ToLower(strings.ReplaceAll(p.Name, " ", "-")))
+	if name == "" {
+		name = "task"
+	}
+	return "playbook/" + name + "-" + now.Format("20060102-1504")
+}
+
+// Context is the extra context given to an agent running the playbook,
+// telling it how its work is checked and whether it is merged unreviewed.
+func (p Playbook) Context() string {
+	if len(p.Checks) == 0 && p.AutoMerge != MergeOnChecks {
+		return ""
+	}
+	var b strings.Builder
+	if len(p.Checks) > 0 {
+		b.WriteString("When you finish, these checks are run in this worktree:\n")
+		for _, c := range p.Checks {
+			fmt.Fprintf(&b, "- `%s`\n", c)
+		}
+	}
+	if p.AutoMerge == MergeOnChecks {
+		b.WriteString("Commit all of your changes before you finish: the branch is merged automatically, without review, once the checks pass.")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Command string
+	Output  string
+	Err     error
+}
+
+// RunChecks runs checks in dir through sh, in order, and stops at the
+// first one that fails. All checks passed when no result has an error.
+func RunChecks(ctx context.Context, dir string, checks []string) []CheckResult {
+	var results []CheckResult
+	for _, c := range checks {
+		cmd := exec.CommandContext(ctx, "sh", "-c", c)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		results = append(results, CheckResult{Command: c, Output: string(out), Err: err})
+		if err != nil {
+			break
+		}
+	}
+	return results
+}
+
+// Failed returns the failed check among results, if any.
+func Failed(results []CheckResult) (CheckResult, bool) {
+	for _, r := range results {
+		if r.Err != nil {
+			return r, true
+		}
+	}
+	return CheckResult{}, false
+}
@@ -0,0 +1,124 @@
+package playbook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "deps.yml", `
+name: Upgrade deps
+base: main
+prompt: |
+  Upgrade all Go dependencies.
+checks:
+  - go build ./...
+  - go test ./...
+auto_merge: checks
+`)
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Upgrade deps" || p.Base != "main" || p.Prompt != "Upgrade all Go dependencies." {
+		t.Errorf("playbook = %+v", p)
+	}
+	if len(p.Checks) != 2 || p.AutoMerge != MergeOnChecks || p.Path != path {
+		t.Errorf("checks = %v, auto_merge = %q, path = %q", p.Checks, p.AutoMerge, p.Path)
+	}
+}
+
+func TestLoad_MarkdownFrontMatter(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "lint.md", "---\nchecks: [make lint]\n---\nFix every lint warning.\n\nKeep changes minimal.\n")
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "lint" {
+		t.Errorf("name = %q, want the file name", p.Name)
+	}
+	if p.Prompt != "Fix every lint warning.\n\nKeep changes minimal." {
+		t.Errorf("prompt = %q", p.Prompt)
+	}
+	if p.AutoMerge != MergeNever {
+		t.Errorf("auto_merge = %q, want never by default", p.AutoMerge)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"empty.yml":    "name: x\n",
+		"policy.yml":   "prompt: x\nauto_merge: always\n",
+		"branch.yml":   "prompt: x\nbranch: 'a..b'\n",
+		"unclosed.md":  "---\nname: x\nprompt body\n",
+		"notes.txt":    "prompt: x\n",
+		"badyaml.yaml": "prompt: [\n",
+	}
+	for name, content := range cases {
+		if _, err := Load(writeFile(t, dir, name, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "b.yml", "name: beta\nprompt: b\n")
+	writeFile(t, dir, "a.md", "Do a.\n")
+	writeFile(t, dir, "README.txt", "not a playbook")
+	writeFile(t, dir, "broken.yml", "name: broken\n")
+
+	playbooks, err := List(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.yml") {
+		t.Errorf("err = %v, want the broken playbook reported", err)
+	}
+	if len(playbooks) != 2 || playbooks[0].Name != "a" || playbooks[1].Name != "beta" {
+		t.Errorf("playbooks = %+v, want a and beta", playbooks)
+	}
+
+	if playbooks, err := List(filepath.Join(dir, "missing")); err != nil || playbooks != nil {
+		t.Errorf("missing dir: %v, %v", playbooks, err)
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	if got := (Playbook{Name: "Upgrade deps"}).BranchName(now); got != "playbook/upgrade-deps-20261016-0930" {
+		t.Errorf("BranchName = %q", got)
+	}
+	if got := (Playbook{Name: "x", Branch: "chore/deps"}).BranchName(now); got != "chore/deps" {
+		t.Errorf("BranchName = %q, want the playbook's branch", got)
+	}
+}
+
+func TestRunChecks(t *testing.T) {
+	dir := t.TempDir()
+	results := RunChecks(context.Background(), dir, []string{"echo ok > out", "echo boom; exit 3", "touch never"})
+	if len(results) != 2 {
+		t.Fatalf("results = %+v, want to stop at the failing check", results)
+	}
+	failed, ok := Failed(results)
+	if !ok || failed.Command != "echo boom; exit 3" || !strings.Contains(failed.Output, "boom") {
+		t.Errorf("failed = %+v", failed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out")); err != nil {
+		t.Error("checks should run in dir")
+	}
+	if _, ok := Failed(RunChecks(context.Background(), dir, []string{"true"})); ok {
+		t.Error("passing checks reported as failed")
+	}
+}
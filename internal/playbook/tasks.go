@@ -0,0 +1,103 @@
+package playbook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+// Task is one entry of a batch tasks file: an agent to spawn.
+type Task struct {
+	Branch string `yaml:"branch"`
+	// Base defaults to the preset's base, then to the current branch.
+	Base   string `yaml:"base"`
+	Prompt string `yaml:"prompt"`
+	// Preset names a playbook the task starts from; its own fields
+	// override the playbook's, and the playbook's checks and merge policy
+	// apply.
+	Preset string `yaml:"preset"`
+	// Harness is "claude", "opencode" or a [[harness.agents]] name; empty
+	// uses the default.
+	Harness string `yaml:"harness"`
+}
+
+// LoadTasks reads a batch tasks file: a YAML list of tasks, or a mapping
+// with the list under "tasks".
+func LoadTasks(path string) ([]Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []Task
+	if err := yaml.Unmarshal(data, &tasks); err != nil {
+		var file struct {
+			Tasks []Task `yaml:"tasks"`
+		}
+		if err2 := yaml.Unmarshal(data, &file); err2 != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		tasks = file.Tasks
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("%s: no tasks", path)
+	}
+	return tasks, nil
+}
+
+// ResolveTasks turns tasks into the playbooks to run, in the order of
+// tasks, looking presets up by name in presets. Every problem is
+// reported, so a batch can be fixed in one go before anything is spawned.
+func ResolveTasks(tasks []Task, presets []Playbook) ([]Playbook, error) {
+	byName := make(map[string]Playbook, len(presets))
+	for _, p := range presets {
+		byName[p.Name] = p
+	}
+
+	var resolved []Playbook
+	var errs []error
+	seen := make(map[string]bool)
+	for i, t := range tasks {
+		p := Playbook{Name: t.Branch, AutoMerge: MergeNever}
+		if t.Preset != "" {
+			preset, ok := byName[t.Preset]
+			if !ok {
+				errs = append(errs, fmt.Errorf("task %d: unknown preset %q", i+1, t.Preset))
+				continue
+			}
+			p = preset
+		}
+		if t.Base != "" {
+			p.Base = t.Base
+		}
+		if t.Prompt != "" {
+			p.Prompt = t.Prompt
+		}
+		p.Branch = t.Branch
+
+		switch {
+		case t.Branch == "":
+			errs = append(errs, fmt.Errorf("task %d: branch is required", i+1))
+			continue
+		case seen[t.Branch]:
+			errs = append(errs, fmt.Errorf("task %d: branch %q is used by an earlier task", i+1, t.Branch))
+			continue
+		case p.Prompt == "":
+			errs = append(errs, fmt.Errorf("task %d (%s): no prompt", i+1, t.Branch))
+			continue
+		}
+		if err := git.ValidateBranchName(t.Branch); err != nil {
+			errs = append(errs, fmt.Errorf("task %d: invalid branch: %w", i+1, err))
+			continue
+		}
+		seen[t.Branch] = true
+		resolved = append(resolved, p)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
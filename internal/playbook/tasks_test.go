@@ -0,0 +1,63 @@
+package playbook
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadTasks(t *testing.T) {
+	dir := t.TempDir()
+	list := writeFile(t, dir, "list.yaml", "- branch: feat/a\n  prompt: Do a.\n- branch: feat/b\n  preset: deps\n  harness: opencode\n")
+	tasks, err := LoadTasks(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[1].Preset != "deps" || tasks[1].Harness != "opencode" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+
+	mapping := writeFile(t, dir, "mapping.yaml", "tasks:\n  - branch: feat/a\n    prompt: Do a.\n")
+	if tasks, err := LoadTasks(mapping); err != nil || len(tasks) != 1 {
+		t.Errorf("tasks under a key = %+v, %v", tasks, err)
+	}
+
+	if _, err := LoadTasks(writeFile(t, dir, "empty.yaml", "tasks: []\n")); err == nil {
+		t.Error("a file without tasks should be an error")
+	}
+}
+
+func TestResolveTasks(t *testing.T) {
+	presets := []Playbook{{Name: "deps", Base: "develop", Prompt: "Upgrade deps.", Checks: []string{"make test"}, AutoMerge: MergeOnChecks, Path: "/pb/deps.yml"}}
+
+	resolved, err := ResolveTasks([]Task{
+		{Branch: "feat/a", Base: "main", Prompt: "Do a."},
+		{Branch: "chore/deps", Preset: "deps"},
+		{Branch: "chore/deps-main", Preset: "deps", Base: "main", Prompt: "Upgrade deps on main."},
+	}, presets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := resolved[0]; a.Branch != "feat/a" || a.Base != "main" || a.Prompt != "Do a." || a.Path != "" || a.AutoMerge != MergeNever {
+		t.Errorf("plain task = %+v", a)
+	}
+	if d := resolved[1]; d.Branch != "chore/deps" || d.Base != "develop" || d.Prompt != "Upgrade deps." || d.Path != "/pb/deps.yml" || len(d.Checks) != 1 {
+		t.Errorf("preset task = %+v, want the preset's fields", d)
+	}
+	if o := resolved[2]; o.Base != "main" || o.Prompt != "Upgrade deps on main." || o.AutoMerge != MergeOnChecks {
+		t.Errorf("overriding task = %+v", o)
+	}
+
+	_, err = ResolveTasks([]Task{
+		{Prompt: "no branch"},
+		{Branch: "feat/x", Prompt: "x"},
+		{Branch: "feat/x", Prompt: "again"},
+		{Branch: "feat/y"},
+		{Branch: "feat/z", Preset: "missing"},
+		{Branch: "bad..name", Prompt: "x"},
+	}, presets)
+	for _, want := range []string{"task 1: branch is required", "task 3: branch \"feat/x\"", "task 4 (feat/y): no prompt", "task 5: unknown preset", "task 6: invalid branch"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want %q", err, want)
+		}
+	}
+}
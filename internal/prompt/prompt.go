@@ -0,0 +1,161 @@
+// Package prompt publishes a per-repo summary of agent states for shell
+// prompts (starship, powerlevel10k, ...) and implements the
+// `mastermind prompt` subcommand that renders it.
+package prompt
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// FileName is the status file written to the repository's .worktrees dir.
+const FileName = "mastermind-prompt.json"
+
+// DefaultFormat is the output of `mastermind prompt` when no format is given.
+const DefaultFormat = "🤖 {attention}"
+
+// Status is the machine-readable summary written for shell prompts.
+type Status struct {
+	Total     int            `json:"total"`
+	Attention int            `json:"attention"`
+	Counts    map[string]int `json:"counts"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// needsAttention reports whether an agent in status s is waiting on the user.
+func needsAttention(s agent.Status) bool {
+	switch s {
+	case agent.StatusWaiting, agent.StatusReviewReady, agent.StatusConflicts:
+		return true
+	}
+	return false
+}
+
+// Summarize counts agents by status. Dismissed agents are ignored.
+func Summarize(agents []*agent.Agent) Status {
+	st := Status{Counts: make(map[string]int)}
+	for _, a := range agents {
+		s := a.GetStatus()
+		if s == agent.StatusDismissed {
+			continue
+		}
+		st.Total++
+		st.Counts[string(s)]++
+		if needsAttention(s) {
+			st.Attention++
+		}
+	}
+	return st
+}
+
+// Write atomically replaces the status file at path.
+func Write(path string, st Status) error {
+	if st.UpdatedAt.IsZero() {
+		st.UpdatedAt = time.Now()
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("marshal prompt status: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("write prompt status temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("rename prompt status file: %w", err)
+	}
+	return nil
+}
+
+// Read loads the status file at path.
+func Read(path string) (Status, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Status{}, err
+	}
+	var st Status
+	if err := json.Unmarshal(data, &st); err != nil {
+		return Status{}, fmt.Errorf("unmarshal prompt status: %w", err)
+	}
+	return st, nil
+}
+
+// Find walks up from dir looking for .worktrees/<FileName>. This also finds
+// the file from inside an agent worktree, which lives under .worktrees.
+func Find(dir string) (string, bool) {
+	for {
+		path := filepath.Join(dir, ".worktrees", FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Format expands placeholders in format: {total}, {attention}, and one per
+// status with spaces replaced by underscores, e.g. {running} or
+// {review_ready}.
+func Format(format string, st Status) string {
+	pairs := []string{
+		"{total}", strconv.Itoa(st.Total),
+		"{attention}", strconv.Itoa(st.Attention),
+	}
+	for _, s := range []agent.Status{
+		agent.StatusRunning, agent.StatusWaiting, agent.StatusReviewReady,
+		agent.StatusDone, agent.StatusReviewing, agent.StatusReviewed,
+		agent.StatusPreviewing, agent.StatusConflicts, agent.StatusOrphaned,
+	} {
+		key := "{" + strings.ReplaceAll(string(s), " ", "_") + "}"
+		pairs = append(pairs, key, strconv.Itoa(st.Counts[string(s)]))
+	}
+	return strings.NewReplacer(pairs...).Replace(format)
+}
+
+// Run renders the prompt segment for the repository containing dir.
+// Nothing is printed when mastermind is not running there or, unless
+// always is set, when no agent needs attention.
+func Run(dir, format string, always bool, out io.Writer) int {
+	path, ok := Find(dir)
+	if !ok {
+		return 0
+	}
+	st, err := Read(path)
+	if err != nil {
+		return 0
+	}
+	if st.Attention == 0 && !always {
+		return 0
+	}
+	fmt.Fprintln(out, Format(format, st))
+	return 0
+}
+
+// Main is the entry point for the `mastermind prompt` subcommand:
+// `prompt [--format FORMAT] [--always]`.
+func Main(args []string) int {
+	fs := flag.NewFlagSet("prompt", flag.ContinueOnError)
+	format := fs.String("format", DefaultFormat, "output format ({attention}, {total}, {running}, {review_ready}, ...)")
+	always := fs.Bool("always", false, "print even when no agent needs attention")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return Run(cwd, *format, *always, os.Stdout)
+}
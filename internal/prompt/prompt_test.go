@@ -0,0 +1,89 @@
+package prompt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+func newAgent(status agent.Status) *agent.Agent {
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	a.SetStatus(status)
+	return a
+}
+
+func TestSummarize(t *testing.T) {
+	st := Summarize([]*agent.Agent{
+		newAgent(agent.StatusRunning),
+		newAgent(agent.StatusWaiting),
+		newAgent(agent.StatusReviewReady),
+		newAgent(agent.StatusReviewReady),
+		newAgent(agent.StatusDismissed),
+	})
+
+	if st.Total != 4 {
+		t.Errorf("total = %d, want 4", st.Total)
+	}
+	if st.Attention != 3 {
+		t.Errorf("attention = %d, want 3", st.Attention)
+	}
+	if st.Counts["review ready"] != 2 || st.Counts["running"] != 1 {
+		t.Errorf("counts = %v", st.Counts)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	st := Status{Total: 5, Attention: 2, Counts: map[string]int{"running": 3, "review ready": 1}}
+
+	got := Format("{attention}/{total} r{running} rr{review_ready} c{conflicts}", st)
+	if want := "2/5 r3 rr1 c0"; got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+}
+
+func TestRun_FindsFileFromAgentWorktree(t *testing.T) {
+	repo := t.TempDir()
+	wtDir := filepath.Join(repo, ".worktrees", "feat-x", "sub")
+	if err := os.MkdirAll(wtDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(repo, ".worktrees", FileName)
+	if err := Write(path, Status{Total: 3, Attention: 1}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var out bytes.Buffer
+	Run(wtDir, DefaultFormat, false, &out)
+	if got := out.String(); got != "🤖 1\n" {
+		t.Errorf("output = %q, want %q", got, "🤖 1\n")
+	}
+}
+
+func TestRun_QuietWithoutAttention(t *testing.T) {
+	repo := t.TempDir()
+	os.MkdirAll(filepath.Join(repo, ".worktrees"), 0o755)
+	if err := Write(filepath.Join(repo, ".worktrees", FileName), Status{Total: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	Run(repo, DefaultFormat, false, &out)
+	if out.Len() != 0 {
+		t.Errorf("expected no output, got %q", out.String())
+	}
+
+	Run(repo, "{total}", true, &out)
+	if out.String() != "2\n" {
+		t.Errorf("--always output = %q, want %q", out.String(), "2\n")
+	}
+}
+
+func TestRun_NoStatusFile(t *testing.T) {
+	var out bytes.Buffer
+	if code := Run(t.TempDir(), DefaultFormat, true, &out); code != 0 || out.Len() != 0 {
+		t.Errorf("code = %d, output = %q; want silent success", code, out.String())
+	}
+}
@@ -0,0 +1,120 @@
+// Package recorder records the actions a user takes in a session, such as
+// spawning, merging and dismissing agents, into a script that can be
+// replayed later to reproduce the session for a bug report or a demo.
+// Actions are the operations of the ipc protocol, so a script is replayed
+// through the same backend the daemon serves.
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Version is the script format written by this build.
+const Version = 1
+
+// Script is a recorded session.
+type Script struct {
+	Version int       `json:"version"`
+	Repo    string    `json:"repo"`
+	Started time.Time `json:"started"`
+	Actions []Action  `json:"actions"`
+}
+
+// Action is one user action.
+type Action struct {
+	Time time.Time `json:"time"`
+	// Op is an ipc operation, e.g. ipc.OpSpawn.
+	Op string `json:"op"`
+	// Branch is the branch of the agent acted on. Agent IDs differ between
+	// sessions, so a replay finds the agent by its branch.
+	Branch string          `json:"branch,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	// Error is why the action failed when it was recorded.
+	Error string `json:"error,omitempty"`
+}
+
+// Recorder appends actions to a script file. The file is rewritten after
+// every action, so it is complete even if mastermind does not exit
+// cleanly. A Recorder is safe for concurrent use.
+type Recorder struct {
+	path string
+
+	mu     sync.Mutex
+	script Script
+}
+
+// New returns a recorder writing the script of a session in repo to path.
+// Nothing is written until the first action.
+func New(path, repo string) *Recorder {
+	return &Recorder{
+		path:   path,
+		script: Script{Version: Version, Repo: repo, Started: time.Now()},
+	}
+}
+
+// Path returns the file the script is written to.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Record appends an action on the agent on branch, with params being the
+// operation's ipc parameters and err its outcome. Failing to write the
+// script is logged; it never fails the action.
+func (r *Recorder) Record(op, branch string, params any, err error) {
+	a := Action{Time: time.Now(), Op: op, Branch: branch}
+	if err != nil {
+		a.Error = err.Error()
+	}
+	if params != nil {
+		data, merr := json.Marshal(params)
+		if merr != nil {
+			slog.Warn("record action failed", "op", op, "error", merr)
+			return
+		}
+		a.Params = data
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.script.Actions = append(r.script.Actions, a)
+	if werr := r.save(); werr != nil {
+		slog.Warn("write recording failed", "path", r.path, "error", werr)
+	}
+}
+
+// save atomically writes the script. The caller holds r.mu.
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.script, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal script: %w", err)
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write script temp file: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("rename script file: %w", err)
+	}
+	return nil
+}
+
+// Load reads a script written by a Recorder.
+func Load(path string) (Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Script{}, err
+	}
+	var s Script
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Script{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Version > Version {
+		return Script{}, fmt.Errorf("%s: script version %d is newer than this build's %d", path, s.Version, Version)
+	}
+	return s, nil
+}
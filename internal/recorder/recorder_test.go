@@ -0,0 +1,118 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// fakeBackend spawns agents with IDs of its own and records the calls.
+type fakeBackend struct {
+	agents []agent.PersistedAgent
+	calls  []string
+}
+
+func (b *fakeBackend) Repo() string                   { return "/repo" }
+func (b *fakeBackend) Agents() []agent.PersistedAgent { return b.agents }
+
+func (b *fakeBackend) Spawn(p ipc.SpawnParams) error {
+	b.calls = append(b.calls, "spawn "+p.Branch)
+	b.agents = append(b.agents, agent.PersistedAgent{ID: "new-" + p.Branch, Branch: p.Branch})
+	return nil
+}
+
+func (b *fakeBackend) Merge(p ipc.MergeParams) ipc.MergeResult {
+	b.calls = append(b.calls, "merge "+p.ID)
+	return ipc.MergeResult{Success: true}
+}
+
+func (b *fakeBackend) Dismiss(p ipc.DismissParams) error {
+	b.calls = append(b.calls, "dismiss "+p.ID)
+	return nil
+}
+
+func (b *fakeBackend) PullRequest(p ipc.PullRequestParams) (ipc.PullRequestResult, error) {
+	return ipc.PullRequestResult{}, errors.New("no forge")
+}
+
+func (b *fakeBackend) Clone(p ipc.CloneParams) error         { return nil }
+func (b *fakeBackend) AllowPush(p ipc.AllowPushParams) error { return nil }
+
+func (b *fakeBackend) Subscribe(int) (<-chan monitor.Event, func()) {
+	return make(chan monitor.Event), func() {}
+}
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	r := New(path, "/repo")
+	r.Record(ipc.OpSpawn, "feat/x", ipc.SpawnParams{Branch: "feat/x", BaseBranch: "main", CreateBranch: true}, nil)
+	r.Record(ipc.OpMerge, "feat/x", ipc.MergeParams{ID: "a1", DeleteBranch: true}, errors.New("merge conflicts in 1 file(s)"))
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s.Version != Version || s.Repo != "/repo" || len(s.Actions) != 2 {
+		t.Fatalf("script = %+v", s)
+	}
+	var p ipc.SpawnParams
+	if a := s.Actions[0]; a.Op != ipc.OpSpawn || a.Branch != "feat/x" || a.Error != "" || decode(a, &p) != nil || p.BaseBranch != "main" {
+		t.Errorf("spawn action = %+v", a)
+	}
+	if a := s.Actions[1]; a.Error != "merge conflicts in 1 file(s)" {
+		t.Errorf("merge action error = %q", a.Error)
+	}
+}
+
+func TestReplay_FindsAgentsByBranch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	r := New(path, "/repo")
+	r.Record(ipc.OpSpawn, "feat/x", ipc.SpawnParams{Branch: "feat/x", BaseBranch: "main", CreateBranch: true}, nil)
+	r.Record(ipc.OpMerge, "feat/x", ipc.MergeParams{ID: "a1", DeleteBranch: true}, nil)
+	r.Record(ipc.OpDismiss, "feat/gone", ipc.DismissParams{ID: "a2"}, nil)
+	r.Record(ipc.OpPullRequest, "feat/x", ipc.PullRequestParams{ID: "a1"}, errors.New("no forge"))
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &fakeBackend{}
+	var reported int
+	results := Replay(context.Background(), s, b, 0, func(int, Result) { reported++ })
+	if len(results) != 4 || reported != 4 {
+		t.Fatalf("results = %+v, reported %d", results, reported)
+	}
+	if want := []string{"spawn feat/x", "merge new-feat/x"}; strings.Join(b.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", b.calls, want)
+	}
+	if results[1].Changed() {
+		t.Errorf("merge = %+v, want its recorded outcome", results[1])
+	}
+	if !results[2].Changed() || !strings.Contains(results[2].Error, `no agent on branch "feat/gone"`) {
+		t.Errorf("dismiss = %+v, want a missing agent", results[2])
+	}
+	if results[3].Changed() {
+		t.Errorf("pull request = %+v, want it to fail as recorded", results[3])
+	}
+}
+
+func TestReplay_StopsWhenCancelled(t *testing.T) {
+	now := time.Now()
+	s := Script{Actions: []Action{
+		{Time: now, Op: ipc.OpSpawn, Params: []byte(`{"branch":"a"}`)},
+		{Time: now.Add(time.Hour), Op: ipc.OpSpawn, Params: []byte(`{"branch":"b"}`)},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &fakeBackend{}
+	results := Replay(ctx, s, b, 1, func(int, Result) { cancel() })
+	if len(results) != 1 || len(b.calls) != 1 {
+		t.Errorf("results = %+v, calls = %v; want to stop after the first action", results, b.calls)
+	}
+}
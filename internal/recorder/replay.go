@@ -0,0 +1,132 @@
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/ipc"
+)
+
+// Result is the outcome of replaying one action.
+type Result struct {
+	Action Action
+	// Error is why the replayed action failed, or "" if it succeeded.
+	Error string
+}
+
+// Changed reports whether the action succeeded on replay but failed when
+// recorded, or the other way round.
+func (r Result) Changed() bool {
+	return (r.Error == "") != (r.Action.Error == "")
+}
+
+// Replay carries out the actions of s on b in order. Between actions it
+// pauses for the recorded time between them divided by speed; a speed of
+// 0 replays without pausing. report, if set, is called after each action.
+// Replay stops early when ctx is cancelled and returns the results of the
+// actions carried out.
+func Replay(ctx context.Context, s Script, b ipc.Backend, speed float64, report func(i int, r Result)) []Result {
+	results := make([]Result, 0, len(s.Actions))
+	for i, a := range s.Actions {
+		if i > 0 && speed > 0 {
+			gap := time.Duration(float64(a.Time.Sub(s.Actions[i-1].Time)) / speed)
+			if gap > 0 {
+				select {
+				case <-ctx.Done():
+					return results
+				case <-time.After(gap):
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return results
+		}
+
+		r := Result{Action: a}
+		if err := apply(b, a); err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+		if report != nil {
+			report(i, r)
+		}
+	}
+	return results
+}
+
+// apply carries out a on b.
+func apply(b ipc.Backend, a Action) error {
+	switch a.Op {
+	case ipc.OpSpawn:
+		var p ipc.SpawnParams
+		if err := decode(a, &p); err != nil {
+			return err
+		}
+		return b.Spawn(p)
+	case ipc.OpMerge:
+		var p ipc.MergeParams
+		if err := decodeFor(b, a, &p, &p.ID); err != nil {
+			return err
+		}
+		res := b.Merge(p)
+		switch {
+		case res.Conflict:
+			return fmt.Errorf("merge conflicts in %d file(s)", len(res.ConflictFiles))
+		case !res.Success:
+			return errors.New(res.Error)
+		}
+		return nil
+	case ipc.OpDismiss:
+		var p ipc.DismissParams
+		if err := decodeFor(b, a, &p, &p.ID); err != nil {
+			return err
+		}
+		return b.Dismiss(p)
+	case ipc.OpPullRequest:
+		var p ipc.PullRequestParams
+		if err := decodeFor(b, a, &p, &p.ID); err != nil {
+			return err
+		}
+		_, err := b.PullRequest(p)
+		return err
+	case ipc.OpClone:
+		var p ipc.CloneParams
+		if err := decodeFor(b, a, &p, &p.ID); err != nil {
+			return err
+		}
+		return b.Clone(p)
+	case ipc.OpAllowPush:
+		var p ipc.AllowPushParams
+		if err := decodeFor(b, a, &p, &p.ID); err != nil {
+			return err
+		}
+		return b.AllowPush(p)
+	}
+	return fmt.Errorf("unknown op %q", a.Op)
+}
+
+// decode unmarshals the parameters of a into params.
+func decode(a Action, params any) error {
+	if err := json.Unmarshal(a.Params, params); err != nil {
+		return fmt.Errorf("invalid %s params: %w", a.Op, err)
+	}
+	return nil
+}
+
+// decodeFor decodes the parameters of an action on an agent and sets *id
+// to the ID the agent on a.Branch has in this session.
+func decodeFor(b ipc.Backend, a Action, params any, id *string) error {
+	if err := decode(a, params); err != nil {
+		return err
+	}
+	for _, pa := range b.Agents() {
+		if pa.Branch == a.Branch {
+			*id = pa.ID
+			return nil
+		}
+	}
+	return fmt.Errorf("no agent on branch %q", a.Branch)
+}
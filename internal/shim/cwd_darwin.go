@@ -0,0 +1,22 @@
+package shim
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// processCWD returns the current working directory of a running process.
+// macOS has no /proc, so this asks lsof for the cwd descriptor.
+func processCWD(pid int) (string, error) {
+	out, err := exec.Command("lsof", "-a", "-p", strconv.Itoa(pid), "-d", "cwd", "-Fn").Output()
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "n") {
+			return line[1:], nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,11 @@
+package shim
+
+import (
+	"os"
+	"strconv"
+)
+
+// processCWD returns the current working directory of a running process.
+func processCWD(pid int) (string, error) {
+	return os.Readlink("/proc/" + strconv.Itoa(pid) + "/cwd")
+}
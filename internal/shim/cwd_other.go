@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package shim
+
+import "errors"
+
+// processCWD is not supported on this platform; the shim keeps the
+// starting directory as the final CWD.
+func processCWD(pid int) (string, error) {
+	return "", errors.New("process cwd not supported")
+}
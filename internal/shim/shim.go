@@ -0,0 +1,176 @@
+// Package shim implements `mastermind shim`, a thin wrapper that agents
+// are launched through. It runs the real command, forwards signals to
+// it, and records the exit code, runtime and final working directory in
+// the worktree. The monitor prefers this record over tmux's
+// pane_dead_status, which is wrong on some tmux builds and unavailable
+// once a pane is gone.
+package shim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ResultFileName is written by the shim into the directory it was started in.
+const ResultFileName = ".mastermind-exit.json"
+
+// cwdPollInterval is how often the child's working directory is sampled.
+const cwdPollInterval = 2 * time.Second
+
+// Result records how the wrapped command ended.
+type Result struct {
+	ExitCode   int       `json:"exit_code"`
+	Signal     string    `json:"signal,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	CWD        string    `json:"cwd,omitempty"`
+}
+
+// Runtime returns how long the wrapped command ran.
+func (r *Result) Runtime() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// ReadResult reads the shim result from the given worktree path.
+// Returns nil, nil if the file does not exist.
+func ReadResult(worktreePath string) (*Result, error) {
+	data, err := os.ReadFile(filepath.Join(worktreePath, ResultFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read shim result: %w", err)
+	}
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse shim result: %w", err)
+	}
+	return &r, nil
+}
+
+// RemoveResult deletes a previous run's result so it can't be mistaken
+// for the next one.
+func RemoveResult(worktreePath string) {
+	_ = os.Remove(filepath.Join(worktreePath, ResultFileName))
+}
+
+// writeResult writes the result file atomically.
+func writeResult(dir string, r *Result) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, ResultFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Wrap prefixes command so it runs through the shim. It returns command
+// unchanged if the mastermind executable can't be located.
+func Wrap(command []string) []string {
+	exe, err := os.Executable()
+	if err != nil || len(command) == 0 {
+		return command
+	}
+	return append([]string{exe, "shim", "--"}, command...)
+}
+
+// Run executes args in dir with the given stdio, records the result in
+// dir and returns the child's exit code.
+func Run(dir string, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "mastermind shim: no command given")
+		return 2
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	res := &Result{StartedAt: time.Now(), CWD: dir}
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(stderr, "mastermind shim: %v\n", err)
+		res.ExitCode = 127
+		res.FinishedAt = time.Now()
+		_ = writeResult(dir, res)
+		return res.ExitCode
+	}
+
+	// Forward termination signals so the child can shut down cleanly.
+	sigCh := make(chan os.Signal, 4)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+
+	var mu sync.Mutex
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cwdPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-sigCh:
+				_ = cmd.Process.Signal(sig)
+			case <-ticker.C:
+				if cwd, err := processCWD(cmd.Process.Pid); err == nil && cwd != "" {
+					mu.Lock()
+					res.CWD = cwd
+					mu.Unlock()
+				}
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	close(done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	res.FinishedAt = time.Now()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			fmt.Fprintf(stderr, "mastermind shim: %v\n", err)
+			res.ExitCode = 1
+		} else if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			res.Signal = ws.Signal().String()
+			res.ExitCode = 128 + int(ws.Signal())
+		} else {
+			res.ExitCode = exitErr.ExitCode()
+		}
+	}
+
+	if err := writeResult(dir, res); err != nil {
+		fmt.Fprintf(stderr, "mastermind shim: write result: %v\n", err)
+	}
+	return res.ExitCode
+}
+
+// Main is the entry point for the hidden `mastermind shim` subcommand.
+// args is everything after "shim", optionally starting with "--".
+func Main(args []string) int {
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	return Run(dir, args, os.Stdin, os.Stdout, os.Stderr)
+}
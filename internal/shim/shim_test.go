@@ -0,0 +1,94 @@
+package shim
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun_RecordsExitCode(t *testing.T) {
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+
+	code := Run(dir, []string{"sh", "-c", "echo hello; exit 7"}, strings.NewReader(""), &stdout, &stderr)
+
+	if code != 7 {
+		t.Errorf("exit code = %d, want 7", code)
+	}
+	if stdout.String() != "hello\n" {
+		t.Errorf("stdout = %q, want %q", stdout.String(), "hello\n")
+	}
+	r, err := ReadResult(dir)
+	if err != nil || r == nil {
+		t.Fatalf("ReadResult = %v, %v", r, err)
+	}
+	if r.ExitCode != 7 {
+		t.Errorf("recorded exit code = %d, want 7", r.ExitCode)
+	}
+	if r.Runtime() < 0 || r.FinishedAt.IsZero() {
+		t.Errorf("bad timing: %+v", r)
+	}
+	if r.CWD != dir {
+		t.Errorf("cwd = %q, want %q", r.CWD, dir)
+	}
+}
+
+func TestRun_Success(t *testing.T) {
+	dir := t.TempDir()
+	code := Run(dir, []string{"true"}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	r, _ := ReadResult(dir)
+	if r == nil || r.ExitCode != 0 {
+		t.Errorf("result = %+v, want exit 0", r)
+	}
+}
+
+func TestRun_CommandNotFound(t *testing.T) {
+	dir := t.TempDir()
+	code := Run(dir, []string{"definitely-not-a-command-xyz"}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	if code != 127 {
+		t.Errorf("exit code = %d, want 127", code)
+	}
+	if r, _ := ReadResult(dir); r == nil || r.ExitCode != 127 {
+		t.Errorf("result = %+v, want exit 127", r)
+	}
+}
+
+func TestRun_Signaled(t *testing.T) {
+	dir := t.TempDir()
+	code := Run(dir, []string{"sh", "-c", "kill -TERM $$"}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	if code != 128+15 {
+		t.Errorf("exit code = %d, want %d", code, 128+15)
+	}
+	if r, _ := ReadResult(dir); r == nil || r.Signal == "" {
+		t.Errorf("result = %+v, want signal recorded", r)
+	}
+}
+
+func TestReadResult_MissingAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	if r, err := ReadResult(dir); r != nil || err != nil {
+		t.Errorf("ReadResult = %v, %v; want nil, nil", r, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ResultFileName), []byte(`{"exit_code":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	RemoveResult(dir)
+	if r, _ := ReadResult(dir); r != nil {
+		t.Errorf("expected result removed, got %+v", r)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	got := Wrap([]string{"claude", "--x"})
+	if len(got) != 5 || got[1] != "shim" || got[2] != "--" || got[3] != "claude" {
+		t.Errorf("Wrap = %v", got)
+	}
+	if Wrap(nil) != nil {
+		t.Error("Wrap(nil) should stay nil")
+	}
+}
@@ -0,0 +1,138 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// Action IDs of the buttons on attention messages.
+const (
+	actionMerge   = "merge"
+	actionDismiss = "dismiss"
+)
+
+// DefaultAPIURL is Slack's Web API base URL.
+const DefaultAPIURL = "https://slack.com/api"
+
+// Poster posts attention events to a channel with a bot token.
+type Poster struct {
+	token   string
+	channel string
+	apiURL  string
+	client  *http.Client
+}
+
+// NewPoster returns a poster for channel. apiURL is usually DefaultAPIURL.
+func NewPoster(botToken, channel, apiURL string) *Poster {
+	return &Poster{
+		token:   botToken,
+		channel: channel,
+		apiURL:  apiURL,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run posts every attention event from backend until ctx is cancelled or
+// the event stream ends.
+func (p *Poster) Run(ctx context.Context, backend Backend) {
+	events, stop := backend.Subscribe(64)
+	defer stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			att, ok := ev.(monitor.Attention)
+			if !ok {
+				continue
+			}
+			if err := p.Post(att, agentStatus(backend, att.AgentID)); err != nil {
+				slog.Warn("slack post failed", "agent", att.AgentID, "error", err)
+			}
+		}
+	}
+}
+
+func agentStatus(backend Backend, id string) agent.Status {
+	for _, a := range backend.Agents() {
+		if a.ID == id {
+			return a.Status
+		}
+	}
+	return ""
+}
+
+// Post sends one attention message. Agents ready to merge get a Merge
+// button; every agent gets a Dismiss button.
+func (p *Poster) Post(att monitor.Attention, status agent.Status) error {
+	var buttons []any
+	if status == agent.StatusReviewReady || status == agent.StatusReviewed {
+		buttons = append(buttons, map[string]any{
+			"type":      "button",
+			"text":      map[string]string{"type": "plain_text", "text": "Merge"},
+			"action_id": actionMerge,
+			"value":     att.AgentID,
+			"style":     "primary",
+		})
+	}
+	buttons = append(buttons, map[string]any{
+		"type":      "button",
+		"text":      map[string]string{"type": "plain_text", "text": "Dismiss"},
+		"action_id": actionDismiss,
+		"value":     att.AgentID,
+		"style":     "danger",
+		"confirm": map[string]any{
+			"title":   map[string]string{"type": "plain_text", "text": "Dismiss agent?"},
+			"text":    map[string]string{"type": "plain_text", "text": "This closes the agent's window and removes its worktree."},
+			"confirm": map[string]string{"type": "plain_text", "text": "Dismiss"},
+			"deny":    map[string]string{"type": "plain_text", "text": "Cancel"},
+		},
+	})
+
+	msg := map[string]any{
+		"channel": p.channel,
+		"text":    att.Message,
+		"blocks": []any{
+			map[string]any{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": att.Message}},
+			map[string]any{"type": "actions", "elements": buttons},
+		},
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, p.apiURL+"/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode slack response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack: %s", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+// Package slack integrates mastermind with a Slack app, for teams running
+// agents on a shared dev box. It serves the app's slash command
+// (`/mastermind list`, `/mastermind merge a3`) and interactive buttons on
+// the web dashboard's HTTP server, and posts attention events to a channel
+// with buttons to act on them.
+package slack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+// maxSkew is how old a request timestamp may be before the request is
+// rejected as a possible replay.
+const maxSkew = 5 * time.Minute
+
+// Backend carries out the commands. The IPC server's serialized view of
+// the orchestrator's backend satisfies it.
+type Backend interface {
+	Agents() []agent.PersistedAgent
+	Merge(p ipc.MergeParams) ipc.MergeResult
+	Dismiss(p ipc.DismissParams) error
+	Subscribe(buffer int) (<-chan monitor.Event, func())
+}
+
+// Handler serves the Slack app's endpoints: POST /slack/commands for the
+// slash command and POST /slack/actions for button clicks. Every request
+// must carry a valid Slack signature for signingSecret.
+type Handler struct {
+	backend Backend
+	secret  string
+	client  *http.Client
+	now     func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time // delivery IDs handled within maxSkew
+}
+
+// NewHandler returns the Slack endpoints for backend.
+func NewHandler(backend Backend, signingSecret string) *Handler {
+	return &Handler{
+		backend: backend,
+		secret:  signingSecret,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		now:     time.Now,
+		seen:    make(map[string]time.Time),
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "read body", http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(r.Header, body); err != nil {
+		slog.Warn("rejected slack request", "path", r.URL.Path, "error", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "malformed form", http.StatusBadRequest)
+		return
+	}
+
+	// Slack redelivers a request it got no timely answer to; running a
+	// merge twice must not follow from a slow reply.
+	if h.duplicate(deliveryID(form)) {
+		slog.Info("dropped duplicate slack request", "path", r.URL.Path, "retry", r.Header.Get("X-Slack-Retry-Num"))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/slack/commands":
+		h.command(w, form)
+	case "/slack/actions":
+		h.action(w, form)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// verify checks Slack's request signature: an HMAC-SHA256 of
+// "v0:<timestamp>:<body>" keyed with the signing secret.
+func (h *Handler) verify(header http.Header, body []byte) error {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("bad timestamp %q", ts)
+	}
+	if d := h.now().Sub(time.Unix(sec, 0)); d > maxSkew || d < -maxSkew {
+		return fmt.Errorf("timestamp too old")
+	}
+	want := Sign(h.secret, ts, body)
+	if !hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(want)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// deliveryID returns what identifies a request across Slack's retries:
+// its event_id, or the trigger_id of a slash command or button click.
+func deliveryID(form url.Values) string {
+	if id := form.Get("event_id"); id != "" {
+		return id
+	}
+	if id := form.Get("trigger_id"); id != "" {
+		return id
+	}
+	var p struct {
+		EventID   string `json:"event_id"`
+		TriggerID string `json:"trigger_id"`
+	}
+	if json.Unmarshal([]byte(form.Get("payload")), &p) == nil {
+		if p.EventID != "" {
+			return p.EventID
+		}
+		return p.TriggerID
+	}
+	return ""
+}
+
+// duplicate records delivery id and reports whether it was already
+// handled. Requests without an ID are never duplicates; IDs are forgotten
+// once their requests would be rejected as stale anyway.
+func (h *Handler) duplicate(id string) bool {
+	if id == "" {
+		return false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	for k, t := range h.seen {
+		if now.Sub(t) > maxSkew {
+			delete(h.seen, k)
+		}
+	}
+	if _, ok := h.seen[id]; ok {
+		return true
+	}
+	h.seen[id] = now
+	return false
+}
+
+// Sign returns the Slack signature of body sent at timestamp ts.
+func Sign(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", ts)
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// reply is a message sent back to Slack.
+type reply struct {
+	ResponseType    string `json:"response_type,omitempty"` // "ephemeral" or "in_channel"
+	ReplaceOriginal bool   `json:"replace_original,omitempty"`
+	Text            string `json:"text"`
+}
+
+func writeReply(w http.ResponseWriter, r reply) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r)
+}
+
+// command handles `/mastermind <subcommand> [args]`.
+func (h *Handler) command(w http.ResponseWriter, form url.Values) {
+	fields := strings.Fields(form.Get("text"))
+	user := form.Get("user_name")
+	if len(fields) == 0 {
+		fields = []string{"help"}
+	}
+	switch fields[0] {
+	case "list", "ls":
+		writeReply(w, reply{ResponseType: "ephemeral", Text: h.list()})
+	case "merge":
+		if len(fields) != 2 {
+			writeReply(w, reply{ResponseType: "ephemeral", Text: "usage: /mastermind merge <agent id>"})
+			return
+		}
+		id := fields[1]
+		// Merging can take longer than Slack's 3s reply deadline, so
+		// acknowledge now and report the outcome to the response URL.
+		writeReply(w, reply{ResponseType: "in_channel", Text: fmt.Sprintf("%s is merging agent %s…", user, id)})
+		go h.respond(form.Get("response_url"), reply{ResponseType: "in_channel", Text: h.merge(id)})
+	default:
+		writeReply(w, reply{ResponseType: "ephemeral", Text: "usage: /mastermind list | /mastermind merge <agent id>"})
+	}
+}
+
+// actionPayload is the part of an interactive message payload used here.
+type actionPayload struct {
+	User struct {
+		Name string `json:"name"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// action handles a click on a button in an attention message.
+func (h *Handler) action(w http.ResponseWriter, form url.Values) {
+	var p actionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &p); err != nil || len(p.Actions) == 0 {
+		http.Error(w, "malformed payload", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+
+	act := p.Actions[0]
+	go func() {
+		var text string
+		switch act.ActionID {
+		case actionMerge:
+			text = h.merge(act.Value)
+		case actionDismiss:
+			text = fmt.Sprintf("Agent %s dismissed", act.Value)
+			if err := h.backend.Dismiss(ipc.DismissParams{ID: act.Value}); err != nil {
+				text = fmt.Sprintf("Dismiss %s failed: %v", act.Value, err)
+			}
+		default:
+			return
+		}
+		h.respond(p.ResponseURL, reply{ReplaceOriginal: true, Text: fmt.Sprintf("%s (by %s)", text, p.User.Name)})
+	}()
+}
+
+// list formats the agents, one per line.
+func (h *Handler) list() string {
+	agents := h.backend.Agents()
+	if len(agents) == 0 {
+		return "No agents"
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+	var b strings.Builder
+	b.WriteString("```\n")
+	for _, a := range agents {
+		status := string(a.Status)
+		if a.WaitingFor != "" {
+			status += " (" + a.WaitingFor + ")"
+		}
+		fmt.Fprintf(&b, "%-4s %-32s %s\n", a.ID, a.Branch, status)
+	}
+	b.WriteString("```")
+	return b.String()
+}
+
+// merge merges agent id with the options the merge wizard defaults to,
+// and describes the outcome.
+func (h *Handler) merge(id string) string {
+	res := h.backend.Merge(ipc.MergeParams{ID: id, Defaults: true})
+	switch {
+	case res.Success:
+		return fmt.Sprintf("Agent %s merged", id)
+	case res.Conflict:
+		return fmt.Sprintf("Agent %s has merge conflicts in %s — resolve them in the terminal", id, strings.Join(res.ConflictFiles, ", "))
+	default:
+		return fmt.Sprintf("Merge %s failed: %s", id, res.Error)
+	}
+}
+
+// respond posts a delayed reply to a Slack response URL.
+func (h *Handler) respond(responseURL string, r reply) {
+	if responseURL == "" {
+		return
+	}
+	body, _ := json.Marshal(r)
+	resp, err := h.client.Post(responseURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		slog.Warn("slack response failed", "error", err)
+		return
+	}
+	resp.Body.Close()
+}
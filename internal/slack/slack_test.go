@@ -0,0 +1,287 @@
+package slack
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+const secret = "s3cret"
+
+type fakeBackend struct {
+	mu        sync.Mutex
+	merged    []ipc.MergeParams
+	dismissed []string
+	bus       *monitor.Bus
+}
+
+func (b *fakeBackend) Agents() []agent.PersistedAgent {
+	return []agent.PersistedAgent{
+		{ID: "a2", Branch: "fix/y", Status: agent.StatusWaiting, WaitingFor: "input"},
+		{ID: "a1", Branch: "feat/x", Status: agent.StatusReviewReady},
+	}
+}
+
+func (b *fakeBackend) Merge(p ipc.MergeParams) ipc.MergeResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.merged = append(b.merged, p)
+	return ipc.MergeResult{Success: true}
+}
+
+func (b *fakeBackend) Dismiss(p ipc.DismissParams) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dismissed = append(b.dismissed, p.ID)
+	return nil
+}
+
+func (b *fakeBackend) Subscribe(buffer int) (<-chan monitor.Event, func()) {
+	ch := b.bus.Subscribe(buffer)
+	return ch, func() { b.bus.Unsubscribe(ch) }
+}
+
+// post sends a signed form request to the handler.
+func post(t *testing.T, srv *httptest.Server, path string, form url.Values, sign string) *http.Response {
+	t.Helper()
+	body := form.Encode()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", Sign(sign, ts, []byte(body)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// responseCatcher is a Slack response URL that records delayed replies.
+func responseCatcher(t *testing.T) (*httptest.Server, <-chan reply) {
+	t.Helper()
+	got := make(chan reply, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rep reply
+		json.NewDecoder(r.Body).Decode(&rep)
+		got <- rep
+	}))
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+func waitReply(t *testing.T, got <-chan reply) reply {
+	t.Helper()
+	select {
+	case r := <-got:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delayed reply")
+		return reply{}
+	}
+}
+
+func TestHandler_RejectsBadSignature(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(&fakeBackend{}, secret))
+	defer srv.Close()
+
+	resp := post(t, srv, "/slack/commands", url.Values{"text": {"list"}}, "wrong")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestHandler_RejectsStaleTimestamp(t *testing.T) {
+	h := NewHandler(&fakeBackend{}, secret)
+	h.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp := post(t, srv, "/slack/commands", url.Values{"text": {"list"}}, secret)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestHandler_ListCommand(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(&fakeBackend{}, secret))
+	defer srv.Close()
+
+	resp := post(t, srv, "/slack/commands", url.Values{"text": {"list"}}, secret)
+	defer resp.Body.Close()
+	var rep reply
+	if err := json.NewDecoder(resp.Body).Decode(&rep); err != nil {
+		t.Fatal(err)
+	}
+	if rep.ResponseType != "ephemeral" {
+		t.Errorf("response_type = %q, want ephemeral", rep.ResponseType)
+	}
+	i1, i2 := strings.Index(rep.Text, "a1"), strings.Index(rep.Text, "a2")
+	if i1 < 0 || i2 < 0 || i1 > i2 {
+		t.Errorf("list not sorted by ID:\n%s", rep.Text)
+	}
+	if !strings.Contains(rep.Text, "waiting (input)") {
+		t.Errorf("list missing waiting reason:\n%s", rep.Text)
+	}
+}
+
+func TestHandler_MergeCommand(t *testing.T) {
+	backend := &fakeBackend{}
+	srv := httptest.NewServer(NewHandler(backend, secret))
+	defer srv.Close()
+	respURL, got := responseCatcher(t)
+
+	resp := post(t, srv, "/slack/commands", url.Values{
+		"text":         {"merge a1"},
+		"user_name":    {"sam"},
+		"response_url": {respURL.URL},
+	}, secret)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "sam is merging agent a1") {
+		t.Errorf("acknowledgement = %s", body)
+	}
+
+	if rep := waitReply(t, got); rep.Text != "Agent a1 merged" {
+		t.Errorf("delayed reply = %q", rep.Text)
+	}
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.merged) != 1 || backend.merged[0].ID != "a1" || !backend.merged[0].Defaults {
+		t.Errorf("merged = %+v", backend.merged)
+	}
+}
+
+func TestHandler_DropsRedeliveries(t *testing.T) {
+	backend := &fakeBackend{}
+	srv := httptest.NewServer(NewHandler(backend, secret))
+	defer srv.Close()
+	respURL, got := responseCatcher(t)
+
+	form := url.Values{
+		"text":         {"merge a1"},
+		"trigger_id":   {"t-1"},
+		"response_url": {respURL.URL},
+	}
+	post(t, srv, "/slack/commands", form, secret).Body.Close()
+	waitReply(t, got)
+	resp := post(t, srv, "/slack/commands", form, secret)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("redelivery status = %d, want 200", resp.StatusCode)
+	}
+
+	form.Set("trigger_id", "t-2")
+	post(t, srv, "/slack/commands", form, secret).Body.Close()
+	waitReply(t, got)
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.merged) != 2 {
+		t.Errorf("merged %d times, want 2 (one per trigger)", len(backend.merged))
+	}
+}
+
+func TestHandler_DismissAction(t *testing.T) {
+	backend := &fakeBackend{}
+	srv := httptest.NewServer(NewHandler(backend, secret))
+	defer srv.Close()
+	respURL, got := responseCatcher(t)
+
+	payload := `{"user":{"name":"sam"},"response_url":"` + respURL.URL +
+		`","actions":[{"action_id":"dismiss","value":"a2"}]}`
+	resp := post(t, srv, "/slack/actions", url.Values{"payload": {payload}}, secret)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	rep := waitReply(t, got)
+	if !rep.ReplaceOriginal || rep.Text != "Agent a2 dismissed (by sam)" {
+		t.Errorf("delayed reply = %+v", rep)
+	}
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.dismissed) != 1 || backend.dismissed[0] != "a2" {
+		t.Errorf("dismissed = %v", backend.dismissed)
+	}
+}
+
+func TestPoster_PostsAttentionWithButtons(t *testing.T) {
+	var mu sync.Mutex
+	var auth string
+	var msg struct {
+		Channel string `json:"channel"`
+		Blocks  []struct {
+			Type     string `json:"type"`
+			Elements []struct {
+				ActionID string `json:"action_id"`
+				Value    string `json:"value"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path != "/chat.postMessage" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&msg)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer api.Close()
+
+	p := NewPoster("xoxb-1", "#agents", api.URL)
+	if err := p.Post(monitor.Attention{AgentID: "a1", Message: "a1 is ready for review"}, agent.StatusReviewReady); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if auth != "Bearer xoxb-1" || msg.Channel != "#agents" {
+		t.Errorf("auth = %q, channel = %q", auth, msg.Channel)
+	}
+	if len(msg.Blocks) != 2 || len(msg.Blocks[1].Elements) != 2 {
+		t.Fatalf("blocks = %+v", msg.Blocks)
+	}
+	if e := msg.Blocks[1].Elements; e[0].ActionID != actionMerge || e[1].ActionID != actionDismiss || e[0].Value != "a1" {
+		t.Errorf("buttons = %+v", e)
+	}
+}
+
+func TestPoster_NoMergeButtonWhileRunning(t *testing.T) {
+	var buttons int
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Blocks []struct {
+				Elements []json.RawMessage `json:"elements"`
+			} `json:"blocks"`
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+		buttons = len(msg.Blocks[1].Elements)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer api.Close()
+
+	p := NewPoster("xoxb-1", "#agents", api.URL)
+	if err := p.Post(monitor.Attention{AgentID: "a2", Message: "a2 needs input"}, agent.StatusWaiting); err != nil {
+		t.Fatal(err)
+	}
+	if buttons != 1 {
+		t.Errorf("buttons = %d, want only Dismiss", buttons)
+	}
+}
@@ -0,0 +1,116 @@
+package streamjson
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// syncWriter makes an io.Writer safe for concurrent use.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// Run starts args with task appended as the final argument and relays
+// the child's stdout. If task is empty it is first read from in. The
+// child's stderr goes straight to out. It returns the child's exit code.
+func Run(worktreePath string, args []string, task string, in io.Reader, out io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(out, "mastermind stream-relay: no command given")
+		return 2
+	}
+
+	if task == "" {
+		var err error
+		task, err = readTask(in, out)
+		if err != nil {
+			fmt.Fprintf(out, "mastermind stream-relay: %v\n", err)
+			return 2
+		}
+	}
+
+	// The child's stderr is copied on exec's goroutine while the relay
+	// writes from this one; serialize them.
+	out = &syncWriter{w: out}
+
+	cmd := exec.Command(args[0], append(args[1:], task)...)
+	cmd.Dir = worktreePath
+	cmd.Stderr = out
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		fmt.Fprintf(out, "mastermind stream-relay: %v\n", err)
+		return 1
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(out, "mastermind stream-relay: %v\n", err)
+		return 1
+	}
+
+	if err := Relay(worktreePath, stdout, out); err != nil {
+		fmt.Fprintf(out, "mastermind stream-relay: %v\n", err)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(out, "mastermind stream-relay: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// readTask reads a multi-line task terminated by an empty line or EOF.
+func readTask(in io.Reader, out io.Writer) (string, error) {
+	fmt.Fprintln(out, "Task for agent (finish with an empty line):")
+	scanner := bufio.NewScanner(in)
+	var lines []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if len(lines) == 0 {
+		return "", errors.New("empty task")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Main is the entry point for the hidden `mastermind stream-relay`
+// subcommand: `stream-relay [--task TASK] -- command...`.
+func Main(args []string) int {
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	var task string
+	if len(args) > 1 && args[0] == "--task" {
+		task, args = args[1], args[2:]
+	}
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	return Run(cwd, args, task, os.Stdin, os.Stdout)
+}
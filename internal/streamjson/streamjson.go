@@ -0,0 +1,194 @@
+// Package streamjson relays Claude Code's `--output-format stream-json`
+// output. The relay prints a readable transcript to the agent's pane and
+// records exact status and token counts in a state file, so the monitor
+// doesn't have to guess from pane content.
+package streamjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StateFileName is written by the relay into the worktree root.
+const StateFileName = ".mastermind-stream.json"
+
+// Relay status values.
+const (
+	StatusRunning = "running"
+	StatusIdle    = "idle"
+)
+
+// State is the relay's view of the agent, rewritten after every event.
+type State struct {
+	Status       string  `json:"status"`
+	Timestamp    int64   `json:"ts"`
+	SessionID    string  `json:"session_id,omitempty"`
+	Model        string  `json:"model,omitempty"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	IsError      bool    `json:"is_error,omitempty"`
+}
+
+// ReadState reads the relay state file from the given worktree path.
+// Returns nil, nil if the file does not exist.
+func ReadState(worktreePath string) (*State, error) {
+	data, err := os.ReadFile(filepath.Join(worktreePath, StateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read stream state: %w", err)
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse stream state: %w", err)
+	}
+	return &st, nil
+}
+
+// writeState writes the state file atomically.
+func writeState(worktreePath string, st *State) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(worktreePath, StateFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// event is the subset of a stream-json line the relay understands.
+type event struct {
+	Type      string  `json:"type"`
+	Subtype   string  `json:"subtype"`
+	SessionID string  `json:"session_id"`
+	Model     string  `json:"model"`
+	IsError   bool    `json:"is_error"`
+	Result    string  `json:"result"`
+	CostUSD   float64 `json:"total_cost_usd"`
+	Usage     *usage  `json:"usage"`
+	Message   *struct {
+		Model   string    `json:"model"`
+		Content []content `json:"content"`
+		Usage   *usage    `json:"usage"`
+	} `json:"message"`
+}
+
+type usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+func (u *usage) input() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	Name string `json:"name"`
+}
+
+// Relay reads stream-json lines from r until EOF, writing a readable
+// transcript to out and the current state to worktreePath. Lines that
+// are not valid JSON are passed through unchanged.
+func Relay(worktreePath string, r io.Reader, out io.Writer) error {
+	st := &State{Status: StatusRunning}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var ev event
+		if err := json.Unmarshal(line, &ev); err != nil {
+			fmt.Fprintln(out, string(line))
+			continue
+		}
+		if !apply(st, &ev, out) {
+			continue
+		}
+		st.Timestamp = time.Now().Unix()
+		if err := writeState(worktreePath, st); err != nil {
+			fmt.Fprintf(out, "mastermind: write stream state: %v\n", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// apply folds ev into st and prints it. Returns false for events that
+// don't change state.
+func apply(st *State, ev *event, out io.Writer) bool {
+	if ev.SessionID != "" {
+		st.SessionID = ev.SessionID
+	}
+
+	switch ev.Type {
+	case "system":
+		if ev.Subtype != "init" {
+			return false
+		}
+		st.Status = StatusRunning
+		if ev.Model != "" {
+			st.Model = ev.Model
+		}
+		fmt.Fprintf(out, "● session started (%s)\n", st.Model)
+
+	case "assistant":
+		st.Status = StatusRunning
+		if ev.Message == nil {
+			return true
+		}
+		if ev.Message.Model != "" {
+			st.Model = ev.Message.Model
+		}
+		if u := ev.Message.Usage; u != nil {
+			st.InputTokens += u.input()
+			st.OutputTokens += u.OutputTokens
+		}
+		for _, c := range ev.Message.Content {
+			switch c.Type {
+			case "text":
+				if text := strings.TrimSpace(c.Text); text != "" {
+					fmt.Fprintln(out, text)
+				}
+			case "tool_use":
+				fmt.Fprintf(out, "→ %s\n", c.Name)
+			}
+		}
+
+	case "user":
+		// Tool results; the agent is still mid-turn.
+		st.Status = StatusRunning
+
+	case "result":
+		st.Status = StatusIdle
+		st.IsError = ev.IsError
+		st.CostUSD = ev.CostUSD
+		// The result carries authoritative session totals.
+		if ev.Usage != nil {
+			st.InputTokens = ev.Usage.input()
+			st.OutputTokens = ev.Usage.OutputTokens
+		}
+		label := "done"
+		if ev.IsError {
+			label = "failed"
+		}
+		fmt.Fprintf(out, "● %s — $%.2f, %d in / %d out tokens\n", label, st.CostUSD, st.InputTokens, st.OutputTokens)
+
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package streamjson
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const sampleStream = `{"type":"system","subtype":"init","session_id":"s1","model":"claude-sonnet"}
+{"type":"assistant","message":{"model":"claude-sonnet","content":[{"type":"text","text":"Looking at the code"},{"type":"tool_use","name":"Read"}],"usage":{"input_tokens":10,"cache_read_input_tokens":5,"output_tokens":3}},"session_id":"s1"}
+{"type":"user","message":{"content":[{"type":"tool_result"}]},"session_id":"s1"}
+not json at all
+{"type":"result","subtype":"success","is_error":false,"total_cost_usd":0.25,"usage":{"input_tokens":100,"output_tokens":40},"session_id":"s1"}
+`
+
+func TestRelay(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+
+	if err := Relay(dir, strings.NewReader(sampleStream), &out); err != nil {
+		t.Fatalf("Relay: %v", err)
+	}
+
+	st, err := ReadState(dir)
+	if err != nil || st == nil {
+		t.Fatalf("ReadState = %v, %v", st, err)
+	}
+	if st.Status != StatusIdle {
+		t.Errorf("status = %q, want %q", st.Status, StatusIdle)
+	}
+	if st.SessionID != "s1" || st.Model != "claude-sonnet" {
+		t.Errorf("session/model = %q/%q", st.SessionID, st.Model)
+	}
+	if st.InputTokens != 100 || st.OutputTokens != 40 {
+		t.Errorf("tokens = %d/%d, want result totals 100/40", st.InputTokens, st.OutputTokens)
+	}
+	if st.CostUSD != 0.25 {
+		t.Errorf("cost = %v, want 0.25", st.CostUSD)
+	}
+
+	transcript := out.String()
+	for _, want := range []string{"Looking at the code", "→ Read", "not json at all", "● done"} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("transcript missing %q:\n%s", want, transcript)
+		}
+	}
+}
+
+func TestRelay_MidTurnIsRunning(t *testing.T) {
+	dir := t.TempDir()
+	lines := strings.SplitAfter(sampleStream, "\n")
+	partial := strings.Join(lines[:2], "")
+
+	if err := Relay(dir, strings.NewReader(partial), &bytes.Buffer{}); err != nil {
+		t.Fatalf("Relay: %v", err)
+	}
+	st, _ := ReadState(dir)
+	if st == nil || st.Status != StatusRunning {
+		t.Fatalf("state = %+v, want running", st)
+	}
+	if st.InputTokens != 15 || st.OutputTokens != 3 {
+		t.Errorf("tokens = %d/%d, want 15/3", st.InputTokens, st.OutputTokens)
+	}
+}
+
+func TestReadState_Missing(t *testing.T) {
+	st, err := ReadState(t.TempDir())
+	if err != nil || st != nil {
+		t.Errorf("ReadState = %v, %v; want nil, nil", st, err)
+	}
+}
+
+func TestRun_ExitCodeAndTask(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	// The task is appended as the last argument: $0 inside sh -c.
+	code := Run(dir, []string{"sh", "-c", `echo "got: $0"; exit 3`}, "", strings.NewReader("fix the bug\n\n"), &out)
+
+	if code != 3 {
+		t.Errorf("exit code = %d, want 3", code)
+	}
+	if !strings.Contains(out.String(), "got: fix the bug") {
+		t.Errorf("output = %q, want task passed through", out.String())
+	}
+}
+
+func TestRun_EmptyTask(t *testing.T) {
+	code := Run(t.TempDir(), []string{"true"}, "", strings.NewReader("\n"), &bytes.Buffer{})
+	if code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+}
+
+func TestRun_GivenTaskSkipsPrompt(t *testing.T) {
+	var out bytes.Buffer
+	code := Run(t.TempDir(), []string{"sh", "-c", `echo "got: $0"`}, "finish this", strings.NewReader(""), &out)
+
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	if strings.Contains(out.String(), "Task for agent") {
+		t.Error("should not prompt when a task is given")
+	}
+	if !strings.Contains(out.String(), "got: finish this") {
+		t.Errorf("output = %q, want task passed through", out.String())
+	}
+}
@@ -5,6 +5,9 @@ type Member struct {
 	Name      string `json:"name"`
 	AgentID   string `json:"agent_id"`
 	AgentType string `json:"agent_type"` // "lead" or "teammate"
+	// TmuxPaneID is the pane a teammate runs in when teammates get their
+	// own tmux panes; empty for in-process teammates.
+	TmuxPaneID string `json:"tmux_pane_id,omitempty"`
 }
 
 // TeamConfig is the on-disk structure of a team's config.json.
@@ -0,0 +1,105 @@
+package ticket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Jira talks to the Jira Cloud REST API with an account's API token.
+type Jira struct {
+	baseURL string
+	email   string
+	token   string
+	client  *http.Client
+}
+
+// NewJira returns a Jira tracker for the site at baseURL, e.g.
+// "https://acme.atlassian.net".
+func NewJira(baseURL, email, token string) *Jira {
+	return &Jira{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		email:   email,
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// do sends a request to an API path and decodes a JSON response into out
+// if it is non-nil.
+func (j *Jira) do(method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, j.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(j.email, j.token)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jira: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("jira: %s %s: HTTP %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("jira: decode response: %w", err)
+	}
+	return nil
+}
+
+func (j *Jira) Title(id string) (string, error) {
+	var issue struct {
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+	}
+	if err := j.do(http.MethodGet, "/rest/api/3/issue/"+url.PathEscape(id)+"?fields=summary", nil, &issue); err != nil {
+		return "", err
+	}
+	return issue.Fields.Summary, nil
+}
+
+// Transition applies the first available transition that leads to state
+// (or is itself named state).
+func (j *Jira) Transition(id, state string) error {
+	path := "/rest/api/3/issue/" + url.PathEscape(id) + "/transitions"
+	var list struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := j.do(http.MethodGet, path, nil, &list); err != nil {
+		return err
+	}
+	for _, t := range list.Transitions {
+		if strings.EqualFold(t.To.Name, state) || strings.EqualFold(t.Name, state) {
+			return j.do(http.MethodPost, path, map[string]any{"transition": map[string]string{"id": t.ID}}, nil)
+		}
+	}
+	return fmt.Errorf("jira: %s has no transition to %q", id, state)
+}
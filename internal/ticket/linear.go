@@ -0,0 +1,116 @@
+package ticket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LinearAPIURL is Linear's GraphQL endpoint.
+const LinearAPIURL = "https://api.linear.app/graphql"
+
+// Linear talks to Linear's GraphQL API with a personal API key.
+type Linear struct {
+	token  string
+	url    string
+	client *http.Client
+}
+
+// NewLinear returns a Linear tracker. url is usually LinearAPIURL.
+func NewLinear(token, url string) *Linear {
+	return &Linear{token: token, url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// query runs a GraphQL query and decodes its data into out.
+func (l *Linear) query(q string, vars map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": q, "variables": vars})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", l.token)
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("linear: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("linear: decode response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("linear: %s", result.Errors[0].Message)
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+func (l *Linear) Title(id string) (string, error) {
+	var data struct {
+		Issue struct {
+			Title string `json:"title"`
+		} `json:"issue"`
+	}
+	if err := l.query(`query($id: String!) { issue(id: $id) { title } }`, map[string]any{"id": id}, &data); err != nil {
+		return "", err
+	}
+	return data.Issue.Title, nil
+}
+
+func (l *Linear) Transition(id, state string) error {
+	var data struct {
+		Issue struct {
+			ID   string `json:"id"`
+			Team struct {
+				States struct {
+					Nodes []struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"states"`
+			} `json:"team"`
+		} `json:"issue"`
+	}
+	err := l.query(`query($id: String!) { issue(id: $id) { id team { states { nodes { id name } } } } }`,
+		map[string]any{"id": id}, &data)
+	if err != nil {
+		return err
+	}
+	stateID := ""
+	for _, s := range data.Issue.Team.States.Nodes {
+		if strings.EqualFold(s.Name, state) {
+			stateID = s.ID
+			break
+		}
+	}
+	if stateID == "" {
+		return fmt.Errorf("linear: %s has no state %q", id, state)
+	}
+
+	var updated struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+		} `json:"issueUpdate"`
+	}
+	err = l.query(`mutation($id: String!, $state: String!) { issueUpdate(id: $id, input: {stateId: $state}) { success } }`,
+		map[string]any{"id": data.Issue.ID, "state": stateID}, &updated)
+	if err != nil {
+		return err
+	}
+	if !updated.IssueUpdate.Success {
+		return fmt.Errorf("linear: moving %s to %q failed", id, state)
+	}
+	return nil
+}
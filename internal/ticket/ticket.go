@@ -0,0 +1,85 @@
+// Package ticket links agents to Linear or Jira tickets. Ticket IDs are
+// parsed and turned into branch names locally; with a configured Tracker,
+// ticket titles are looked up and tickets are moved between workflow
+// states as their agents progress.
+package ticket
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// idPattern matches Linear and Jira style IDs such as ENG-123.
+var idPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*-[0-9]+$`)
+
+// maxBranchLen caps generated branch names.
+const maxBranchLen = 50
+
+// Parse validates a ticket ID and returns it upper-cased.
+func Parse(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !idPattern.MatchString(s) {
+		return "", fmt.Errorf("%q is not a ticket ID (e.g. ENG-123)", s)
+	}
+	return strings.ToUpper(s), nil
+}
+
+// BranchName returns a branch name for a ticket: the lower-cased ID
+// followed by a slug of its title, e.g. "eng-123-add-login-page".
+func BranchName(id, title string) string {
+	name := strings.ToLower(id)
+	var slug strings.Builder
+	dash := true
+	for _, r := range strings.ToLower(title) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	for _, word := range strings.Split(strings.TrimSuffix(slug.String(), "-"), "-") {
+		if word == "" || len(name)+1+len(word) > maxBranchLen {
+			break
+		}
+		name += "-" + word
+	}
+	return name
+}
+
+// Context is the background handed to an agent working on a ticket, so
+// its commits and pull requests reference the ticket.
+func Context(id, title string) string {
+	subject := id
+	if title != "" {
+		subject = fmt.Sprintf("%s (%s)", id, title)
+	}
+	return fmt.Sprintf("This work is for ticket %s. Reference %s in commit messages and pull request descriptions.", subject, id)
+}
+
+// Tracker is the API of a ticket tracker.
+type Tracker interface {
+	// Title returns the ticket's title.
+	Title(id string) (string, error)
+	// Transition moves the ticket to the named workflow state.
+	Transition(id, state string) error
+}
+
+// New returns the tracker for provider, or nil if provider is empty.
+func New(provider, token, jiraURL, jiraEmail string) (Tracker, error) {
+	switch provider {
+	case "":
+		return nil, nil
+	case "linear":
+		return NewLinear(token, LinearAPIURL), nil
+	case "jira":
+		if jiraURL == "" {
+			return nil, fmt.Errorf("jira provider needs jira_url")
+		}
+		return NewJira(jiraURL, jiraEmail, token), nil
+	default:
+		return nil, fmt.Errorf("unknown ticket provider %q", provider)
+	}
+}
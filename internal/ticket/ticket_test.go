@@ -0,0 +1,139 @@
+package ticket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+		ok       bool
+	}{
+		{"ENG-123", "ENG-123", true},
+		{" eng-7 ", "ENG-7", true},
+		{"PROJ2-1", "PROJ2-1", true},
+		{"feat/x", "", false},
+		{"ENG-", "", false},
+		{"123-4", "", false},
+	} {
+		got, err := Parse(tc.in)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("Parse(%q) = %q, %v", tc.in, got, err)
+		}
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	for _, tc := range []struct {
+		id, title, want string
+	}{
+		{"ENG-123", "Add login page", "eng-123-add-login-page"},
+		{"ENG-123", "  Fix: crash on *empty* input! ", "eng-123-fix-crash-on-empty-input"},
+		{"ENG-123", "", "eng-123"},
+		{"ENG-1", "Make the onboarding flow remember where the user left off last time", "eng-1-make-the-onboarding-flow-remember-where-the"},
+	} {
+		got := BranchName(tc.id, tc.title)
+		if got != tc.want {
+			t.Errorf("BranchName(%q, %q) = %q, want %q", tc.id, tc.title, got, tc.want)
+		}
+		if len(got) > maxBranchLen {
+			t.Errorf("BranchName(%q, %q) is %d chars", tc.id, tc.title, len(got))
+		}
+	}
+}
+
+func TestLinear(t *testing.T) {
+	var updated map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_x" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case strings.Contains(req.Query, "issueUpdate"):
+			updated = req.Variables
+			w.Write([]byte(`{"data":{"issueUpdate":{"success":true}}}`))
+		case strings.Contains(req.Query, "states"):
+			w.Write([]byte(`{"data":{"issue":{"id":"uuid-1","team":{"states":{"nodes":[
+				{"id":"s1","name":"Todo"},{"id":"s2","name":"In Review"}]}}}}}`))
+		default:
+			w.Write([]byte(`{"data":{"issue":{"title":"Add login page"}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	l := NewLinear("lin_api_x", srv.URL)
+	if title, err := l.Title("ENG-1"); err != nil || title != "Add login page" {
+		t.Errorf("Title = %q, %v", title, err)
+	}
+	if err := l.Transition("ENG-1", "in review"); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if updated["id"] != "uuid-1" || updated["state"] != "s2" {
+		t.Errorf("issueUpdate variables = %v", updated)
+	}
+	if err := l.Transition("ENG-1", "Shipped"); err == nil {
+		t.Error("expected error for unknown state")
+	}
+}
+
+func TestLinear_GraphQLError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":[{"message":"Entity not found"}]}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewLinear("x", srv.URL).Title("ENG-404"); err == nil || !strings.Contains(err.Error(), "Entity not found") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestJira(t *testing.T) {
+	var posted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "me@acme.dev" || pass != "tok" {
+			t.Errorf("basic auth = %q:%q", user, pass)
+		}
+		switch {
+		case r.URL.Path == "/rest/api/3/issue/ENG-1":
+			w.Write([]byte(`{"fields":{"summary":"Add login page"}}`))
+		case r.URL.Path == "/rest/api/3/issue/ENG-1/transitions" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"transitions":[{"id":"11","name":"Start","to":{"name":"In Progress"}},
+				{"id":"21","name":"Review","to":{"name":"In Review"}}]}`))
+		case r.URL.Path == "/rest/api/3/issue/ENG-1/transitions":
+			var body struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			posted = body.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	j := NewJira(srv.URL+"/", "me@acme.dev", "tok")
+	if title, err := j.Title("ENG-1"); err != nil || title != "Add login page" {
+		t.Errorf("Title = %q, %v", title, err)
+	}
+	if err := j.Transition("ENG-1", "In Review"); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if posted != "21" {
+		t.Errorf("posted transition = %q, want 21", posted)
+	}
+	if _, err := j.Title("ENG-2"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Title of missing issue: err = %v", err)
+	}
+}
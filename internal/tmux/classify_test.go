@@ -317,6 +317,7 @@ func TestPaneMonitor_Remove(t *testing.T) {
 	m.mu.Lock()
 	m.lastContent["%0"] = []byte("abc")
 	m.stableCount["%0"] = 3
+	m.unread["%0"] = []byte("abc")
 	m.mu.Unlock()
 
 	m.Remove("%0")
@@ -329,4 +330,40 @@ func TestPaneMonitor_Remove(t *testing.T) {
 	if _, ok := m.stableCount["%0"]; ok {
 		t.Error("Remove should clear stableCount entry")
 	}
+	if _, ok := m.unread["%0"]; ok {
+		t.Error("Remove should clear unread entry")
+	}
+}
+
+func TestPaneMonitor_StatuslineReusesCapture(t *testing.T) {
+	m := NewPaneMonitor()
+	m.mu.Lock()
+	m.unread["%0"] = []byte("➜  proj [ctx: 12%] $1.5000  opus\n")
+	m.mu.Unlock()
+
+	if got := m.Statusline("%0"); got == nil || got.CostUSD != 1.5 {
+		t.Fatalf("Statusline = %+v, want the unread capture parsed", got)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.unread["%0"]; ok {
+		t.Error("Statusline should consume the capture it parsed")
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	content := "first\n\nDo you want to run `rm -rf build`?  \n  \n❯ 1. Yes\n  2. No\n\n"
+	got := tailLines(content, 3)
+	want := []string{"Do you want to run `rm -rf build`?", "❯ 1. Yes", "  2. No"}
+	if len(got) != len(want) {
+		t.Fatalf("tailLines = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if got := tailLines("", 3); len(got) != 0 {
+		t.Errorf("tailLines of empty content = %q, want none", got)
+	}
 }
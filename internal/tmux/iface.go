@@ -5,6 +5,9 @@ type PaneInfo struct {
 	WindowID string
 	Dead     bool
 	ExitCode int
+	// ServerPID is the PID of the tmux server the pane is on; a new PID
+	// means the server was restarted and pane IDs may have been reused.
+	ServerPID int
 }
 
 // WindowInfo holds metadata about a tmux window returned by ListWindows.
@@ -17,9 +20,14 @@ type WindowInfo struct {
 type TmuxOps interface {
 	NewWindow(session, name, dir string, command []string) (string, error)
 	SplitWindow(paneID, dir string, horizontal bool, sizePercent int, command []string) (string, error)
+	SplitWindowDetached(paneID, dir string, horizontal bool, sizePercent int, command []string) (string, error)
 	KillWindow(target string) error
 	KillPane(paneID string) error
+	RespawnPane(paneID string) error
+	RespawnPaneWith(paneID, dir string, command []string) error
 	SendKeys(paneID string, keys ...string) error
+	PasteText(paneID, text string) error
+	PanePID(paneID string) (int, error)
 	SelectWindow(target string) error
 	SelectPane(paneID string) error
 	PaneExistsInWindow(paneID, windowID string) bool
@@ -29,11 +37,18 @@ type TmuxOps interface {
 	ListWindows(session string) (map[string]WindowInfo, error)
 	RenameWindow(target, name string) error
 	CurrentWindowName(target string) (string, error)
+	RingBell(target string) error
+	SessionForPane(paneID string) (id, name string, err error)
+	ListSessions() ([]string, error)
+	CreateSession(name string) error
+	SwitchClient(target string) error
 }
 
 // PaneStatusChecker abstracts pane monitoring for testing.
 type PaneStatusChecker interface {
 	GetPaneStatus(paneID string) (PaneStatus, error)
+	Statusline(paneID string) *StatuslineFromPane
+	Tail(paneID string, n int) []string
 	Remove(paneID string)
 }
 
@@ -48,6 +63,10 @@ func (RealTmux) SplitWindow(paneID, dir string, horizontal bool, sizePercent int
 	return SplitWindow(paneID, dir, horizontal, sizePercent, command)
 }
 
+func (RealTmux) SplitWindowDetached(paneID, dir string, horizontal bool, sizePercent int, command []string) (string, error) {
+	return SplitWindowDetached(paneID, dir, horizontal, sizePercent, command)
+}
+
 func (RealTmux) KillWindow(target string) error {
 	return KillWindow(target)
 }
@@ -56,10 +75,26 @@ func (RealTmux) KillPane(paneID string) error {
 	return KillPane(paneID)
 }
 
+func (RealTmux) RespawnPane(paneID string) error {
+	return RespawnPane(paneID)
+}
+
+func (RealTmux) RespawnPaneWith(paneID, dir string, command []string) error {
+	return RespawnPaneWith(paneID, dir, command)
+}
+
 func (RealTmux) SendKeys(paneID string, keys ...string) error {
 	return SendKeys(paneID, keys...)
 }
 
+func (RealTmux) PasteText(paneID, text string) error {
+	return PasteText(paneID, text)
+}
+
+func (RealTmux) PanePID(paneID string) (int, error) {
+	return PanePID(paneID)
+}
+
 func (RealTmux) SelectWindow(target string) error {
 	return SelectWindow(target)
 }
@@ -95,3 +130,23 @@ func (RealTmux) RenameWindow(target, name string) error {
 func (RealTmux) CurrentWindowName(target string) (string, error) {
 	return CurrentWindowName(target)
 }
+
+func (RealTmux) RingBell(target string) error {
+	return RingBell(target)
+}
+
+func (RealTmux) SessionForPane(paneID string) (string, string, error) {
+	return SessionForPane(paneID)
+}
+
+func (RealTmux) ListSessions() ([]string, error) {
+	return ListSessions()
+}
+
+func (RealTmux) CreateSession(name string) error {
+	return CreateSession(name)
+}
+
+func (RealTmux) SwitchClient(target string) error {
+	return SwitchClient(target)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -26,6 +27,7 @@ type PaneMonitor struct {
 	mu          sync.Mutex
 	lastContent map[string][]byte // paneID → raw content of last capture
 	stableCount map[string]int    // paneID → number of consecutive polls with same content
+	unread      map[string][]byte // paneID → latest capture not yet parsed by Statusline
 	Patterns    MonitorPatterns
 }
 
@@ -33,6 +35,7 @@ func NewPaneMonitor() *PaneMonitor {
 	return &PaneMonitor{
 		lastContent: make(map[string][]byte),
 		stableCount: make(map[string]int),
+		unread:      make(map[string][]byte),
 		Patterns:    DefaultPatterns,
 	}
 }
@@ -42,6 +45,7 @@ func (m *PaneMonitor) Remove(paneID string) {
 	defer m.mu.Unlock()
 	delete(m.lastContent, paneID)
 	delete(m.stableCount, paneID)
+	delete(m.unread, paneID)
 }
 
 func (m *PaneMonitor) GetPaneStatus(paneID string) (PaneStatus, error) {
@@ -74,6 +78,53 @@ func (m *PaneMonitor) GetPaneStatus(paneID string) (PaneStatus, error) {
 	return status, nil
 }
 
+// Statusline parses the statusline shown in the pane's visible content,
+// or returns nil when there is none. It reuses the capture made by the
+// last GetPaneStatus or Tail since it was called, and only captures the
+// pane itself when there is none.
+func (m *PaneMonitor) Statusline(paneID string) *StatuslineFromPane {
+	m.mu.Lock()
+	content, ok := m.unread[paneID]
+	delete(m.unread, paneID)
+	m.mu.Unlock()
+	if !ok {
+		content = capturePane(paneID)
+	}
+	return ParseStatuslineFromContent(string(content))
+}
+
+// Tail returns the last n non-empty lines of the pane's visible content,
+// top to bottom.
+func (m *PaneMonitor) Tail(paneID string, n int) []string {
+	return tailLines(string(m.capture(paneID)), n)
+}
+
+// capture captures the pane's visible content and keeps it for the next
+// Statusline.
+func (m *PaneMonitor) capture(paneID string) []byte {
+	content := capturePane(paneID)
+	if len(content) > 0 {
+		m.mu.Lock()
+		m.unread[paneID] = content
+		m.mu.Unlock()
+	}
+	return content
+}
+
+// tailLines returns the last n non-empty lines of content, trimmed of
+// trailing whitespace.
+func tailLines(content string, n int) []string {
+	lines := strings.Split(content, "\n")
+	var tail []string
+	for i := len(lines) - 1; i >= 0 && len(tail) < n; i-- {
+		if line := strings.TrimRight(lines[i], " \t\r"); strings.TrimSpace(line) != "" {
+			tail = append(tail, line)
+		}
+	}
+	slices.Reverse(tail)
+	return tail
+}
+
 // classifyInfo holds the result of pane content classification.
 type classifyInfo struct {
 	waitingFor      string
@@ -81,7 +132,7 @@ type classifyInfo struct {
 }
 
 func (m *PaneMonitor) detectWaiting(paneID string) classifyInfo {
-	content := capturePane(paneID)
+	content := m.capture(paneID)
 	if len(content) == 0 {
 		return classifyInfo{}
 	}
@@ -0,0 +1,139 @@
+package tmux
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Retrying wraps a TmuxOps and retries failed calls with exponential
+// backoff, riding out transient failures such as "server not responding"
+// while the tmux server is under load.
+//
+// Only calls that are safe to repeat are retried. Creating windows or
+// panes, sending keys and ringing the bell may have taken effect before
+// the error was reported, and kills usually fail because the target is
+// already gone, so those are passed through unchanged.
+type Retrying struct {
+	TmuxOps
+	attempts int
+	backoff  time.Duration
+	sleep    func(time.Duration)
+}
+
+// NewRetrying returns ops wrapped to retry idempotent calls up to retries
+// extra times, waiting backoff before the first retry and doubling it after
+// each one. retries <= 0 disables retrying.
+func NewRetrying(ops TmuxOps, retries int, backoff time.Duration) *Retrying {
+	return &Retrying{
+		TmuxOps:  ops,
+		attempts: max(retries, 0) + 1,
+		backoff:  backoff,
+		sleep:    time.Sleep,
+	}
+}
+
+// do calls fn until it succeeds or the attempts are used up, returning the
+// last error.
+func (r *Retrying) do(op string, fn func() error) error {
+	wait := r.backoff
+	var err error
+	for i := 0; i < r.attempts; i++ {
+		if i > 0 {
+			slog.Debug("retrying tmux call", "op", op, "attempt", i+1, "error", err)
+			r.sleep(wait)
+			wait *= 2
+		}
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (r *Retrying) SelectWindow(target string) error {
+	return r.do("select-window", func() error { return r.TmuxOps.SelectWindow(target) })
+}
+
+func (r *Retrying) SelectPane(paneID string) error {
+	return r.do("select-pane", func() error { return r.TmuxOps.SelectPane(paneID) })
+}
+
+func (r *Retrying) WindowIDForPane(paneID string) (string, error) {
+	var id string
+	err := r.do("window-id", func() (err error) {
+		id, err = r.TmuxOps.WindowIDForPane(paneID)
+		return err
+	})
+	return id, err
+}
+
+func (r *Retrying) PanePID(paneID string) (int, error) {
+	var pid int
+	err := r.do("pane-pid", func() (err error) {
+		pid, err = r.TmuxOps.PanePID(paneID)
+		return err
+	})
+	return pid, err
+}
+
+func (r *Retrying) ListAllPanes(session string) (map[string]PaneInfo, error) {
+	var panes map[string]PaneInfo
+	err := r.do("list-panes", func() (err error) {
+		panes, err = r.TmuxOps.ListAllPanes(session)
+		return err
+	})
+	return panes, err
+}
+
+func (r *Retrying) ListPanesInWindow(windowID string) ([]string, error) {
+	var panes []string
+	err := r.do("list-panes", func() (err error) {
+		panes, err = r.TmuxOps.ListPanesInWindow(windowID)
+		return err
+	})
+	return panes, err
+}
+
+func (r *Retrying) ListWindows(session string) (map[string]WindowInfo, error) {
+	var windows map[string]WindowInfo
+	err := r.do("list-windows", func() (err error) {
+		windows, err = r.TmuxOps.ListWindows(session)
+		return err
+	})
+	return windows, err
+}
+
+func (r *Retrying) RenameWindow(target, name string) error {
+	return r.do("rename-window", func() error { return r.TmuxOps.RenameWindow(target, name) })
+}
+
+func (r *Retrying) CurrentWindowName(target string) (string, error) {
+	var name string
+	err := r.do("window-name", func() (err error) {
+		name, err = r.TmuxOps.CurrentWindowName(target)
+		return err
+	})
+	return name, err
+}
+
+func (r *Retrying) SessionForPane(paneID string) (string, string, error) {
+	var id, name string
+	err := r.do("session-for-pane", func() (err error) {
+		id, name, err = r.TmuxOps.SessionForPane(paneID)
+		return err
+	})
+	return id, name, err
+}
+
+func (r *Retrying) ListSessions() ([]string, error) {
+	var names []string
+	err := r.do("list-sessions", func() (err error) {
+		names, err = r.TmuxOps.ListSessions()
+		return err
+	})
+	return names, err
+}
+
+func (r *Retrying) SwitchClient(target string) error {
+	return r.do("switch-client", func() error { return r.TmuxOps.SwitchClient(target) })
+}
@@ -0,0 +1,52 @@
+package tmux
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type flakyTmux struct {
+	TmuxOps
+	failures int
+	calls    int
+}
+
+func (f *flakyTmux) ListAllPanes(session string) (map[string]PaneInfo, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("server not responding")
+	}
+	return map[string]PaneInfo{"%1": {WindowID: "@1"}}, nil
+}
+
+func TestRetrying_RecoversFromTransientFailure(t *testing.T) {
+	inner := &flakyTmux{failures: 2}
+	r := NewRetrying(inner, 2, 10*time.Millisecond)
+	var waits []time.Duration
+	r.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	panes, err := r.ListAllPanes("s")
+	if err != nil {
+		t.Fatalf("ListAllPanes: %v", err)
+	}
+	if len(panes) != 1 || inner.calls != 3 {
+		t.Errorf("panes = %v after %d calls, want 1 pane after 3", panes, inner.calls)
+	}
+	if len(waits) != 2 || waits[0] != 10*time.Millisecond || waits[1] != 20*time.Millisecond {
+		t.Errorf("waits = %v, want [10ms 20ms]", waits)
+	}
+}
+
+func TestRetrying_GivesUp(t *testing.T) {
+	inner := &flakyTmux{failures: 5}
+	r := NewRetrying(inner, 1, 0)
+	r.sleep = func(time.Duration) {}
+
+	if _, err := r.ListAllPanes("s"); err == nil {
+		t.Fatal("expected error after retries are exhausted")
+	}
+	if inner.calls != 2 {
+		t.Errorf("calls = %d, want 2", inner.calls)
+	}
+}
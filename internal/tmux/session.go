@@ -19,6 +19,21 @@ func CurrentSession() (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// SessionForPane returns the ID (e.g. "$3") and name of the session that
+// currently contains paneID. The ID stays the same when the session is
+// renamed, so it is the stable way to target a session.
+func SessionForPane(paneID string) (id, name string, err error) {
+	out, err := exec.Command("tmux", "display-message", "-t", paneID, "-p", "#{session_id}|#{session_name}").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("get session for pane %s: %w", paneID, err)
+	}
+	id, name, ok := strings.Cut(strings.TrimSpace(string(out)), "|")
+	if !ok || id == "" {
+		return "", "", fmt.Errorf("unexpected session for pane %s: %q", paneID, strings.TrimSpace(string(out)))
+	}
+	return id, name, nil
+}
+
 func SessionExists(name string) bool {
 	err := exec.Command("tmux", "has-session", "-t", name).Run()
 	return err == nil
@@ -32,6 +47,30 @@ func CreateSession(name string) error {
 	return nil
 }
 
+// ListSessions returns the names of all sessions on the tmux server.
+func ListSessions() ([]string, error) {
+	out, err := exec.Command("tmux", "list-sessions", "-F", "#{session_name}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("list tmux sessions: %w", err)
+	}
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+// SwitchClient switches the current client to the session containing
+// target (a session, window or pane).
+func SwitchClient(target string) error {
+	if err := exec.Command("tmux", "switch-client", "-t", target).Run(); err != nil {
+		return fmt.Errorf("switch tmux client to %s: %w", target, err)
+	}
+	return nil
+}
+
 func AttachSession(name string) error {
 	cmd := exec.Command("tmux", "attach-session", "-t", name)
 	cmd.Stdin = os.Stdin
@@ -50,6 +89,32 @@ func RenameWindow(target, name string) error {
 	return nil
 }
 
+// AttentionPrefix is prepended to an agent window's name while the agent
+// waits for permission.
+const AttentionPrefix = "❗ "
+
+// RingBell enables monitor-bell on the window identified by target and
+// writes a BEL to its active pane's tty, so tmux raises the window's bell
+// flag in the status line while another window is active.
+func RingBell(target string) error {
+	if err := exec.Command("tmux", "set-window-option", "-t", target, "monitor-bell", "on").Run(); err != nil {
+		return fmt.Errorf("enable monitor-bell on %s: %w", target, err)
+	}
+	out, err := exec.Command("tmux", "display-message", "-t", target, "-p", "#{pane_tty}").Output()
+	if err != nil {
+		return fmt.Errorf("get pane tty for %s: %w", target, err)
+	}
+	tty, err := os.OpenFile(strings.TrimSpace(string(out)), os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("open pane tty for %s: %w", target, err)
+	}
+	defer tty.Close()
+	if _, err := tty.Write([]byte("\a")); err != nil {
+		return fmt.Errorf("ring bell on %s: %w", target, err)
+	}
+	return nil
+}
+
 // CurrentWindowName returns the name of the tmux window identified by target
 // (a window ID like @0, or a session:window specifier).
 func CurrentWindowName(target string) (string, error) {
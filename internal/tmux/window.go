@@ -36,12 +36,25 @@ func NewWindow(session, name, dir string, command []string) (string, error) {
 }
 
 func SplitWindow(paneID, dir string, horizontal bool, sizePercent int, command []string) (string, error) {
+	return splitWindow(paneID, dir, horizontal, false, sizePercent, command)
+}
+
+// SplitWindowDetached splits paneID like SplitWindow but leaves paneID as
+// the active pane.
+func SplitWindowDetached(paneID, dir string, horizontal bool, sizePercent int, command []string) (string, error) {
+	return splitWindow(paneID, dir, horizontal, true, sizePercent, command)
+}
+
+func splitWindow(paneID, dir string, horizontal, detached bool, sizePercent int, command []string) (string, error) {
 	args := []string{
 		"split-window",
 		"-t", paneID,
 		"-c", dir,
 		"-P", "-F", "#{pane_id}",
 	}
+	if detached {
+		args = append(args, "-d")
+	}
 	if horizontal {
 		args = append(args, "-h")
 	}
@@ -73,6 +86,33 @@ func SendKeys(paneID string, keys ...string) error {
 	return nil
 }
 
+// PasteText pastes text into a pane as a bracketed paste, so an
+// application reading the pane takes line breaks in it as part of the
+// text rather than as Enter.
+func PasteText(paneID, text string) error {
+	buffer := "mastermind-" + strings.TrimPrefix(paneID, "%")
+	if out, err := exec.Command("tmux", "set-buffer", "-b", buffer, "--", text).CombinedOutput(); err != nil {
+		return fmt.Errorf("set paste buffer: %s (%w)", strings.TrimSpace(string(out)), err)
+	}
+	if err := exec.Command("tmux", "paste-buffer", "-p", "-d", "-b", buffer, "-t", paneID).Run(); err != nil {
+		return fmt.Errorf("paste into pane %s: %w", paneID, err)
+	}
+	return nil
+}
+
+// PanePID returns the PID of the process running in a pane.
+func PanePID(paneID string) (int, error) {
+	out, err := exec.Command("tmux", "display-message", "-p", "-t", paneID, "#{pane_pid}").Output()
+	if err != nil {
+		return 0, fmt.Errorf("get pid of pane %s: %w", paneID, err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, fmt.Errorf("parse pid of pane %s: %w", paneID, err)
+	}
+	return pid, nil
+}
+
 func KillPane(paneID string) error {
 	if err := exec.Command("tmux", "kill-pane", "-t", paneID).Run(); err != nil {
 		return fmt.Errorf("kill tmux pane %s: %w", paneID, err)
@@ -80,6 +120,32 @@ func KillPane(paneID string) error {
 	return nil
 }
 
+// RespawnPane kills the process in the pane and starts the pane's
+// original command again in its place.
+func RespawnPane(paneID string) error {
+	if err := exec.Command("tmux", "respawn-pane", "-k", "-t", paneID).Run(); err != nil {
+		return fmt.Errorf("respawn tmux pane %s: %w", paneID, err)
+	}
+	return nil
+}
+
+// RespawnPaneWith kills the process in the pane, if any, and runs command
+// in dir in its place, e.g. to restart an agent whose process died.
+func RespawnPaneWith(paneID, dir string, command []string) error {
+	args := []string{
+		"respawn-pane", "-k",
+		"-t", paneID,
+		"-c", dir,
+		"-e", "CLAUDECODE=",
+		"-e", "CLAUDE_CODE_ENTRYPOINT=",
+	}
+	args = append(args, command...)
+	if out, err := exec.Command("tmux", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("respawn tmux pane %s: %s (%w)", paneID, strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
 func SelectWindow(target string) error {
 	if err := exec.Command("tmux", "select-window", "-t", target).Run(); err != nil {
 		return fmt.Errorf("select tmux window %s: %w", target, err)
@@ -109,10 +175,16 @@ func PaneExistsInWindow(paneID, windowID string) bool {
 	return false
 }
 
-// ListAllPanes returns a map of pane ID → PaneInfo for all panes in the session.
+// ListAllPanes returns a map of pane ID → PaneInfo for all panes in the session,
+// or in every session on the server when session is empty.
 // This allows batch existence + dead-pane checks with a single tmux subprocess.
 func ListAllPanes(session string) (map[string]PaneInfo, error) {
-	out, err := exec.Command("tmux", "list-panes", "-s", "-t", session, "-F", "#{pane_id}|#{window_id}|#{pane_dead}|#{pane_dead_status}").Output()
+	args := []string{"list-panes", "-a"}
+	if session != "" {
+		args = []string{"list-panes", "-s", "-t", session}
+	}
+	args = append(args, "-F", "#{pane_id}|#{window_id}|#{pane_dead}|#{pane_dead_status}|#{pid}")
+	out, err := exec.Command("tmux", args...).Output()
 	if err != nil {
 		return nil, fmt.Errorf("list-panes: %w", err)
 	}
@@ -121,7 +193,7 @@ func ListAllPanes(session string) (map[string]PaneInfo, error) {
 		if line == "" {
 			continue
 		}
-		parts := strings.SplitN(line, "|", 4)
+		parts := strings.SplitN(line, "|", 5)
 		if len(parts) < 2 {
 			continue
 		}
@@ -133,6 +205,9 @@ func ListAllPanes(session string) (map[string]PaneInfo, error) {
 				info.ExitCode = code
 			}
 		}
+		if len(parts) >= 5 {
+			info.ServerPID, _ = strconv.Atoi(parts[4])
+		}
 		result[parts[0]] = info
 	}
 	return result, nil
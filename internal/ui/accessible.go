@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// plainText replaces the box drawing and symbols the views use with text
+// a screen reader reads out sensibly.
+var plainText = strings.NewReplacer(
+	"│", "|",
+	"─", "-",
+	"└", " ",
+	"◀", " ",
+	"▸", "+",
+	"▾", "-",
+	"↑", "up ",
+	"↓", "down ",
+	"→", "->",
+	"↳", "-",
+	"⇡", " (can push)",
+	"…", "...",
+	"·", ",",
+	"—", "-",
+	"–", "-",
+	"✓", "[done]",
+	"■", "[doing]",
+	"□", "[todo]",
+)
+
+// accessibleStyles drops the border around the views, keeping its
+// padding, for the screen reader mode.
+func accessibleStyles(s Styles) Styles {
+	s.Border = lipgloss.NewStyle().Padding(1, 2)
+	return s
+}
+
+// statusText describes an agent's status in words.
+func statusText(a *agent.Agent) string {
+	status := a.GetStatus()
+	if status == agent.StatusWaiting {
+		switch a.GetWaitingFor() {
+		case "permission":
+			return "needs permission"
+		case "unknown":
+			return "may need attention"
+		}
+		return "waiting for input"
+	}
+	return string(status)
+}
+
+// announceStatusChanges adds a notification for every agent whose status
+// changed since the last call, and for every new agent. The first call
+// only takes note of the statuses.
+func (m *dashboardModel) announceStatusChanges() {
+	first := m.lastStatus == nil
+	if first {
+		m.lastStatus = map[string]string{}
+	}
+	for _, a := range m.sortedAgents() {
+		text := statusText(a)
+		prev, seen := m.lastStatus[a.ID]
+		m.lastStatus[a.ID] = text
+		if first || prev == text {
+			continue
+		}
+		announcement := fmt.Sprintf("Agent %s (%s) is %s", a.ID, a.Branch, text)
+		if !seen {
+			announcement = fmt.Sprintf("Agent %s (%s) started, %s", a.ID, a.Branch, text)
+		}
+		m.addNotification(notification{
+			text:  announcement,
+			time:  time.Now(),
+			style: m.styles.Notification,
+			agent: a.ID,
+		})
+	}
+}
+
+// takeAnnouncements returns the notifications added since the last call,
+// as lines to print for a screen reader.
+func (m *dashboardModel) takeAnnouncements() []string {
+	lines := m.announcements
+	m.announcements = nil
+	return lines
+}
@@ -21,6 +21,19 @@ const (
 	viewMerge
 	viewDismiss
 	viewPrune
+	viewGraph
+	viewMaintenance
+	viewCommand
+	viewErrors
+	viewClone
+	viewRollback
+	viewReport
+	viewTeam
+	viewInstances
+	viewOrphans
+	viewClearDone
+	viewArchive
+	viewDiff
 )
 
 type AppModel struct {
@@ -31,20 +44,47 @@ type AppModel struct {
 	activeView view
 
 	styles Styles
-	layout config.Layout
+
+	// Open lazygit as soon as a merge reports conflicts
+	lazygitOnConflict bool
 
 	dashboard dashboardModel
 	spawn     spawnModel
 	merge     mergeModel
 	dismiss   dismissModel
 	prune     pruneModel
+	graph     graphModel
+	maint     maintenanceModel
+	command   commandModel
+	errors    errorsModel
+	clone     cloneModel
+	rollback  rollbackModel
+	team      teamModel
+	report    reportModel
+	instances instancesModel
+	orphans   orphansModel
+	clearDone clearDoneModel
+	archive   archiveModel
+	diff      diffModel
+
+	// Worktrees kept because processes still run in them, each shown
+	// in the orphans view in turn once the dashboard is back
+	orphanQueue []orchestrator.OrphanProcessesMsg
 
 	width  int
 	height int
 }
 
 func NewApp(cfg config.Config, orch *orchestrator.Orchestrator, store *agent.Store, repoPath, session string) AppModel {
-	s := NewStyles(cfg.Colors)
+	s := NewStyles(accentColors(cfg.Colors, cfg.Instance.Accent))
+	if cfg.Accessibility.ScreenReader {
+		s = accessibleStyles(s)
+	}
+	dashboard := newDashboard(s, cfg.Layout, newFormatter(cfg.Format), time.Duration(cfg.Monitor.NoSignalMinutes)*time.Minute, orch, store, repoPath, session)
+	dashboard.instance = cfg.Instance.Name
+	dashboard.screenReader = cfg.Accessibility.ScreenReader
+	dashboard.rowSpacing = max(cfg.Accessibility.RowSpacing, 0)
+	dashboard.diffPreview = cfg.Preview.DiffOnly
 	return AppModel{
 		orch:       orch,
 		store:      store,
@@ -52,16 +92,74 @@ func NewApp(cfg config.Config, orch *orchestrator.Orchestrator, store *agent.Sto
 		session:    session,
 		activeView: viewDashboard,
 		styles:     s,
-		layout:     cfg.Layout,
-		dashboard:  newDashboard(s, cfg.Layout, orch, store, repoPath, session),
+		dashboard:  dashboard,
+
+		lazygitOnConflict: cfg.Merge.OpenLazygitOnConflict,
+	}
+}
+
+// openConflictLazygit opens lazygit for an agent whose merge reported
+// conflicts, unless it is open already, and reports whether it is open.
+func (m *AppModel) openConflictLazygit(id string) bool {
+	a, ok := m.store.Get(id)
+	if !ok {
+		return false
+	}
+	if a.GetLazygitPaneID() != "" {
+		return true
+	}
+	if err := m.orch.OpenLazyGit(id); err != nil {
+		m.dashboard.setError(fmt.Sprintf("open lazygit for %s: %s", id, err))
+		return false
 	}
+	return true
+}
+
+// accentColors gives the logo, title and border the instance's accent
+// color, if it has one.
+func accentColors(c config.Colors, accent string) config.Colors {
+	if accent != "" {
+		c.Logo, c.Title, c.Border = accent, accent, accent
+	}
+	return c
 }
 
 func (m AppModel) Init() tea.Cmd {
+	if m.dashboard.instance != "" {
+		return tea.Batch(m.dashboard.Init(), tea.SetWindowTitle("mastermind: "+m.dashboard.instance))
+	}
 	return m.dashboard.Init()
 }
 
 func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Keep the cursor on the selected agent however the agents were
+	// re-sorted since the last message.
+	m.dashboard.followSelection()
+	cursor := m.dashboard.cursor
+	updated, cmd := m.update(msg)
+	app := updated.(AppModel)
+	app.dashboard.trackCursor(cursor)
+	if app.activeView == viewDashboard && len(app.orphanQueue) > 0 {
+		app.activeView = viewOrphans
+		app.orphans = newOrphans(app.styles, app.orch, app.orphanQueue[0], app.width)
+		app.orphanQueue = app.orphanQueue[1:]
+	}
+	if !app.dashboard.screenReader {
+		return app, cmd
+	}
+	// Print notifications above the TUI, where a screen reader reads them
+	// as they arrive.
+	var prints []tea.Cmd
+	for _, line := range app.dashboard.takeAnnouncements() {
+		prints = append(prints, tea.Println(plainText.Replace(line)))
+	}
+	if len(prints) == 0 {
+		return app, cmd
+	}
+	return app, tea.Batch(cmd, tea.Sequence(prints...))
+}
+
+func (m AppModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -75,20 +173,36 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.merge.width = msg.Width
 		m.dismiss.width = msg.Width
 		m.prune.width = msg.Width
+		m.maint.width = msg.Width
+		m.command.width = msg.Width
+		m.errors.width = msg.Width
+		m.clone.width = msg.Width
+		m.instances.width = msg.Width
+		m.orphans.width = msg.Width
+		m.clearDone.width = msg.Width
+		m.archive.width = msg.Width
 		return m, nil
 
 	case tea.FocusMsg:
 		// When the tmux pane regains focus, force a full repaint so the
 		// screen is correct after tmux restores its buffer, and schedule
 		// an immediate tick so durations update without waiting.
-		return m, tea.Batch(tea.ClearScreen, tickCmd())
+		m.dashboard.tickInterval = minTick
+		return m, tea.Batch(tea.ClearScreen, tickCmd(minTick))
 
 	case tickMsg:
 		// Always keep the tick chain alive regardless of active view,
 		// and always forward to dashboard so it can update durations.
 		var dashCmd tea.Cmd
 		m.dashboard, dashCmd = m.dashboard.Update(msg)
-		return m, tea.Batch(dashCmd, tickCmd())
+		return m, tea.Batch(dashCmd, tickCmd(m.dashboard.nextTick(time.Time(msg))))
+
+	case orchestrator.StoreChangedMsg:
+		// Always forward store changes so the dashboard is up to date
+		// when the user returns to it.
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
 
 	case orchestrator.AgentFinishedMsg:
 		// Always forward agent-finished notifications to dashboard.
@@ -102,6 +216,12 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.dashboard, cmd = m.dashboard.Update(msg)
 		return m, cmd
 
+	case orchestrator.SessionChangedMsg:
+		m.session = msg.Name
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
+
 	case orchestrator.AgentGoneMsg:
 		// Window was closed externally — forward to dashboard and clean up.
 		var cmd tea.Cmd
@@ -113,6 +233,133 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.dashboard, cmd = m.dashboard.Update(msg)
 		return m, cmd
 
+	case orchestrator.PullRequestMergedMsg:
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		// Offer to clean up, unless the user is busy in another view.
+		if !msg.Dismissed && m.activeView == viewDashboard {
+			if a, ok := m.store.Get(msg.AgentID); ok {
+				m.activeView = viewDismiss
+				m.dismiss = newDismiss(m.styles, m.dashboard.format, m.orch, startDismissMsg{
+					agentID:      a.ID,
+					agentName:    a.ID,
+					branch:       a.Branch,
+					deleteBranch: true,
+					landed:       true,
+					note:         fmt.Sprintf("Pull request #%d was merged. Clean up the agent?", msg.Number),
+				})
+				cmd = tea.Batch(cmd, m.dismiss.Init())
+			}
+		}
+		return m, cmd
+
+	case orchestrator.PullRequestMsg:
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
+
+	case orchestrator.CheckpointMsg:
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
+
+	case orchestrator.PlaybookFinishedMsg:
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
+
+	case orchestrator.BaseFetchedMsg:
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
+
+	case orchestrator.ReportCollectedMsg:
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
+
+	case orchestrator.AgentForceKilledMsg:
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
+
+	case orchestrator.OrphanProcessesMsg:
+		m.orphanQueue = append(m.orphanQueue, msg)
+		return m, nil
+
+	case orchestrator.OrphansKilledMsg:
+		if msg.Error == "" {
+			m.dashboard.addNotification(notification{
+				text:  fmt.Sprintf("Killed the processes left in %s's worktree and removed it", msg.Branch),
+				time:  time.Now(),
+				style: m.styles.Done,
+				agent: msg.AgentID,
+			})
+		}
+		if m.activeView == viewOrphans {
+			var cmd tea.Cmd
+			m.orphans, cmd = m.orphans.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case orchestrator.BulkDismissMsg:
+		if len(msg.Dismissed) > 0 {
+			m.dashboard.addNotification(notification{
+				text:  fmt.Sprintf("Dismissed %d done agent(s): %s", len(msg.Dismissed), strings.Join(msg.Dismissed, ", ")),
+				time:  time.Now(),
+				style: m.styles.Done,
+			})
+		}
+		if m.activeView == viewClearDone {
+			var cmd tea.Cmd
+			m.clearDone, cmd = m.clearDone.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case clearDoneCloseMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case archiveRestoredMsg:
+		m.activeView = viewDashboard
+		m.dashboard.addNotification(notification{
+			text:  fmt.Sprintf("Restored branch %s from the archive", msg.branch),
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+		})
+		return m, nil
+
+	case archiveCloseMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case archiveLoadedMsg, archiveErrorMsg:
+		if m.activeView == viewArchive {
+			var cmd tea.Cmd
+			m.archive, cmd = m.archive.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case orphansCloseMsg:
+		if msg.kept {
+			m.dashboard.addNotification(notification{
+				text:  fmt.Sprintf("Kept %s: processes still run in it", m.orphans.orphans.WorktreePath),
+				time:  time.Now(),
+				style: m.styles.Attention,
+				agent: m.orphans.orphans.AgentID,
+			})
+		}
+		m.activeView = viewDashboard
+		return m, nil
+
+	case orchestrator.StackRestackedMsg:
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
+
 	case orchestrator.PreviewStartedMsg:
 		var cmd tea.Cmd
 		m.dashboard, cmd = m.dashboard.Update(msg)
@@ -123,6 +370,11 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.dashboard, cmd = m.dashboard.Update(msg)
 		return m, cmd
 
+	case orchestrator.PreviewStaleMsg, orchestrator.PreviewRefreshedMsg:
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		return m, cmd
+
 	case orchestrator.PreviewErrorMsg:
 		var cmd tea.Cmd
 		m.dashboard, cmd = m.dashboard.Update(msg)
@@ -131,6 +383,11 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case orchestrator.MergeResultMsg:
 		var dashCmd tea.Cmd
 		m.dashboard, dashCmd = m.dashboard.Update(msg)
+		if msg.Conflict && m.lazygitOnConflict && m.openConflictLazygit(msg.AgentID) && m.activeView == viewMerge {
+			// lazygit takes the place of the wizard's conflicts step.
+			m.activeView = viewDashboard
+			return m, dashCmd
+		}
 		if m.activeView == viewMerge {
 			var mergeCmd tea.Cmd
 			m.merge, mergeCmd = m.merge.Update(msg)
@@ -144,6 +401,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				text:  fmt.Sprintf("Agent %s pruned (branch kept)", msg.AgentID),
 				time:  time.Now(),
 				style: m.styles.Reviewed,
+				agent: msg.AgentID,
 			})
 			agents := m.dashboard.sortedAgents()
 			if m.dashboard.cursor >= len(agents) && m.dashboard.cursor > 0 {
@@ -178,7 +436,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case startMergeMsg:
 		m.activeView = viewMerge
 		m.merge = newMerge(m.styles, m.orch, m.repoPath, msg)
-		return m, nil
+		return m, m.merge.Init()
 
 	case mergeDoneMsg:
 		m.activeView = viewDashboard
@@ -190,8 +448,8 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case startDismissMsg:
 		m.activeView = viewDismiss
-		m.dismiss = newDismiss(m.styles, m.orch, msg)
-		return m, nil
+		m.dismiss = newDismiss(m.styles, m.dashboard.format, m.orch, msg)
+		return m, m.dismiss.Init()
 
 	case dismissDoneMsg:
 		m.activeView = viewDashboard
@@ -230,6 +488,156 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case pruneCancelMsg:
 		m.activeView = viewDashboard
 		return m, nil
+
+	case graphCloseMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case instancesCloseMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case instanceFocusedMsg:
+		if msg.err != "" {
+			if m.activeView == viewInstances {
+				m.instances, _ = m.instances.Update(msg)
+			}
+			return m, nil
+		}
+		m.activeView = viewDashboard
+		m.dashboard.addNotification(notification{
+			text:  "Switched to instance " + msg.label,
+			time:  time.Now(),
+			style: m.styles.Done,
+		})
+		return m, nil
+
+	case maintenanceCloseMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case startCommandMsg:
+		m.activeView = viewCommand
+		m.command = newCommand(m.styles, msg, m.width)
+		return m, m.command.Init()
+
+	case commandCloseMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case startCloneMsg:
+		m.activeView = viewClone
+		m.clone = newClone(m.styles, m.orch, msg, m.width)
+		return m, m.clone.Init()
+
+	case cloneDoneMsg:
+		m.activeView = viewDashboard
+		m.dashboard.addNotification(notification{
+			text:  fmt.Sprintf("Agent %s cloned to %s", msg.agentID, msg.branch),
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+			agent: msg.agentID,
+		})
+		return m, nil
+
+	case cloneCancelMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case cloneErrorMsg:
+		if m.activeView == viewClone {
+			var cmd tea.Cmd
+			m.clone, cmd = m.clone.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case startRollbackMsg:
+		m.activeView = viewRollback
+		m.rollback = newRollback(m.styles, m.orch, msg, m.width)
+		return m, nil
+
+	case rollbackDoneMsg:
+		m.activeView = viewDashboard
+		m.dashboard.addNotification(notification{
+			text:  fmt.Sprintf("Agent %s rolled back to %s (previous state: %s)", msg.agentID, msg.tag, msg.saved),
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+			agent: msg.agentID,
+		})
+		return m, nil
+
+	case rollbackCancelMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case rollbackErrorMsg:
+		if m.activeView == viewRollback {
+			var cmd tea.Cmd
+			m.rollback, cmd = m.rollback.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case startTeamMsg:
+		m.activeView = viewTeam
+		m.team = newTeam(m.styles, m.orch, m.store, msg, m.width)
+		return m, nil
+
+	case teamCancelMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case teammateActionMsg:
+		if msg.err == "" {
+			m.dashboard.addNotification(notification{
+				text:  fmt.Sprintf("Agent %s: teammate %s %s", msg.agentID, msg.name, msg.action),
+				time:  time.Now(),
+				style: m.styles.Reviewed,
+				agent: msg.agentID,
+			})
+		}
+		if m.activeView == viewTeam {
+			var cmd tea.Cmd
+			m.team, cmd = m.team.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case startReportMsg:
+		m.activeView = viewReport
+		m.report = newReport(m.styles, msg, m.width)
+		return m, nil
+
+	case reportCloseMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case startDiffMsg:
+		m.activeView = viewDiff
+		m.diff = newDiff(m.styles, m.orch, msg, m.width)
+		return m, m.diff.Init()
+
+	case diffCloseMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case errorsCloseMsg:
+		m.activeView = viewDashboard
+		return m, nil
+
+	case errorsClearMsg:
+		m.dashboard.errors = nil
+		m.dashboard.err = ""
+		return m, nil
+
+	case graphLoadedMsg:
+		if m.activeView == viewGraph {
+			var cmd tea.Cmd
+			m.graph, cmd = m.graph.Update(msg)
+			return m, cmd
+		}
+		return m, nil
 	}
 
 	switch m.activeView {
@@ -243,6 +651,32 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateDismiss(msg)
 	case viewPrune:
 		return m.updatePrune(msg)
+	case viewGraph:
+		return m.updateGraph(msg)
+	case viewMaintenance:
+		return m.updateMaintenance(msg)
+	case viewCommand:
+		return m.updateCommand(msg)
+	case viewErrors:
+		return m.updateErrors(msg)
+	case viewClone:
+		return m.updateClone(msg)
+	case viewRollback:
+		return m.updateRollback(msg)
+	case viewReport:
+		return m.updateReport(msg)
+	case viewTeam:
+		return m.updateTeam(msg)
+	case viewInstances:
+		return m.updateInstances(msg)
+	case viewOrphans:
+		return m.updateOrphans(msg)
+	case viewClearDone:
+		return m.updateClearDone(msg)
+	case viewArchive:
+		return m.updateArchive(msg)
+	case viewDiff:
+		return m.updateDiff(msg)
 	}
 
 	return m, nil
@@ -257,6 +691,30 @@ func (m AppModel) updateDashboard(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.activeView = viewSpawn
 			m.spawn = newSpawn(m.styles, m.orch, m.repoPath, m.width, m.orch.DefaultHarness())
 			return m, m.spawn.Init()
+		case "I":
+			m.activeView = viewInstances
+			m.instances = newInstances(m.styles, m.width)
+			return m, m.instances.Init()
+		case "C":
+			m.activeView = viewClearDone
+			m.clearDone = newClearDone(m.styles, m.orch, m.width)
+			return m, m.clearDone.Init()
+		case "A":
+			m.activeView = viewArchive
+			m.archive = newArchive(m.styles, m.orch, m.width)
+			return m, m.archive.Init()
+		case "g":
+			m.activeView = viewGraph
+			m.graph = newGraph(m.styles, m.store, m.repoPath, m.panelWidth())
+			return m, m.graph.Init()
+		case "x":
+			m.activeView = viewMaintenance
+			m.maint = newMaintenance(m.styles, m.orch, m.width)
+			return m, nil
+		case "e":
+			m.activeView = viewErrors
+			m.errors = newErrors(m.styles, m.dashboard.format, m.dashboard.errors, m.width)
+			return m, nil
 		}
 	}
 
@@ -289,7 +747,92 @@ func (m AppModel) updatePrune(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m AppModel) updateGraph(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.graph, cmd = m.graph.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateMaintenance(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.maint, cmd = m.maint.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateCommand(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.command, cmd = m.command.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateErrors(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.errors, cmd = m.errors.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateClone(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.clone, cmd = m.clone.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateRollback(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.rollback, cmd = m.rollback.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateReport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.report, cmd = m.report.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateTeam(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.team, cmd = m.team.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateInstances(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.instances, cmd = m.instances.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateOrphans(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.orphans, cmd = m.orphans.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateClearDone(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.clearDone, cmd = m.clearDone.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateArchive(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.archive, cmd = m.archive.Update(msg)
+	return m, cmd
+}
+
+func (m AppModel) updateDiff(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.diff, cmd = m.diff.Update(msg)
+	return m, cmd
+}
+
 func (m AppModel) View() string {
+	if m.dashboard.screenReader {
+		return plainText.Replace(m.view())
+	}
+	return m.view()
+}
+
+func (m AppModel) view() string {
 	switch m.activeView {
 	case viewSpawn:
 		return m.viewSideBySide(m.spawn.ViewContent())
@@ -299,15 +842,61 @@ func (m AppModel) View() string {
 		return m.viewSideBySide(m.dismiss.ViewContent())
 	case viewPrune:
 		return m.viewSideBySide(m.prune.ViewContent())
+	case viewGraph:
+		// The panel narrows as the dashboard is resized.
+		g := m.graph
+		g.width = m.panelWidth()
+		return m.viewSideBySide(g.ViewContent())
+	case viewMaintenance:
+		return m.viewSideBySide(m.maint.ViewContent())
+	case viewCommand:
+		return m.viewSideBySide(m.command.ViewContent())
+	case viewErrors:
+		return m.viewSideBySide(m.errors.ViewContent())
+	case viewClone:
+		return m.viewSideBySide(m.clone.ViewContent())
+	case viewRollback:
+		return m.viewSideBySide(m.rollback.ViewContent())
+	case viewReport:
+		return m.viewSideBySide(m.report.ViewContent())
+	case viewTeam:
+		return m.viewSideBySide(m.team.ViewContent())
+	case viewInstances:
+		return m.viewSideBySide(m.instances.ViewContent())
+	case viewOrphans:
+		return m.viewSideBySide(m.orphans.ViewContent())
+	case viewClearDone:
+		return m.viewSideBySide(m.clearDone.ViewContent())
+	case viewArchive:
+		return m.viewSideBySide(m.archive.ViewContent())
+	case viewDiff:
+		return m.viewSideBySide(m.diff.ViewContent())
 	default:
 		return m.dashboard.View()
 	}
 }
 
+// Screenshot renders the first frame of m at the given terminal size,
+// without starting a program. It backs the hidden --screenshot flag and
+// golden-file tests of the UI.
+func Screenshot(m AppModel, width, height int) string {
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return updated.(AppModel).View()
+}
+
 // minSideBySideWidth is the minimum terminal width needed to show
 // dashboard and sidebar side-by-side. Below this, panels stack vertically.
 const minSideBySideWidth = 100
 
+// panelWidth is the width viewSideBySide gives the right panel.
+func (m AppModel) panelWidth() int {
+	maxWidth := max(m.width-4, 20)
+	if m.width < minSideBySideWidth {
+		return maxWidth
+	}
+	return maxWidth - maxWidth*m.dashboard.layout.DashboardWidth/100 - 1
+}
+
 func (m AppModel) viewSideBySide(rightPanel string) string {
 	maxWidth := m.width - 4
 	if maxWidth < 20 {
@@ -327,8 +916,8 @@ func (m AppModel) viewSideBySide(rightPanel string) string {
 	}
 
 	// Wide terminal: side-by-side
-	dashWidth := maxWidth * m.layout.DashboardWidth / 100
-	panelWidth := maxWidth - dashWidth - 1
+	panelWidth := m.panelWidth()
+	dashWidth := maxWidth - panelWidth - 1
 
 	// Give dashboard the constrained width so logo/columns adapt
 	dash := m.dashboard
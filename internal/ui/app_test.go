@@ -2,9 +2,12 @@ package ui
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/simonbystrom/mastermind/internal/agent"
 	"github.com/simonbystrom/mastermind/internal/config"
@@ -45,6 +48,83 @@ func TestAppModel_KeyN_OpensSpawn(t *testing.T) {
 	}
 }
 
+func TestAppModel_KeyG_OpensGraph(t *testing.T) {
+	m := newTestApp(t)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	app := updated.(AppModel)
+	if app.activeView != viewGraph {
+		t.Fatalf("activeView = %d, want %d (viewGraph)", app.activeView, viewGraph)
+	}
+	if cmd == nil {
+		t.Fatal("expected graph load command")
+	}
+
+	updated, _ = app.Update(graphLoadedMsg{
+		rows:  []graphRow{{agentID: "a1", branch: "feat/x", base: "main", ahead: 2, behind: 1}},
+		lines: []string{"* abc123 (feat/x) work"},
+	})
+	app = updated.(AppModel)
+	view := app.graph.ViewContent()
+	for _, want := range []string{"feat/x", "↑2 ↓1 main", "abc123"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("graph view missing %q:\n%s", want, view)
+		}
+	}
+
+	updated, cmd = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	updated, _ = updated.(AppModel).Update(cmd())
+	if updated.(AppModel).activeView != viewDashboard {
+		t.Error("esc should close the graph")
+	}
+}
+
+func TestGraph_TruncatesLinesToPanel(t *testing.T) {
+	g := newGraph(NewStyles(config.Default().Colors), agent.NewStore(), "/repo", 30)
+	g, _ = g.Update(graphLoadedMsg{lines: []string{"| * abc123 (feat/a-very-long-branch-name) a commit subject that goes on"}})
+
+	for _, line := range strings.Split(g.ViewContent(), "\n") {
+		if strings.Contains(line, "abc123") && (lipgloss.Width(line) > 30 || !strings.HasSuffix(line, "…")) {
+			t.Errorf("graph line %q is %d wide, want it cut to the panel's 30", line, lipgloss.Width(line))
+		}
+	}
+}
+
+func TestAppModel_KeyX_OpensMaintenance(t *testing.T) {
+	m := newTestApp(t)
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	app := updated.(AppModel)
+	if app.activeView != viewMaintenance {
+		t.Fatalf("activeView = %d, want %d (viewMaintenance)", app.activeView, viewMaintenance)
+	}
+
+	// Deselect the first task and check it is left out of the run.
+	updated, _ = app.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	app = updated.(AppModel)
+	if opts := app.maint.options(); opts.RepairWorktrees || !opts.PruneWorktrees {
+		t.Errorf("options = %+v, want repair off and prune on", opts)
+	}
+
+	updated, _ = app.Update(orchestrator.MaintenanceResultMsg{Report: orchestrator.MaintenanceReport{
+		DeletedBranches: []string{"preview/a9"},
+		GCRan:           true,
+	}})
+	app = updated.(AppModel)
+	view := app.maint.ViewContent()
+	for _, want := range []string{"Deleted branches (1)", "preview/a9", "gc --auto completed"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("maintenance view missing %q:\n%s", want, view)
+		}
+	}
+
+	updated, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	updated, _ = updated.(AppModel).Update(cmd())
+	if updated.(AppModel).activeView != viewDashboard {
+		t.Error("a key press after the report should close the panel")
+	}
+}
+
 func TestAppModel_WindowSizeMsg(t *testing.T) {
 	m := newTestApp(t)
 
@@ -115,3 +195,171 @@ func TestAppModel_DismissCancelReturns(t *testing.T) {
 		t.Errorf("activeView = %d, want %d (viewDashboard)", app.activeView, viewDashboard)
 	}
 }
+
+func TestAppModel_KeyE_OpensErrorsPanel(t *testing.T) {
+	m := newTestApp(t)
+
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 200, Height: 50})
+	updated, _ = updated.(AppModel).Update(resumeErrorMsg{agentID: "a1", err: "create worktree: exit status 128\nfatal: invalid reference: feat/x"})
+	app := updated.(AppModel)
+	if app.dashboard.err != "resume a1: create worktree: exit status 128" {
+		t.Errorf("dashboard err = %q, want first line only", app.dashboard.err)
+	}
+
+	updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	app = updated.(AppModel)
+	if app.activeView != viewErrors {
+		t.Fatalf("activeView = %d, want %d (viewErrors)", app.activeView, viewErrors)
+	}
+	if strings.Contains(app.errors.ViewContent(), "fatal: invalid reference") {
+		t.Error("collapsed entry should not show the full text")
+	}
+
+	updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app = updated.(AppModel)
+	if !strings.Contains(app.errors.ViewContent(), "fatal: invalid reference") {
+		t.Errorf("expanded entry missing stderr:\n%s", app.errors.ViewContent())
+	}
+
+	updated, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	app = updated.(AppModel)
+	updated, _ = app.Update(cmd())
+	app = updated.(AppModel)
+	if len(app.dashboard.errors) != 0 || app.dashboard.err != "" {
+		t.Errorf("clear left errors = %v, err = %q", app.dashboard.errors, app.dashboard.err)
+	}
+}
+
+func TestAppModel_SessionChangedUpdatesTitle(t *testing.T) {
+	m := newTestApp(t)
+
+	updated, _ := m.Update(orchestrator.SessionChangedMsg{Name: "renamed"})
+	app := updated.(AppModel)
+	if app.session != "renamed" || app.dashboard.session != "renamed" {
+		t.Errorf("session = %q / %q, want renamed", app.session, app.dashboard.session)
+	}
+	if !strings.Contains(app.dashboard.ViewContent(), "session: renamed") {
+		t.Error("dashboard title should show the new session name")
+	}
+}
+
+func TestNewApp_Instance(t *testing.T) {
+	cfg := config.Default()
+	cfg.Instance = config.Instance{Name: "api", Accent: "#fab387"}
+	store := agent.NewStore()
+	orch := orchestrator.New(context.Background(), store, "/repo", "test", t.TempDir())
+	m := NewApp(cfg, orch, store, "/repo", "test")
+
+	if !strings.Contains(Screenshot(m, 120, 40), "api — repo: /repo — session: test") {
+		t.Error("expected the instance name in the title")
+	}
+	for name, style := range map[string]lipgloss.Style{"logo": m.styles.Logo, "title": m.styles.Title} {
+		if got := style.GetForeground(); got != lipgloss.Color("#fab387") {
+			t.Errorf("%s color = %v, want the accent", name, got)
+		}
+	}
+	if got := m.styles.Border.GetBorderTopForeground(); got != lipgloss.Color("#fab387") {
+		t.Errorf("border color = %v, want the accent", got)
+	}
+}
+
+func TestNewApp_ScreenReader(t *testing.T) {
+	cfg := config.Default()
+	cfg.Accessibility = config.Accessibility{ScreenReader: true, RowSpacing: 1}
+	store := agent.NewStore()
+	orch := orchestrator.New(context.Background(), store, "/repo", "test", t.TempDir())
+	m := NewApp(cfg, orch, store, "/repo", "test")
+
+	a := agent.NewAgent("feat/x", "main", "/wt1", "@1", "%1", "claude")
+	a.ID = "a1"
+	store.Add(a)
+	b := agent.NewAgent("feat/y", "main", "/wt2", "@2", "%2", "claude")
+	b.ID = "a2"
+	store.Add(b)
+
+	view := Screenshot(m, 120, 40)
+	for _, glyph := range []string{"│", "─", "╭", "█", "◀"} {
+		if strings.Contains(view, glyph) {
+			t.Errorf("view contains %q:\n%s", glyph, view)
+		}
+	}
+	if !strings.Contains(view, "> a1 [C]") {
+		t.Errorf("view = %s, want the selected row marked", view)
+	}
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "a1 [C]") && (i+1 == len(lines) || strings.TrimSpace(lines[i+1]) != "") {
+			t.Errorf("view = %s, want a blank line between rows", view)
+		}
+	}
+
+	// The first tick takes note of the statuses, later ones announce
+	// changes.
+	updated, _ := m.Update(tickMsg(time.Now()))
+	m = updated.(AppModel)
+	a.SetStatus(agent.StatusReviewReady)
+	updated, cmd := m.Update(tickMsg(time.Now()))
+	m = updated.(AppModel)
+	if n := m.dashboard.notifications; len(n) != 1 || n[0].text != "Agent a1 (feat/x) is review ready" {
+		t.Errorf("notifications = %+v, want the status change announced", n)
+	}
+	if cmd == nil || len(m.dashboard.announcements) != 0 {
+		t.Error("expected the announcement to be printed")
+	}
+}
+
+func TestAppModel_LazygitOnConflict(t *testing.T) {
+	m := newTestApp(t)
+	m.lazygitOnConflict = true
+	a := agent.NewAgent("feat/x", "main", "/wt1", "@1", "%1", "claude")
+	a.ID = "a1"
+	a.SetLazygitPaneID("%2") // already open, so nothing is run
+	m.store.Add(a)
+
+	conflict := orchestrator.MergeResultMsg{AgentID: "a1", Conflict: true, ConflictFiles: []string{"main.go"}}
+	m.activeView = viewMerge
+	m.merge = newMerge(m.styles, m.orch, "/repo", startMergeMsg{agentID: "a1", branch: "feat/x", baseBranch: "main"})
+	updated, _ := m.Update(conflict)
+	if app := updated.(AppModel); app.activeView != viewDashboard {
+		t.Errorf("activeView = %d, want the wizard closed for lazygit", app.activeView)
+	}
+
+	m.lazygitOnConflict = false
+	updated, _ = m.Update(conflict)
+	if app := updated.(AppModel); app.activeView != viewMerge || app.merge.step != mergeStepConflicts {
+		t.Errorf("activeView = %d, step = %d; want the conflicts view", app.activeView, app.merge.step)
+	}
+}
+
+func TestAppModel_OrphansWaitForDashboard(t *testing.T) {
+	m := newTestApp(t)
+	m.activeView = viewDismiss
+
+	updated, _ := m.Update(orchestrator.OrphanProcessesMsg{
+		AgentID:      "a1",
+		Branch:       "feat/x",
+		WorktreePath: "/repo/.worktrees/feat__x",
+		Processes:    []orchestrator.Process{{PID: 300, Command: "node"}},
+	})
+	m = updated.(AppModel)
+	if m.activeView != viewDismiss {
+		t.Fatalf("activeView = %d, want the dismiss dialog left to finish", m.activeView)
+	}
+
+	updated, _ = m.Update(dismissDoneMsg{})
+	m = updated.(AppModel)
+	if m.activeView != viewOrphans {
+		t.Fatalf("activeView = %d, want %d (viewOrphans)", m.activeView, viewOrphans)
+	}
+	if view := m.orphans.ViewContent(); !strings.Contains(view, "300  node") {
+		t.Errorf("view = %s, want the process listed", view)
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(AppModel)
+	updated, _ = m.Update(cmd())
+	m = updated.(AppModel)
+	if m.activeView != viewDashboard {
+		t.Errorf("activeView = %d, want the dashboard after keeping the processes", m.activeView)
+	}
+}
@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+type archiveLoadedMsg struct {
+	branches []git.ArchivedBranch
+	err      string
+}
+
+type archiveRestoredMsg struct {
+	ref    string
+	branch string
+}
+type archiveCloseMsg struct{}
+type archiveErrorMsg struct {
+	err string
+}
+
+// archiveModel browses the branches archived instead of deleted, most
+// recently archived first, and restores or drops the chosen one.
+type archiveModel struct {
+	orch   *orchestrator.Orchestrator
+	styles Styles
+	width  int
+	err    string
+
+	loading    bool
+	branches   []git.ArchivedBranch
+	cursor     int
+	confirming bool // dropping the selected branch for good
+	busy       bool
+}
+
+func newArchive(s Styles, orch *orchestrator.Orchestrator, width int) archiveModel {
+	return archiveModel{
+		orch:    orch,
+		styles:  s,
+		width:   width,
+		loading: true,
+	}
+}
+
+// Init lists the archived branches in the background, since it runs git.
+func (m archiveModel) Init() tea.Cmd {
+	orch := m.orch
+	return func() tea.Msg {
+		branches, err := orch.ArchivedBranches()
+		if err != nil {
+			return archiveLoadedMsg{err: err.Error()}
+		}
+		return archiveLoadedMsg{branches: branches}
+	}
+}
+
+func (m archiveModel) Update(msg tea.Msg) (archiveModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case archiveLoadedMsg:
+		m.loading = false
+		m.busy = false
+		m.branches = msg.branches
+		if msg.err != "" {
+			m.err = msg.err
+		}
+		m.cursor = min(m.cursor, max(len(m.branches)-1, 0))
+		return m, nil
+
+	case archiveErrorMsg:
+		m.busy = false
+		m.confirming = false
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.busy || m.loading {
+			if msg.String() == "esc" {
+				return m, func() tea.Msg { return archiveCloseMsg{} }
+			}
+			return m, nil
+		}
+
+		if m.confirming {
+			switch msg.String() {
+			case "y":
+				m.confirming = false
+				m.busy = true
+				m.err = ""
+				orch, ref := m.orch, m.branches[m.cursor].Ref
+				return m, func() tea.Msg {
+					if err := orch.DeleteArchivedBranch(ref); err != nil {
+						return archiveErrorMsg{err: err.Error()}
+					}
+					branches, err := orch.ArchivedBranches()
+					if err != nil {
+						return archiveLoadedMsg{err: err.Error()}
+					}
+					return archiveLoadedMsg{branches: branches}
+				}
+			case "n", "esc":
+				m.confirming = false
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "esc", "q", "A":
+			return m, func() tea.Msg { return archiveCloseMsg{} }
+		case "down", "j":
+			if m.cursor < len(m.branches)-1 {
+				m.cursor++
+			}
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "enter":
+			if len(m.branches) == 0 {
+				return m, nil
+			}
+			m.busy = true
+			m.err = ""
+			orch, ref := m.orch, m.branches[m.cursor].Ref
+			return m, func() tea.Msg {
+				branch, err := orch.RestoreArchivedBranch(ref)
+				if err != nil {
+					return archiveErrorMsg{err: err.Error()}
+				}
+				return archiveRestoredMsg{ref: ref, branch: branch}
+			}
+		case "x":
+			if len(m.branches) > 0 {
+				m.confirming = true
+				m.err = ""
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m archiveModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Branch Archive"))
+	b.WriteString("\n\n")
+
+	if m.loading {
+		b.WriteString(m.styles.WizardDim.Render("  Loading…"))
+		return b.String()
+	}
+
+	if len(m.branches) == 0 {
+		if m.err == "" {
+			b.WriteString(m.styles.WizardDim.Render("  No archived branches"))
+			b.WriteString("\n")
+			if !m.orch.ArchivesBranches() {
+				b.WriteString(m.styles.WizardDim.Render("  Set [git] archive_branches = true to archive branches instead of deleting them"))
+				b.WriteString("\n")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(m.styles.Help.Render("  esc: close"))
+		m.writeError(&b)
+		return b.String()
+	}
+
+	textWidth := max(m.width/2-8, 30)
+	for i, a := range m.branches {
+		line := fmt.Sprintf("%-12s %s  %s %s", a.Date, a.Branch, a.Commit, a.Subject)
+		line = truncate(line, textWidth)
+		if i == m.cursor {
+			b.WriteString(m.styles.WizardActive.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case m.busy:
+		b.WriteString(m.styles.WizardActive.Render("  Working..."))
+	case m.confirming:
+		a := m.branches[m.cursor]
+		b.WriteString(fmt.Sprintf("  Drop %s (archived %s) for good?\n", a.Branch, a.Date))
+		b.WriteString(m.styles.WizardDim.Render("  Commits no other ref has are lost once git collects garbage."))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  y: drop │ n/esc: back"))
+	default:
+		b.WriteString(m.styles.Help.Render("  ↑/↓: select │ enter: restore branch │ x: drop │ esc: close"))
+	}
+
+	m.writeError(&b)
+	return b.String()
+}
+
+func (m archiveModel) writeError(b *strings.Builder) {
+	if m.err != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+	}
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+func TestArchive_ListAndDrop(t *testing.T) {
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir())
+	m := newArchive(NewStyles(config.Default().Colors), orch, 160)
+	if content := m.ViewContent(); !strings.Contains(content, "Loading") {
+		t.Errorf("view should show loading:\n%s", content)
+	}
+
+	m, _ = m.Update(archiveLoadedMsg{branches: []git.ArchivedBranch{
+		{Ref: "refs/mastermind/archive/2026-03-02/feat/new", Branch: "feat/new", Date: "2026-03-02", Commit: "abc1234", Subject: "add login"},
+		{Ref: "refs/mastermind/archive/2026-03-01/feat/old", Branch: "feat/old", Date: "2026-03-01", Commit: "def5678", Subject: "add logout"},
+	}})
+	content := m.ViewContent()
+	for _, want := range []string{"> 2026-03-02   feat/new  abc1234 add login", "feat/old"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("view should contain %q:\n%s", want, content)
+		}
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if !m.confirming || !strings.Contains(m.ViewContent(), "Drop feat/old") {
+		t.Fatalf("x should ask to drop the selected branch:\n%s", m.ViewContent())
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.confirming {
+		t.Error("n should back out of dropping")
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, ok := cmd().(archiveCloseMsg); !ok {
+		t.Error("esc should close the archive")
+	}
+}
+
+func TestArchive_EmptyExplainsSetting(t *testing.T) {
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir())
+	m := newArchive(NewStyles(config.Default().Colors), orch, 160)
+	m, _ = m.Update(archiveLoadedMsg{})
+
+	if content := m.ViewContent(); !strings.Contains(content, "archive_branches = true") {
+		t.Errorf("view should say how to turn archiving on:\n%s", content)
+	}
+}
@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+// clearDoneModel dismisses every finished agent after one confirmation:
+// the agents that are done and, optionally, those whose branch is merged
+// but that still wait for review.
+type clearDoneModel struct {
+	orch   *orchestrator.Orchestrator
+	styles Styles
+	width  int
+
+	loading      bool
+	agents       []orchestrator.DoneAgent
+	withMerged   bool
+	deleteBranch bool
+	dismissing   bool
+	err          string
+
+	spinner spinner.Model
+}
+
+type clearDoneLoadedMsg struct {
+	agents []orchestrator.DoneAgent
+}
+
+type clearDoneCloseMsg struct{}
+
+func newClearDone(s Styles, orch *orchestrator.Orchestrator, width int) clearDoneModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.MiniDot
+	return clearDoneModel{
+		orch:       orch,
+		styles:     s,
+		width:      width,
+		loading:    true,
+		withMerged: true,
+		spinner:    sp,
+	}
+}
+
+// Init lists the finished agents in the background, since finding merged
+// branches runs git.
+func (m clearDoneModel) Init() tea.Cmd {
+	orch := m.orch
+	return func() tea.Msg {
+		return clearDoneLoadedMsg{agents: orch.DoneAgents()}
+	}
+}
+
+// selected returns the IDs of the agents that will be dismissed.
+func (m clearDoneModel) selected() []string {
+	var ids []string
+	for _, a := range m.agents {
+		if !a.Merged || m.withMerged {
+			ids = append(ids, a.AgentID)
+		}
+	}
+	return ids
+}
+
+func (m clearDoneModel) Update(msg tea.Msg) (clearDoneModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case clearDoneLoadedMsg:
+		m.loading = false
+		m.agents = msg.agents
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.dismissing {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case orchestrator.BulkDismissMsg:
+		m.dismissing = false
+		if len(msg.Errors) > 0 {
+			m.err = strings.Join(msg.Errors, "; ")
+			m.loading = true
+			return m, m.Init()
+		}
+		return m, func() tea.Msg { return clearDoneCloseMsg{} }
+
+	case tea.KeyMsg:
+		if m.dismissing {
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc", "n", "C":
+			return m, func() tea.Msg { return clearDoneCloseMsg{} }
+		case "m":
+			m.withMerged = !m.withMerged
+		case "b":
+			m.deleteBranch = !m.deleteBranch
+		case "y", "enter":
+			ids := m.selected()
+			if m.loading || len(ids) == 0 {
+				return m, nil
+			}
+			m.dismissing = true
+			m.err = ""
+			orch, del := m.orch, m.deleteBranch
+			return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+				return orch.DismissAgents(ids, del)
+			})
+		}
+	}
+	return m, nil
+}
+
+func (m clearDoneModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Dismiss Done Agents"))
+	b.WriteString("\n\n")
+
+	if m.loading {
+		b.WriteString(m.styles.WizardDim.Render("  Loading…"))
+		return b.String()
+	}
+
+	merged := 0
+	for _, a := range m.agents {
+		if a.Merged {
+			merged++
+			if !m.withMerged {
+				continue
+			}
+		}
+		line := fmt.Sprintf("  %s  %s", a.AgentID, truncate(a.Branch, max(m.width/3, 20)))
+		if a.Merged {
+			line += m.styles.WizardDim.Render("  (merged)")
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	if len(m.selected()) == 0 {
+		b.WriteString(m.styles.WizardDim.Render("  No done agents."))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	check := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	b.WriteString(fmt.Sprintf("  %s Include %d merged agent(s) still waiting for review\n", check(m.withMerged), merged))
+	b.WriteString(fmt.Sprintf("  %s Delete their branches\n", check(m.deleteBranch)))
+	b.WriteString("\n")
+
+	if m.dismissing {
+		b.WriteString(m.styles.WizardActive.Render("  " + m.spinner.View() + " Dismissing..."))
+	} else {
+		b.WriteString(m.styles.Help.Render("  y/enter: dismiss all │ m: toggle merged │ b: toggle branches │ esc: cancel"))
+	}
+
+	if m.err != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+type startCloneMsg struct {
+	agentID    string
+	branch     string
+	baseBranch string
+	prompt     string
+}
+
+type cloneDoneMsg struct {
+	agentID string
+	branch  string
+}
+type cloneCancelMsg struct{}
+type cloneErrorMsg struct {
+	err string
+}
+
+// cloneModel asks for the branch of a clone of an agent and whether it
+// forks the agent's branch or starts fresh from its base.
+type cloneModel struct {
+	orch   *orchestrator.Orchestrator
+	err    string
+	width  int
+	styles Styles
+
+	agentID    string
+	branch     string
+	baseBranch string
+	prompt     string
+	mode       orchestrator.CloneMode
+	cloning    bool
+
+	input   textinput.Model
+	spinner spinner.Model
+}
+
+func newClone(s Styles, orch *orchestrator.Orchestrator, msg startCloneMsg, width int) cloneModel {
+	ti := textinput.New()
+	ti.Placeholder = "branch for the clone"
+	ti.SetValue(orch.CloneBranchName(msg.branch))
+	ti.CursorEnd()
+	ti.Focus()
+
+	sp := spinner.New()
+	sp.Spinner = spinner.MiniDot
+	return cloneModel{
+		orch:       orch,
+		agentID:    msg.agentID,
+		branch:     msg.branch,
+		baseBranch: msg.baseBranch,
+		prompt:     msg.prompt,
+		styles:     s,
+		width:      width,
+		input:      ti,
+		spinner:    sp,
+	}
+}
+
+func (m cloneModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m cloneModel) Update(msg tea.Msg) (cloneModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if m.cloning {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case cloneErrorMsg:
+		m.cloning = false
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.cloning {
+			return m, nil
+		}
+		m.err = ""
+
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return cloneCancelMsg{} }
+		case "tab":
+			if m.mode == orchestrator.CloneFork {
+				m.mode = orchestrator.CloneFresh
+			} else {
+				m.mode = orchestrator.CloneFork
+			}
+			return m, nil
+		case "enter":
+			branch := strings.TrimSpace(m.input.Value())
+			if err := git.ValidateBranchName(branch); err != nil {
+				m.err = "invalid branch name: " + err.Error()
+				return m, nil
+			}
+			m.cloning = true
+			id, mode := m.agentID, m.mode
+			return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+				if err := m.orch.CloneAgent(id, branch, mode); err != nil {
+					return cloneErrorMsg{err: err.Error()}
+				}
+				return cloneDoneMsg{agentID: id, branch: branch}
+			})
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m cloneModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Clone Agent " + m.agentID))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("  From:      %s\n", m.branch))
+	base := m.baseBranch
+	if base == "" {
+		base = "—"
+	}
+	b.WriteString(fmt.Sprintf("  Base:      %s\n", base))
+	b.WriteString("\n")
+
+	forkLine := "  Fork the branch, with its uncommitted changes"
+	freshLine := "  Start fresh from the base with the same prompt"
+	if m.mode == orchestrator.CloneFork {
+		b.WriteString(m.styles.WizardActive.Render("> " + strings.TrimPrefix(forkLine, "  ")))
+		b.WriteString("\n" + freshLine + "\n")
+	} else {
+		b.WriteString(forkLine + "\n")
+		b.WriteString(m.styles.WizardActive.Render("> " + strings.TrimPrefix(freshLine, "  ")))
+		b.WriteString("\n")
+	}
+	if m.mode == orchestrator.CloneFresh {
+		prompt := m.prompt
+		if prompt == "" {
+			prompt = "(none recorded — the clone starts without a task)"
+		}
+		b.WriteString(m.styles.WizardDim.Render("    Prompt: " + truncate(prompt, max(m.width/2-16, 20))))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString("  New branch: " + m.input.View())
+	b.WriteString("\n\n")
+
+	if m.cloning {
+		b.WriteString(m.styles.WizardActive.Render("  " + m.spinner.View() + " Cloning..."))
+	} else {
+		b.WriteString(m.styles.Help.Render("  enter: clone │ tab: fork/fresh │ esc: cancel"))
+	}
+
+	if m.err != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+	}
+
+	return b.String()
+}
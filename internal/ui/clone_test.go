@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+func TestClone_SuggestsBranchAndTogglesMode(t *testing.T) {
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir())
+	m := newClone(NewStyles(config.Default().Colors), orch,
+		startCloneMsg{agentID: "a1", branch: "feat/x", baseBranch: "main"}, 120)
+
+	if got := m.input.Value(); got != "feat/x-alt" {
+		t.Errorf("suggested branch = %q, want feat/x-alt", got)
+	}
+	if m.mode != orchestrator.CloneFork {
+		t.Errorf("default mode = %v, want fork", m.mode)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m.mode != orchestrator.CloneFresh {
+		t.Errorf("mode after tab = %v, want fresh", m.mode)
+	}
+	if !strings.Contains(m.ViewContent(), "none recorded") {
+		t.Error("fresh mode should say the agent has no recorded prompt")
+	}
+
+	m.input.SetValue("bad name")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !strings.Contains(m.err, "invalid branch name") {
+		t.Errorf("err = %q, want invalid branch name", m.err)
+	}
+}
@@ -0,0 +1,254 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandTimeout bounds a one-off worktree command.
+const commandTimeout = 5 * time.Minute
+
+// commandWaitDelay is how long a cancelled command's output is waited for
+// once its process group was killed.
+const commandWaitDelay = 2 * time.Second
+
+// commandMaxLines is how many trailing output lines are kept.
+const commandMaxLines = 500
+
+// commandPageSize is how many output lines are shown at once.
+const commandPageSize = 20
+
+type startCommandMsg struct {
+	agentID string
+	branch  string
+	wtPath  string
+}
+
+type commandCloseMsg struct{}
+
+type commandDoneMsg struct {
+	output   []string
+	exitCode int
+	elapsed  time.Duration
+	err      error
+}
+
+// commandModel runs a one-off shell command in an agent's worktree and
+// shows its output.
+type commandModel struct {
+	agentID string
+	branch  string
+	wtPath  string
+	styles  Styles
+	width   int
+
+	input   textinput.Model
+	running bool
+	cancel  context.CancelFunc
+	ran     string
+	done    *commandDoneMsg
+	offset  int
+
+	spinner spinner.Model
+}
+
+func newCommand(s Styles, msg startCommandMsg, width int) commandModel {
+	ti := textinput.New()
+	ti.Placeholder = "shell command (e.g. go test ./...)"
+	ti.Focus()
+
+	sp := spinner.New()
+	sp.Spinner = spinner.MiniDot
+	return commandModel{
+		agentID: msg.agentID,
+		branch:  msg.branch,
+		wtPath:  msg.wtPath,
+		styles:  s,
+		width:   width,
+		input:   ti,
+		spinner: sp,
+	}
+}
+
+func (m commandModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// runCommand runs command through the user's shell in dir.
+func runCommand(ctx context.Context, dir, command string) commandDoneMsg {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, shell, "-c", command)
+	cmd.Dir = dir
+	// The command runs in its own process group, all of which is killed on
+	// timeout or cancel: killing only the shell would leave what it
+	// started, e.g. a test binary, running and holding the output open.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = commandWaitDelay
+	out, err := cmd.CombinedOutput()
+
+	res := commandDoneMsg{elapsed: time.Since(start)}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+	if len(lines) > commandMaxLines {
+		lines = lines[len(lines)-commandMaxLines:]
+	}
+	res.output = lines
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		res.err = fmt.Errorf("timed out after %s", commandTimeout)
+	case errors.Is(ctx.Err(), context.Canceled):
+		res.err = errors.New("cancelled")
+	case errors.As(err, &exitErr):
+		res.exitCode = exitErr.ExitCode()
+	case err != nil:
+		res.err = err
+	}
+	return res
+}
+
+func (m commandModel) Update(msg tea.Msg) (commandModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if m.running {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case commandDoneMsg:
+		m.running = false
+		m.cancel = nil
+		m.done = &msg
+		m.offset = max(len(msg.output)-commandPageSize, 0)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running {
+			if msg.String() == "esc" || msg.String() == "ctrl+c" {
+				m.cancel()
+			}
+			return m, nil
+		}
+
+		if m.done != nil {
+			switch msg.String() {
+			case "down", "j":
+				if m.offset < len(m.done.output)-commandPageSize {
+					m.offset++
+				}
+			case "up", "k":
+				if m.offset > 0 {
+					m.offset--
+				}
+			case "!", "r":
+				// Edit and run another command.
+				m.done = nil
+				m.input.Focus()
+				return m, textinput.Blink
+			case "esc", "q", "enter":
+				return m, func() tea.Msg { return commandCloseMsg{} }
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return commandCloseMsg{} }
+		case "enter":
+			command := strings.TrimSpace(m.input.Value())
+			if command == "" {
+				return m, nil
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+			m.cancel = cancel
+			m.running = true
+			m.ran = command
+			m.input.Blur()
+			dir := m.wtPath
+			return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+				defer cancel()
+				return runCommand(ctx, dir, command)
+			})
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m commandModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Run in " + m.agentID))
+	b.WriteString("\n")
+	b.WriteString(m.styles.WizardDim.Render("  " + truncate(m.branch, max(m.width/2-4, 10))))
+	b.WriteString("\n\n")
+
+	if m.done == nil && !m.running {
+		b.WriteString("  $ " + m.input.View())
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  enter: run │ esc: cancel"))
+		return b.String()
+	}
+
+	b.WriteString(m.styles.WizardActive.Render("  $ " + m.ran))
+	b.WriteString("\n\n")
+
+	if m.running {
+		b.WriteString(m.styles.WizardActive.Render("  " + m.spinner.View() + " Running..."))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  esc: cancel"))
+		return b.String()
+	}
+
+	d := m.done
+	end := min(m.offset+commandPageSize, len(d.output))
+	for _, line := range d.output[m.offset:end] {
+		b.WriteString("  " + truncate(line, max(m.width/2-4, 20)))
+		b.WriteString("\n")
+	}
+	if len(d.output) == 0 {
+		b.WriteString(m.styles.WizardDim.Render("  (no output)"))
+		b.WriteString("\n")
+	} else if len(d.output) > commandPageSize {
+		b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("  lines %d–%d of %d", m.offset+1, end, len(d.output))))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	elapsed := d.elapsed.Round(100 * time.Millisecond)
+	switch {
+	case d.err != nil:
+		b.WriteString(m.styles.Error.Render(fmt.Sprintf("  Error: %v", d.err)))
+	case d.exitCode != 0:
+		b.WriteString(m.styles.Error.Render(fmt.Sprintf("  exit %d in %s", d.exitCode, elapsed)))
+	default:
+		b.WriteString(m.styles.Reviewed.Render(fmt.Sprintf("  exit 0 in %s", elapsed)))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(m.styles.Help.Render("  j/k: scroll │ !: new command │ esc: close"))
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/config"
+)
+
+func TestRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "marker.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SHELL", "/bin/sh")
+
+	res := runCommand(context.Background(), dir, "ls; exit 3")
+
+	if res.err != nil {
+		t.Fatalf("err = %v", res.err)
+	}
+	if res.exitCode != 3 {
+		t.Errorf("exitCode = %d, want 3", res.exitCode)
+	}
+	if len(res.output) != 1 || res.output[0] != "marker.txt" {
+		t.Errorf("output = %q, want [marker.txt]", res.output)
+	}
+}
+
+func TestRunCommand_CancelKillsProcessGroup(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The background sleep inherits the output pipe; only killing the
+	// whole group lets the command return.
+	start := time.Now()
+	res := runCommand(ctx, t.TempDir(), "sleep 30 & sleep 30")
+
+	if res.err == nil || !strings.Contains(res.err.Error(), "timed out") {
+		t.Errorf("err = %v, want a timeout", res.err)
+	}
+	if elapsed := time.Since(start); elapsed > commandWaitDelay {
+		t.Errorf("returned after %s, want the process group killed at the timeout", elapsed)
+	}
+}
+
+func TestCommandModel_RunAndShowOutput(t *testing.T) {
+	s := NewStyles(config.Default().Colors)
+	m := newCommand(s, startCommandMsg{agentID: "a1", branch: "feat/x", wtPath: t.TempDir()}, 120)
+
+	m.input.SetValue("echo hi")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.running || cmd == nil {
+		t.Fatal("enter should start the command")
+	}
+
+	m, _ = m.Update(commandDoneMsg{output: []string{"hi"}})
+	view := m.ViewContent()
+	for _, want := range []string{"$ echo hi", "hi", "exit 0"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, ok := cmd().(commandCloseMsg); !ok {
+		t.Error("esc after the run should close the panel")
+	}
+}
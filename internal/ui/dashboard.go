@@ -3,7 +3,6 @@ package ui
 import (
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
@@ -14,8 +13,11 @@ import (
 
 	"github.com/simonbystrom/mastermind/internal/agent"
 	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/harness"
 	"github.com/simonbystrom/mastermind/internal/hook"
+	"github.com/simonbystrom/mastermind/internal/monitor"
 	"github.com/simonbystrom/mastermind/internal/orchestrator"
+	"github.com/simonbystrom/mastermind/internal/team"
 )
 
 type sortMode int
@@ -45,11 +47,33 @@ type dashboardKeyMap struct {
 	Focus      key.Binding
 	Preview    key.Binding
 	Merge      key.Binding
+	QuickMerge key.Binding
+	Sync       key.Binding
+	OpenPR     key.Binding
 	Resume     key.Binding
+	Refresh    key.Binding
 	Prune      key.Binding
 	Dismiss    key.Binding
 	DismissDel key.Binding
+	ClearDone  key.Binding
 	Sort       key.Binding
+	Times      key.Binding
+	Graph      key.Binding
+	Maint      key.Binding
+	Archive    key.Binding
+	Shell      key.Binding
+	Command    key.Binding
+	Clone      key.Binding
+	Checkpoint key.Binding
+	Rollback   key.Binding
+	AllowPush  key.Binding
+	Report     key.Binding
+	Diff       key.Binding
+	Team       key.Binding
+	Errors     key.Binding
+	Resize     key.Binding
+	Instances  key.Binding
+	Expand     key.Binding
 	Quit       key.Binding
 }
 
@@ -59,23 +83,45 @@ func newDashboardKeyMap() dashboardKeyMap {
 		Focus:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter:", "focus")),
 		Preview:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p:", "preview")),
 		Merge:      key.NewBinding(key.WithKeys("m"), key.WithHelp("m:", "merge")),
+		QuickMerge: key.NewBinding(key.WithKeys("M"), key.WithHelp("M:", "quick merge")),
+		Sync:       key.NewBinding(key.WithKeys("u"), key.WithHelp("u:", "sync base")),
+		OpenPR:     key.NewBinding(key.WithKeys("P"), key.WithHelp("P:", "open PR")),
 		Resume:     key.NewBinding(key.WithKeys("r"), key.WithHelp("r:", "resume")),
+		Refresh:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r:", "refresh preview")),
 		Prune:      key.NewBinding(key.WithKeys("w"), key.WithHelp("w:", "prune wt")),
 		Dismiss:    key.NewBinding(key.WithKeys("d"), key.WithHelp("d:", "dismiss")),
 		DismissDel: key.NewBinding(key.WithKeys("D"), key.WithHelp("D:", "dismiss+del")),
+		ClearDone:  key.NewBinding(key.WithKeys("C"), key.WithHelp("C:", "clear done")),
 		Sort:       key.NewBinding(key.WithKeys("s"), key.WithHelp("s:", "sort (id)")),
+		Times:      key.NewBinding(key.WithKeys("T"), key.WithHelp("T:", "clock times")),
+		Graph:      key.NewBinding(key.WithKeys("g"), key.WithHelp("g:", "graph")),
+		Maint:      key.NewBinding(key.WithKeys("x"), key.WithHelp("x:", "maint")),
+		Archive:    key.NewBinding(key.WithKeys("A"), key.WithHelp("A:", "archive")),
+		Shell:      key.NewBinding(key.WithKeys("t"), key.WithHelp("t:", "shell")),
+		Command:    key.NewBinding(key.WithKeys("!"), key.WithHelp("!:", "run")),
+		Clone:      key.NewBinding(key.WithKeys("y"), key.WithHelp("y:", "clone")),
+		Checkpoint: key.NewBinding(key.WithKeys("S"), key.WithHelp("S:", "checkpoint")),
+		Rollback:   key.NewBinding(key.WithKeys("R"), key.WithHelp("R:", "rollback")),
+		AllowPush:  key.NewBinding(key.WithKeys("a"), key.WithHelp("a:", "allow push")),
+		Report:     key.NewBinding(key.WithKeys("v"), key.WithHelp("v:", "report")),
+		Diff:       key.NewBinding(key.WithKeys("V"), key.WithHelp("V:", "diff")),
+		Team:       key.NewBinding(key.WithKeys("i"), key.WithHelp("i:", "team")),
+		Errors:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e:", "errors")),
+		Instances:  key.NewBinding(key.WithKeys("I"), key.WithHelp("I:", "instances")),
+		Resize:     key.NewBinding(key.WithKeys("<", ">", "-", "+"), key.WithHelp("<>-+:", "resize")),
+		Expand:     key.NewBinding(key.WithKeys(" "), key.WithHelp("space:", "expand")),
 		Quit:       key.NewBinding(key.WithKeys("q"), key.WithHelp("q:", "quit")),
 	}
 }
 
 func (k dashboardKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.New, k.Focus, k.Preview, k.Merge, k.Resume, k.Prune, k.Dismiss, k.DismissDel, k.Sort, k.Quit}
+	return []key.Binding{k.New, k.Focus, k.Preview, k.Merge, k.QuickMerge, k.Sync, k.OpenPR, k.Resume, k.Refresh, k.Shell, k.Command, k.Clone, k.Checkpoint, k.Rollback, k.AllowPush, k.Diff, k.Report, k.Team, k.Prune, k.Dismiss, k.DismissDel, k.ClearDone, k.Sort, k.Times, k.Graph, k.Maint, k.Archive, k.Errors, k.Instances, k.Expand, k.Resize, k.Quit}
 }
 
 func (k dashboardKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.New, k.Focus, k.Preview, k.Merge, k.Resume, k.Prune},
-		{k.Dismiss, k.DismissDel, k.Sort, k.Quit},
+		{k.New, k.Focus, k.Preview, k.Merge, k.QuickMerge, k.Sync, k.OpenPR, k.Resume, k.Refresh, k.Shell, k.Command, k.Clone, k.Checkpoint, k.Rollback, k.AllowPush, k.Diff, k.Report, k.Team, k.Prune},
+		{k.Dismiss, k.DismissDel, k.ClearDone, k.Sort, k.Times, k.Graph, k.Maint, k.Archive, k.Errors, k.Instances, k.Expand, k.Resize, k.Quit},
 	}
 }
 
@@ -83,6 +129,7 @@ type notification struct {
 	text  string
 	time  time.Time
 	style lipgloss.Style
+	agent string // ID of the agent the notification is about, if any
 }
 
 type tickMsg time.Time
@@ -93,19 +140,44 @@ type resumeErrorMsg struct {
 	err     string
 }
 
+// pushGuardMsg reports the outcome of allowing or blocking an agent's
+// pushes.
+type pushGuardMsg struct {
+	agentID string
+	allow   bool
+	err     string
+}
+
 type dashboardModel struct {
 	store         *agent.Store
 	orch          *orchestrator.Orchestrator
 	repoPath      string
 	session       string
-	cursor        int
+	instance      string // name of this mastermind instance, shown in the title
+	screenReader  bool   // plain-text output; see config.Accessibility
+	rowSpacing    int    // blank lines between agent rows
+	diffPreview   bool   // p shows the diff-only preview; see config.Preview
+	lastStatus    map[string]string
+	announcements []string // notifications not yet printed for a screen reader
+	tickInterval  time.Duration
+	lastSignature string
+	sorted        *sortCache
+	ticks         int
+	cursor        int    // index of the selected agent in sortedAgents
+	selectedID    string // the selected agent, which the cursor follows
+	// agents whose rows show their details
+	expanded      map[string]bool
 	notifications []notification
 	width         int
 	height        int
 	err           string
+	errors        []errorEntry
 	sortBy        sortMode
+	absoluteTimes bool          // Started and Ready show clock times instead of "2h ago"
+	noSignalAfter time.Duration // silence before a working agent shows "no signal"; 0 never
 	styles        Styles
 	layout        config.Layout
+	format        formatter
 	keys          dashboardKeyMap
 	help          help.Model
 
@@ -114,7 +186,7 @@ type dashboardModel struct {
 	cachedLogoWidth int
 }
 
-func newDashboard(s Styles, layout config.Layout, orch *orchestrator.Orchestrator, store *agent.Store, repoPath, session string) dashboardModel {
+func newDashboard(s Styles, layout config.Layout, f formatter, noSignalAfter time.Duration, orch *orchestrator.Orchestrator, store *agent.Store, repoPath, session string) dashboardModel {
 	keys := newDashboardKeyMap()
 	h := help.New()
 	h.ShortSeparator = " │ "
@@ -125,41 +197,181 @@ func newDashboard(s Styles, layout config.Layout, orch *orchestrator.Orchestrato
 	h.Styles.FullDesc = s.Help
 	h.Styles.FullSeparator = s.Help
 	h.Styles.Ellipsis = s.Help
-	return dashboardModel{
-		store:    store,
-		orch:     orch,
-		repoPath: repoPath,
-		session:  session,
-		styles:   s,
-		layout:   layout,
-		keys:     keys,
-		help:     h,
+	m := dashboardModel{
+		store:         store,
+		orch:          orch,
+		repoPath:      repoPath,
+		session:       session,
+		styles:        s,
+		layout:        layout,
+		format:        f,
+		keys:          keys,
+		noSignalAfter: noSignalAfter,
+		help:          h,
+		sorted:        &sortCache{},
+		expanded:      map[string]bool{},
+	}
+	if broken := orch.BrokenWorktrees(); len(broken) > 0 {
+		m.addNotification(notification{
+			text:  fmt.Sprintf("%d broken worktree(s) found — press x to repair", len(broken)),
+			time:  time.Now(),
+			style: m.styles.Attention,
+		})
 	}
+	return m
 }
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+// The dashboard ticks every minTick while its data changes. Ticks that
+// only move durations and relative times along back off to maxTick, so a
+// quiet dashboard redraws rarely, which keeps it from flickering over
+// slow connections. Bubble Tea only rewrites the lines that changed.
+const (
+	minTick = time.Second
+	maxTick = 5 * time.Second
+)
+
+func tickCmd(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
+// nextTick returns the interval until the next tick: minTick if anything
+// but the clock changed what the dashboard shows since the last tick,
+// otherwise twice the previous interval, up to maxTick.
+func (m *dashboardModel) nextTick(now time.Time) time.Duration {
+	sig := m.signature(now)
+	if sig != m.lastSignature {
+		m.lastSignature = sig
+		m.tickInterval = minTick
+	} else {
+		m.tickInterval = min(max(m.tickInterval*2, minTick), maxTick)
+	}
+	return m.tickInterval
+}
+
+// signature sums up the dashboard's data apart from durations and
+// relative times.
+func (m dashboardModel) signature(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d|%s|%s|", m.cursor, m.err, m.orch.GetPreviewAgentID())
+	if n := len(m.notifications); n > 0 {
+		fmt.Fprintf(&b, "%s %v|", m.notifications[n-1].text, m.notifications[n-1].time)
+	}
+	for _, a := range m.store.All() {
+		fmt.Fprintf(&b, "%s %s %s %v %s %v|", a.ID, a.GetStatus(), a.GetWaitingFor(), a.GetPaneUnknown(), m.noSignal(a, now), a.GetReadyAt())
+		if sd := a.GetStatuslineData(); sd != nil {
+			fmt.Fprintf(&b, "%+v|", *sd)
+		}
+		fmt.Fprintf(&b, "%+v|%+v|", a.GetTodos(), a.GetTeam())
+	}
+	return b.String()
+}
+
 func (m *dashboardModel) addNotification(n notification) {
 	m.notifications = append(m.notifications, n)
 	if len(m.notifications) > 10 {
 		m.notifications = m.notifications[len(m.notifications)-10:]
 	}
+	if m.screenReader {
+		m.announcements = append(m.announcements, m.format.clock(n.time)+" "+n.text)
+	}
+}
+
+// setError shows text as the current error and records it in the error
+// history, which keeps the full text for the errors panel.
+func (m *dashboardModel) setError(text string) {
+	e := errorEntry{time: time.Now(), text: strings.TrimRight(text, "\n")}
+	m.err = e.summary()
+	m.errors = append(m.errors, e)
+	if len(m.errors) > maxErrors {
+		m.errors = m.errors[len(m.errors)-maxErrors:]
+	}
+}
+
+// Layout sizes change in resizeStep steps within these bounds.
+const (
+	resizeStep        = 5
+	minDashboardWidth = 20
+	maxDashboardWidth = 80
+	minLazygitSplit   = 20
+	maxLazygitSplit   = 90
+)
+
+// layoutSavedMsg reports the outcome of saving a resized layout to the
+// config file.
+type layoutSavedMsg struct{ err string }
+
+// resize narrows or widens the dashboard next to side panels with < and >,
+// and shrinks or grows lazygit panes opened from now on with - and +. The
+// new sizes are saved to the config file so they survive a restart.
+func (m *dashboardModel) resize(k string) tea.Cmd {
+	l := m.layout
+	switch k {
+	case "<":
+		l.DashboardWidth = max(l.DashboardWidth-resizeStep, minDashboardWidth)
+	case ">":
+		l.DashboardWidth = min(l.DashboardWidth+resizeStep, maxDashboardWidth)
+	case "-":
+		l.LazygitSplit = max(l.LazygitSplit-resizeStep, minLazygitSplit)
+	case "+":
+		l.LazygitSplit = min(l.LazygitSplit+resizeStep, maxLazygitSplit)
+	}
+	if l == m.layout {
+		return nil
+	}
+	m.layout = l
+	m.orch.SetLazygitSplit(l.LazygitSplit)
+	m.addNotification(notification{
+		text:  fmt.Sprintf("Dashboard width %d%%, lazygit split %d%%", l.DashboardWidth, l.LazygitSplit),
+		time:  time.Now(),
+		style: m.styles.Done,
+	})
+	return func() tea.Msg {
+		if err := config.SaveLayout(config.Path(), l); err != nil {
+			return layoutSavedMsg{err: err.Error()}
+		}
+		return layoutSavedMsg{}
+	}
 }
 
 func (m dashboardModel) Init() tea.Cmd {
-	return tickCmd()
+	return tickCmd(minTick)
 }
 
 func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
+	m.followSelection()
+	cursor := m.cursor
+	m, cmd := m.update(msg)
+	m.trackCursor(cursor)
+	return m, cmd
+}
+
+func (m dashboardModel) update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case orchestrator.ClearAttentionMsg:
 		// Consumed silently — the indicator was already cleared by the tea.Cmd.
 		return m, nil
 
+	case tickMsg:
+		m.ticks++
+		if m.screenReader {
+			m.announceStatusChanges()
+		}
+		return m, nil
+
+	case orchestrator.StoreChangedMsg:
+		// The message itself redraws the dashboard; the ticks that follow
+		// are fast again to catch up on durations.
+		m.tickInterval = minTick
+		if agents := m.sortedAgents(); m.cursor >= len(agents) && m.cursor > 0 {
+			m.cursor = len(agents) - 1
+		}
+		if m.screenReader {
+			m.announceStatusChanges()
+		}
+		return m, nil
+
 	case orchestrator.AgentFinishedMsg:
 		name := msg.AgentID
 		var text string
@@ -175,6 +387,20 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			text:  text,
 			time:  time.Now(),
 			style: style,
+			agent: msg.AgentID,
+		})
+		return m, nil
+
+	case orchestrator.SessionChangedMsg:
+		m.session = msg.Name
+		text := fmt.Sprintf("tmux session renamed to %s", msg.Name)
+		if msg.Moved {
+			text = fmt.Sprintf("Moved to tmux session %s — new agents open there", msg.Name)
+		}
+		m.addNotification(notification{
+			text:  text,
+			time:  time.Now(),
+			style: m.styles.Done,
 		})
 		return m, nil
 
@@ -185,6 +411,7 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			text:  fmt.Sprintf("Agent %s window closed", name),
 			time:  time.Now(),
 			style: m.styles.Done,
+			agent: msg.AgentID,
 		})
 		agents := m.sortedAgents()
 		if m.cursor >= len(agents) && m.cursor > 0 {
@@ -207,6 +434,7 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			text:  text,
 			time:  time.Now(),
 			style: style,
+			agent: msg.AgentID,
 		})
 		return m, nil
 
@@ -214,20 +442,36 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 		name := msg.AgentID
 		var text string
 		var style lipgloss.Style
-		if msg.Success {
+		if msg.Synced && msg.Success {
+			text = fmt.Sprintf("Agent %s synced with its base", name)
+			style = m.styles.Reviewed
+		} else if msg.Synced && msg.Conflict {
+			text = fmt.Sprintf("Agent %s conflicts with its base — resolve in lazygit", name)
+			style = m.styles.Conflicts
+		} else if msg.Synced && msg.Error != "" {
+			m.setError(fmt.Sprintf("sync %s: %s", name, msg.Error))
+			text = fmt.Sprintf("Agent %s sync failed: %s", name, errorEntry{text: msg.Error}.summary())
+			style = m.styles.Error
+		} else if msg.Success && msg.PushError != "" {
+			m.setError(fmt.Sprintf("push after merging %s: %s", name, msg.PushError))
+			text = fmt.Sprintf("Agent %s merged, but pushing failed: %s", name, msg.PushError)
+			style = m.styles.Error
+		} else if msg.Success {
 			text = fmt.Sprintf("Agent %s merged successfully", name)
 			style = m.styles.Reviewed
 		} else if msg.Conflict {
 			text = fmt.Sprintf("Agent %s merge has conflicts — resolve in lazygit", name)
 			style = m.styles.Conflicts
 		} else if msg.Error != "" {
-			text = fmt.Sprintf("Agent %s merge failed: %s", name, msg.Error)
+			m.setError(fmt.Sprintf("merge %s: %s", name, msg.Error))
+			text = fmt.Sprintf("Agent %s merge failed: %s", name, errorEntry{text: msg.Error}.summary())
 			style = m.styles.Error
 		}
 		m.addNotification(notification{
 			text:  text,
 			time:  time.Now(),
 			style: style,
+			agent: msg.AgentID,
 		})
 		agents := m.sortedAgents()
 		if m.cursor >= len(agents) && m.cursor > 0 {
@@ -235,12 +479,158 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 		}
 		return m, nil
 
+	case orchestrator.AutoCleanupMsg:
+		why := "done"
+		if msg.Rule == "merged" {
+			why = "branch merged"
+		}
+		if msg.Error != "" {
+			m.setError(fmt.Sprintf("agent %s: cleanup (%s): %s", msg.AgentID, why, msg.Error))
+			return m, nil
+		}
+		m.addNotification(notification{
+			text:  fmt.Sprintf("Agent %s: cleaned up %s (%s)", msg.AgentID, msg.Branch, why),
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+			agent: msg.AgentID,
+		})
+		agents := m.sortedAgents()
+		if m.cursor >= len(agents) && m.cursor > 0 {
+			m.cursor = len(agents) - 1
+		}
+		return m, nil
+
+	case orchestrator.PullRequestMergedMsg:
+		text := fmt.Sprintf("Agent %s: pull request #%d was merged", msg.AgentID, msg.Number)
+		if msg.Dismissed {
+			text += " — agent cleaned up"
+		} else if msg.Kept != "" {
+			text += " — agent kept: " + msg.Kept
+		}
+		m.addNotification(notification{
+			text:  text,
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+			agent: msg.AgentID,
+		})
+		return m, nil
+
+	case orchestrator.PullRequestMsg:
+		if msg.Error != "" {
+			m.setError(fmt.Sprintf("pull request %s: %s", msg.AgentID, msg.Error))
+			return m, nil
+		}
+		text := fmt.Sprintf("Agent %s: opened %s", msg.AgentID, msg.URL)
+		if len(msg.Reviewers) > 0 {
+			text += " (review: " + strings.Join(msg.Reviewers, ", ") + ")"
+		}
+		m.addNotification(notification{
+			text:  text,
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+			agent: msg.AgentID,
+		})
+		if msg.Warning != "" {
+			m.setError(fmt.Sprintf("pull request %s: %s", msg.AgentID, msg.Warning))
+		}
+		return m, nil
+
+	case orchestrator.CheckpointMsg:
+		if msg.Error != "" {
+			m.setError(fmt.Sprintf("checkpoint %s: %s", msg.AgentID, msg.Error))
+			return m, nil
+		}
+		m.addNotification(notification{
+			text:  fmt.Sprintf("Agent %s: checkpoint %s", msg.AgentID, msg.Tag),
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+			agent: msg.AgentID,
+		})
+		return m, nil
+
+	case orchestrator.PlaybookFinishedMsg:
+		switch {
+		case msg.Failed != "":
+			m.setError(fmt.Sprintf("agent %s: check %q failed\n%s", msg.AgentID, msg.Failed, msg.Output))
+		case msg.Error != "":
+			m.setError(fmt.Sprintf("agent %s: playbook: %s", msg.AgentID, msg.Error))
+		case msg.Merged:
+			m.addNotification(notification{
+				text:  fmt.Sprintf("Agent %s: %d check(s) passed — merged %s", msg.AgentID, msg.Checks, msg.Branch),
+				time:  time.Now(),
+				style: m.styles.Reviewed,
+				agent: msg.AgentID,
+			})
+			agents := m.sortedAgents()
+			if m.cursor >= len(agents) && m.cursor > 0 {
+				m.cursor = len(agents) - 1
+			}
+		default:
+			m.addNotification(notification{
+				text:  fmt.Sprintf("Agent %s: %d check(s) passed", msg.AgentID, msg.Checks),
+				time:  time.Now(),
+				style: m.styles.ReviewReady,
+				agent: msg.AgentID,
+			})
+		}
+		return m, nil
+
+	case orchestrator.BaseFetchedMsg:
+		if msg.Error != "" {
+			m.setError(fmt.Sprintf("could not fast-forward %s from origin, the agent starts from the local branch: %s", msg.Base, msg.Error))
+			return m, nil
+		}
+		m.addNotification(notification{
+			text:  fmt.Sprintf("Fast-forwarded %s from origin", msg.Base),
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+		})
+		return m, nil
+
+	case orchestrator.ReportCollectedMsg:
+		m.addNotification(notification{
+			text:  fmt.Sprintf("Agent %s: report saved to %s (v to view)", msg.AgentID, msg.Path),
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+			agent: msg.AgentID,
+		})
+		return m, nil
+
+	case orchestrator.AgentForceKilledMsg:
+		if msg.Error != "" {
+			m.setError(fmt.Sprintf("Agent %s (%s) ignored /exit and could not be killed with %s: %s", msg.AgentID, msg.Branch, msg.Signal, msg.Error))
+			return m, nil
+		}
+		m.addNotification(notification{
+			text:  fmt.Sprintf("Agent %s (%s) ignored /exit; its processes were killed with %s", msg.AgentID, msg.Branch, msg.Signal),
+			time:  time.Now(),
+			style: m.styles.Attention,
+			agent: msg.AgentID,
+		})
+		return m, nil
+
+	case orchestrator.StackRestackedMsg:
+		text := fmt.Sprintf("Agent %s restacked onto %s after %s merged", msg.AgentID, msg.NewBase, msg.Parent)
+		style := m.styles.Reviewed
+		if !msg.Rebased {
+			text = fmt.Sprintf("Agent %s now targets %s — rebase manually (%s)", msg.AgentID, msg.NewBase, msg.Reason)
+			style = m.styles.Attention
+		}
+		m.addNotification(notification{
+			text:  text,
+			time:  time.Now(),
+			style: style,
+			agent: msg.AgentID,
+		})
+		return m, nil
+
 	case orchestrator.PreviewStartedMsg:
 		name := msg.AgentID
 		m.addNotification(notification{
 			text:  fmt.Sprintf("Preview started for agent %s", name),
 			time:  time.Now(),
 			style: m.styles.Previewing,
+			agent: msg.AgentID,
 		})
 		return m, nil
 
@@ -250,11 +640,38 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			text:  fmt.Sprintf("Preview stopped for agent %s", name),
 			time:  time.Now(),
 			style: m.styles.Done,
+			agent: msg.AgentID,
+		})
+		if msg.Stash != "" {
+			m.addNotification(notification{
+				text:  fmt.Sprintf("Changes made in the main worktree during the preview were stashed as %s: %s", msg.Stash, strings.Join(msg.Stashed, ", ")),
+				time:  time.Now(),
+				style: m.styles.Previewing,
+				agent: msg.AgentID,
+			})
+		}
+		return m, nil
+
+	case orchestrator.PreviewStaleMsg:
+		m.addNotification(notification{
+			text:  fmt.Sprintf("Agent %s moved on since the preview started — r to refresh it", msg.AgentID),
+			time:  time.Now(),
+			style: m.styles.Previewing,
+			agent: msg.AgentID,
+		})
+		return m, nil
+
+	case orchestrator.PreviewRefreshedMsg:
+		m.addNotification(notification{
+			text:  fmt.Sprintf("Preview refreshed for agent %s", msg.AgentID),
+			time:  time.Now(),
+			style: m.styles.Previewing,
+			agent: msg.AgentID,
 		})
 		return m, nil
 
 	case orchestrator.PreviewErrorMsg:
-		m.err = msg.Error
+		m.setError(msg.Error)
 		return m, nil
 
 	case orchestrator.CleanupMsg:
@@ -284,11 +701,35 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			text:  fmt.Sprintf("Resumed agent %s", msg.agentID),
 			time:  time.Now(),
 			style: m.styles.Running,
+			agent: msg.agentID,
 		})
 		return m, nil
 
 	case resumeErrorMsg:
-		m.err = fmt.Sprintf("resume %s: %s", msg.agentID, msg.err)
+		m.setError(fmt.Sprintf("resume %s: %s", msg.agentID, msg.err))
+		return m, nil
+
+	case layoutSavedMsg:
+		if msg.err != "" {
+			m.setError("save layout: " + msg.err)
+		}
+		return m, nil
+
+	case pushGuardMsg:
+		if msg.err != "" {
+			m.setError(fmt.Sprintf("push guard %s: %s", msg.agentID, msg.err))
+			return m, nil
+		}
+		text := fmt.Sprintf("Agent %s can no longer push", msg.agentID)
+		if msg.allow {
+			text = fmt.Sprintf("Agent %s may push its branch", msg.agentID)
+		}
+		m.addNotification(notification{
+			text:  text,
+			time:  time.Now(),
+			style: m.styles.Reviewed,
+			agent: msg.agentID,
+		})
 		return m, nil
 
 	case orchestrator.AgentWaitingMsg:
@@ -309,6 +750,7 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			text:  text,
 			time:  time.Now(),
 			style: style,
+			agent: msg.AgentID,
 		})
 		return m, nil
 
@@ -331,6 +773,19 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			}
 		case "s":
 			m.sortBy = (m.sortBy + 1) % 3
+		case "T":
+			m.absoluteTimes = !m.absoluteTimes
+		case " ":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				id := agents[m.cursor].ID
+				if m.expanded[id] {
+					delete(m.expanded, id)
+				} else {
+					m.expanded[id] = true
+				}
+			}
+		case "<", ">", "-", "+":
+			return m, tea.Batch(clearCmd, m.resize(msg.String()))
 		case "enter":
 			if len(agents) > 0 && m.cursor < len(agents) {
 				a := agents[m.cursor]
@@ -338,24 +793,24 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 				switch status {
 				case agent.StatusReviewReady:
 					if err := m.orch.OpenLazyGit(a.ID); err != nil {
-						m.err = err.Error()
+						m.setError(err.Error())
 					} else {
 						m.store.UpdateStatus(a.ID, agent.StatusReviewing)
 					}
 				case agent.StatusReviewed:
 					if err := m.orch.OpenLazyGit(a.ID); err != nil {
-						m.err = err.Error()
+						m.setError(err.Error())
 					} else {
 						m.store.UpdateStatus(a.ID, agent.StatusReviewing)
 					}
 				case agent.StatusConflicts:
 					if err := m.orch.OpenLazyGit(a.ID); err != nil {
-						m.err = err.Error()
+						m.setError(err.Error())
 					}
 					// Status stays StatusConflicts
 				case agent.StatusRunning, agent.StatusWaiting, agent.StatusReviewing, agent.StatusDone, agent.StatusPreviewing:
 					if err := m.orch.FocusAgent(a.ID); err != nil {
-						m.err = err.Error()
+						m.setError(err.Error())
 					}
 				}
 			}
@@ -363,18 +818,72 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			if len(agents) > 0 && m.cursor < len(agents) {
 				a := agents[m.cursor]
 				status := a.GetStatus()
-				if status == agent.StatusReviewed || status == agent.StatusReviewReady {
+				if (status == agent.StatusReviewed || status == agent.StatusReviewReady) && !a.ReadOnly {
 					name := a.ID
 					return m, tea.Batch(clearCmd, func() tea.Msg {
 						return startMergeMsg{
 							agentID:    a.ID,
 							agentName:  name,
 							branch:     a.Branch,
-							baseBranch: a.BaseBranch,
+							baseBranch: a.GetBaseBranch(),
 						}
 					})
 				}
 			}
+		case "M":
+			// Quick merge: skip the wizard and clean up as it would by
+			// default. Agents on an existing branch have nothing to merge.
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				status := a.GetStatus()
+				if (status == agent.StatusReviewed || status == agent.StatusReviewReady) && !a.ReadOnly && a.GetBaseBranch() != "" {
+					m.addNotification(notification{
+						text:  fmt.Sprintf("Merging agent %s into %s...", a.ID, a.GetBaseBranch()),
+						time:  time.Now(),
+						style: m.styles.Attention,
+						agent: a.ID,
+					})
+					orch := m.orch
+					return m, tea.Batch(clearCmd, func() tea.Msg {
+						d := orch.MergeDefaults()
+						return orch.MergeAgent(a.ID, d.DeleteBranch, d.RemoveWorktree, d.Push, d.Strategy, "")
+					})
+				}
+			}
+		case "u":
+			// Bring the latest base into a long-lived agent's branch
+			// before it drifts too far to merge.
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				if !a.ReadOnly && a.GetBaseBranch() != "" && orchestrator.Syncable(a.GetStatus()) {
+					m.addNotification(notification{
+						text:  fmt.Sprintf("Syncing agent %s with %s...", a.ID, a.GetBaseBranch()),
+						time:  time.Now(),
+						style: m.styles.Attention,
+						agent: a.ID,
+					})
+					orch := m.orch
+					return m, tea.Batch(clearCmd, func() tea.Msg {
+						return orch.SyncAgent(a.ID)
+					})
+				}
+			}
+		case "P":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				status := a.GetStatus()
+				if (status == agent.StatusReviewed || status == agent.StatusReviewReady) && !a.ReadOnly && !hasPullRequest(a) {
+					m.addNotification(notification{
+						text:  fmt.Sprintf("Opening pull request for agent %s...", a.ID),
+						time:  time.Now(),
+						style: m.styles.Attention,
+						agent: a.ID,
+					})
+					return m, tea.Batch(clearCmd, func() tea.Msg {
+						return m.orch.OpenPullRequest(a.ID)
+					})
+				}
+			}
 		case "d":
 			if len(agents) > 0 && m.cursor < len(agents) {
 				a := agents[m.cursor]
@@ -397,6 +906,11 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 			if len(agents) > 0 && m.cursor < len(agents) {
 				a := agents[m.cursor]
 				previewID := m.orch.GetPreviewAgentID()
+				if m.diffPreview && previewID != a.ID {
+					return m, tea.Batch(clearCmd, func() tea.Msg {
+						return startDiffMsg{agentID: a.ID, branch: a.Branch}
+					})
+				}
 				if previewID != "" && previewID == a.ID {
 					// Stop preview for this agent
 					return m, tea.Batch(clearCmd, func() tea.Msg {
@@ -406,7 +920,7 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 						return nil
 					})
 				} else if previewID != "" {
-					m.err = fmt.Sprintf("preview already active for agent %s — press p on that agent to stop it first", previewID)
+					m.setError(fmt.Sprintf("preview already active for agent %s — press p on that agent to stop it first", previewID))
 				} else {
 					return m, tea.Batch(clearCmd, func() tea.Msg {
 						if err := m.orch.PreviewAgent(a.ID); err != nil {
@@ -431,6 +945,10 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 		case "D":
 			if len(agents) > 0 && m.cursor < len(agents) {
 				a := agents[m.cursor]
+				if a.ReadOnly {
+					m.setError(fmt.Sprintf("agent %s is read-only: its branch is always kept, dismiss it with d", a.ID))
+					return m, clearCmd
+				}
 				name := a.ID
 				return m, tea.Batch(clearCmd, func() tea.Msg {
 					return startDismissMsg{
@@ -444,6 +962,14 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 		case "r":
 			if len(agents) > 0 && m.cursor < len(agents) {
 				a := agents[m.cursor]
+				if a.GetStatus() == agent.StatusPreviewing && m.orch.GetPreviewAgentID() == a.ID {
+					return m, tea.Batch(clearCmd, func() tea.Msg {
+						if err := m.orch.RefreshPreview(); err != nil {
+							return orchestrator.PreviewErrorMsg{AgentID: a.ID, Error: err.Error()}
+						}
+						return nil
+					})
+				}
 				if a.GetStatus() == agent.StatusOrphaned {
 					return m, tea.Batch(clearCmd, func() tea.Msg {
 						if err := m.orch.ResumeAgent(a.ID); err != nil {
@@ -453,6 +979,85 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 					})
 				}
 			}
+		case "!":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				return m, tea.Batch(clearCmd, func() tea.Msg {
+					return startCommandMsg{agentID: a.ID, branch: a.Branch, wtPath: a.WorktreePath}
+				})
+			}
+		case "S":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				return m, tea.Batch(clearCmd, func() tea.Msg {
+					return m.orch.CheckpointAgent(a.ID, "")
+				})
+			}
+		case "R":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				return m, tea.Batch(clearCmd, func() tea.Msg {
+					msg := startRollbackMsg{agentID: a.ID, branch: a.Branch}
+					cps, err := m.orch.Checkpoints(a.ID)
+					if err != nil {
+						msg.err = err.Error()
+					}
+					msg.checkpoints = cps
+					return msg
+				})
+			}
+		case "a":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				allow := !a.GetAllowPush()
+				return m, tea.Batch(clearCmd, func() tea.Msg {
+					if err := m.orch.SetAllowPush(a.ID, allow); err != nil {
+						return pushGuardMsg{agentID: a.ID, allow: allow, err: err.Error()}
+					}
+					return pushGuardMsg{agentID: a.ID, allow: allow}
+				})
+			}
+		case "i":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				if a.GetTeam() != nil {
+					return m, tea.Batch(clearCmd, func() tea.Msg {
+						return startTeamMsg{agentID: a.ID}
+					})
+				}
+			}
+		case "V":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				return m, tea.Batch(clearCmd, func() tea.Msg {
+					return startDiffMsg{agentID: a.ID, branch: a.Branch}
+				})
+			}
+		case "v":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				if path := a.GetReportPath(); path != "" {
+					return m, tea.Batch(clearCmd, func() tea.Msg {
+						return startReportMsg{agentID: a.ID, path: path}
+					})
+				}
+			}
+		case "y":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				return m, tea.Batch(clearCmd, func() tea.Msg {
+					return startCloneMsg{agentID: a.ID, branch: a.Branch, baseBranch: a.GetBaseBranch(), prompt: a.Prompt}
+				})
+			}
+		case "t":
+			if len(agents) > 0 && m.cursor < len(agents) {
+				a := agents[m.cursor]
+				if a.TmuxWindow == "" {
+					m.setError(fmt.Sprintf("agent %s has no tmux window — resume it first", a.ID))
+				} else if _, err := m.orch.ToggleShell(a.ID); err != nil {
+					m.setError(err.Error())
+				}
+			}
 		}
 
 		return m, clearCmd
@@ -461,7 +1066,71 @@ func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
 	return m, nil
 }
 
+// sortCache holds the sorted agents until the store's version or the sort
+// mode changes, so keystrokes and ticks don't re-sort every agent. Sorted
+// by duration, the agents are re-sorted on every tick too, as running
+// agents overtake the others. It is shared by the copies of the dashboard
+// model.
+type sortCache struct {
+	version uint64
+	sortBy  sortMode
+	tick    int
+	agents  []*agent.Agent
+}
+
+// sortedAgents returns the agents in display order: sorted by the sort
+// mode, with stacked agents below their parents.
 func (m dashboardModel) sortedAgents() []*agent.Agent {
+	key := sortCache{version: m.store.Version(), sortBy: m.sortBy}
+	if m.sortBy == sortByDuration {
+		key.tick = m.ticks
+	}
+	if c := m.sorted; c != nil && c.agents != nil && c.version == key.version && c.sortBy == key.sortBy && c.tick == key.tick {
+		return c.agents
+	}
+	key.agents = m.sortAgents()
+	if m.sorted != nil {
+		*m.sorted = key
+	}
+	return key.agents
+}
+
+// followSelection moves the cursor to the selected agent, wherever
+// sorting put it. If the agent is gone, the one now at the cursor's
+// position is selected instead.
+func (m *dashboardModel) followSelection() {
+	agents := m.sortedAgents()
+	for i, a := range agents {
+		if a.ID == m.selectedID {
+			m.cursor = i
+			return
+		}
+	}
+	m.selectCursor(agents)
+}
+
+// selectCursor selects the agent at the cursor, moving the cursor back
+// onto the list if it fell off its end.
+func (m *dashboardModel) selectCursor(agents []*agent.Agent) {
+	m.cursor = max(min(m.cursor, len(agents)-1), 0)
+	m.selectedID = ""
+	if m.cursor < len(agents) {
+		m.selectedID = agents[m.cursor].ID
+	}
+}
+
+// trackCursor updates the selection after a message was handled: a
+// cursor moved away from prev selects the agent it moved to, otherwise
+// the cursor follows the selected agent.
+func (m *dashboardModel) trackCursor(prev int) {
+	if m.cursor != prev {
+		m.selectCursor(m.sortedAgents())
+		return
+	}
+	m.followSelection()
+}
+
+func (m dashboardModel) sortAgents() []*agent.Agent {
 	agents := m.store.All()
 	switch m.sortBy {
 	case sortByStatus:
@@ -482,7 +1151,8 @@ func (m dashboardModel) sortedAgents() []*agent.Agent {
 			return agents[i].ID < agents[j].ID
 		})
 	}
-	return agents
+	stacked, _ := stackAgents(agents)
+	return stacked
 }
 
 func (m dashboardModel) sortLabel() string {
@@ -513,7 +1183,9 @@ func (m dashboardModel) ViewContent() string {
 	cw := m.contentWidth()
 
 	var chosenLogo string
-	if cw == m.cachedLogoWidth && m.cachedLogo != "" {
+	if m.screenReader {
+		chosenLogo = " MASTERMIND"
+	} else if cw == m.cachedLogoWidth && m.cachedLogo != "" {
 		chosenLogo = m.cachedLogo
 	} else {
 		chosenLogo = renderLogo(cw)
@@ -526,7 +1198,14 @@ func (m dashboardModel) ViewContent() string {
 	b.WriteString("\n\n")
 
 	// Title
-	title := m.styles.Title.Render(fmt.Sprintf("repo: %s — session: %s", m.repoPath, m.session))
+	titleText := fmt.Sprintf("repo: %s — session: %s", m.repoPath, m.session)
+	if m.instance != "" {
+		titleText = m.instance + " — " + titleText
+	}
+	if m.orch.DaemonClient() {
+		titleText += " — daemon"
+	}
+	title := m.styles.Title.Render(titleText)
 	b.WriteString(title)
 	b.WriteString("\n")
 
@@ -539,6 +1218,9 @@ func (m dashboardModel) ViewContent() string {
 			previewBranch = previewAgent.Branch
 		}
 		banner := fmt.Sprintf("  PREVIEW ACTIVE: %s (branch %s) — p to stop", previewName, previewBranch)
+		if m.orch.PreviewStale() {
+			banner = fmt.Sprintf("  PREVIEW STALE: %s (branch %s moved on) — r to refresh, p to stop", previewName, previewBranch)
+		}
 		b.WriteString(m.styles.PreviewBanner.Render(banner))
 		b.WriteString("\n")
 	}
@@ -550,8 +1232,8 @@ func (m dashboardModel) ViewContent() string {
 	type col struct {
 		min, weight int
 	}
-	cols := [8]col{
-		{3, 1},  // 0: ID
+	cols := [11]col{
+		{6, 1},  // 0: ID and harness badge
 		{8, 2},  // 1: Model
 		{10, 3}, // 2: Branch
 		{10, 2}, // 3: Status
@@ -559,22 +1241,43 @@ func (m dashboardModel) ViewContent() string {
 		{6, 1},  // 5: Cost
 		{4, 1},  // 6: Ctx%
 		{8, 2},  // 7: Lines
+		{7, 1},  // 8: Base (ahead/behind)
+		{7, 1},  // 9: Started
+		{7, 1},  // 10: Ready
 	}
 	const indent = 2
-	const gaps = 8  // 1-char gap between each of 8 cols + indicator
 	const indic = 2 // indicator width
+	gaps := 11      // 1-char gap between each of 11 cols + indicator
 	totalMin := indent + gaps + indic
 	totalWeight := 0
 	for _, c := range cols {
 		totalMin += c.min
 		totalWeight += c.weight
 	}
+	// The Started and Ready columns are the first to go when space is
+	// short, then Base.
+	dropCols := func(idx ...int) {
+		for _, i := range idx {
+			totalMin -= cols[i].min + 1
+			totalWeight -= cols[i].weight
+			cols[i] = col{}
+		}
+		gaps -= len(idx)
+	}
+	showTimes := cw >= totalMin
+	if !showTimes {
+		dropCols(9, 10)
+	}
+	showBase := cw >= totalMin
+	if !showBase {
+		dropCols(8)
+	}
 	extra := cw - totalMin
 	if extra < 0 {
 		extra = 0
 	}
 	// Compute actual widths
-	var colW [8]int
+	var colW [11]int
 	for i, c := range cols {
 		colW[i] = c.min + extra*c.weight/totalWeight
 	}
@@ -588,6 +1291,7 @@ func (m dashboardModel) ViewContent() string {
 	}
 
 	agents := m.sortedAgents()
+	_, depths := stackAgents(agents)
 	if len(agents) == 0 {
 		b.WriteString(m.styles.WizardDim.Render("  No agents running. Press n to spawn one."))
 		b.WriteString("\n")
@@ -596,9 +1300,16 @@ func (m dashboardModel) ViewContent() string {
 		header := fmt.Sprintf("  %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s",
 			colW[0], "ID", colW[1], "Model", colW[2], "Branch", colW[3], "Status",
 			colW[4], "Duration", colW[5], "Cost", colW[6], "Ctx%", colW[7], "Lines")
+		if showBase {
+			header += fmt.Sprintf(" %-*s", colW[8], "Base")
+		}
+		if showTimes {
+			header += fmt.Sprintf(" %-*s %-*s", colW[9], "Started", colW[10], "Ready")
+		}
 		b.WriteString(m.styles.Header.Render(header))
 		b.WriteString("\n")
 
+		now := time.Now()
 		for i, a := range agents {
 			status := a.GetStatus()
 			waitingFor := a.GetWaitingFor()
@@ -632,8 +1343,22 @@ func (m dashboardModel) ViewContent() string {
 			default:
 				styledStatus = string(status)
 			}
+			paneUnknown := a.GetPaneUnknown()
+			if paneUnknown {
+				styledStatus = m.styles.WizardDim.Render("unknown")
+			}
+			noSignal := m.noSignal(a, now)
+			if noSignal != "" {
+				styledStatus = m.styles.Attention.Render(truncate(noSignal, colW[3]))
+			}
 
 			dur := formatDuration(a.Duration())
+			var times string
+			if showTimes {
+				times = fmt.Sprintf(" %-*s %-*s",
+					colW[9], m.format.when(a.StartedAt, now, m.absoluteTimes),
+					colW[10], m.format.when(a.GetReadyAt(), now, m.absoluteTimes))
+			}
 
 			indicator := "  "
 			switch status {
@@ -667,16 +1392,33 @@ func (m dashboardModel) ViewContent() string {
 				if sd.Model != "" {
 					modelStr = sd.Model
 				}
-				costStr = fmt.Sprintf("$%.2f", sd.CostUSD)
+				costStr = m.format.cost(sd.CostUSD)
+				if total, ok := a.TeamCostUSD(); ok {
+					costStr = m.format.cost(total)
+				}
 				ctxPct = int(sd.ContextPct)
 				ctxPctStr = fmt.Sprintf("%d%%", ctxPct)
 				linesStr = fmt.Sprintf("+%d -%d", sd.LinesAdded, sd.LinesRemoved)
 			}
+			// Commits ahead of and behind base, as last counted by the
+			// monitor loop.
+			var baseStr string
+			if showBase {
+				counts := "-"
+				if ahead, behind, ok := a.GetAheadBehind(); ok {
+					counts = fmt.Sprintf("↑%d ↓%d", ahead, behind)
+				}
+				baseStr = fmt.Sprintf(" %-*s", colW[8], truncate(counts, colW[8]))
+			}
 
 			// Harness badge
 			harnessBadge := "[C]"
-			if a.Harness == "opencode" {
+			switch a.Harness {
+			case harness.TypeClaudeCode, "":
+			case harness.TypeOpenCode:
 				harnessBadge = "[O]"
+			default:
+				harnessBadge = "[" + strings.ToUpper(string(a.Harness[:1])) + "]"
 			}
 
 			isSelected := i == m.cursor
@@ -695,23 +1437,34 @@ func (m dashboardModel) ViewContent() string {
 						plainStatus = "waiting"
 					}
 				}
+				if paneUnknown {
+					plainStatus = "unknown"
+				}
+				if noSignal != "" {
+					plainStatus = truncate(noSignal, colW[3])
+				}
 
 				idWithBadge := fmt.Sprintf("%s %s", a.ID, harnessBadge)
-				row = fmt.Sprintf("  %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s  ",
+				row = fmt.Sprintf("  %-*s %-*s %s %-*s %-*s %-*s %-*s %-*s%s%s  ",
 					colW[0], idWithBadge,
 					colW[1], truncate(modelStr, colW[1]),
-					colW[2], truncate(a.Branch, colW[2]),
+					branchCell(branchLabel(a), depths[a.ID], colW[2]),
 					colW[3], plainStatus,
 					colW[4], dur,
 					colW[5], costStr,
 					colW[6], ctxPctStr,
 					colW[7], linesStr,
+					baseStr,
+					times,
 				)
 
 				// Pad to full content width using visual width for safety
 				if w := lipgloss.Width(row); w < cw {
 					row += strings.Repeat(" ", cw-w)
 				}
+				if m.screenReader {
+					row = ">" + row[1:]
+				}
 				row = m.styles.Selected.Render(row)
 			} else {
 				// Non-selected row: styled status, ctx%, and indicator.
@@ -731,15 +1484,17 @@ func (m dashboardModel) ViewContent() string {
 				}
 
 				idWithBadge := fmt.Sprintf("%s %s", a.ID, harnessBadge)
-				row = fmt.Sprintf("  %-*s %-*s %-*s %s %-*s %-*s %s %-*s %s",
+				row = fmt.Sprintf("  %-*s %-*s %s %s %-*s %-*s %s %-*s%s%s %s",
 					colW[0], idWithBadge,
 					colW[1], truncate(modelStr, colW[1]),
-					colW[2], truncate(a.Branch, colW[2]),
+					branchCell(branchLabel(a), depths[a.ID], colW[2]),
 					displayStatus,
 					colW[4], dur,
 					colW[5], costStr,
 					displayCtx,
 					colW[7], linesStr,
+					baseStr,
+					times,
 					indicator,
 				)
 
@@ -751,6 +1506,31 @@ func (m dashboardModel) ViewContent() string {
 			b.WriteString(row)
 			b.WriteString("\n")
 
+			if isSelected {
+				if line := statusSourceLine(a, now); line != "" {
+					b.WriteString(m.styles.WizardDim.Render(truncate(line, cw)))
+					b.WriteString("\n")
+				}
+				if line := m.teamCostLine(a); line != "" {
+					b.WriteString(m.styles.WizardDim.Render(truncate(line, cw)))
+					b.WriteString("\n")
+				}
+			}
+			if m.expanded[a.ID] {
+				for _, line := range m.expandedLines(a) {
+					b.WriteString(m.styles.WizardDim.Render(truncate(line, cw)))
+					b.WriteString("\n")
+				}
+			}
+
+			// Render the agent team's teammates below the agent row
+			if info := a.GetTeam(); info != nil {
+				for _, line := range renderTeamLines(m.styles, info, a, now, cw) {
+					b.WriteString(line)
+					b.WriteString("\n")
+				}
+			}
+
 			// Render todos below the agent row
 			if todos := a.GetTodos(); len(todos) > 0 {
 				for _, todo := range todos {
@@ -758,6 +1538,7 @@ func (m dashboardModel) ViewContent() string {
 					b.WriteString("\n")
 				}
 			}
+			b.WriteString(strings.Repeat("\n", m.rowSpacing))
 		}
 	}
 
@@ -768,7 +1549,7 @@ func (m dashboardModel) ViewContent() string {
 		b.WriteString("\n")
 		for i := len(m.notifications) - 1; i >= 0; i-- {
 			n := m.notifications[i]
-			ts := n.time.Format("15:04")
+			ts := m.format.clock(n.time)
 			line := fmt.Sprintf("  %s %s", ts, n.text)
 			b.WriteString(n.style.Render(line))
 			b.WriteString("\n")
@@ -778,7 +1559,8 @@ func (m dashboardModel) ViewContent() string {
 	// Error
 	if m.err != "" {
 		b.WriteString("\n")
-		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+		b.WriteString(m.styles.Error.Render("  Error: " + truncate(m.err, max(cw-20, 20))))
+		b.WriteString(m.styles.Help.Render(" (e: details)"))
 		b.WriteString("\n")
 	}
 
@@ -786,36 +1568,68 @@ func (m dashboardModel) ViewContent() string {
 	b.WriteString("\n")
 
 	var selectedStatus agent.Status
-	hasSelection := false
+	hasSelection, readOnly := false, false
 	if len(agents) > 0 && m.cursor < len(agents) {
 		hasSelection = true
 		selectedStatus = agents[m.cursor].GetStatus()
+		readOnly = agents[m.cursor].ReadOnly
 	}
 
 	canPreview := hasSelection && (selectedStatus == agent.StatusReviewReady ||
 		selectedStatus == agent.StatusReviewed ||
 		selectedStatus == agent.StatusReviewing ||
 		selectedStatus == agent.StatusPreviewing)
-	canMerge := hasSelection && (selectedStatus == agent.StatusReviewed ||
+	canMerge := hasSelection && !readOnly && (selectedStatus == agent.StatusReviewed ||
 		selectedStatus == agent.StatusReviewReady)
 	canResume := hasSelection && selectedStatus == agent.StatusOrphaned
+	canQuickMerge := canMerge && agents[m.cursor].GetBaseBranch() != ""
+	canOpenPR := canMerge && !hasPullRequest(agents[m.cursor])
+	canSync := hasSelection && !readOnly && agents[m.cursor].GetBaseBranch() != "" && orchestrator.Syncable(selectedStatus)
 
 	m.keys.Focus.SetEnabled(hasSelection)
 	m.keys.Preview.SetEnabled(canPreview)
 	m.keys.Merge.SetEnabled(canMerge)
+	m.keys.QuickMerge.SetEnabled(canQuickMerge)
+	m.keys.Sync.SetEnabled(canSync)
+	m.keys.OpenPR.SetEnabled(canOpenPR)
 	m.keys.Resume.SetEnabled(canResume)
+	m.keys.Refresh.SetEnabled(selectedStatus == agent.StatusPreviewing && m.orch.GetPreviewAgentID() == agents[m.cursor].ID)
+	m.keys.Clone.SetEnabled(hasSelection)
+	m.keys.Checkpoint.SetEnabled(hasSelection)
+	m.keys.Rollback.SetEnabled(hasSelection)
+	m.keys.AllowPush.SetEnabled(hasSelection && !readOnly)
+	m.keys.Diff.SetEnabled(hasSelection && agents[m.cursor].GetBaseBranch() != "")
+	m.keys.Report.SetEnabled(hasSelection && agents[m.cursor].GetReportPath() != "")
+	m.keys.Team.SetEnabled(hasSelection && agents[m.cursor].GetTeam() != nil)
+	if hasSelection && agents[m.cursor].GetAllowPush() {
+		m.keys.AllowPush.SetHelp("a:", "block push")
+	} else {
+		m.keys.AllowPush.SetHelp("a:", "allow push")
+	}
 	m.keys.Prune.SetEnabled(hasSelection)
 	m.keys.Dismiss.SetEnabled(hasSelection)
-	m.keys.DismissDel.SetEnabled(hasSelection)
+	m.keys.DismissDel.SetEnabled(hasSelection && !readOnly)
+	m.keys.Errors.SetEnabled(len(m.errors) > 0)
+	m.keys.Expand.SetEnabled(hasSelection)
+	if hasSelection && m.expanded[agents[m.cursor].ID] {
+		m.keys.Expand.SetHelp("space:", "collapse")
+	} else {
+		m.keys.Expand.SetHelp("space:", "expand")
+	}
 	m.keys.Sort.SetHelp("s:", fmt.Sprintf("sort (%s)", m.sortLabel()))
+	if m.absoluteTimes {
+		m.keys.Times.SetHelp("T:", "relative times")
+	} else {
+		m.keys.Times.SetHelp("T:", "clock times")
+	}
 
 	m.help.Width = cw - 2
 
 	var helpLine string
 	if cw < 80 {
 		m.keys.DismissDel.SetHelp("D:", "del")
-		line1 := m.help.ShortHelpView([]key.Binding{m.keys.New, m.keys.Focus, m.keys.Preview, m.keys.Merge, m.keys.Prune})
-		line2 := m.help.ShortHelpView([]key.Binding{m.keys.Dismiss, m.keys.DismissDel, m.keys.Sort, m.keys.Quit})
+		line1 := m.help.ShortHelpView([]key.Binding{m.keys.New, m.keys.Focus, m.keys.Preview, m.keys.Merge, m.keys.QuickMerge, m.keys.Sync, m.keys.OpenPR, m.keys.Shell, m.keys.Command, m.keys.Prune})
+		line2 := m.help.ShortHelpView([]key.Binding{m.keys.Clone, m.keys.Checkpoint, m.keys.Rollback, m.keys.AllowPush, m.keys.Diff, m.keys.Report, m.keys.Team, m.keys.Dismiss, m.keys.DismissDel, m.keys.ClearDone, m.keys.Sort, m.keys.Times, m.keys.Graph, m.keys.Maint, m.keys.Archive, m.keys.Errors, m.keys.Instances, m.keys.Resize, m.keys.Quit})
 		helpLine = "  " + line1 + "\n  " + line2
 	} else {
 		m.keys.DismissDel.SetHelp("D:", "dismiss+del")
@@ -837,17 +1651,6 @@ func (m dashboardModel) View() string {
 	return m.styles.Border.Width(maxWidth).Render(content)
 }
 
-func formatDuration(d time.Duration) string {
-	d = d.Round(time.Second)
-	m := int(d.Minutes())
-	s := int(d.Seconds()) % 60
-	sec := strconv.Itoa(s)
-	if s < 10 {
-		sec = "0" + sec
-	}
-	return strconv.Itoa(m) + "m " + sec + "s"
-}
-
 func renderTodoLine(styles Styles, todo hook.TodoItem, cw int) string {
 	var iconChar string
 	var style lipgloss.Style
@@ -878,6 +1681,209 @@ func renderTodoLine(styles Styles, todo hook.TodoItem, cw int) string {
 	return line
 }
 
+// teamCostLine breaks down the Cost column of an agent team lead by
+// session, e.g. "    cost: lead $1.20 · backend $0.80", or returns "".
+func (m dashboardModel) teamCostLine(a *agent.Agent) string {
+	costs := a.GetTeamCosts()
+	if len(costs) == 0 {
+		return ""
+	}
+	parts := make([]string, len(costs))
+	for i, c := range costs {
+		parts[i] = c.Name + " " + m.format.cost(c.CostUSD)
+	}
+	return "    cost: " + strings.Join(parts, " · ")
+}
+
+// renderTeamLines renders an agent team led by lead: a summary of its
+// tasks and a sub-row per teammate with the task it is working on.
+func renderTeamLines(styles Styles, info *team.TeamInfo, lead *agent.Agent, now time.Time, cw int) []string {
+	summary := fmt.Sprintf("      team %s · %d/%d tasks done", info.TeamName, info.CompletedTasks, info.TotalTasks)
+	lines := []string{styles.WizardDim.Render(truncate(summary, cw))}
+	for _, member := range info.Members {
+		if member.AgentType == "lead" {
+			continue
+		}
+		line := renderTeammateLine(styles, member.Name, info.Tasks, cw)
+		if act, ok := lead.GetTeammateAction(member.Name); ok {
+			line += styles.WizardDim.Render(fmt.Sprintf(" (%s %s)", act.Action, formatAgo(now.Sub(act.At))))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// renderTeammateLine renders a teammate with its task in progress, else
+// its next pending task, else how many of its tasks are done.
+func renderTeammateLine(styles Styles, name string, tasks []team.Task, cw int) string {
+	var current, next *team.Task
+	done := 0
+	for i, t := range tasks {
+		if t.Owner != name {
+			continue
+		}
+		switch t.Status {
+		case team.TaskInProgress:
+			if current == nil {
+				current = &tasks[i]
+			}
+		case team.TaskCompleted:
+			done++
+		default:
+			if next == nil {
+				next = &tasks[i]
+			}
+		}
+	}
+
+	iconChar, style, text := "\u25a1", styles.WizardDim, "idle" // □
+	switch {
+	case current != nil:
+		iconChar, style, text = "\u25a0", styles.Running, current.Subject // ■
+	case next != nil:
+		text = "next: " + next.Subject
+	case done > 0:
+		iconChar, style, text = "\u2713", styles.Done, fmt.Sprintf("%d done", done) // ✓
+	}
+
+	prefix := fmt.Sprintf("      \u21b3 %s ", name) // ↳
+	if remaining := cw - lipgloss.Width(prefix) - 2; remaining > 3 {
+		text = truncate(text, remaining)
+	}
+	return prefix + style.Render(iconChar) + " " + style.Render(text)
+}
+
+// noSignal returns e.g. "no signal 7m" for an agent that was working
+// but has sent no hook events for longer than noSignalAfter, or "".
+func (m dashboardModel) noSignal(a *agent.Agent, now time.Time) string {
+	if m.noSignalAfter <= 0 {
+		return ""
+	}
+	d := a.SilentFor(now)
+	if d < m.noSignalAfter {
+		return ""
+	}
+	return "no signal " + strings.TrimSuffix(formatAgo(d), " ago")
+}
+
+// statusSourceLine tells where the selected agent's status comes from
+// and how fresh its hook status is, e.g. "status: pane polling, hook 2m
+// ago (stale)", to help debug a status that looks wrong. It is empty for
+// agents the monitor has no reading for.
+func statusSourceLine(a *agent.Agent, now time.Time) string {
+	source := a.GetStatusSource()
+	sf := a.GetHookStatus()
+	var hookAge string
+	if sf != nil {
+		hookAge = "hook " + formatAge(now.Sub(time.Unix(sf.Timestamp, 0)))
+		var details []string
+		for _, d := range []string{sf.Event, sf.Tool} {
+			if d != "" {
+				details = append(details, d)
+			}
+		}
+		if sf.Error {
+			details = append(details, "failed")
+		}
+		if sf.IsStale() {
+			details = append(details, "stale")
+		}
+		if len(details) > 0 {
+			hookAge += " (" + strings.Join(details, ", ") + ")"
+		}
+	}
+
+	var line string
+	switch source {
+	case "":
+		if sf == nil {
+			return ""
+		}
+		line = "no status reading, " + hookAge
+	case monitor.ProviderHook:
+		line = hookAge
+	case monitor.ProviderStream:
+		line = "stream-json relay"
+	case monitor.ProviderPane:
+		line = "pane polling"
+		if sf != nil {
+			line += ", " + hookAge
+		} else {
+			line += ", no hook status"
+		}
+	default:
+		line = source
+	}
+	return "    status: " + line
+}
+
+// expandedLines are the details shown below an expanded agent row: its
+// base branch, why it is waiting along with the pane lines it was waiting
+// at, its latest notification and its pull request.
+func (m dashboardModel) expandedLines(a *agent.Agent) []string {
+	base := a.GetBaseBranch()
+	if base == "" {
+		base = "-"
+	}
+	lines := []string{"    base: " + base}
+	if a.GetStatus() == agent.StatusWaiting {
+		lines = append(lines, "    waiting: "+statusText(a))
+		// The bottom of the pane when the agent started waiting shows why
+		for _, line := range a.GetAttentionReason() {
+			lines = append(lines, "      │ "+line)
+		}
+	}
+	for i := len(m.notifications) - 1; i >= 0; i-- {
+		if n := m.notifications[i]; n.agent == a.ID {
+			lines = append(lines, "    last: "+m.format.clock(n.time)+" "+n.text)
+			break
+		}
+	}
+	if pr := a.GetPullRequest(); pr != nil {
+		lines = append(lines, fmt.Sprintf("    PR: #%d %s (%s)", pr.Number, pr.URL, pr.State))
+	}
+	return lines
+}
+
+// formatAge shows how long ago something happened, in seconds under a
+// minute.
+func formatAge(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds ago", max(int(d.Seconds()), 0))
+	}
+	return formatAgo(d)
+}
+
+// branchLabel is an agent's branch as shown in the table, prefixed with
+// its ticket unless the branch name already contains it.
+func branchLabel(a *agent.Agent) string {
+	label := a.Branch
+	if a.Ticket != "" && !strings.Contains(strings.ToUpper(a.Branch), a.Ticket) {
+		label = a.Ticket + " " + label
+	}
+	if pr := a.GetPullRequest(); pr != nil {
+		switch pr.State {
+		case agent.PROpen:
+			label += fmt.Sprintf(" #%d", pr.Number)
+		case agent.PRMerged:
+			label += fmt.Sprintf(" #%d merged", pr.Number)
+		}
+	}
+	if a.ReadOnly {
+		label += " [ro]"
+	} else if a.GetAllowPush() {
+		label += " ⇡"
+	}
+	return label
+}
+
+// hasPullRequest reports whether a pull request from the agent's branch is
+// open or merged.
+func hasPullRequest(a *agent.Agent) bool {
+	pr := a.GetPullRequest()
+	return pr != nil && pr.State != agent.PRClosed
+}
+
 func truncate(s string, max int) string {
 	if lipgloss.Width(s) <= max {
 		return s
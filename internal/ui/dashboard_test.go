@@ -2,6 +2,7 @@ package ui
 
 import (
 	"context"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -12,27 +13,11 @@ import (
 	"github.com/simonbystrom/mastermind/internal/agent"
 	"github.com/simonbystrom/mastermind/internal/config"
 	"github.com/simonbystrom/mastermind/internal/hook"
+	"github.com/simonbystrom/mastermind/internal/monitor"
 	"github.com/simonbystrom/mastermind/internal/orchestrator"
+	"github.com/simonbystrom/mastermind/internal/team"
 )
 
-func TestFormatDuration(t *testing.T) {
-	tests := []struct {
-		d    time.Duration
-		want string
-	}{
-		{0, "0m 00s"},
-		{65 * time.Second, "1m 05s"},
-		{3661 * time.Second, "61m 01s"},
-		{30 * time.Second, "0m 30s"},
-	}
-	for _, tt := range tests {
-		got := formatDuration(tt.d)
-		if got != tt.want {
-			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
-		}
-	}
-}
-
 func TestTruncate(t *testing.T) {
 	tests := []struct {
 		s    string
@@ -58,7 +43,7 @@ func newTestDashboard(t *testing.T) (dashboardModel, *agent.Store) {
 	store := agent.NewStore()
 	cfg := config.Default()
 	orch := orchestrator.New(context.Background(), store, "/repo", "test", t.TempDir())
-	d := newDashboard(NewStyles(cfg.Colors), cfg.Layout, orch, store, "/repo", "test")
+	d := newDashboard(NewStyles(cfg.Colors), cfg.Layout, newFormatter(cfg.Format), 5*time.Minute, orch, store, "/repo", "test")
 	d.width = 120
 	d.height = 40
 	return d, store
@@ -128,6 +113,33 @@ func TestSortedAgents_ByDuration(t *testing.T) {
 	}
 }
 
+func TestSortedAgents_StackedFollowParent(t *testing.T) {
+	d, store := newTestDashboard(t)
+	d.sortBy = sortByID
+
+	parent := agent.NewAgent("feat/a", "main", "/wt1", "@1", "%1", "claude")
+	parent.ID = "a1"
+	other := agent.NewAgent("feat/b", "main", "/wt2", "@2", "%2", "claude")
+	other.ID = "a2"
+	child := agent.NewAgent("feat/c", "feat/a", "/wt3", "@3", "%3", "claude")
+	child.ID = "a3"
+	store.Add(parent)
+	store.Add(other)
+	store.Add(child)
+
+	sorted := d.sortedAgents()
+	var ids []string
+	for _, a := range sorted {
+		ids = append(ids, a.ID)
+	}
+	if strings.Join(ids, ",") != "a1,a3,a2" {
+		t.Errorf("order = %v, want child after its parent", ids)
+	}
+	if !strings.Contains(d.ViewContent(), "└ feat/c") {
+		t.Error("stacked agent should be drawn under its parent")
+	}
+}
+
 func TestDashboard_ViewContent_NoAgents(t *testing.T) {
 	d, _ := newTestDashboard(t)
 
@@ -137,6 +149,19 @@ func TestDashboard_ViewContent_NoAgents(t *testing.T) {
 	}
 }
 
+func TestDashboard_ViewContent_AheadBehind(t *testing.T) {
+	d, store := newTestDashboard(t)
+
+	a := agent.NewAgent("feat/build", "main", "/wt", "@1", "%1", "claude")
+	a.SetAheadBehind(3, 12)
+	store.Add(a)
+
+	content := d.ViewContent()
+	if !strings.Contains(content, "Base") || !strings.Contains(content, "↑3 ↓12") {
+		t.Errorf("dashboard should show the commits ahead of and behind base:\n%s", content)
+	}
+}
+
 func TestDashboard_ViewContent_WithAgents(t *testing.T) {
 	d, store := newTestDashboard(t)
 
@@ -209,6 +234,122 @@ func TestDashboard_SortCycle(t *testing.T) {
 	}
 }
 
+func TestDashboard_TimesToggle(t *testing.T) {
+	d, store := newTestDashboard(t)
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	a.ID = "a1"
+	a.StartedAt = time.Now().Add(-2 * time.Hour)
+	a.SetStatus(agent.StatusReviewReady)
+	store.Add(a)
+
+	view := d.ViewContent()
+	if !strings.Contains(view, "2h ago") {
+		t.Errorf("relative times missing:\n%s", view)
+	}
+
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	view = d.ViewContent()
+	started := d.format.clock(a.StartedAt)
+	if a.StartedAt.Day() != time.Now().Day() {
+		started = a.StartedAt.Format("Jan 2")
+	}
+	if strings.Contains(view, "2h ago") || !strings.Contains(view, started) {
+		t.Errorf("clock times missing after T:\n%s", view)
+	}
+}
+
+func TestDashboard_NoSignal(t *testing.T) {
+	d, store := newTestDashboard(t)
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	a.ID = "a1"
+	a.SetHookStatus(&hook.StatusFile{Status: hook.StatusRunning})
+	a.SetHeartbeat(time.Now().Add(-2 * time.Minute))
+	store.Add(a)
+
+	if view := d.ViewContent(); strings.Contains(view, "no signal") {
+		t.Errorf("no signal shown after 2m:\n%s", view)
+	}
+	a.SetHeartbeat(time.Now().Add(-7 * time.Minute))
+	if view := d.ViewContent(); !strings.Contains(view, "no signal 7m") {
+		t.Errorf("no signal missing after 7m:\n%s", view)
+	}
+}
+
+func TestDashboard_TeamCost(t *testing.T) {
+	d, store := newTestDashboard(t)
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	a.ID = "a1"
+	a.SetStatuslineData(&agent.StatuslineData{CostUSD: 1.5})
+	a.SetTeamCosts([]agent.SessionCost{{Name: "lead", CostUSD: 1.5}, {Name: "backend", CostUSD: 0.75}})
+	store.Add(a)
+
+	view := d.ViewContent()
+	if !strings.Contains(view, "$2.25") {
+		t.Errorf("Cost column should include the teammates:\n%s", view)
+	}
+	if !strings.Contains(view, "cost: lead $1.50 · backend $0.75") {
+		t.Errorf("selected team lead should show the cost breakdown:\n%s", view)
+	}
+}
+
+func TestStatusSourceLine(t *testing.T) {
+	now := time.Now()
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	if got := statusSourceLine(a, now); got != "" {
+		t.Errorf("unmonitored agent: got %q, want none", got)
+	}
+
+	a.SetStatusSource(monitor.ProviderHook)
+	a.SetHookStatus(&hook.StatusFile{Status: hook.StatusRunning, Timestamp: now.Add(-4 * time.Second).Unix(), Event: "PostToolUse", Tool: "Bash", Error: true})
+	if got, want := statusSourceLine(a, now), "    status: hook 4s ago (PostToolUse, Bash, failed)"; got != want {
+		t.Errorf("hook: got %q, want %q", got, want)
+	}
+
+	a.SetStatusSource(monitor.ProviderPane)
+	a.SetHookStatus(&hook.StatusFile{Status: hook.StatusIdle, Timestamp: now.Add(-2 * time.Minute).Unix(), Event: "Stop"})
+	if got, want := statusSourceLine(a, now), "    status: pane polling, hook 2m ago (Stop, stale)"; got != want {
+		t.Errorf("pane fallback: got %q, want %q", got, want)
+	}
+
+	a.SetHookStatus(nil)
+	if got, want := statusSourceLine(a, now), "    status: pane polling, no hook status"; got != want {
+		t.Errorf("pane without hook: got %q, want %q", got, want)
+	}
+}
+
+func TestRenderTeamLines(t *testing.T) {
+	styles := NewStyles(config.Default().Colors)
+	info := &team.TeamInfo{
+		TeamName:       "feat-login",
+		TotalTasks:     3,
+		CompletedTasks: 1,
+		Members: []team.Member{
+			{Name: "lead", AgentType: "lead"},
+			{Name: "backend", AgentType: "teammate"},
+			{Name: "frontend", AgentType: "teammate"},
+			{Name: "tester", AgentType: "teammate"},
+		},
+		Tasks: []team.Task{
+			{ID: "1", Subject: "Add token endpoint", Status: team.TaskCompleted, Owner: "backend"},
+			{ID: "2", Subject: "Add login form", Status: team.TaskInProgress, Owner: "frontend"},
+			{ID: "3", Subject: "Write e2e test", Status: team.TaskPending, Owner: "tester"},
+		},
+	}
+
+	lead := agent.NewAgent("feat/login", "main", "/wt", "@1", "%1", "claude")
+	lead.SetTeammateAction("backend", agent.TeammateAction{Action: agent.TeammateRestarted, At: time.Now()})
+	lines := renderTeamLines(styles, info, lead, time.Now(), 80)
+	want := []string{"team feat-login · 1/3 tasks done", "backend \u2713 1 done (restarted now)", "frontend \u25a0 Add login form", "tester \u25a1 next: Write e2e test"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d (no lead sub-row): %q", len(lines), len(want), lines)
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("line %d = %q, want it to contain %q", i, lines[i], w)
+		}
+	}
+}
+
 func TestRenderTodoLine(t *testing.T) {
 	styles := NewStyles(config.Default().Colors)
 
@@ -275,3 +416,311 @@ func TestDashboard_Notifications(t *testing.T) {
 		t.Errorf("notification text = %q, expected 'finished'", d.notifications[0].text)
 	}
 }
+
+func TestDashboard_PushGuardMsg(t *testing.T) {
+	d, _ := newTestDashboard(t)
+
+	d, _ = d.Update(pushGuardMsg{agentID: "a1", allow: true})
+	if len(d.notifications) != 1 || !strings.Contains(d.notifications[0].text, "may push") {
+		t.Errorf("notifications = %+v", d.notifications)
+	}
+
+	d, _ = d.Update(pushGuardMsg{agentID: "a1", err: "the push guard is disabled"})
+	if !strings.Contains(d.err, "push guard a1") {
+		t.Errorf("err = %q", d.err)
+	}
+}
+
+func TestDashboard_CheckpointMsg(t *testing.T) {
+	d, _ := newTestDashboard(t)
+
+	d, _ = d.Update(orchestrator.CheckpointMsg{AgentID: "a1", Tag: "checkpoint/feat/x/20261016-120000"})
+	if len(d.notifications) != 1 || !strings.Contains(d.notifications[0].text, "checkpoint/feat/x/20261016-120000") {
+		t.Errorf("notifications = %+v, want the checkpoint tag", d.notifications)
+	}
+
+	d, _ = d.Update(orchestrator.CheckpointMsg{AgentID: "a1", Error: "commit-tree failed"})
+	if !strings.Contains(d.err, "commit-tree failed") {
+		t.Errorf("err = %q", d.err)
+	}
+}
+
+func TestDashboard_PreviewStoppedMsg_Stash(t *testing.T) {
+	d, _ := newTestDashboard(t)
+
+	d, _ = d.Update(orchestrator.PreviewStoppedMsg{AgentID: "a1", Stash: "abc1234", Stashed: []string{"notes.txt"}})
+	if n := d.notifications; len(n) != 2 || !strings.Contains(n[1].text, "stashed as abc1234: notes.txt") {
+		t.Errorf("notifications = %+v, want the stash reported", n)
+	}
+}
+
+func TestDashboard_MergeResultMsg_Synced(t *testing.T) {
+	d, _ := newTestDashboard(t)
+
+	d, _ = d.Update(orchestrator.MergeResultMsg{AgentID: "a1", Success: true, Synced: true})
+	if n := d.notifications; len(n) != 1 || !strings.Contains(n[0].text, "synced with its base") {
+		t.Errorf("notifications = %+v, want the sync reported", n)
+	}
+	d, _ = d.Update(orchestrator.MergeResultMsg{AgentID: "a1", Error: "boom", Synced: true})
+	if !strings.Contains(d.err, "sync a1: boom") {
+		t.Errorf("err = %q, want the failed sync", d.err)
+	}
+	if n := d.notifications; len(n) != 2 || n[1].text != "Agent a1 sync failed: boom" {
+		t.Errorf("notifications = %+v, want the failure without the error's prefix", n)
+	}
+}
+
+func TestDashboard_MergeResultMsg_Failed(t *testing.T) {
+	d, _ := newTestDashboard(t)
+
+	d, _ = d.Update(orchestrator.MergeResultMsg{AgentID: "a1", Error: "base moved\nretry the merge"})
+	if d.err != "merge a1: base moved" {
+		t.Errorf("err = %q, want the failed merge", d.err)
+	}
+	if n := d.notifications; len(n) != 1 || n[0].text != "Agent a1 merge failed: base moved" {
+		t.Errorf("notifications = %+v, want the failure without the error's prefix", n)
+	}
+}
+
+func TestDashboard_ReportCollectedMsg(t *testing.T) {
+	d, _ := newTestDashboard(t)
+
+	d, _ = d.Update(orchestrator.ReportCollectedMsg{AgentID: "a1", Path: "/repo/.worktrees/reports/research-x.md"})
+	if len(d.notifications) != 1 || !strings.Contains(d.notifications[0].text, "research-x.md") {
+		t.Errorf("notifications = %+v, want the report path", d.notifications)
+	}
+}
+
+func TestDashboard_PlaybookFinishedMsg(t *testing.T) {
+	d, _ := newTestDashboard(t)
+
+	d, _ = d.Update(orchestrator.PlaybookFinishedMsg{AgentID: "a1", Branch: "playbook/deps", Checks: 2, Merged: true})
+	if len(d.notifications) != 1 || !strings.Contains(d.notifications[0].text, "merged playbook/deps") {
+		t.Errorf("notifications = %+v, want the merge", d.notifications)
+	}
+
+	d, _ = d.Update(orchestrator.PlaybookFinishedMsg{AgentID: "a1", Failed: "go test ./...", Output: "FAIL x"})
+	if !strings.Contains(d.err, "go test ./...") || len(d.errors) != 1 || !strings.Contains(d.errors[0].text, "FAIL x") {
+		t.Errorf("err = %q, errors = %+v; want the failing check and its output", d.err, d.errors)
+	}
+}
+
+func TestDashboard_QuickMerge(t *testing.T) {
+	d, store := newTestDashboard(t)
+	a := agent.NewAgent("feat/x", "main", "/wt1", "@1", "%1", "claude")
+	a.ID = "a1"
+	a.SetStatus(agent.StatusReviewReady)
+	store.Add(a)
+
+	d, cmd := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'M'}})
+	if cmd == nil {
+		t.Fatal("expected M to merge a review-ready agent")
+	}
+	if n := d.notifications; len(n) == 0 || !strings.Contains(n[len(n)-1].text, "Merging agent a1 into main") {
+		t.Errorf("notifications = %+v, want the merge announced", n)
+	}
+
+	// An agent on an existing branch has nothing to merge into.
+	a.SetBaseBranch("")
+	before := len(d.notifications)
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'M'}})
+	if len(d.notifications) != before {
+		t.Error("M should do nothing for an agent without a base branch")
+	}
+}
+
+func TestDashboard_Resize(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := config.Path()
+	if err := config.WriteDefault(path); err != nil {
+		t.Fatal(err)
+	}
+	d, _ := newTestDashboard(t)
+
+	press := func(k rune) tea.Cmd {
+		var cmd tea.Cmd
+		d, cmd = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{k}})
+		return cmd
+	}
+	press('>')
+	saved := press('-')
+	if d.layout.DashboardWidth != 60 || d.layout.LazygitSplit != 75 {
+		t.Errorf("layout = %+v, want width 60 and split 75", d.layout)
+	}
+	if got := d.orch.LazygitSplit(); got != 75 {
+		t.Errorf("orchestrator split = %d, want 75", got)
+	}
+
+	// The layout is saved with the last change.
+	for _, msg := range runCmds(saved) {
+		if msg, ok := msg.(layoutSavedMsg); ok && msg.err != "" {
+			t.Fatalf("save layout: %s", msg.err)
+		}
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Layout != d.layout {
+		t.Errorf("saved layout = %+v, want %+v", cfg.Layout, d.layout)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "dashboard_width = 60   # percentage") {
+		t.Errorf("config = %s, want the commented-out key set in place", data)
+	}
+
+	// Sizes stop at their bounds.
+	for i := 0; i < 20; i++ {
+		press('>')
+	}
+	if d.layout.DashboardWidth != maxDashboardWidth {
+		t.Errorf("width = %d, want %d", d.layout.DashboardWidth, maxDashboardWidth)
+	}
+	for _, msg := range runCmds(press('>')) {
+		if _, ok := msg.(layoutSavedMsg); ok {
+			t.Error("nothing should be saved at the bound")
+		}
+	}
+}
+
+// runCmds runs cmd, and the commands of a batch, returning their messages.
+func runCmds(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, runCmds(c)...)
+	}
+	return msgs
+}
+
+func TestDashboard_TickBacksOffWhileNothingChanges(t *testing.T) {
+	d, store := newTestDashboard(t)
+	a := agent.NewAgent("feat/x", "main", "/wt1", "@1", "%1", "claude")
+	a.ID = "a1"
+	store.Add(a)
+
+	now := time.Now()
+	want := []time.Duration{minTick, 2 * time.Second, 4 * time.Second, maxTick, maxTick}
+	for i, w := range want {
+		if got := d.nextTick(now.Add(time.Duration(i) * time.Second)); got != w {
+			t.Errorf("tick %d: interval = %v, want %v", i, got, w)
+		}
+	}
+
+	a.SetStatus(agent.StatusReviewReady)
+	if got := d.nextTick(now); got != minTick {
+		t.Errorf("interval after a status change = %v, want %v", got, minTick)
+	}
+}
+
+func TestDashboard_StoreChanged(t *testing.T) {
+	d, store := newTestDashboard(t)
+	a1 := agent.NewAgent("b1", "main", "/wt1", "@1", "%1", "claude")
+	a1.ID = "a1"
+	a2 := agent.NewAgent("b2", "main", "/wt2", "@2", "%2", "claude")
+	a2.ID = "a2"
+	store.Add(a1)
+	store.Add(a2)
+	changes := store.Subscribe(4)
+	d.cursor = 1
+	d.tickInterval = maxTick
+
+	store.Remove("a2")
+	d, _ = d.Update(orchestrator.StoreChangedMsg(<-changes))
+	if d.cursor != 0 {
+		t.Errorf("cursor = %d, want 0 after the selected agent was removed", d.cursor)
+	}
+	if d.tickInterval != minTick {
+		t.Errorf("tick interval = %v, want %v after a change", d.tickInterval, minTick)
+	}
+}
+
+func TestDashboard_CursorFollowsSelectedAgent(t *testing.T) {
+	d, store := newTestDashboard(t)
+	d.sortBy = sortByStatus
+	for _, id := range []string{"a1", "a2", "a3"} {
+		a := agent.NewAgent("feat/"+id, "main", "/wt-"+id, "@1", "%1", "claude")
+		a.ID = id
+		store.Add(a)
+	}
+
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if d.selectedID != "a2" {
+		t.Fatalf("selected = %q, want a2", d.selectedID)
+	}
+
+	// a3 becoming ready for review moves it to the top, pushing a2 down
+	// a row.
+	a3, _ := store.Get("a3")
+	a3.SetStatus(agent.StatusReviewReady)
+	d, _ = d.Update(tickMsg(time.Now()))
+	if agents := d.sortedAgents(); agents[d.cursor].ID != "a2" {
+		t.Errorf("cursor on %s, want it to stay on a2", agents[d.cursor].ID)
+	}
+
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	if d.selectedID != "a1" {
+		t.Errorf("selected = %q after moving up, want a1", d.selectedID)
+	}
+}
+
+func TestDashboard_ExpandRow(t *testing.T) {
+	d, store := newTestDashboard(t)
+	a := agent.NewAgent("feat/x", "develop", "/wt1", "@1", "%1", "claude")
+	a.ID = "a1"
+	a.SetStatus(agent.StatusWaiting)
+	a.SetWaitingFor("permission")
+	a.SetAttentionReason([]string{"Bash(rm -rf build)", "Do you want to proceed?"})
+	a.SetPullRequest(&agent.PullRequest{Number: 7, URL: "https://example.com/pr/7", State: agent.PROpen})
+	store.Add(a)
+	d, _ = d.Update(orchestrator.AgentWaitingMsg{AgentID: "a1", WaitingFor: "permission"})
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	d, _ = d.Update(space)
+	content := d.ViewContent()
+	for _, want := range []string{"base: develop", "waiting: needs permission", "│ Bash(rm -rf build)", "│ Do you want to proceed?", "last: ", "PR: #7 https://example.com/pr/7 (open)"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expanded row missing %q:\n%s", want, content)
+		}
+	}
+
+	d, _ = d.Update(space)
+	if strings.Contains(d.ViewContent(), "base: develop") {
+		t.Error("row should collapse on a second space")
+	}
+}
+
+func TestSortedAgents_CachedUntilStoreChanges(t *testing.T) {
+	d, store := newTestDashboard(t)
+	d.sortBy = sortByStatus
+
+	a1 := agent.NewAgent("b1", "main", "/wt1", "@1", "%1", "claude")
+	a1.ID = "a1"
+	a2 := agent.NewAgent("b2", "main", "/wt2", "@2", "%2", "claude")
+	a2.ID = "a2"
+	store.Add(a1)
+	store.Add(a2)
+
+	first := d.sortedAgents()
+	if again := d.sortedAgents(); &again[0] != &first[0] {
+		t.Error("expected the sorted agents to be reused while nothing changes")
+	}
+
+	// A status change can reorder the list.
+	a2.SetStatus(agent.StatusReviewReady)
+	if got := d.sortedAgents(); got[0].ID != "a2" {
+		t.Errorf("first agent = %s, want a2 once it is review ready", got[0].ID)
+	}
+
+	d.sortBy = sortByID
+	if got := d.sortedAgents(); got[0].ID != "a1" {
+		t.Errorf("first agent = %s, want a1 sorted by ID", got[0].ID)
+	}
+}
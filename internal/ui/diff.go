@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+// diffPageSize is how many diff lines are shown at once.
+const diffPageSize = 25
+
+// startDiffMsg opens the diff-only preview of an agent.
+type startDiffMsg struct {
+	agentID string
+	branch  string
+}
+
+type diffLoadedMsg struct {
+	agentID string
+	diff    string
+	err     string
+}
+
+type diffCloseMsg struct{}
+
+// diffModel shows an agent's changes against its base, committed and
+// not, read from its worktree. Unlike the checkout preview it leaves the
+// main worktree alone.
+type diffModel struct {
+	orch    *orchestrator.Orchestrator
+	agentID string
+	branch  string
+	styles  Styles
+	width   int
+
+	loading bool
+	lines   []string
+	files   []int // line index of each file's "diff --git" header
+	offset  int
+	err     string
+}
+
+func newDiff(s Styles, orch *orchestrator.Orchestrator, msg startDiffMsg, width int) diffModel {
+	return diffModel{
+		orch:    orch,
+		agentID: msg.agentID,
+		branch:  msg.branch,
+		styles:  s,
+		width:   width,
+		loading: true,
+	}
+}
+
+// Init loads the diff in the background, since it runs git.
+func (m diffModel) Init() tea.Cmd {
+	orch, id := m.orch, m.agentID
+	return func() tea.Msg {
+		diff, err := orch.DiffAgent(id)
+		if err != nil {
+			return diffLoadedMsg{agentID: id, err: err.Error()}
+		}
+		return diffLoadedMsg{agentID: id, diff: diff}
+	}
+}
+
+func (m diffModel) Update(msg tea.Msg) (diffModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case diffLoadedMsg:
+		if msg.agentID != m.agentID {
+			return m, nil
+		}
+		m.loading = false
+		m.err = msg.err
+		m.lines, m.files = nil, nil
+		if diff := strings.TrimRight(msg.diff, "\n"); diff != "" {
+			m.lines = strings.Split(diff, "\n")
+		}
+		for i, line := range m.lines {
+			if strings.HasPrefix(line, "diff --git ") {
+				m.files = append(m.files, i)
+			}
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		last := max(len(m.lines)-diffPageSize, 0)
+		switch msg.String() {
+		case "esc", "q", "V":
+			return m, func() tea.Msg { return diffCloseMsg{} }
+		case "down", "j":
+			m.offset = min(m.offset+1, last)
+		case "up", "k":
+			m.offset = max(m.offset-1, 0)
+		case "pgdown", " ":
+			m.offset = min(m.offset+diffPageSize, last)
+		case "pgup":
+			m.offset = max(m.offset-diffPageSize, 0)
+		case "n":
+			for _, f := range m.files {
+				if f > m.offset {
+					m.offset = min(f, last)
+					break
+				}
+			}
+		case "N":
+			for i := len(m.files) - 1; i >= 0; i-- {
+				if m.files[i] < m.offset {
+					m.offset = m.files[i]
+					break
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m diffModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Diff of " + m.branch))
+	b.WriteString("\n")
+	b.WriteString(m.styles.WizardDim.Render("  Commits and uncommitted changes since the branch forked"))
+	b.WriteString("\n\n")
+
+	switch {
+	case m.loading:
+		b.WriteString(m.styles.WizardDim.Render("  Loading…"))
+		b.WriteString("\n")
+	case m.err != "":
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+		b.WriteString("\n")
+	case len(m.lines) == 0:
+		b.WriteString(m.styles.WizardDim.Render("  No changes"))
+		b.WriteString("\n")
+	default:
+		textWidth := max(m.width/2-8, 30)
+		end := min(m.offset+diffPageSize, len(m.lines))
+		for _, line := range m.lines[m.offset:end] {
+			b.WriteString("  " + m.diffLine(truncate(strings.ReplaceAll(line, "\t", "    "), textWidth)))
+			b.WriteString("\n")
+		}
+		if len(m.lines) > diffPageSize {
+			b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("  lines %d–%d of %d, %d files", m.offset+1, end, len(m.lines), len(m.files))))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.Help.Render("  j/k: scroll │ space/pgup: page │ n/N: next/prev file │ esc: close"))
+	return b.String()
+}
+
+// diffLine colors a line of a unified diff.
+func (m diffModel) diffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "diff --git "), strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+		return m.styles.Header.Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return m.styles.WizardDim.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return m.styles.Reviewed.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return m.styles.Conflicts.Render(line)
+	}
+	return line
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+func TestDiff_ScrollAndJumpFiles(t *testing.T) {
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir())
+	m := newDiff(NewStyles(config.Default().Colors), orch, startDiffMsg{agentID: "a1", branch: "feat/x"}, 160)
+	if content := m.ViewContent(); !strings.Contains(content, "Loading") {
+		t.Errorf("view should show loading:\n%s", content)
+	}
+
+	var diff []string
+	for _, file := range []string{"a.go", "b.go"} {
+		diff = append(diff, "diff --git a/"+file+" b/"+file, "--- a/"+file, "+++ b/"+file, "@@ -1,30 +1,30 @@")
+		for i := 0; i < 30; i++ {
+			diff = append(diff, "+line of "+file)
+		}
+	}
+	m, _ = m.Update(diffLoadedMsg{agentID: "other", diff: "diff --git a/z b/z\n"})
+	if !m.loading {
+		t.Fatal("a diff of another agent should be ignored")
+	}
+	m, _ = m.Update(diffLoadedMsg{agentID: "a1", diff: strings.Join(diff, "\n") + "\n"})
+	if len(m.lines) != len(diff) || len(m.files) != 2 {
+		t.Fatalf("lines = %d files = %v, want %d lines in 2 files", len(m.lines), m.files, len(diff))
+	}
+	if content := m.ViewContent(); !strings.Contains(content, "a.go") || !strings.Contains(content, "of 68, 2 files") {
+		t.Errorf("view should show the first file:\n%s", content)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.offset != 34 {
+		t.Errorf("n: offset = %d, want 34 (the second file)", m.offset)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	if m.offset != 0 {
+		t.Errorf("N: offset = %d, want 0", m.offset)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("esc should close the diff")
+	}
+	if _, ok := cmd().(diffCloseMsg); !ok {
+		t.Errorf("esc sent %T, want diffCloseMsg", cmd())
+	}
+}
+
+func TestDiff_Empty(t *testing.T) {
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir())
+	m := newDiff(NewStyles(config.Default().Colors), orch, startDiffMsg{agentID: "a1", branch: "feat/x"}, 160)
+	m, _ = m.Update(diffLoadedMsg{agentID: "a1"})
+	if content := m.ViewContent(); !strings.Contains(content, "No changes") {
+		t.Errorf("view should say there are no changes:\n%s", content)
+	}
+}
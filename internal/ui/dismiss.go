@@ -3,8 +3,10 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/simonbystrom/mastermind/internal/orchestrator"
@@ -15,16 +17,36 @@ type dismissModel struct {
 	err    string
 	width  int
 	styles Styles
+	format formatter
 
 	agentID      string
 	agentName    string
 	branch       string
 	deleteBranch bool
+	note         string
+	landed       bool
+	archive      bool // the branch is archived rather than deleted
 	dismissing   bool
 
+	// typing is the second step of deleting a branch with unmerged
+	// commits: the branch name must be typed into confirm.
+	typing  bool
+	confirm textinput.Model
+
+	// summary is what the agent has done, loaded when the view opens.
+	summary    *orchestrator.WorkSummary
+	summaryErr string
+
 	spinner spinner.Model
 }
 
+// dismissSummaryMsg carries the work summary of the agent being dismissed.
+type dismissSummaryMsg struct {
+	agentID string
+	summary orchestrator.WorkSummary
+	err     string
+}
+
 type dismissDoneMsg struct{}
 type dismissCancelMsg struct{}
 
@@ -33,24 +55,77 @@ type startDismissMsg struct {
 	agentName    string
 	branch       string
 	deleteBranch bool
+	note         string // why the dismissal is offered, shown above the summary
+	landed       bool   // the branch's work landed elsewhere, e.g. in a merged pull request
 }
 
-func newDismiss(s Styles, orch *orchestrator.Orchestrator, msg startDismissMsg) dismissModel {
+func newDismiss(s Styles, f formatter, orch *orchestrator.Orchestrator, msg startDismissMsg) dismissModel {
 	sp := spinner.New()
 	sp.Spinner = spinner.MiniDot
+	ti := textinput.New()
+	ti.Placeholder = msg.branch
 	return dismissModel{
 		orch:         orch,
 		agentID:      msg.agentID,
 		agentName:    msg.agentName,
 		branch:       msg.branch,
 		deleteBranch: msg.deleteBranch,
+		note:         msg.note,
+		landed:       msg.landed,
+		archive:      orch.ArchivesBranches(),
+		confirm:      ti,
 		styles:       s,
+		format:       f,
 		spinner:      sp,
 	}
 }
 
+// needsTypedConfirm reports whether the branch name must be typed to
+// confirm: the branch is deleted and has commits no other branch has, or
+// they could not be counted yet.
+func (m dismissModel) needsTypedConfirm() bool {
+	if !m.deleteBranch || m.landed || m.archive {
+		return false
+	}
+	return m.summary == nil || m.summaryErr != "" || m.summary.Unmerged > 0
+}
+
+// lostCommits describes the commits deleting the branch would lose, or
+// returns "" when there are none or they are not counted yet.
+func (m dismissModel) lostCommits() string {
+	if !m.deleteBranch || m.archive || m.summary == nil || m.summary.Unmerged == 0 {
+		return ""
+	}
+	commits := "commits"
+	if m.summary.Unmerged == 1 {
+		commits = "commit"
+	}
+	return fmt.Sprintf("%d %s on no other branch will be lost.", m.summary.Unmerged, commits)
+}
+
+// Init loads the summary of the agent's work in the background, since
+// it runs git.
+func (m dismissModel) Init() tea.Cmd {
+	orch, id := m.orch, m.agentID
+	return func() tea.Msg {
+		s, err := orch.WorkSummary(id)
+		msg := dismissSummaryMsg{agentID: id, summary: s}
+		if err != nil {
+			msg.err = err.Error()
+		}
+		return msg
+	}
+}
+
 func (m dismissModel) Update(msg tea.Msg) (dismissModel, tea.Cmd) {
 	switch msg := msg.(type) {
+	case dismissSummaryMsg:
+		if msg.agentID == m.agentID {
+			m.summary = &msg.summary
+			m.summaryErr = msg.err
+		}
+		return m, nil
+
 	case spinner.TickMsg:
 		if m.dismissing {
 			var cmd tea.Cmd
@@ -66,20 +141,34 @@ func (m dismissModel) Update(msg tea.Msg) (dismissModel, tea.Cmd) {
 
 		m.err = ""
 
+		if m.typing {
+			switch msg.String() {
+			case "esc":
+				m.typing = false
+				m.confirm.Reset()
+				m.confirm.Blur()
+				return m, nil
+			case "enter":
+				if strings.TrimSpace(m.confirm.Value()) != m.branch {
+					m.err = "the branch name does not match"
+					return m, nil
+				}
+				return m.dismiss()
+			}
+			var cmd tea.Cmd
+			m.confirm, cmd = m.confirm.Update(msg)
+			return m, cmd
+		}
+
 		switch msg.String() {
 		case "esc", "n":
 			return m, func() tea.Msg { return dismissCancelMsg{} }
 		case "y", "enter":
-			m.dismissing = true
-			id := m.agentID
-			del := m.deleteBranch
-			dismissCmd := func() tea.Msg {
-				if err := m.orch.DismissAgent(id, del); err != nil {
-					return dismissErrorMsg{err: err.Error()}
-				}
-				return dismissDoneMsg{}
+			if m.needsTypedConfirm() {
+				m.typing = true
+				return m, m.confirm.Focus()
 			}
-			return m, tea.Batch(m.spinner.Tick, dismissCmd)
+			return m.dismiss()
 		}
 
 	case dismissErrorMsg:
@@ -95,6 +184,20 @@ type dismissErrorMsg struct {
 	err string
 }
 
+// dismiss starts the dismissal.
+func (m dismissModel) dismiss() (dismissModel, tea.Cmd) {
+	m.dismissing = true
+	id := m.agentID
+	del := m.deleteBranch
+	dismissCmd := func() tea.Msg {
+		if err := m.orch.DismissAgent(id, del); err != nil {
+			return dismissErrorMsg{err: err.Error()}
+		}
+		return dismissDoneMsg{}
+	}
+	return m, tea.Batch(m.spinner.Tick, dismissCmd)
+}
+
 func (m dismissModel) ViewContent() string {
 	var b strings.Builder
 
@@ -107,29 +210,46 @@ func (m dismissModel) ViewContent() string {
 
 	b.WriteString(fmt.Sprintf("  Agent:       %s\n", m.agentName))
 	b.WriteString(fmt.Sprintf("  Branch:      %s\n", m.branch))
+	m.writeSummary(&b, time.Now())
 	b.WriteString("\n")
+	if m.note != "" {
+		b.WriteString("  " + m.note + "\n\n")
+	}
 
 	b.WriteString(m.styles.WizardActive.Render("  This will:"))
 	b.WriteString("\n")
 	b.WriteString("    - Stop the Claude process\n")
 	b.WriteString("    - Kill the tmux window\n")
 	b.WriteString("    - Remove the worktree\n")
-	if m.deleteBranch {
+	switch {
+	case m.deleteBranch && m.archive:
+		b.WriteString("    - Archive the branch (restore it with A)\n")
+	case m.deleteBranch:
 		b.WriteString("    - Delete the branch\n")
 	}
 
 	b.WriteString("\n")
-	if m.deleteBranch {
+	if m.deleteBranch && !m.archive {
 		b.WriteString(m.styles.Error.Render("  All changes (committed and uncommitted) will be lost."))
+		if lost := m.lostCommits(); lost != "" {
+			b.WriteString("\n")
+			b.WriteString(m.styles.Error.Render("  " + lost))
+		}
 	} else {
 		b.WriteString(m.styles.Error.Render("  Any uncommitted changes will be lost."))
 	}
 	b.WriteString("\n")
 
 	b.WriteString("\n")
-	if m.dismissing {
+	switch {
+	case m.dismissing:
 		b.WriteString(m.styles.WizardActive.Render("  " + m.spinner.View() + " Dismissing..."))
-	} else {
+	case m.typing:
+		b.WriteString(m.styles.Attention.Render("  The branch has commits that are not merged anywhere else."))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  Type %s to delete it: %s\n\n", m.branch, m.confirm.View()))
+		b.WriteString(m.styles.Help.Render("  enter: dismiss & delete | esc: back"))
+	default:
 		b.WriteString(m.styles.Help.Render("  y/enter: confirm | esc/n: cancel"))
 	}
 
@@ -141,6 +261,58 @@ func (m dismissModel) ViewContent() string {
 	return b.String()
 }
 
+// writeSummary lists what the agent has done, so that work isn't thrown
+// away by accident: its commits, changes, cost and last hook status.
+func (m dismissModel) writeSummary(b *strings.Builder, now time.Time) {
+	switch {
+	case m.summaryErr != "":
+		b.WriteString(m.styles.WizardDim.Render("  Summary:     " + m.summaryErr))
+		b.WriteString("\n")
+		return
+	case m.summary == nil:
+		b.WriteString(m.styles.WizardDim.Render("  Summary:     loading..."))
+		b.WriteString("\n")
+		return
+	}
+	s := m.summary
+
+	if s.CommitCount == 0 {
+		b.WriteString("  Commits:     none\n")
+	} else {
+		b.WriteString(fmt.Sprintf("  Commits:     %d\n", s.CommitCount))
+	}
+	for _, c := range s.Commits {
+		b.WriteString(m.styles.WizardDim.Render("                 " + truncate(c, 60)))
+		b.WriteString("\n")
+	}
+	if more := s.CommitCount - len(s.Commits); more > 0 && len(s.Commits) > 0 {
+		b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("                 ... and %d more", more)))
+		b.WriteString("\n")
+	}
+
+	changes := "none"
+	if s.Diff.Files > 0 {
+		changes = s.Diff.String()
+	}
+	if s.Uncommitted {
+		changes += " (includes uncommitted changes)"
+	}
+	b.WriteString(fmt.Sprintf("  Changes:     %s\n", changes))
+
+	if s.HasCost {
+		b.WriteString(fmt.Sprintf("  Cost:        %s\n", m.format.cost(s.CostUSD)))
+	}
+
+	if sf := s.HookStatus; sf != nil {
+		status := sf.Status
+		if sf.Event != "" {
+			status += " (" + sf.Event + ")"
+		}
+		status += ", " + formatAgo(now.Sub(time.Unix(sf.Timestamp, 0)))
+		b.WriteString(fmt.Sprintf("  Last status: %s\n", status))
+	}
+}
+
 func (m dismissModel) View() string {
 	return m.styles.Border.Render(m.ViewContent())
 }
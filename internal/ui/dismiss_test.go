@@ -4,11 +4,14 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/simonbystrom/mastermind/internal/agent"
 	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/hook"
 	"github.com/simonbystrom/mastermind/internal/orchestrator"
 )
 
@@ -16,7 +19,7 @@ func newTestDismiss(t *testing.T, deleteBranch bool) dismissModel {
 	t.Helper()
 	store := agent.NewStore()
 	orch := orchestrator.New(context.Background(), store, "/repo", "test", t.TempDir())
-	return newDismiss(NewStyles(config.Default().Colors), orch, startDismissMsg{
+	return newDismiss(NewStyles(config.Default().Colors), newFormatter(config.Default().Format), orch, startDismissMsg{
 		agentID:      "a1",
 		agentName:    "test-agent",
 		branch:       "feat/x",
@@ -96,3 +99,111 @@ func TestDismiss_ErrorMsg(t *testing.T) {
 		t.Error("should display error")
 	}
 }
+
+func TestDismiss_Summary(t *testing.T) {
+	m := newTestDismiss(t, true)
+	if content := m.ViewContent(); !strings.Contains(content, "loading") {
+		t.Error("should show the summary loading")
+	}
+
+	m, _ = m.Update(dismissSummaryMsg{agentID: "a1", summary: orchestrator.WorkSummary{
+		Commits:     []string{"abc1234 add login", "def5678 add logout"},
+		CommitCount: 7,
+		Diff:        git.DiffStat{Files: 3, Insertions: 10, Deletions: 2},
+		Uncommitted: true,
+		CostUSD:     1.5,
+		HasCost:     true,
+		HookStatus:  &hook.StatusFile{Status: "waiting", Event: "Stop", Timestamp: time.Now().Unix()},
+	}})
+	content := m.ViewContent()
+	for _, want := range []string{
+		"Commits:     7",
+		"abc1234 add login",
+		"and 5 more",
+		"3 files changed, +10 -2 (includes uncommitted changes)",
+		"$1.50",
+		"waiting (Stop), now",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("summary should contain %q:\n%s", want, content)
+		}
+	}
+
+	// A summary for another agent, e.g. from a dismissal that was
+	// cancelled, is ignored.
+	m, _ = m.Update(dismissSummaryMsg{agentID: "a2"})
+	if m.summary.CommitCount != 7 {
+		t.Error("summary of another agent should be ignored")
+	}
+}
+
+func TestDismiss_UnmergedCommitsNeedTypedBranch(t *testing.T) {
+	m := newTestDismiss(t, true)
+	m, _ = m.Update(dismissSummaryMsg{agentID: "a1", summary: orchestrator.WorkSummary{CommitCount: 3, Unmerged: 2}})
+	if content := m.ViewContent(); !strings.Contains(content, "2 commits on no other branch will be lost") {
+		t.Errorf("view should count the commits that would be lost:\n%s", content)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if m.dismissing || !m.typing {
+		t.Fatalf("dismissing = %v, typing = %v after y, want the branch name asked for", m.dismissing, m.typing)
+	}
+	if content := m.ViewContent(); !strings.Contains(content, "Type feat/x to delete it") {
+		t.Errorf("view should ask for the branch name:\n%s", content)
+	}
+
+	// n is typed, not taken as cancel.
+	for _, r := range "feat/n" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.dismissing || m.err == "" {
+		t.Fatalf("dismissing = %v, err = %q with a wrong branch name", m.dismissing, m.err)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.dismissing || cmd == nil {
+		t.Error("typing the branch name should dismiss")
+	}
+}
+
+func TestDismiss_MergedBranchNeedsNoTyping(t *testing.T) {
+	m := newTestDismiss(t, true)
+	// Commits its base lacks but another branch has are not lost.
+	m, _ = m.Update(dismissSummaryMsg{agentID: "a1", summary: orchestrator.WorkSummary{CommitCount: 3}})
+	if content := m.ViewContent(); strings.Contains(content, "on no other branch") {
+		t.Errorf("view should not warn about lost commits:\n%s", content)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if !m.dismissing || m.typing {
+		t.Errorf("dismissing = %v, typing = %v, want a branch without unmerged commits dismissed on y", m.dismissing, m.typing)
+	}
+}
+
+func TestClearDone_ToggleMerged(t *testing.T) {
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir())
+	m := newClearDone(NewStyles(config.Default().Colors), orch, 120)
+	m, _ = m.Update(clearDoneLoadedMsg{agents: []orchestrator.DoneAgent{
+		{AgentID: "1", Branch: "feat/done"},
+		{AgentID: "2", Branch: "feat/landed", Merged: true},
+	}})
+
+	if got := m.selected(); len(got) != 2 {
+		t.Errorf("selected = %v, want both agents", got)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if got := m.selected(); len(got) != 1 || got[0] != "1" {
+		t.Errorf("selected = %v, want only the done agent", got)
+	}
+	if view := m.ViewContent(); strings.Contains(view, "feat/landed") {
+		t.Errorf("view lists the merged agent after excluding it:\n%s", view)
+	}
+
+	m, cmd := m.Update(orchestrator.BulkDismissMsg{Dismissed: []string{"1"}})
+	if _, ok := cmd().(clearDoneCloseMsg); !ok {
+		t.Error("expected the view to close after dismissing")
+	}
+}
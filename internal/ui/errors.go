@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxErrors is how many recent errors the dashboard keeps.
+const maxErrors = 20
+
+// errorEntry is one error recorded by the dashboard. text may span several
+// lines, e.g. when it carries git's stderr.
+type errorEntry struct {
+	time time.Time
+	text string
+}
+
+// summary returns the first line of the error.
+func (e errorEntry) summary() string {
+	first, _, _ := strings.Cut(e.text, "\n")
+	return first
+}
+
+type errorsCloseMsg struct{}
+
+type errorsClearMsg struct{}
+
+// errorsModel lists recent errors, newest first. The selected entry can be
+// expanded to show its full text.
+type errorsModel struct {
+	entries  []errorEntry
+	cursor   int
+	expanded map[int]bool
+	styles   Styles
+	format   formatter
+	width    int
+}
+
+func newErrors(s Styles, f formatter, entries []errorEntry, width int) errorsModel {
+	// Newest first.
+	rev := make([]errorEntry, len(entries))
+	for i, e := range entries {
+		rev[len(entries)-1-i] = e
+	}
+	return errorsModel{
+		entries:  rev,
+		expanded: make(map[int]bool),
+		styles:   s,
+		format:   f,
+		width:    width,
+	}
+}
+
+func (m errorsModel) Update(msg tea.Msg) (errorsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "down", "j":
+		if m.cursor < len(m.entries)-1 {
+			m.cursor++
+		}
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "enter", " ":
+		if len(m.entries) > 0 {
+			m.expanded[m.cursor] = !m.expanded[m.cursor]
+		}
+	case "c":
+		m.entries = nil
+		m.cursor = 0
+		m.expanded = make(map[int]bool)
+		return m, func() tea.Msg { return errorsClearMsg{} }
+	case "esc", "q", "e":
+		return m, func() tea.Msg { return errorsCloseMsg{} }
+	}
+	return m, nil
+}
+
+func (m errorsModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Errors"))
+	b.WriteString("\n\n")
+
+	if len(m.entries) == 0 {
+		b.WriteString(m.styles.WizardDim.Render("  No errors"))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  esc: close"))
+		return b.String()
+	}
+
+	textWidth := max(m.width/2-8, 20)
+	for i, e := range m.entries {
+		ts := m.format.clockSeconds(e.time)
+		marker := "▸"
+		if m.expanded[i] {
+			marker = "▾"
+		}
+		line := fmt.Sprintf("%s %s %s", marker, ts, truncate(e.summary(), textWidth-len(ts)-3))
+		if i == m.cursor {
+			b.WriteString(m.styles.WizardActive.Render("  " + line))
+		} else {
+			b.WriteString(m.styles.Error.Render("  " + line))
+		}
+		b.WriteString("\n")
+
+		if m.expanded[i] {
+			body := lipgloss.NewStyle().Width(textWidth).Render(e.text)
+			for _, l := range strings.Split(body, "\n") {
+				b.WriteString(m.styles.WizardDim.Render("      " + l))
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.Help.Render("  j/k: select │ enter: expand │ c: clear │ esc: close"))
+	return b.String()
+}
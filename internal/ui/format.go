@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/simonbystrom/mastermind/internal/config"
+)
+
+// formatter formats costs and times as configured under [format].
+type formatter struct {
+	printer  *message.Printer
+	clock24h bool
+}
+
+func newFormatter(cfg config.Format) formatter {
+	return formatter{
+		printer:  message.NewPrinter(resolveLocale(cfg.Locale)),
+		clock24h: cfg.Clock24h,
+	}
+}
+
+// resolveLocale returns the language of locale, or of the environment's
+// locale when it is empty, falling back to American English.
+func resolveLocale(locale string) language.Tag {
+	for _, l := range []string{locale, os.Getenv("LC_ALL"), os.Getenv("LC_NUMERIC"), os.Getenv("LANG")} {
+		// POSIX locales look like de_DE.UTF-8@euro.
+		l, _, _ = strings.Cut(l, ".")
+		l, _, _ = strings.Cut(l, "@")
+		if l == "" || l == "C" || l == "POSIX" {
+			continue
+		}
+		if tag, err := language.Parse(strings.ReplaceAll(l, "_", "-")); err == nil {
+			return tag
+		}
+	}
+	return language.AmericanEnglish
+}
+
+// cost formats an amount in US dollars with the locale's separators,
+// e.g. "$1,234.50" or "$1.234,50".
+func (f formatter) cost(usd float64) string {
+	return f.printer.Sprintf("$%.2f", usd)
+}
+
+// clock formats the time of day of t.
+func (f formatter) clock(t time.Time) string {
+	if f.clock24h {
+		return t.Format("15:04")
+	}
+	return t.Format("3:04PM")
+}
+
+// clockSeconds formats the time of day of t including seconds.
+func (f formatter) clockSeconds(t time.Time) string {
+	if f.clock24h {
+		return t.Format("15:04:05")
+	}
+	return t.Format("3:04:05PM")
+}
+
+// formatDuration shows d as "4m 05s" under an hour, "8h 5m" under a day
+// and "2d 3h" beyond.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d >= 24*time.Hour:
+		h := int(d.Hours())
+		return fmt.Sprintf("%dd %dh", h/24, h%24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	m := int(d.Minutes())
+	s := int(d.Seconds()) % 60
+	sec := strconv.Itoa(s)
+	if s < 10 {
+		sec = "0" + sec
+	}
+	return strconv.Itoa(m) + "m " + sec + "s"
+}
+
+// formatAgo shows how long ago something happened d before now, e.g.
+// "15m ago", "2h ago" or "3d ago".
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours())/24)
+}
+
+// when formats t for the dashboard's time columns: relative to now, or
+// with absolute set as the time of day, or the date if t was on an
+// earlier day. A zero t is "-".
+func (f formatter) when(t, now time.Time, absolute bool) string {
+	switch {
+	case t.IsZero():
+		return "-"
+	case !absolute:
+		return formatAgo(now.Sub(t))
+	}
+	t = t.In(now.Location())
+	if y, m, d := t.Date(); y == now.Year() && m == now.Month() && d == now.Day() {
+		return f.clock(t)
+	}
+	return t.Format("Jan 2")
+}
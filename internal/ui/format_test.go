@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/config"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m 00s"},
+		{65 * time.Second, "1m 05s"},
+		{30 * time.Second, "0m 30s"},
+		{3661 * time.Second, "1h 1m"},
+		{485*time.Minute + 2*time.Second, "8h 5m"},
+		{51 * time.Hour, "2d 3h"},
+	}
+	for _, tt := range tests {
+		got := formatDuration(tt.d)
+		if got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatter_Cost(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_NUMERIC", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en-US", "$1,234.50"},
+		{"", "$1.234,50"}, // from LANG
+		{"fr_FR", "$1\u00a0234,50"},
+	}
+	for _, tt := range tests {
+		got := newFormatter(config.Format{Locale: tt.locale}).cost(1234.5)
+		if got != tt.want {
+			t.Errorf("cost with locale %q = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestFormatter_Clock(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	if got := newFormatter(config.Format{Clock24h: true}).clock(ts); got != "15:04" {
+		t.Errorf("24h clock = %q", got)
+	}
+	if got := newFormatter(config.Format{}).clockSeconds(ts); got != "3:04:05PM" {
+		t.Errorf("12h clock = %q", got)
+	}
+}
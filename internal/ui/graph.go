@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/git"
+)
+
+// graphLimit caps the number of graph lines rendered.
+const graphLimit = 60
+
+// graphPageSize is how many graph lines are shown at once.
+const graphPageSize = 20
+
+// graphRow summarizes one agent branch relative to its base.
+type graphRow struct {
+	agentID string
+	branch  string
+	base    string
+	ahead   int
+	behind  int
+	err     error
+}
+
+type graphModel struct {
+	repoPath string
+	store    *agent.Store
+	styles   Styles
+	width    int // of the side panel
+
+	loading bool
+	rows    []graphRow
+	lines   []string
+	offset  int
+	err     string
+}
+
+type graphCloseMsg struct{}
+
+type graphLoadedMsg struct {
+	rows  []graphRow
+	lines []string
+	err   error
+}
+
+func newGraph(s Styles, store *agent.Store, repoPath string, width int) graphModel {
+	return graphModel{
+		repoPath: repoPath,
+		store:    store,
+		styles:   s,
+		width:    width,
+		loading:  true,
+	}
+}
+
+func (m graphModel) Init() tea.Cmd {
+	return m.load()
+}
+
+// load collects ahead/behind counts and the commit graph for every agent
+// branch and the bases they target.
+func (m graphModel) load() tea.Cmd {
+	agents := m.store.All()
+	return func() tea.Msg {
+		sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+
+		var rows []graphRow
+		seen := make(map[string]bool)
+		var refs []string
+		addRef := func(ref string) {
+			if ref != "" && !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+		for _, a := range agents {
+			base := a.GetBaseBranch()
+			row := graphRow{agentID: a.ID, branch: a.Branch, base: base}
+			if base != "" {
+				row.ahead, row.behind, row.err = git.AheadBehind(m.repoPath, a.Branch, base)
+			}
+			rows = append(rows, row)
+			addRef(base)
+			addRef(a.Branch)
+		}
+
+		lines, err := git.BranchGraph(m.repoPath, refs, graphLimit)
+		return graphLoadedMsg{rows: rows, lines: lines, err: err}
+	}
+}
+
+func (m graphModel) Update(msg tea.Msg) (graphModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case graphLoadedMsg:
+		m.loading = false
+		m.rows = msg.rows
+		m.lines = msg.lines
+		m.offset = 0
+		m.err = ""
+		if msg.err != nil {
+			m.err = msg.err.Error()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q", "g":
+			return m, func() tea.Msg { return graphCloseMsg{} }
+		case "r":
+			m.loading = true
+			return m, m.load()
+		case "down", "j":
+			if m.offset < len(m.lines)-graphPageSize {
+				m.offset++
+			}
+		case "up", "k":
+			if m.offset > 0 {
+				m.offset--
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m graphModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Branch Graph"))
+	b.WriteString("\n\n")
+
+	if m.loading {
+		b.WriteString(m.styles.WizardDim.Render("  Loading…"))
+		return b.String()
+	}
+
+	cw := max(m.width-2, 10) // less the indent
+	if len(m.rows) == 0 {
+		b.WriteString(m.styles.WizardDim.Render("  No agent branches."))
+		b.WriteString("\n")
+	}
+	for _, r := range m.rows {
+		var counts string
+		switch {
+		case r.base == "":
+			counts = "no base"
+		case r.err != nil:
+			counts = "unknown"
+		default:
+			counts = fmt.Sprintf("↑%d ↓%d %s", r.ahead, r.behind, r.base)
+		}
+		line := fmt.Sprintf("  %-4s %s", r.agentID, truncate(r.branch, max(cw/2, 10)))
+		b.WriteString(line)
+		b.WriteString("  ")
+		if r.behind > 0 {
+			b.WriteString(m.styles.Attention.Render(counts))
+		} else {
+			b.WriteString(m.styles.WizardDim.Render(counts))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+		b.WriteString("\n")
+	} else {
+		end := min(m.offset+graphPageSize, len(m.lines))
+		for _, line := range m.lines[m.offset:end] {
+			// Cut rather than let the panel wrap the graph.
+			b.WriteString("  " + ansi.Truncate(line, cw, "…"))
+			b.WriteString("\n")
+		}
+		if len(m.lines) > graphPageSize {
+			b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("  lines %d–%d of %d", m.offset+1, end, len(m.lines))))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.Help.Render("  j/k: scroll │ r: refresh │ esc: close"))
+	return b.String()
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/instance"
+)
+
+// instancesModel lists the other mastermind instances running for the
+// user, e.g. one per repository, and focuses the tmux pane of the
+// selected one.
+type instancesModel struct {
+	styles Styles
+	width  int
+
+	loading   bool
+	instances []instance.Instance
+	cursor    int
+	err       string
+}
+
+type instancesCloseMsg struct{}
+
+type instancesLoadedMsg struct {
+	instances []instance.Instance
+	err       error
+}
+
+// instanceFocusedMsg reports the outcome of focusing an instance.
+type instanceFocusedMsg struct {
+	label string
+	err   string
+}
+
+func newInstances(s Styles, width int) instancesModel {
+	return instancesModel{
+		styles:  s,
+		width:   width,
+		loading: true,
+	}
+}
+
+func (m instancesModel) Init() tea.Cmd {
+	return loadInstances
+}
+
+// loadInstances lists the running instances other than this one.
+func loadInstances() tea.Msg {
+	all, err := instance.List()
+	var others []instance.Instance
+	for _, inst := range all {
+		if inst.PID != os.Getpid() {
+			others = append(others, inst)
+		}
+	}
+	return instancesLoadedMsg{instances: others, err: err}
+}
+
+func (m instancesModel) Update(msg tea.Msg) (instancesModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case instancesLoadedMsg:
+		m.loading = false
+		m.instances = msg.instances
+		m.cursor = min(m.cursor, max(len(m.instances)-1, 0))
+		m.err = ""
+		if msg.err != nil {
+			m.err = msg.err.Error()
+		}
+		return m, nil
+
+	case instanceFocusedMsg:
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q", "I":
+			return m, func() tea.Msg { return instancesCloseMsg{} }
+		case "r":
+			m.loading = true
+			return m, loadInstances
+		case "down", "j":
+			if m.cursor < len(m.instances)-1 {
+				m.cursor++
+			}
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "enter":
+			if m.cursor < len(m.instances) {
+				inst := m.instances[m.cursor]
+				return m, func() tea.Msg {
+					msg := instanceFocusedMsg{label: inst.Label()}
+					if err := instance.Focus(inst); err != nil {
+						msg.err = err.Error()
+					}
+					return msg
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m instancesModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Mastermind Instances"))
+	b.WriteString("\n\n")
+
+	if m.loading {
+		b.WriteString(m.styles.WizardDim.Render("  Loading…"))
+		return b.String()
+	}
+
+	if len(m.instances) == 0 {
+		b.WriteString(m.styles.WizardDim.Render("  No other instances running."))
+		b.WriteString("\n")
+	}
+	for i, inst := range m.instances {
+		line := fmt.Sprintf("%s  %s", inst.Label(), truncate(inst.Repo, max(m.width/3, 20)))
+		if inst.Session != "" {
+			line += "  (" + inst.Session + ")"
+		}
+		if i == m.cursor {
+			b.WriteString(m.styles.WizardActive.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	if m.err != "" {
+		b.WriteString("\n")
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.Help.Render("  ↑/↓: select │ enter: switch │ r: refresh │ esc: close"))
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/instance"
+)
+
+func TestInstances_ListsOtherInstances(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	for _, inst := range []instance.Instance{
+		{PID: os.Getpid(), Name: "self", Repo: "/src/self"},
+		{PID: os.Getpid() + 1, Name: "api", Repo: "/src/backend", Session: "work", PaneID: "%3"},
+	} {
+		reg, err := instance.Register(inst)
+		if err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+		defer reg.Remove()
+	}
+
+	m := newTestApp(t)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'I'}})
+	m = updated.(AppModel)
+	if m.activeView != viewInstances || cmd == nil {
+		t.Fatalf("activeView = %v, want the instances view loading", m.activeView)
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(AppModel)
+
+	view := m.instances.ViewContent()
+	if !strings.Contains(view, "> api  /src/backend  (work)") {
+		t.Errorf("view = %q, want the api instance selected", view)
+	}
+	if strings.Contains(view, "self") {
+		t.Errorf("view = %q, should not list this instance", view)
+	}
+
+	updated, _ = m.Update(instanceFocusedMsg{label: "api"})
+	m = updated.(AppModel)
+	if m.activeView != viewDashboard {
+		t.Errorf("activeView = %v, want the dashboard after switching", m.activeView)
+	}
+	if n := m.dashboard.notifications; len(n) == 0 || n[len(n)-1].text != "Switched to instance api" {
+		t.Errorf("notifications = %+v, want the switch announced", n)
+	}
+}
@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+// maintenanceTask is one entry in the maintenance menu.
+type maintenanceTask struct {
+	label    string
+	desc     string
+	selected bool
+}
+
+type maintenanceModel struct {
+	orch   *orchestrator.Orchestrator
+	styles Styles
+	width  int
+
+	tasks   []maintenanceTask
+	cursor  int
+	running bool
+	report  *orchestrator.MaintenanceReport
+	err     string
+
+	spinner spinner.Model
+}
+
+type maintenanceCloseMsg struct{}
+
+func newMaintenance(s Styles, orch *orchestrator.Orchestrator, width int) maintenanceModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.MiniDot
+	return maintenanceModel{
+		orch:   orch,
+		styles: s,
+		width:  width,
+		tasks: []maintenanceTask{
+			{label: "Repair worktrees", desc: "git worktree repair — relink worktrees broken by a repo move", selected: true},
+			{label: "Prune worktrees", desc: "git worktree prune — drop entries for deleted worktree dirs", selected: true},
+			{label: "Garbage collect", desc: "git gc --auto — housekeeping only when needed", selected: true},
+			{label: "Stale preview branches", desc: "delete preview/* branches not backing an active preview", selected: true},
+			{label: "Orphaned status files", desc: "remove .mastermind-status and heartbeat files from worktrees no agent owns", selected: true},
+		},
+		spinner: sp,
+	}
+}
+
+func (m maintenanceModel) options() orchestrator.MaintenanceOptions {
+	return orchestrator.MaintenanceOptions{
+		RepairWorktrees: m.tasks[0].selected,
+		PruneWorktrees:  m.tasks[1].selected,
+		GC:              m.tasks[2].selected,
+		PreviewBranches: m.tasks[3].selected,
+		StatusFiles:     m.tasks[4].selected,
+	}
+}
+
+func (m maintenanceModel) Update(msg tea.Msg) (maintenanceModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if m.running {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case orchestrator.MaintenanceResultMsg:
+		m.running = false
+		m.report = &msg.Report
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running {
+			return m, nil
+		}
+		if m.report != nil {
+			// Any key dismisses the report.
+			return m, func() tea.Msg { return maintenanceCloseMsg{} }
+		}
+
+		m.err = ""
+		switch msg.String() {
+		case "esc", "q":
+			return m, func() tea.Msg { return maintenanceCloseMsg{} }
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.tasks)-1 {
+				m.cursor++
+			}
+		case " ":
+			m.tasks[m.cursor].selected = !m.tasks[m.cursor].selected
+		case "enter":
+			opts := m.options()
+			if opts == (orchestrator.MaintenanceOptions{}) {
+				m.err = "select at least one task"
+				return m, nil
+			}
+			m.running = true
+			orch := m.orch
+			return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+				return orchestrator.MaintenanceResultMsg{Report: orch.RunMaintenance(opts)}
+			})
+		}
+	}
+	return m, nil
+}
+
+func (m maintenanceModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Maintenance"))
+	b.WriteString("\n\n")
+
+	if m.report != nil {
+		b.WriteString(m.viewReport(*m.report))
+		b.WriteString("\n")
+		b.WriteString(m.styles.Help.Render("  any key: close"))
+		return b.String()
+	}
+
+	for i, t := range m.tasks {
+		check := "[ ]"
+		if t.selected {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", check, t.label)
+		if i == m.cursor {
+			b.WriteString(m.styles.WizardActive.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+		b.WriteString(m.styles.WizardDim.Render("      " + t.desc))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.running {
+		b.WriteString(m.styles.WizardActive.Render("  " + m.spinner.View() + " Running..."))
+	} else {
+		b.WriteString(m.styles.Help.Render("  space: toggle │ enter: run │ esc: cancel"))
+	}
+
+	if m.err != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+	}
+	return b.String()
+}
+
+func (m maintenanceModel) viewReport(r orchestrator.MaintenanceReport) string {
+	var b strings.Builder
+	if r.Empty() {
+		b.WriteString(m.styles.Reviewed.Render("  Nothing to clean up."))
+		b.WriteString("\n")
+	}
+	section := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		b.WriteString(m.styles.WizardActive.Render(fmt.Sprintf("  %s (%d)", title, len(items))))
+		b.WriteString("\n")
+		for _, item := range items {
+			b.WriteString("    - " + truncate(item, max(m.width-12, 20)) + "\n")
+		}
+	}
+	section("Repaired worktrees", r.RepairedWorktrees)
+	section("Pruned worktrees", r.PrunedWorktrees)
+	section("Deleted branches", r.DeletedBranches)
+	section("Removed files", r.RemovedFiles)
+	if r.GCRan {
+		b.WriteString(m.styles.WizardDim.Render("  git gc --auto completed"))
+		b.WriteString("\n")
+	}
+	for _, e := range r.Errors {
+		b.WriteString(m.styles.Error.Render("  Error: " + e))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -5,11 +5,16 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/simonbystrom/mastermind/internal/git"
 	"github.com/simonbystrom/mastermind/internal/orchestrator"
 )
 
+// mergePreviewFiles is how many changed files the merge wizard lists.
+const mergePreviewFiles = 10
+
 type mergeStep int
 
 const (
@@ -32,17 +37,62 @@ type mergeModel struct {
 	baseBranch string
 
 	// Cleanup options (toggled by user)
-	deleteBranch   bool // default: true
-	removeWorktree bool // default: true
-	optionCursor   int  // 0 = removeWorktree, 1 = deleteBranch
+	deleteBranch   bool // default: Orchestrator.MergeDefaults
+	removeWorktree bool // default: Orchestrator.MergeDefaults
+	push           bool // default: Orchestrator.MergeDefaults
+	optionCursor   int  // 0 = removeWorktree, 1 = deleteBranch, 2 = push
+
+	// How the branch lands on base, cycled with s
+	strategy orchestrator.MergeStrategy // default: Orchestrator.MergeDefaults
+
+	// Squash commit message: the subject is edited with e, the body of
+	// commit subjects is generated
+	message        textinput.Model
+	messageBody    string
+	editingMessage bool
 
 	// Conflict info
-	conflictFiles []string
+	conflictFiles   []string
+	conflictNotes   []string
+	conflictDetails map[string]orchestrator.ConflictFile // by path, loaded in the background
+	conflictCursor  int
+	resolving       bool
+
+	// What the merge lands on base, loaded when the wizard opens
+	preview    *git.MergePreview
+	previewErr string
 
 	// Spinner shown during merge
 	spinner spinner.Model
 }
 
+// mergePreviewMsg carries what merging the agent would land on base.
+type mergePreviewMsg struct {
+	agentID string
+	preview git.MergePreview
+	err     string
+}
+
+// squashMessageMsg carries the generated squash commit message.
+type squashMessageMsg struct {
+	agentID string
+	message string
+}
+
+// conflictDetailsMsg carries which conflicted files are binary or large.
+type conflictDetailsMsg struct {
+	agentID string
+	files   []orchestrator.ConflictFile
+}
+
+// conflictResolvedMsg reports the outcome of resolving a conflicted file
+// by taking one side.
+type conflictResolvedMsg struct {
+	agentID string
+	result  orchestrator.MergeResultMsg
+	err     string
+}
+
 type mergeDoneMsg struct{}
 type mergeCancelMsg struct{}
 
@@ -57,6 +107,10 @@ type startMergeMsg struct {
 func newMerge(s Styles, orch *orchestrator.Orchestrator, repoPath string, msg startMergeMsg) mergeModel {
 	sp := spinner.New()
 	sp.Spinner = spinner.MiniDot
+	defaults := orch.MergeDefaults()
+	ti := textinput.New()
+	ti.Placeholder = "commit subject"
+	ti.CharLimit = 200
 	return mergeModel{
 		orch:           orch,
 		repoPath:       repoPath,
@@ -65,15 +119,55 @@ func newMerge(s Styles, orch *orchestrator.Orchestrator, repoPath string, msg st
 		agentName:      msg.agentName,
 		branch:         msg.branch,
 		baseBranch:     msg.baseBranch,
-		deleteBranch:   true,
-		removeWorktree: true,
+		deleteBranch:   defaults.DeleteBranch,
+		removeWorktree: defaults.RemoveWorktree,
+		push:           defaults.Push,
+		strategy:       defaults.Strategy,
+		message:        ti,
 		styles:         s,
 		spinner:        sp,
 	}
 }
 
+// Init loads the merge preview and the squash commit message in the
+// background. Detaching an agent on an existing branch merges nothing, so
+// there is nothing to preview.
+func (m mergeModel) Init() tea.Cmd {
+	if m.isExistingBranch() {
+		return nil
+	}
+	orch, id := m.orch, m.agentID
+	return tea.Batch(func() tea.Msg {
+		p, err := orch.PreviewMerge(id)
+		msg := mergePreviewMsg{agentID: id, preview: p}
+		if err != nil {
+			msg.err = err.Error()
+		}
+		return msg
+	}, func() tea.Msg {
+		// Without a message the merge generates one itself.
+		message, _ := orch.SquashMessage(id)
+		return squashMessageMsg{agentID: id, message: message}
+	})
+}
+
 func (m mergeModel) Update(msg tea.Msg) (mergeModel, tea.Cmd) {
 	switch msg := msg.(type) {
+	case mergePreviewMsg:
+		if msg.agentID == m.agentID {
+			m.preview = &msg.preview
+			m.previewErr = msg.err
+		}
+		return m, nil
+
+	case squashMessageMsg:
+		if msg.agentID == m.agentID && m.message.Value() == "" {
+			subject, body, _ := strings.Cut(msg.message, "\n")
+			m.message.SetValue(subject)
+			m.messageBody = strings.TrimSpace(body)
+		}
+		return m, nil
+
 	case orchestrator.PruneResultMsg:
 		if msg.AgentID != m.agentID {
 			return m, nil
@@ -97,7 +191,11 @@ func (m mergeModel) Update(msg tea.Msg) (mergeModel, tea.Cmd) {
 		if msg.Conflict {
 			m.step = mergeStepConflicts
 			m.conflictFiles = msg.ConflictFiles
-			return m, nil
+			m.conflictNotes = msg.ConflictNotes
+			if m.conflictCursor >= len(m.conflictFiles) {
+				m.conflictCursor = max(len(m.conflictFiles)-1, 0)
+			}
+			return m, m.loadConflictDetails()
 		}
 		m.step = mergeStepConfirm
 		if msg.Error != "" {
@@ -105,6 +203,31 @@ func (m mergeModel) Update(msg tea.Msg) (mergeModel, tea.Cmd) {
 		}
 		return m, nil
 
+	case conflictDetailsMsg:
+		if msg.agentID == m.agentID {
+			m.conflictDetails = make(map[string]orchestrator.ConflictFile, len(msg.files))
+			for _, f := range msg.files {
+				m.conflictDetails[f.Path] = f
+			}
+		}
+		return m, nil
+
+	case conflictResolvedMsg:
+		if msg.agentID != m.agentID {
+			return m, nil
+		}
+		m.resolving = false
+		if msg.err != "" {
+			m.err = msg.err
+			return m, nil
+		}
+		if msg.result.Conflict {
+			return m.Update(msg.result)
+		}
+		// Done or failed: pass the result on so the dashboard reports it.
+		result := msg.result
+		return m, func() tea.Msg { return result }
+
 	case spinner.TickMsg:
 		if m.step == mergeStepMerging {
 			var cmd tea.Cmd
@@ -120,6 +243,18 @@ func (m mergeModel) Update(msg tea.Msg) (mergeModel, tea.Cmd) {
 
 		m.err = ""
 
+		if m.editingMessage {
+			switch msg.String() {
+			case "enter", "esc":
+				m.editingMessage = false
+				m.message.Blur()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.message, cmd = m.message.Update(msg)
+			return m, cmd
+		}
+
 		if msg.String() == "esc" {
 			return m, func() tea.Msg { return mergeCancelMsg{} }
 		}
@@ -156,7 +291,7 @@ func (m mergeModel) updateConfirm(msg tea.KeyMsg) (mergeModel, tea.Cmd) {
 
 	switch msg.String() {
 	case "j", "down":
-		if m.optionCursor < 1 {
+		if m.optionCursor < 2 {
 			m.optionCursor++
 		}
 	case "k", "up":
@@ -164,25 +299,78 @@ func (m mergeModel) updateConfirm(msg tea.KeyMsg) (mergeModel, tea.Cmd) {
 			m.optionCursor--
 		}
 	case " ":
-		if m.optionCursor == 0 {
+		switch m.optionCursor {
+		case 0:
 			m.removeWorktree = !m.removeWorktree
-		} else {
+		case 1:
 			m.deleteBranch = !m.deleteBranch
+		default:
+			m.push = !m.push
+		}
+	case "s":
+		m.strategy = nextStrategy(m.strategy)
+	case "e":
+		if m.strategy == orchestrator.MergeSquash {
+			m.editingMessage = true
+			return m, m.message.Focus()
 		}
 	case "y", "enter":
+		var message string
+		if m.strategy == orchestrator.MergeSquash {
+			message = m.squashMessage()
+		}
 		m.step = mergeStepMerging
 		mergeID := m.agentID
 		delBranch := m.deleteBranch
 		removeWT := m.removeWorktree
+		push := m.push
+		strategy := m.strategy
 		mergeCmd := func() tea.Msg {
-			return m.orch.MergeAgent(mergeID, delBranch, removeWT)
+			m.orch.RememberMergeChoices(orchestrator.MergeChoices{DeleteBranch: delBranch, RemoveWorktree: removeWT, Push: push, Strategy: strategy})
+			return m.orch.MergeAgent(mergeID, delBranch, removeWT, push, strategy, message)
 		}
 		return m, tea.Batch(m.spinner.Tick, mergeCmd)
 	}
 	return m, nil
 }
 
+// squashMessage returns the squash commit message: the subject as typed
+// and the generated body. Empty lets the merge generate the message.
+func (m mergeModel) squashMessage() string {
+	subject := strings.TrimSpace(m.message.Value())
+	if subject == "" || m.messageBody == "" {
+		return subject
+	}
+	return subject + "\n\n" + m.messageBody
+}
+
+// nextStrategy returns the merge strategy after s.
+func nextStrategy(s orchestrator.MergeStrategy) orchestrator.MergeStrategy {
+	all := orchestrator.MergeStrategies
+	for i, st := range all {
+		if st == s {
+			return all[(i+1)%len(all)]
+		}
+	}
+	return all[0]
+}
+
+// strategyLabel describes what a merge strategy does to base.
+func strategyLabel(s orchestrator.MergeStrategy, base string) string {
+	switch s {
+	case orchestrator.MergeRebase:
+		return "rebase onto " + base + ", then fast-forward"
+	case orchestrator.MergeSquash:
+		return "squash into one commit on " + base
+	default:
+		return "merge " + base + " in, then fast-forward"
+	}
+}
+
 func (m mergeModel) updateConflicts(msg tea.KeyMsg) (mergeModel, tea.Cmd) {
+	if m.resolving {
+		return m, nil
+	}
 	switch msg.String() {
 	case "enter":
 		if err := m.orch.OpenLazyGit(m.agentID); err != nil {
@@ -190,10 +378,65 @@ func (m mergeModel) updateConflicts(msg tea.KeyMsg) (mergeModel, tea.Cmd) {
 			return m, nil
 		}
 		return m, func() tea.Msg { return mergeDoneMsg{} }
+	case "j", "down":
+		if m.conflictCursor < len(m.conflictFiles)-1 {
+			m.conflictCursor++
+		}
+	case "k", "up":
+		if m.conflictCursor > 0 {
+			m.conflictCursor--
+		}
+	case "e":
+		if len(m.conflictFiles) == 0 {
+			return m, nil
+		}
+		path := m.conflictFiles[m.conflictCursor]
+		if d, ok := m.conflictDetails[path]; ok && d.Binary {
+			m.err = path + " is binary; keep a side with o or t"
+			return m, nil
+		}
+		if err := m.orch.OpenConflictInEditor(m.agentID, path); err != nil {
+			m.err = err.Error()
+		}
+	case "o":
+		return m.resolveConflict(git.Ours)
+	case "t":
+		return m.resolveConflict(git.Theirs)
 	}
 	return m, nil
 }
 
+// resolveConflict resolves the selected file by taking one side of it,
+// git.Ours being the agent's version and git.Theirs base's.
+func (m mergeModel) resolveConflict(side git.ConflictSide) (mergeModel, tea.Cmd) {
+	if len(m.conflictFiles) == 0 {
+		return m, nil
+	}
+	m.resolving = true
+	orch, id, path := m.orch, m.agentID, m.conflictFiles[m.conflictCursor]
+	return m, func() tea.Msg {
+		res, err := orch.ResolveConflict(id, path, side)
+		msg := conflictResolvedMsg{agentID: id, result: res}
+		if err != nil {
+			msg.err = err.Error()
+		}
+		return msg
+	}
+}
+
+// loadConflictDetails finds out which conflicted files are binary or
+// large in the background.
+func (m mergeModel) loadConflictDetails() tea.Cmd {
+	orch, id := m.orch, m.agentID
+	return func() tea.Msg {
+		files, err := orch.ConflictDetails(id)
+		if err != nil {
+			return nil
+		}
+		return conflictDetailsMsg{agentID: id, files: files}
+	}
+}
+
 func (m mergeModel) ViewContent() string {
 	var b strings.Builder
 
@@ -228,6 +471,11 @@ func (m mergeModel) ViewContent() string {
 			b.WriteString(fmt.Sprintf("  Agent:       %s\n", m.agentName))
 			b.WriteString(fmt.Sprintf("  Branch:      %s\n", m.branch))
 			b.WriteString(fmt.Sprintf("  Into:        %s\n", m.baseBranch))
+			b.WriteString(fmt.Sprintf("  Strategy:    %s\n", strategyLabel(m.strategy, m.baseBranch)))
+			if m.strategy == orchestrator.MergeSquash {
+				m.writeMessage(&b)
+			}
+			m.writePreview(&b)
 			b.WriteString("\n")
 			b.WriteString(m.styles.WizardActive.Render("  After merge:"))
 			b.WriteString("\n")
@@ -238,6 +486,7 @@ func (m mergeModel) ViewContent() string {
 			}{
 				{"Remove worktree", m.removeWorktree},
 				{"Delete branch", m.deleteBranch},
+				{"Push " + m.baseBranch + " to its upstream", m.push},
 			}
 			for i, opt := range options {
 				cursor := "  "
@@ -258,10 +507,15 @@ func (m mergeModel) ViewContent() string {
 			}
 
 			b.WriteString("\n")
-			if m.step == mergeStepMerging {
+			switch {
+			case m.step == mergeStepMerging:
 				b.WriteString(m.styles.WizardActive.Render("  " + m.spinner.View() + " Merging..."))
-			} else {
-				b.WriteString(m.styles.Help.Render("  y/enter: merge | space: toggle | esc: cancel"))
+			case m.editingMessage:
+				b.WriteString(m.styles.Help.Render("  enter/esc: done editing"))
+			case m.strategy == orchestrator.MergeSquash:
+				b.WriteString(m.styles.Help.Render("  y/enter: merge | space: toggle | s: strategy | e: edit message | esc: cancel"))
+			default:
+				b.WriteString(m.styles.Help.Render("  y/enter: merge | space: toggle | s: strategy | esc: cancel"))
 			}
 		}
 
@@ -278,13 +532,44 @@ func (m mergeModel) ViewContent() string {
 			b.WriteString(m.styles.WizardDim.Render("    (no files detected)"))
 			b.WriteString("\n")
 		} else {
-			for _, f := range m.conflictFiles {
-				b.WriteString(fmt.Sprintf("    both modified:   %s\n", f))
+			for i, f := range m.conflictFiles {
+				line := fmt.Sprintf("both modified:   %s", f)
+				if d, ok := m.conflictDetails[f]; ok {
+					var notes []string
+					if d.Binary {
+						notes = append(notes, "binary")
+					}
+					if d.Large {
+						notes = append(notes, "large, "+formatSize(d.Size))
+					}
+					if len(notes) > 0 {
+						line += "  (" + strings.Join(notes, ", ") + ")"
+					}
+				}
+				if i == m.conflictCursor {
+					b.WriteString(m.styles.WizardActive.Render("  > " + line))
+				} else {
+					b.WriteString("    " + line)
+				}
+				b.WriteString("\n")
+			}
+		}
+		if len(m.conflictNotes) > 0 {
+			b.WriteString("\n")
+			b.WriteString(m.styles.WizardActive.Render("  Merge drivers:"))
+			b.WriteString("\n")
+			for _, n := range m.conflictNotes {
+				b.WriteString(m.styles.Error.Render("    " + n))
+				b.WriteString("\n")
 			}
 		}
 
 		b.WriteString("\n")
-		b.WriteString(m.styles.Help.Render("  enter: open lazygit | esc: cancel"))
+		if m.resolving {
+			b.WriteString(m.styles.WizardActive.Render("  Resolving..."))
+		} else {
+			b.WriteString(m.styles.Help.Render("  e: edit | o: keep agent's | t: take " + m.baseBranch + "'s | enter: open lazygit | esc: cancel"))
+		}
 	}
 
 	if m.err != "" {
@@ -295,6 +580,80 @@ func (m mergeModel) ViewContent() string {
 	return b.String()
 }
 
+// writePreview shows what the merge lands on base: the diff stat, the
+// changed files and whether base is fast-forwarded or gets a merge commit.
+func (m mergeModel) writePreview(b *strings.Builder) {
+	switch {
+	case m.previewErr != "":
+		b.WriteString(m.styles.WizardDim.Render("  Changes:     " + m.previewErr))
+		b.WriteString("\n")
+		return
+	case m.preview == nil:
+		b.WriteString(m.styles.WizardDim.Render("  Changes:     loading..."))
+		b.WriteString("\n")
+		return
+	}
+	p := m.preview
+
+	switch {
+	case m.strategy == orchestrator.MergeSquash:
+		b.WriteString(fmt.Sprintf("  Merge:       the branch's commits become one new commit on %s\n", m.baseBranch))
+	case p.FastForward:
+		b.WriteString(fmt.Sprintf("  Merge:       fast-forward %s\n", m.baseBranch))
+	case m.strategy == orchestrator.MergeRebase:
+		b.WriteString(fmt.Sprintf("  Merge:       %s has moved on; the branch will be rebased onto it\n", m.baseBranch))
+	default:
+		b.WriteString(fmt.Sprintf("  Merge:       %s has moved on; a merge commit will be created\n", m.baseBranch))
+	}
+	if len(p.Files) == 0 {
+		b.WriteString("  Changes:     none\n")
+		return
+	}
+	b.WriteString(fmt.Sprintf("  Changes:     %s\n", p.Stat))
+	for i, f := range p.Files {
+		if i == mergePreviewFiles {
+			b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("    ... and %d more", len(p.Files)-i)))
+			b.WriteString("\n")
+			break
+		}
+		counts := "binary"
+		if !f.Binary {
+			counts = fmt.Sprintf("+%d -%d", f.Insertions, f.Deletions)
+		}
+		b.WriteString(fmt.Sprintf("    %-12s %s\n", counts, f.Path))
+	}
+}
+
+// writeMessage shows the squash commit message, the subject being edited
+// in place.
+func (m mergeModel) writeMessage(b *strings.Builder) {
+	switch {
+	case m.editingMessage:
+		b.WriteString("  Message:     " + m.message.View() + "\n")
+	case m.message.Value() == "":
+		b.WriteString(m.styles.WizardDim.Render("  Message:     generated from the branch's commits"))
+		b.WriteString("\n")
+	default:
+		b.WriteString(fmt.Sprintf("  Message:     %s\n", truncate(m.message.Value(), 60)))
+	}
+	if m.messageBody != "" {
+		lines := strings.Count(m.messageBody, "\n") + 1
+		b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("                 followed by %d lines listing its commits", lines)))
+		b.WriteString("\n")
+	}
+}
+
 func (m mergeModel) View() string {
 	return m.styles.Border.Render(m.ViewContent())
 }
+
+// formatSize formats a file size in bytes, e.g. "3.2 MB".
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/simonbystrom/mastermind/internal/agent"
 	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/git"
 	"github.com/simonbystrom/mastermind/internal/orchestrator"
 )
 
@@ -53,6 +55,68 @@ func TestMerge_ToggleOptions(t *testing.T) {
 	if m.deleteBranch {
 		t.Error("deleteBranch should be false after toggle")
 	}
+
+	// Move to push
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{' '}})
+	if !m.push {
+		t.Error("push should be true after toggle")
+	}
+	if content := m.ViewContent(); !strings.Contains(content, "[x] Push main to its upstream") {
+		t.Errorf("view should show push checked:\n%s", content)
+	}
+}
+
+func TestMerge_ToggleStrategy(t *testing.T) {
+	m := newTestMerge(t)
+	if m.strategy != orchestrator.MergeCommit {
+		t.Fatalf("strategy = %q, want %q by default", m.strategy, orchestrator.MergeCommit)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if m.strategy != orchestrator.MergeRebase {
+		t.Errorf("strategy = %q after s, want %q", m.strategy, orchestrator.MergeRebase)
+	}
+	if content := m.ViewContent(); !strings.Contains(content, "rebase onto main") {
+		t.Errorf("view should show the rebase strategy:\n%s", content)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if m.strategy != orchestrator.MergeSquash {
+		t.Errorf("strategy = %q after s twice, want %q", m.strategy, orchestrator.MergeSquash)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if m.strategy != orchestrator.MergeCommit {
+		t.Errorf("strategy = %q after s three times, want %q", m.strategy, orchestrator.MergeCommit)
+	}
+}
+
+func TestMerge_SquashMessage(t *testing.T) {
+	m := newTestMerge(t)
+	m.strategy = orchestrator.MergeSquash
+	m, _ = m.Update(squashMessageMsg{agentID: "a1", message: "Squash feat/x (2 commits)\n\n* wip\n* more wip"})
+
+	content := m.ViewContent()
+	for _, want := range []string{"squash into one commit on main", "Message:     Squash feat/x (2 commits)", "followed by 2 lines"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("view should contain %q:\n%s", want, content)
+		}
+	}
+
+	// Editing takes keys that are otherwise shortcuts, and esc only ends it.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m.message.SetValue("")
+	for _, r := range "Add sign-in" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.editingMessage || cmd != nil {
+		t.Fatalf("editing = %v, cmd = %v after esc, want editing ended and the wizard open", m.editingMessage, cmd)
+	}
+	if got, want := m.squashMessage(), "Add sign-in\n\n* wip\n* more wip"; got != want {
+		t.Errorf("squashMessage = %q, want %q", got, want)
+	}
 }
 
 func TestMerge_EscCancels(t *testing.T) {
@@ -101,6 +165,21 @@ func TestMerge_ConflictMsg_ShowsConflicts(t *testing.T) {
 	}
 }
 
+func TestMerge_ConflictMsg_ShowsMergeDriverProblems(t *testing.T) {
+	m := newTestMerge(t)
+
+	m, _ = m.Update(orchestrator.MergeResultMsg{
+		AgentID:       "a1",
+		Conflict:      true,
+		ConflictFiles: []string{"deps.lock"},
+		ConflictNotes: []string{`deps.lock: merge driver "lockfile" from .gitattributes is not configured`},
+	})
+
+	if content := m.ViewContent(); !strings.Contains(content, `merge driver "lockfile"`) {
+		t.Errorf("should show the merge driver problem:\n%s", content)
+	}
+}
+
 func TestMerge_ViewContent_Confirm(t *testing.T) {
 	m := newTestMerge(t)
 
@@ -135,3 +214,104 @@ func TestMerge_ViewContent_Conflicts(t *testing.T) {
 		t.Error("should show conflict file")
 	}
 }
+
+func TestMerge_Preview(t *testing.T) {
+	m := newTestMerge(t)
+	if content := m.ViewContent(); !strings.Contains(content, "loading") {
+		t.Error("should show the preview loading")
+	}
+
+	files := []git.FileStat{{Path: "logo.png", Binary: true}}
+	for i := 0; i < 11; i++ {
+		files = append(files, git.FileStat{Path: fmt.Sprintf("f%d.go", i), Insertions: 1})
+	}
+	m, _ = m.Update(mergePreviewMsg{agentID: "a1", preview: git.MergePreview{
+		Stat:  git.DiffStat{Files: 12, Insertions: 11},
+		Files: files,
+	}})
+	content := m.ViewContent()
+	for _, want := range []string{
+		"main has moved on; a merge commit will be created",
+		"12 files changed, +11 -0",
+		"binary       logo.png",
+		"+1 -0        f0.go",
+		"and 2 more",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("preview should contain %q:\n%s", want, content)
+		}
+	}
+
+	m, _ = m.Update(mergePreviewMsg{agentID: "a1", preview: git.MergePreview{FastForward: true}})
+	if content := m.ViewContent(); !strings.Contains(content, "fast-forward main") {
+		t.Errorf("should show the fast-forward:\n%s", content)
+	}
+}
+
+func TestMerge_ConfiguredDefaults(t *testing.T) {
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir(),
+		orchestrator.WithMergeDefaults(false, true, false))
+	m := newMerge(NewStyles(config.Default().Colors), orch, "/repo", startMergeMsg{
+		agentID:    "a1",
+		branch:     "feat/x",
+		baseBranch: "main",
+	})
+	if m.deleteBranch || !m.removeWorktree {
+		t.Errorf("deleteBranch = %v, removeWorktree = %v; want the configured defaults", m.deleteBranch, m.removeWorktree)
+	}
+}
+
+func TestMerge_ConflictsQuickResolve(t *testing.T) {
+	m := newTestMerge(t)
+	m, _ = m.Update(orchestrator.MergeResultMsg{
+		AgentID:       "a1",
+		Conflict:      true,
+		ConflictFiles: []string{"logo.png", "dump.sql"},
+	})
+	m, _ = m.Update(conflictDetailsMsg{agentID: "a1", files: []orchestrator.ConflictFile{
+		{Conflict: git.Conflict{Path: "logo.png", Binary: true, Size: 2048}},
+		{Conflict: git.Conflict{Path: "dump.sql", Size: 5 << 20}, Large: true},
+	}})
+	content := m.ViewContent()
+	for _, want := range []string{"logo.png  (binary)", "dump.sql  (large, 5.0 MB)", "t: take main's"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("conflicts view should contain %q:\n%s", want, content)
+		}
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if cmd == nil || !m.resolving {
+		t.Fatal("expected t to resolve the selected file")
+	}
+	// The test orchestrator doesn't know the agent, so resolving fails.
+	m, _ = m.Update(cmd())
+	if m.resolving || m.err == "" || m.step != mergeStepConflicts {
+		t.Errorf("resolving = %v, err = %q, step = %d; want the error shown in the conflicts view", m.resolving, m.err, m.step)
+	}
+}
+
+func TestMerge_ConflictsEdit(t *testing.T) {
+	m := newTestMerge(t)
+	m, _ = m.Update(orchestrator.MergeResultMsg{
+		AgentID:       "a1",
+		Conflict:      true,
+		ConflictFiles: []string{"logo.png", "main.go"},
+	})
+	m, _ = m.Update(conflictDetailsMsg{agentID: "a1", files: []orchestrator.ConflictFile{
+		{Conflict: git.Conflict{Path: "logo.png", Binary: true, Size: 2048}},
+	}})
+
+	edit := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}}
+	m, _ = m.Update(edit)
+	if !strings.Contains(m.err, "binary") {
+		t.Errorf("err = %q, want binary files refused", m.err)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m, _ = m.Update(edit)
+	// The test orchestrator doesn't know the agent, so opening fails.
+	if !strings.Contains(m.err, "not found") || m.step != mergeStepConflicts {
+		t.Errorf("err = %q, step = %d; want the error shown in the conflicts view", m.err, m.step)
+	}
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+// orphansModel asks whether to kill the processes still running in a
+// dismissed agent's worktree, which was kept because of them.
+type orphansModel struct {
+	orch   *orchestrator.Orchestrator
+	styles Styles
+	width  int
+
+	orphans orchestrator.OrphanProcessesMsg
+	killing bool
+	err     string
+
+	spinner spinner.Model
+}
+
+// orphansCloseMsg closes the view; kept means the processes were left
+// running.
+type orphansCloseMsg struct {
+	kept bool
+}
+
+func newOrphans(s Styles, orch *orchestrator.Orchestrator, msg orchestrator.OrphanProcessesMsg, width int) orphansModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.MiniDot
+	return orphansModel{
+		orch:    orch,
+		styles:  s,
+		width:   width,
+		orphans: msg,
+		spinner: sp,
+	}
+}
+
+func (m orphansModel) Update(msg tea.Msg) (orphansModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if m.killing {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case orchestrator.OrphansKilledMsg:
+		if msg.AgentID != m.orphans.AgentID {
+			return m, nil
+		}
+		m.killing = false
+		if msg.Error != "" {
+			m.err = msg.Error
+			return m, nil
+		}
+		return m, func() tea.Msg { return orphansCloseMsg{} }
+
+	case tea.KeyMsg:
+		if m.killing {
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc", "n":
+			return m, func() tea.Msg { return orphansCloseMsg{kept: true} }
+		case "y", "enter":
+			m.killing = true
+			m.err = ""
+			orphans := m.orphans
+			return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+				return m.orch.KillOrphans(orphans)
+			})
+		}
+	}
+	return m, nil
+}
+
+func (m orphansModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Processes Left Running"))
+	b.WriteString("\n\n")
+
+	b.WriteString(fmt.Sprintf("  Agent:       %s\n", m.orphans.AgentID))
+	b.WriteString(fmt.Sprintf("  Branch:      %s\n", m.orphans.Branch))
+	b.WriteString(fmt.Sprintf("  Worktree:    %s\n", truncate(m.orphans.WorktreePath, max(m.width/2, 30))))
+	b.WriteString("\n")
+
+	b.WriteString(m.styles.Attention.Render("  Still running in the worktree after its window was killed:"))
+	b.WriteString("\n")
+	for _, p := range m.orphans.Processes {
+		b.WriteString(fmt.Sprintf("    %d  %s\n", p.PID, p.Command))
+	}
+	b.WriteString("\n")
+	what := "the worktree"
+	if m.orphans.DeleteBranch {
+		what = "the worktree and branch"
+	}
+	b.WriteString(m.styles.WizardDim.Render("  Kill them to remove " + what + "; keep them to leave it in place."))
+	b.WriteString("\n\n")
+
+	if m.killing {
+		b.WriteString(m.styles.WizardActive.Render("  " + m.spinner.View() + " Killing..."))
+	} else {
+		b.WriteString(m.styles.Help.Render("  y/enter: kill them | n/esc: keep them"))
+	}
+
+	if m.err != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+	}
+
+	return b.String()
+}
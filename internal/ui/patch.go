@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// clipboardCommands are tried in order to read the system clipboard.
+var clipboardCommands = [][]string{
+	{"pbpaste"},
+	{"wl-paste", "--no-newline"},
+	{"xclip", "-selection", "clipboard", "-o"},
+	{"xsel", "--clipboard", "--output"},
+}
+
+// readPatch reads a patch from a file path, or from the clipboard when
+// source is empty.
+func readPatch(source string) ([]byte, error) {
+	if source == "" {
+		return readClipboard()
+	}
+	if rest, ok := strings.CutPrefix(source, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			source = filepath.Join(home, rest)
+		}
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("read patch: %w", err)
+	}
+	return data, nil
+}
+
+// readClipboard returns the clipboard contents using the first available
+// clipboard tool.
+func readClipboard() ([]byte, error) {
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("read clipboard: %w", err)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("no clipboard tool found (install pbpaste, wl-paste, xclip or xsel)")
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// reportPageSize is how many report lines are shown at once.
+const reportPageSize = 25
+
+type startReportMsg struct {
+	agentID string
+	path    string
+}
+
+type reportCloseMsg struct{}
+
+// reportModel shows a report collected from a report agent.
+type reportModel struct {
+	agentID string
+	path    string
+	lines   []string
+	offset  int
+	err     string
+	styles  Styles
+	width   int
+}
+
+func newReport(s Styles, msg startReportMsg, width int) reportModel {
+	m := reportModel{
+		agentID: msg.agentID,
+		path:    msg.path,
+		styles:  s,
+		width:   width,
+	}
+	data, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.err = err.Error()
+		return m
+	}
+	// Wrap to the panel so long paragraphs stay readable.
+	text := lipgloss.NewStyle().Width(max(width/2-8, 20)).Render(strings.TrimRight(string(data), "\n"))
+	for _, line := range strings.Split(text, "\n") {
+		m.lines = append(m.lines, strings.TrimRight(line, " "))
+	}
+	return m
+}
+
+func (m reportModel) Update(msg tea.Msg) (reportModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "esc", "q", "v":
+		return m, func() tea.Msg { return reportCloseMsg{} }
+	case "down", "j":
+		if m.offset < len(m.lines)-reportPageSize {
+			m.offset++
+		}
+	case "up", "k":
+		if m.offset > 0 {
+			m.offset--
+		}
+	case "pgdown", " ":
+		m.offset = max(min(m.offset+reportPageSize, len(m.lines)-reportPageSize), 0)
+	case "pgup":
+		m.offset = max(m.offset-reportPageSize, 0)
+	}
+	return m, nil
+}
+
+func (m reportModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Report of Agent " + m.agentID))
+	b.WriteString("\n")
+	b.WriteString(m.styles.WizardDim.Render("  " + m.path))
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+		b.WriteString("\n")
+	} else {
+		end := min(m.offset+reportPageSize, len(m.lines))
+		for _, line := range m.lines[m.offset:end] {
+			b.WriteString("  " + line)
+			b.WriteString("\n")
+		}
+		if len(m.lines) > reportPageSize {
+			b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("  lines %d–%d of %d", m.offset+1, end, len(m.lines))))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.Help.Render("  j/k: scroll │ space/pgup: page │ esc: close"))
+	return b.String()
+}
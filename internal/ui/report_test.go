@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/config"
+)
+
+func TestReport_ReadsAndScrolls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "research-x.md")
+	var lines []string
+	for i := 1; i <= 40; i++ {
+		lines = append(lines, fmt.Sprintf("finding %d", i))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newReport(NewStyles(config.Default().Colors), startReportMsg{agentID: "a1", path: path}, 120)
+	view := m.ViewContent()
+	if !strings.Contains(view, "finding 1\n") || strings.Contains(view, "finding 26") {
+		t.Errorf("first page should show lines 1-25:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{' '}})
+	view = m.ViewContent()
+	if m.offset != 15 || !strings.Contains(view, "finding 40") {
+		t.Errorf("offset = %d, page down should reach the end:\n%s", m.offset, view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("esc should close the report")
+	}
+	if _, ok := cmd().(reportCloseMsg); !ok {
+		t.Error("esc should send reportCloseMsg")
+	}
+}
+
+func TestReport_MissingFile(t *testing.T) {
+	m := newReport(NewStyles(config.Default().Colors), startReportMsg{agentID: "a1", path: filepath.Join(t.TempDir(), "gone.md")}, 120)
+	if !strings.Contains(m.ViewContent(), "Error:") {
+		t.Error("a missing report should show an error")
+	}
+}
@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+type startRollbackMsg struct {
+	agentID     string
+	branch      string
+	checkpoints []git.Checkpoint
+	err         string
+}
+
+type rollbackDoneMsg struct {
+	agentID string
+	tag     string
+	saved   string
+}
+type rollbackCancelMsg struct{}
+type rollbackErrorMsg struct {
+	err string
+}
+
+// rollbackModel lists an agent's checkpoints, newest first, and rolls the
+// agent back to the chosen one after confirmation.
+type rollbackModel struct {
+	orch   *orchestrator.Orchestrator
+	err    string
+	width  int
+	styles Styles
+
+	agentID     string
+	branch      string
+	checkpoints []git.Checkpoint
+	cursor      int
+	confirming  bool
+	rolling     bool
+
+	spinner spinner.Model
+}
+
+func newRollback(s Styles, orch *orchestrator.Orchestrator, msg startRollbackMsg, width int) rollbackModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.MiniDot
+	return rollbackModel{
+		orch:        orch,
+		err:         msg.err,
+		agentID:     msg.agentID,
+		branch:      msg.branch,
+		checkpoints: msg.checkpoints,
+		styles:      s,
+		width:       width,
+		spinner:     sp,
+	}
+}
+
+func (m rollbackModel) Update(msg tea.Msg) (rollbackModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if m.rolling {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case rollbackErrorMsg:
+		m.rolling = false
+		m.confirming = false
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.rolling {
+			return m, nil
+		}
+
+		if m.confirming {
+			switch msg.String() {
+			case "y":
+				m.rolling = true
+				m.err = ""
+				id, tag := m.agentID, m.checkpoints[m.cursor].Tag
+				return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+					res := m.orch.RollbackAgent(id, tag)
+					if res.Error != "" {
+						if res.Saved != "" {
+							return rollbackErrorMsg{err: fmt.Sprintf("%s (state before rollback saved as %s)", res.Error, res.Saved)}
+						}
+						return rollbackErrorMsg{err: res.Error}
+					}
+					return rollbackDoneMsg{agentID: id, tag: tag, saved: res.Saved}
+				})
+			case "n", "esc":
+				m.confirming = false
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "esc", "q":
+			return m, func() tea.Msg { return rollbackCancelMsg{} }
+		case "down", "j":
+			if m.cursor < len(m.checkpoints)-1 {
+				m.cursor++
+			}
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "enter":
+			if len(m.checkpoints) > 0 {
+				m.confirming = true
+				m.err = ""
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m rollbackModel) ViewContent() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.WizardTitle.Render("Roll Back Agent " + m.agentID))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("  Branch:    %s\n\n", m.branch))
+
+	if len(m.checkpoints) == 0 {
+		if m.err == "" {
+			b.WriteString(m.styles.WizardDim.Render("  No checkpoints — press S on the dashboard to create one"))
+			b.WriteString("\n\n")
+		}
+		b.WriteString(m.styles.Help.Render("  esc: close"))
+		m.writeError(&b)
+		return b.String()
+	}
+
+	textWidth := max(m.width/2-8, 20)
+	for i, cp := range m.checkpoints {
+		line := fmt.Sprintf("%s  %s", cp.Created.Format("Jan 02 15:04:05"), truncate(cp.Label, textWidth-17))
+		if i == m.cursor {
+			b.WriteString(m.styles.WizardActive.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case m.rolling:
+		b.WriteString(m.styles.WizardActive.Render("  " + m.spinner.View() + " Rolling back..."))
+	case m.confirming:
+		cp := m.checkpoints[m.cursor]
+		b.WriteString(fmt.Sprintf("  Roll %s back to %s?\n", m.branch, cp.Tag))
+		b.WriteString(m.styles.WizardDim.Render("  Later commits and changes are discarded from the worktree;"))
+		b.WriteString("\n")
+		b.WriteString(m.styles.WizardDim.Render("  the current state is saved as a checkpoint first."))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  y: roll back │ n/esc: back"))
+	default:
+		b.WriteString(m.styles.Help.Render("  ↑/↓: select │ enter: roll back │ esc: cancel"))
+	}
+
+	m.writeError(&b)
+	return b.String()
+}
+
+func (m rollbackModel) writeError(b *strings.Builder) {
+	if m.err != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+	}
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+)
+
+func TestRollback_SelectAndConfirm(t *testing.T) {
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir())
+	now := time.Now()
+	m := newRollback(NewStyles(config.Default().Colors), orch, startRollbackMsg{
+		agentID: "a1",
+		branch:  "feat/x",
+		checkpoints: []git.Checkpoint{
+			{Tag: "checkpoint/feat/x/2", Label: "tests pass", Created: now},
+			{Tag: "checkpoint/feat/x/1", Label: "checkpoint of feat/x", Created: now.Add(-time.Hour)},
+		},
+	}, 120)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.confirming {
+		t.Fatal("enter should ask for confirmation")
+	}
+	if view := m.ViewContent(); !strings.Contains(view, "back to checkpoint/feat/x/1?") || !strings.Contains(view, "saved as a checkpoint") {
+		t.Errorf("confirmation view:\n%s", view)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.confirming || cmd != nil {
+		t.Error("n should return to the list without rolling back")
+	}
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("esc should cancel")
+	}
+	if _, ok := cmd().(rollbackCancelMsg); !ok {
+		t.Error("expected rollbackCancelMsg")
+	}
+}
+
+func TestRollback_NoCheckpoints(t *testing.T) {
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir())
+	m := newRollback(NewStyles(config.Default().Colors), orch, startRollbackMsg{agentID: "a1", branch: "feat/x"}, 120)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.confirming {
+		t.Error("nothing to confirm without checkpoints")
+	}
+	if !strings.Contains(m.ViewContent(), "No checkpoints") {
+		t.Errorf("view:\n%s", m.ViewContent())
+	}
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/exp/golden"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/muesli/termenv"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// Snapshot tests compare rendered views with the golden files in testdata.
+// After an intended change to the UI, regenerate them with
+//
+//	go test ./internal/ui -run Snapshot -update
+
+func init() {
+	// Golden files hold plain text whatever terminal runs the tests.
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// newSnapshotApp returns an app with agents whose rendering does not
+// depend on the clock, as long as the test takes less than a minute.
+func newSnapshotApp(t *testing.T) AppModel {
+	t.Helper()
+	m := newTestApp(t)
+
+	waiting := agent.NewAgent("feat/login", "main", "/wt/login", "@1", "%1", "claude")
+	waiting.ID = "a1"
+	waiting.SetStatus(agent.StatusWaiting)
+	waiting.SetWaitingFor("permission")
+	waiting.SetDurationState(4*time.Minute+12*time.Second, time.Time{})
+	waiting.StartedAt = time.Now().Add(-2 * time.Hour)
+
+	done := agent.NewAgent("fix/crash", "main", "/wt/crash", "@2", "%2", "opencode")
+	done.ID = "a2"
+	done.SetStatus(agent.StatusReviewReady)
+	done.SetDurationState(11*time.Minute+3*time.Second, time.Time{})
+	done.StartedAt = time.Now().Add(-3 * 24 * time.Hour)
+	done.SetReadyAt(time.Now().Add(-15 * time.Minute))
+
+	m.store.Add(waiting)
+	m.store.Add(done)
+	return m
+}
+
+func TestSnapshot_Dashboard(t *testing.T) {
+	golden.RequireEqual(t, []byte(Screenshot(newSnapshotApp(t), 120, 40)))
+}
+
+func TestSnapshot_DashboardNarrow(t *testing.T) {
+	golden.RequireEqual(t, []byte(Screenshot(newSnapshotApp(t), 70, 30)))
+}
+
+func TestSnapshot_SortThenQuit(t *testing.T) {
+	tm := teatest.NewTestModel(t, newSnapshotApp(t), teatest.WithInitialTermSize(120, 40))
+	teatest.WaitFor(t, tm.Output(), func(b []byte) bool {
+		return bytes.Contains(b, []byte("fix/crash"))
+	}, teatest.WithDuration(3*time.Second))
+
+	tm.Type("s")
+	tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	tm.Type("q")
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+
+	golden.RequireEqual(t, []byte(tm.FinalModel(t).View()))
+}
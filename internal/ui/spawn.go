@@ -1,17 +1,23 @@
 package ui
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/simonbystrom/mastermind/internal/ci"
 	"github.com/simonbystrom/mastermind/internal/git"
 	"github.com/simonbystrom/mastermind/internal/harness"
 	"github.com/simonbystrom/mastermind/internal/orchestrator"
+	"github.com/simonbystrom/mastermind/internal/playbook"
+	"github.com/simonbystrom/mastermind/internal/ticket"
 )
 
 type spawnStep int
@@ -19,9 +25,14 @@ type spawnStep int
 const (
 	stepChooseHarness spawnStep = iota
 	stepChooseMode
+	stepPatchSource
+	stepTeamTask
+	stepPickRun
+	stepPickPlaybook
 	stepPickBranch
 	stepNewBranchName
 	stepConfirm
+	stepTask
 )
 
 type spawnMode int
@@ -29,24 +40,69 @@ type spawnMode int
 const (
 	modeExisting spawnMode = iota
 	modeNew
+	modePatch
+	modeCI
+	modePlaybook
+	modeTeam
 )
 
 // branchItem implements list.DefaultItem for the branch picker list.
 type branchItem struct {
 	name    string
 	current bool
+	agentID string // set when an agent works on this branch
+	// worktree is set when the branch has a leftover worktree to reuse
+	worktree bool
 }
 
 func (b branchItem) Title() string {
+	title := b.name
 	if b.current {
-		return b.name + " (current)"
+		title += " (current)"
 	}
-	return b.name
+	if b.agentID != "" {
+		title += fmt.Sprintf(" (agent %s)", b.agentID)
+	}
+	if b.worktree {
+		title += " (existing worktree)"
+	}
+	return title
 }
 
 func (b branchItem) Description() string { return "" }
 func (b branchItem) FilterValue() string { return b.name }
 
+// runItem implements list.DefaultItem for the failed CI run picker.
+type runItem struct {
+	run ci.Run
+}
+
+func (r runItem) Title() string {
+	return fmt.Sprintf("%s · %s — %s", r.run.Workflow, r.run.Branch, r.run.Title)
+}
+
+func (r runItem) Description() string { return "" }
+func (r runItem) FilterValue() string {
+	return r.run.Workflow + " " + r.run.Branch + " " + r.run.Title
+}
+
+// playbookItem implements list.DefaultItem for the playbook picker.
+type playbookItem struct {
+	playbook playbook.Playbook
+}
+
+func (p playbookItem) Title() string {
+	if p.playbook.Description == "" {
+		return p.playbook.Name
+	}
+	return p.playbook.Name + " — " + p.playbook.Description
+}
+
+func (p playbookItem) Description() string { return "" }
+func (p playbookItem) FilterValue() string {
+	return p.playbook.Name + " " + p.playbook.Description
+}
+
 type spawnModel struct {
 	orch            *orchestrator.Orchestrator
 	repoPath        string
@@ -69,8 +125,62 @@ type spawnModel struct {
 	checkedOutBranches map[string]bool
 	branchList         list.Model
 
-	// New branch name input
-	branchInput textinput.Model
+	// Leftover worktrees by branch, offered for reuse in existing branch
+	// mode. reusePath is the one picked; reuseErr says why it cannot be
+	// reused and reuseDirty whether it has uncommitted changes.
+	leftoverWorktrees map[string]string
+	reusePath         string
+	reuseErr          string
+	reuseDirty        bool
+
+	// New branch name input, and a valid name offered when the typed one
+	// is rejected
+	branchInput      textinput.Model
+	branchSuggestion string
+
+	// Patch source input (file path; empty reads the clipboard)
+	patchInput  textinput.Model
+	patch       []byte
+	patchSource string
+
+	// Ticket typed into the branch name input; its branch name is
+	// generated from the title once looked up.
+	ticket        string
+	ticketTitle   string
+	ticketLoading bool
+
+	// Agent team task input
+	teamInput textinput.Model
+	teamTask  string
+
+	// Task typed into the agent's pane once it starts, edited from the
+	// confirm step of the modes that have no prompt of their own
+	taskInput textarea.Model
+	task      string
+
+	// Failed CI run picker
+	runList     list.Model
+	runsLoading bool
+	logsLoading bool
+	run         ci.Run
+
+	// Playbook picker
+	playbookList list.Model
+	playbook     playbook.Playbook
+
+	// Target tmux session, cycled on the confirm step. sessions is loaded
+	// on first use; index 0 is mastermind's own session.
+	sessions   []string
+	sessionIdx int
+
+	// readOnly spawns a research agent and report additionally collects
+	// its report on finish; both are cycled on the confirm step.
+	readOnly bool
+	report   bool
+
+	// fetchBase fast-forwards a new branch's base from origin first,
+	// toggled on the confirm step.
+	fetchBase bool
 
 	// Computed
 	baseBranch   string
@@ -78,6 +188,12 @@ type spawnModel struct {
 	createBranch bool
 }
 
+type ticketLoadedMsg struct {
+	id    string
+	title string
+	err   error
+}
+
 type spawnDoneMsg struct{}
 type spawnCancelMsg struct{}
 
@@ -85,6 +201,21 @@ func newSpawn(s Styles, orch *orchestrator.Orchestrator, repoPath string, width
 	bi := textinput.New()
 	bi.Placeholder = "new branch name (e.g. feat/my-feature)"
 
+	pi := textinput.New()
+	pi.Placeholder = "path to .patch/.diff file (empty: clipboard)"
+
+	ti := textinput.New()
+	ti.Placeholder = "what the team should build"
+	ti.CharLimit = 0
+
+	ta := textarea.New()
+	ta.Placeholder = "what the agent should do (sent once it starts)"
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.SetWidth(max(width-12, 20))
+	ta.SetHeight(6)
+	ta.KeyMap.InsertNewline.SetKeys("enter", "ctrl+j")
+
 	delegate := list.NewDefaultDelegate()
 	delegate.ShowDescription = false
 	delegate.SetHeight(1)
@@ -100,30 +231,41 @@ func newSpawn(s Styles, orch *orchestrator.Orchestrator, repoPath string, width
 		Padding(0, 0, 0, 2)
 
 	listWidth := max(width-8, 20)
-	bl := list.New([]list.Item{}, delegate, listWidth, 15)
-	bl.SetShowTitle(false)
-	bl.SetShowStatusBar(false)
-	bl.SetShowHelp(false)
-	bl.SetFilteringEnabled(true)
-	bl.DisableQuitKeybindings()
-	bl.KeyMap.ShowFullHelp.SetEnabled(false)
-	bl.KeyMap.CloseFullHelp.SetEnabled(false)
-	bl.FilterInput.Prompt = "Filter: "
-	bl.FilterInput.PromptStyle = s.WizardActive
 
 	return spawnModel{
 		orch:            orch,
 		repoPath:        repoPath,
 		step:            stepChooseHarness,
 		branchInput:     bi,
-		branchList:      bl,
+		patchInput:      pi,
+		teamInput:       ti,
+		taskInput:       ta,
+		branchList:      newPickerList(s, delegate, listWidth),
+		runList:         newPickerList(s, delegate, listWidth),
+		playbookList:    newPickerList(s, delegate, listWidth),
 		styles:          s,
 		width:           width,
 		defaultHarness:  defaultHarness,
 		selectedHarness: defaultHarness,
+		fetchBase:       orch.FetchesBase(),
 	}
 }
 
+// newPickerList returns a filterable single-line list for wizard pickers.
+func newPickerList(s Styles, delegate list.DefaultDelegate, width int) list.Model {
+	l := list.New([]list.Item{}, delegate, width, 15)
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(true)
+	l.DisableQuitKeybindings()
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+	l.FilterInput.Prompt = "Filter: "
+	l.FilterInput.PromptStyle = s.WizardActive
+	return l
+}
+
 func (m spawnModel) Init() tea.Cmd {
 	return m.loadBranches()
 }
@@ -140,13 +282,49 @@ func (m spawnModel) loadBranches() tea.Cmd {
 	}
 }
 
+type runsLoadedMsg struct {
+	runs []ci.Run
+	err  error
+}
+
+func (m spawnModel) loadFailedRuns() tea.Cmd {
+	return func() tea.Msg {
+		runs, err := m.orch.FailedRuns(30)
+		return runsLoadedMsg{runs: runs, err: err}
+	}
+}
+
+type ciLogsLoadedMsg struct {
+	logs string
+	err  error
+}
+
+func (m spawnModel) loadFailedLogs(runID int64) tea.Cmd {
+	orch := m.orch
+	return func() tea.Msg {
+		logs, err := orch.FailedLogs(runID)
+		return ciLogsLoadedMsg{logs: logs, err: err}
+	}
+}
+
+func (m spawnModel) lookupTicket(id string) tea.Cmd {
+	orch := m.orch
+	return func() tea.Msg {
+		title, err := orch.TicketTitle(id)
+		return ticketLoadedMsg{id: id, title: title, err: err}
+	}
+}
+
 func (m *spawnModel) setBranchListItems() tea.Cmd {
 	var items []list.Item
+	agentBranches := m.orch.AgentBranches()
 	for _, b := range m.branches {
-		if m.mode == modeExisting && m.checkedOutBranches[b.Name] {
+		_, leftover := m.leftoverWorktrees[b.Name]
+		reusable := m.mode == modeExisting && leftover && agentBranches[b.Name] == ""
+		if m.mode == modeExisting && m.checkedOutBranches[b.Name] && !reusable {
 			continue
 		}
-		items = append(items, branchItem{name: b.Name, current: b.Current})
+		items = append(items, branchItem{name: b.Name, current: b.Current, agentID: agentBranches[b.Name], worktree: reusable})
 	}
 	cmd := m.branchList.SetItems(items)
 	m.branchList.ResetFilter()
@@ -170,6 +348,47 @@ func (m spawnModel) Update(msg tea.Msg) (spawnModel, tea.Cmd) {
 				}
 			}
 		}
+		m.leftoverWorktrees = m.orch.LeftoverWorktrees()
+		return m, nil
+
+	case runsLoadedMsg:
+		m.runsLoading = false
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		if len(msg.runs) == 0 {
+			m.err = "no failed workflow runs found"
+		}
+		items := make([]list.Item, len(msg.runs))
+		for i, r := range msg.runs {
+			items[i] = runItem{run: r}
+		}
+		cmd := m.runList.SetItems(items)
+		m.runList.Select(0)
+		return m, cmd
+
+	case ciLogsLoadedMsg:
+		m.logsLoading = false
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		if err := m.orch.SpawnAgentFromCI(m.run, msg.logs, m.selectedHarness, m.spawnOptions()...); err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		return m, func() tea.Msg { return spawnDoneMsg{} }
+
+	case ticketLoadedMsg:
+		m.ticketLoading = false
+		m.ticket = msg.id
+		m.ticketTitle = msg.title
+		if msg.err != nil {
+			m.err = fmt.Sprintf("could not look up %s: %v", msg.id, msg.err)
+		}
+		m.branchInput.SetValue(ticket.BranchName(msg.id, msg.title))
+		m.branchInput.CursorEnd()
 		return m, nil
 
 	case tea.KeyMsg:
@@ -180,9 +399,21 @@ func (m spawnModel) Update(msg tea.Msg) (spawnModel, tea.Cmd) {
 			if m.step == stepPickBranch && (m.branchList.SettingFilter() || m.branchList.IsFiltered()) {
 				return m.updatePickBranch(msg)
 			}
+			if m.step == stepPickRun && (m.runList.SettingFilter() || m.runList.IsFiltered()) {
+				return m.updatePickRun(msg)
+			}
+			if m.step == stepPickPlaybook && (m.playbookList.SettingFilter() || m.playbookList.IsFiltered()) {
+				return m.updatePickPlaybook(msg)
+			}
 			if m.step == stepChooseHarness {
 				return m, func() tea.Msg { return spawnCancelMsg{} }
 			}
+			// Discard the task edit
+			if m.step == stepTask {
+				m.taskInput.Blur()
+				m.step = stepConfirm
+				return m, nil
+			}
 			// Go back one step
 			if m.step == stepChooseMode {
 				m.step = stepChooseHarness
@@ -192,6 +423,13 @@ func (m spawnModel) Update(msg tea.Msg) (spawnModel, tea.Cmd) {
 			m.branchList.ResetFilter()
 			m.branchList.Select(0)
 			m.branchInput.SetValue("")
+			m.patchInput.SetValue("")
+			m.patch = nil
+			m.teamInput.SetValue("")
+			m.teamTask = ""
+			m.task = ""
+			m.ticket, m.ticketTitle = "", ""
+			m.playbook = playbook.Playbook{}
 			return m, nil
 		}
 
@@ -200,12 +438,22 @@ func (m spawnModel) Update(msg tea.Msg) (spawnModel, tea.Cmd) {
 			return m.updateChooseHarness(msg)
 		case stepChooseMode:
 			return m.updateChooseMode(msg)
+		case stepPatchSource:
+			return m.updatePatchSource(msg)
+		case stepTeamTask:
+			return m.updateTeamTask(msg)
+		case stepPickRun:
+			return m.updatePickRun(msg)
+		case stepPickPlaybook:
+			return m.updatePickPlaybook(msg)
 		case stepPickBranch:
 			return m.updatePickBranch(msg)
 		case stepNewBranchName:
 			return m.updateNewBranchName(msg)
 		case stepConfirm:
 			return m.updateConfirm(msg)
+		case stepTask:
+			return m.updateTask(msg)
 		}
 	}
 
@@ -219,16 +467,11 @@ func (m spawnModel) updateChooseHarness(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
 			m.harnessCursor--
 		}
 	case "down", "j":
-		if m.harnessCursor < 1 {
+		if m.harnessCursor < len(m.orch.Harnesses())-1 {
 			m.harnessCursor++
 		}
 	case "enter":
-		// Set selected harness based on cursor
-		if m.harnessCursor == 0 {
-			m.selectedHarness = harness.TypeClaudeCode
-		} else {
-			m.selectedHarness = harness.TypeOpenCode
-		}
+		m.selectedHarness = m.orch.Harnesses()[m.harnessCursor]
 		m.step = stepChooseMode
 		return m, nil
 	}
@@ -242,15 +485,52 @@ func (m spawnModel) updateChooseMode(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
 			m.modeCursor--
 		}
 	case "down", "j":
-		if m.modeCursor < 1 {
+		if m.modeCursor < 5 {
 			m.modeCursor++
 		}
 	case "enter":
-		if m.modeCursor == 0 {
+		switch m.modeCursor {
+		case 0:
 			m.mode = modeExisting
 			m.step = stepPickBranch
 			cmd := m.setBranchListItems()
 			return m, cmd
+		case 2:
+			m.mode = modePatch
+			m.step = stepPatchSource
+			m.patchInput.Focus()
+			return m, textinput.Blink
+		case 3:
+			m.mode = modeCI
+			m.step = stepPickRun
+			m.runsLoading = true
+			return m, m.loadFailedRuns()
+		case 4:
+			m.mode = modePlaybook
+			m.step = stepPickPlaybook
+			playbooks, err := m.orch.Playbooks()
+			if err != nil {
+				m.err = err.Error()
+			} else if len(playbooks) == 0 {
+				m.err = "no playbooks in " + m.orch.PlaybooksDir()
+			}
+			items := make([]list.Item, len(playbooks))
+			for i, p := range playbooks {
+				items[i] = playbookItem{playbook: p}
+			}
+			cmd := m.playbookList.SetItems(items)
+			m.playbookList.ResetFilter()
+			m.playbookList.Select(0)
+			return m, cmd
+		case 5:
+			if m.selectedHarness != harness.TypeClaudeCode {
+				m.err = "agent teams need Claude Code"
+				return m, nil
+			}
+			m.mode = modeTeam
+			m.step = stepTeamTask
+			m.teamInput.Focus()
+			return m, textinput.Blink
 		}
 		m.mode = modeNew
 		m.step = stepNewBranchName
@@ -260,6 +540,97 @@ func (m spawnModel) updateChooseMode(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
 	return m, nil
 }
 
+func (m spawnModel) updatePatchSource(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		source := strings.TrimSpace(m.patchInput.Value())
+		patch, err := readPatch(source)
+		if err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		if len(bytes.TrimSpace(patch)) == 0 {
+			m.err = "patch is empty"
+			return m, nil
+		}
+		m.patch = patch
+		m.patchSource = source
+		if source == "" {
+			m.patchSource = "clipboard"
+		}
+		m.step = stepNewBranchName
+		m.branchInput.Focus()
+		return m, textinput.Blink
+	default:
+		var cmd tea.Cmd
+		m.patchInput, cmd = m.patchInput.Update(msg)
+		return m, cmd
+	}
+}
+
+func (m spawnModel) updateTeamTask(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		task := strings.TrimSpace(m.teamInput.Value())
+		if task == "" {
+			m.err = "team task is required"
+			return m, nil
+		}
+		m.teamTask = task
+		m.step = stepNewBranchName
+		m.branchInput.Focus()
+		return m, textinput.Blink
+	default:
+		var cmd tea.Cmd
+		m.teamInput, cmd = m.teamInput.Update(msg)
+		return m, cmd
+	}
+}
+
+func (m spawnModel) updatePickRun(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
+	wasFiltering := m.runList.SettingFilter()
+
+	var cmd tea.Cmd
+	m.runList, cmd = m.runList.Update(msg)
+
+	if msg.String() == "enter" && !wasFiltering && !m.runList.SettingFilter() {
+		item := m.runList.SelectedItem()
+		if item == nil {
+			return m, cmd
+		}
+		m.run = item.(runItem).run
+		m.branch = m.run.Branch
+		m.baseBranch = ""
+		m.createBranch = false
+		m.step = stepConfirm
+		return m, nil
+	}
+
+	return m, cmd
+}
+
+func (m spawnModel) updatePickPlaybook(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
+	wasFiltering := m.playbookList.SettingFilter()
+
+	var cmd tea.Cmd
+	m.playbookList, cmd = m.playbookList.Update(msg)
+
+	if msg.String() == "enter" && !wasFiltering && !m.playbookList.SettingFilter() {
+		item := m.playbookList.SelectedItem()
+		if item == nil {
+			return m, cmd
+		}
+		m.playbook = item.(playbookItem).playbook
+		m.branchInput.SetValue(m.playbook.BranchName(time.Now()))
+		m.branchInput.CursorEnd()
+		m.branchInput.Focus()
+		m.step = stepNewBranchName
+		return m, textinput.Blink
+	}
+
+	return m, cmd
+}
+
 func (m spawnModel) updatePickBranch(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
 	wasFiltering := m.branchList.SettingFilter()
 
@@ -279,6 +650,19 @@ func (m spawnModel) updatePickBranch(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
 			m.branch = selected.name
 			m.baseBranch = ""
 			m.createBranch = false
+			m.reusePath, m.reuseErr, m.reuseDirty = "", "", false
+			if selected.worktree {
+				path, err := m.orch.ReusableWorktree(selected.name)
+				switch {
+				case err != nil:
+					m.reuseErr = err.Error()
+				case path == "":
+					m.reuseErr = "the worktree is gone"
+				default:
+					m.reusePath = path
+					m.reuseDirty = git.HasChanges(path)
+				}
+			}
 			m.step = stepConfirm
 			return m, nil
 		}
@@ -294,12 +678,33 @@ func (m spawnModel) updatePickBranch(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
 
 func (m spawnModel) updateNewBranchName(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
 	switch msg.String() {
+	case "tab":
+		if m.branchSuggestion != "" {
+			m.branchInput.SetValue(m.branchSuggestion)
+			m.branchInput.CursorEnd()
+			m.branchSuggestion = ""
+			m.err = ""
+		}
+		return m, nil
 	case "enter":
 		name := strings.TrimSpace(m.branchInput.Value())
 		if name == "" {
 			m.err = "branch name is required"
 			return m, nil
 		}
+		if m.ticketLoading {
+			return m, nil
+		}
+		// An upper-case ticket ID generates the branch name instead.
+		if id, err := ticket.Parse(name); err == nil && name == strings.ToUpper(name) {
+			m.ticketLoading = true
+			return m, m.lookupTicket(id)
+		}
+		if err := git.ValidateBranchName(name); err != nil {
+			m.err = "invalid branch name: " + err.Error()
+			m.branchSuggestion = git.SanitizeBranchName(name)
+			return m, nil
+		}
 		if git.BranchExists(m.repoPath, name) {
 			m.err = fmt.Sprintf("branch %q already exists — use existing branch mode", name)
 			return m, nil
@@ -307,24 +712,150 @@ func (m spawnModel) updateNewBranchName(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
 		m.branch = name
 		m.step = stepPickBranch
 		cmd := m.setBranchListItems()
+		// A playbook with a base skips the base picker.
+		if m.mode == modePlaybook && m.playbook.Base != "" {
+			m.baseBranch = m.playbook.Base
+			m.createBranch = true
+			m.step = stepConfirm
+		}
 		return m, cmd
 	default:
+		m.branchSuggestion = ""
 		var cmd tea.Cmd
 		m.branchInput, cmd = m.branchInput.Update(msg)
 		return m, cmd
 	}
 }
 
+// takesTask reports whether the agent can be given a task to type into
+// its pane; the other modes start the agent with a prompt of their own.
+func (m spawnModel) takesTask() bool {
+	return m.mode == modeExisting || m.mode == modeNew
+}
+
+// branchesFromBase reports whether the agent's branch is created from the
+// base branch, which can be fast-forwarded from origin first.
+func (m spawnModel) branchesFromBase() bool {
+	return m.createBranch && m.mode != modeCI
+}
+
+func (m spawnModel) updateTask(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+s":
+		m.task = strings.TrimSpace(m.taskInput.Value())
+		m.taskInput.Blur()
+		m.step = stepConfirm
+		return m, nil
+	default:
+		var cmd tea.Cmd
+		m.taskInput, cmd = m.taskInput.Update(msg)
+		return m, cmd
+	}
+}
+
+// sessionName returns the tmux session the agent will be spawned into.
+func (m spawnModel) sessionName() string {
+	if m.sessions == nil {
+		return m.orch.SessionName()
+	}
+	return m.sessions[m.sessionIdx]
+}
+
+// spawnOptions returns the options chosen in the wizard for the spawn.
+func (m spawnModel) spawnOptions() []orchestrator.SpawnOption {
+	var opts []orchestrator.SpawnOption
+	if m.reusePath != "" {
+		opts = append(opts, orchestrator.ReuseWorktree())
+	}
+	if m.sessionIdx > 0 {
+		opts = append(opts, orchestrator.InSession(m.sessionName()))
+	}
+	if m.ticket != "" {
+		opts = append(opts, orchestrator.WithTicket(m.ticket, m.ticketTitle))
+	}
+	switch {
+	case m.report:
+		opts = append(opts, orchestrator.Report())
+	case m.readOnly:
+		opts = append(opts, orchestrator.ReadOnly())
+	}
+	if m.mode == modePlaybook {
+		opts = append(opts, orchestrator.FromPlaybook(m.playbook))
+	}
+	if m.mode == modeTeam {
+		opts = append(opts, orchestrator.AsTeam(m.teamTask))
+	}
+	if m.task != "" && m.takesTask() {
+		opts = append(opts, orchestrator.WithTask(m.task))
+	}
+	if m.branchesFromBase() {
+		opts = append(opts, orchestrator.FetchBase(m.fetchBase))
+	}
+	return opts
+}
+
 func (m spawnModel) updateConfirm(msg tea.KeyMsg) (spawnModel, tea.Cmd) {
+	if m.logsLoading {
+		return m, nil
+	}
 	switch msg.String() {
+	case "s":
+		if m.sessions == nil {
+			m.sessions = m.orch.Sessions()
+		}
+		m.sessionIdx = (m.sessionIdx + 1) % len(m.sessions)
+		return m, nil
+	case "t":
+		if !m.takesTask() {
+			return m, nil
+		}
+		m.taskInput.SetValue(m.task)
+		m.step = stepTask
+		return m, m.taskInput.Focus()
+	case "r":
+		// off → read-only → report → off
+		switch {
+		case m.report:
+			m.readOnly, m.report = false, false
+		case m.readOnly:
+			m.report = true
+		default:
+			m.readOnly = true
+		}
+		return m, nil
+	case "f":
+		if m.branchesFromBase() {
+			m.fetchBase = !m.fetchBase
+		}
+		return m, nil
 	case "y", "enter":
-		err := m.orch.SpawnAgent(m.branch, m.baseBranch, m.createBranch, m.selectedHarness)
+		if m.reuseErr != "" {
+			m.err = "cannot reuse worktree: " + m.reuseErr
+			return m, nil
+		}
+		opts := m.spawnOptions()
+		var err error
+		switch m.mode {
+		case modePatch:
+			err = m.orch.SpawnAgentFromPatch(m.branch, m.baseBranch, m.patch, m.selectedHarness, opts...)
+		case modeCI:
+			// The run's logs are downloaded first; the spawn follows on
+			// ciLogsLoadedMsg.
+			m.logsLoading = true
+			return m, m.loadFailedLogs(m.run.ID)
+		default:
+			err = m.orch.SpawnAgent(m.branch, m.baseBranch, m.createBranch, m.selectedHarness, opts...)
+		}
 		if err != nil {
 			m.err = err.Error()
 			return m, nil
 		}
 		return m, func() tea.Msg { return spawnDoneMsg{} }
 	case "n":
+		if m.mode == modeCI {
+			m.step = stepPickRun
+			return m, nil
+		}
 		m.step = stepPickBranch
 		return m, nil
 	}
@@ -342,14 +873,7 @@ func (m spawnModel) ViewContent() string {
 		b.WriteString(m.styles.WizardActive.Render("Choose AI coding assistant"))
 		b.WriteString("\n\n")
 
-		options := []struct {
-			label string
-			desc  string
-		}{
-			{"Claude Code", "Use Anthropic's Claude Code CLI"},
-			{"OpenCode", "Use OpenCode (requires: opencode CLI installed)"},
-		}
-		for i, opt := range options {
+		for i, opt := range harnessOptions(m.orch.Harnesses()) {
 			cursor := "  "
 			if i == m.harnessCursor {
 				cursor = "> "
@@ -379,6 +903,10 @@ func (m spawnModel) ViewContent() string {
 		}{
 			{"Use existing branch", "Check out an existing branch into a new worktree"},
 			{"Create new branch", "Create a new branch from a base branch"},
+			{"From patch", "Apply a patch file or clipboard diff to a new branch and have the agent finish it"},
+			{"Fix failing CI run", "Pick a failed GitHub Actions run and hand its logs to an agent (requires: gh)"},
+			{"From playbook", "Run a task playbook: its prompt, base, checks and merge policy"},
+			{"Spawn agent team", "Start a lead that forms a Claude Code agent team for a task (Claude Code only)"},
 		}
 		for i, opt := range options {
 			cursor := "  "
@@ -408,33 +936,154 @@ func (m spawnModel) ViewContent() string {
 		} else {
 			b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("New branch: %s", m.branch)))
 			b.WriteString("\n")
-			b.WriteString(m.styles.WizardActive.Render("Pick base branch to create from"))
+			b.WriteString(m.styles.WizardActive.Render("Pick base branch to create from (pick an agent's branch to stack on it)"))
 		}
 		b.WriteString("\n\n")
 		b.WriteString(m.branchList.View())
 		b.WriteString("\n")
 		b.WriteString(m.styles.Help.Render("  /: filter │ enter: select │ esc: back"))
 
+	case stepPatchSource:
+		b.WriteString(m.styles.WizardDim.Render("Mode: From patch"))
+		b.WriteString("\n")
+		b.WriteString(m.styles.WizardActive.Render("Enter patch file (leave empty to use the clipboard)"))
+		b.WriteString("\n\n")
+		b.WriteString("  " + m.patchInput.View())
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  enter: continue │ esc: back"))
+
+	case stepTeamTask:
+		b.WriteString(m.styles.WizardDim.Render("Mode: Spawn agent team"))
+		b.WriteString("\n")
+		b.WriteString(m.styles.WizardActive.Render("Describe the task the team should work on"))
+		b.WriteString("\n\n")
+		b.WriteString("  " + m.teamInput.View())
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  enter: continue │ esc: back"))
+
+	case stepPickRun:
+		b.WriteString(m.styles.WizardDim.Render("Mode: Fix failing CI run"))
+		b.WriteString("\n")
+		b.WriteString(m.styles.WizardActive.Render("Pick a failed workflow run"))
+		b.WriteString("\n\n")
+		if m.runsLoading {
+			b.WriteString(m.styles.WizardDim.Render("  Loading runs from gh…"))
+		} else {
+			b.WriteString(m.runList.View())
+		}
+		b.WriteString("\n")
+		b.WriteString(m.styles.Help.Render("  /: filter │ enter: select │ esc: back"))
+
+	case stepPickPlaybook:
+		b.WriteString(m.styles.WizardDim.Render("Mode: From playbook"))
+		b.WriteString("\n")
+		b.WriteString(m.styles.WizardActive.Render("Pick a playbook from " + m.orch.PlaybooksDir()))
+		b.WriteString("\n\n")
+		b.WriteString(m.playbookList.View())
+		b.WriteString("\n")
+		b.WriteString(m.styles.Help.Render("  /: filter │ enter: select │ esc: back"))
+
 	case stepNewBranchName:
-		b.WriteString(m.styles.WizardDim.Render("Mode: Create new branch"))
+		switch m.mode {
+		case modePatch:
+			b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("Mode: From patch (%s)", m.patchSource)))
+		case modePlaybook:
+			b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("Mode: From playbook (%s)", m.playbook.Name)))
+		case modeTeam:
+			b.WriteString(m.styles.WizardDim.Render("Mode: Spawn agent team"))
+		default:
+			b.WriteString(m.styles.WizardDim.Render("Mode: Create new branch"))
+		}
 		b.WriteString("\n")
-		b.WriteString(m.styles.WizardActive.Render("Enter new branch name"))
+		b.WriteString(m.styles.WizardActive.Render("Enter new branch name, or a ticket ID (e.g. ENG-123) to name it after"))
 		b.WriteString("\n\n")
 		b.WriteString("  " + m.branchInput.View())
 		b.WriteString("\n\n")
-		b.WriteString(m.styles.Help.Render("  enter: continue │ esc: back"))
+		if m.ticketLoading {
+			b.WriteString(m.styles.WizardDim.Render("  Looking up ticket…"))
+			b.WriteString("\n\n")
+		} else if m.ticket != "" {
+			b.WriteString(m.styles.WizardDim.Render("  Ticket: " + ticketLine(m.ticket, m.ticketTitle)))
+			b.WriteString("\n\n")
+		}
+		if m.branchSuggestion != "" {
+			b.WriteString(m.styles.WizardDim.Render("  Suggestion: " + m.branchSuggestion))
+			b.WriteString("\n\n")
+			b.WriteString(m.styles.Help.Render("  enter: continue │ tab: use suggestion │ esc: back"))
+		} else {
+			b.WriteString(m.styles.Help.Render("  enter: continue │ esc: back"))
+		}
 
 	case stepConfirm:
 		b.WriteString(m.styles.WizardActive.Render("Confirm"))
 		b.WriteString("\n\n")
 		b.WriteString(fmt.Sprintf("  Branch:    %s\n", m.branch))
-		if m.createBranch {
+		if m.mode == modeCI {
+			b.WriteString(fmt.Sprintf("  CI run:    #%d %s — %s\n", m.run.ID, m.run.Workflow, m.run.Title))
+		} else if m.createBranch && m.fetchBase {
+			b.WriteString(fmt.Sprintf("  Base:      %s (will create, after fast-forwarding it from origin)\n", m.baseBranch))
+		} else if m.createBranch {
 			b.WriteString(fmt.Sprintf("  Base:      %s (will create)\n", m.baseBranch))
 		} else {
 			b.WriteString("  Base:      — (existing branch)\n")
 		}
+		if m.mode == modePatch {
+			b.WriteString(fmt.Sprintf("  Patch:     %s (agent will finish it)\n", m.patchSource))
+		}
+		if m.mode == modePlaybook {
+			b.WriteString(fmt.Sprintf("  Playbook:  %s\n", playbookLine(m.playbook)))
+		}
+		if m.mode == modeTeam {
+			b.WriteString(fmt.Sprintf("  Team:      %s (the lead forms the team)\n", m.teamTask))
+		}
+		switch {
+		case m.reuseErr != "":
+			b.WriteString(m.styles.Error.Render("  Worktree:  cannot reuse — "+m.reuseErr) + "\n")
+		case m.reuseDirty:
+			b.WriteString(fmt.Sprintf("  Worktree:  %s (reuse, has uncommitted changes)\n", m.reusePath))
+		case m.reusePath != "":
+			b.WriteString(fmt.Sprintf("  Worktree:  %s (reuse)\n", m.reusePath))
+		}
+		if m.ticket != "" {
+			b.WriteString(fmt.Sprintf("  Ticket:    %s\n", ticketLine(m.ticket, m.ticketTitle)))
+		}
+		if m.task != "" {
+			b.WriteString(fmt.Sprintf("  Task:      %s\n", taskLine(m.task)))
+		}
+		session := m.sessionName()
+		if m.sessionIdx == 0 {
+			session += " (current)"
+		}
+		b.WriteString(fmt.Sprintf("  Session:   %s\n", session))
+		switch {
+		case m.report:
+			b.WriteString(fmt.Sprintf("  Mode:      report (writes %s, collected on finish; no merge)\n", m.orch.ReportFile()))
+		case m.readOnly:
+			b.WriteString("  Mode:      read-only (report only: no merge or push, branch kept on dismiss)\n")
+		}
+		b.WriteString("\n")
+		if m.logsLoading {
+			b.WriteString(m.styles.WizardDim.Render("  Downloading the run's logs from gh…"))
+			break
+		}
+		fetch := ""
+		if m.branchesFromBase() {
+			fetch = "f: fetch base │ "
+		}
+		help := "  y/enter: spawn │ s: session │ " + fetch + "r: read-only/report │ n: go back │ esc: back"
+		if m.takesTask() {
+			help = "  y/enter: spawn │ t: task │ s: session │ " + fetch + "r: read-only/report │ n: go back │ esc: back"
+		}
+		b.WriteString(m.styles.Help.Render(help))
+
+	case stepTask:
+		b.WriteString(m.styles.WizardDim.Render(fmt.Sprintf("Branch: %s", m.branch)))
 		b.WriteString("\n")
-		b.WriteString(m.styles.Help.Render("  y/enter: spawn │ n: go back │ esc: back"))
+		b.WriteString(m.styles.WizardActive.Render("Describe the task, typed into the agent's pane once it starts"))
+		b.WriteString("\n\n")
+		b.WriteString(lipgloss.NewStyle().PaddingLeft(2).Render(m.taskInput.View()))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  ctrl+s: save │ enter: new line │ esc: discard"))
 	}
 
 	if m.err != "" {
@@ -445,6 +1094,53 @@ func (m spawnModel) ViewContent() string {
 	return b.String()
 }
 
+// harnessOptions labels and describes the harnesses for the wizard.
+func harnessOptions(types []harness.Type) []struct{ label, desc string } {
+	options := make([]struct{ label, desc string }, len(types))
+	for i, t := range types {
+		switch t {
+		case harness.TypeClaudeCode:
+			options[i].label, options[i].desc = "Claude Code", "Use Anthropic's Claude Code CLI"
+		case harness.TypeOpenCode:
+			options[i].label, options[i].desc = "OpenCode", "Use OpenCode (requires: opencode CLI installed)"
+		default:
+			options[i].label, options[i].desc = string(t), "Agent command from [[harness.agents]]; status read from its pane"
+		}
+	}
+	return options
+}
+
+// playbookLine describes a playbook's checks and merge policy for the
+// wizard.
+func playbookLine(p playbook.Playbook) string {
+	line := fmt.Sprintf("%s (%d check(s)", p.Name, len(p.Checks))
+	if p.AutoMerge == playbook.MergeOnChecks {
+		line += ", merged automatically when they pass"
+	}
+	return line + ")"
+}
+
+// taskLine shows the first line of a task for the wizard, noting how
+// many more it has.
+func taskLine(task string) string {
+	first, rest, ok := strings.Cut(task, "\n")
+	if !ok {
+		return first
+	}
+	if n := strings.Count(rest, "\n") + 1; n > 1 {
+		return fmt.Sprintf("%s (+%d more lines)", first, n)
+	}
+	return first + " (+1 more line)"
+}
+
+// ticketLine describes a ticket for the wizard.
+func ticketLine(id, title string) string {
+	if title == "" {
+		return id
+	}
+	return id + " — " + title
+}
+
 func (m spawnModel) View() string {
 	return m.styles.Border.Render(m.ViewContent())
 }
@@ -2,14 +2,19 @@ package ui
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/ci"
 	"github.com/simonbystrom/mastermind/internal/config"
 	"github.com/simonbystrom/mastermind/internal/git"
+	"github.com/simonbystrom/mastermind/internal/harness/custom"
 	"github.com/simonbystrom/mastermind/internal/orchestrator"
 )
 
@@ -64,6 +69,28 @@ func TestSpawn_ChooseMode_ExistingBranch(t *testing.T) {
 	}
 }
 
+func TestSpawn_ChooseHarness_Custom(t *testing.T) {
+	h, err := custom.New(custom.Spec{Name: "aider", Command: "aider"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir(),
+		orchestrator.WithHarness(h))
+	m := newSpawn(NewStyles(config.Default().Colors), orch, "/repo", 120, "claude")
+
+	if !strings.Contains(m.ViewContent(), "aider") {
+		t.Error("harness list does not offer the configured agent command")
+	}
+	for range 3 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.selectedHarness != "aider" {
+		t.Errorf("selected harness = %q, want aider", m.selectedHarness)
+	}
+}
+
 func TestSpawn_ChooseMode_NewBranch(t *testing.T) {
 	m := newTestSpawn(t)
 
@@ -144,3 +171,345 @@ func TestSpawn_ViewContent_Confirm(t *testing.T) {
 		t.Error("confirm should show branch")
 	}
 }
+
+func TestSpawn_Confirm_TogglesReadOnly(t *testing.T) {
+	m := newTestSpawn(t)
+	m.step = stepConfirm
+	m.branch = "research/x"
+	m.baseBranch = "main"
+	m.createBranch = true
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if !m.readOnly || !strings.Contains(m.ViewContent(), "read-only") {
+		t.Error("r should mark the agent read-only")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if !m.readOnly || !m.report || !strings.Contains(m.ViewContent(), "REPORT.md") {
+		t.Error("second r should switch to a report agent")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if m.readOnly || m.report {
+		t.Error("third r should toggle the mode off again")
+	}
+}
+
+func TestSpawn_Confirm_TogglesFetchBase(t *testing.T) {
+	m := newTestSpawn(t)
+	m.step = stepConfirm
+	m.branch = "feat/x"
+	m.baseBranch = "main"
+	m.createBranch = true
+
+	if m.fetchBase {
+		t.Fatal("fetch base should start from the orchestrator's default, off")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if !m.fetchBase || !strings.Contains(m.ViewContent(), "after fast-forwarding it from origin") {
+		t.Errorf("f should fetch the base first:\n%s", m.ViewContent())
+	}
+
+	// An existing branch has no base to fetch.
+	m.createBranch = false
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if !m.fetchBase || strings.Contains(m.ViewContent(), "f: fetch base") {
+		t.Errorf("f should do nothing for an existing branch:\n%s", m.ViewContent())
+	}
+}
+
+func TestSpawn_Confirm_EditsTask(t *testing.T) {
+	m := newTestSpawn(t)
+	m.mode = modeNew
+	m.step = stepConfirm
+	m.branch = "feat/login"
+	m.baseBranch = "main"
+	m.createBranch = true
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if m.step != stepTask {
+		t.Fatalf("step = %d, want the task editor", m.step)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Fix the login")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Add a test")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.step != stepConfirm || m.task != "Fix the login\nAdd a test" {
+		t.Fatalf("step = %d, task = %q; want the two-line task saved", m.step, m.task)
+	}
+	if view := m.ViewContent(); !strings.Contains(view, "Task:      Fix the login (+1 more line)") {
+		t.Errorf("confirm should show the task:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" more")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.step != stepConfirm || m.task != "Fix the login\nAdd a test" {
+		t.Errorf("step = %d, task = %q; esc should discard the edit", m.step, m.task)
+	}
+
+	m.mode = modeCI
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if m.step != stepConfirm {
+		t.Errorf("step = %d; CI agents get their prompt from the run", m.step)
+	}
+}
+
+func TestSpawn_PatchMode_ReadsFile(t *testing.T) {
+	m := newTestSpawn(t)
+	patchFile := filepath.Join(t.TempDir(), "wip.patch")
+	if err := os.WriteFile(patchFile, []byte("--- a/x\n+++ b/x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter}) // harness
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepPatchSource || m.mode != modePatch {
+		t.Fatalf("step/mode = %d/%d, want patch source", m.step, m.mode)
+	}
+
+	m.patchInput.SetValue(patchFile)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepNewBranchName {
+		t.Errorf("step = %d, want %d (stepNewBranchName), err = %q", m.step, stepNewBranchName, m.err)
+	}
+	if len(m.patch) == 0 || m.patchSource != patchFile {
+		t.Errorf("patch = %q from %q", m.patch, m.patchSource)
+	}
+}
+
+func TestSpawn_PatchMode_MissingFile(t *testing.T) {
+	m := newTestSpawn(t)
+	m.step = stepPatchSource
+	m.mode = modePatch
+	m.patchInput.SetValue(filepath.Join(t.TempDir(), "missing.patch"))
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepPatchSource {
+		t.Errorf("step = %d, want to stay on patch source", m.step)
+	}
+	if m.err == "" {
+		t.Error("expected error for missing patch file")
+	}
+}
+
+func TestSpawn_CIMode_PickRun(t *testing.T) {
+	m := newTestSpawn(t)
+	m.step = stepPickRun
+	m.mode = modeCI
+	m.runsLoading = true
+
+	m, _ = m.Update(runsLoadedMsg{runs: []ci.Run{
+		{ID: 1, Branch: "feat/red", Workflow: "CI", Title: "Add x"},
+	}})
+	if m.runsLoading {
+		t.Error("runsLoading should be cleared")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepConfirm {
+		t.Fatalf("step = %d, want %d (stepConfirm)", m.step, stepConfirm)
+	}
+	if m.branch != "feat/red" || m.run.ID != 1 {
+		t.Errorf("branch = %q, run = %+v", m.branch, m.run)
+	}
+	if !strings.Contains(m.ViewContent(), "CI run:") {
+		t.Error("confirm should show the CI run")
+	}
+}
+
+func TestSpawn_CIMode_LoadsLogsOutsideUpdate(t *testing.T) {
+	m := newTestSpawn(t)
+	m.step = stepConfirm
+	m.mode = modeCI
+	m.run = ci.Run{ID: 1, Branch: "feat/red"}
+	m.branch = "feat/red"
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.logsLoading || cmd == nil {
+		t.Fatalf("logsLoading = %v, cmd = %v; want the logs loaded in a command", m.logsLoading, cmd)
+	}
+	if !strings.Contains(m.ViewContent(), "Downloading the run's logs") {
+		t.Error("confirm should show the logs loading")
+	}
+	if m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}); m.step != stepConfirm {
+		t.Error("keys should be ignored while the logs load")
+	}
+
+	m, _ = m.Update(ciLogsLoadedMsg{err: errors.New("gh: not logged in")})
+	if m.logsLoading || m.err != "gh: not logged in" {
+		t.Errorf("logsLoading = %v, err = %q; want the failure shown", m.logsLoading, m.err)
+	}
+}
+
+func TestSpawn_ConfirmCyclesSession(t *testing.T) {
+	m := newTestSpawn(t)
+	m.step = stepConfirm
+	m.branch = "feat/x"
+	m.sessions = []string{"test", "noise"}
+
+	if !strings.Contains(m.ViewContent(), "Session:   test (current)") {
+		t.Errorf("confirm should default to the current session:\n%s", m.ViewContent())
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if got := m.sessionName(); got != "noise" {
+		t.Errorf("session = %q, want noise", got)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if m.sessionIdx != 0 {
+		t.Errorf("sessionIdx = %d, want to wrap to 0", m.sessionIdx)
+	}
+}
+
+func TestSpawn_TicketIDGeneratesBranchName(t *testing.T) {
+	m := newTestSpawn(t)
+	m.step = stepNewBranchName
+	m.mode = modeNew
+
+	m.branchInput.SetValue("ENG-7")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !m.ticketLoading {
+		t.Fatal("expected ticket lookup")
+	}
+	m, _ = m.Update(cmd())
+	if m.ticket != "ENG-7" || m.step != stepNewBranchName {
+		t.Fatalf("ticket = %q, step = %d", m.ticket, m.step)
+	}
+	if got := m.branchInput.Value(); got != "eng-7" {
+		t.Errorf("branch input = %q, want eng-7", got)
+	}
+
+	m.branchInput.SetValue("eng-7-login")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.branch != "eng-7-login" || m.step != stepPickBranch || m.ticket != "ENG-7" {
+		t.Errorf("branch = %q, step = %d, ticket = %q", m.branch, m.step, m.ticket)
+	}
+	if !strings.Contains(m.ViewContent(), "New branch: eng-7-login") {
+		t.Errorf("view:\n%s", m.ViewContent())
+	}
+}
+
+func TestSpawn_InvalidBranchNameOffersSuggestion(t *testing.T) {
+	m := newTestSpawn(t)
+	m.step = stepNewBranchName
+	m.mode = modeNew
+
+	m.branchInput.SetValue("fix: login page")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepNewBranchName || !strings.Contains(m.err, "invalid branch name") {
+		t.Fatalf("step = %d, err = %q", m.step, m.err)
+	}
+	if !strings.Contains(m.ViewContent(), "Suggestion: fix-login-page") {
+		t.Errorf("view:\n%s", m.ViewContent())
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.branchInput.Value(); got != "fix-login-page" || m.err != "" {
+		t.Errorf("after tab: input = %q, err = %q", got, m.err)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.branch != "fix-login-page" || m.step != stepPickBranch {
+		t.Errorf("branch = %q, step = %d", m.branch, m.step)
+	}
+}
+
+func TestSpawn_OffersLeftoverWorktree(t *testing.T) {
+	m := newTestSpawn(t)
+	m, _ = m.Update(branchesLoadedMsg{branches: []git.Branch{{Name: "main", Current: true}, {Name: "feat/x"}}})
+	m.checkedOutBranches = map[string]bool{"main": true, "feat/x": true}
+	m.leftoverWorktrees = map[string]string{"feat/x": "/repo/.worktrees/feat__x"}
+	m.mode = modeExisting
+	m.setBranchListItems()
+
+	items := m.branchList.Items()
+	if len(items) != 1 {
+		t.Fatalf("items = %v, want only the branch with a leftover worktree", items)
+	}
+	if title := items[0].(branchItem).Title(); title != "feat/x (existing worktree)" {
+		t.Errorf("title = %q", title)
+	}
+
+	// The test orchestrator finds no worktree to reuse, so the confirm
+	// step explains and refuses instead of spawning.
+	m.step = stepPickBranch
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepConfirm || m.reuseErr == "" {
+		t.Fatalf("step = %d, reuseErr = %q", m.step, m.reuseErr)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if !strings.Contains(m.err, "cannot reuse worktree") {
+		t.Errorf("err = %q", m.err)
+	}
+}
+
+func TestSpawn_PlaybookMode_SkipsBasePicker(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "deps.yml"), []byte("prompt: Upgrade deps.\nbase: main\nchecks: [go test ./...]\nauto_merge: checks\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	orch := orchestrator.New(context.Background(), agent.NewStore(), "/repo", "test", t.TempDir(), orchestrator.WithPlaybooks(dir))
+	m := newSpawn(NewStyles(config.Default().Colors), orch, "/repo", 120, "claude")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter}) // harness
+	for range 4 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepPickPlaybook || m.mode != modePlaybook {
+		t.Fatalf("step/mode = %d/%d, want playbook picker, err = %q", m.step, m.mode, m.err)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepNewBranchName || !strings.HasPrefix(m.branchInput.Value(), "playbook/deps-") {
+		t.Fatalf("step = %d, branch = %q; want the playbook's branch suggested", m.step, m.branchInput.Value())
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepConfirm || m.baseBranch != "main" || !m.createBranch {
+		t.Fatalf("step = %d, base = %q, err = %q; want confirm on the playbook's base", m.step, m.baseBranch, m.err)
+	}
+	if view := m.ViewContent(); !strings.Contains(view, "merged automatically") {
+		t.Errorf("confirm should describe the playbook:\n%s", view)
+	}
+}
+
+func TestSpawn_TeamMode(t *testing.T) {
+	m := newTestSpawn(t)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter}) // harness
+	for range 5 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepTeamTask || m.mode != modeTeam {
+		t.Fatalf("step/mode = %d/%d, want team task, err = %q", m.step, m.mode, m.err)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepTeamTask || m.err == "" {
+		t.Fatalf("step = %d, err = %q; want the empty task rejected", m.step, m.err)
+	}
+	m.teamInput.SetValue("Add OAuth login")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepNewBranchName || m.teamTask != "Add OAuth login" {
+		t.Fatalf("step = %d, task = %q; want the branch name step", m.step, m.teamTask)
+	}
+
+	m.step, m.branch, m.baseBranch, m.createBranch = stepConfirm, "feat/login", "main", true
+	if view := m.ViewContent(); !strings.Contains(view, "Team:      Add OAuth login") {
+		t.Errorf("confirm should show the team task:\n%s", view)
+	}
+}
+
+func TestSpawn_TeamModeNeedsClaude(t *testing.T) {
+	m := newTestSpawn(t)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter}) // OpenCode
+	for range 5 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.step != stepChooseMode || m.err == "" {
+		t.Errorf("step = %d, err = %q; want team mode refused for OpenCode", m.step, m.err)
+	}
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+)
+
+// stackAgents reorders agents so that every stacked agent directly follows
+// the agent whose branch it is based on, keeping the given order among
+// siblings. It also returns each agent's depth in its stack, keyed by ID.
+func stackAgents(agents []*agent.Agent) ([]*agent.Agent, map[string]int) {
+	byBranch := make(map[string]*agent.Agent, len(agents))
+	for _, a := range agents {
+		byBranch[a.Branch] = a
+	}
+
+	children := make(map[string][]*agent.Agent)
+	var roots []*agent.Agent
+	for _, a := range agents {
+		parent, ok := byBranch[a.GetBaseBranch()]
+		if !ok || parent == a {
+			roots = append(roots, a)
+			continue
+		}
+		children[parent.ID] = append(children[parent.ID], a)
+	}
+
+	ordered := make([]*agent.Agent, 0, len(agents))
+	depths := make(map[string]int, len(agents))
+	var visit func(a *agent.Agent, depth int)
+	visit = func(a *agent.Agent, depth int) {
+		if _, seen := depths[a.ID]; seen {
+			return
+		}
+		depths[a.ID] = depth
+		ordered = append(ordered, a)
+		for _, c := range children[a.ID] {
+			visit(c, depth+1)
+		}
+	}
+	for _, a := range roots {
+		visit(a, 0)
+	}
+	// Agents in a base-branch cycle have no root; list them flat.
+	for _, a := range agents {
+		visit(a, 0)
+	}
+	return ordered, depths
+}
+
+// stackPrefix returns the tree prefix drawn before a stacked agent's branch.
+func stackPrefix(depth int) string {
+	if depth == 0 {
+		return ""
+	}
+	return strings.Repeat("  ", depth-1) + "└ "
+}
+
+// branchCell renders a branch name with its stack prefix, truncated and
+// padded to width visual columns.
+func branchCell(branch string, depth, width int) string {
+	prefix := stackPrefix(depth)
+	cell := prefix + truncate(branch, max(width-lipgloss.Width(prefix), 4))
+	if w := lipgloss.Width(cell); w < width {
+		cell += strings.Repeat(" ", width-w)
+	}
+	return cell
+}
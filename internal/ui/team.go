@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+	"github.com/simonbystrom/mastermind/internal/team"
+)
+
+type startTeamMsg struct {
+	agentID string
+}
+
+type teamCancelMsg struct{}
+
+// teammateActionMsg reports the outcome of killing or restarting a
+// teammate's pane.
+type teammateActionMsg struct {
+	agentID string
+	name    string
+	action  string // agent.TeammateKilled or agent.TeammateRestarted
+	err     string
+}
+
+// teamModel lists the teammates of the agent team an agent leads and
+// kills or restarts a teammate's pane, e.g. when it hangs. Killing asks
+// for confirmation first.
+type teamModel struct {
+	orch   *orchestrator.Orchestrator
+	store  *agent.Store
+	err    string
+	width  int
+	styles Styles
+
+	agentID    string
+	cursor     int
+	confirming bool
+}
+
+func newTeam(s Styles, orch *orchestrator.Orchestrator, store *agent.Store, msg startTeamMsg, width int) teamModel {
+	return teamModel{
+		orch:    orch,
+		store:   store,
+		agentID: msg.agentID,
+		styles:  s,
+		width:   width,
+	}
+}
+
+// teammates returns the teammates of the lead, read from the store so the
+// view follows the monitor's updates.
+func (m teamModel) teammates() []team.Member {
+	a, ok := m.store.Get(m.agentID)
+	if !ok {
+		return nil
+	}
+	info := a.GetTeam()
+	if info == nil {
+		return nil
+	}
+	var members []team.Member
+	for _, member := range info.Members {
+		if member.AgentType != "lead" {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+func (m teamModel) Update(msg tea.Msg) (teamModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case teammateActionMsg:
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		members := m.teammates()
+		if m.cursor >= len(members) {
+			m.cursor = max(len(members)-1, 0)
+		}
+
+		if m.confirming {
+			switch msg.String() {
+			case "y":
+				m.confirming = false
+				if len(members) == 0 {
+					return m, nil
+				}
+				return m, m.act(members[m.cursor].Name, agent.TeammateKilled)
+			case "n", "esc":
+				m.confirming = false
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "esc", "q":
+			return m, func() tea.Msg { return teamCancelMsg{} }
+		case "down", "j":
+			if m.cursor < len(members)-1 {
+				m.cursor++
+			}
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "x":
+			if len(members) > 0 {
+				m.confirming = true
+				m.err = ""
+			}
+		case "r":
+			if len(members) > 0 {
+				m.err = ""
+				return m, m.act(members[m.cursor].Name, agent.TeammateRestarted)
+			}
+		}
+	}
+	return m, nil
+}
+
+// act kills or restarts the named teammate's pane.
+func (m teamModel) act(name, action string) tea.Cmd {
+	orch, id := m.orch, m.agentID
+	return func() tea.Msg {
+		var err error
+		if action == agent.TeammateKilled {
+			err = orch.KillTeammate(id, name)
+		} else {
+			err = orch.RestartTeammate(id, name)
+		}
+		msg := teammateActionMsg{agentID: id, name: name, action: action}
+		if err != nil {
+			msg.err = err.Error()
+		}
+		return msg
+	}
+}
+
+func (m teamModel) ViewContent() string {
+	var b strings.Builder
+
+	a, _ := m.store.Get(m.agentID)
+	title := "Agent Team of " + m.agentID
+	if a != nil {
+		if info := a.GetTeam(); info != nil {
+			title = fmt.Sprintf("Agent Team %s (lead %s)", info.TeamName, m.agentID)
+		}
+	}
+	b.WriteString(m.styles.WizardTitle.Render(title))
+	b.WriteString("\n\n")
+
+	members := m.teammates()
+	if len(members) == 0 {
+		b.WriteString(m.styles.WizardDim.Render("  No teammates yet"))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  esc: close"))
+		m.writeError(&b)
+		return b.String()
+	}
+
+	now := time.Now()
+	for i, member := range members {
+		line := member.Name
+		if member.TmuxPaneID == "" {
+			line += "  (in-process)"
+		} else {
+			line += "  " + member.TmuxPaneID
+		}
+		if act, ok := a.GetTeammateAction(member.Name); ok {
+			line += fmt.Sprintf("  %s %s", act.Action, formatAgo(now.Sub(act.At)))
+		}
+		if i == m.cursor {
+			b.WriteString(m.styles.WizardActive.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.confirming {
+		name := members[min(m.cursor, len(members)-1)].Name
+		b.WriteString(fmt.Sprintf("  Kill teammate %s's pane?\n", name))
+		b.WriteString(m.styles.WizardDim.Render("  The lead can reassign its tasks or spawn a new teammate."))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("  y: kill │ n/esc: back"))
+	} else {
+		b.WriteString(m.styles.Help.Render("  ↑/↓: select │ r: restart │ x: kill │ esc: close"))
+	}
+
+	m.writeError(&b)
+	return b.String()
+}
+
+func (m teamModel) writeError(b *strings.Builder) {
+	if m.err != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Error.Render("  Error: " + m.err))
+	}
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+	"github.com/simonbystrom/mastermind/internal/team"
+)
+
+func TestTeam_KillAsksForConfirmation(t *testing.T) {
+	store := agent.NewStore()
+	orch := orchestrator.New(context.Background(), store, "/repo", "test", t.TempDir())
+	a := agent.NewAgent("feat/x", "main", "/wt", "@1", "%1", "claude")
+	a.ID = "a1"
+	a.SetTeam(&team.TeamInfo{TeamName: "feat-x", Members: []team.Member{
+		{Name: "lead", AgentType: "lead"},
+		{Name: "backend", AgentType: "teammate", TmuxPaneID: "%7"},
+		{Name: "docs", AgentType: "teammate"},
+	}})
+	store.Add(a)
+	m := newTeam(NewStyles(config.Default().Colors), orch, store, startTeamMsg{agentID: "a1"}, 120)
+
+	view := m.ViewContent()
+	if strings.Contains(view, "> lead") || !strings.Contains(view, "> backend  %7") || !strings.Contains(view, "docs  (in-process)") {
+		t.Errorf("team view should list the teammates:\n%s", view)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if !m.confirming || cmd != nil {
+		t.Fatal("x should ask for confirmation before killing")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.confirming {
+		t.Error("n should cancel the kill")
+	}
+
+	// An in-process teammate has no pane to restart.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if cmd == nil {
+		t.Fatal("r should restart the teammate")
+	}
+	m, _ = m.Update(cmd())
+	if !strings.Contains(m.ViewContent(), "in-process and has no pane") {
+		t.Errorf("expected the restart error:\n%s", m.ViewContent())
+	}
+}
@@ -0,0 +1,180 @@
+// Package web serves a read-only dashboard over HTTP so agent status can
+// be checked from a browser, e.g. on a phone. The page is embedded in the
+// binary; it loads agents from a small JSON API and refreshes on events
+// streamed over server-sent events.
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// keepAlive is how often an idle event stream gets a comment line, so
+// proxies and phones don't drop the connection.
+const keepAlive = 30 * time.Second
+
+// Source provides the agents and events shown on the dashboard. The
+// orchestrator's IPC backend satisfies it.
+type Source interface {
+	Repo() string
+	Agents() []agent.PersistedAgent
+	Subscribe(buffer int) (<-chan monitor.Event, func())
+}
+
+// AgentsResponse is the body of GET /api/agents.
+type AgentsResponse struct {
+	Repo   string                 `json:"repo"`
+	Agents []agent.PersistedAgent `json:"agents"`
+}
+
+// eventPayload is the data of a server-sent event.
+type eventPayload struct {
+	Agent string          `json:"agent"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Handler returns the dashboard's HTTP handler. A non-empty token must be
+// presented as ?token= or an "Authorization: Bearer" header.
+func Handler(src Source, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(indexHTML)
+	})
+	mux.HandleFunc("GET /api/agents", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(AgentsResponse{Repo: src.Repo(), Agents: src.Agents()})
+	})
+	mux.HandleFunc("GET /api/events", func(w http.ResponseWriter, r *http.Request) {
+		streamEvents(w, r, src)
+	})
+	return authorize(token, mux)
+}
+
+// authorize rejects requests that don't carry token.
+func authorize(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given := r.URL.Query().Get("token")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			given = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// streamEvents sends monitor events as server-sent events until the
+// client goes away. Each event is named by its type and carries the agent
+// ID and the event's fields.
+func streamEvents(w http.ResponseWriter, r *http.Request, src Source) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	events, stop := src.Subscribe(64)
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			typ, data, err := monitor.MarshalEvent(ev)
+			if err != nil {
+				slog.Warn("web event not sent", "error", err)
+				continue
+			}
+			payload, err := json.Marshal(eventPayload{Agent: ev.AgentRef(), Data: data})
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typ, payload)
+		}
+		flusher.Flush()
+	}
+}
+
+// ListenAddr checks the configured listen address addr against token and
+// returns the address to serve on. An address without a host, such as
+// ":8765", listens on 127.0.0.1 only; other machines can only be let in
+// by naming a non-loopback host, and only with a token.
+func ListenAddr(addr, token string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if host == "" {
+		return net.JoinHostPort("127.0.0.1", port), nil
+	}
+	if token == "" && !isLoopback(host) {
+		return "", fmt.Errorf("refusing to serve the dashboard on %s without a token; set [web] token or listen on 127.0.0.1", addr)
+	}
+	return addr, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Serve runs h, usually the dashboard's Handler, on addr until ctx is
+// cancelled.
+func Serve(ctx context.Context, addr string, h http.Handler) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	slog.Info("web dashboard listening", "addr", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+)
+
+type fakeSource struct {
+	bus    *monitor.Bus
+	subbed chan struct{}
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{bus: monitor.NewBus(), subbed: make(chan struct{}, 1)}
+}
+
+func (s *fakeSource) Repo() string { return "/repo" }
+
+func (s *fakeSource) Agents() []agent.PersistedAgent {
+	return []agent.PersistedAgent{{ID: "a1", Branch: "feat/x", Status: agent.StatusRunning}}
+}
+
+func (s *fakeSource) Subscribe(buffer int) (<-chan monitor.Event, func()) {
+	ch := s.bus.Subscribe(buffer)
+	s.subbed <- struct{}{}
+	return ch, func() { s.bus.Unsubscribe(ch) }
+}
+
+func TestHandler_AgentsAndIndex(t *testing.T) {
+	srv := httptest.NewServer(Handler(newFakeSource(), ""))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/agents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body AgentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Repo != "/repo" || len(body.Agents) != 1 || body.Agents[0].Branch != "feat/x" {
+		t.Errorf("agents response = %+v", body)
+	}
+
+	resp, err = http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("index Content-Type = %q", ct)
+	}
+}
+
+func TestHandler_Token(t *testing.T) {
+	srv := httptest.NewServer(Handler(newFakeSource(), "s3cret"))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		url    string
+		bearer string
+		want   int
+	}{
+		{url: "/api/agents", want: http.StatusUnauthorized},
+		{url: "/api/agents?token=wrong", want: http.StatusUnauthorized},
+		{url: "/api/agents?token=s3cret", want: http.StatusOK},
+		{url: "/api/agents", bearer: "s3cret", want: http.StatusOK},
+	} {
+		req, _ := http.NewRequest("GET", srv.URL+tc.url, nil)
+		if tc.bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.bearer)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("GET %s (bearer %q) = %d, want %d", tc.url, tc.bearer, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestHandler_StreamsEvents(t *testing.T) {
+	src := newFakeSource()
+	srv := httptest.NewServer(Handler(src, ""))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/api/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	<-src.subbed
+	src.bus.Publish(monitor.AgentFinished{AgentID: "a1", ExitCode: 0, HasChanges: true})
+
+	r := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if lines[0] != "event: finished" {
+		t.Errorf("event line = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], `"agent":"a1"`) || !strings.Contains(lines[1], `"HasChanges":true`) {
+		t.Errorf("data line = %q", lines[1])
+	}
+}
+
+func TestListenAddr(t *testing.T) {
+	for _, tc := range []struct {
+		addr, token string
+		want        string
+		wantErr     bool
+	}{
+		{addr: ":8765", want: "127.0.0.1:8765"},
+		{addr: "127.0.0.1:8765", want: "127.0.0.1:8765"},
+		{addr: "localhost:8765", want: "localhost:8765"},
+		{addr: "[::1]:8765", want: "[::1]:8765"},
+		{addr: "0.0.0.0:8765", wantErr: true},
+		{addr: "0.0.0.0:8765", token: "t", want: "0.0.0.0:8765"},
+		{addr: "devbox:8765", wantErr: true},
+		{addr: "8765", wantErr: true},
+	} {
+		got, err := ListenAddr(tc.addr, tc.token)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ListenAddr(%q, %q) = %q, %v; want %q, error %t", tc.addr, tc.token, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
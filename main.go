@@ -9,16 +9,29 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/simonbystrom/mastermind/internal/agent"
 	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/daemon"
+	"github.com/simonbystrom/mastermind/internal/forge"
+	"github.com/simonbystrom/mastermind/internal/git"
 	"github.com/simonbystrom/mastermind/internal/harness"
+	customharness "github.com/simonbystrom/mastermind/internal/harness/custom"
+	"github.com/simonbystrom/mastermind/internal/instance"
+	"github.com/simonbystrom/mastermind/internal/ipc"
 	"github.com/simonbystrom/mastermind/internal/notify"
 	"github.com/simonbystrom/mastermind/internal/orchestrator"
+	"github.com/simonbystrom/mastermind/internal/prompt"
+	"github.com/simonbystrom/mastermind/internal/recorder"
+	"github.com/simonbystrom/mastermind/internal/shim"
+	"github.com/simonbystrom/mastermind/internal/streamjson"
+	"github.com/simonbystrom/mastermind/internal/ticket"
 	"github.com/simonbystrom/mastermind/internal/tmux"
 	"github.com/simonbystrom/mastermind/internal/ui"
 )
@@ -26,10 +39,53 @@ import (
 var version = "dev"
 
 func main() {
+	// Subcommands that run without the TUI. stream-relay and shim are
+	// hidden and used as agent commands inside tmux windows; prompt is
+	// called from shell prompts; daemon monitors agents in the background;
+	// run executes a playbook headlessly, batch spawns a tasks file,
+	// replay carries out a session recorded with --record and switch
+	// focuses another running instance. spawn, list, merge and dismiss
+	// drive single agents from scripts, and cleanup restores the main
+	// worktree from a preview a killed or hung TUI left behind.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "stream-relay":
+			os.Exit(streamjson.Main(os.Args[2:]))
+		case "shim":
+			os.Exit(shim.Main(os.Args[2:]))
+		case "prompt":
+			os.Exit(prompt.Main(os.Args[2:]))
+		case "daemon":
+			os.Exit(runDaemon(os.Args[2:]))
+		case "run":
+			os.Exit(runPlaybook(os.Args[2:]))
+		case "batch":
+			os.Exit(runBatch(os.Args[2:]))
+		case "replay":
+			os.Exit(runReplay(os.Args[2:]))
+		case "switch":
+			os.Exit(runSwitch(os.Args[2:]))
+		case "spawn":
+			os.Exit(runSpawn(os.Args[2:]))
+		case "list":
+			os.Exit(runList(os.Args[2:]))
+		case "merge":
+			os.Exit(runMerge(os.Args[2:]))
+		case "dismiss":
+			os.Exit(runDismiss(os.Args[2:]))
+		case "cleanup":
+			os.Exit(runCleanup(os.Args[2:]))
+		}
+	}
+
+	// --screenshot renders the dashboard once instead of starting the TUI.
+	screenshot := hiddenFlag("screenshot")
+
 	repo := flag.String("repo", "", "path to git repository (defaults to current directory)")
 	session := flag.String("session", "", "tmux session name (defaults to current session)")
 	showVersion := flag.Bool("version", false, "print version and exit")
 	initConfig := flag.Bool("init-config", false, "write default config file and print its path")
+	record := flag.String("record", "", "record your actions on agents into a script for mastermind replay")
 	flag.Parse()
 
 	if *showVersion {
@@ -47,21 +103,16 @@ func main() {
 		os.Exit(0)
 	}
 
-	if *repo == "" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
-		*repo = cwd
-	}
-
-	absRepo, err := filepath.Abs(*repo)
+	absRepo, err := resolveRepo(*repo)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error resolving repo path: %v\n", err)
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 
+	if screenshot {
+		os.Exit(runScreenshot(absRepo, *session))
+	}
+
 	if err := validateDependencies(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
@@ -74,20 +125,16 @@ func main() {
 
 	// Auto-detect current tmux session if not specified
 	if *session == "" {
-		if os.Getenv("TMUX") == "" {
-			fmt.Fprintf(os.Stderr, "error: not inside a tmux session (run inside tmux or pass --session)\n")
-			os.Exit(1)
-		}
 		detected, err := detectTmuxSession()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error detecting tmux session: %v\n", err)
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			os.Exit(1)
 		}
 		*session = detected
 	}
 
-	// Load user configuration
-	cfg, err := config.Load()
+	// Load user configuration, overlaid with the repository's .mastermind.conf
+	cfg, err := config.LoadForRepo(absRepo)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
 		os.Exit(1)
@@ -99,25 +146,16 @@ func main() {
 	}
 
 	// Install the statusline script for Claude Code integration
-	if err := config.WriteStatuslineScript(); err != nil {
+	if err := config.WriteStatuslineScript(cfg.Claude.StatuslineScript); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: could not write statusline script: %v\n", err)
 	}
 
-	worktreeDir := filepath.Join(absRepo, ".worktrees")
-	if err := os.MkdirAll(worktreeDir, 0o755); err != nil {
-		fmt.Fprintf(os.Stderr, "error creating worktree directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Set up persistent logging
-	logPath := filepath.Join(worktreeDir, "mastermind.log")
-	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	worktreeDir, logFile, err := setupWorktreeDir(absRepo)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error opening log file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 	defer logFile.Close()
-	slog.SetDefault(slog.New(slog.NewTextHandler(logFile, &slog.HandlerOptions{Level: slog.LevelDebug})))
 
 	// Log startup info
 	tmuxVersion, _ := tmux.CheckVersion()
@@ -126,21 +164,13 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Parse harness type from config
-	var defaultHarness harness.Type
-	switch cfg.Harness.Default {
-	case "opencode":
-		defaultHarness = harness.TypeOpenCode
-	case "claude", "":
-		defaultHarness = harness.TypeClaudeCode
-	default:
-		fmt.Fprintf(os.Stderr, "warning: unknown harness %q, defaulting to claude\n", cfg.Harness.Default)
-		defaultHarness = harness.TypeClaudeCode
-	}
-
 	// Detect the current tmux window so we can append " *" for attention.
 	var overviewWindowID, overviewWindowName string
+	selfPane := os.Getenv("TMUX_PANE")
 	if paneID, err := getCurrentPaneID(); err == nil {
+		if selfPane == "" {
+			selfPane = paneID
+		}
 		if wID, err := tmux.WindowIDForPane(paneID); err == nil {
 			if wName, err := tmux.CurrentWindowName(wID); err == nil {
 				overviewWindowID = wID
@@ -148,21 +178,50 @@ func main() {
 			}
 		}
 	}
+	// A named instance names its window so several instances, e.g. one
+	// per repository, can be told apart in tmux's status line.
+	if name := cfg.Instance.Name; name != "" && overviewWindowID != "" && name != overviewWindowName {
+		if err := tmux.RenameWindow(overviewWindowID, name); err != nil {
+			slog.Warn("failed to name the mastermind window", "window", overviewWindowID, "error", err)
+		} else {
+			overviewWindowName = name
+		}
+	}
 
-	notifier := notify.New(cfg.Notifications.Enabled, cfg.Notifications.Sound)
+	// Register the instance so other instances and `mastermind switch`
+	// can focus it.
+	reg, err := instance.Register(instance.Instance{
+		PID:       os.Getpid(),
+		Name:      cfg.Instance.Name,
+		Repo:      absRepo,
+		Session:   *session,
+		PaneID:    selfPane,
+		WindowID:  overviewWindowID,
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		slog.Warn("failed to register instance", "error", err)
+	} else {
+		defer reg.Remove()
+	}
 
-	store := agent.NewStore()
-	orch := orchestrator.New(ctx, store, absRepo, *session, worktreeDir,
-		orchestrator.WithLazygitSplit(cfg.Layout.LazygitSplit),
-		orchestrator.WithAgentTeams(cfg.Claude.AgentTeams),
-		orchestrator.WithTeammateMode(cfg.Claude.TeammateMode),
-		orchestrator.WithSkipPermissions(cfg.Claude.SkipPermissions),
-		orchestrator.WithPromptEditor(cfg.Claude.PromptEditor),
-		orchestrator.WithPromptEditorSize(cfg.Claude.PromptEditorSize),
-		orchestrator.WithDefaultHarness(defaultHarness),
-		orchestrator.WithNotifier(notifier),
+	opts := append(orchestratorOptions(cfg),
 		orchestrator.WithOverviewWindow(overviewWindowID, overviewWindowName),
+		orchestrator.WithSelfPane(selfPane),
 	)
+	if *record != "" {
+		opts = append(opts, orchestrator.WithRecorder(recorder.New(*record, absRepo)))
+	}
+	// With a daemon monitoring the repository, the TUI is a thin client
+	// that follows the daemon's state and events.
+	daemonPID, daemonRunning := daemon.Running(worktreeDir)
+	if daemonRunning {
+		slog.Info("daemon running, following its state", "pid", daemonPID)
+		opts = append(opts, orchestrator.WithDaemonClient(daemon.EventsPath(worktreeDir), daemon.SocketPath(worktreeDir)))
+	}
+
+	store := agent.NewStore()
+	orch := orchestrator.New(ctx, store, absRepo, *session, worktreeDir, opts...)
 
 	// Recover agents from previous session
 	orch.RecoverAgents()
@@ -173,18 +232,41 @@ func main() {
 	orch.ResetPreviewCleanup()
 
 	model := ui.NewApp(cfg, orch, store, absRepo, *session)
-	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithReportFocus())
+	programOpts := []tea.ProgramOption{tea.WithReportFocus()}
+	// Screen readers follow output in the normal screen, where printed
+	// notifications stay in the scrollback.
+	if !cfg.Accessibility.ScreenReader {
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+	p := tea.NewProgram(model, programOpts...)
 
 	orch.SetProgram(p)
 	go orch.StartMonitor()
 
-	// Handle SIGTERM/SIGHUP so preview cleanup runs even when the
-	// process is killed outside of the TUI (e.g. tmux session closed).
+	// Offer to kill the processes keeping the worktrees of agents
+	// dismissed earlier, e.g. by the daemon or a session that has quit.
+	go func() {
+		for _, held := range orch.HeldWorktrees() {
+			p.Send(held)
+		}
+	}()
+
+	// A running daemon serves the web dashboard and Slack itself.
+	if !daemonRunning {
+		startRemote(ctx, cfg, ipc.NewServer(orch.IPCBackend()).Backend())
+	}
+
+	// Handle SIGINT/SIGTERM/SIGHUP so preview cleanup runs even when the
+	// process is killed outside of the TUI (e.g. tmux session closed, or
+	// a Ctrl+C that reaches the process while the TUI is wedged).
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGHUP)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	go func() {
 		<-sigCh
 		orch.CleanupPreview()
+		if !daemonRunning {
+			orch.RemovePromptStatus()
+		}
 		p.Kill()
 	}()
 
@@ -193,9 +275,167 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Ensure preview branch is cleaned up on exit
+	// Ensure preview branch is cleaned up on exit. The prompt status
+	// stays while a daemon keeps monitoring.
 	orch.CleanupPreview()
+	if !daemonRunning {
+		orch.RemovePromptStatus()
+	}
+}
+
+// resolveRepo returns the absolute path of repo, defaulting to the
+// current directory.
+func resolveRepo(repo string) (string, error) {
+	if repo == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		repo = cwd
+	}
+	abs, err := filepath.Abs(repo)
+	if err != nil {
+		return "", fmt.Errorf("resolving repo path: %w", err)
+	}
+	return abs, nil
+}
+
+// setupWorktreeDir creates the repository's .worktrees directory, keeps
+// git from seeing it, and points slog at the mastermind.log inside it. The
+// caller closes the returned log file.
+func setupWorktreeDir(absRepo string) (string, *os.File, error) {
+	worktreeDir := filepath.Join(absRepo, ".worktrees")
+	if err := os.MkdirAll(worktreeDir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("creating worktree directory: %w", err)
+	}
+
+	logPath := filepath.Join(worktreeDir, "mastermind.log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return "", nil, fmt.Errorf("opening log file: %w", err)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(logFile, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	// Without this, agents can commit the worktrees of other agents and
+	// git status in the main tree walks every worktree.
+	if err := git.ExcludeFromRepo(absRepo, "/.worktrees/"); err != nil {
+		slog.Warn("failed to exclude .worktrees from git", "error", err)
+	}
+	return worktreeDir, logFile, nil
+}
+
+// orchestratorOptions returns the orchestrator options derived from the
+// user's configuration, shared by the TUI and the daemon.
+func orchestratorOptions(cfg config.Config) []orchestrator.Option {
+	// Custom agent commands are harnesses of their own
+	var opts []orchestrator.Option
+	custom := make(map[string]bool)
+	for _, ac := range cfg.Harness.Agents {
+		h, err := customharness.New(customharness.Spec{
+			Name:               ac.Name,
+			Command:            ac.Command,
+			Args:               ac.Args,
+			PromptFlag:         ac.PromptFlag,
+			InputPatterns:      ac.InputPatterns,
+			PermissionPatterns: ac.PermissionPatterns,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v, skipping it\n", err)
+			continue
+		}
+		custom[ac.Name] = true
+		opts = append(opts, orchestrator.WithHarness(h))
+	}
+
+	// Parse harness type from config
+	var defaultHarness harness.Type
+	switch {
+	case cfg.Harness.Default == "opencode":
+		defaultHarness = harness.TypeOpenCode
+	case cfg.Harness.Default == "claude", cfg.Harness.Default == "":
+		defaultHarness = harness.TypeClaudeCode
+	case custom[cfg.Harness.Default]:
+		defaultHarness = harness.Type(cfg.Harness.Default)
+	default:
+		fmt.Fprintf(os.Stderr, "warning: unknown harness %q, defaulting to claude\n", cfg.Harness.Default)
+		defaultHarness = harness.TypeClaudeCode
+	}
+
+	notifier := notify.New(cfg.Notifications.Enabled, cfg.Notifications.Sound)
+
+	tracker, err := ticket.New(cfg.Tickets.Provider, cfg.Tickets.Token, cfg.Tickets.JiraURL, cfg.Tickets.JiraEmail)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v, ticket tracking disabled\n", err)
+	}
+
+	cleanup := orchestrator.CleanupPolicy{
+		DoneAfter:      time.Duration(cfg.Cleanup.DoneAfterMinutes) * time.Minute,
+		DeleteBranches: cfg.Cleanup.DeleteBranches,
+	}
+	if cfg.Cleanup.MergedAt != "" {
+		at, err := time.Parse("15:04", cfg.Cleanup.MergedAt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: [cleanup] merged_at %q is not a time like 03:00, merged agents are not cleaned up\n", cfg.Cleanup.MergedAt)
+		} else {
+			cleanup.Merged = true
+			cleanup.MergedAt = time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute
+		}
+	}
+
+	strategy := orchestrator.MergeStrategy(cfg.Merge.Strategy)
+	if !slices.Contains(orchestrator.MergeStrategies, strategy) {
+		fmt.Fprintf(os.Stderr, "warning: unknown merge strategy %q, defaulting to merge\n", cfg.Merge.Strategy)
+		strategy = orchestrator.MergeCommit
+	}
 
+	tmuxOps := tmux.NewRetrying(tmux.RealTmux{}, cfg.Tmux.Retries,
+		time.Duration(cfg.Tmux.RetryBackoffMS)*time.Millisecond)
+
+	return append(opts,
+		orchestrator.WithLazygitSplit(cfg.Layout.LazygitSplit),
+		orchestrator.WithAgentTeams(cfg.Claude.AgentTeams),
+		orchestrator.WithTeammateMode(cfg.Claude.TeammateMode),
+		orchestrator.WithSkipPermissions(cfg.Claude.SkipPermissions),
+		orchestrator.WithPromptEditor(cfg.Claude.PromptEditor),
+		orchestrator.WithPromptEditorSize(cfg.Claude.PromptEditorSize),
+		orchestrator.WithStreamJSON(cfg.Claude.StreamJSON),
+		orchestrator.WithShim(cfg.Monitor.Shim),
+		orchestrator.WithPushGuard(cfg.Git.PushGuard),
+		orchestrator.WithMergeDefaults(cfg.Merge.DeleteBranch, cfg.Merge.RemoveWorktree, cfg.Merge.Remember),
+		orchestrator.WithMergeStrategy(strategy),
+		orchestrator.WithMergePush(cfg.Merge.Push),
+		orchestrator.WithMergeRetries(cfg.Merge.Retries),
+		orchestrator.WithRerere(cfg.Git.Rerere),
+		orchestrator.WithArchiveBranches(cfg.Git.ArchiveBranches),
+		orchestrator.WithBackupRetention(time.Duration(cfg.Git.BackupRetentionDays)*24*time.Hour),
+		orchestrator.WithFetchBase(cfg.Git.FetchBase),
+		orchestrator.WithLargeConflictSize(int64(cfg.Merge.LargeConflictKB)<<10),
+		orchestrator.WithConflictEditor(cfg.Merge.Editor),
+		orchestrator.WithReports(cfg.Reports.File, cfg.Reports.Dir),
+		orchestrator.WithPlaybooks(cfg.Playbooks.Dir),
+		orchestrator.WithMaxRunning(cfg.Agents.MaxRunning),
+		orchestrator.WithStopTimeout(time.Duration(cfg.Agents.StopSeconds)*time.Second),
+		orchestrator.WithDefaultHarness(defaultHarness),
+		orchestrator.WithNotifier(notifier),
+		orchestrator.WithSpawnSessions(cfg.Tmux.Sessions),
+		orchestrator.WithTmuxBell(cfg.Notifications.TmuxBell),
+		orchestrator.WithMarkWindows(cfg.Notifications.MarkWindows),
+		orchestrator.WithWindowStatus(cfg.Notifications.WindowStatus),
+		orchestrator.WithWindowLayout(cfg.Window.Panes),
+		orchestrator.WithStatusProviders(cfg.Monitor.Providers),
+		orchestrator.WithGoneAfter(cfg.Monitor.GoneAfter),
+		orchestrator.WithForge(forge.Config{
+			Provider: cfg.Forge.Provider,
+			URL:      cfg.Forge.URL,
+			Token:    cfg.Forge.Token,
+			Username: cfg.Forge.Username,
+		}),
+		orchestrator.WithPullRequests(cfg.PullRequests.Reviewers, cfg.PullRequests.Codeowners, cfg.PullRequests.Labels),
+		orchestrator.WithPullRequestPolling(time.Duration(cfg.PullRequests.PollSeconds)*time.Second, cfg.PullRequests.AutoDismiss),
+		orchestrator.WithCleanupPolicy(cleanup),
+		orchestrator.WithTickets(tracker, cfg.Tickets.InProgress, cfg.Tickets.InReview),
+		orchestrator.WithTmux(tmuxOps),
+	)
 }
 
 func validateDependencies() error {
@@ -229,6 +469,9 @@ func getCurrentPaneID() (string, error) {
 }
 
 func detectTmuxSession() (string, error) {
+	if os.Getenv("TMUX") == "" {
+		return "", fmt.Errorf("not inside a tmux session (run inside tmux or pass --session)")
+	}
 	out, err := exec.Command("tmux", "display-message", "-p", "#{session_name}").Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to detect tmux session: %w", err)
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/ipc"
+	"github.com/simonbystrom/mastermind/internal/slack"
+	"github.com/simonbystrom/mastermind/internal/web"
+)
+
+// startRemote starts the configured remote frontends for backend: the web
+// dashboard, the Slack app endpoints served alongside it, and posting
+// attention events to Slack. They stop when ctx is cancelled. backend
+// should serialize the operations that change agents, as
+// ipc.Server.Backend does.
+func startRemote(ctx context.Context, cfg config.Config, backend ipc.Backend) {
+	if cfg.Slack.BotToken != "" && cfg.Slack.Channel != "" {
+		poster := slack.NewPoster(cfg.Slack.BotToken, cfg.Slack.Channel, slack.DefaultAPIURL)
+		go poster.Run(ctx, backend)
+	}
+
+	if cfg.Web.Listen == "" {
+		if cfg.Slack.SigningSecret != "" {
+			slog.Warn("slack commands need [web] listen to be set")
+		}
+		return
+	}
+	addr, err := web.ListenAddr(cfg.Web.Listen, cfg.Web.Token)
+	if err != nil {
+		slog.Error("not serving the web dashboard", "error", err)
+		return
+	}
+	mux := http.NewServeMux()
+	// Slack authenticates with its request signature, not the web token.
+	if cfg.Slack.SigningSecret != "" {
+		mux.Handle("/slack/", slack.NewHandler(backend, cfg.Slack.SigningSecret))
+	}
+	mux.Handle("/", web.Handler(backend, cfg.Web.Token))
+	go func() {
+		if err := web.Serve(ctx, addr, mux); err != nil {
+			slog.Error("web dashboard stopped", "error", err)
+		}
+	}()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/simonbystrom/mastermind/internal/recorder"
+)
+
+// runReplay implements `mastermind replay <session.json>`: it carries out
+// the actions of a script recorded with --record, in order and with the
+// recorded pauses between them, and reports each outcome. It exits
+// non-zero when an action failed on replay but not when recorded, or the
+// other way round.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	repo := fs.String("repo", "", "path to git repository (defaults to current directory)")
+	session := fs.String("session", "", "tmux session name (defaults to current session)")
+	speed := fs.Float64("speed", 1, "replay speed; 0 replays without pauses")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: mastermind replay [flags] <session.json>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 || *speed < 0 {
+		fs.Usage()
+		return 2
+	}
+
+	script, err := recorder.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	orch, _, logFile, err := startHeadless(ctx, *repo, *session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+
+	done := make(chan struct{})
+	go func() {
+		orch.StartMonitor()
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	fmt.Printf("Replaying %d action(s) recorded in %s\n", len(script.Actions), script.Repo)
+	results := recorder.Replay(ctx, script, orch.IPCBackend(), *speed, func(i int, r recorder.Result) {
+		outcome := "ok"
+		if r.Error != "" {
+			outcome = "failed: " + r.Error
+		}
+		if r.Changed() {
+			if r.Action.Error != "" {
+				outcome += fmt.Sprintf(" (recorded: failed: %s)", r.Action.Error)
+			} else {
+				outcome += " (recorded: ok)"
+			}
+		}
+		fmt.Printf("[%d/%d] %s %s: %s\n", i+1, len(script.Actions), r.Action.Op, r.Action.Branch, outcome)
+	})
+
+	changed := 0
+	for _, r := range results {
+		if r.Changed() {
+			changed++
+		}
+	}
+	switch {
+	case len(results) < len(script.Actions):
+		fmt.Fprintf(os.Stderr, "interrupted after %d of %d action(s)\n", len(results), len(script.Actions))
+		return 1
+	case changed > 0:
+		fmt.Printf("Replay: %d action(s) had a different outcome than recorded\n", changed)
+		return 1
+	}
+	fmt.Println("Replay: every action had its recorded outcome")
+	return 0
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/daemon"
+	"github.com/simonbystrom/mastermind/internal/monitor"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+	"github.com/simonbystrom/mastermind/internal/playbook"
+)
+
+// runPlaybook implements `mastermind run <playbook>`: it spawns an agent
+// for the playbook without the TUI and waits until the agent has finished
+// and the playbook's checks have run. It exits non-zero when a check or
+// the automatic merge fails. With a daemon running, the daemon runs the
+// agent and this command only follows it.
+func runPlaybook(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	repo := fs.String("repo", "", "path to git repository (defaults to current directory)")
+	session := fs.String("session", "", "tmux session name (defaults to current session)")
+	branch := fs.String("branch", "", "branch for the agent (defaults to the playbook's)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: mastermind run [flags] <playbook.yml|playbook.md>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	// The daemon may load the playbook from another directory.
+	path, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	p, err := playbook.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if *branch == "" {
+		*branch = p.BranchName(time.Now())
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	orch, store, logFile, err := startHeadless(ctx, *repo, *session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+
+	// Subscribe before the monitor starts so no event is missed.
+	events := orch.Events()
+	if err := orch.RunPlaybook(p, *branch, orch.DefaultHarness()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	slog.Info("playbook started", "playbook", p.Name, "branch", *branch)
+	fmt.Printf("Running playbook %s on %s; waiting for the agent to finish\n", p.Name, *branch)
+
+	done := make(chan struct{})
+	go func() {
+		orch.StartMonitor()
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	for ev := range events {
+		switch ev := ev.(type) {
+		case monitor.PlaybookFinished:
+			if ev.Branch != *branch {
+				continue
+			}
+			return reportPlaybook(ev)
+		case monitor.AgentGone:
+			if a, ok := store.Get(ev.AgentID); ok && a.Branch == *branch {
+				fmt.Fprintf(os.Stderr, "error: the agent's tmux window closed before it finished\n")
+				return 1
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "interrupted; the agent keeps running in tmux\n")
+	return 1
+}
+
+// startHeadless sets up an orchestrator for a subcommand that drives
+// agents without the TUI: it validates the repository, detects the tmux
+// session and recovers the tracked agents. With a daemon running it
+// follows the daemon, which then runs the agents. The caller closes the
+// returned log file and starts the monitor.
+func startHeadless(ctx context.Context, repo, session string) (*orchestrator.Orchestrator, *agent.Store, *os.File, error) {
+	absRepo, err := resolveRepo(repo)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := validateDependencies(); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := validateGitRepo(absRepo); err != nil {
+		return nil, nil, nil, err
+	}
+	if session == "" {
+		if session, err = detectTmuxSession(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	cfg, err := config.LoadForRepo(absRepo)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	worktreeDir, logFile, err := setupWorktreeDir(absRepo)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	opts := orchestratorOptions(cfg)
+	if pid, running := daemon.Running(worktreeDir); running {
+		slog.Info("daemon running, handing agents to it", "pid", pid)
+		opts = append(opts, orchestrator.WithDaemonClient(daemon.EventsPath(worktreeDir), daemon.SocketPath(worktreeDir)))
+	}
+	store := agent.NewStore()
+	orch := orchestrator.New(ctx, store, absRepo, session, worktreeDir, opts...)
+	orch.RecoverAgents()
+	return orch, store, logFile, nil
+}
+
+// reportPlaybook prints the outcome of a playbook run and returns the
+// command's exit code.
+func reportPlaybook(ev monitor.PlaybookFinished) int {
+	switch {
+	case ev.Failed != "":
+		fmt.Fprintf(os.Stderr, "check failed: %s\n%s", ev.Failed, ev.Output)
+		return 1
+	case ev.Error != "":
+		fmt.Fprintf(os.Stderr, "error: %s\n", ev.Error)
+		return 1
+	case ev.Merged:
+		fmt.Printf("%d check(s) passed; merged %s\n", ev.Checks, ev.Branch)
+	default:
+		fmt.Printf("%d check(s) passed; %s is ready for review\n", ev.Checks, ev.Branch)
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+
+	"github.com/simonbystrom/mastermind/internal/agent"
+	"github.com/simonbystrom/mastermind/internal/config"
+	"github.com/simonbystrom/mastermind/internal/orchestrator"
+	"github.com/simonbystrom/mastermind/internal/ui"
+)
+
+// Size of a screenshot when stdout is not a terminal.
+const (
+	screenshotWidth  = 120
+	screenshotHeight = 40
+)
+
+// hiddenFlag reports whether the boolean flag name is on the command line
+// and removes it, so it works without being listed by -help.
+func hiddenFlag(name string) bool {
+	found := false
+	args := os.Args[:1]
+	for _, arg := range os.Args[1:] {
+		if arg == "-"+name || arg == "--"+name {
+			found = true
+			continue
+		}
+		args = append(args, arg)
+	}
+	os.Args = args
+	return found
+}
+
+// runScreenshot implements the hidden --screenshot flag: it renders the
+// dashboard with the tracked agents once to stdout, at the terminal's size
+// or 120x40, for docs and debugging. Nothing is started or changed: the
+// agents are read from the state file as they are.
+func runScreenshot(absRepo, session string) int {
+	if err := validateGitRepo(absRepo); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if session == "" {
+		// Outside tmux the header just shows no session.
+		session, _ = detectTmuxSession()
+	}
+	cfg, err := config.LoadForRepo(absRepo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		return 1
+	}
+	worktreeDir, logFile, err := setupWorktreeDir(absRepo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+
+	store := agent.NewStore()
+	orch := orchestrator.New(context.Background(), store, absRepo, session, worktreeDir, orchestratorOptions(cfg)...)
+	if err := orch.LoadAgents(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	width, height, err := term.GetSize(os.Stdout.Fd())
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = screenshotWidth, screenshotHeight
+	}
+	fmt.Println(ui.Screenshot(ui.NewApp(cfg, orch, store, absRepo, session), width, height))
+	return 0
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/simonbystrom/mastermind/internal/instance"
+)
+
+// runSwitch implements `mastermind switch [name]`: without a name it lists
+// the running mastermind instances, with one it focuses the tmux pane of
+// the instance of that name, or of that repository.
+func runSwitch(args []string) int {
+	fs := flag.NewFlagSet("switch", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: mastermind switch [name | repo]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		instances, err := instance.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		if len(instances) == 0 {
+			fmt.Println("No mastermind instances running")
+			return 0
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tREPO\tSESSION\tPID")
+		for _, inst := range instances {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", inst.Label(), inst.Repo, inst.Session, inst.PID)
+		}
+		w.Flush()
+		return 0
+	}
+
+	inst, err := instance.Find(fs.Arg(0))
+	if err == nil {
+		err = instance.Focus(inst)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}